@@ -37,6 +37,7 @@ SERVICES=(
     "Queue http://queue-service:8083/health"
     "Third-Party http://third-party-service:8084/health"
     "Mock-Error http://mock-error-service:8085/health"
+    "Verifier http://verifier-service:8086/health"
 )
 
 # 基础设施健康检查端点
@@ -2,14 +2,20 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"mocks3/services/metadata/internal/config"
 	"mocks3/services/metadata/internal/handler"
 	"mocks3/services/metadata/internal/repository"
 	"mocks3/services/metadata/internal/service"
 	"mocks3/shared/client"
+	"mocks3/shared/interfaces"
 	"mocks3/shared/middleware"
+	"mocks3/shared/models"
 	"mocks3/shared/observability"
+	"mocks3/shared/proto/metadatapb"
+	"mocks3/shared/utils"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,11 +23,20 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 func main() {
 	// 加载配置
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// 初始化统一可观测性
 	obsConfig := &observability.Config{
@@ -30,6 +45,15 @@ func main() {
 		Environment:    cfg.Server.Environment,
 		OTLPEndpoint:   "http://localhost:4318",
 		LogLevel:       cfg.LogLevel,
+		Debug:          observability.DebugConfigFromEnv(),
+		Profiling:      observability.ProfilingConfigFromEnv(),
+		AuditLog:       observability.AuditLogConfigFromEnv(),
+		LogSampling:    observability.LogSamplingConfigFromEnv(),
+		LogFile:        observability.LogFileConfigFromEnv(),
+		SLOs: []observability.SLO{
+			observability.AvailabilitySLO("availability", 0.999),
+			observability.LatencySLO("latency", 0.99, 200*time.Millisecond),
+		},
 	}
 
 	obs, err := observability.New(context.Background(), obsConfig)
@@ -40,6 +64,13 @@ func main() {
 
 	logger := obs.Logger()
 
+	// 初始化全局ID生成器
+	idStrategy, err := utils.ConfigureIDGeneratorFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure ID generator: %v", err)
+	}
+	logger.Info(context.Background(), "ID generator configured", observability.String("strategy", idStrategy))
+
 	// 初始化Consul管理器
 	consulManager, err := middleware.NewDefaultConsulManager("metadata-service")
 	if err != nil {
@@ -53,20 +84,87 @@ func main() {
 	}
 	defer db.Close()
 
+	if replicas := len(cfg.Database.Replicas.DSNs); replicas > 0 {
+		logger.Info(context.Background(), "Database read replicas configured",
+			observability.Int("replicas", replicas),
+			observability.Int("max_lag_secs", cfg.Database.Replicas.MaxLagSecs))
+	}
+
+	if report, err := db.MigrationStatus(context.Background()); err != nil {
+		logger.Warn(context.Background(), "Failed to get database migration status", observability.Error(err))
+	} else {
+		logger.Info(context.Background(), "Database schema version",
+			observability.Int64("version", report.CurrentVersion),
+			observability.Int("pending", report.Pending))
+	}
+
 	// 初始化仓库
-	metadataRepo := repository.NewMetadataRepository(db)
+	var metadataRepo interfaces.MetadataRepository = repository.NewMetadataRepository(db)
 
-	// 初始化队列客户端
-	queueClient := client.NewQueueClient("http://localhost:8083", 30*time.Second)
-	
-	// TODO: 在未来版本中集成队列功能，当前仅进行连接测试
-	_ = queueClient
+	// 可选的Redis读缓存，连接失败时不启用缓存
+	var metadataCache *repository.CachedMetadataRepository
+	if cfg.Cache.Enabled {
+		metadataCache, err = repository.NewCachedMetadataRepository(metadataRepo, &cfg.Cache, logger)
+		if err != nil {
+			logger.Warn(context.Background(), "Metadata cache disabled",
+				observability.Error(err))
+		} else {
+			defer metadataCache.Close()
+			metadataCache.SetMetricCollector(obs.Collector())
+			metadataRepo = metadataCache
+			logger.Info(context.Background(), "Metadata cache enabled",
+				observability.String("redis", cfg.Cache.Redis.GetAddress()),
+				observability.Duration("ttl", cfg.Cache.GetTTL()))
+		}
+	}
 
 	// 初始化服务
 	metadataService := service.NewMetadataService(metadataRepo, logger)
 
+	// 初始化搜索索引
+	switch cfg.Search.Backend {
+	case "memory":
+		metadataService.SetSearchIndex(repository.NewMemorySearchIndex())
+		if _, err := metadataService.RebuildSearchIndex(context.Background()); err != nil {
+			log.Fatalf("Failed to build search index: %v", err)
+		}
+	default:
+		metadataService.SetSearchIndex(repository.NewPostgresSearchIndex(db))
+	}
+
+	// 元数据变更事件发布到队列服务
+	changesCtx, stopChanges := context.WithCancel(context.Background())
+	defer stopChanges()
+	if cfg.Events.Enabled {
+		queueClient := client.NewQueueClient(cfg.Events.QueueURL, cfg.Events.GetTimeout())
+		metadataService.SetChangePublisher(queueClient, cfg.Events.Topic, cfg.Events.BufferSize, cfg.Events.GetTimeout())
+		metadataService.StartChangePublisher(changesCtx)
+	}
+
+	// 软删除保留与定时清理
+	metadataService.SetDeletedRetention(cfg.Retention.GetDeletedRetention())
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	metadataService.StartPurgeJob(purgeCtx, cfg.Retention.GetPurgeInterval(), cfg.Retention.PurgeBatchSize)
+
+	// 后台统计汇总
+	statsCtx, stopStats := context.WithCancel(context.Background())
+	defer stopStats()
+	metadataService.StartStatsAggregator(statsCtx, cfg.Stats.GetAggregationInterval())
+
+	// 对象过期扫描，到期对象的删除任务发到队列服务
+	expiryCtx, stopExpiry := context.WithCancel(context.Background())
+	defer stopExpiry()
+	if cfg.Expiry.Enabled {
+		queueClient := client.NewQueueClient(cfg.Expiry.QueueURL, cfg.Expiry.GetTimeout())
+		metadataService.SetExpiryScanner(queueClient, cfg.Expiry.BatchSize, cfg.Expiry.MaxTasksPerRun, cfg.Expiry.DryRun)
+		metadataService.StartExpiryScanner(expiryCtx, cfg.Expiry.GetInterval())
+	}
+
 	// 初始化处理器
 	metadataHandler := handler.NewMetadataHandler(metadataService, logger)
+	graphqlHandler := handler.NewGraphQLHandler(metadataService, logger)
+	adminHandler := handler.NewAdminHandler(db, db, metadataService, logger)
 
 	// 注册服务到Consul
 	ctx := context.Background()
@@ -97,20 +195,67 @@ func main() {
 	// 添加中间件
 	router.Use(gin.Logger())
 	router.Use(middleware.GinRecoveryMiddleware(middleware.DefaultRecoveryConfig()))
+	// 请求ID：沿用或生成X-Request-ID，日志和出站调用自动带上
+	router.Use(middleware.RequestIDMiddleware())
+	// baggage：读取上游透传的租户、测试运行ID和场景ID，日志和错误注入条件自动带上
+	router.Use(obs.GinBaggageMiddleware())
 	// 使用统一可观测性中间件
 	router.Use(obs.GinMiddleware())
 
+	// 管理接口的修改操作写入审计日志
+	router.Use(obs.AuditLogger().GinAdminMiddleware("/admin"))
+
+	// 管理接口鉴权和飞行记录器
+	authorizer, flightRecorder, err := setupAdmin(router, cfg, obs, client.NewStorageClient(cfg.FlightRecorder.StorageURL, 10*time.Second))
+	if err != nil {
+		log.Fatalf("Failed to set up admin endpoints: %v", err)
+	}
+
+	// 多租户：解析请求租户，元数据按租户隔离
+	var tenantResolver *middleware.TenantResolver
+	if cfg.Tenancy.Enabled {
+		tenantResolver, err = newTenantResolver(cfg.Tenancy, authorizer)
+		if err != nil {
+			log.Fatalf("Failed to initialize tenant resolver: %v", err)
+		}
+		router.Use(tenantResolver.GinMiddleware())
+	}
+
+	// 记录变更审计的操作者
+	router.Use(middleware.AuditActorMiddleware(authorizer))
+
+	// 运行时修改日志级别，排查问题时临时切换到debug
+	middleware.NewLogLevelController(logger).RegisterAdminRoutes(router.Group("/admin"))
+
+	// 错误注入：每个请求向mock-error查询是否注入错误，放在业务路由之前
+	if cfg.FaultInjection.Enabled {
+		faultInjector, err := newFaultInjector(cfg.FaultInjection, logger)
+		if err != nil {
+			log.Fatalf("Failed to initialize fault injector: %v", err)
+		}
+		router.Use(faultInjector.GinMiddleware())
+	}
+
 	// 设置路由
 	metadataHandler.RegisterRoutes(router)
+	graphqlHandler.RegisterRoutes(router)
+	adminHandler.RegisterRoutes(router)
 
 	// 健康检查
 	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"status":    "healthy",
 			"service":   "metadata-service",
 			"version":   cfg.Server.Version,
 			"timestamp": time.Now().Format(time.RFC3339),
-		})
+		}
+		if metadataCache != nil {
+			response["cache"] = metadataCache.Stats()
+		}
+		if changeStats := metadataService.ChangeStats(); changeStats != nil {
+			response["change_events"] = changeStats
+		}
+		c.JSON(http.StatusOK, response)
 	})
 
 	// 创建HTTP服务器
@@ -131,6 +276,23 @@ func main() {
 		}
 	}()
 
+	// gRPC接口：与HTTP接口共用同一个MetadataService
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		listener, err := net.Listen("tcp", cfg.GRPC.GetAddress(cfg.Server.Host))
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		grpcServer = newGRPCServer(handler.NewGRPCHandler(metadataService, logger), tenantResolver, authorizer)
+		go func() {
+			logger.Info(context.Background(), "Starting metadata gRPC server",
+				observability.String("address", listener.Addr().String()))
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -145,6 +307,150 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+
+	// 等待进行中的事件包上传完成
+	if flightRecorder != nil {
+		flightRecorder.Wait(ctx)
+	}
+
+	// 停止变更事件发布，等待缓冲区中的事件发送完成
+	stopChanges()
+	metadataService.WaitChangePublisher(ctx)
 
 	logger.Info(context.Background(), "Metadata service stopped")
 }
+
+// setupAdmin 安装管理接口鉴权和飞行记录器，未启用的功能返回nil；
+// gin在注册路由时固定处理链，鉴权中间件必须先于任何管理接口注册，否则这些接口不经过鉴权
+func setupAdmin(router *gin.Engine, cfg *config.Config, obs *observability.Observability, store middleware.IncidentStore) (*middleware.Authorizer, *middleware.FlightRecorder, error) {
+	var authorizer *middleware.Authorizer
+	if cfg.RBAC.Enabled {
+		var err error
+		authorizer, err = newAuthorizer(cfg.RBAC)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize authorizer: %w", err)
+		}
+		router.Use(authorizer.GinMiddleware())
+		authorizer.RegisterAdminRoutes(router.Group("/admin"))
+	}
+
+	// 飞行记录器：保留最近的请求、日志和span，SLO违规或护栏触发时转储事件包到存储bucket
+	var flightRecorder *middleware.FlightRecorder
+	if cfg.FlightRecorder.Enabled {
+		var err error
+		flightRecorder, err = newFlightRecorder(cfg.FlightRecorder, store, obs.Logger())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize flight recorder: %w", err)
+		}
+		flightRecorder.SetMetricCollector(obs.Collector())
+		flightRecorder.Attach(obs)
+		router.Use(obs.GinTracingMiddleware())
+		router.Use(flightRecorder.GinMiddleware())
+		flightRecorder.RegisterAdminRoutes(router.Group("/admin"))
+	}
+
+	return authorizer, flightRecorder, nil
+}
+
+// newAuthorizer 根据配置创建管理接口鉴权器（/admin/*、统计刷新、过期扫描、租户列表、bucket保留策略与schema）
+func newAuthorizer(cfg config.RBACConfig) (*middleware.Authorizer, error) {
+	rbacConfig := middleware.DefaultRBACConfig()
+	rbacConfig.Header = cfg.Header
+	rbacConfig.Rules = append(rbacConfig.Rules, &middleware.RBACRule{
+		PathPrefix:      "/api/v1/stats/refresh",
+		ReadCapability:  models.CapabilityAdminRead,
+		WriteCapability: models.CapabilityAdminWrite,
+	}, &middleware.RBACRule{
+		PathPrefix:      "/api/v1/lifecycle/expiry",
+		ReadCapability:  models.CapabilityAdminRead,
+		WriteCapability: models.CapabilityAdminWrite,
+	}, &middleware.RBACRule{
+		PathPrefix:      "/api/v1/tenants",
+		ReadCapability:  models.CapabilityAdminRead,
+		WriteCapability: models.CapabilityAdminWrite,
+	}, &middleware.RBACRule{
+		PathPrefix:      "/api/v1/retention",
+		ReadCapability:  models.CapabilityAdminRead,
+		WriteCapability: models.CapabilityAdminWrite,
+	}, &middleware.RBACRule{
+		PathPrefix:      "/api/v1/schemas",
+		ReadCapability:  models.CapabilityAdminRead,
+		WriteCapability: models.CapabilityAdminWrite,
+	})
+	for _, key := range cfg.Keys {
+		rbacConfig.Keys = append(rbacConfig.Keys, &models.APIKey{Name: key.Name, Key: key.Key, Role: key.Role, Tenant: key.Tenant})
+	}
+	return middleware.NewAuthorizer(rbacConfig)
+}
+
+// newTenantResolver 根据配置创建租户解析器，authorizer为nil时只从请求头读取租户
+func newTenantResolver(cfg config.TenancyConfig, authorizer *middleware.Authorizer) (*middleware.TenantResolver, error) {
+	tenantConfig := middleware.DefaultTenantConfig()
+	tenantConfig.Header = cfg.Header
+	tenantConfig.DefaultTenant = cfg.DefaultTenant
+	tenantConfig.Required = cfg.Required
+	return middleware.NewTenantResolver(tenantConfig, authorizer)
+}
+
+// newGRPCServer 创建gRPC服务器：OTel追踪、panic恢复、请求ID，启用多租户时解析请求租户，并解析审计操作者
+func newGRPCServer(metadataHandler *handler.GRPCHandler, tenantResolver *middleware.TenantResolver, authorizer *middleware.Authorizer) *grpc.Server {
+	interceptors := []grpc.UnaryServerInterceptor{grpcRecoveryInterceptor, middleware.RequestIDUnaryServerInterceptor()}
+	if tenantResolver != nil {
+		interceptors = append(interceptors, tenantResolver.UnaryServerInterceptor())
+	}
+	interceptors = append(interceptors, middleware.AuditActorUnaryServerInterceptor(authorizer))
+
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(interceptors...),
+	)
+	metadataHandler.Register(server)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(metadatapb.MetadataService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	return server
+}
+
+// grpcRecoveryInterceptor 将处理中的panic转换为Internal错误
+func grpcRecoveryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = status.Errorf(codes.Internal, "panic in %s: %v", info.FullMethod, r)
+		}
+	}()
+	return next(ctx, req)
+}
+
+// newFlightRecorder 根据配置创建飞行记录器
+func newFlightRecorder(cfg config.FlightRecorderConfig, store middleware.IncidentStore, logger *observability.Logger) (*middleware.FlightRecorder, error) {
+	recorderConfig := middleware.DefaultFlightRecorderConfig("metadata-service")
+	recorderConfig.Bucket = cfg.Bucket
+	recorderConfig.RequestCapacity = cfg.RequestCapacity
+	recorderConfig.LogCapacity = cfg.LogCapacity
+	recorderConfig.SpanCapacity = cfg.SpanCapacity
+	recorderConfig.SLOWindow = time.Duration(cfg.SLOWindowSecs) * time.Second
+	recorderConfig.MinRequests = cfg.MinRequests
+	recorderConfig.MaxErrorRate = cfg.MaxErrorRate
+	recorderConfig.LatencyThreshold = time.Duration(cfg.LatencyThresholdMs) * time.Millisecond
+	recorderConfig.MaxSlowRate = cfg.MaxSlowRate
+	recorderConfig.Cooldown = time.Duration(cfg.CooldownSecs) * time.Second
+	return middleware.NewFlightRecorder(recorderConfig, store, logger)
+}
+
+// newFaultInjector 根据配置创建错误注入客户端中间件
+func newFaultInjector(cfg config.FaultInjectionConfig, logger *observability.Logger) (*middleware.FaultInjector, error) {
+	timeout, err := time.ParseDuration(cfg.CheckTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fault injection check timeout: %w", err)
+	}
+
+	injectionConfig := middleware.DefaultFaultInjectionConfig("metadata-service")
+	injectionConfig.CheckTimeout = timeout
+	mockErrorClient := client.NewMockErrorClient(cfg.MockErrorURL, timeout)
+	mockErrorClient.SetNamespace(cfg.Namespace)
+	return middleware.NewFaultInjector(injectionConfig, mockErrorClient, logger)
+}
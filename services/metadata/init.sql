@@ -31,6 +31,8 @@ CREATE INDEX IF NOT EXISTS idx_metadata_created_at ON metadata(created_at);
 CREATE INDEX IF NOT EXISTS idx_metadata_content_type ON metadata(content_type);
 CREATE INDEX IF NOT EXISTS idx_metadata_size ON metadata(size);
 CREATE INDEX IF NOT EXISTS idx_metadata_deleted_at ON metadata(deleted_at);
+-- 标签查询（@> 和 ? 运算符）
+CREATE INDEX IF NOT EXISTS idx_metadata_tags ON metadata USING gin(tags) WHERE deleted_at IS NULL;
 
 -- 创建唯一约束（同一bucket下key唯一，排除已删除的记录）
 CREATE UNIQUE INDEX IF NOT EXISTS idx_metadata_bucket_key_unique 
@@ -42,6 +44,15 @@ CREATE INDEX IF NOT EXISTS idx_metadata_tags_gin ON metadata USING gin(tags);
 CREATE INDEX IF NOT EXISTS idx_metadata_headers_gin ON metadata USING gin(headers);
 CREATE INDEX IF NOT EXISTS idx_metadata_storage_nodes_gin ON metadata USING gin(storage_nodes);
 
+-- 创建修改时间索引用于增量同步（按LastModified窗口列举）
+CREATE INDEX IF NOT EXISTS idx_metadata_updated_at
+ON metadata(updated_at, bucket, key)
+WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_metadata_bucket_updated_at
+ON metadata(bucket, updated_at, key)
+WHERE deleted_at IS NULL;
+
 -- 创建复合索引用于常见查询
 CREATE INDEX IF NOT EXISTS idx_metadata_bucket_status_created 
 ON metadata(bucket, status, created_at DESC);
@@ -50,6 +61,43 @@ CREATE INDEX IF NOT EXISTS idx_metadata_content_type_created
 ON metadata(content_type, created_at DESC) 
 WHERE deleted_at IS NULL;
 
+-- 创建全文检索函数和索引（key > tags > content_type > headers）
+CREATE OR REPLACE FUNCTION metadata_search_vector(k TEXT, ct TEXT, t JSONB, h JSONB)
+RETURNS tsvector AS $$
+    SELECT
+        setweight(to_tsvector('simple', regexp_replace(lower(COALESCE(k, '')), '[^[:alnum:]]+', ' ', 'g')), 'A') ||
+        setweight(to_tsvector('simple', regexp_replace(lower(COALESCE(t::text, '')), '[^[:alnum:]]+', ' ', 'g')), 'B') ||
+        setweight(to_tsvector('simple', regexp_replace(lower(COALESCE(ct, '')), '[^[:alnum:]]+', ' ', 'g')), 'C') ||
+        setweight(to_tsvector('simple', regexp_replace(lower(COALESCE(h::text, '')), '[^[:alnum:]]+', ' ', 'g')), 'D')
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE INDEX IF NOT EXISTS idx_metadata_search ON metadata
+    USING gin(metadata_search_vector(key, content_type, tags, headers))
+    WHERE deleted_at IS NULL;
+
+-- 创建历史版本表（每次更新前保存旧版本）
+CREATE TABLE IF NOT EXISTS metadata_history (
+    history_id BIGSERIAL PRIMARY KEY,
+    metadata_id VARCHAR(255) NOT NULL,
+    key VARCHAR(500) NOT NULL,
+    bucket VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    content_type VARCHAR(255),
+    md5_hash VARCHAR(32),
+    etag VARCHAR(255),
+    storage_nodes JSONB,
+    headers JSONB,
+    tags JSONB,
+    status VARCHAR(50),
+    version BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    archived_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_metadata_history_bucket_key_version
+ON metadata_history(bucket, key, version DESC);
+
 -- 创建统计缓存表
 CREATE TABLE IF NOT EXISTS stats_cache (
     id SERIAL PRIMARY KEY,
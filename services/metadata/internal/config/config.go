@@ -2,14 +2,169 @@ package config
 
 import (
 	"fmt"
+	"mocks3/shared/models"
 	"mocks3/shared/utils"
+	"time"
 )
 
 // Config 元数据服务配置
 type Config struct {
-	Server   ServerConfig   `yaml:"server" json:"server"`
-	Database DatabaseConfig `yaml:"database" json:"database"`
-	LogLevel string         `yaml:"log_level" json:"log_level"`
+	Server         ServerConfig         `yaml:"server" json:"server"`
+	GRPC           GRPCConfig           `yaml:"grpc" json:"grpc"`
+	Database       DatabaseConfig       `yaml:"database" json:"database"`
+	Search         SearchConfig         `yaml:"search" json:"search"`
+	Retention      RetentionConfig      `yaml:"retention" json:"retention"`
+	Stats          StatsConfig          `yaml:"stats" json:"stats"`
+	Cache          CacheConfig          `yaml:"cache" json:"cache"`
+	Events         EventsConfig         `yaml:"events" json:"events"`
+	Expiry         ExpiryConfig         `yaml:"expiry" json:"expiry"`
+	RBAC           RBACConfig           `yaml:"rbac" json:"rbac"`
+	Tenancy        TenancyConfig        `yaml:"tenancy" json:"tenancy"`
+	FlightRecorder FlightRecorderConfig `yaml:"flight_recorder" json:"flight_recorder"`
+	FaultInjection FaultInjectionConfig `yaml:"fault_injection" json:"fault_injection"`
+	LogLevel       string               `yaml:"log_level" json:"log_level"`
+}
+
+// RBACConfig 管理接口鉴权配置
+type RBACConfig struct {
+	Enabled bool           `yaml:"enabled" json:"enabled"`
+	Header  string         `yaml:"header" json:"header"`
+	Keys    []APIKeyConfig `yaml:"keys" json:"keys"`
+}
+
+// FaultInjectionConfig 错误注入配置：每个请求向mock-error查询是否注入错误并执行命中的动作
+type FaultInjectionConfig struct {
+	Enabled      bool   `yaml:"enabled" json:"enabled"`
+	MockErrorURL string `yaml:"mock_error_url" json:"mock_error_url"`
+	Namespace    string `yaml:"namespace" json:"namespace"`         // 只评估该命名空间的规则，为空时为default
+	CheckTimeout string `yaml:"check_timeout" json:"check_timeout"` // 单次检查超时，超时或失败时不注入
+}
+
+// FlightRecorderConfig 飞行记录器配置：保留最近的请求、日志和span，SLO违规或护栏触发时转储到存储bucket
+type FlightRecorderConfig struct {
+	Enabled            bool    `yaml:"enabled" json:"enabled"`
+	StorageURL         string  `yaml:"storage_url" json:"storage_url"`
+	Bucket             string  `yaml:"bucket" json:"bucket"`
+	RequestCapacity    int     `yaml:"request_capacity" json:"request_capacity"`
+	LogCapacity        int     `yaml:"log_capacity" json:"log_capacity"`
+	SpanCapacity       int     `yaml:"span_capacity" json:"span_capacity"`
+	SLOWindowSecs      int     `yaml:"slo_window_secs" json:"slo_window_secs"`
+	MinRequests        int     `yaml:"min_requests" json:"min_requests"`     // 窗口内请求数少于该值时不判定SLO
+	MaxErrorRate       float64 `yaml:"max_error_rate" json:"max_error_rate"` // 5xx比例阈值，0表示不检查
+	LatencyThresholdMs int     `yaml:"latency_threshold_ms" json:"latency_threshold_ms"`
+	MaxSlowRate        float64 `yaml:"max_slow_rate" json:"max_slow_rate"` // 慢请求比例阈值，0表示不检查
+	CooldownSecs       int     `yaml:"cooldown_secs" json:"cooldown_secs"` // 两次自动抓取的最小间隔
+}
+
+// TenancyConfig 多租户配置：按API key绑定的租户或租户请求头隔离数据
+type TenancyConfig struct {
+	Enabled       bool   `yaml:"enabled" json:"enabled"`
+	Header        string `yaml:"header" json:"header"`
+	DefaultTenant string `yaml:"default_tenant" json:"default_tenant"` // 未指定租户的请求使用的租户
+	Required      bool   `yaml:"required" json:"required"`             // 要求每个请求显式指定租户
+}
+
+// APIKeyConfig 初始API key
+type APIKeyConfig struct {
+	Name   string `yaml:"name" json:"name"`
+	Key    string `yaml:"key" json:"key"`
+	Role   string `yaml:"role" json:"role"`     // viewer, operator, chaos-admin, super-admin
+	Tenant string `yaml:"tenant" json:"tenant"` // 绑定的租户，为空时不绑定
+}
+
+// EventsConfig 元数据变更事件（CDC）配置
+type EventsConfig struct {
+	Enabled    bool   `yaml:"enabled" json:"enabled"`
+	QueueURL   string `yaml:"queue_url" json:"queue_url"`
+	Topic      string `yaml:"topic" json:"topic"`
+	BufferSize int    `yaml:"buffer_size" json:"buffer_size"`
+	Timeout    string `yaml:"timeout" json:"timeout"`
+}
+
+// GetTimeout 获取单次发布超时
+func (e *EventsConfig) GetTimeout() time.Duration {
+	timeout, _ := time.ParseDuration(e.Timeout)
+	return timeout
+}
+
+// ExpiryConfig 对象过期扫描配置：按expires-at/expire-after-days标签向队列服务发出删除任务
+type ExpiryConfig struct {
+	Enabled        bool   `yaml:"enabled" json:"enabled"`
+	QueueURL       string `yaml:"queue_url" json:"queue_url"`
+	Timeout        string `yaml:"timeout" json:"timeout"`
+	IntervalSecs   int    `yaml:"interval_secs" json:"interval_secs"` // 0表示只通过接口手动触发
+	BatchSize      int    `yaml:"batch_size" json:"batch_size"`
+	MaxTasksPerRun int    `yaml:"max_tasks_per_run" json:"max_tasks_per_run"` // 0表示不限制
+	DryRun         bool   `yaml:"dry_run" json:"dry_run"`                     // 只统计不发出删除任务
+}
+
+// GetTimeout 获取单次入队超时
+func (e *ExpiryConfig) GetTimeout() time.Duration {
+	timeout, _ := time.ParseDuration(e.Timeout)
+	return timeout
+}
+
+// GetInterval 获取扫描间隔
+func (e *ExpiryConfig) GetInterval() time.Duration {
+	return time.Duration(e.IntervalSecs) * time.Second
+}
+
+// CacheConfig 元数据读缓存配置（Redis，读穿透 + 写失效）
+type CacheConfig struct {
+	Enabled    bool        `yaml:"enabled" json:"enabled"`
+	TTLSeconds int         `yaml:"ttl" json:"ttl"` // 缓存条目过期时间，兜底并发读写产生的旧值
+	KeyPrefix  string      `yaml:"key_prefix" json:"key_prefix"`
+	Redis      RedisConfig `yaml:"redis" json:"redis"`
+}
+
+// GetTTL 获取缓存过期时间
+func (c *CacheConfig) GetTTL() time.Duration {
+	return time.Duration(c.TTLSeconds) * time.Second
+}
+
+// RedisConfig Redis配置
+type RedisConfig struct {
+	Host     string `yaml:"host" json:"host"`
+	Port     int    `yaml:"port" json:"port"`
+	Password string `yaml:"password" json:"password"`
+	DB       int    `yaml:"db" json:"db"`
+}
+
+// GetAddress 获取Redis地址
+func (r *RedisConfig) GetAddress() string {
+	return fmt.Sprintf("%s:%d", r.Host, r.Port)
+}
+
+// RetentionConfig 软删除保留配置
+type RetentionConfig struct {
+	DeletedRetentionHours int `yaml:"deleted_retention_hours" json:"deleted_retention_hours"` // 0表示永久保留
+	PurgeIntervalMins     int `yaml:"purge_interval_mins" json:"purge_interval_mins"`         // 0表示不启动清理任务
+	PurgeBatchSize        int `yaml:"purge_batch_size" json:"purge_batch_size"`
+}
+
+// GetDeletedRetention 获取软删除保留时长
+func (r *RetentionConfig) GetDeletedRetention() time.Duration {
+	return time.Duration(r.DeletedRetentionHours) * time.Hour
+}
+
+// GetPurgeInterval 获取清理任务间隔
+func (r *RetentionConfig) GetPurgeInterval() time.Duration {
+	return time.Duration(r.PurgeIntervalMins) * time.Minute
+}
+
+// StatsConfig 统计汇总配置
+type StatsConfig struct {
+	AggregationIntervalSecs int `yaml:"aggregation_interval_secs" json:"aggregation_interval_secs"` // 0表示每次请求实时计算
+}
+
+// GetAggregationInterval 获取后台汇总间隔
+func (s *StatsConfig) GetAggregationInterval() time.Duration {
+	return time.Duration(s.AggregationIntervalSecs) * time.Second
+}
+
+// SearchConfig 搜索配置
+type SearchConfig struct {
+	Backend string `yaml:"backend" json:"backend"` // postgres, memory
 }
 
 // ServerConfig 服务器配置
@@ -20,6 +175,17 @@ type ServerConfig struct {
 	Version     string `yaml:"version" json:"version"`
 }
 
+// GRPCConfig gRPC接口配置，与HTTP接口共用服务实现，监听独立端口
+type GRPCConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	Port    int  `yaml:"port" json:"port"`
+}
+
+// GetAddress 获取gRPC监听地址（与HTTP使用相同的host）
+func (g *GRPCConfig) GetAddress(host string) string {
+	return fmt.Sprintf("%s:%d", host, g.Port)
+}
+
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
 	Driver   string `yaml:"driver" json:"driver"`
@@ -29,6 +195,18 @@ type DatabaseConfig struct {
 	Password string `yaml:"password" json:"password"`
 	Database string `yaml:"database" json:"database"`
 	SSLMode  string `yaml:"ssl_mode" json:"ssl_mode"`
+	DSN      string `yaml:"dsn" json:"-"` // 主库连接串，设置后忽略host/port等字段
+
+	AutoMigrate bool `yaml:"auto_migrate" json:"auto_migrate"` // 启动时执行未应用的schema迁移
+
+	Replicas ReplicaConfig `yaml:"replicas" json:"replicas"`
+}
+
+// ReplicaConfig 只读副本配置：读请求（Get/List/Search/Stats）路由到副本，写请求始终走主库
+type ReplicaConfig struct {
+	DSNs              []string `yaml:"dsns" json:"-"`                                  // 副本连接串，驱动与主库相同
+	MaxLagSecs        int      `yaml:"max_lag_secs" json:"max_lag_secs"`               // 复制延迟超过该值的副本不参与读，0表示不检查延迟
+	CheckIntervalSecs int      `yaml:"check_interval_secs" json:"check_interval_secs"` // 副本健康与延迟检查间隔
 }
 
 // GetAddress 获取服务器地址
@@ -38,15 +216,20 @@ func (s *ServerConfig) GetAddress() string {
 
 // GetDSN 获取数据库连接字符串
 func (d *DatabaseConfig) GetDSN() string {
+	if d.DSN != "" {
+		return d.DSN
+	}
 	switch d.Driver {
 	case "postgres":
 		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 			d.Host, d.Port, d.Username, d.Password, d.Database, d.SSLMode)
 	case "mysql":
-		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
+		// multiStatements用于执行包含多条语句的迁移脚本
+		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&loc=UTC&multiStatements=true",
 			d.Username, d.Password, d.Host, d.Port, d.Database)
 	case "sqlite3":
-		return d.Database
+		// database为数据库文件路径（或:memory:）；_cslike使LIKE区分大小写，与PostgreSQL一致
+		return fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL&_foreign_keys=on&_cslike=true", d.Database)
 	default:
 		return ""
 	}
@@ -62,6 +245,10 @@ func Load() *Config {
 			Environment: "development",
 			Version:     "1.0.0",
 		},
+		GRPC: GRPCConfig{
+			Enabled: false,
+			Port:    9081,
+		},
 		Database: DatabaseConfig{
 			Driver:   "postgres",
 			Host:     "localhost",
@@ -70,6 +257,78 @@ func Load() *Config {
 			Password: "password",
 			Database: "mocks3_metadata",
 			SSLMode:  "disable",
+
+			AutoMigrate: true,
+
+			Replicas: ReplicaConfig{
+				MaxLagSecs:        10,
+				CheckIntervalSecs: 5,
+			},
+		},
+		Search: SearchConfig{
+			Backend: "postgres",
+		},
+		Stats: StatsConfig{
+			AggregationIntervalSecs: 60,
+		},
+		Retention: RetentionConfig{
+			DeletedRetentionHours: 168,
+			PurgeIntervalMins:     60,
+			PurgeBatchSize:        1000,
+		},
+		Cache: CacheConfig{
+			Enabled:    false,
+			TTLSeconds: 300,
+			KeyPrefix:  "mocks3:metadata:",
+			Redis: RedisConfig{
+				Host: "localhost",
+				Port: 6379,
+			},
+		},
+		Events: EventsConfig{
+			Enabled:    false,
+			QueueURL:   "http://localhost:8083",
+			Topic:      "metadata-changes",
+			BufferSize: 1000,
+			Timeout:    "5s",
+		},
+		Expiry: ExpiryConfig{
+			Enabled:        false,
+			QueueURL:       "http://localhost:8083",
+			Timeout:        "5s",
+			IntervalSecs:   3600,
+			BatchSize:      500,
+			MaxTasksPerRun: 10000,
+			DryRun:         false,
+		},
+		RBAC: RBACConfig{
+			Enabled: false,
+			Header:  "X-Api-Key",
+		},
+		Tenancy: TenancyConfig{
+			Enabled:       false,
+			Header:        models.HeaderTenantID,
+			DefaultTenant: models.DefaultTenant,
+			Required:      false,
+		},
+		FlightRecorder: FlightRecorderConfig{
+			Enabled:            false,
+			StorageURL:         "http://localhost:8082",
+			Bucket:             models.DefaultIncidentBucket,
+			RequestCapacity:    200,
+			LogCapacity:        500,
+			SpanCapacity:       500,
+			SLOWindowSecs:      60,
+			MinRequests:        20,
+			MaxErrorRate:       0.1,
+			LatencyThresholdMs: 1000,
+			MaxSlowRate:        0.2,
+			CooldownSecs:       300,
+		},
+		FaultInjection: FaultInjectionConfig{
+			Enabled:      false,
+			MockErrorURL: "http://localhost:8085",
+			CheckTimeout: "200ms",
 		},
 		LogLevel: "info",
 	}
@@ -89,21 +348,168 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
 
+	if c.GRPC.Enabled {
+		if c.GRPC.Port <= 0 || c.GRPC.Port > 65535 {
+			return fmt.Errorf("invalid grpc port: %d", c.GRPC.Port)
+		}
+		if c.GRPC.Port == c.Server.Port {
+			return fmt.Errorf("grpc port must differ from server port %d", c.Server.Port)
+		}
+	}
+
 	if c.Database.Driver == "" {
 		return fmt.Errorf("database driver is required")
 	}
 
-	if c.Database.Host == "" {
-		return fmt.Errorf("database host is required")
-	}
+	switch c.Database.Driver {
+	case "postgres", "mysql":
+		if c.Database.DSN != "" {
+			break
+		}
+		if c.Database.Host == "" {
+			return fmt.Errorf("database host is required")
+		}
 
-	if c.Database.Username == "" {
-		return fmt.Errorf("database username is required")
+		if c.Database.Username == "" {
+			return fmt.Errorf("database username is required")
+		}
+	case "sqlite3":
+	default:
+		return fmt.Errorf("unsupported database driver: %s", c.Database.Driver)
 	}
 
-	if c.Database.Database == "" {
+	if c.Database.Database == "" && c.Database.DSN == "" {
 		return fmt.Errorf("database name is required")
 	}
 
+	for i, dsn := range c.Database.Replicas.DSNs {
+		if dsn == "" {
+			return fmt.Errorf("database replica %d dsn is required", i+1)
+		}
+	}
+	if len(c.Database.Replicas.DSNs) > 0 {
+		if c.Database.Replicas.MaxLagSecs < 0 {
+			return fmt.Errorf("invalid replica max lag: %d", c.Database.Replicas.MaxLagSecs)
+		}
+		if c.Database.Replicas.CheckIntervalSecs <= 0 {
+			return fmt.Errorf("invalid replica check interval: %d", c.Database.Replicas.CheckIntervalSecs)
+		}
+	}
+
+	if c.Retention.DeletedRetentionHours < 0 {
+		return fmt.Errorf("invalid deleted retention hours: %d", c.Retention.DeletedRetentionHours)
+	}
+
+	if c.Retention.PurgeIntervalMins < 0 {
+		return fmt.Errorf("invalid purge interval: %d", c.Retention.PurgeIntervalMins)
+	}
+
+	if c.Retention.PurgeBatchSize <= 0 {
+		return fmt.Errorf("invalid purge batch size: %d", c.Retention.PurgeBatchSize)
+	}
+
+	if c.Stats.AggregationIntervalSecs < 0 {
+		return fmt.Errorf("invalid stats aggregation interval: %d", c.Stats.AggregationIntervalSecs)
+	}
+
+	if c.Cache.Enabled {
+		if c.Cache.TTLSeconds <= 0 {
+			return fmt.Errorf("invalid cache ttl: %d", c.Cache.TTLSeconds)
+		}
+		if c.Cache.Redis.Host == "" {
+			return fmt.Errorf("cache redis host is required")
+		}
+	}
+
+	if c.FaultInjection.Enabled {
+		if c.FaultInjection.MockErrorURL == "" {
+			return fmt.Errorf("fault injection mock error URL is required")
+		}
+		if _, err := time.ParseDuration(c.FaultInjection.CheckTimeout); err != nil {
+			return fmt.Errorf("invalid fault injection check timeout: %w", err)
+		}
+	}
+
+	if c.FlightRecorder.Enabled {
+		if c.FlightRecorder.StorageURL == "" {
+			return fmt.Errorf("flight recorder storage URL is required")
+		}
+		if c.FlightRecorder.Bucket == "" {
+			return fmt.Errorf("flight recorder bucket is required")
+		}
+		if c.FlightRecorder.MaxErrorRate < 0 || c.FlightRecorder.MaxErrorRate > 1 ||
+			c.FlightRecorder.MaxSlowRate < 0 || c.FlightRecorder.MaxSlowRate > 1 {
+			return fmt.Errorf("flight recorder rates must be between 0 and 1")
+		}
+	}
+
+	if c.RBAC.Enabled {
+		if c.RBAC.Header == "" {
+			return fmt.Errorf("rbac header is required")
+		}
+		for _, key := range c.RBAC.Keys {
+			if key.Name == "" || key.Key == "" {
+				return fmt.Errorf("rbac api key name and key are required")
+			}
+			if !models.IsValidRole(key.Role) {
+				return fmt.Errorf("rbac api key %s: unknown role %q", key.Name, key.Role)
+			}
+			if key.Tenant != "" {
+				if err := models.ValidateTenantID(key.Tenant); err != nil {
+					return fmt.Errorf("rbac api key %s: %w", key.Name, err)
+				}
+			}
+		}
+	}
+
+	if c.Tenancy.Enabled {
+		if c.Tenancy.Header == "" {
+			return fmt.Errorf("tenancy header is required")
+		}
+		if err := models.ValidateTenantID(c.Tenancy.DefaultTenant); err != nil {
+			return fmt.Errorf("invalid default tenant: %w", err)
+		}
+	}
+
+	if c.Events.Enabled {
+		if c.Events.QueueURL == "" {
+			return fmt.Errorf("events queue URL is required")
+		}
+		if c.Events.Topic == "" {
+			return fmt.Errorf("events topic is required")
+		}
+		if _, err := time.ParseDuration(c.Events.Timeout); err != nil {
+			return fmt.Errorf("invalid events timeout: %w", err)
+		}
+	}
+
+	if c.Expiry.Enabled {
+		if c.Expiry.QueueURL == "" && !c.Expiry.DryRun {
+			return fmt.Errorf("expiry queue URL is required")
+		}
+		if _, err := time.ParseDuration(c.Expiry.Timeout); err != nil {
+			return fmt.Errorf("invalid expiry timeout: %w", err)
+		}
+		if c.Expiry.IntervalSecs < 0 {
+			return fmt.Errorf("expiry interval must not be negative")
+		}
+		if c.Expiry.BatchSize <= 0 {
+			return fmt.Errorf("expiry batch size must be positive")
+		}
+		if c.Expiry.MaxTasksPerRun < 0 {
+			return fmt.Errorf("expiry max tasks per run must not be negative")
+		}
+	}
+
+	switch c.Search.Backend {
+	case "postgres":
+		if c.Database.Driver != "postgres" {
+			return fmt.Errorf("search backend postgres requires postgres database driver, use memory with %s", c.Database.Driver)
+		}
+	case "memory":
+	default:
+		return fmt.Errorf("unsupported search backend: %s", c.Search.Backend)
+	}
+
 	return nil
 }
@@ -0,0 +1,181 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// DefaultMaxDepth 默认最大查询嵌套深度
+const DefaultMaxDepth = 10
+
+// Schema GraphQL schema，解析、校验和执行使用graphql-go
+type Schema struct {
+	MaxDepth int
+
+	schema gql.Schema
+}
+
+// Request GraphQL请求
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response GraphQL响应，请求级错误时data为null
+type Response = gql.Result
+
+// Execute 执行查询：解析、按规范校验、检查嵌套深度后执行
+func (s *Schema) Execute(ctx context.Context, req *Request) *Response {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &Response{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	// 先单独检查片段循环引用：graphql-go的OverlappingFieldsCanBeMerged规则遇到循环引用时无限递归
+	if result := gql.ValidateDocument(&s.schema, doc, []gql.ValidationRuleFn{gql.NoFragmentCyclesRule}); !result.IsValid {
+		return &Response{Errors: result.Errors}
+	}
+	if result := gql.ValidateDocument(&s.schema, doc, gql.SpecifiedRules); !result.IsValid {
+		return &Response{Errors: result.Errors}
+	}
+
+	if err := checkDepth(doc, s.MaxDepth); err != nil {
+		return &Response{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	return gql.Execute(gql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+// checkDepth 检查每个操作的选择集嵌套深度，片段展开计入所在位置的深度
+//
+// 片段循环引用已由校验规则拒绝。
+func checkDepth(doc *ast.Document, maxDepth int) error {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	var depth func(set *ast.SelectionSet) int
+	depth = func(set *ast.SelectionSet) int {
+		if set == nil {
+			return 0
+		}
+		deepest := 0
+		for _, selection := range set.Selections {
+			var d int
+			switch selection := selection.(type) {
+			case *ast.Field:
+				if selection.SelectionSet != nil {
+					d = 1 + depth(selection.SelectionSet)
+				}
+			case *ast.InlineFragment:
+				d = depth(selection.SelectionSet)
+			case *ast.FragmentSpread:
+				if fragment, ok := fragments[selection.Name.Value]; ok {
+					d = depth(fragment.SelectionSet)
+				}
+			}
+			deepest = max(deepest, d)
+		}
+		return deepest
+	}
+
+	for _, def := range doc.Definitions {
+		if operation, ok := def.(*ast.OperationDefinition); ok {
+			if depth(operation.SelectionSet) >= maxDepth {
+				return fmt.Errorf("query exceeds maximum depth of %d", maxDepth)
+			}
+		}
+	}
+	return nil
+}
+
+// SDL 以GraphQL SDL格式输出schema中的对象类型和自定义标量
+func (s *Schema) SDL() string {
+	query := s.schema.QueryType()
+	var names []string
+	for name, t := range s.schema.TypeMap() {
+		if strings.HasPrefix(name, "__") || name == query.Name() {
+			continue
+		}
+		if _, ok := t.(*gql.Object); ok || isCustomScalar(t) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{query.Name()}, names...)
+
+	var sb strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		t := s.schema.Type(name)
+		if t.Description() != "" {
+			fmt.Fprintf(&sb, "# %s\n", t.Description())
+		}
+		object, ok := t.(*gql.Object)
+		if !ok {
+			fmt.Fprintf(&sb, "scalar %s\n", name)
+			continue
+		}
+		fmt.Fprintf(&sb, "type %s {\n", name)
+
+		fields := object.Fields()
+		fieldNames := make([]string, 0, len(fields))
+		for fieldName := range fields {
+			fieldNames = append(fieldNames, fieldName)
+		}
+		sort.Strings(fieldNames)
+
+		for _, fieldName := range fieldNames {
+			field := fields[fieldName]
+			if field.Description != "" {
+				fmt.Fprintf(&sb, "  # %s\n", field.Description)
+			}
+			sb.WriteString("  " + fieldName)
+			if len(field.Args) > 0 {
+				args := make([]string, len(field.Args))
+				for j, arg := range field.Args {
+					args[j] = arg.Name() + ": " + arg.Type.String()
+				}
+				sort.Strings(args)
+				sb.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			sb.WriteString(": " + field.Type.String() + "\n")
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+// isCustomScalar 是否为schema自定义的标量（内置标量不输出）
+func isCustomScalar(t gql.Type) bool {
+	if _, ok := t.(*gql.Scalar); !ok {
+		return false
+	}
+	switch t.Name() {
+	case "String", "Int", "Float", "Boolean", "ID":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,402 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"mocks3/shared/interfaces"
+	"mocks3/shared/models"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// bucketNode Bucket类型的解析源
+type bucketNode struct {
+	Name        string
+	ObjectCount int64
+}
+
+// keyValue KeyValue类型的解析源
+type keyValue struct {
+	Key   string
+	Value string
+}
+
+// countNode Count类型的解析源
+type countNode struct {
+	Name  string
+	Count int64
+}
+
+// longType 64位整数标量，用于可能超过Int（32位）范围的字节数
+var longType = gql.NewScalar(gql.ScalarConfig{
+	Name:        "Long",
+	Description: "64位整数，用于字节数",
+	Serialize: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case int64:
+			return v
+		case int:
+			return int64(v)
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		if v, ok := value.(float64); ok && v == math.Trunc(v) {
+			return int64(v)
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if v, ok := valueAST.(*ast.IntValue); ok {
+			var n int64
+			if _, err := fmt.Sscan(v.Value, &n); err == nil {
+				return n
+			}
+		}
+		return nil
+	},
+})
+
+// NewMetadataSchema 创建元数据服务的GraphQL schema
+//
+// 顶层查询：object、objects、buckets、bucket、stats、tags；
+// Object.history、Bucket.objects等嵌套字段按需解析，未选择的字段不会查询数据库。
+func NewMetadataSchema(service interfaces.MetadataService) *Schema {
+	keyValueType := gql.NewObject(gql.ObjectConfig{
+		Name: "KeyValue",
+		Fields: gql.Fields{
+			"key":   {Type: gql.NewNonNull(gql.String), Resolve: prop(func(kv *keyValue) interface{} { return kv.Key })},
+			"value": {Type: gql.NewNonNull(gql.String), Resolve: prop(func(kv *keyValue) interface{} { return kv.Value })},
+		},
+	})
+
+	countType := gql.NewObject(gql.ObjectConfig{
+		Name: "Count",
+		Fields: gql.Fields{
+			"name":  {Type: gql.NewNonNull(gql.String), Resolve: prop(func(c *countNode) interface{} { return c.Name })},
+			"count": {Type: gql.NewNonNull(gql.Int), Resolve: prop(func(c *countNode) interface{} { return c.Count })},
+		},
+	})
+
+	objectFields := func(versioned bool) gql.Fields {
+		fields := gql.Fields{
+			"id":           {Type: gql.NewNonNull(gql.ID), Resolve: metadataProp(func(m *models.Metadata) interface{} { return m.ID })},
+			"bucket":       {Type: gql.NewNonNull(gql.String), Resolve: metadataProp(func(m *models.Metadata) interface{} { return m.Bucket })},
+			"key":          {Type: gql.NewNonNull(gql.String), Resolve: metadataProp(func(m *models.Metadata) interface{} { return m.Key })},
+			"size":         {Type: gql.NewNonNull(longType), Resolve: metadataProp(func(m *models.Metadata) interface{} { return m.Size })},
+			"contentType":  {Type: gql.String, Resolve: metadataProp(func(m *models.Metadata) interface{} { return m.ContentType })},
+			"md5Hash":      {Type: gql.String, Resolve: metadataProp(func(m *models.Metadata) interface{} { return m.MD5Hash })},
+			"etag":         {Type: gql.String, Resolve: metadataProp(func(m *models.Metadata) interface{} { return m.ETag })},
+			"status":       {Type: gql.String, Resolve: metadataProp(func(m *models.Metadata) interface{} { return m.Status })},
+			"version":      {Type: gql.NewNonNull(gql.Int), Resolve: metadataProp(func(m *models.Metadata) interface{} { return m.Version })},
+			"storageNodes": {Type: gql.NewList(gql.NewNonNull(gql.String)), Resolve: metadataProp(func(m *models.Metadata) interface{} { return m.StorageNodes })},
+			"createdAt":    {Type: gql.String, Resolve: metadataProp(func(m *models.Metadata) interface{} { return formatTime(m.CreatedAt) })},
+			"updatedAt":    {Type: gql.String, Resolve: metadataProp(func(m *models.Metadata) interface{} { return formatTime(m.UpdatedAt) })},
+			"headers":      {Type: gql.NewList(gql.NewNonNull(keyValueType)), Resolve: metadataProp(func(m *models.Metadata) interface{} { return sortedKeyValues(m.Headers) })},
+			"tags":         {Type: gql.NewList(gql.NewNonNull(keyValueType)), Resolve: metadataProp(func(m *models.Metadata) interface{} { return sortedKeyValues(m.Tags) })},
+			"tag": {
+				Type:        gql.String,
+				Args:        gql.FieldConfigArgument{"name": {Type: gql.NewNonNull(gql.String)}},
+				Description: "单个标签的值",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					m := toMetadata(p.Source)
+					value, ok := m.Tags[argString(p.Args, "name")]
+					if !ok {
+						return nil, nil
+					}
+					return value, nil
+				},
+			},
+		}
+		if versioned {
+			fields["archivedAt"] = &gql.Field{Type: gql.String, Resolve: prop(func(v *models.MetadataVersion) interface{} { return formatTime(v.ArchivedAt) })}
+		}
+		return fields
+	}
+
+	versionType := gql.NewObject(gql.ObjectConfig{
+		Name:        "ObjectVersion",
+		Description: "对象的历史版本",
+		Fields:      objectFields(true),
+	})
+
+	objectType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Object",
+		Description: "对象元数据",
+		Fields:      objectFields(false),
+	})
+	objectType.AddFieldConfig("history", &gql.Field{
+		Type:        gql.NewList(gql.NewNonNull(versionType)),
+		Args:        gql.FieldConfigArgument{"limit": {Type: gql.Int}, "offset": {Type: gql.Int}},
+		Description: "历史版本，新的在前",
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			m := toMetadata(p.Source)
+			return service.GetMetadataHistory(p.Context, m.Bucket, m.Key, argInt(p.Args, "limit", 10), argInt(p.Args, "offset", 0))
+		},
+	})
+
+	pageType := gql.NewObject(gql.ObjectConfig{
+		Name:        "ObjectPage",
+		Description: "游标分页的对象列表",
+		Fields: gql.Fields{
+			"items":       {Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(objectType))), Resolve: prop(func(p *models.MetadataPage) interface{} { return p.Metadata })},
+			"count":       {Type: gql.NewNonNull(gql.Int), Resolve: prop(func(p *models.MetadataPage) interface{} { return p.Count })},
+			"isTruncated": {Type: gql.NewNonNull(gql.Boolean), Resolve: prop(func(p *models.MetadataPage) interface{} { return p.IsTruncated })},
+			"nextCursor":  {Type: gql.String, Resolve: prop(func(p *models.MetadataPage) interface{} { return p.NextContinuationToken })},
+		},
+	})
+
+	listObjects := func(p gql.ResolveParams, bucket string) (interface{}, error) {
+		filter := &models.MetadataFilter{
+			Bucket:      bucket,
+			Prefix:      argString(p.Args, "prefix"),
+			ContentType: argString(p.Args, "contentType"),
+		}
+		limit := argInt(p.Args, "limit", 100)
+		if query := argString(p.Args, "tagQuery"); query != "" {
+			return service.QueryMetadataByTags(p.Context, query, filter, argString(p.Args, "after"), limit)
+		}
+		return service.ListMetadataPage(p.Context, filter, argString(p.Args, "after"), limit)
+	}
+
+	objectsArgs := func() gql.FieldConfigArgument {
+		return gql.FieldConfigArgument{
+			"prefix":      {Type: gql.String},
+			"contentType": {Type: gql.String},
+			"tagQuery":    {Type: gql.String},
+			"after":       {Type: gql.String},
+			"limit":       {Type: gql.Int},
+		}
+	}
+
+	tagCountType := gql.NewObject(gql.ObjectConfig{
+		Name:        "TagCount",
+		Description: "标签键值对及使用该标签的对象数",
+		Fields: gql.Fields{
+			"key":   {Type: gql.NewNonNull(gql.String), Resolve: prop(func(t *models.TagCount) interface{} { return t.Key })},
+			"value": {Type: gql.NewNonNull(gql.String), Resolve: prop(func(t *models.TagCount) interface{} { return t.Value })},
+			"count": {Type: gql.NewNonNull(gql.Int), Resolve: prop(func(t *models.TagCount) interface{} { return t.Count })},
+		},
+	})
+
+	bucketType := gql.NewObject(gql.ObjectConfig{
+		Name: "Bucket",
+		Fields: gql.Fields{
+			"name":        {Type: gql.NewNonNull(gql.String), Resolve: prop(func(b *bucketNode) interface{} { return b.Name })},
+			"objectCount": {Type: gql.NewNonNull(gql.Int), Resolve: prop(func(b *bucketNode) interface{} { return b.ObjectCount })},
+			"count": {
+				Type:        gql.NewNonNull(gql.Int),
+				Args:        gql.FieldConfigArgument{"prefix": {Type: gql.String}},
+				Description: "指定前缀下的对象数",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return service.CountObjects(p.Context, p.Source.(*bucketNode).Name, argString(p.Args, "prefix"))
+				},
+			},
+			"objects": {
+				Type: gql.NewNonNull(pageType),
+				Args: objectsArgs(),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return listObjects(p, p.Source.(*bucketNode).Name)
+				},
+			},
+			"tags": {
+				Type: gql.NewList(gql.NewNonNull(tagCountType)),
+				Args: gql.FieldConfigArgument{"limit": {Type: gql.Int}},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return service.ListTagCounts(p.Context, p.Source.(*bucketNode).Name, argInt(p.Args, "limit", 100))
+				},
+			},
+		},
+	})
+
+	dailyUploadType := gql.NewObject(gql.ObjectConfig{
+		Name: "DailyUpload",
+		Fields: gql.Fields{
+			"date":  {Type: gql.NewNonNull(gql.String), Resolve: prop(func(d models.DailyUploadStat) interface{} { return d.Date })},
+			"count": {Type: gql.NewNonNull(gql.Int), Resolve: prop(func(d models.DailyUploadStat) interface{} { return d.Count })},
+			"size":  {Type: gql.NewNonNull(longType), Resolve: prop(func(d models.DailyUploadStat) interface{} { return d.Size })},
+		},
+	})
+
+	statsType := gql.NewObject(gql.ObjectConfig{
+		Name: "Stats",
+		Fields: gql.Fields{
+			"totalObjects": {Type: gql.NewNonNull(gql.Int), Resolve: prop(func(s *models.Stats) interface{} { return s.TotalObjects })},
+			"totalSize":    {Type: gql.NewNonNull(longType), Resolve: prop(func(s *models.Stats) interface{} { return s.TotalSize })},
+			"averageSize":  {Type: gql.NewNonNull(gql.Float), Resolve: prop(func(s *models.Stats) interface{} { return s.AverageSize })},
+			"lastUpdated":  {Type: gql.String, Resolve: prop(func(s *models.Stats) interface{} { return formatTime(s.LastUpdated) })},
+			"buckets":      {Type: gql.NewList(gql.NewNonNull(bucketType)), Resolve: prop(func(s *models.Stats) interface{} { return bucketNodes(s.BucketStats) })},
+			"contentTypes": {Type: gql.NewList(gql.NewNonNull(countType)), Resolve: prop(func(s *models.Stats) interface{} { return sortedCounts(s.ContentTypes) })},
+			"storageNodes": {Type: gql.NewList(gql.NewNonNull(countType)), Resolve: prop(func(s *models.Stats) interface{} { return sortedCounts(s.StorageNodes) })},
+			"statusCounts": {Type: gql.NewList(gql.NewNonNull(countType)), Resolve: prop(func(s *models.Stats) interface{} { return sortedCounts(s.StatusCounts) })},
+			"dailyUploads": {Type: gql.NewList(gql.NewNonNull(dailyUploadType)), Resolve: prop(func(s *models.Stats) interface{} { return s.DailyUploads })},
+		},
+	})
+
+	queryArgs := objectsArgs()
+	queryArgs["bucket"] = &gql.ArgumentConfig{Type: gql.String}
+
+	queryType := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"object": {
+				Type:        objectType,
+				Args:        gql.FieldConfigArgument{"bucket": {Type: gql.NewNonNull(gql.String)}, "key": {Type: gql.NewNonNull(gql.String)}},
+				Description: "按bucket和key获取对象，不存在时返回null",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					metadata, err := service.GetMetadata(p.Context, argString(p.Args, "bucket"), argString(p.Args, "key"))
+					if errors.Is(err, models.ErrMetadataNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+					return metadata, nil
+				},
+			},
+			"objects": {
+				Type:        gql.NewNonNull(pageType),
+				Args:        queryArgs,
+				Description: "列出对象，tagQuery使用标签查询语言（如 env=prod AND team!=core）",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return listObjects(p, argString(p.Args, "bucket"))
+				},
+			},
+			"buckets": {
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(bucketType))),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					stats, err := service.GetStats(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					return bucketNodes(stats.BucketStats), nil
+				},
+			},
+			"bucket": {
+				Type:        bucketType,
+				Args:        gql.FieldConfigArgument{"name": {Type: gql.NewNonNull(gql.String)}},
+				Description: "获取bucket，没有对象时返回null",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					name := argString(p.Args, "name")
+					count, err := service.CountObjects(p.Context, name, "")
+					if err != nil {
+						return nil, err
+					}
+					if count == 0 {
+						return nil, nil
+					}
+					return &bucketNode{Name: name, ObjectCount: count}, nil
+				},
+			},
+			"stats": {
+				Type: gql.NewNonNull(statsType),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return service.GetStats(p.Context)
+				},
+			},
+			"tags": {
+				Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(tagCountType))),
+				Args:        gql.FieldConfigArgument{"bucket": {Type: gql.String}, "limit": {Type: gql.Int}},
+				Description: "标签键值对使用次数，按次数降序",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return service.ListTagCounts(p.Context, argString(p.Args, "bucket"), argInt(p.Args, "limit", 100))
+				},
+			},
+		},
+	})
+
+	schema, err := gql.NewSchema(gql.SchemaConfig{Query: queryType})
+	if err != nil {
+		// schema在代码中静态定义，构建失败是编程错误
+		panic(fmt.Sprintf("invalid metadata GraphQL schema: %v", err))
+	}
+	return &Schema{MaxDepth: DefaultMaxDepth, schema: schema}
+}
+
+// prop 将类型化的取值函数包装为解析函数
+func prop[T any](get func(T) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		typed, ok := p.Source.(T)
+		if !ok {
+			return nil, fmt.Errorf("unexpected source type %T", p.Source)
+		}
+		return get(typed), nil
+	}
+}
+
+// metadataProp Object与ObjectVersion共用的取值函数
+func metadataProp(get func(*models.Metadata) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		return get(toMetadata(p.Source)), nil
+	}
+}
+
+// toMetadata 从Object或ObjectVersion解析源取出元数据
+func toMetadata(source interface{}) *models.Metadata {
+	switch v := source.(type) {
+	case *models.MetadataVersion:
+		return &v.Metadata
+	case *models.Metadata:
+		return v
+	}
+	return &models.Metadata{}
+}
+
+func argString(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+func argInt(args map[string]interface{}, name string, defaultValue int) int {
+	if value, ok := args[name].(int); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func formatTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// sortedKeyValues 按键排序的键值对列表
+func sortedKeyValues(m map[string]string) []*keyValue {
+	result := make([]*keyValue, 0, len(m))
+	for k, v := range m {
+		result = append(result, &keyValue{Key: k, Value: v})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// sortedCounts 按计数降序的计数列表
+func sortedCounts(m map[string]int64) []*countNode {
+	result := make([]*countNode, 0, len(m))
+	for name, count := range m {
+		result = append(result, &countNode{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// bucketNodes 按名称排序的bucket列表
+func bucketNodes(bucketStats map[string]int64) []*bucketNode {
+	result := make([]*bucketNode, 0, len(bucketStats))
+	for name, count := range bucketStats {
+		result = append(result, &bucketNode{Name: name, ObjectCount: count})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
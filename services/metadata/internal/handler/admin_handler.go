@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MigrationReporter 数据库迁移状态查询
+type MigrationReporter interface {
+	MigrationStatus(ctx context.Context) (*models.MigrationReport, error)
+}
+
+// ReplicaReporter 只读副本路由状态查询
+type ReplicaReporter interface {
+	ReplicaStatus(ctx context.Context) (*models.ReplicaReport, error)
+}
+
+// AuditReporter 元数据变更审计查询
+type AuditReporter interface {
+	ListMetadataAudit(ctx context.Context, query *models.MetadataAuditQuery) (*models.MetadataAuditPage, error)
+}
+
+// AdminHandler 运维管理处理器
+type AdminHandler struct {
+	migrations MigrationReporter
+	replicas   ReplicaReporter
+	audit      AuditReporter
+	logger     *observability.Logger
+}
+
+// NewAdminHandler 创建运维管理处理器
+func NewAdminHandler(migrations MigrationReporter, replicas ReplicaReporter, audit AuditReporter, logger *observability.Logger) *AdminHandler {
+	return &AdminHandler{
+		migrations: migrations,
+		replicas:   replicas,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *AdminHandler) RegisterRoutes(router *gin.Engine) {
+	admin := router.Group("/admin")
+	{
+		admin.GET("/migrations", h.GetMigrations)
+		admin.GET("/replicas", h.GetReplicas)
+		admin.GET("/audit", h.GetAudit)
+	}
+}
+
+// GetMigrations 获取数据库schema迁移状态
+func (h *AdminHandler) GetMigrations(c *gin.Context) {
+	report, err := h.migrations.MigrationStatus(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get migration status", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to get migration status: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// GetReplicas 获取只读副本的可用性与复制延迟
+func (h *AdminHandler) GetReplicas(c *gin.Context) {
+	report, err := h.replicas.ReplicaStatus(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get replica status", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to get replica status: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// GetAudit 查询元数据变更审计记录（?bucket=&key=&action=&since=&until=&after=&limit=）
+func (h *AdminHandler) GetAudit(c *gin.Context) {
+	query := &models.MetadataAuditQuery{
+		Bucket: c.Query("bucket"),
+		Key:    c.Query("key"),
+		Action: c.Query("action"),
+	}
+
+	var err error
+	if query.Since, err = parseOptionalTime(c.Query("since")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid since parameter, expected RFC3339")
+		return
+	}
+	if query.Until, err = parseOptionalTime(c.Query("until")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid until parameter, expected RFC3339")
+		return
+	}
+	if value := c.Query("after"); value != "" {
+		if query.After, err = strconv.ParseInt(value, 10, 64); err != nil {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid after parameter")
+			return
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		if query.Limit, err = strconv.Atoi(value); err != nil {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+	}
+
+	page, err := h.audit.ListMetadataAudit(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list metadata audit", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to list metadata audit: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    page,
+	})
+}
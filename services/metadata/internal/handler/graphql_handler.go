@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"mocks3/services/metadata/internal/graphql"
+	"mocks3/shared/interfaces"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQLHandler GraphQL查询处理器
+type GraphQLHandler struct {
+	schema *graphql.Schema
+	logger *observability.Logger
+}
+
+// NewGraphQLHandler 创建GraphQL查询处理器
+func NewGraphQLHandler(service interfaces.MetadataService, logger *observability.Logger) *GraphQLHandler {
+	return &GraphQLHandler{
+		schema: graphql.NewMetadataSchema(service),
+		logger: logger,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *GraphQLHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.POST("/graphql", h.Query)
+		v1.GET("/graphql", h.Query)
+		v1.GET("/graphql/schema", h.GetSchema)
+	}
+}
+
+// Query 执行GraphQL查询，POST使用JSON请求体，GET使用query/variables/operationName参数
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graphql.Request
+	if c.Request.Method == http.MethodPost {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+	} else {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid variables parameter: "+err.Error())
+				return
+			}
+		}
+	}
+
+	if req.Query == "" {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Query is required")
+		return
+	}
+
+	resp := h.schema.Execute(c.Request.Context(), &req)
+	if len(resp.Errors) > 0 {
+		h.logger.WarnContext(c.Request.Context(), "GraphQL query returned errors",
+			"operation", req.OperationName, "errors", len(resp.Errors), "first_error", resp.Errors[0].Message)
+	}
+
+	// 请求级错误（解析失败、变量缺失等）没有data，返回400；字段级错误随部分结果返回200
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, resp)
+}
+
+// GetSchema 以SDL格式返回schema
+func (h *GraphQLHandler) GetSchema(c *gin.Context) {
+	c.String(http.StatusOK, h.schema.SDL())
+}
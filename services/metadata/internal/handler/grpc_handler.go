@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"mocks3/shared/interfaces"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/proto/metadatapb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCHandler 元数据gRPC接口，与HTTP接口共用同一个MetadataService
+type GRPCHandler struct {
+	metadatapb.UnimplementedMetadataServiceServer
+	service interfaces.MetadataService
+	logger  *observability.Logger
+}
+
+// NewGRPCHandler 创建元数据gRPC处理器
+func NewGRPCHandler(service interfaces.MetadataService, logger *observability.Logger) *GRPCHandler {
+	return &GRPCHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Register 注册到gRPC服务器
+func (h *GRPCHandler) Register(server *grpc.Server) {
+	metadatapb.RegisterMetadataServiceServer(server, h)
+}
+
+// SaveMetadata 保存元数据
+func (h *GRPCHandler) SaveMetadata(ctx context.Context, req *metadatapb.SaveMetadataRequest) (*metadatapb.SaveMetadataResponse, error) {
+	if req.GetMetadata() == nil {
+		return nil, status.Error(codes.InvalidArgument, "metadata is required")
+	}
+
+	metadata := req.GetMetadata().ToModel()
+	if err := h.service.SaveMetadata(ctx, metadata); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to save metadata via gRPC", "error", err)
+		return nil, grpcError(err)
+	}
+	return &metadatapb.SaveMetadataResponse{Metadata: metadatapb.FromModel(metadata)}, nil
+}
+
+// GetMetadata 获取元数据
+func (h *GRPCHandler) GetMetadata(ctx context.Context, req *metadatapb.GetMetadataRequest) (*metadatapb.GetMetadataResponse, error) {
+	metadata, err := h.service.GetMetadata(ctx, req.GetBucket(), req.GetKey())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &metadatapb.GetMetadataResponse{Metadata: metadatapb.FromModel(metadata)}, nil
+}
+
+// UpdateMetadata 更新元数据
+func (h *GRPCHandler) UpdateMetadata(ctx context.Context, req *metadatapb.UpdateMetadataRequest) (*metadatapb.UpdateMetadataResponse, error) {
+	if req.GetMetadata() == nil {
+		return nil, status.Error(codes.InvalidArgument, "metadata is required")
+	}
+
+	metadata := req.GetMetadata().ToModel()
+	if err := h.service.UpdateMetadata(ctx, metadata); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to update metadata via gRPC", "error", err)
+		return nil, grpcError(err)
+	}
+	return &metadatapb.UpdateMetadataResponse{Metadata: metadatapb.FromModel(metadata)}, nil
+}
+
+// DeleteMetadata 删除元数据
+func (h *GRPCHandler) DeleteMetadata(ctx context.Context, req *metadatapb.DeleteMetadataRequest) (*metadatapb.DeleteMetadataResponse, error) {
+	if err := h.service.DeleteMetadata(ctx, req.GetBucket(), req.GetKey()); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to delete metadata via gRPC",
+			"bucket", req.GetBucket(), "key", req.GetKey(), "error", err)
+		return nil, grpcError(err)
+	}
+	return &metadatapb.DeleteMetadataResponse{}, nil
+}
+
+// ListMetadata 按过滤条件分页列出元数据
+func (h *GRPCHandler) ListMetadata(ctx context.Context, req *metadatapb.ListMetadataRequest) (*metadatapb.ListMetadataResponse, error) {
+	page, err := h.service.ListMetadataPage(ctx, req.GetFilter().ToModel(), req.GetContinuationToken(), int(req.GetLimit()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &metadatapb.ListMetadataResponse{
+		Metadata:              metadatapb.FromModels(page.Metadata),
+		IsTruncated:           page.IsTruncated,
+		NextContinuationToken: page.NextContinuationToken,
+	}, nil
+}
+
+// CountObjects 计算对象数量
+func (h *GRPCHandler) CountObjects(ctx context.Context, req *metadatapb.CountObjectsRequest) (*metadatapb.CountObjectsResponse, error) {
+	count, err := h.service.CountObjects(ctx, req.GetBucket(), req.GetPrefix())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &metadatapb.CountObjectsResponse{Count: count}, nil
+}
+
+// FindMetadataByHash 查找内容相同的对象
+func (h *GRPCHandler) FindMetadataByHash(ctx context.Context, req *metadatapb.FindMetadataByHashRequest) (*metadatapb.FindMetadataByHashResponse, error) {
+	result, err := h.service.FindMetadataByHash(ctx, req.GetHash(), req.GetSize(), int(req.GetLimit()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &metadatapb.FindMetadataByHashResponse{Metadata: metadatapb.FromModels(result)}, nil
+}
+
+// ValidateMetadataSchema 按bucket的schema校验元数据（不写入）
+func (h *GRPCHandler) ValidateMetadataSchema(ctx context.Context, req *metadatapb.ValidateMetadataSchemaRequest) (*metadatapb.ValidateMetadataSchemaResponse, error) {
+	if req.GetMetadata() == nil {
+		return nil, status.Error(codes.InvalidArgument, "metadata is required")
+	}
+
+	if err := h.service.ValidateMetadataSchema(ctx, req.GetMetadata().ToModel()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &metadatapb.ValidateMetadataSchemaResponse{}, nil
+}
+
+// grpcError 按错误类型映射gRPC状态码（与HTTP接口的状态码判断一致）
+func grpcError(err error) error {
+	var violation *models.RetentionViolationError
+	if errors.As(err, &violation) {
+		return metadatapb.RetentionViolationStatus(violation).Err()
+	}
+	var validationErr *models.SchemaValidationError
+	if errors.As(err, &validationErr) {
+		return metadatapb.SchemaViolationStatus(validationErr).Err()
+	}
+
+	message := err.Error()
+	switch {
+	case errors.Is(err, models.ErrMetadataNotFound):
+		return status.Error(codes.NotFound, message)
+	case errors.Is(err, models.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, message)
+	default:
+		return status.Error(codes.Internal, message)
+	}
+}
+
+// 确保实现了接口
+var _ metadatapb.MetadataServiceServer = (*GRPCHandler)(nil)
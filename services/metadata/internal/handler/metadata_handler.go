@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"mocks3/shared/interfaces"
 	"mocks3/shared/models"
@@ -32,17 +35,55 @@ func (h *MetadataHandler) RegisterRoutes(router *gin.Engine) {
 	{
 		// 元数据CRUD操作
 		v1.POST("/metadata", h.CreateMetadata)
+		v1.POST("/metadata/batch", h.BatchMetadata)
+		v1.POST("/metadata/validate", h.ValidateMetadataSchema)
 		v1.GET("/metadata/:bucket/:key", h.GetMetadata)
 		v1.PUT("/metadata/:bucket/:key", h.UpdateMetadata)
 		v1.DELETE("/metadata/:bucket/:key", h.DeleteMetadata)
+		v1.POST("/metadata/:bucket/:key/restore", h.RestoreMetadata)
+
+		// 版本历史
+		v1.GET("/metadata/:bucket/:key/history", h.GetMetadataHistory)
+		v1.POST("/metadata/:bucket/:key/history/:version/restore", h.RestoreMetadataVersion)
 
 		// 列表和搜索
 		v1.GET("/metadata", h.ListMetadata)
 		v1.GET("/metadata/search", h.SearchMetadata)
+		v1.GET("/search", h.SearchObjects)
+		v1.GET("/metadata/shards", h.ShardKeyspace)
+		v1.GET("/metadata/tags", h.QueryMetadataByTags)
+
+		// 重复内容
+		v1.GET("/metadata/duplicates", h.FindDuplicates)
+		v1.GET("/metadata/by-hash", h.FindMetadataByHash)
+
+		// 导出导入（环境克隆）
+		v1.GET("/metadata/export", h.ExportMetadata)
+		v1.POST("/metadata/import", h.ImportMetadata)
 
 		// 统计信息
 		v1.GET("/stats", h.GetStats)
+		v1.POST("/stats/refresh", h.RefreshStats)
 		v1.GET("/metadata/count", h.CountObjects)
+		v1.GET("/metadata/usage", h.GetUsage)
+		v1.GET("/tenants", h.ListTenants)
+
+		// bucket保留策略
+		v1.GET("/retention", h.ListBucketRetention)
+		v1.GET("/retention/:bucket", h.GetBucketRetention)
+		v1.PUT("/retention/:bucket", h.PutBucketRetention)
+		v1.DELETE("/retention/:bucket", h.DeleteBucketRetention)
+
+		// bucket自定义元数据schema
+		v1.GET("/schemas", h.ListBucketSchemas)
+		v1.GET("/schemas/:bucket", h.GetBucketSchema)
+		v1.PUT("/schemas/:bucket", h.PutBucketSchema)
+		v1.DELETE("/schemas/:bucket", h.DeleteBucketSchema)
+
+		// 生命周期
+		v1.POST("/lifecycle/preview", h.PreviewLifecycleRule)
+		v1.GET("/lifecycle/expiry", h.GetExpiryScan)
+		v1.POST("/lifecycle/expiry/scan", h.ScanExpiredObjects)
 	}
 }
 
@@ -56,6 +97,9 @@ func (h *MetadataHandler) CreateMetadata(c *gin.Context) {
 	}
 
 	if err := h.service.SaveMetadata(c.Request.Context(), &metadata); err != nil {
+		if h.writeSchemaViolation(c, err) {
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to create metadata", "error", err)
 		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to create metadata: "+err.Error())
 		return
@@ -68,6 +112,32 @@ func (h *MetadataHandler) CreateMetadata(c *gin.Context) {
 	})
 }
 
+// BatchMetadata 批量创建/更新/删除元数据
+func (h *MetadataHandler) BatchMetadata(c *gin.Context) {
+	var req models.MetadataBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Invalid request body", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	result, err := h.service.BatchMetadata(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to execute metadata batch", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to execute batch: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": result.Failed == 0,
+		"data":    result,
+	})
+}
+
 // GetMetadata 获取元数据
 func (h *MetadataHandler) GetMetadata(c *gin.Context) {
 	bucket := c.Param("bucket")
@@ -75,9 +145,13 @@ func (h *MetadataHandler) GetMetadata(c *gin.Context) {
 
 	metadata, err := h.service.GetMetadata(c.Request.Context(), bucket, key)
 	if err != nil {
-		h.logger.WarnContext(c.Request.Context(), "Metadata not found",
+		if errors.Is(err, models.ErrMetadataNotFound) {
+			utils.SetErrorResponse(c.Writer, http.StatusNotFound, "Metadata not found")
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get metadata",
 			"bucket", bucket, "key", key, "error", err)
-		utils.SetErrorResponse(c.Writer, http.StatusNotFound, "Metadata not found")
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to get metadata: "+err.Error())
 		return
 	}
 
@@ -104,6 +178,9 @@ func (h *MetadataHandler) UpdateMetadata(c *gin.Context) {
 	metadata.Key = key
 
 	if err := h.service.UpdateMetadata(c.Request.Context(), &metadata); err != nil {
+		if h.writeSchemaViolation(c, err) {
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to update metadata", "error", err)
 		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to update metadata: "+err.Error())
 		return
@@ -122,6 +199,13 @@ func (h *MetadataHandler) DeleteMetadata(c *gin.Context) {
 	key := c.Param("key")
 
 	if err := h.service.DeleteMetadata(c.Request.Context(), bucket, key); err != nil {
+		if h.writeRetentionViolation(c, err) {
+			return
+		}
+		if errors.Is(err, models.ErrMetadataNotFound) {
+			utils.SetErrorResponse(c.Writer, http.StatusNotFound, err.Error())
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to delete metadata",
 			"bucket", bucket, "key", key, "error", err)
 		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to delete metadata: "+err.Error())
@@ -134,10 +218,112 @@ func (h *MetadataHandler) DeleteMetadata(c *gin.Context) {
 	})
 }
 
+// RestoreMetadata 恢复已删除的元数据
+func (h *MetadataHandler) RestoreMetadata(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := c.Param("key")
+
+	metadata, err := h.service.RestoreMetadata(c.Request.Context(), bucket, key)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrMetadataExists):
+			utils.SetErrorResponse(c.Writer, http.StatusConflict, err.Error())
+		case errors.Is(err, models.ErrMetadataNotFound):
+			utils.SetErrorResponse(c.Writer, http.StatusNotFound, err.Error())
+		default:
+			h.logger.ErrorContext(c.Request.Context(), "Failed to restore metadata",
+				"bucket", bucket, "key", key, "error", err)
+			utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to restore metadata: "+err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    metadata,
+		"message": "Metadata restored successfully",
+	})
+}
+
+// GetMetadataHistory 获取元数据历史版本
+func (h *MetadataHandler) GetMetadataHistory(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := c.Param("key")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid limit parameter")
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid offset parameter")
+		return
+	}
+
+	versions, err := h.service.GetMetadataHistory(c.Request.Context(), bucket, key, limit, offset)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get metadata history",
+			"bucket", bucket, "key", key, "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to get metadata history: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"bucket":   bucket,
+			"key":      key,
+			"versions": versions,
+			"count":    len(versions),
+			"limit":    limit,
+			"offset":   offset,
+		},
+	})
+}
+
+// RestoreMetadataVersion 恢复元数据到指定历史版本
+func (h *MetadataHandler) RestoreMetadataVersion(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := c.Param("key")
+
+	version, err := strconv.ParseInt(c.Param("version"), 10, 64)
+	if err != nil || version <= 0 {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid version parameter")
+		return
+	}
+
+	metadata, err := h.service.RestoreMetadataVersion(c.Request.Context(), bucket, key, version)
+	if err != nil {
+		if errors.Is(err, models.ErrMetadataNotFound) {
+			utils.SetErrorResponse(c.Writer, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to restore metadata version",
+			"bucket", bucket, "key", key, "version", version, "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to restore metadata version: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    metadata,
+		"message": "Metadata version restored successfully",
+	})
+}
+
 // ListMetadata 列出元数据
 func (h *MetadataHandler) ListMetadata(c *gin.Context) {
-	bucket := c.Query("bucket")
-	prefix := c.Query("prefix")
+	filter := &models.MetadataFilter{
+		Bucket:      c.Query("bucket"),
+		Prefix:      c.Query("prefix"),
+		Status:      c.Query("status"),
+		ContentType: c.Query("content_type"),
+		StartAfter:  c.Query("start_after"),
+		EndKey:      c.Query("end_key"),
+		Delimiter:   c.Query("delimiter"),
+	}
 
 	limitStr := c.DefaultQuery("limit", "100")
 	limit, err := strconv.Atoi(limitStr)
@@ -146,15 +332,81 @@ func (h *MetadataHandler) ListMetadata(c *gin.Context) {
 		return
 	}
 
-	offsetStr := c.DefaultQuery("offset", "0")
+	// 标签查询表达式
+	if tagQuery := c.Query("tag_query"); tagQuery != "" {
+		if filter.TagQuery, err = models.ParseTagQuery(tagQuery); err != nil {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// 大小范围（字节，闭区间）
+	if filter.SizeMin, err = parseOptionalInt64(c.Query("size_min")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid size_min parameter")
+		return
+	}
+	if filter.SizeMax, err = parseOptionalInt64(c.Query("size_max")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid size_max parameter")
+		return
+	}
+
+	// 创建时间范围（RFC3339，闭区间）
+	if filter.CreatedFrom, err = parseOptionalTime(c.Query("created_from")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid created_from parameter, expected RFC3339")
+		return
+	}
+	if filter.CreatedTo, err = parseOptionalTime(c.Query("created_to")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid created_to parameter, expected RFC3339")
+		return
+	}
+
+	// 修改时间窗口（RFC3339）
+	if filter.ModifiedAfter, err = parseOptionalTime(c.Query("modified_after")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid modified_after parameter, expected RFC3339")
+		return
+	}
+	if filter.ModifiedBefore, err = parseOptionalTime(c.Query("modified_before")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid modified_before parameter, expected RFC3339")
+		return
+	}
+
+	// 显式指定offset时保留旧的偏移分页，否则使用continuation token游标分页
+	offsetStr, useOffset := c.GetQuery("offset")
+	if !useOffset {
+		page, err := h.service.ListMetadataPage(c.Request.Context(), filter, c.Query("continuation_token"), limit)
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidArgument) {
+				utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+				return
+			}
+			h.logger.ErrorContext(c.Request.Context(), "Failed to list metadata", "error", err)
+			utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to list metadata: "+err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    page,
+		})
+		return
+	}
+
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil {
 		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid offset parameter")
 		return
 	}
+	if filter.Delimiter != "" {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "delimiter requires continuation_token pagination, offset is not supported")
+		return
+	}
 
-	metadataList, err := h.service.ListMetadata(c.Request.Context(), bucket, prefix, limit, offset)
+	metadataList, err := h.service.ListMetadataFiltered(c.Request.Context(), filter, limit, offset)
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to list metadata", "error", err)
 		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to list metadata: "+err.Error())
 		return
@@ -204,6 +456,256 @@ func (h *MetadataHandler) SearchMetadata(c *gin.Context) {
 	})
 }
 
+// SearchObjects 全文搜索（支持过滤和排序）
+func (h *MetadataHandler) SearchObjects(c *gin.Context) {
+	query := &models.MetadataSearchQuery{
+		Query:       c.Query("q"),
+		Bucket:      c.Query("bucket"),
+		ContentType: c.Query("content_type"),
+	}
+
+	// 标签过滤：tag=k:v，可重复
+	for _, tag := range c.QueryArray("tag") {
+		k, v, ok := strings.Cut(tag, ":")
+		if !ok || k == "" {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid tag filter, expected key:value")
+			return
+		}
+		if query.Tags == nil {
+			query.Tags = make(map[string]string)
+		}
+		query.Tags[k] = v
+	}
+
+	var err error
+	if query.SizeMin, err = parseOptionalInt64(c.Query("size_min")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid size_min parameter")
+		return
+	}
+	if query.SizeMax, err = parseOptionalInt64(c.Query("size_max")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid size_max parameter")
+		return
+	}
+
+	if query.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "100")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid limit parameter")
+		return
+	}
+	if query.Offset, err = strconv.Atoi(c.DefaultQuery("offset", "0")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid offset parameter")
+		return
+	}
+
+	result, err := h.service.SearchObjects(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to search objects", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to search objects: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// ShardKeyspace 将bucket键空间切分为多个范围，配合 start_after/end_key 并行列举
+func (h *MetadataHandler) ShardKeyspace(c *gin.Context) {
+	bucket := c.Query("bucket")
+	prefix := c.Query("prefix")
+
+	shards, err := strconv.Atoi(c.DefaultQuery("shards", "4"))
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid shards parameter")
+		return
+	}
+
+	plan, err := h.service.ShardKeyspace(c.Request.Context(), bucket, prefix, shards)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to shard keyspace", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to shard keyspace: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    plan,
+	})
+}
+
+// FindDuplicates 报告内容相同、键不同的重复对象
+func (h *MetadataHandler) FindDuplicates(c *gin.Context) {
+	bucket := c.Query("bucket")
+
+	minCopies, err := strconv.Atoi(c.DefaultQuery("min_copies", "2"))
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid min_copies parameter")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid limit parameter")
+		return
+	}
+
+	report, err := h.service.FindDuplicates(c.Request.Context(), bucket, minCopies, limit)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to find duplicates", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to find duplicates: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// FindMetadataByHash 查找内容哈希和大小相同的对象（写入去重使用）
+func (h *MetadataHandler) FindMetadataByHash(c *gin.Context) {
+	hash := c.Query("hash")
+	size, err := strconv.ParseInt(c.Query("size"), 10, 64)
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid size parameter")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid limit parameter")
+		return
+	}
+
+	result, err := h.service.FindMetadataByHash(c.Request.Context(), hash, size, limit)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to find metadata by hash", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to find metadata by hash: "+err.Error())
+		return
+	}
+	if result == nil {
+		result = []*models.Metadata{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// QueryMetadataByTags 按标签查询表达式筛选元数据，如 q=env=staging AND team!=core
+func (h *MetadataHandler) QueryMetadataByTags(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Tag query is required")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid limit parameter")
+		return
+	}
+
+	filter := &models.MetadataFilter{
+		Bucket: c.Query("bucket"),
+		Prefix: c.Query("prefix"),
+	}
+
+	page, err := h.service.QueryMetadataByTags(c.Request.Context(), query, filter, c.Query("continuation_token"), limit)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to query metadata by tags", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to query metadata by tags: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"query": filter.TagQuery.String(),
+			"page":  page,
+		},
+	})
+}
+
+// PreviewLifecycleRule 预览生命周期规则将过期或转换的对象数量和字节数
+func (h *MetadataHandler) PreviewLifecycleRule(c *gin.Context) {
+	var req models.LifecyclePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	preview, err := h.service.PreviewLifecycleRule(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to preview lifecycle rule", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to preview lifecycle rule: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    preview,
+	})
+}
+
+// ScanExpiredObjects 立即执行一次对象过期扫描，dry_run=true时只统计不发出删除任务
+func (h *MetadataHandler) ScanExpiredObjects(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	result, err := h.service.ScanExpiredObjects(c.Request.Context(), dryRun)
+	if err != nil {
+		if errors.Is(err, models.ErrExpiryScannerDisabled) {
+			utils.SetErrorResponse(c.Writer, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to scan expired objects", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to scan expired objects: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetExpiryScan 获取最近一次对象过期扫描的结果
+func (h *MetadataHandler) GetExpiryScan(c *gin.Context) {
+	result := h.service.LastExpiryScan()
+	if result == nil {
+		utils.SetErrorResponse(c.Writer, http.StatusNotFound, "No expiry scan has run")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
 // GetStats 获取统计信息
 func (h *MetadataHandler) GetStats(c *gin.Context) {
 	stats, err := h.service.GetStats(c.Request.Context())
@@ -219,6 +721,39 @@ func (h *MetadataHandler) GetStats(c *gin.Context) {
 	})
 }
 
+// RefreshStats 立即重新汇总统计信息
+func (h *MetadataHandler) RefreshStats(c *gin.Context) {
+	stats, err := h.service.RefreshStats(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to refresh stats", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to refresh statistics: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// ListTenants 列出所有租户及其用量
+func (h *MetadataHandler) ListTenants(c *gin.Context) {
+	tenants, err := h.service.ListTenants(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list tenants", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to list tenants: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"tenants": tenants,
+			"count":   len(tenants),
+		},
+	})
+}
+
 // CountObjects 计算对象数量
 func (h *MetadataHandler) CountObjects(c *gin.Context) {
 	bucket := c.Query("bucket")
@@ -240,3 +775,101 @@ func (h *MetadataHandler) CountObjects(c *gin.Context) {
 		},
 	})
 }
+
+// GetUsage 获取bucket/前缀下的对象数和字节数
+func (h *MetadataHandler) GetUsage(c *gin.Context) {
+	bucket := c.Query("bucket")
+	prefix := c.Query("prefix")
+
+	usage, err := h.service.GetUsage(c.Request.Context(), bucket, prefix)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get usage", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to get usage: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"bucket":  bucket,
+			"prefix":  prefix,
+			"objects": usage.Objects,
+			"bytes":   usage.Bytes,
+		},
+	})
+}
+
+// ExportMetadata 流式导出元数据（JSONL或CSV）
+func (h *MetadataHandler) ExportMetadata(c *gin.Context) {
+	filter := &models.MetadataFilter{
+		Bucket: c.Query("bucket"),
+		Prefix: c.Query("prefix"),
+	}
+
+	format := c.DefaultQuery("format", models.MetadataFormatJSONL)
+	contentType := "application/x-ndjson"
+	switch format {
+	case models.MetadataFormatJSONL:
+	case models.MetadataFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	default:
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid format parameter, expected jsonl or csv")
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="metadata.`+format+`"`)
+	c.Status(http.StatusOK)
+
+	// 响应头已发送，中途失败只能记录日志，客户端收到的内容不完整
+	count, err := h.service.ExportMetadata(c.Request.Context(), filter, format, c.Writer)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to export metadata", "error", err, "exported", count)
+	}
+}
+
+// ImportMetadata 从请求体导入元数据（JSONL或CSV）
+func (h *MetadataHandler) ImportMetadata(c *gin.Context) {
+	format := c.DefaultQuery("format", models.MetadataFormatJSONL)
+	conflict := c.DefaultQuery("conflict", models.ImportConflictSkip)
+
+	result, err := h.service.ImportMetadata(c.Request.Context(), c.Request.Body, format, conflict)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to import metadata", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to import metadata: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": result.Failed == 0 && !result.Aborted,
+		"data":    result,
+	})
+}
+
+// parseOptionalInt64 解析可选的int64参数
+func parseOptionalInt64(value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// parseOptionalTime 解析可选的RFC3339时间参数
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"mocks3/shared/models"
+	"mocks3/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListBucketRetention 列出所有bucket的保留策略
+func (h *MetadataHandler) ListBucketRetention(c *gin.Context) {
+	result, err := h.service.ListBucketRetention(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list bucket retention", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to list bucket retention: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetBucketRetention 获取bucket的保留策略
+func (h *MetadataHandler) GetBucketRetention(c *gin.Context) {
+	retention, err := h.service.GetBucketRetention(c.Request.Context(), c.Param("bucket"))
+	if err != nil {
+		h.writeRetentionError(c, "Failed to get bucket retention", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    retention,
+	})
+}
+
+// PutBucketRetention 设置bucket的最短保留天数（{"min_retention_days": N}）
+func (h *MetadataHandler) PutBucketRetention(c *gin.Context) {
+	var req struct {
+		MinRetentionDays int `json:"min_retention_days"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	retention := &models.BucketRetention{Bucket: c.Param("bucket"), MinRetentionDays: req.MinRetentionDays}
+	if err := h.service.SetBucketRetention(c.Request.Context(), retention); err != nil {
+		h.writeRetentionError(c, "Failed to set bucket retention", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    retention,
+	})
+}
+
+// DeleteBucketRetention 删除bucket的保留策略
+func (h *MetadataHandler) DeleteBucketRetention(c *gin.Context) {
+	if err := h.service.DeleteBucketRetention(c.Request.Context(), c.Param("bucket")); err != nil {
+		h.writeRetentionError(c, "Failed to delete bucket retention", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bucket retention deleted successfully",
+	})
+}
+
+// writeRetentionError 按错误类型返回保留策略接口的错误响应
+func (h *MetadataHandler) writeRetentionError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidArgument):
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrBucketRetentionNotFound):
+		utils.SetErrorResponse(c.Writer, http.StatusNotFound, err.Error())
+	default:
+		h.logger.ErrorContext(c.Request.Context(), message, "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
+
+// writeRetentionViolation 删除的对象仍在bucket保留期内时返回403 RetentionViolation，返回是否已处理
+func (h *MetadataHandler) writeRetentionViolation(c *gin.Context, err error) bool {
+	var violation *models.RetentionViolationError
+	if !errors.As(err, &violation) {
+		return false
+	}
+
+	h.logger.WarnContext(c.Request.Context(), "Delete rejected by bucket retention",
+		"bucket", violation.Bucket, "key", violation.Key, "retain_until", violation.RetainUntil)
+	c.JSON(http.StatusForbidden, gin.H{
+		"success":   false,
+		"error":     violation.Error(),
+		"code":      models.ErrCodeRetentionViolation,
+		"retention": violation,
+	})
+	return true
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"mocks3/shared/models"
+	"mocks3/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListBucketSchemas 列出所有bucket的schema
+func (h *MetadataHandler) ListBucketSchemas(c *gin.Context) {
+	result, err := h.service.ListBucketSchemas(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list bucket schemas", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to list bucket schemas: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetBucketSchema 获取bucket注册的schema
+func (h *MetadataHandler) GetBucketSchema(c *gin.Context) {
+	schema, err := h.service.GetBucketSchema(c.Request.Context(), c.Param("bucket"))
+	if err != nil {
+		h.writeSchemaError(c, "Failed to get bucket schema", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    schema,
+	})
+}
+
+// PutBucketSchema 注册bucket的schema，请求体即JSON Schema文档
+func (h *MetadataHandler) PutBucketSchema(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, models.MaxBucketSchemaSize+1))
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Failed to read request body: "+err.Error())
+		return
+	}
+	if !json.Valid(body) {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid request body: schema must be a JSON document")
+		return
+	}
+
+	schema := &models.BucketSchema{Bucket: c.Param("bucket"), Schema: body}
+	if err := h.service.SetBucketSchema(c.Request.Context(), schema); err != nil {
+		h.writeSchemaError(c, "Failed to set bucket schema", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    schema,
+	})
+}
+
+// DeleteBucketSchema 删除bucket的schema
+func (h *MetadataHandler) DeleteBucketSchema(c *gin.Context) {
+	if err := h.service.DeleteBucketSchema(c.Request.Context(), c.Param("bucket")); err != nil {
+		h.writeSchemaError(c, "Failed to delete bucket schema", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bucket schema deleted successfully",
+	})
+}
+
+// ValidateMetadataSchema 按所属bucket的schema校验请求体中的元数据（不写入），不符合时返回400及逐项违规
+func (h *MetadataHandler) ValidateMetadataSchema(c *gin.Context) {
+	var metadata models.Metadata
+	if err := c.ShouldBindJSON(&metadata); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if err := h.service.ValidateMetadataSchema(c.Request.Context(), &metadata); err != nil {
+		if h.writeSchemaViolation(c, err) {
+			return
+		}
+		h.writeSchemaError(c, "Failed to validate metadata", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"valid": true},
+	})
+}
+
+// writeSchemaError 按错误类型返回schema接口的错误响应
+func (h *MetadataHandler) writeSchemaError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidArgument):
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrBucketSchemaNotFound):
+		utils.SetErrorResponse(c.Writer, http.StatusNotFound, err.Error())
+	default:
+		h.logger.ErrorContext(c.Request.Context(), message, "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
+
+// writeSchemaViolation 自定义元数据不符合bucket schema时返回400及逐项违规，返回是否已处理
+func (h *MetadataHandler) writeSchemaViolation(c *gin.Context, err error) bool {
+	var validationErr *models.SchemaValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success":    false,
+		"error":      validationErr.Error(),
+		"code":       models.ErrCodeSchemaViolation,
+		"violations": validationErr.Violations,
+	})
+	return true
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"strings"
+	"time"
+)
+
+// recordAudit 追加一条元数据变更审计记录，调用方需保证与变更在同一事务中
+func (r *MetadataRepository) recordAudit(ctx context.Context, exec dbExecutor, action string, oldValue, newValue *models.Metadata) error {
+	subject := newValue
+	if subject == nil {
+		subject = oldValue
+	}
+
+	oldJSON, err := auditValue(oldValue)
+	if err != nil {
+		return err
+	}
+	newJSON, err := auditValue(newValue)
+	if err != nil {
+		return err
+	}
+
+	actor := models.AuditActorFromContext(ctx)
+	_, err = exec.ExecContext(ctx, `
+		INSERT INTO metadata_audit (
+			tenant, bucket, key, metadata_id, action,
+			actor_name, actor_ip, user_agent, old_value, new_value, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		models.TenantFromContext(ctx), subject.Bucket, subject.Key, subject.ID, action,
+		actor.Name, actor.IP, actor.UserAgent, oldJSON, newJSON, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record metadata audit: %w", err)
+	}
+	return nil
+}
+
+// auditValue 序列化审计中的元数据快照，nil写入NULL
+func auditValue(metadata *models.Metadata) (interface{}, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit value: %w", err)
+	}
+	return data, nil
+}
+
+// ListAudit 按条件查询租户内的审计记录（按ID升序）
+func (r *MetadataRepository) ListAudit(ctx context.Context, query *models.MetadataAuditQuery) ([]*models.MetadataAuditEntry, error) {
+	conditions := []string{"tenant = $1", "id > $2"}
+	args := []interface{}{models.TenantFromContext(ctx), query.After}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if query.Bucket != "" {
+		addCondition("bucket = $%d", query.Bucket)
+	}
+	if query.Key != "" {
+		addCondition("key = $%d", query.Key)
+	}
+	if query.Action != "" {
+		addCondition("action = $%d", query.Action)
+	}
+	if query.Since != nil {
+		addCondition("created_at >= $%d", *query.Since)
+	}
+	if query.Until != nil {
+		addCondition("created_at < $%d", *query.Until)
+	}
+	args = append(args, query.Limit)
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, tenant, bucket, key, metadata_id, action,
+			   actor_name, actor_ip, user_agent, old_value, new_value, created_at
+		FROM metadata_audit
+		WHERE %s
+		ORDER BY id
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metadata audit: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*models.MetadataAuditEntry, 0)
+	for rows.Next() {
+		var entry models.MetadataAuditEntry
+		var actorName, actorIP, userAgent sql.NullString
+		var oldJSON, newJSON []byte
+		if err := rows.Scan(&entry.ID, &entry.Tenant, &entry.Bucket, &entry.Key, &entry.MetadataID, &entry.Action,
+			&actorName, &actorIP, &userAgent, &oldJSON, &newJSON, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan metadata audit: %w", err)
+		}
+		entry.Actor = models.AuditActor{Name: actorName.String, IP: actorIP.String, UserAgent: userAgent.String}
+		if len(oldJSON) > 0 {
+			if err := json.Unmarshal(oldJSON, &entry.OldValue); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal audit value: %w", err)
+			}
+		}
+		if len(newJSON) > 0 {
+			if err := json.Unmarshal(newJSON, &entry.NewValue); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal audit value: %w", err)
+			}
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mocks3/services/metadata/internal/config"
+	"mocks3/shared/interfaces"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// metadataCacheName 缓存指标中的cache标签
+const metadataCacheName = "metadata"
+
+// 缓存查询结果
+const (
+	cacheResultHit   = "hit"
+	cacheResultMiss  = "miss"
+	cacheResultError = "error"
+)
+
+// CacheStats 缓存统计
+type CacheStats struct {
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	Errors        int64   `json:"errors"`
+	Invalidations int64   `json:"invalidations"`
+	HitRatio      float64 `json:"hit_ratio"`
+}
+
+// CachedMetadataRepository 带Redis读穿透缓存的元数据仓库
+//
+// 仅缓存GetByKey的结果；Create/Update/Delete/Restore/ExecuteBatch成功后删除对应缓存项。
+// 读写并发时可能写回旧值，由TTL兜底。Redis不可用时直接回退到底层仓库。
+type CachedMetadataRepository struct {
+	interfaces.MetadataRepository
+
+	client  *redis.Client
+	ttl     time.Duration
+	prefix  string
+	metrics *observability.MetricCollector
+	logger  *observability.Logger
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	errors        atomic.Int64
+	invalidations atomic.Int64
+}
+
+var _ interfaces.MetadataRepository = (*CachedMetadataRepository)(nil)
+
+// NewCachedMetadataRepository 创建带缓存的元数据仓库
+func NewCachedMetadataRepository(repo interfaces.MetadataRepository, cfg *config.CacheConfig, logger *observability.Logger) (*CachedMetadataRepository, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.GetAddress(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	// 测试连接
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return &CachedMetadataRepository{
+		MetadataRepository: repo,
+		client:             client,
+		ttl:                cfg.GetTTL(),
+		prefix:             cfg.KeyPrefix,
+		logger:             logger,
+	}, nil
+}
+
+// SetMetricCollector 设置指标收集器
+func (r *CachedMetadataRepository) SetMetricCollector(metrics *observability.MetricCollector) {
+	r.metrics = metrics
+}
+
+// GetByKey 根据键获取元数据，优先读取缓存
+func (r *CachedMetadataRepository) GetByKey(ctx context.Context, bucket, key string) (*models.Metadata, error) {
+	cacheKey := r.cacheKey(ctx, bucket, key)
+
+	data, err := r.client.Get(ctx, cacheKey).Bytes()
+	switch {
+	case err == nil:
+		var metadata models.Metadata
+		if err := json.Unmarshal(data, &metadata); err == nil {
+			r.record(ctx, cacheResultHit)
+			return &metadata, nil
+		}
+		// 无法解析的缓存项视为未命中，回源后覆盖
+		r.record(ctx, cacheResultMiss)
+	case errors.Is(err, redis.Nil):
+		r.record(ctx, cacheResultMiss)
+	default:
+		r.record(ctx, cacheResultError)
+		r.logger.Warn(ctx, "Metadata cache read failed, falling back to database",
+			observability.String("bucket", bucket),
+			observability.String("key", key),
+			observability.Error(err))
+	}
+
+	metadata, err := r.MetadataRepository.GetByKey(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(metadata); err == nil {
+		if err := r.client.Set(ctx, cacheKey, data, r.ttl).Err(); err != nil {
+			r.logger.Warn(ctx, "Failed to populate metadata cache",
+				observability.String("bucket", bucket),
+				observability.String("key", key),
+				observability.Error(err))
+		}
+	}
+
+	return metadata, nil
+}
+
+// Create 创建元数据并失效缓存
+func (r *CachedMetadataRepository) Create(ctx context.Context, metadata *models.Metadata) error {
+	if err := r.MetadataRepository.Create(ctx, metadata); err != nil {
+		return err
+	}
+	r.invalidate(ctx, r.cacheKey(ctx, metadata.Bucket, metadata.Key))
+	return nil
+}
+
+// Update 更新元数据并失效缓存
+func (r *CachedMetadataRepository) Update(ctx context.Context, metadata *models.Metadata) error {
+	if err := r.MetadataRepository.Update(ctx, metadata); err != nil {
+		return err
+	}
+	r.invalidate(ctx, r.cacheKey(ctx, metadata.Bucket, metadata.Key))
+	return nil
+}
+
+// Delete 删除元数据并失效缓存
+func (r *CachedMetadataRepository) Delete(ctx context.Context, bucket, key string) error {
+	if err := r.MetadataRepository.Delete(ctx, bucket, key); err != nil {
+		return err
+	}
+	r.invalidate(ctx, r.cacheKey(ctx, bucket, key))
+	return nil
+}
+
+// Restore 恢复软删除的元数据并失效缓存
+func (r *CachedMetadataRepository) Restore(ctx context.Context, bucket, key string, deletedAfter time.Time) (*models.Metadata, error) {
+	metadata, err := r.MetadataRepository.Restore(ctx, bucket, key, deletedAfter)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, r.cacheKey(ctx, bucket, key))
+	return metadata, nil
+}
+
+// Import 导入元数据并失效缓存
+func (r *CachedMetadataRepository) Import(ctx context.Context, metadata *models.Metadata, overwrite bool) (string, error) {
+	outcome, err := r.MetadataRepository.Import(ctx, metadata, overwrite)
+	if err != nil {
+		return "", err
+	}
+	if outcome != models.ImportOutcomeSkipped {
+		r.invalidate(ctx, r.cacheKey(ctx, metadata.Bucket, metadata.Key))
+	}
+	return outcome, nil
+}
+
+// ExecuteBatch 执行批量操作并失效涉及的缓存项（非原子模式下可能部分成功，因此总是失效）
+func (r *CachedMetadataRepository) ExecuteBatch(ctx context.Context, ops []*models.MetadataBatchOperation, atomic bool) ([]*models.MetadataBatchItemResult, bool, error) {
+	results, committed, err := r.MetadataRepository.ExecuteBatch(ctx, ops, atomic)
+
+	keys := make([]string, 0, len(ops))
+	for _, op := range ops {
+		if op.Metadata != nil {
+			keys = append(keys, r.cacheKey(ctx, op.Metadata.Bucket, op.Metadata.Key))
+		} else {
+			keys = append(keys, r.cacheKey(ctx, op.Bucket, op.Key))
+		}
+	}
+	r.invalidate(ctx, keys...)
+
+	return results, committed, err
+}
+
+// Stats 获取缓存统计
+func (r *CachedMetadataRepository) Stats() *CacheStats {
+	stats := &CacheStats{
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Errors:        r.errors.Load(),
+		Invalidations: r.invalidations.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses + stats.Errors; lookups > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+// Close 关闭Redis连接
+func (r *CachedMetadataRepository) Close() error {
+	return r.client.Close()
+}
+
+// cacheKey 生成缓存键（租户和bucket名不含"/"，可无歧义拼接）
+func (r *CachedMetadataRepository) cacheKey(ctx context.Context, bucket, key string) string {
+	return r.prefix + models.TenantFromContext(ctx) + "/" + bucket + "/" + key
+}
+
+// invalidate 删除缓存项，失败时仅记录日志，由TTL兜底
+func (r *CachedMetadataRepository) invalidate(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		r.logger.Warn(ctx, "Failed to invalidate metadata cache",
+			observability.Int("keys", len(keys)),
+			observability.Error(err))
+		return
+	}
+	r.invalidations.Add(int64(len(keys)))
+}
+
+// record 记录缓存查询结果
+func (r *CachedMetadataRepository) record(ctx context.Context, result string) {
+	switch result {
+	case cacheResultHit:
+		r.hits.Add(1)
+	case cacheResultMiss:
+		r.misses.Add(1)
+	case cacheResultError:
+		r.errors.Add(1)
+	}
+	if r.metrics != nil {
+		r.metrics.RecordCacheRequest(ctx, metadataCacheName, result)
+	}
+}
@@ -5,18 +5,35 @@ import (
 	"database/sql"
 	"fmt"
 	"mocks3/services/metadata/internal/config"
+	"mocks3/shared/models"
 	"time"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	_ "github.com/go-sql-driver/mysql" // MySQL driver
+	_ "github.com/lib/pq"              // PostgreSQL driver
+	_ "github.com/mattn/go-sqlite3"    // SQLite driver
 )
 
+// dbExecutor 可执行SQL的对象（*sql.DB 或 *sql.Tx）
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 // Database 数据库连接管理器
 type Database struct {
-	db *sql.DB
+	db       *sql.DB
+	dialect  *dialect
+	replicas *replicaSet // 未配置只读副本时为nil
 }
 
 // NewDatabase 创建数据库连接
 func NewDatabase(config config.DatabaseConfig) (*Database, error) {
+	dialect, err := newDialect(config.Driver)
+	if err != nil {
+		return nil, err
+	}
+
 	dsn := config.GetDSN()
 
 	db, err := sql.Open(config.Driver, dsn)
@@ -25,20 +42,37 @@ func NewDatabase(config config.DatabaseConfig) (*Database, error) {
 	}
 
 	// 设置连接池参数
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	if config.Driver == DriverSQLite {
+		// SQLite同一时间只允许一个写入者，单连接避免SQLITE_BUSY，也使:memory:数据库在连接间共享
+		db.SetMaxOpenConns(1)
+	} else {
+		db.SetMaxOpenConns(25)
+		db.SetMaxIdleConns(5)
+		db.SetConnMaxLifetime(5 * time.Minute)
+	}
 
 	// 测试连接
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	database := &Database{db: db}
+	replicas, err := newReplicaSet(config, dialect)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	database := &Database{db: db, dialect: dialect, replicas: replicas}
 
-	// 初始化数据库表
-	if err := database.initTables(); err != nil {
-		return nil, fmt.Errorf("failed to initialize tables: %w", err)
+	// 执行未应用的schema迁移
+	if config.AutoMigrate {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		if _, err := database.Migrate(ctx); err != nil {
+			database.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
 	}
 
 	return database, nil
@@ -49,67 +83,48 @@ func (d *Database) GetDB() *sql.DB {
 	return d.db
 }
 
-// Close 关闭数据库连接
-func (d *Database) Close() error {
-	return d.db.Close()
+// Driver 数据库驱动名
+func (d *Database) Driver() string {
+	return d.dialect.driver
 }
 
-// initTables 初始化数据库表
-func (d *Database) initTables() error {
-	// 创建元数据表
-	metadataTable := `
-	CREATE TABLE IF NOT EXISTS metadata (
-		id VARCHAR(255) PRIMARY KEY,
-		key VARCHAR(500) NOT NULL,
-		bucket VARCHAR(255) NOT NULL,
-		size BIGINT NOT NULL,
-		content_type VARCHAR(255),
-		md5_hash VARCHAR(32),
-		etag VARCHAR(255),
-		storage_nodes JSONB,
-		headers JSONB,
-		tags JSONB,
-		status VARCHAR(50) DEFAULT 'active',
-		version BIGINT DEFAULT 1,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		deleted_at TIMESTAMP WITH TIME ZONE NULL
-	);
-	
-	-- 创建索引
-	CREATE INDEX IF NOT EXISTS idx_metadata_key ON metadata(key);
-	CREATE INDEX IF NOT EXISTS idx_metadata_bucket ON metadata(bucket);
-	CREATE INDEX IF NOT EXISTS idx_metadata_bucket_key ON metadata(bucket, key);
-	CREATE INDEX IF NOT EXISTS idx_metadata_status ON metadata(status);
-	CREATE INDEX IF NOT EXISTS idx_metadata_created_at ON metadata(created_at);
-	CREATE INDEX IF NOT EXISTS idx_metadata_content_type ON metadata(content_type);
-	CREATE INDEX IF NOT EXISTS idx_metadata_size ON metadata(size);
-	
-	-- 创建唯一约束
-	CREATE UNIQUE INDEX IF NOT EXISTS idx_metadata_bucket_key_unique ON metadata(bucket, key) WHERE deleted_at IS NULL;
-	`
-
-	// 创建统计表
-	statsTable := `
-	CREATE TABLE IF NOT EXISTS stats_cache (
-		id SERIAL PRIMARY KEY,
-		stats_data JSONB NOT NULL,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);
-	
-	-- 确保只有一行统计数据
-	CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_cache_single ON stats_cache((1));
-	`
-
-	// 执行SQL
-	for _, tableSQL := range []string{metadataTable, statsTable} {
-		if _, err := d.db.Exec(tableSQL); err != nil {
-			return fmt.Errorf("failed to create table: %w", err)
+// executor 返回按方言转换SQL的执行器
+func (d *Database) executor() dbExecutor {
+	return d.bind(d.db)
+}
+
+// reader 返回读请求的执行器：优先使用可用的只读副本，无可用副本或要求读主库时使用主库
+func (d *Database) reader(ctx context.Context) dbExecutor {
+	if d.replicas != nil && !models.IsPrimaryRead(ctx) {
+		if replica := d.replicas.pick(); replica != nil {
+			return d.bind(replica)
 		}
 	}
+	return d.executor()
+}
 
-	return nil
+// ReplicaStatus 获取只读副本路由状态
+func (d *Database) ReplicaStatus(ctx context.Context) (*models.ReplicaReport, error) {
+	if d.replicas == nil {
+		return &models.ReplicaReport{Replicas: []*models.ReplicaStatus{}}, nil
+	}
+	return d.replicas.report(), nil
+}
+
+// bind 包装执行器（如事务），执行前按方言转换SQL
+func (d *Database) bind(exec dbExecutor) dbExecutor {
+	if d.dialect.driver == DriverPostgres {
+		return exec
+	}
+	return &boundExecutor{exec: exec, dialect: d.dialect}
+}
+
+// Close 关闭数据库连接
+func (d *Database) Close() error {
+	if d.replicas != nil {
+		d.replicas.close()
+	}
+	return d.db.Close()
 }
 
 // HealthCheck 健康检查
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"mocks3/services/metadata/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mysqlDSNEnv MySQL测试使用的连接串，未设置时跳过MySQL测试；
+// 测试会删除该库中的全部表，只能指向专用的测试库，如 user:pass@tcp(localhost:3306)/mocks3_test?parseTime=true&loc=UTC&multiStatements=true
+const mysqlDSNEnv = "METADATA_TEST_MYSQL_DSN"
+
+// testDrivers 测试覆盖的驱动：SQLite使用临时文件，MySQL需设置mysqlDSNEnv
+var testDrivers = []struct {
+	driver string
+	open   func(t *testing.T, migrate bool) *Database
+}{
+	{DriverSQLite, newSQLiteDatabase},
+	{DriverMySQL, newMySQLDatabase},
+}
+
+// forEachDriver 对每个驱动在迁移完成的数据库上运行测试
+func forEachDriver(t *testing.T, fn func(t *testing.T, db *Database)) {
+	for _, tc := range testDrivers {
+		t.Run(tc.driver, func(t *testing.T) {
+			fn(t, tc.open(t, true))
+		})
+	}
+}
+
+// newSQLiteDatabase 在临时目录中创建SQLite数据库
+func newSQLiteDatabase(t *testing.T, migrate bool) *Database {
+	t.Helper()
+	db, err := NewDatabase(config.DatabaseConfig{
+		Driver:      DriverSQLite,
+		Database:    filepath.Join(t.TempDir(), "metadata.db"),
+		AutoMigrate: migrate,
+	})
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// newMySQLDatabase 连接mysqlDSNEnv指定的MySQL并清空全部表，未设置时跳过
+func newMySQLDatabase(t *testing.T, migrate bool) *Database {
+	t.Helper()
+	dsn := os.Getenv(mysqlDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", mysqlDSNEnv)
+	}
+
+	db, err := NewDatabase(config.DatabaseConfig{Driver: DriverMySQL, DSN: dsn})
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	dropMySQLTables(t, db)
+	if migrate {
+		if _, err := db.Migrate(context.Background()); err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+	}
+	return db
+}
+
+// dropMySQLTables 删除当前库中的全部表
+func dropMySQLTables(t *testing.T, db *Database) {
+	t.Helper()
+	ctx := context.Background()
+	rows, err := db.GetDB().QueryContext(ctx, "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()")
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			t.Fatalf("scan table: %v", err)
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+
+	for _, table := range tables {
+		if _, err := db.GetDB().ExecContext(ctx, "DROP TABLE IF EXISTS `"+table+"`"); err != nil {
+			t.Fatalf("drop table %s: %v", table, err)
+		}
+	}
+}
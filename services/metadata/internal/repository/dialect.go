@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// 支持的数据库驱动
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite3"
+)
+
+// dialect SQL方言
+//
+// 仓库中的SQL统一按PostgreSQL书写（$N占位符），MySQL和SQLite执行前由rebind转换；
+// 无法通用的片段（JSON运算、采样、随机排序等）由方言方法生成。
+type dialect struct {
+	driver string
+}
+
+// newDialect 创建驱动对应的方言
+func newDialect(driver string) (*dialect, error) {
+	switch driver {
+	case DriverPostgres, DriverMySQL, DriverSQLite:
+		return &dialect{driver: driver}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+	}
+}
+
+// rebind 将$N占位符转换为?并按出现顺序展开参数
+//
+// MySQL中key是保留字，同时为其加上反引号；JSON参数以字符串传递，
+// 避免被当作二进制串（MySQL拒绝binary字符集的JSON，SQLite将BLOB视为JSONB），见bindArg。
+func (d *dialect) rebind(query string, args []interface{}) (string, []interface{}) {
+	if d.driver == DriverPostgres {
+		return query, args
+	}
+
+	var b strings.Builder
+	bound := make([]interface{}, 0, len(args))
+	inString := false
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		if ch == '\'' {
+			inString = !inString
+			b.WriteByte(ch)
+			continue
+		}
+		if inString {
+			b.WriteByte(ch)
+			continue
+		}
+
+		if ch == '$' && i+1 < len(query) && isDigit(query[i+1]) {
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			n, _ := strconv.Atoi(query[i+1 : j])
+			if n >= 1 && n <= len(args) {
+				bound = append(bound, d.bindArg(args[n-1]))
+			}
+			b.WriteByte('?')
+			i = j - 1
+			continue
+		}
+
+		if d.driver == DriverMySQL && isIdentStart(ch) && (i == 0 || !isIdentPart(query[i-1])) {
+			j := i
+			for j < len(query) && isIdentPart(query[j]) {
+				j++
+			}
+			word := query[i:j]
+			if word == "key" && (i == 0 || query[i-1] != '.') {
+				b.WriteString("`key`")
+			} else {
+				b.WriteString(word)
+			}
+			i = j - 1
+			continue
+		}
+
+		b.WriteByte(ch)
+	}
+
+	return b.String(), bound
+}
+
+// bindArg 转换参数：JSON以字符串传递；SQLite以文本保存时间，统一为UTC保证按字符串比较的顺序正确
+func (d *dialect) bindArg(arg interface{}) interface{} {
+	switch v := arg.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		if d.driver == DriverSQLite {
+			return v.UTC()
+		}
+	case *time.Time:
+		if d.driver == DriverSQLite && v != nil {
+			return v.UTC()
+		}
+	}
+	return arg
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isIdentPart(ch byte) bool {
+	return isIdentStart(ch) || isDigit(ch)
+}
+
+// jsonPath 标签键对应的JSON路径
+func jsonPath(key string) string {
+	key = strings.ReplaceAll(key, `\`, `\\`)
+	key = strings.ReplaceAll(key, `"`, `\"`)
+	return `$."` + key + `"`
+}
+
+// tagsContain 标签包含全部指定键值对的条件
+func (d *dialect) tagsContain(tags map[string]string, args *[]interface{}) (string, error) {
+	switch d.driver {
+	case DriverSQLite:
+		keys := make([]string, 0, len(tags))
+		for key := range tags {
+			keys = append(keys, key)
+		}
+		// 键顺序固定，保证生成的SQL稳定
+		sort.Strings(keys)
+		conditions := make([]string, 0, len(keys))
+		for _, key := range keys {
+			*args = append(*args, jsonPath(key), tags[key])
+			conditions = append(conditions, fmt.Sprintf("(json_type(tags, $%d) = 'text' AND json_extract(tags, $%d) = $%d)",
+				len(*args)-1, len(*args)-1, len(*args)))
+		}
+		return "(" + strings.Join(conditions, " AND ") + ")", nil
+	}
+
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tag filter: %w", err)
+	}
+	*args = append(*args, string(tagsJSON))
+	if d.driver == DriverMySQL {
+		return fmt.Sprintf("JSON_CONTAINS(tags, $%d)", len(*args)), nil
+	}
+	return fmt.Sprintf("tags @> $%d::jsonb", len(*args)), nil
+}
+
+// tagExists 标签键存在的条件
+func (d *dialect) tagExists(key string, args *[]interface{}) string {
+	switch d.driver {
+	case DriverMySQL:
+		*args = append(*args, jsonPath(key))
+		return fmt.Sprintf("JSON_CONTAINS_PATH(tags, 'one', $%d)", len(*args))
+	case DriverSQLite:
+		*args = append(*args, jsonPath(key))
+		return fmt.Sprintf("json_type(tags, $%d) IS NOT NULL", len(*args))
+	}
+	*args = append(*args, key)
+	return fmt.Sprintf("tags ? $%d", len(*args))
+}
+
+// ilike 不区分大小写的LIKE
+func (d *dialect) ilike(column, arg string) string {
+	if d.driver == DriverPostgres {
+		return column + " ILIKE " + arg
+	}
+	return "LOWER(" + column + ") LIKE LOWER(" + arg + ")"
+}
+
+// strpos 子串首次出现的位置（按字符从1开始，不存在时为0）
+func (d *dialect) strpos(str, substr string) string {
+	if d.driver == DriverPostgres {
+		return "strpos(" + str + ", " + substr + ")"
+	}
+	return "INSTR(" + str + ", " + substr + ")"
+}
+
+// jsonText JSON列的文本形式
+func (d *dialect) jsonText(column string) string {
+	switch d.driver {
+	case DriverMySQL:
+		return "CAST(" + column + " AS CHAR)"
+	case DriverSQLite:
+		return column
+	}
+	return column + "::text"
+}
+
+// random 随机排序表达式
+func (d *dialect) random() string {
+	if d.driver == DriverMySQL {
+		return "RAND()"
+	}
+	return "random()"
+}
+
+// sample 按比例采样：PostgreSQL使用TABLESAMPLE SYSTEM按数据页采样，其他方言按行随机过滤
+// 返回FROM子句和附加的WHERE条件（可能为空）
+func (d *dialect) sample(table string, percent float64, args *[]interface{}) (string, string) {
+	*args = append(*args, percent)
+	switch d.driver {
+	case DriverMySQL:
+		return table, fmt.Sprintf("RAND() * 100 < $%d", len(*args))
+	case DriverSQLite:
+		return table, fmt.Sprintf("(abs(random()) %% 1000000) / 10000.0 < $%d", len(*args))
+	}
+	return fmt.Sprintf("%s TABLESAMPLE SYSTEM ($%d)", table, len(*args)), ""
+}
+
+// tagPairs 将tags展开为(tag_key, tag_value)行的FROM子句和条件
+func (d *dialect) tagPairs() (string, string) {
+	switch d.driver {
+	case DriverMySQL:
+		return `metadata, JSON_TABLE(JSON_KEYS(tags), '$[*]' COLUMNS (tag_key VARCHAR(255) PATH '$')) AS t`,
+			`JSON_TYPE(tags) = 'OBJECT'`
+	case DriverSQLite:
+		return `metadata, json_each(metadata.tags) AS t`, `json_type(metadata.tags) = 'object'`
+	}
+	return `metadata, jsonb_each_text(tags) AS t`, `jsonb_typeof(tags) = 'object'`
+}
+
+// tagPairColumns tagPairs展开后的键列和值列
+func (d *dialect) tagPairColumns() (string, string) {
+	switch d.driver {
+	case DriverMySQL:
+		return "t.tag_key", `JSON_UNQUOTE(JSON_EXTRACT(tags, CONCAT('$."', t.tag_key, '"')))`
+	}
+	return "t.key", "t.value"
+}
+
+// purgeDeletedQuery 按删除时间顺序物理删除软删除记录（MySQL不支持在子查询中引用被删除表）
+func (d *dialect) purgeDeletedQuery() string {
+	if d.driver == DriverMySQL {
+		return `
+			DELETE FROM metadata
+			WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			ORDER BY deleted_at
+			LIMIT $2
+		`
+	}
+	return `
+		DELETE FROM metadata
+		WHERE id IN (
+			SELECT id FROM metadata
+			WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			ORDER BY deleted_at
+			LIMIT $2
+		)
+	`
+}
+
+// nullTime 可为NULL的时间列，兼容SQLite聚合结果（MAX等不带列类型，驱动返回文本）
+type nullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+// Scan 实现sql.Scanner
+func (t *nullTime) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		t.Time, t.Valid = time.Time{}, false
+		return nil
+	case time.Time:
+		t.Time, t.Valid = v, true
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("unsupported time value: %T", value)
+	}
+
+	text = strings.TrimSuffix(text, "Z")
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		if parsed, err := time.ParseInLocation(format, text, time.UTC); err == nil {
+			t.Time, t.Valid = parsed, true
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid time value: %q", text)
+}
+
+// Ptr 有效时返回时间指针
+func (t nullTime) Ptr() *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	value := t.Time
+	return &value
+}
+
+// boundExecutor 执行前按方言转换SQL
+type boundExecutor struct {
+	exec    dbExecutor
+	dialect *dialect
+}
+
+// ExecContext 执行SQL
+func (b *boundExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = b.dialect.rebind(query, args)
+	return b.exec.ExecContext(ctx, query, args...)
+}
+
+// QueryContext 查询多行
+func (b *boundExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = b.dialect.rebind(query, args)
+	return b.exec.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext 查询单行
+func (b *boundExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = b.dialect.rebind(query, args)
+	return b.exec.QueryRowContext(ctx, query, args...)
+}
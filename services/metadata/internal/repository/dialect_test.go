@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name      string
+		driver    string
+		query     string
+		args      []interface{}
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "postgres unchanged",
+			driver:    DriverPostgres,
+			query:     "SELECT * FROM metadata WHERE bucket = $1 AND key = $2",
+			args:      []interface{}{"b", "k"},
+			wantQuery: "SELECT * FROM metadata WHERE bucket = $1 AND key = $2",
+			wantArgs:  []interface{}{"b", "k"},
+		},
+		{
+			name:      "sqlite placeholders",
+			driver:    DriverSQLite,
+			query:     "SELECT * FROM metadata WHERE bucket = $1 AND key = $2",
+			args:      []interface{}{"b", "k"},
+			wantQuery: "SELECT * FROM metadata WHERE bucket = ? AND key = ?",
+			wantArgs:  []interface{}{"b", "k"},
+		},
+		{
+			name:      "arguments expanded in order of appearance",
+			driver:    DriverSQLite,
+			query:     "UPDATE metadata SET deleted_at = $1, updated_at = $1 WHERE id = $2 AND size > $10",
+			args:      []interface{}{"t", "id", 3, 4, 5, 6, 7, 8, 9, 10},
+			wantQuery: "UPDATE metadata SET deleted_at = ?, updated_at = ? WHERE id = ? AND size > ?",
+			wantArgs:  []interface{}{"t", "t", "id", 10},
+		},
+		{
+			name:      "string literals untouched",
+			driver:    DriverSQLite,
+			query:     "SELECT '$1 key' FROM metadata WHERE status = $1",
+			args:      []interface{}{"active"},
+			wantQuery: "SELECT '$1 key' FROM metadata WHERE status = ?",
+			wantArgs:  []interface{}{"active"},
+		},
+		{
+			name:      "mysql quotes key",
+			driver:    DriverMySQL,
+			query:     "SELECT id, key FROM metadata WHERE bucket = $1 AND key = $2 ORDER BY bucket, key",
+			args:      []interface{}{"b", "k"},
+			wantQuery: "SELECT id, `key` FROM metadata WHERE bucket = ? AND `key` = ? ORDER BY bucket, `key`",
+			wantArgs:  []interface{}{"b", "k"},
+		},
+		{
+			name:      "mysql leaves qualified and longer identifiers",
+			driver:    DriverMySQL,
+			query:     "SELECT t.key, tag_key, keys, monkey FROM t WHERE key_prefix = $1",
+			args:      []interface{}{"p"},
+			wantQuery: "SELECT t.key, tag_key, keys, monkey FROM t WHERE key_prefix = ?",
+			wantArgs:  []interface{}{"p"},
+		},
+		{
+			name:      "json bound as string",
+			driver:    DriverMySQL,
+			query:     "INSERT INTO metadata (tags) VALUES ($1)",
+			args:      []interface{}{[]byte(`{"a":"b"}`)},
+			wantQuery: "INSERT INTO metadata (tags) VALUES (?)",
+			wantArgs:  []interface{}{`{"a":"b"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newDialect(tt.driver)
+			if err != nil {
+				t.Fatalf("newDialect: %v", err)
+			}
+			query, args := d.rebind(tt.query, tt.args)
+			if query != tt.wantQuery {
+				t.Errorf("query = %q, want %q", query, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestRebindSQLiteTimeUTC(t *testing.T) {
+	d, _ := newDialect(DriverSQLite)
+	local := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("UTC+8", 8*3600))
+
+	_, args := d.rebind("SELECT $1, $2", []interface{}{local, &local})
+	for i, arg := range args {
+		value, ok := arg.(time.Time)
+		if !ok {
+			t.Fatalf("args[%d] = %T, want time.Time", i, arg)
+		}
+		if value.Location() != time.UTC || !value.Equal(local) {
+			t.Errorf("args[%d] = %v, want %v in UTC", i, value, local.UTC())
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+)
+
+// maxDuplicateGroupObjects 重复内容报告中每组最多列出的对象数
+const maxDuplicateGroupObjects = 100
+
+// FindByHash 查找租户内内容哈希和大小相同的未删除对象，按创建时间升序
+func (r *MetadataRepository) FindByHash(ctx context.Context, hash string, size int64, limit int) ([]*models.Metadata, error) {
+	rows, err := r.db.reader(ctx).QueryContext(ctx, `
+		SELECT id, key, bucket, size, content_type, md5_hash, etag,
+			   storage_nodes, headers, tags, status, version,
+			   created_at, updated_at, deleted_at
+		FROM metadata
+		WHERE tenant = $1 AND md5_hash = $2 AND size = $3 AND deleted_at IS NULL
+		ORDER BY created_at, bucket, key
+		LIMIT $4
+	`, models.TenantFromContext(ctx), hash, size, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find metadata by hash: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*models.Metadata
+	for rows.Next() {
+		metadata, err := r.scanMetadata(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan metadata: %w", err)
+		}
+		result = append(result, metadata)
+	}
+	return result, rows.Err()
+}
+
+// FindDuplicates 按内容哈希和大小分组，返回至少minCopies份的重复内容（按可释放字节数降序）
+// bucket非空时只统计该bucket内的对象
+func (r *MetadataRepository) FindDuplicates(ctx context.Context, bucket string, minCopies, limit int) ([]*models.DuplicateGroup, error) {
+	args := []interface{}{models.TenantFromContext(ctx)}
+	bucketCondition := ""
+	if bucket != "" {
+		args = append(args, bucket)
+		bucketCondition = fmt.Sprintf(" AND bucket = $%d", len(args))
+	}
+	args = append(args, minCopies, limit)
+
+	query := fmt.Sprintf(`
+		SELECT md5_hash, size, COUNT(*)
+		FROM metadata
+		WHERE tenant = $1 AND deleted_at IS NULL AND md5_hash IS NOT NULL AND md5_hash <> ''%s
+		GROUP BY md5_hash, size
+		HAVING COUNT(*) >= $%d
+		ORDER BY (COUNT(*) - 1) * size DESC, md5_hash
+		LIMIT $%d
+	`, bucketCondition, len(args)-1, len(args))
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	var groups []*models.DuplicateGroup
+	for rows.Next() {
+		var group models.DuplicateGroup
+		if err := rows.Scan(&group.Hash, &group.Size, &group.Copies); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan duplicate group: %w", err)
+		}
+		group.ReclaimableBytes = (group.Copies - 1) * group.Size
+		groups = append(groups, &group)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	for _, group := range groups {
+		objects, err := r.duplicateObjects(ctx, group, bucket)
+		if err != nil {
+			return nil, err
+		}
+		group.Objects = objects
+	}
+	return groups, nil
+}
+
+// duplicateObjects 列出重复组中的对象
+func (r *MetadataRepository) duplicateObjects(ctx context.Context, group *models.DuplicateGroup, bucket string) ([]*models.ObjectReference, error) {
+	args := []interface{}{models.TenantFromContext(ctx), group.Hash, group.Size}
+	bucketCondition := ""
+	if bucket != "" {
+		args = append(args, bucket)
+		bucketCondition = fmt.Sprintf(" AND bucket = $%d", len(args))
+	}
+	args = append(args, maxDuplicateGroupObjects)
+
+	query := fmt.Sprintf(`
+		SELECT id, bucket, key, etag, created_at
+		FROM metadata
+		WHERE tenant = $1 AND md5_hash = $2 AND size = $3 AND deleted_at IS NULL%s
+		ORDER BY created_at, bucket, key
+		LIMIT $%d
+	`, bucketCondition, len(args))
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list duplicate objects: %w", err)
+	}
+	defer rows.Close()
+
+	objects := make([]*models.ObjectReference, 0, group.Copies)
+	for rows.Next() {
+		var object models.ObjectReference
+		if err := rows.Scan(&object.ID, &object.Bucket, &object.Key, &object.ETag, &object.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate object: %w", err)
+		}
+		objects = append(objects, &object)
+	}
+	return objects, rows.Err()
+}
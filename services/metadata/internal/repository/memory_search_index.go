@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"mocks3/shared/interfaces"
+	"mocks3/shared/models"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 字段权重：key > tags > content_type > headers
+const (
+	weightKey         = 4.0
+	weightTags        = 2.0
+	weightContentType = 1.5
+	weightHeaders     = 1.0
+)
+
+// MemorySearchIndex 内嵌的内存倒排索引，文档按ctx中的租户隔离
+type MemorySearchIndex struct {
+	docs     map[string]*models.Metadata   // docID -> metadata
+	postings map[string]map[string]float64 // term -> docID -> 加权词频
+	terms    map[string][]string           // docID -> terms，用于删除
+	mu       sync.RWMutex
+}
+
+// NewMemorySearchIndex 创建内存搜索索引
+func NewMemorySearchIndex() *MemorySearchIndex {
+	return &MemorySearchIndex{
+		docs:     make(map[string]*models.Metadata),
+		postings: make(map[string]map[string]float64),
+		terms:    make(map[string][]string),
+	}
+}
+
+// Name 索引名称
+func (m *MemorySearchIndex) Name() string {
+	return "memory"
+}
+
+// Index 索引元数据
+func (m *MemorySearchIndex) Index(ctx context.Context, metadata *models.Metadata) error {
+	docID := searchDocID(models.TenantFromContext(ctx), metadata.Bucket, metadata.Key)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeLocked(docID)
+
+	weights := make(map[string]float64)
+	addTerms := func(text string, weight float64) {
+		for _, term := range strings.Fields(normalizeSearchText(text)) {
+			weights[term] += weight
+		}
+	}
+
+	addTerms(metadata.Key, weightKey)
+	addTerms(metadata.ContentType, weightContentType)
+	for k, v := range metadata.Tags {
+		addTerms(k+" "+v, weightTags)
+	}
+	for k, v := range metadata.Headers {
+		addTerms(k+" "+v, weightHeaders)
+	}
+
+	docTerms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		if m.postings[term] == nil {
+			m.postings[term] = make(map[string]float64)
+		}
+		m.postings[term][docID] = weight
+		docTerms = append(docTerms, term)
+	}
+
+	metadataCopy := *metadata
+	m.docs[docID] = &metadataCopy
+	m.terms[docID] = docTerms
+	return nil
+}
+
+// Remove 移除索引
+func (m *MemorySearchIndex) Remove(ctx context.Context, bucket, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeLocked(searchDocID(models.TenantFromContext(ctx), bucket, key))
+	return nil
+}
+
+// Search 全文搜索（TF-IDF打分）
+func (m *MemorySearchIndex) Search(ctx context.Context, query *models.MetadataSearchQuery) (*models.MetadataSearchResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	queryTerms := strings.Fields(normalizeSearchText(query.Query))
+	totalDocs := float64(len(m.docs))
+	tenantPrefix := models.TenantFromContext(ctx) + "/"
+
+	// 计算候选文档得分，所有词都必须命中
+	scores := make(map[string]float64)
+	if len(queryTerms) == 0 {
+		for docID := range m.docs {
+			scores[docID] = 0
+		}
+	} else {
+		for i, term := range queryTerms {
+			postings := m.postings[term]
+			idf := math.Log(1 + totalDocs/float64(len(postings)+1))
+
+			next := make(map[string]float64)
+			for docID, weight := range postings {
+				if i > 0 {
+					if _, ok := scores[docID]; !ok {
+						continue
+					}
+				}
+				next[docID] = scores[docID] + weight*idf
+			}
+			scores = next
+		}
+	}
+
+	hits := make([]*models.MetadataSearchHit, 0, len(scores))
+	for docID, score := range scores {
+		metadata := m.docs[docID]
+		if !strings.HasPrefix(docID, tenantPrefix) || !matchesSearchFilters(metadata, query) {
+			continue
+		}
+		metadataCopy := *metadata
+		hits = append(hits, &models.MetadataSearchHit{Metadata: &metadataCopy, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Metadata.UpdatedAt.After(hits[j].Metadata.UpdatedAt)
+	})
+
+	result := &models.MetadataSearchResult{
+		Query:   query.Query,
+		Backend: m.Name(),
+		Total:   int64(len(hits)),
+		Limit:   query.Limit,
+		Offset:  query.Offset,
+	}
+
+	start := query.Offset
+	if start > len(hits) {
+		start = len(hits)
+	}
+	end := start + query.Limit
+	if query.Limit <= 0 || end > len(hits) {
+		end = len(hits)
+	}
+	result.Hits = hits[start:end]
+
+	return result, nil
+}
+
+// removeLocked 移除文档（调用方需持有写锁）
+func (m *MemorySearchIndex) removeLocked(docID string) {
+	for _, term := range m.terms[docID] {
+		delete(m.postings[term], docID)
+		if len(m.postings[term]) == 0 {
+			delete(m.postings, term)
+		}
+	}
+	delete(m.terms, docID)
+	delete(m.docs, docID)
+}
+
+// matchesSearchFilters 检查元数据是否满足过滤条件
+func matchesSearchFilters(metadata *models.Metadata, query *models.MetadataSearchQuery) bool {
+	if query.Bucket != "" && metadata.Bucket != query.Bucket {
+		return false
+	}
+	if query.ContentType != "" && !strings.HasPrefix(metadata.ContentType, query.ContentType) {
+		return false
+	}
+	for k, v := range query.Tags {
+		if metadata.Tags[k] != v {
+			return false
+		}
+	}
+	if query.SizeMin != nil && metadata.Size < *query.SizeMin {
+		return false
+	}
+	if query.SizeMax != nil && metadata.Size > *query.SizeMax {
+		return false
+	}
+	return true
+}
+
+// searchDocID 生成文档ID（租户和bucket名不含"/"，可无歧义拼接）
+func searchDocID(tenant, bucket, key string) string {
+	return tenant + "/" + bucket + "/" + key
+}
+
+// 确保实现了接口
+var _ interfaces.SearchIndex = (*MemorySearchIndex)(nil)
@@ -4,12 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"mocks3/shared/models"
+	"mocks3/shared/utils"
 	"strings"
 	"time"
-
-	"github.com/google/uuid"
+	"unicode/utf8"
 )
 
 // MetadataRepository 元数据仓库实现
@@ -26,10 +27,32 @@ func NewMetadataRepository(db *Database) *MetadataRepository {
 
 // Create 创建元数据
 func (r *MetadataRepository) Create(ctx context.Context, metadata *models.Metadata) error {
+	return r.db.WithTx(func(tx *sql.Tx) error {
+		return r.create(ctx, r.db.bind(tx), metadata)
+	})
+}
+
+// create 使用指定执行器创建元数据，调用方需保证在事务中执行
+func (r *MetadataRepository) create(ctx context.Context, exec dbExecutor, metadata *models.Metadata) error {
 	if metadata.ID == "" {
-		metadata.ID = uuid.New().String()
+		metadata.ID = utils.NewID()
+	}
+
+	now := time.Now()
+	if metadata.CreatedAt.IsZero() {
+		metadata.CreatedAt = now
 	}
+	metadata.UpdatedAt = now
+
+	if err := r.insert(ctx, exec, metadata); err != nil {
+		return fmt.Errorf("failed to create metadata: %w", err)
+	}
+
+	return r.recordAudit(ctx, exec, models.MetadataAuditCreate, nil, metadata)
+}
 
+// insert 按给定的ID、版本和时间戳插入元数据，归属ctx中的租户，并计入前缀计数
+func (r *MetadataRepository) insert(ctx context.Context, exec dbExecutor, metadata *models.Metadata) error {
 	// 序列化JSON字段
 	storageNodesJSON, err := json.Marshal(metadata.StorageNodes)
 	if err != nil {
@@ -50,31 +73,119 @@ func (r *MetadataRepository) Create(ctx context.Context, metadata *models.Metada
 		INSERT INTO metadata (
 			id, key, bucket, size, content_type, md5_hash, etag,
 			storage_nodes, headers, tags, status, version,
-			created_at, updated_at
+			created_at, updated_at, tenant
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 		)
 	`
 
-	now := time.Now()
-	if metadata.CreatedAt.IsZero() {
-		metadata.CreatedAt = now
-	}
-	metadata.UpdatedAt = now
-
-	_, err = r.db.GetDB().ExecContext(ctx, query,
+	_, err = exec.ExecContext(ctx, query,
 		metadata.ID, metadata.Key, metadata.Bucket, metadata.Size,
 		metadata.ContentType, metadata.MD5Hash, metadata.ETag,
 		storageNodesJSON, headersJSON, tagsJSON,
 		metadata.Status, metadata.Version,
-		metadata.CreatedAt, metadata.UpdatedAt,
+		metadata.CreatedAt, metadata.UpdatedAt, models.TenantFromContext(ctx),
 	)
+	if err != nil {
+		return err
+	}
+	return r.adjustPrefixCounters(ctx, exec, metadata.Bucket, metadata.Key, 1, metadata.Size)
+}
+
+// Import 保留ID、版本和时间戳写入元数据，用于环境克隆
+//
+// 租户内已有同bucket/key的未删除记录或同ID记录时：overwrite为false则不写入并返回skipped，
+// 否则在同一事务中物理删除已有记录后写入（已有记录的历史版本保留在历史表中）。
+// 同ID记录属于其他租户时不会被覆盖，写入失败。
+func (r *MetadataRepository) Import(ctx context.Context, metadata *models.Metadata, overwrite bool) (string, error) {
+	tenant := models.TenantFromContext(ctx)
+	outcome := models.ImportOutcomeCreated
+	var replaced *models.Metadata
+	err := r.db.WithTx(func(tx *sql.Tx) error {
+		exec := r.db.bind(tx)
+
+		// ID全局唯一，其他租户已使用的ID不能导入（也不能覆盖）
+		var foreign int
+		err := exec.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM metadata WHERE id = $1 AND tenant <> $2",
+			metadata.ID, tenant,
+		).Scan(&foreign)
+		if err != nil {
+			return fmt.Errorf("failed to check existing metadata: %w", err)
+		}
+		if foreign > 0 {
+			return fmt.Errorf("metadata id %s belongs to another tenant", metadata.ID)
+		}
 
+		var conflicts int
+		err = exec.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM metadata WHERE tenant = $1 AND (id = $2 OR (bucket = $3 AND key = $4 AND deleted_at IS NULL))",
+			tenant, metadata.ID, metadata.Bucket, metadata.Key,
+		).Scan(&conflicts)
+		if err != nil {
+			return fmt.Errorf("failed to check existing metadata: %w", err)
+		}
+
+		if conflicts > 0 {
+			if !overwrite {
+				outcome = models.ImportOutcomeSkipped
+				return nil
+			}
+
+			rows, err := exec.QueryContext(ctx, `
+				SELECT id, key, bucket, size, content_type, md5_hash, etag,
+					   storage_nodes, headers, tags, status, version,
+					   created_at, updated_at, deleted_at
+				FROM metadata
+				WHERE tenant = $1 AND (id = $2 OR (bucket = $3 AND key = $4)) AND deleted_at IS NULL
+			`, tenant, metadata.ID, metadata.Bucket, metadata.Key)
+			if err != nil {
+				return fmt.Errorf("failed to check existing metadata: %w", err)
+			}
+			var removed []*models.Metadata
+			for rows.Next() {
+				object, err := r.scanMetadata(rows)
+				if err != nil {
+					rows.Close()
+					return fmt.Errorf("failed to scan metadata: %w", err)
+				}
+				removed = append(removed, object)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("rows iteration error: %w", err)
+			}
+
+			_, err = exec.ExecContext(ctx,
+				"DELETE FROM metadata WHERE tenant = $1 AND (id = $2 OR (bucket = $3 AND key = $4 AND deleted_at IS NULL))",
+				tenant, metadata.ID, metadata.Bucket, metadata.Key)
+			if err != nil {
+				return fmt.Errorf("failed to remove existing metadata: %w", err)
+			}
+			for _, object := range removed {
+				if err := r.adjustPrefixCounters(ctx, exec, object.Bucket, object.Key, -1, -object.Size); err != nil {
+					return err
+				}
+				// 同bucket/key的记录作为导入的旧值，仅ID冲突的其他键记为删除
+				if object.Bucket == metadata.Bucket && object.Key == metadata.Key {
+					replaced = object
+				} else if err := r.recordAudit(ctx, exec, models.MetadataAuditDelete, object, nil); err != nil {
+					return err
+				}
+			}
+			outcome = models.ImportOutcomeOverwritten
+		}
+
+		if err := r.insert(ctx, exec, metadata); err != nil {
+			return fmt.Errorf("failed to import metadata: %w", err)
+		}
+		return r.recordAudit(ctx, exec, models.MetadataAuditImport, replaced, metadata)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create metadata: %w", err)
+		return "", err
 	}
 
-	return nil
+	return outcome, nil
 }
 
 // GetByKey 根据键获取元数据
@@ -84,15 +195,15 @@ func (r *MetadataRepository) GetByKey(ctx context.Context, bucket, key string) (
 			   storage_nodes, headers, tags, status, version,
 			   created_at, updated_at, deleted_at
 		FROM metadata
-		WHERE bucket = $1 AND key = $2 AND deleted_at IS NULL
+		WHERE tenant = $1 AND bucket = $2 AND key = $3 AND deleted_at IS NULL
 	`
 
-	row := r.db.GetDB().QueryRowContext(ctx, query, bucket, key)
+	row := r.db.reader(ctx).QueryRowContext(ctx, query, models.TenantFromContext(ctx), bucket, key)
 
 	metadata, err := r.scanMetadata(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("metadata not found: %s/%s", bucket, key)
+			return nil, fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, bucket, key)
 		}
 		return nil, fmt.Errorf("failed to get metadata: %w", err)
 	}
@@ -100,8 +211,15 @@ func (r *MetadataRepository) GetByKey(ctx context.Context, bucket, key string) (
 	return metadata, nil
 }
 
-// Update 更新元数据
+// Update 更新元数据（在同一事务中保存旧版本并更新）
 func (r *MetadataRepository) Update(ctx context.Context, metadata *models.Metadata) error {
+	return r.db.WithTx(func(tx *sql.Tx) error {
+		return r.update(ctx, r.db.bind(tx), metadata)
+	})
+}
+
+// update 保存旧版本到历史表并更新元数据，调用方需保证在事务中执行
+func (r *MetadataRepository) update(ctx context.Context, exec dbExecutor, metadata *models.Metadata) error {
 	// 序列化JSON字段
 	storageNodesJSON, err := json.Marshal(metadata.StorageNodes)
 	if err != nil {
@@ -123,44 +241,173 @@ func (r *MetadataRepository) Update(ctx context.Context, metadata *models.Metada
 		SET size = $1, content_type = $2, md5_hash = $3, etag = $4,
 			storage_nodes = $5, headers = $6, tags = $7, status = $8,
 			version = version + 1, updated_at = $9
-		WHERE bucket = $10 AND key = $11 AND deleted_at IS NULL
+		WHERE tenant = $10 AND bucket = $11 AND key = $12 AND deleted_at IS NULL
 	`
 
-	metadata.UpdatedAt = time.Now()
-	metadata.Version++
+	tenant := models.TenantFromContext(ctx)
+	updatedAt := time.Now()
+
+	current, err := r.lockMetadata(ctx, exec, metadata.Bucket, metadata.Key)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, metadata.Bucket, metadata.Key)
+	}
+
+	if _, err := exec.ExecContext(ctx, archiveQuery, tenant, metadata.Bucket, metadata.Key, updatedAt); err != nil {
+		return fmt.Errorf("failed to archive metadata: %w", err)
+	}
 
-	result, err := r.db.GetDB().ExecContext(ctx, query,
+	result, err := exec.ExecContext(ctx, query,
 		metadata.Size, metadata.ContentType, metadata.MD5Hash, metadata.ETag,
 		storageNodesJSON, headersJSON, tagsJSON, metadata.Status,
-		metadata.UpdatedAt, metadata.Bucket, metadata.Key,
+		updatedAt, tenant, metadata.Bucket, metadata.Key,
 	)
-
 	if err != nil {
 		return fmt.Errorf("failed to update metadata: %w", err)
 	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, metadata.Bucket, metadata.Key)
+	}
 
-	rowsAffected, err := result.RowsAffected()
+	// 不使用RETURNING（MySQL不支持），在同一事务中读取新版本
+	updated, err := r.lockMetadata(ctx, exec, metadata.Bucket, metadata.Key)
 	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
+		return fmt.Errorf("failed to get metadata version: %w", err)
+	}
+	if updated == nil {
+		return fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, metadata.Bucket, metadata.Key)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("metadata not found: %s/%s", metadata.Bucket, metadata.Key)
+	if err := r.adjustPrefixCounters(ctx, exec, metadata.Bucket, metadata.Key, 0, metadata.Size-current.Size); err != nil {
+		return err
+	}
+	if err := r.recordAudit(ctx, exec, models.MetadataAuditUpdate, current, updated); err != nil {
+		return err
 	}
 
+	metadata.UpdatedAt = updatedAt
+	metadata.Version = updated.Version
 	return nil
 }
 
+// archiveQuery 将当前版本复制到历史表
+const archiveQuery = `
+	INSERT INTO metadata_history (
+		metadata_id, key, bucket, size, content_type, md5_hash, etag,
+		storage_nodes, headers, tags, status, version,
+		created_at, updated_at, archived_at, tenant
+	)
+	SELECT id, key, bucket, size, content_type, md5_hash, etag,
+		   storage_nodes, headers, tags, status, version,
+		   created_at, updated_at, $4, tenant
+	FROM metadata
+	WHERE tenant = $1 AND bucket = $2 AND key = $3 AND deleted_at IS NULL
+`
+
+// ListHistory 列出对象的历史版本（按版本号倒序）
+func (r *MetadataRepository) ListHistory(ctx context.Context, bucket, key string, limit, offset int) ([]*models.MetadataVersion, error) {
+	query := `
+		SELECT metadata_id, key, bucket, size, content_type, md5_hash, etag,
+			   storage_nodes, headers, tags, status, version,
+			   created_at, updated_at, NULL,
+			   history_id, archived_at
+		FROM metadata_history
+		WHERE tenant = $1 AND bucket = $2 AND key = $3
+		ORDER BY version DESC, history_id DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query, models.TenantFromContext(ctx), bucket, key, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metadata history: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*models.MetadataVersion
+	for rows.Next() {
+		version, err := r.scanVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan metadata history: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetVersion 获取指定的历史版本
+func (r *MetadataRepository) GetVersion(ctx context.Context, bucket, key string, version int64) (*models.MetadataVersion, error) {
+	query := `
+		SELECT metadata_id, key, bucket, size, content_type, md5_hash, etag,
+			   storage_nodes, headers, tags, status, version,
+			   created_at, updated_at, NULL,
+			   history_id, archived_at
+		FROM metadata_history
+		WHERE tenant = $1 AND bucket = $2 AND key = $3 AND version = $4
+		ORDER BY history_id DESC
+		LIMIT 1
+	`
+
+	row := r.db.reader(ctx).QueryRowContext(ctx, query, models.TenantFromContext(ctx), bucket, key, version)
+
+	result, err := r.scanVersion(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s/%s@%d", models.ErrMetadataNotFound, bucket, key, version)
+		}
+		return nil, fmt.Errorf("failed to get metadata version: %w", err)
+	}
+
+	return result, nil
+}
+
+// scanVersion 扫描历史版本行
+func (r *MetadataRepository) scanVersion(scanner interface{}) (*models.MetadataVersion, error) {
+	var version models.MetadataVersion
+	metadata, err := r.scanMetadata(scanner, &version.HistoryID, &version.ArchivedAt)
+	if err != nil {
+		return nil, err
+	}
+	version.Metadata = *metadata
+	return &version, nil
+}
+
 // Delete 删除元数据（软删除）
 func (r *MetadataRepository) Delete(ctx context.Context, bucket, key string) error {
+	return r.db.WithTx(func(tx *sql.Tx) error {
+		return r.softDelete(ctx, r.db.bind(tx), bucket, key)
+	})
+}
+
+// softDelete 使用指定执行器软删除元数据，调用方需保证在事务中执行
+func (r *MetadataRepository) softDelete(ctx context.Context, exec dbExecutor, bucket, key string) error {
+	current, err := r.lockMetadata(ctx, exec, bucket, key)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, bucket, key)
+	}
+	if err := r.checkRetention(ctx, exec, current); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE metadata
 		SET deleted_at = $1, status = 'deleted', updated_at = $1
-		WHERE bucket = $2 AND key = $3 AND deleted_at IS NULL
+		WHERE id = $2 AND deleted_at IS NULL
 	`
 
 	now := time.Now()
-	result, err := r.db.GetDB().ExecContext(ctx, query, now, bucket, key)
+	result, err := exec.ExecContext(ctx, query, now, current.ID)
 	if err != nil {
 		return fmt.Errorf("failed to delete metadata: %w", err)
 	}
@@ -171,30 +418,169 @@ func (r *MetadataRepository) Delete(ctx context.Context, bucket, key string) err
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("metadata not found: %s/%s", bucket, key)
+		return fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, bucket, key)
 	}
 
-	return nil
+	if err := r.adjustPrefixCounters(ctx, exec, bucket, key, -1, -current.Size); err != nil {
+		return err
+	}
+	return r.recordAudit(ctx, exec, models.MetadataAuditDelete, current, nil)
+}
+
+// errBatchAborted 原子批量操作中止
+var errBatchAborted = errors.New("batch aborted")
+
+// ExecuteBatch 在同一事务中执行批量操作，返回逐项结果以及事务是否提交
+// 非原子模式下每项使用SAVEPOINT隔离，失败项回滚而不影响其他项
+func (r *MetadataRepository) ExecuteBatch(ctx context.Context, ops []*models.MetadataBatchOperation, atomic bool) ([]*models.MetadataBatchItemResult, bool, error) {
+	results := make([]*models.MetadataBatchItemResult, len(ops))
+	for i, op := range ops {
+		results[i] = &models.MetadataBatchItemResult{Index: i, Op: op.Op, Bucket: op.Bucket, Key: op.Key}
+	}
+
+	err := r.db.WithTx(func(tx *sql.Tx) error {
+		for i, op := range ops {
+			if !atomic {
+				if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+					return fmt.Errorf("failed to create savepoint: %w", err)
+				}
+			}
+
+			opErr := r.executeBatchOp(ctx, r.db.bind(tx), op)
+			if opErr == nil {
+				results[i].Status = models.BatchItemSucceeded
+				results[i].Metadata = op.Metadata
+				if !atomic {
+					if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item"); err != nil {
+						return fmt.Errorf("failed to release savepoint: %w", err)
+					}
+				}
+				continue
+			}
+
+			results[i].Status = models.BatchItemFailed
+			results[i].Error = opErr.Error()
+			if atomic {
+				return errBatchAborted
+			}
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item"); err != nil {
+				return fmt.Errorf("failed to rollback savepoint: %w", err)
+			}
+		}
+		return nil
+	})
+
+	if err == errBatchAborted {
+		for _, result := range results {
+			if result.Status != models.BatchItemFailed {
+				result.Status = models.BatchItemAborted
+				result.Metadata = nil
+			}
+		}
+		return results, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to execute batch: %w", err)
+	}
+
+	return results, true, nil
+}
+
+// executeBatchOp 执行单个批量操作
+func (r *MetadataRepository) executeBatchOp(ctx context.Context, exec dbExecutor, op *models.MetadataBatchOperation) error {
+	switch op.Op {
+	case models.BatchOpCreate:
+		return r.create(ctx, exec, op.Metadata)
+	case models.BatchOpUpdate:
+		return r.update(ctx, exec, op.Metadata)
+	case models.BatchOpDelete:
+		return r.softDelete(ctx, exec, op.Bucket, op.Key)
+	default:
+		return fmt.Errorf("unsupported batch operation: %s", op.Op)
+	}
+}
+
+// Restore 恢复最近一次软删除的元数据（仅限deletedAfter之后删除的记录）
+func (r *MetadataRepository) Restore(ctx context.Context, bucket, key string, deletedAfter time.Time) (*models.Metadata, error) {
+	var metadata *models.Metadata
+	err := r.db.WithTx(func(tx *sql.Tx) error {
+		exec := r.db.bind(tx)
+
+		var id string
+		err := exec.QueryRowContext(ctx, `
+			SELECT id FROM metadata
+			WHERE tenant = $1 AND bucket = $2 AND key = $3 AND deleted_at IS NOT NULL AND deleted_at > $4
+			ORDER BY deleted_at DESC
+			LIMIT 1
+		`, models.TenantFromContext(ctx), bucket, key, deletedAfter).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		if _, err := exec.ExecContext(ctx,
+			"UPDATE metadata SET deleted_at = NULL, status = 'active', updated_at = $1 WHERE id = $2",
+			time.Now(), id); err != nil {
+			return err
+		}
+
+		row := exec.QueryRowContext(ctx, `
+			SELECT id, key, bucket, size, content_type, md5_hash, etag,
+				   storage_nodes, headers, tags, status, version,
+				   created_at, updated_at, deleted_at
+			FROM metadata
+			WHERE id = $1
+		`, id)
+		metadata, err = r.scanMetadata(row)
+		if err != nil {
+			return err
+		}
+		if err := r.adjustPrefixCounters(ctx, exec, metadata.Bucket, metadata.Key, 1, metadata.Size); err != nil {
+			return err
+		}
+		return r.recordAudit(ctx, exec, models.MetadataAuditRestore, nil, metadata)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: no deleted version of %s/%s", models.ErrMetadataNotFound, bucket, key)
+		}
+		return nil, fmt.Errorf("failed to restore metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// PurgeDeleted 物理删除deletedBefore之前软删除的记录（所有租户）
+func (r *MetadataRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	result, err := r.db.executor().ExecContext(ctx, r.db.dialect.purgeDeletedQuery(), deletedBefore, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted metadata: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return purged, nil
 }
 
 // List 列出元数据
 func (r *MetadataRepository) List(ctx context.Context, bucket, prefix string, limit, offset int) ([]*models.Metadata, error) {
-	var args []interface{}
-	var conditions []string
-	argIndex := 1
-
-	conditions = append(conditions, "deleted_at IS NULL")
+	return r.ListFiltered(ctx, &models.MetadataFilter{Bucket: bucket, Prefix: prefix}, limit, offset)
+}
 
-	if bucket != "" {
-		conditions = append(conditions, fmt.Sprintf("bucket = $%d", argIndex))
-		args = append(args, bucket)
-		argIndex++
+// ListFiltered 按过滤条件列出元数据
+// 指定修改时间窗口时按updated_at升序返回，便于增量同步
+func (r *MetadataRepository) ListFiltered(ctx context.Context, filter *models.MetadataFilter, limit, offset int) ([]*models.Metadata, error) {
+	conditions, args, err := r.buildFilterConditions(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
+	argIndex := len(args) + 1
 
-	if prefix != "" {
-		conditions = append(conditions, fmt.Sprintf("key LIKE $%d", argIndex))
-		args = append(args, prefix+"%")
-		argIndex++
+	orderBy := "created_at DESC"
+	if filter.HasModifiedRange() {
+		orderBy = "updated_at ASC, bucket ASC, key ASC"
 	}
 
 	query := fmt.Sprintf(`
@@ -203,13 +589,73 @@ func (r *MetadataRepository) List(ctx context.Context, bucket, prefix string, li
 			   created_at, updated_at, deleted_at
 		FROM metadata
 		WHERE %s
-		ORDER BY created_at DESC
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, strings.Join(conditions, " AND "), argIndex, argIndex+1)
+	`, strings.Join(conditions, " AND "), orderBy, argIndex, argIndex+1)
 
 	args = append(args, limit, offset)
 
-	rows, err := r.db.GetDB().QueryContext(ctx, query, args...)
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metadata: %w", err)
+	}
+	defer rows.Close()
+
+	var metadataList []*models.Metadata
+	for rows.Next() {
+		metadata, err := r.scanMetadata(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan metadata: %w", err)
+		}
+		metadataList = append(metadataList, metadata)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return metadataList, nil
+}
+
+// ListAfter 基于游标的keyset分页
+// 默认按(bucket, key)排序；指定修改时间窗口时按(updated_at, bucket, key)排序
+func (r *MetadataRepository) ListAfter(ctx context.Context, filter *models.MetadataFilter, cursor *models.MetadataCursor, limit int) ([]*models.Metadata, error) {
+	conditions, args, err := r.buildFilterConditions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	byModified := filter.HasModifiedRange()
+	orderBy := "bucket ASC, key ASC"
+	if byModified {
+		orderBy = "updated_at ASC, bucket ASC, key ASC"
+	}
+
+	if cursor != nil {
+		if byModified {
+			if cursor.UpdatedAt == nil {
+				return nil, fmt.Errorf("%w: continuation token is missing the modification time", models.ErrInvalidArgument)
+			}
+			args = append(args, *cursor.UpdatedAt, cursor.Bucket, cursor.Key)
+			conditions = append(conditions, fmt.Sprintf("(updated_at, bucket, key) > ($%d, $%d, $%d)", len(args)-2, len(args)-1, len(args)))
+		} else {
+			args = append(args, cursor.Bucket, cursor.Key)
+			conditions = append(conditions, fmt.Sprintf("(bucket, key) > ($%d, $%d)", len(args)-1, len(args)))
+		}
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		SELECT id, key, bucket, size, content_type, md5_hash, etag,
+			   storage_nodes, headers, tags, status, version,
+			   created_at, updated_at, deleted_at
+		FROM metadata
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), orderBy, len(args))
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list metadata: %w", err)
 	}
@@ -231,25 +677,271 @@ func (r *MetadataRepository) List(ctx context.Context, bucket, prefix string, li
 	return metadataList, nil
 }
 
+// ListDelimited 按分隔符分层列出bucket下的对象和公共前缀，按名称升序，after为上一页最后一项的名称
+//
+// 前缀截断和去重在SQL中完成：Prefix之后包含分隔符的key截断到第一个分隔符（含）作为公共前缀，
+// 与其余key一起DISTINCT后分页，再只为本页的对象读取完整元数据。
+func (r *MetadataRepository) ListDelimited(ctx context.Context, filter *models.MetadataFilter, after string, limit int) ([]*models.MetadataListEntry, error) {
+	if filter == nil || filter.Bucket == "" {
+		return nil, fmt.Errorf("%w: bucket is required when delimiter is set", models.ErrInvalidArgument)
+	}
+	if filter.Delimiter == "" {
+		return nil, fmt.Errorf("%w: delimiter is required", models.ErrInvalidArgument)
+	}
+
+	conditions, args, err := r.buildFilterConditions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按字符计算位置，与substr/strpos一致
+	prefixLen := utf8.RuneCountInString(filter.Prefix)
+	delimiterLen := utf8.RuneCountInString(filter.Delimiter)
+	args = append(args, prefixLen+1, filter.Delimiter, prefixLen+delimiterLen-1)
+	startArg, delimiterArg, baseArg := len(args)-2, len(args)-1, len(args)
+
+	rest := fmt.Sprintf("substr(key, $%d)", startArg)
+	position := r.db.dialect.strpos(rest, fmt.Sprintf("$%d", delimiterArg))
+	entry := fmt.Sprintf("CASE WHEN %s > 0 THEN substr(key, 1, $%d + %s) ELSE key END", position, baseArg, position)
+
+	outer := ""
+	if after != "" {
+		args = append(args, after)
+		outer = fmt.Sprintf("WHERE entry > $%d", len(args))
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		SELECT entry FROM (
+			SELECT DISTINCT %s AS entry
+			FROM metadata
+			WHERE %s
+		) entries
+		%s
+		ORDER BY entry
+		LIMIT $%d
+	`, entry, strings.Join(conditions, " AND "), outer, len(args))
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metadata: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.MetadataListEntry
+	var keys []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan listing entry: %w", err)
+		}
+		// 公共前缀在Prefix之后包含分隔符，对象key则不包含
+		rest, _ := strings.CutPrefix(name, filter.Prefix)
+		if strings.Contains(rest, filter.Delimiter) {
+			entries = append(entries, &models.MetadataListEntry{Prefix: name})
+			continue
+		}
+		entries = append(entries, &models.MetadataListEntry{Metadata: &models.Metadata{Key: name}})
+		keys = append(keys, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return entries, nil
+	}
+
+	found, err := r.getByKeys(ctx, filter.Bucket, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// 两次查询之间被删除的对象从结果中去掉
+	result := entries[:0]
+	for _, e := range entries {
+		if e.Prefix == "" {
+			metadata, ok := found[e.Metadata.Key]
+			if !ok {
+				continue
+			}
+			e.Metadata = metadata
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// getByKeys 批量读取bucket下未删除的对象元数据
+func (r *MetadataRepository) getByKeys(ctx context.Context, bucket string, keys []string) (map[string]*models.Metadata, error) {
+	args := make([]interface{}, 0, len(keys)+2)
+	args = append(args, models.TenantFromContext(ctx), bucket)
+	placeholders := make([]string, len(keys))
+	for i, key := range keys {
+		args = append(args, key)
+		placeholders[i] = fmt.Sprintf("$%d", len(args))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, key, bucket, size, content_type, md5_hash, etag,
+			   storage_nodes, headers, tags, status, version,
+			   created_at, updated_at, deleted_at
+		FROM metadata
+		WHERE tenant = $1 AND bucket = $2 AND key IN (%s) AND deleted_at IS NULL
+	`, strings.Join(placeholders, ", "))
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[string]*models.Metadata, len(keys))
+	for rows.Next() {
+		metadata, err := r.scanMetadata(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan metadata: %w", err)
+		}
+		found[metadata.Key] = metadata
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return found, nil
+}
+
+// buildFilterConditions 根据过滤器构建WHERE条件和参数，总是限定在ctx中的租户内
+func (r *MetadataRepository) buildFilterConditions(ctx context.Context, filter *models.MetadataFilter) ([]string, []interface{}, error) {
+	var args []interface{}
+	conditions := []string{"deleted_at IS NULL"}
+
+	add := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	add("tenant = $%d", models.TenantFromContext(ctx))
+
+	if filter == nil {
+		return conditions, args, nil
+	}
+
+	if filter.Bucket != "" {
+		add("bucket = $%d", filter.Bucket)
+	}
+	if filter.Prefix != "" {
+		add("key LIKE $%d", filter.Prefix+"%")
+	}
+	if filter.Status != "" {
+		add("status = $%d", filter.Status)
+	}
+	if prefix, ok := filter.ContentTypePrefix(); ok {
+		add("content_type LIKE $%d", prefix+"%")
+	} else if filter.ContentType != "" {
+		add("content_type = $%d", filter.ContentType)
+	}
+	if len(filter.Tags) > 0 {
+		condition, err := r.db.dialect.tagsContain(filter.Tags, &args)
+		if err != nil {
+			return nil, nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	if filter.SizeMin != nil {
+		add("size >= $%d", *filter.SizeMin)
+	}
+	if filter.SizeMax != nil {
+		add("size <= $%d", *filter.SizeMax)
+	}
+	if filter.CreatedFrom != nil {
+		add("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		add("created_at <= $%d", *filter.CreatedTo)
+	}
+	if filter.ModifiedAfter != nil {
+		add("updated_at > $%d", *filter.ModifiedAfter)
+	}
+	if filter.ModifiedBefore != nil {
+		add("updated_at < $%d", *filter.ModifiedBefore)
+	}
+	if filter.StartAfter != "" {
+		add("key > $%d", filter.StartAfter)
+	}
+	if filter.EndKey != "" {
+		add("key <= $%d", filter.EndKey)
+	}
+	if filter.TagQuery != nil {
+		condition, err := r.buildTagExprCondition(filter.TagQuery, &args)
+		if err != nil {
+			return nil, nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, args, nil
+}
+
+// buildTagExprCondition 将标签表达式转换为SQL条件
+// PostgreSQL下=和存在判断使用 @> / ? 运算符，可命中tags列的GIN索引；
+// 取反时tags为NULL的行按"标签不存在"处理
+func (r *MetadataRepository) buildTagExprCondition(expr *models.TagExpr, args *[]interface{}) (string, error) {
+	switch expr.Op {
+	case models.TagExprAnd, models.TagExprOr:
+		parts := make([]string, 0, len(expr.Children))
+		for _, child := range expr.Children {
+			part, err := r.buildTagExprCondition(child, args)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+		return "(" + strings.Join(parts, " "+strings.ToUpper(expr.Op)+" ") + ")", nil
+	case models.TagExprNot:
+		if len(expr.Children) != 1 {
+			return "", fmt.Errorf("%w: tag query NOT requires exactly one operand", models.ErrInvalidArgument)
+		}
+		child, err := r.buildTagExprCondition(expr.Children[0], args)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("NOT COALESCE(%s, false)", child), nil
+	case models.TagExprEq, models.TagExprNe:
+		condition, err := r.db.dialect.tagsContain(map[string]string{expr.Key: expr.Value}, args)
+		if err != nil {
+			return "", err
+		}
+		if expr.Op == models.TagExprNe {
+			return fmt.Sprintf("NOT COALESCE(%s, false)", condition), nil
+		}
+		return condition, nil
+	case models.TagExprExists:
+		return r.db.dialect.tagExists(expr.Key, args), nil
+	}
+	return "", fmt.Errorf("%w: tag query has unknown operator %s", models.ErrInvalidArgument, expr.Op)
+}
+
 // Search 搜索元数据
 func (r *MetadataRepository) Search(ctx context.Context, query string, limit int) ([]*models.Metadata, error) {
-	sqlQuery := `
+	d := r.db.dialect
+	sqlQuery := fmt.Sprintf(`
 		SELECT id, key, bucket, size, content_type, md5_hash, etag,
 			   storage_nodes, headers, tags, status, version,
 			   created_at, updated_at, deleted_at
 		FROM metadata
-		WHERE deleted_at IS NULL AND (
-			key ILIKE $1 OR
-			bucket ILIKE $1 OR
-			content_type ILIKE $1 OR
-			tags::text ILIKE $1
+		WHERE deleted_at IS NULL AND tenant = $3 AND (
+			%s OR
+			%s OR
+			%s OR
+			%s
 		)
 		ORDER BY created_at DESC
 		LIMIT $2
-	`
+	`, d.ilike("key", "$1"), d.ilike("bucket", "$1"), d.ilike("content_type", "$1"), d.ilike(d.jsonText("tags"), "$1"))
 
 	searchPattern := "%" + query + "%"
-	rows, err := r.db.GetDB().QueryContext(ctx, sqlQuery, searchPattern, limit)
+	rows, err := r.db.reader(ctx).QueryContext(ctx, sqlQuery, searchPattern, limit, models.TenantFromContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to search metadata: %w", err)
 	}
@@ -271,14 +963,67 @@ func (r *MetadataRepository) Search(ctx context.Context, query string, limit int
 	return metadataList, nil
 }
 
-// Count 计数
+// SampleKeys 随机采样对象键（按键排序返回）
+func (r *MetadataRepository) SampleKeys(ctx context.Context, bucket, prefix string, sampleSize int) ([]string, error) {
+	conditions, args, err := r.buildFilterConditions(ctx, &models.MetadataFilter{Bucket: bucket, Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+
+	args = append(args, sampleSize)
+	query := fmt.Sprintf(`
+		SELECT key FROM (
+			SELECT key
+			FROM metadata
+			WHERE %s
+			ORDER BY %s
+			LIMIT $%d
+		) sample
+		ORDER BY key
+	`, strings.Join(conditions, " AND "), r.db.dialect.random(), len(args))
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Count 计数，空前缀和目录前缀直接读取前缀计数（见Usage）
 func (r *MetadataRepository) Count(ctx context.Context, bucket, prefix string) (int64, error) {
+	if isCounterPrefix(prefix) {
+		usage, err := r.Usage(ctx, bucket, prefix)
+		if err != nil {
+			return 0, err
+		}
+		return usage.Objects, nil
+	}
+
 	var args []interface{}
 	var conditions []string
 	argIndex := 1
 
 	conditions = append(conditions, "deleted_at IS NULL")
 
+	conditions = append(conditions, fmt.Sprintf("tenant = $%d", argIndex))
+	args = append(args, models.TenantFromContext(ctx))
+	argIndex++
+
 	if bucket != "" {
 		conditions = append(conditions, fmt.Sprintf("bucket = $%d", argIndex))
 		args = append(args, bucket)
@@ -298,7 +1043,7 @@ func (r *MetadataRepository) Count(ctx context.Context, bucket, prefix string) (
 	`, strings.Join(conditions, " AND "))
 
 	var count int64
-	err := r.db.GetDB().QueryRowContext(ctx, query, args...).Scan(&count)
+	err := r.db.reader(ctx).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count metadata: %w", err)
 	}
@@ -306,59 +1051,130 @@ func (r *MetadataRepository) Count(ctx context.Context, bucket, prefix string) (
 	return count, nil
 }
 
-// GetStats 获取统计信息
-func (r *MetadataRepository) GetStats(ctx context.Context) (*models.Stats, error) {
-	// 基础统计
-	baseQuery := `
-		SELECT 
-			COUNT(*) as total_objects,
-			COALESCE(SUM(size), 0) as total_size,
-			COALESCE(AVG(size), 0) as average_size
-		FROM metadata
-		WHERE deleted_at IS NULL
-	`
-
-	var stats models.Stats
-	err := r.db.GetDB().QueryRowContext(ctx, baseQuery).Scan(
-		&stats.TotalObjects,
-		&stats.TotalSize,
-		&stats.AverageSize,
-	)
+// SummarizeByAge 统计过滤范围内的对象数和字节数，以及最后修改时间早于各cutoff的部分
+// samplePercent在(0,100)之间时按比例采样（PostgreSQL使用TABLESAMPLE SYSTEM按数据页采样），结果未按比例放大
+func (r *MetadataRepository) SummarizeByAge(ctx context.Context, filter *models.MetadataFilter, cutoffs []time.Time, samplePercent float64) (*models.ObjectSummary, []*models.ObjectSummary, error) {
+	conditions, args, err := r.buildFilterConditions(ctx, filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get base stats: %w", err)
+		return nil, nil, err
 	}
 
-	// 按bucket统计
-	bucketQuery := `
-		SELECT bucket, COUNT(*)
-		FROM metadata
-		WHERE deleted_at IS NULL
-		GROUP BY bucket
-	`
-	bucketRows, err := r.db.GetDB().QueryContext(ctx, bucketQuery)
+	columns := []string{"COUNT(*)", "COALESCE(SUM(size), 0)"}
+	for _, cutoff := range cutoffs {
+		args = append(args, cutoff)
+		columns = append(columns,
+			fmt.Sprintf("COALESCE(SUM(CASE WHEN updated_at < $%d THEN 1 ELSE 0 END), 0)", len(args)),
+			fmt.Sprintf("COALESCE(SUM(CASE WHEN updated_at < $%d THEN size ELSE 0 END), 0)", len(args)))
+	}
+
+	from := "metadata"
+	if samplePercent > 0 && samplePercent < 100 {
+		var condition string
+		from, condition = r.db.dialect.sample(from, samplePercent, &args)
+		if condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE %s
+	`, strings.Join(columns, ", "), from, strings.Join(conditions, " AND "))
+
+	total := &models.ObjectSummary{}
+	older := make([]*models.ObjectSummary, len(cutoffs))
+	dest := []interface{}{&total.Objects, &total.Bytes}
+	for i := range older {
+		older[i] = &models.ObjectSummary{}
+		dest = append(dest, &older[i].Objects, &older[i].Bytes)
+	}
+
+	if err := r.db.reader(ctx).QueryRowContext(ctx, query, args...).Scan(dest...); err != nil {
+		return nil, nil, fmt.Errorf("failed to summarize metadata: %w", err)
+	}
+
+	return total, older, nil
+}
+
+// TagCounts 统计标签键值对的使用次数，按次数降序
+func (r *MetadataRepository) TagCounts(ctx context.Context, bucket string, limit int) ([]*models.TagCount, error) {
+	from, isObject := r.db.dialect.tagPairs()
+	tagKey, tagValue := r.db.dialect.tagPairColumns()
+
+	args := []interface{}{models.TenantFromContext(ctx)}
+	conditions := []string{"metadata.deleted_at IS NULL", "metadata.tenant = $1", isObject}
+
+	if bucket != "" {
+		conditions = append(conditions, "metadata.bucket = $2")
+		args = append(args, bucket)
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		SELECT tag_key, tag_value, COUNT(*) AS count
+		FROM (
+			SELECT %s AS tag_key, %s AS tag_value
+			FROM %s
+			WHERE %s
+		) pairs
+		GROUP BY tag_key, tag_value
+		ORDER BY count DESC, tag_key, tag_value
+		LIMIT $%d
+	`, tagKey, tagValue, from, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get bucket stats: %w", err)
+		return nil, fmt.Errorf("failed to count tags: %w", err)
 	}
-	defer bucketRows.Close()
+	defer rows.Close()
 
+	var counts []*models.TagCount
+	for rows.Next() {
+		var count models.TagCount
+		if err := rows.Scan(&count.Key, &count.Value, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag count: %w", err)
+		}
+		counts = append(counts, &count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetStats 获取ctx中租户的统计信息（实时计算）
+func (r *MetadataRepository) GetStats(ctx context.Context) (*models.Stats, error) {
+	var stats models.Stats
+
+	// 按bucket汇总，总量由各bucket累加
+	rollups, err := r.bucketRollups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.Buckets = rollups
 	stats.BucketStats = make(map[string]int64)
-	for bucketRows.Next() {
-		var bucket string
-		var count int64
-		if err := bucketRows.Scan(&bucket, &count); err != nil {
-			return nil, fmt.Errorf("failed to scan bucket stats: %w", err)
+	for bucket, rollup := range rollups {
+		stats.TotalObjects += rollup.Objects
+		stats.TotalSize += rollup.Bytes
+		if rollup.Objects > 0 {
+			stats.BucketStats[bucket] = rollup.Objects
 		}
-		stats.BucketStats[bucket] = count
+	}
+	if stats.TotalObjects > 0 {
+		stats.AverageSize = float64(stats.TotalSize) / float64(stats.TotalObjects)
 	}
 
 	// 按内容类型统计
 	contentTypeQuery := `
 		SELECT content_type, COUNT(*)
 		FROM metadata
-		WHERE deleted_at IS NULL AND content_type IS NOT NULL
+		WHERE deleted_at IS NULL AND content_type IS NOT NULL AND tenant = $1
 		GROUP BY content_type
 	`
-	ctRows, err := r.db.GetDB().QueryContext(ctx, contentTypeQuery)
+	ctRows, err := r.db.reader(ctx).QueryContext(ctx, contentTypeQuery, models.TenantFromContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get content type stats: %w", err)
 	}
@@ -378,30 +1194,155 @@ func (r *MetadataRepository) GetStats(ctx context.Context) (*models.Stats, error
 	return &stats, nil
 }
 
-// scanMetadata 扫描元数据行
-func (r *MetadataRepository) scanMetadata(scanner interface{}) (*models.Metadata, error) {
+// bucketRollups 按bucket汇总对象数、字节数和最近活动时间（含软删除记录的删除时间）
+func (r *MetadataRepository) bucketRollups(ctx context.Context) (map[string]*models.BucketRollup, error) {
+	query := `
+		SELECT bucket,
+			   COALESCE(SUM(CASE WHEN deleted_at IS NULL THEN 1 ELSE 0 END), 0),
+			   COALESCE(SUM(CASE WHEN deleted_at IS NULL THEN size ELSE 0 END), 0),
+			   MAX(updated_at), MAX(deleted_at)
+		FROM metadata
+		WHERE tenant = $1
+		GROUP BY bucket
+	`
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query, models.TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket rollups: %w", err)
+	}
+	defer rows.Close()
+
+	rollups := make(map[string]*models.BucketRollup)
+	for rows.Next() {
+		var bucket string
+		var rollup models.BucketRollup
+		var lastUpdated, lastDeleted nullTime
+		if err := rows.Scan(&bucket, &rollup.Objects, &rollup.Bytes, &lastUpdated, &lastDeleted); err != nil {
+			return nil, fmt.Errorf("failed to scan bucket rollup: %w", err)
+		}
+
+		rollup.LastActivity = lastUpdated.Ptr()
+		if lastDeleted.Valid && (!lastUpdated.Valid || lastDeleted.Time.After(lastUpdated.Time)) {
+			rollup.LastActivity = lastDeleted.Ptr()
+		}
+		rollups[bucket] = &rollup
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return rollups, nil
+}
+
+// ListTenants 按租户汇总对象数、字节数、bucket数和最近活动时间（含只剩软删除记录的租户）
+func (r *MetadataRepository) ListTenants(ctx context.Context) ([]*models.TenantStats, error) {
+	query := `
+		SELECT tenant,
+			   COALESCE(SUM(CASE WHEN deleted_at IS NULL THEN 1 ELSE 0 END), 0),
+			   COALESCE(SUM(CASE WHEN deleted_at IS NULL THEN size ELSE 0 END), 0),
+			   COUNT(DISTINCT CASE WHEN deleted_at IS NULL THEN bucket END),
+			   MAX(updated_at), MAX(deleted_at)
+		FROM metadata
+		GROUP BY tenant
+		ORDER BY tenant
+	`
+
+	rows, err := r.db.reader(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []*models.TenantStats
+	for rows.Next() {
+		var stats models.TenantStats
+		var lastUpdated, lastDeleted nullTime
+		if err := rows.Scan(&stats.Tenant, &stats.Objects, &stats.Bytes, &stats.Buckets, &lastUpdated, &lastDeleted); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant stats: %w", err)
+		}
+
+		stats.LastActivity = lastUpdated.Ptr()
+		if lastDeleted.Valid && (!lastUpdated.Valid || lastDeleted.Time.After(lastUpdated.Time)) {
+			stats.LastActivity = lastDeleted.Ptr()
+		}
+		tenants = append(tenants, &stats)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// SaveStatsSnapshot 保存各租户的统计快照到stats_cache（只保留一行）
+func (r *MetadataRepository) SaveStatsSnapshot(ctx context.Context, snapshots map[string]*models.Stats) error {
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+
+	return r.db.WithTx(func(tx *sql.Tx) error {
+		exec := r.db.bind(tx)
+		if _, err := exec.ExecContext(ctx, "DELETE FROM stats_cache"); err != nil {
+			return fmt.Errorf("failed to clear stats cache: %w", err)
+		}
+		_, err := exec.ExecContext(ctx,
+			"INSERT INTO stats_cache (id, stats_data, created_at, updated_at) VALUES (1, $1, $2, $2)",
+			data, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to save stats snapshot: %w", err)
+		}
+		return nil
+	})
+}
+
+// LoadStatsSnapshot 读取最近保存的各租户统计快照，不存在时返回nil
+//
+// 启用多租户前保存的单份快照按default租户返回。
+func (r *MetadataRepository) LoadStatsSnapshot(ctx context.Context) (map[string]*models.Stats, error) {
+	var data []byte
+	err := r.db.executor().QueryRowContext(ctx, "SELECT stats_data FROM stats_cache WHERE id = 1").Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stats snapshot: %w", err)
+	}
+
+	var snapshots map[string]*models.Stats
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		var stats models.Stats
+		if legacyErr := json.Unmarshal(data, &stats); legacyErr != nil {
+			return nil, fmt.Errorf("failed to unmarshal stats snapshot: %w", err)
+		}
+		snapshots = map[string]*models.Stats{models.DefaultTenant: &stats}
+	}
+	return snapshots, nil
+}
+
+// scanMetadata 扫描元数据行，extra为附加在标准列之后的额外列
+func (r *MetadataRepository) scanMetadata(scanner interface{}, extra ...interface{}) (*models.Metadata, error) {
 	var metadata models.Metadata
 	var storageNodesJSON, headersJSON, tagsJSON []byte
 	var deletedAt sql.NullTime
 
+	dest := []interface{}{
+		&metadata.ID, &metadata.Key, &metadata.Bucket, &metadata.Size,
+		&metadata.ContentType, &metadata.MD5Hash, &metadata.ETag,
+		&storageNodesJSON, &headersJSON, &tagsJSON,
+		&metadata.Status, &metadata.Version,
+		&metadata.CreatedAt, &metadata.UpdatedAt, &deletedAt,
+	}
+	dest = append(dest, extra...)
+
 	var err error
 	switch s := scanner.(type) {
 	case *sql.Row:
-		err = s.Scan(
-			&metadata.ID, &metadata.Key, &metadata.Bucket, &metadata.Size,
-			&metadata.ContentType, &metadata.MD5Hash, &metadata.ETag,
-			&storageNodesJSON, &headersJSON, &tagsJSON,
-			&metadata.Status, &metadata.Version,
-			&metadata.CreatedAt, &metadata.UpdatedAt, &deletedAt,
-		)
+		err = s.Scan(dest...)
 	case *sql.Rows:
-		err = s.Scan(
-			&metadata.ID, &metadata.Key, &metadata.Bucket, &metadata.Size,
-			&metadata.ContentType, &metadata.MD5Hash, &metadata.ETag,
-			&storageNodesJSON, &headersJSON, &tagsJSON,
-			&metadata.Status, &metadata.Version,
-			&metadata.CreatedAt, &metadata.UpdatedAt, &deletedAt,
-		)
+		err = s.Scan(dest...)
 	default:
 		return nil, fmt.Errorf("unsupported scanner type")
 	}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mocks3/shared/models"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// newTestMetadata 创建测试用元数据
+func newTestMetadata(bucket, key string, tags map[string]string) *models.Metadata {
+	return &models.Metadata{
+		Key:          key,
+		Bucket:       bucket,
+		Size:         int64(len(key)),
+		ContentType:  "text/plain",
+		MD5Hash:      "md5-" + key,
+		ETag:         "etag-" + key,
+		StorageNodes: []string{"stg1", "stg2"},
+		Headers:      map[string]string{"X-Test": key},
+		Tags:         tags,
+		Status:       "active",
+		Version:      1,
+	}
+}
+
+// keysOf 元数据的key，按字典序
+func keysOf(list []*models.Metadata) []string {
+	keys := make([]string, 0, len(list))
+	for _, metadata := range list {
+		keys = append(keys, metadata.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestMetadataRepositoryCRUD(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *Database) {
+		ctx := context.Background()
+		repo := NewMetadataRepository(db)
+
+		// key是MySQL保留字，读写均依赖rebind为列名加反引号
+		metadata := newTestMetadata("bucket", "dir/key.txt", map[string]string{"env": "dev"})
+		if err := repo.Create(ctx, metadata); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		got, err := repo.GetByKey(ctx, "bucket", "dir/key.txt")
+		if err != nil {
+			t.Fatalf("GetByKey: %v", err)
+		}
+		if got.ID != metadata.ID || got.Key != metadata.Key || got.Size != metadata.Size || got.Version != 1 {
+			t.Errorf("GetByKey = %+v, want %+v", got, metadata)
+		}
+		if !reflect.DeepEqual(got.StorageNodes, metadata.StorageNodes) || !reflect.DeepEqual(got.Tags, metadata.Tags) {
+			t.Errorf("json columns = %v %v, want %v %v", got.StorageNodes, got.Tags, metadata.StorageNodes, metadata.Tags)
+		}
+
+		if _, err := repo.GetByKey(ctx, "bucket", "missing"); !errors.Is(err, models.ErrMetadataNotFound) {
+			t.Errorf("GetByKey missing error = %v, want ErrMetadataNotFound", err)
+		}
+
+		metadata.Size = 42
+		metadata.Tags = map[string]string{"env": "prod"}
+		if err := repo.Update(ctx, metadata); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if metadata.Version != 2 {
+			t.Errorf("version after update = %d, want 2", metadata.Version)
+		}
+		got, err = repo.GetByKey(ctx, "bucket", "dir/key.txt")
+		if err != nil {
+			t.Fatalf("GetByKey after update: %v", err)
+		}
+		if got.Size != 42 || got.Version != 2 || got.Tags["env"] != "prod" {
+			t.Errorf("after update = size %d, version %d, tags %v", got.Size, got.Version, got.Tags)
+		}
+
+		list, err := repo.List(ctx, "bucket", "dir/", 10, 0)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(list) != 1 || list[0].Key != "dir/key.txt" {
+			t.Errorf("List = %v, want [dir/key.txt]", keysOf(list))
+		}
+	})
+}
+
+func TestMetadataRepositoryHistory(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *Database) {
+		ctx := context.Background()
+		repo := NewMetadataRepository(db)
+
+		metadata := newTestMetadata("bucket", "key", nil)
+		if err := repo.Create(ctx, metadata); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		for size := int64(10); size <= 20; size += 10 {
+			metadata.Size = size
+			if err := repo.Update(ctx, metadata); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+		}
+
+		// 每次更新归档更新前的版本，按版本号倒序返回
+		versions, err := repo.ListHistory(ctx, "bucket", "key", 10, 0)
+		if err != nil {
+			t.Fatalf("ListHistory: %v", err)
+		}
+		if len(versions) != 2 || versions[0].Version != 2 || versions[1].Version != 1 {
+			t.Fatalf("ListHistory = %d versions, want versions 2 and 1", len(versions))
+		}
+		if versions[0].Size != 10 || versions[1].Size != int64(len("key")) {
+			t.Errorf("history sizes = %d, %d", versions[0].Size, versions[1].Size)
+		}
+		if versions[0].ArchivedAt.IsZero() {
+			t.Error("archived_at is not set")
+		}
+
+		version, err := repo.GetVersion(ctx, "bucket", "key", 2)
+		if err != nil {
+			t.Fatalf("GetVersion: %v", err)
+		}
+		if version.Size != 10 {
+			t.Errorf("GetVersion size = %d, want 10", version.Size)
+		}
+		if _, err := repo.GetVersion(ctx, "bucket", "key", 3); !errors.Is(err, models.ErrMetadataNotFound) {
+			t.Errorf("GetVersion current version error = %v, want ErrMetadataNotFound", err)
+		}
+	})
+}
+
+func TestMetadataRepositorySoftDeleteRestore(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *Database) {
+		ctx := context.Background()
+		repo := NewMetadataRepository(db)
+
+		before := time.Now().Add(-time.Minute)
+		metadata := newTestMetadata("bucket", "key", nil)
+		if err := repo.Create(ctx, metadata); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		if err := repo.Delete(ctx, "bucket", "key"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByKey(ctx, "bucket", "key"); !errors.Is(err, models.ErrMetadataNotFound) {
+			t.Errorf("GetByKey after delete error = %v, want ErrMetadataNotFound", err)
+		}
+		if err := repo.Delete(ctx, "bucket", "key"); !errors.Is(err, models.ErrMetadataNotFound) {
+			t.Errorf("second Delete error = %v, want ErrMetadataNotFound", err)
+		}
+
+		// 只恢复deletedAfter之后删除的记录
+		if _, err := repo.Restore(ctx, "bucket", "key", time.Now().Add(time.Minute)); !errors.Is(err, models.ErrMetadataNotFound) {
+			t.Errorf("Restore outside window error = %v, want ErrMetadataNotFound", err)
+		}
+		restored, err := repo.Restore(ctx, "bucket", "key", before)
+		if err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if restored.ID != metadata.ID || restored.Status != "active" || restored.DeletedAt != nil {
+			t.Errorf("Restore = id %s, status %s, deleted_at %v", restored.ID, restored.Status, restored.DeletedAt)
+		}
+		if _, err := repo.GetByKey(ctx, "bucket", "key"); err != nil {
+			t.Errorf("GetByKey after restore: %v", err)
+		}
+
+		// 物理删除早于指定时间的软删除记录
+		if err := repo.Delete(ctx, "bucket", "key"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("PurgeDeleted: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("purged = %d, want 1", purged)
+		}
+		if _, err := repo.Restore(ctx, "bucket", "key", before); !errors.Is(err, models.ErrMetadataNotFound) {
+			t.Errorf("Restore after purge error = %v, want ErrMetadataNotFound", err)
+		}
+	})
+}
+
+func TestMetadataRepositoryExecuteBatch(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *Database) {
+		ctx := context.Background()
+		repo := NewMetadataRepository(db)
+
+		if err := repo.Create(ctx, newTestMetadata("bucket", "existing", nil)); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		ops := func() []*models.MetadataBatchOperation {
+			return []*models.MetadataBatchOperation{
+				{Op: models.BatchOpCreate, Bucket: "bucket", Key: "a", Metadata: newTestMetadata("bucket", "a", nil)},
+				{Op: models.BatchOpUpdate, Bucket: "bucket", Key: "missing", Metadata: newTestMetadata("bucket", "missing", nil)},
+				{Op: models.BatchOpDelete, Bucket: "bucket", Key: "existing"},
+			}
+		}
+
+		// 原子模式：任一项失败时整批回滚
+		results, committed, err := repo.ExecuteBatch(ctx, ops(), true)
+		if err != nil {
+			t.Fatalf("atomic ExecuteBatch: %v", err)
+		}
+		if committed {
+			t.Error("atomic batch with a failed item was committed")
+		}
+		wantStatuses := []string{models.BatchItemAborted, models.BatchItemFailed, models.BatchItemAborted}
+		for i, result := range results {
+			if result.Status != wantStatuses[i] {
+				t.Errorf("atomic results[%d].Status = %s, want %s", i, result.Status, wantStatuses[i])
+			}
+		}
+		list, err := repo.List(ctx, "bucket", "", 10, 0)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if keys := keysOf(list); !reflect.DeepEqual(keys, []string{"existing"}) {
+			t.Errorf("after atomic batch keys = %v, want [existing]", keys)
+		}
+
+		// 非原子模式：失败项回滚到savepoint，其他项照常提交
+		results, committed, err = repo.ExecuteBatch(ctx, ops(), false)
+		if err != nil {
+			t.Fatalf("ExecuteBatch: %v", err)
+		}
+		if !committed {
+			t.Error("non-atomic batch was not committed")
+		}
+		wantStatuses = []string{models.BatchItemSucceeded, models.BatchItemFailed, models.BatchItemSucceeded}
+		for i, result := range results {
+			if result.Status != wantStatuses[i] {
+				t.Errorf("results[%d].Status = %s, want %s", i, result.Status, wantStatuses[i])
+			}
+		}
+		list, err = repo.List(ctx, "bucket", "", 10, 0)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if keys := keysOf(list); !reflect.DeepEqual(keys, []string{"a"}) {
+			t.Errorf("after batch keys = %v, want [a]", keys)
+		}
+	})
+}
+
+func TestMetadataRepositoryTagQueries(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *Database) {
+		ctx := context.Background()
+		repo := NewMetadataRepository(db)
+
+		objects := map[string]map[string]string{
+			"a": {"env": "staging", "team": "core"},
+			"b": {"env": "staging", "team": "web"},
+			"c": {"env": "prod", "team": "web"},
+			"d": nil,
+		}
+		for key, tags := range objects {
+			if err := repo.Create(ctx, newTestMetadata("bucket", key, tags)); err != nil {
+				t.Fatalf("Create %s: %v", key, err)
+			}
+		}
+
+		list, err := repo.ListFiltered(ctx, &models.MetadataFilter{Bucket: "bucket", Tags: map[string]string{"env": "staging", "team": "web"}}, 10, 0)
+		if err != nil {
+			t.Fatalf("ListFiltered tags: %v", err)
+		}
+		if keys := keysOf(list); !reflect.DeepEqual(keys, []string{"b"}) {
+			t.Errorf("tags filter keys = %v, want [b]", keys)
+		}
+
+		for query, want := range map[string][]string{
+			"env=staging AND team!=core": {"b"},
+			"env=prod OR team=core":      {"a", "c"},
+			"team":                       {"a", "b", "c"},
+			"NOT team":                   {"d"},
+			"env!=staging":               {"c", "d"},
+		} {
+			expr, err := models.ParseTagQuery(query)
+			if err != nil {
+				t.Fatalf("ParseTagQuery(%q): %v", query, err)
+			}
+			list, err := repo.ListFiltered(ctx, &models.MetadataFilter{Bucket: "bucket", TagQuery: expr}, 10, 0)
+			if err != nil {
+				t.Fatalf("ListFiltered %q: %v", query, err)
+			}
+			if keys := keysOf(list); !reflect.DeepEqual(keys, want) {
+				t.Errorf("tag query %q keys = %v, want %v", query, keys, want)
+			}
+		}
+
+		counts, err := repo.TagCounts(ctx, "bucket", 10)
+		if err != nil {
+			t.Fatalf("TagCounts: %v", err)
+		}
+		got := make(map[string]int64)
+		for _, count := range counts {
+			got[count.Key+"="+count.Value] = count.Count
+		}
+		want := map[string]int64{"env=staging": 2, "env=prod": 1, "team=web": 2, "team=core": 1}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("TagCounts = %v, want %v", got, want)
+		}
+		if counts[0].Count != 2 {
+			t.Errorf("TagCounts not ordered by count: %v", got)
+		}
+	})
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"mocks3/shared/models"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID 迁移期间持有的advisory lock键，避免多个实例同时迁移
+const migrationLockID = 0x6d6f636b7333
+
+// migrationLockName MySQL迁移锁名（GET_LOCK）
+const migrationLockName = "mocks3_migrations"
+
+// migrationFilePattern 迁移文件名：<版本号>_<名称>.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+// createMigrationsTable 迁移记录表
+const createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		duration_ms BIGINT NOT NULL DEFAULT 0,
+		applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	)
+`
+
+// createMigrationsTableCompat MySQL/SQLite迁移记录表（applied_at由应用写入）
+const createMigrationsTableCompat = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		duration_ms BIGINT NOT NULL DEFAULT 0,
+		applied_at TIMESTAMP NULL
+	)
+`
+
+// migration 内嵌的迁移脚本
+type migration struct {
+	version  int64
+	name     string
+	sql      string
+	checksum string
+}
+
+// appliedMigration 已应用的迁移记录
+type appliedMigration struct {
+	name       string
+	checksum   string
+	durationMs int64
+	appliedAt  time.Time
+}
+
+// loadMigrations 读取驱动对应目录（migrations/<driver>）下内嵌的迁移脚本，按版本号升序返回
+func loadMigrations(driver string) ([]*migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := migrationFiles.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []*migration
+	versions := make(map[int64]string)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+		if existing, ok := versions[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, existing, entry.Name())
+		}
+		versions[version] = entry.Name()
+
+		data, err := migrationFiles.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		sum := sha256.Sum256(data)
+
+		migrations = append(migrations, &migration{
+			version:  version,
+			name:     match[2],
+			sql:      string(data),
+			checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// Migrate 执行所有未应用的迁移，每个迁移在独立事务中执行，返回本次应用的迁移数
+//
+// 已应用的迁移被修改时返回错误，需新增迁移而不是修改旧文件。
+// MySQL的DDL会隐式提交，迁移中途失败时已执行的语句不会回滚。
+func (d *Database) Migrate(ctx context.Context) (int, error) {
+	migrations, err := loadMigrations(d.dialect.driver)
+	if err != nil {
+		return 0, err
+	}
+
+	// 迁移锁属于会话，锁定和迁移必须使用同一连接
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	unlock, err := d.lockMigrations(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	if _, err := conn.ExecContext(ctx, d.migrationsTableDDL()); err != nil {
+		return 0, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	applied, err := loadAppliedMigrations(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if record, ok := applied[m.version]; ok {
+			if record.checksum != m.checksum {
+				return count, fmt.Errorf("migration %d_%s was modified after being applied", m.version, m.name)
+			}
+			continue
+		}
+
+		if err := d.applyMigration(ctx, conn, m); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// lockMigrations 获取迁移锁，返回释放函数；SQLite为单连接且写入串行，无需加锁
+func (d *Database) lockMigrations(ctx context.Context, conn *sql.Conn) (func(), error) {
+	switch d.dialect.driver {
+	case DriverMySQL:
+		var acquired sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 300)", migrationLockName).Scan(&acquired); err != nil {
+			return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if acquired.Int64 != 1 {
+			return nil, fmt.Errorf("failed to acquire migration lock: timed out")
+		}
+		return func() {
+			conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName)
+		}, nil
+	case DriverSQLite:
+		return func() {}, nil
+	}
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	return func() {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+	}, nil
+}
+
+// migrationsTableDDL 迁移记录表DDL
+func (d *Database) migrationsTableDDL() string {
+	if d.dialect.driver == DriverPostgres {
+		return createMigrationsTable
+	}
+	return createMigrationsTableCompat
+}
+
+// applyMigration 在事务中执行迁移并记录
+func (d *Database) applyMigration(ctx context.Context, conn *sql.Conn, m *migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	start := time.Now()
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s: %w", m.version, m.name, err)
+	}
+
+	_, err = d.bind(tx).ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name, checksum, duration_ms, applied_at) VALUES ($1, $2, $3, $4, $5)",
+		m.version, m.name, m.checksum, time.Since(start).Milliseconds(), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", m.version, m.name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d_%s: %w", m.version, m.name, err)
+	}
+	return nil
+}
+
+// loadAppliedMigrations 读取已应用的迁移记录
+func loadAppliedMigrations(ctx context.Context, exec dbExecutor) (map[int64]*appliedMigration, error) {
+	rows, err := exec.QueryContext(ctx, "SELECT version, name, checksum, duration_ms, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]*appliedMigration)
+	for rows.Next() {
+		var version int64
+		var record appliedMigration
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&version, &record.name, &record.checksum, &record.durationMs, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan migration: %w", err)
+		}
+		record.appliedAt = appliedAt.Time
+		applied[version] = &record
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return applied, nil
+}
+
+// MigrationStatus 获取迁移状态
+func (d *Database) MigrationStatus(ctx context.Context) (*models.MigrationReport, error) {
+	migrations, err := loadMigrations(d.dialect.driver)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := d.db.ExecContext(ctx, d.migrationsTableDDL()); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	applied, err := loadAppliedMigrations(ctx, d.db)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.MigrationReport{
+		Migrations: make([]*models.MigrationStatus, 0, len(migrations)),
+	}
+	for _, m := range migrations {
+		status := &models.MigrationStatus{
+			Version:  m.version,
+			Name:     m.name,
+			Checksum: m.checksum,
+		}
+		if record, ok := applied[m.version]; ok {
+			appliedAt := record.appliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.DurationMs = record.durationMs
+			status.Modified = record.checksum != m.checksum
+			if m.version > report.CurrentVersion {
+				report.CurrentVersion = m.version
+			}
+		} else {
+			report.Pending++
+		}
+		report.LatestVersion = m.version
+		report.Migrations = append(report.Migrations, status)
+	}
+
+	return report, nil
+}
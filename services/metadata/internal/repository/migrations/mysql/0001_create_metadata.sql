@@ -0,0 +1,32 @@
+-- 元数据表
+-- key为保留字需加反引号；MySQL不支持部分索引，未删除对象的(bucket, key)唯一性
+-- 通过生成列alive实现（已删除行为NULL，不参与唯一约束）
+CREATE TABLE IF NOT EXISTS metadata (
+	id VARCHAR(255) PRIMARY KEY,
+	`key` VARCHAR(500) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	size BIGINT NOT NULL,
+	content_type VARCHAR(255),
+	md5_hash VARCHAR(32),
+	etag VARCHAR(255),
+	storage_nodes JSON,
+	headers JSON,
+	tags JSON,
+	status VARCHAR(50) DEFAULT 'active',
+	version BIGINT DEFAULT 1,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	deleted_at DATETIME(6) NULL,
+	alive TINYINT GENERATED ALWAYS AS (IF(deleted_at IS NULL, 1, NULL)) STORED,
+	INDEX idx_metadata_key (`key`),
+	INDEX idx_metadata_bucket (bucket),
+	INDEX idx_metadata_bucket_key (bucket, `key`),
+	INDEX idx_metadata_status (status),
+	INDEX idx_metadata_created_at (created_at),
+	INDEX idx_metadata_content_type (content_type),
+	INDEX idx_metadata_size (size),
+	INDEX idx_metadata_updated_at (updated_at, bucket, `key`),
+	INDEX idx_metadata_bucket_updated_at (bucket, updated_at, `key`),
+	INDEX idx_metadata_deleted_at (deleted_at),
+	UNIQUE INDEX idx_metadata_bucket_key_unique (bucket, `key`, alive)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,8 @@
+-- 统计缓存表（只有一行统计数据）
+CREATE TABLE IF NOT EXISTS stats_cache (
+	id INT PRIMARY KEY DEFAULT 1,
+	stats_data JSON NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	CONSTRAINT chk_stats_cache_single CHECK (id = 1)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
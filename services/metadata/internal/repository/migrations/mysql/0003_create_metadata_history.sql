@@ -0,0 +1,20 @@
+-- 历史版本表（每次更新前保存旧版本）
+CREATE TABLE IF NOT EXISTS metadata_history (
+	history_id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	metadata_id VARCHAR(255) NOT NULL,
+	`key` VARCHAR(500) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	size BIGINT NOT NULL,
+	content_type VARCHAR(255),
+	md5_hash VARCHAR(32),
+	etag VARCHAR(255),
+	storage_nodes JSON,
+	headers JSON,
+	tags JSON,
+	status VARCHAR(50),
+	version BIGINT NOT NULL,
+	created_at DATETIME(6),
+	updated_at DATETIME(6),
+	archived_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	INDEX idx_metadata_history_bucket_key_version (bucket, `key`, version DESC)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,11 @@
+-- 租户维度：已有数据归属default租户，未删除对象的(tenant, bucket, key)唯一
+ALTER TABLE metadata
+	ADD COLUMN tenant VARCHAR(64) NOT NULL DEFAULT 'default',
+	DROP INDEX idx_metadata_bucket_key_unique,
+	ADD UNIQUE INDEX idx_metadata_tenant_bucket_key_unique (tenant, bucket, `key`, alive),
+	ADD INDEX idx_metadata_tenant_bucket_key (tenant, bucket, `key`);
+
+ALTER TABLE metadata_history
+	ADD COLUMN tenant VARCHAR(64) NOT NULL DEFAULT 'default',
+	DROP INDEX idx_metadata_history_bucket_key_version,
+	ADD INDEX idx_metadata_history_tenant_bucket_key_version (tenant, bucket, `key`, version DESC);
@@ -0,0 +1,28 @@
+-- 按bucket/目录前缀物化的对象数和字节数，写入时在同一事务中增量维护
+-- prefix为空表示整个bucket，其余为键中以/结尾的各级目录；
+-- (tenant, bucket, prefix)超过InnoDB索引长度上限，主键使用prefix的SHA-256
+CREATE TABLE IF NOT EXISTS prefix_counters (
+	tenant VARCHAR(64) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	prefix VARCHAR(500) NOT NULL,
+	prefix_hash BINARY(32) AS (UNHEX(SHA2(prefix, 256))) STORED,
+	object_count BIGINT NOT NULL DEFAULT 0,
+	total_bytes BIGINT NOT NULL DEFAULT 0,
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	PRIMARY KEY (tenant, bucket, prefix_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+-- 回填已有对象：递归展开每个键的各级目录前缀
+INSERT INTO prefix_counters (tenant, bucket, prefix, object_count, total_bytes)
+WITH RECURSIVE prefixes (tenant, bucket, size, prefix, rest) AS (
+	SELECT tenant, bucket, size, CAST('' AS CHAR(500)), CAST(`key` AS CHAR(500))
+	FROM metadata
+	WHERE deleted_at IS NULL
+	UNION ALL
+	SELECT tenant, bucket, size, CONCAT(prefix, SUBSTRING(rest, 1, LOCATE('/', rest))), SUBSTRING(rest, LOCATE('/', rest) + 1)
+	FROM prefixes
+	WHERE LOCATE('/', rest) > 0
+)
+SELECT tenant, bucket, prefix, COUNT(*), SUM(size)
+FROM prefixes
+GROUP BY tenant, bucket, prefix;
@@ -0,0 +1,2 @@
+-- 按内容哈希查找重复对象（写入去重和重复内容报告）
+ALTER TABLE metadata ADD INDEX idx_metadata_tenant_md5_size (tenant, md5_hash, size);
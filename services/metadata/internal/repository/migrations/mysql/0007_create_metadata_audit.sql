@@ -0,0 +1,24 @@
+-- 元数据变更审计（只追加）：每次创建、更新、删除、恢复和导入在同一事务中记录操作者与新旧值
+CREATE TABLE IF NOT EXISTS metadata_audit (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	tenant VARCHAR(64) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	`key` VARCHAR(500) NOT NULL,
+	metadata_id VARCHAR(255) NOT NULL,
+	action VARCHAR(20) NOT NULL,
+	actor_name VARCHAR(255),
+	actor_ip VARCHAR(64),
+	user_agent VARCHAR(500),
+	old_value JSON,
+	new_value JSON,
+	created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+	INDEX idx_metadata_audit_tenant_bucket_key (tenant, bucket, `key`, id),
+	INDEX idx_metadata_audit_tenant_created_at (tenant, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+-- 禁止修改和删除审计记录
+CREATE TRIGGER metadata_audit_no_update BEFORE UPDATE ON metadata_audit
+	FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'metadata_audit is append-only';
+
+CREATE TRIGGER metadata_audit_no_delete BEFORE DELETE ON metadata_audit
+	FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'metadata_audit is append-only';
@@ -0,0 +1,8 @@
+-- bucket级保留策略：对象创建后min_retention_days天内拒绝删除
+CREATE TABLE IF NOT EXISTS bucket_retention (
+	tenant VARCHAR(64) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	min_retention_days INT NOT NULL,
+	updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+	PRIMARY KEY (tenant, bucket)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,8 @@
+-- bucket注册的JSON Schema，写入元数据时校验headers/tags
+CREATE TABLE IF NOT EXISTS bucket_schemas (
+	tenant VARCHAR(64) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	schema_json TEXT NOT NULL,
+	updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+	PRIMARY KEY (tenant, bucket)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,34 @@
+-- 元数据表（IF NOT EXISTS：兼容引入迁移前已初始化的数据库）
+CREATE TABLE IF NOT EXISTS metadata (
+	id VARCHAR(255) PRIMARY KEY,
+	key VARCHAR(500) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	size BIGINT NOT NULL,
+	content_type VARCHAR(255),
+	md5_hash VARCHAR(32),
+	etag VARCHAR(255),
+	storage_nodes JSONB,
+	headers JSONB,
+	tags JSONB,
+	status VARCHAR(50) DEFAULT 'active',
+	version BIGINT DEFAULT 1,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+	deleted_at TIMESTAMP WITH TIME ZONE NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_metadata_key ON metadata(key);
+CREATE INDEX IF NOT EXISTS idx_metadata_bucket ON metadata(bucket);
+CREATE INDEX IF NOT EXISTS idx_metadata_bucket_key ON metadata(bucket, key);
+CREATE INDEX IF NOT EXISTS idx_metadata_status ON metadata(status);
+CREATE INDEX IF NOT EXISTS idx_metadata_created_at ON metadata(created_at);
+CREATE INDEX IF NOT EXISTS idx_metadata_content_type ON metadata(content_type);
+CREATE INDEX IF NOT EXISTS idx_metadata_size ON metadata(size);
+CREATE INDEX IF NOT EXISTS idx_metadata_updated_at ON metadata(updated_at, bucket, key) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_metadata_bucket_updated_at ON metadata(bucket, updated_at, key) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_metadata_deleted_at ON metadata(deleted_at);
+-- 标签查询（@> 和 ? 运算符）
+CREATE INDEX IF NOT EXISTS idx_metadata_tags ON metadata USING gin(tags) WHERE deleted_at IS NULL;
+
+-- 未删除对象的(bucket, key)唯一
+CREATE UNIQUE INDEX IF NOT EXISTS idx_metadata_bucket_key_unique ON metadata(bucket, key) WHERE deleted_at IS NULL;
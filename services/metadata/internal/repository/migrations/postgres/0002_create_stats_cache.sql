@@ -0,0 +1,10 @@
+-- 统计缓存表
+CREATE TABLE IF NOT EXISTS stats_cache (
+	id SERIAL PRIMARY KEY,
+	stats_data JSONB NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- 确保只有一行统计数据
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_cache_single ON stats_cache((1));
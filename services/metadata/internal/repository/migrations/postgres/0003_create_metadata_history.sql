@@ -0,0 +1,21 @@
+-- 历史版本表（每次更新前保存旧版本）
+CREATE TABLE IF NOT EXISTS metadata_history (
+	history_id BIGSERIAL PRIMARY KEY,
+	metadata_id VARCHAR(255) NOT NULL,
+	key VARCHAR(500) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	size BIGINT NOT NULL,
+	content_type VARCHAR(255),
+	md5_hash VARCHAR(32),
+	etag VARCHAR(255),
+	storage_nodes JSONB,
+	headers JSONB,
+	tags JSONB,
+	status VARCHAR(50),
+	version BIGINT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE,
+	updated_at TIMESTAMP WITH TIME ZONE,
+	archived_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_metadata_history_bucket_key_version ON metadata_history(bucket, key, version DESC);
@@ -0,0 +1,13 @@
+-- 全文检索函数和索引（key > tags > content_type > headers）
+CREATE OR REPLACE FUNCTION metadata_search_vector(k TEXT, ct TEXT, t JSONB, h JSONB)
+RETURNS tsvector AS $$
+	SELECT
+		setweight(to_tsvector('simple', regexp_replace(lower(COALESCE(k, '')), '[^[:alnum:]]+', ' ', 'g')), 'A') ||
+		setweight(to_tsvector('simple', regexp_replace(lower(COALESCE(t::text, '')), '[^[:alnum:]]+', ' ', 'g')), 'B') ||
+		setweight(to_tsvector('simple', regexp_replace(lower(COALESCE(ct, '')), '[^[:alnum:]]+', ' ', 'g')), 'C') ||
+		setweight(to_tsvector('simple', regexp_replace(lower(COALESCE(h::text, '')), '[^[:alnum:]]+', ' ', 'g')), 'D')
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE INDEX IF NOT EXISTS idx_metadata_search ON metadata
+	USING gin(metadata_search_vector(key, content_type, tags, headers))
+	WHERE deleted_at IS NULL;
@@ -0,0 +1,10 @@
+-- 租户维度：已有数据归属default租户，未删除对象的(tenant, bucket, key)唯一
+ALTER TABLE metadata ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE metadata_history ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+
+DROP INDEX IF EXISTS idx_metadata_bucket_key_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_metadata_tenant_bucket_key_unique ON metadata(tenant, bucket, key) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_metadata_tenant_bucket_key ON metadata(tenant, bucket, key);
+
+DROP INDEX IF EXISTS idx_metadata_history_bucket_key_version;
+CREATE INDEX IF NOT EXISTS idx_metadata_history_tenant_bucket_key_version ON metadata_history(tenant, bucket, key, version DESC);
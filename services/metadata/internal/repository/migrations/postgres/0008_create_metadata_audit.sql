@@ -0,0 +1,30 @@
+-- 元数据变更审计（只追加）：每次创建、更新、删除、恢复和导入在同一事务中记录操作者与新旧值
+CREATE TABLE IF NOT EXISTS metadata_audit (
+	id BIGSERIAL PRIMARY KEY,
+	tenant VARCHAR(64) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	key VARCHAR(500) NOT NULL,
+	metadata_id VARCHAR(255) NOT NULL,
+	action VARCHAR(20) NOT NULL,
+	actor_name VARCHAR(255),
+	actor_ip VARCHAR(64),
+	user_agent VARCHAR(500),
+	old_value JSONB,
+	new_value JSONB,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_metadata_audit_tenant_bucket_key ON metadata_audit(tenant, bucket, key, id);
+CREATE INDEX IF NOT EXISTS idx_metadata_audit_tenant_created_at ON metadata_audit(tenant, created_at);
+
+-- 禁止修改和删除审计记录
+CREATE OR REPLACE FUNCTION metadata_audit_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'metadata_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS metadata_audit_append_only ON metadata_audit;
+CREATE TRIGGER metadata_audit_append_only
+	BEFORE UPDATE OR DELETE ON metadata_audit
+	FOR EACH ROW EXECUTE FUNCTION metadata_audit_append_only();
@@ -0,0 +1,8 @@
+-- bucket级保留策略：对象创建后min_retention_days天内拒绝删除
+CREATE TABLE IF NOT EXISTS bucket_retention (
+	tenant VARCHAR(64) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	min_retention_days INTEGER NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (tenant, bucket)
+);
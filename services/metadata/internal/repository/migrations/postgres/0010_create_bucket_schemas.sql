@@ -0,0 +1,8 @@
+-- bucket注册的JSON Schema，写入元数据时校验headers/tags
+CREATE TABLE IF NOT EXISTS bucket_schemas (
+	tenant VARCHAR(64) NOT NULL,
+	bucket VARCHAR(255) NOT NULL,
+	schema_json TEXT NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (tenant, bucket)
+);
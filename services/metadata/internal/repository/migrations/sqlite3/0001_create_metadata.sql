@@ -0,0 +1,32 @@
+-- 元数据表（JSON列以TEXT保存，时间列声明为TIMESTAMP以便驱动解析为time.Time）
+CREATE TABLE IF NOT EXISTS metadata (
+	id TEXT PRIMARY KEY,
+	key TEXT NOT NULL,
+	bucket TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	content_type TEXT,
+	md5_hash TEXT,
+	etag TEXT,
+	storage_nodes TEXT,
+	headers TEXT,
+	tags TEXT,
+	status TEXT DEFAULT 'active',
+	version INTEGER DEFAULT 1,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_metadata_key ON metadata(key);
+CREATE INDEX IF NOT EXISTS idx_metadata_bucket ON metadata(bucket);
+CREATE INDEX IF NOT EXISTS idx_metadata_bucket_key ON metadata(bucket, key);
+CREATE INDEX IF NOT EXISTS idx_metadata_status ON metadata(status);
+CREATE INDEX IF NOT EXISTS idx_metadata_created_at ON metadata(created_at);
+CREATE INDEX IF NOT EXISTS idx_metadata_content_type ON metadata(content_type);
+CREATE INDEX IF NOT EXISTS idx_metadata_size ON metadata(size);
+CREATE INDEX IF NOT EXISTS idx_metadata_updated_at ON metadata(updated_at, bucket, key) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_metadata_bucket_updated_at ON metadata(bucket, updated_at, key) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_metadata_deleted_at ON metadata(deleted_at);
+
+-- 未删除对象的(bucket, key)唯一
+CREATE UNIQUE INDEX IF NOT EXISTS idx_metadata_bucket_key_unique ON metadata(bucket, key) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- 统计缓存表（只有一行统计数据）
+CREATE TABLE IF NOT EXISTS stats_cache (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	stats_data TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
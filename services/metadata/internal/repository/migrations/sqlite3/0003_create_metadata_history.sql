@@ -0,0 +1,21 @@
+-- 历史版本表（每次更新前保存旧版本）
+CREATE TABLE IF NOT EXISTS metadata_history (
+	history_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	metadata_id TEXT NOT NULL,
+	key TEXT NOT NULL,
+	bucket TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	content_type TEXT,
+	md5_hash TEXT,
+	etag TEXT,
+	storage_nodes TEXT,
+	headers TEXT,
+	tags TEXT,
+	status TEXT,
+	version INTEGER NOT NULL,
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP,
+	archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_metadata_history_bucket_key_version ON metadata_history(bucket, key, version DESC);
@@ -0,0 +1,26 @@
+-- 按bucket/目录前缀物化的对象数和字节数，写入时在同一事务中增量维护
+-- prefix为空表示整个bucket，其余为键中以/结尾的各级目录
+CREATE TABLE IF NOT EXISTS prefix_counters (
+	tenant TEXT NOT NULL,
+	bucket TEXT NOT NULL,
+	prefix TEXT NOT NULL,
+	object_count INTEGER NOT NULL DEFAULT 0,
+	total_bytes INTEGER NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (tenant, bucket, prefix)
+);
+
+-- 回填已有对象：递归展开每个键的各级目录前缀
+WITH RECURSIVE prefixes (tenant, bucket, size, prefix, rest) AS (
+	SELECT tenant, bucket, size, '', key
+	FROM metadata
+	WHERE deleted_at IS NULL
+	UNION ALL
+	SELECT tenant, bucket, size, prefix || substr(rest, 1, instr(rest, '/')), substr(rest, instr(rest, '/') + 1)
+	FROM prefixes
+	WHERE instr(rest, '/') > 0
+)
+INSERT INTO prefix_counters (tenant, bucket, prefix, object_count, total_bytes)
+SELECT tenant, bucket, prefix, COUNT(*), SUM(size)
+FROM prefixes
+GROUP BY tenant, bucket, prefix;
@@ -0,0 +1,2 @@
+-- 按内容哈希查找重复对象（写入去重和重复内容报告）
+CREATE INDEX IF NOT EXISTS idx_metadata_tenant_md5_size ON metadata(tenant, md5_hash, size) WHERE deleted_at IS NULL;
@@ -0,0 +1,29 @@
+-- 元数据变更审计（只追加）：每次创建、更新、删除、恢复和导入在同一事务中记录操作者与新旧值
+CREATE TABLE IF NOT EXISTS metadata_audit (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	tenant TEXT NOT NULL,
+	bucket TEXT NOT NULL,
+	key TEXT NOT NULL,
+	metadata_id TEXT NOT NULL,
+	action TEXT NOT NULL,
+	actor_name TEXT,
+	actor_ip TEXT,
+	user_agent TEXT,
+	old_value TEXT,
+	new_value TEXT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_metadata_audit_tenant_bucket_key ON metadata_audit(tenant, bucket, key, id);
+CREATE INDEX IF NOT EXISTS idx_metadata_audit_tenant_created_at ON metadata_audit(tenant, created_at);
+
+-- 禁止修改和删除审计记录
+CREATE TRIGGER IF NOT EXISTS metadata_audit_no_update BEFORE UPDATE ON metadata_audit
+BEGIN
+	SELECT RAISE(ABORT, 'metadata_audit is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS metadata_audit_no_delete BEFORE DELETE ON metadata_audit
+BEGIN
+	SELECT RAISE(ABORT, 'metadata_audit is append-only');
+END;
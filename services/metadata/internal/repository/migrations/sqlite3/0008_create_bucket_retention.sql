@@ -0,0 +1,8 @@
+-- bucket级保留策略：对象创建后min_retention_days天内拒绝删除
+CREATE TABLE IF NOT EXISTS bucket_retention (
+	tenant TEXT NOT NULL,
+	bucket TEXT NOT NULL,
+	min_retention_days INTEGER NOT NULL,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (tenant, bucket)
+);
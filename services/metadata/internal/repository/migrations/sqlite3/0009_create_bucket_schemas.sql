@@ -0,0 +1,8 @@
+-- bucket注册的JSON Schema，写入元数据时校验headers/tags
+CREATE TABLE IF NOT EXISTS bucket_schemas (
+	tenant TEXT NOT NULL,
+	bucket TEXT NOT NULL,
+	schema_json TEXT NOT NULL,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (tenant, bucket)
+);
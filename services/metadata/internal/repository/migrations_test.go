@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	for _, tc := range testDrivers {
+		t.Run(tc.driver, func(t *testing.T) {
+			ctx := context.Background()
+			db := tc.open(t, false)
+
+			migrations, err := loadMigrations(tc.driver)
+			if err != nil {
+				t.Fatalf("loadMigrations: %v", err)
+			}
+
+			applied, err := db.Migrate(ctx)
+			if err != nil {
+				t.Fatalf("Migrate: %v", err)
+			}
+			if applied != len(migrations) {
+				t.Fatalf("applied = %d, want %d", applied, len(migrations))
+			}
+
+			// 再次迁移不应用任何脚本
+			if applied, err = db.Migrate(ctx); err != nil || applied != 0 {
+				t.Fatalf("second Migrate = %d, %v; want 0, nil", applied, err)
+			}
+
+			report, err := db.MigrationStatus(ctx)
+			if err != nil {
+				t.Fatalf("MigrationStatus: %v", err)
+			}
+			latest := migrations[len(migrations)-1].version
+			if report.Pending != 0 || report.CurrentVersion != latest || report.LatestVersion != latest {
+				t.Errorf("report = pending %d, current %d, latest %d; want 0, %d, %d",
+					report.Pending, report.CurrentVersion, report.LatestVersion, latest, latest)
+			}
+		})
+	}
+}
+
+func TestMigrateRejectsModifiedMigration(t *testing.T) {
+	for _, tc := range testDrivers {
+		t.Run(tc.driver, func(t *testing.T) {
+			ctx := context.Background()
+			db := tc.open(t, true)
+
+			// 模拟已应用的迁移文件被修改：记录的校验和与内嵌脚本不一致
+			if _, err := db.executor().ExecContext(ctx, "UPDATE schema_migrations SET checksum = $1 WHERE version = $2", "modified", 1); err != nil {
+				t.Fatalf("update checksum: %v", err)
+			}
+
+			if _, err := db.Migrate(ctx); err == nil || !strings.Contains(err.Error(), "was modified after being applied") {
+				t.Fatalf("Migrate error = %v, want modified migration error", err)
+			}
+
+			report, err := db.MigrationStatus(ctx)
+			if err != nil {
+				t.Fatalf("MigrationStatus: %v", err)
+			}
+			for _, status := range report.Migrations {
+				if status.Modified != (status.Version == 1) {
+					t.Errorf("migration %d modified = %v", status.Version, status.Modified)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mocks3/shared/models"
+	"strings"
+	"time"
+)
+
+// counterPrefixes 对象计入的计数前缀：空前缀（整个bucket）及键中以/结尾的各级目录
+func counterPrefixes(key string) []string {
+	prefixes := []string{""}
+	for i := 0; i < len(key); i++ {
+		if key[i] == '/' {
+			prefixes = append(prefixes, key[:i+1])
+		}
+	}
+	return prefixes
+}
+
+// isCounterPrefix 前缀是否由prefix_counters维护（空前缀或以/结尾的目录）
+func isCounterPrefix(prefix string) bool {
+	return prefix == "" || strings.HasSuffix(prefix, "/")
+}
+
+// adjustPrefixCounters 按增量更新对象所在bucket及各级目录的计数，调用方需保证与对象写入在同一事务中
+func (r *MetadataRepository) adjustPrefixCounters(ctx context.Context, exec dbExecutor, bucket, key string, objects, bytes int64) error {
+	if objects == 0 && bytes == 0 {
+		return nil
+	}
+
+	tenant := models.TenantFromContext(ctx)
+	prefixes := counterPrefixes(key)
+	query := r.db.dialect.upsertPrefixCounterQuery()
+	now := time.Now()
+	for _, prefix := range prefixes {
+		if _, err := exec.ExecContext(ctx, query, tenant, bucket, prefix, objects, bytes, now); err != nil {
+			return fmt.Errorf("failed to update prefix counters: %w", err)
+		}
+	}
+
+	// 清理已没有对象的前缀，避免计数表随删除的目录膨胀
+	if objects < 0 {
+		args := []interface{}{tenant, bucket}
+		placeholders := make([]string, len(prefixes))
+		for i, prefix := range prefixes {
+			args = append(args, prefix)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		query := fmt.Sprintf(
+			"DELETE FROM prefix_counters WHERE tenant = $1 AND bucket = $2 AND object_count <= 0 AND prefix IN (%s)",
+			strings.Join(placeholders, ", "))
+		if _, err := exec.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to clean up prefix counters: %w", err)
+		}
+	}
+	return nil
+}
+
+// Usage 统计bucket/前缀下的对象数和字节数
+//
+// 空前缀和以/结尾的目录前缀直接读取prefix_counters（bucket为空时汇总租户内所有bucket），
+// 其他前缀回退为扫描metadata表。
+func (r *MetadataRepository) Usage(ctx context.Context, bucket, prefix string) (*models.ObjectSummary, error) {
+	args := []interface{}{models.TenantFromContext(ctx)}
+	var query string
+	if isCounterPrefix(prefix) {
+		args = append(args, prefix)
+		query = "SELECT COALESCE(SUM(object_count), 0), COALESCE(SUM(total_bytes), 0) FROM prefix_counters WHERE tenant = $1 AND prefix = $2"
+		if bucket != "" {
+			args = append(args, bucket)
+			query += " AND bucket = $3"
+		}
+	} else {
+		args = append(args, prefix+"%")
+		query = "SELECT COUNT(*), COALESCE(SUM(size), 0) FROM metadata WHERE tenant = $1 AND key LIKE $2 AND deleted_at IS NULL"
+		if bucket != "" {
+			args = append(args, bucket)
+			query += " AND bucket = $3"
+		}
+	}
+
+	var summary models.ObjectSummary
+	if err := r.db.reader(ctx).QueryRowContext(ctx, query, args...).Scan(&summary.Objects, &summary.Bytes); err != nil {
+		return nil, fmt.Errorf("failed to get prefix usage: %w", err)
+	}
+	return &summary, nil
+}
+
+// lockMetadata 读取并锁定租户内未删除的对象，不存在时返回nil
+func (r *MetadataRepository) lockMetadata(ctx context.Context, exec dbExecutor, bucket, key string) (*models.Metadata, error) {
+	row := exec.QueryRowContext(ctx, `
+		SELECT id, key, bucket, size, content_type, md5_hash, etag,
+			   storage_nodes, headers, tags, status, version,
+			   created_at, updated_at, deleted_at
+		FROM metadata
+		WHERE tenant = $1 AND bucket = $2 AND key = $3 AND deleted_at IS NULL`+r.db.dialect.forUpdate(),
+		models.TenantFromContext(ctx), bucket, key)
+	metadata, err := r.scanMetadata(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// upsertPrefixCounterQuery 累加前缀计数，不存在时插入
+func (d *dialect) upsertPrefixCounterQuery() string {
+	if d.driver == DriverMySQL {
+		return `
+			INSERT INTO prefix_counters (tenant, bucket, prefix, object_count, total_bytes, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON DUPLICATE KEY UPDATE
+				object_count = object_count + VALUES(object_count),
+				total_bytes = total_bytes + VALUES(total_bytes),
+				updated_at = VALUES(updated_at)
+		`
+	}
+	return `
+		INSERT INTO prefix_counters (tenant, bucket, prefix, object_count, total_bytes, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant, bucket, prefix) DO UPDATE SET
+			object_count = prefix_counters.object_count + excluded.object_count,
+			total_bytes = prefix_counters.total_bytes + excluded.total_bytes,
+			updated_at = excluded.updated_at
+	`
+}
+
+// forUpdate 行锁子句（SQLite单连接串行写入，无需加锁）
+func (d *dialect) forUpdate() string {
+	if d.driver == DriverSQLite {
+		return ""
+	}
+	return " FOR UPDATE"
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mocks3/services/metadata/internal/config"
+	"mocks3/shared/models"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// replica 只读副本连接及最近一次检查结果
+type replica struct {
+	name string
+	db   *sql.DB
+
+	mu     sync.RWMutex
+	status models.ReplicaStatus
+}
+
+// replicaSet 读副本路由：读请求轮询可用副本，无可用副本时回退主库
+type replicaSet struct {
+	replicas      []*replica
+	dialect       *dialect
+	maxLag        time.Duration
+	checkInterval time.Duration
+	next          atomic.Uint64
+	stop          chan struct{}
+	done          chan struct{}
+}
+
+// newReplicaSet 连接副本并执行首次检查，未配置副本时返回nil
+func newReplicaSet(cfg config.DatabaseConfig, dialect *dialect) (*replicaSet, error) {
+	if len(cfg.Replicas.DSNs) == 0 {
+		return nil, nil
+	}
+
+	set := &replicaSet{
+		dialect:       dialect,
+		maxLag:        time.Duration(cfg.Replicas.MaxLagSecs) * time.Second,
+		checkInterval: time.Duration(cfg.Replicas.CheckIntervalSecs) * time.Second,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if set.checkInterval <= 0 {
+		set.checkInterval = 5 * time.Second
+	}
+
+	for i, dsn := range cfg.Replicas.DSNs {
+		db, err := sql.Open(cfg.Driver, dsn)
+		if err != nil {
+			set.closeConns()
+			return nil, fmt.Errorf("failed to open database replica %d: %w", i+1, err)
+		}
+		db.SetMaxOpenConns(25)
+		db.SetMaxIdleConns(5)
+		db.SetConnMaxLifetime(5 * time.Minute)
+
+		// 不可达的副本不阻止启动，由后台检查在恢复后重新启用
+		name := fmt.Sprintf("replica-%d", i+1)
+		set.replicas = append(set.replicas, &replica{
+			name:   name,
+			db:     db,
+			status: models.ReplicaStatus{Name: name},
+		})
+	}
+
+	set.checkAll()
+	go set.monitor()
+	return set, nil
+}
+
+// pick 轮询选择一个可用副本，没有可用副本时返回nil
+func (s *replicaSet) pick() *sql.DB {
+	n := len(s.replicas)
+	start := int(s.next.Add(1) - 1)
+	for i := 0; i < n; i++ {
+		r := s.replicas[(start+i)%n]
+		r.mu.RLock()
+		available := r.status.Available
+		r.mu.RUnlock()
+		if available {
+			return r.db
+		}
+	}
+	return nil
+}
+
+// monitor 定期检查副本连通性与复制延迟
+func (s *replicaSet) monitor() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.checkAll()
+		}
+	}
+}
+
+// checkAll 检查所有副本
+func (s *replicaSet) checkAll() {
+	for _, r := range s.replicas {
+		s.check(r)
+	}
+}
+
+// check 检查单个副本，不可达或延迟超过阈值时标记为不可用
+func (s *replicaSet) check(r *replica) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.checkInterval)
+	defer cancel()
+
+	status := models.ReplicaStatus{Name: r.name, LastCheckedAt: time.Now()}
+	lag, err := s.dialect.replicationLag(ctx, r.db)
+	switch {
+	case err != nil:
+		status.Error = err.Error()
+	case s.maxLag > 0 && lag > s.maxLag:
+		status.LagSeconds = lag.Seconds()
+		status.Error = fmt.Sprintf("replication lag %s exceeds %s", lag.Round(time.Millisecond), s.maxLag)
+	default:
+		status.LagSeconds = lag.Seconds()
+		status.Available = true
+	}
+
+	r.mu.Lock()
+	r.status = status
+	r.mu.Unlock()
+}
+
+// report 副本状态汇总
+func (s *replicaSet) report() *models.ReplicaReport {
+	report := &models.ReplicaReport{
+		MaxLagSeconds: int(s.maxLag / time.Second),
+		Replicas:      make([]*models.ReplicaStatus, 0, len(s.replicas)),
+	}
+	for _, r := range s.replicas {
+		r.mu.RLock()
+		status := r.status
+		r.mu.RUnlock()
+		if status.Available {
+			report.Available++
+		}
+		report.Replicas = append(report.Replicas, &status)
+	}
+	return report
+}
+
+// close 停止检查并关闭副本连接
+func (s *replicaSet) close() {
+	close(s.stop)
+	<-s.done
+	s.closeConns()
+}
+
+// closeConns 关闭副本连接
+func (s *replicaSet) closeConns() {
+	for _, r := range s.replicas {
+		r.db.Close()
+	}
+}
+
+// replicationLag 查询副本的复制延迟，非副本（或SQLite）返回0
+func (d *dialect) replicationLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	switch d.driver {
+	case DriverPostgres:
+		// 已回放到最新WAL时延迟为0，避免主库空闲时误判为落后
+		var seconds float64
+		err := db.QueryRowContext(ctx, `
+			SELECT CASE
+				WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+				ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+			END
+		`).Scan(&seconds)
+		if err != nil {
+			return 0, fmt.Errorf("failed to query replication lag: %w", err)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	case DriverMySQL:
+		return mysqlReplicationLag(ctx, db)
+	default:
+		if err := db.PingContext(ctx); err != nil {
+			return 0, fmt.Errorf("failed to ping replica: %w", err)
+		}
+		return 0, nil
+	}
+}
+
+// mysqlReplicationLag 读取 SHOW REPLICA STATUS 的 Seconds_Behind_Source
+func mysqlReplicationLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		return 0, fmt.Errorf("failed to query replication lag: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read replica status columns: %w", err)
+	}
+	if !rows.Next() {
+		// 未配置复制（直接指向主库）视为无延迟
+		return 0, rows.Err()
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, fmt.Errorf("failed to scan replica status: %w", err)
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, fmt.Errorf("replication is not running")
+		}
+		seconds, err := strconv.ParseInt(values[i].String, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid replication lag %q: %w", values[i].String, err)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, fmt.Errorf("replica status has no Seconds_Behind_Source column")
+}
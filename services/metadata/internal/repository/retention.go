@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mocks3/shared/models"
+	"time"
+)
+
+// GetBucketRetention 获取租户内bucket的保留策略，未设置时返回nil
+func (r *MetadataRepository) GetBucketRetention(ctx context.Context, bucket string) (*models.BucketRetention, error) {
+	retention, err := r.bucketRetention(ctx, r.db.reader(ctx), bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket retention: %w", err)
+	}
+	return retention, nil
+}
+
+// bucketRetention 使用指定执行器读取bucket的保留策略，未设置时返回nil
+func (r *MetadataRepository) bucketRetention(ctx context.Context, exec dbExecutor, bucket string) (*models.BucketRetention, error) {
+	retention := &models.BucketRetention{Bucket: bucket}
+	err := exec.QueryRowContext(ctx,
+		"SELECT min_retention_days, updated_at FROM bucket_retention WHERE tenant = $1 AND bucket = $2",
+		models.TenantFromContext(ctx), bucket,
+	).Scan(&retention.MinRetentionDays, &retention.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return retention, nil
+}
+
+// PutBucketRetention 设置bucket的保留策略（已存在时覆盖）
+func (r *MetadataRepository) PutBucketRetention(ctx context.Context, retention *models.BucketRetention) error {
+	retention.UpdatedAt = time.Now()
+	_, err := r.db.executor().ExecContext(ctx, r.db.dialect.upsertBucketRetentionQuery(),
+		models.TenantFromContext(ctx), retention.Bucket, retention.MinRetentionDays, retention.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to put bucket retention: %w", err)
+	}
+	return nil
+}
+
+// DeleteBucketRetention 删除bucket的保留策略，返回是否存在
+func (r *MetadataRepository) DeleteBucketRetention(ctx context.Context, bucket string) (bool, error) {
+	result, err := r.db.executor().ExecContext(ctx,
+		"DELETE FROM bucket_retention WHERE tenant = $1 AND bucket = $2",
+		models.TenantFromContext(ctx), bucket)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete bucket retention: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ListBucketRetention 列出租户内所有bucket的保留策略
+func (r *MetadataRepository) ListBucketRetention(ctx context.Context) ([]*models.BucketRetention, error) {
+	rows, err := r.db.reader(ctx).QueryContext(ctx,
+		"SELECT bucket, min_retention_days, updated_at FROM bucket_retention WHERE tenant = $1 ORDER BY bucket",
+		models.TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bucket retention: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.BucketRetention, 0)
+	for rows.Next() {
+		var retention models.BucketRetention
+		if err := rows.Scan(&retention.Bucket, &retention.MinRetentionDays, &retention.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan bucket retention: %w", err)
+		}
+		result = append(result, &retention)
+	}
+	return result, rows.Err()
+}
+
+// checkRetention 对象仍在bucket保留期内时返回*models.RetentionViolationError，调用方需已锁定对象
+func (r *MetadataRepository) checkRetention(ctx context.Context, exec dbExecutor, metadata *models.Metadata) error {
+	retention, err := r.bucketRetention(ctx, exec, metadata.Bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket retention: %w", err)
+	}
+	if retention == nil {
+		return nil
+	}
+
+	retainUntil := retention.RetainUntil(metadata.CreatedAt)
+	if !time.Now().Before(retainUntil) {
+		return nil
+	}
+	return &models.RetentionViolationError{
+		Bucket:           metadata.Bucket,
+		Key:              metadata.Key,
+		MinRetentionDays: retention.MinRetentionDays,
+		CreatedAt:        metadata.CreatedAt,
+		RetainUntil:      retainUntil,
+	}
+}
+
+// upsertBucketRetentionQuery 写入bucket保留策略，已存在时覆盖
+func (d *dialect) upsertBucketRetentionQuery() string {
+	if d.driver == DriverMySQL {
+		return `
+			INSERT INTO bucket_retention (tenant, bucket, min_retention_days, updated_at)
+			VALUES ($1, $2, $3, $4)
+			ON DUPLICATE KEY UPDATE
+				min_retention_days = VALUES(min_retention_days),
+				updated_at = VALUES(updated_at)
+		`
+	}
+	return `
+		INSERT INTO bucket_retention (tenant, bucket, min_retention_days, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant, bucket) DO UPDATE SET
+			min_retention_days = excluded.min_retention_days,
+			updated_at = excluded.updated_at
+	`
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mocks3/shared/models"
+	"time"
+)
+
+// GetBucketSchema 获取租户内bucket注册的schema，未注册时返回nil
+func (r *MetadataRepository) GetBucketSchema(ctx context.Context, bucket string) (*models.BucketSchema, error) {
+	schema := &models.BucketSchema{Bucket: bucket}
+	var document string
+	err := r.db.reader(ctx).QueryRowContext(ctx,
+		"SELECT schema_json, updated_at FROM bucket_schemas WHERE tenant = $1 AND bucket = $2",
+		models.TenantFromContext(ctx), bucket,
+	).Scan(&document, &schema.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket schema: %w", err)
+	}
+	schema.Schema = []byte(document)
+	return schema, nil
+}
+
+// PutBucketSchema 注册bucket的schema（已存在时覆盖）
+func (r *MetadataRepository) PutBucketSchema(ctx context.Context, schema *models.BucketSchema) error {
+	schema.UpdatedAt = time.Now()
+	_, err := r.db.executor().ExecContext(ctx, r.db.dialect.upsertBucketSchemaQuery(),
+		models.TenantFromContext(ctx), schema.Bucket, string(schema.Schema), schema.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to put bucket schema: %w", err)
+	}
+	return nil
+}
+
+// DeleteBucketSchema 删除bucket的schema，返回是否存在
+func (r *MetadataRepository) DeleteBucketSchema(ctx context.Context, bucket string) (bool, error) {
+	result, err := r.db.executor().ExecContext(ctx,
+		"DELETE FROM bucket_schemas WHERE tenant = $1 AND bucket = $2",
+		models.TenantFromContext(ctx), bucket)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete bucket schema: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ListBucketSchemas 列出租户内所有bucket的schema
+func (r *MetadataRepository) ListBucketSchemas(ctx context.Context) ([]*models.BucketSchema, error) {
+	rows, err := r.db.reader(ctx).QueryContext(ctx,
+		"SELECT bucket, schema_json, updated_at FROM bucket_schemas WHERE tenant = $1 ORDER BY bucket",
+		models.TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bucket schemas: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.BucketSchema, 0)
+	for rows.Next() {
+		var schema models.BucketSchema
+		var document string
+		if err := rows.Scan(&schema.Bucket, &document, &schema.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan bucket schema: %w", err)
+		}
+		schema.Schema = []byte(document)
+		result = append(result, &schema)
+	}
+	return result, rows.Err()
+}
+
+// upsertBucketSchemaQuery 写入bucket schema，已存在时覆盖
+func (d *dialect) upsertBucketSchemaQuery() string {
+	if d.driver == DriverMySQL {
+		return `
+			INSERT INTO bucket_schemas (tenant, bucket, schema_json, updated_at)
+			VALUES ($1, $2, $3, $4)
+			ON DUPLICATE KEY UPDATE
+				schema_json = VALUES(schema_json),
+				updated_at = VALUES(updated_at)
+		`
+	}
+	return `
+		INSERT INTO bucket_schemas (tenant, bucket, schema_json, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant, bucket) DO UPDATE SET
+			schema_json = excluded.schema_json,
+			updated_at = excluded.updated_at
+	`
+}
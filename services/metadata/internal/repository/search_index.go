@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/interfaces"
+	"mocks3/shared/models"
+	"regexp"
+	"strings"
+)
+
+// nonWordPattern 分词时使用的分隔符
+var nonWordPattern = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// normalizeSearchText 将文本切分为以空格分隔的小写词
+func normalizeSearchText(text string) string {
+	return strings.TrimSpace(nonWordPattern.ReplaceAllString(strings.ToLower(text), " "))
+}
+
+// PostgresSearchIndex 基于PostgreSQL全文检索的搜索索引
+// 索引由数据库表达式索引维护，因此Index/Remove无需额外操作
+type PostgresSearchIndex struct {
+	db   *Database
+	repo *MetadataRepository
+}
+
+// NewPostgresSearchIndex 创建PostgreSQL搜索索引
+func NewPostgresSearchIndex(db *Database) *PostgresSearchIndex {
+	return &PostgresSearchIndex{
+		db:   db,
+		repo: NewMetadataRepository(db),
+	}
+}
+
+// Name 索引名称
+func (p *PostgresSearchIndex) Name() string {
+	return "postgres"
+}
+
+// Index 索引元数据（由数据库表达式索引自动维护）
+func (p *PostgresSearchIndex) Index(ctx context.Context, metadata *models.Metadata) error {
+	return nil
+}
+
+// Remove 移除索引（由数据库表达式索引自动维护）
+func (p *PostgresSearchIndex) Remove(ctx context.Context, bucket, key string) error {
+	return nil
+}
+
+// Search 全文搜索
+func (p *PostgresSearchIndex) Search(ctx context.Context, query *models.MetadataSearchQuery) (*models.MetadataSearchResult, error) {
+	var args []interface{}
+	var conditions []string
+	argIndex := 1
+
+	conditions = append(conditions, "deleted_at IS NULL")
+	conditions = append(conditions, fmt.Sprintf("tenant = $%d", argIndex))
+	args = append(args, models.TenantFromContext(ctx))
+	argIndex++
+
+	scoreExpr := "0"
+	terms := normalizeSearchText(query.Query)
+	if terms != "" {
+		vector := "metadata_search_vector(key, content_type, tags, headers)"
+		conditions = append(conditions, fmt.Sprintf("%s @@ plainto_tsquery('simple', $%d)", vector, argIndex))
+		scoreExpr = fmt.Sprintf("ts_rank_cd(%s, plainto_tsquery('simple', $%d))", vector, argIndex)
+		args = append(args, terms)
+		argIndex++
+	}
+
+	if query.Bucket != "" {
+		conditions = append(conditions, fmt.Sprintf("bucket = $%d", argIndex))
+		args = append(args, query.Bucket)
+		argIndex++
+	}
+
+	if query.ContentType != "" {
+		conditions = append(conditions, fmt.Sprintf("content_type LIKE $%d", argIndex))
+		args = append(args, query.ContentType+"%")
+		argIndex++
+	}
+
+	if len(query.Tags) > 0 {
+		tagsJSON, err := json.Marshal(query.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal tag filter: %w", err)
+		}
+		conditions = append(conditions, fmt.Sprintf("tags @> $%d::jsonb", argIndex))
+		args = append(args, string(tagsJSON))
+		argIndex++
+	}
+
+	if query.SizeMin != nil {
+		conditions = append(conditions, fmt.Sprintf("size >= $%d", argIndex))
+		args = append(args, *query.SizeMin)
+		argIndex++
+	}
+
+	if query.SizeMax != nil {
+		conditions = append(conditions, fmt.Sprintf("size <= $%d", argIndex))
+		args = append(args, *query.SizeMax)
+		argIndex++
+	}
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT id, key, bucket, size, content_type, md5_hash, etag,
+			   storage_nodes, headers, tags, status, version,
+			   created_at, updated_at, deleted_at,
+			   %s AS score, COUNT(*) OVER() AS total
+		FROM metadata
+		WHERE %s
+		ORDER BY score DESC, updated_at DESC
+		LIMIT $%d OFFSET $%d
+	`, scoreExpr, strings.Join(conditions, " AND "), argIndex, argIndex+1)
+
+	args = append(args, query.Limit, query.Offset)
+
+	rows, err := p.db.GetDB().QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search metadata: %w", err)
+	}
+	defer rows.Close()
+
+	result := &models.MetadataSearchResult{
+		Query:   query.Query,
+		Backend: p.Name(),
+		Hits:    make([]*models.MetadataSearchHit, 0),
+		Limit:   query.Limit,
+		Offset:  query.Offset,
+	}
+
+	for rows.Next() {
+		var score float64
+		var total int64
+		metadata, err := p.repo.scanMetadata(rows, &score, &total)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan metadata: %w", err)
+		}
+		result.Hits = append(result.Hits, &models.MetadataSearchHit{Metadata: metadata, Score: score})
+		result.Total = total
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+// 确保实现了接口
+var _ interfaces.SearchIndex = (*PostgresSearchIndex)(nil)
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+)
+
+// validAuditActions 可查询的审计操作
+var validAuditActions = map[string]bool{
+	models.MetadataAuditCreate:  true,
+	models.MetadataAuditUpdate:  true,
+	models.MetadataAuditDelete:  true,
+	models.MetadataAuditRestore: true,
+	models.MetadataAuditImport:  true,
+}
+
+// ListMetadataAudit 查询元数据变更审计记录，按ID升序分页（NextAfter作为下一页的after）
+func (s *MetadataService) ListMetadataAudit(ctx context.Context, query *models.MetadataAuditQuery) (*models.MetadataAuditPage, error) {
+	if query.Key != "" && query.Bucket == "" {
+		return nil, fmt.Errorf("%w: key requires bucket", models.ErrInvalidArgument)
+	}
+	if query.Action != "" && !validAuditActions[query.Action] {
+		return nil, fmt.Errorf("%w: audit action %s", models.ErrInvalidArgument, query.Action)
+	}
+	if query.After < 0 {
+		return nil, fmt.Errorf("%w: after %d must not be negative", models.ErrInvalidArgument, query.After)
+	}
+	if query.Since != nil && query.Until != nil && !query.Until.After(*query.Since) {
+		return nil, fmt.Errorf("%w: until must be after since", models.ErrInvalidArgument)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	// 多取一条判断是否还有下一页
+	paged := *query
+	paged.Limit = limit + 1
+	entries, err := s.repo.ListAudit(ctx, &paged)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list metadata audit",
+			observability.String("bucket", query.Bucket),
+			observability.String("key", query.Key),
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to list metadata audit: %w", err)
+	}
+
+	page := &models.MetadataAuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.IsTruncated = true
+		page.NextAfter = page.Entries[limit-1].ID
+	}
+	return page, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"sync/atomic"
+	"time"
+)
+
+// ChangePublisher 变更事件发布者（由QueueClient实现）
+type ChangePublisher interface {
+	PublishEvent(ctx context.Context, topic string, payload []byte) (*models.TopicEvent, error)
+}
+
+// changeEmitter 异步发布元数据变更事件，单协程按入队顺序发布
+type changeEmitter struct {
+	publisher ChangePublisher
+	topic     string
+	timeout   time.Duration
+	events    chan *models.MetadataChangeEvent
+	done      chan struct{} // 发布协程退出后关闭
+
+	published atomic.Int64
+	dropped   atomic.Int64
+	failed    atomic.Int64
+}
+
+// SetChangePublisher 设置变更事件发布者，需调用StartChangePublisher后才会发布
+func (s *MetadataService) SetChangePublisher(publisher ChangePublisher, topic string, bufferSize int, timeout time.Duration) {
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	s.changes = &changeEmitter{
+		publisher: publisher,
+		topic:     topic,
+		timeout:   timeout,
+		events:    make(chan *models.MetadataChangeEvent, bufferSize),
+		done:      make(chan struct{}),
+	}
+}
+
+// StartChangePublisher 启动后台发布协程，ctx取消后尽力发送缓冲区中剩余的事件
+func (s *MetadataService) StartChangePublisher(ctx context.Context) {
+	if s.changes == nil {
+		return
+	}
+
+	go func() {
+		defer close(s.changes.done)
+		for {
+			select {
+			case <-ctx.Done():
+				s.drainChanges()
+				return
+			case event := <-s.changes.events:
+				s.publishChange(event)
+			}
+		}
+	}()
+}
+
+// WaitChangePublisher 等待发布协程发送完剩余事件并退出（需先取消StartChangePublisher的ctx）
+func (s *MetadataService) WaitChangePublisher(ctx context.Context) {
+	if s.changes == nil {
+		return
+	}
+	select {
+	case <-s.changes.done:
+	case <-ctx.Done():
+	}
+}
+
+// ChangeStats 获取变更事件发布统计，未启用时返回nil
+func (s *MetadataService) ChangeStats() *models.MetadataChangeStats {
+	if s.changes == nil {
+		return nil
+	}
+	return &models.MetadataChangeStats{
+		Topic:     s.changes.topic,
+		Queued:    len(s.changes.events),
+		Published: s.changes.published.Load(),
+		Dropped:   s.changes.dropped.Load(),
+		Failed:    s.changes.failed.Load(),
+	}
+}
+
+// emitChange 记录一条变更事件（非阻塞），缓冲区满时丢弃
+//
+// 并发修改同一对象时入队顺序可能与提交顺序不同，消费者应以Version判断先后。
+func (s *MetadataService) emitChange(ctx context.Context, eventType, operation, bucket, key string, metadata *models.Metadata) {
+	if s.changes == nil {
+		return
+	}
+
+	event := &models.MetadataChangeEvent{
+		SchemaVersion: models.MetadataChangeSchemaVersion,
+		EventID:       utils.NewID(),
+		EventType:     eventType,
+		Operation:     operation,
+		Timestamp:     time.Now().UTC(),
+		Tenant:        models.TenantFromContext(ctx),
+		Bucket:        bucket,
+		Key:           key,
+	}
+	if metadata != nil {
+		snapshot := *metadata
+		event.Metadata = &snapshot
+		event.Version = metadata.Version
+	}
+
+	select {
+	case s.changes.events <- event:
+	default:
+		s.changes.dropped.Add(1)
+		s.logger.Warn(ctx, "Change event buffer full, dropping event",
+			observability.String("event_type", eventType),
+			observability.String("bucket", bucket),
+			observability.String("key", key))
+	}
+}
+
+// publishChange 发布单条事件
+func (s *MetadataService) publishChange(event *models.MetadataChangeEvent) {
+	ctx, cancel := context.WithTimeout(models.WithTenant(context.Background(), event.Tenant), s.changes.timeout)
+	defer cancel()
+
+	payload, err := json.Marshal(event)
+	if err == nil {
+		_, err = s.changes.publisher.PublishEvent(ctx, s.changes.topic, payload)
+	}
+	if err != nil {
+		s.changes.failed.Add(1)
+		s.logger.Warn(ctx, "Failed to publish change event",
+			observability.String("event_type", event.EventType),
+			observability.String("bucket", event.Bucket),
+			observability.String("key", event.Key),
+			observability.String("error", err.Error()))
+		return
+	}
+	s.changes.published.Add(1)
+}
+
+// drainChanges 发送缓冲区中剩余的事件
+func (s *MetadataService) drainChanges() {
+	for {
+		select {
+		case event := <-s.changes.events:
+			s.publishChange(event)
+		default:
+			return
+		}
+	}
+}
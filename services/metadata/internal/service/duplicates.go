@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+)
+
+// FindMetadataByHash 查找内容哈希（MD5十六进制）和大小相同的对象
+func (s *MetadataService) FindMetadataByHash(ctx context.Context, hash string, size int64, limit int) ([]*models.Metadata, error) {
+	if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != 16 {
+		return nil, fmt.Errorf("%w: hash must be 32 hex characters", models.ErrInvalidArgument)
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("%w: size %d must not be negative", models.ErrInvalidArgument, size)
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	result, err := s.repo.FindByHash(ctx, hash, size, limit)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to find metadata by hash",
+			observability.String("hash", hash),
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to find metadata by hash: %w", err)
+	}
+	return result, nil
+}
+
+// FindDuplicates 报告内容相同、键不同的重复对象（可跨bucket），bucket非空时只统计该bucket
+func (s *MetadataService) FindDuplicates(ctx context.Context, bucket string, minCopies, limit int) (*models.DuplicateReport, error) {
+	if minCopies == 0 {
+		minCopies = 2
+	}
+	if minCopies < 2 {
+		return nil, fmt.Errorf("%w: min_copies must be at least 2", models.ErrInvalidArgument)
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	groups, err := s.repo.FindDuplicates(ctx, bucket, minCopies, limit)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to find duplicates",
+			observability.String("bucket", bucket),
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	report := &models.DuplicateReport{Groups: make([]*models.DuplicateGroup, 0, len(groups))}
+	for _, group := range groups {
+		report.Groups = append(report.Groups, group)
+		report.ReclaimableBytes += group.ReclaimableBytes
+	}
+
+	s.logger.Debug(ctx, "Duplicates found",
+		observability.String("bucket", bucket),
+		observability.Int("groups", len(report.Groups)),
+		observability.Int64("reclaimable_bytes", report.ReclaimableBytes))
+	return report, nil
+}
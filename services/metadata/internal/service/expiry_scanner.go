@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxExpiryExamples 扫描结果中保留的受影响对象数
+const maxExpiryExamples = 100
+
+// TaskEnqueuer 删除任务入队（由QueueClient实现）
+type TaskEnqueuer interface {
+	EnqueueTask(ctx context.Context, task *models.Task) error
+}
+
+// expiryScanner 对象过期扫描配置和状态
+type expiryScanner struct {
+	enqueuer  TaskEnqueuer
+	batchSize int  // 每次从数据库读取的对象数
+	maxTasks  int  // 单次扫描最多发出的删除任务数，0表示不限制
+	dryRun    bool // 只统计不发出任务
+
+	mu      sync.Mutex       // 同一时间只运行一次扫描
+	emitted map[string]int64 // 已发出删除任务的元数据ID -> 版本
+	last    atomic.Pointer[models.ExpiryScanResult]
+}
+
+// SetExpiryScanner 设置对象过期扫描：按对象的过期标签发出删除任务到队列服务
+//
+// dryRun为true时所有扫描（包括手动触发）都只统计不发出任务，此时enqueuer可以为nil。
+func (s *MetadataService) SetExpiryScanner(enqueuer TaskEnqueuer, batchSize, maxTasksPerRun int, dryRun bool) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	s.expiry = &expiryScanner{
+		enqueuer:  enqueuer,
+		batchSize: batchSize,
+		maxTasks:  maxTasksPerRun,
+		dryRun:    dryRun || enqueuer == nil,
+		emitted:   make(map[string]int64),
+	}
+}
+
+// StartExpiryScanner 启动后台定时过期扫描，未设置扫描器或interval<=0时不启动
+func (s *MetadataService) StartExpiryScanner(ctx context.Context, interval time.Duration) {
+	if s.expiry == nil || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.ScanExpiredObjects(ctx, false); err != nil && ctx.Err() == nil {
+					s.logger.Warn(ctx, "Expiry scan failed",
+						observability.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+}
+
+// ScanExpiredObjects 扫描带过期标签且已到期的对象，为每个对象版本发出一次删除任务
+//
+// 受保留期（包括GOVERNANCE模式）或法律保留保护的对象跳过，保护解除后的扫描会再处理。
+// 配置为dry-run时忽略dryRun=false。
+func (s *MetadataService) ScanExpiredObjects(ctx context.Context, dryRun bool) (*models.ExpiryScanResult, error) {
+	scanner := s.expiry
+	if scanner == nil {
+		return nil, models.ErrExpiryScannerDisabled
+	}
+
+	scanner.mu.Lock()
+	defer scanner.mu.Unlock()
+
+	result := &models.ExpiryScanResult{
+		DryRun:    dryRun || scanner.dryRun,
+		StartedAt: time.Now(),
+		Objects:   make([]*models.ExpiredObject, 0),
+	}
+	err := s.scanExpired(ctx, scanner, result)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.FinishedAt = time.Now()
+	result.DurationMs = result.FinishedAt.Sub(result.StartedAt).Milliseconds()
+	scanner.last.Store(result)
+
+	if err != nil {
+		return result, err
+	}
+
+	s.logger.Info(ctx, "Expiry scan completed",
+		observability.Bool("dry_run", result.DryRun),
+		observability.Int("scanned", result.Scanned),
+		observability.Int("expired", result.Expired),
+		observability.Int("enqueued", result.Enqueued),
+		observability.Int("held", result.Held),
+		observability.Int("failed", result.Failed),
+		observability.Duration("duration", result.FinishedAt.Sub(result.StartedAt)))
+	return result, nil
+}
+
+// LastExpiryScan 获取最近一次过期扫描的结果，未扫描过时返回nil
+func (s *MetadataService) LastExpiryScan() *models.ExpiryScanResult {
+	if s.expiry == nil {
+		return nil
+	}
+	return s.expiry.last.Load()
+}
+
+// scanExpired 逐个租户扫描带过期标签的对象，删除任务携带对象所属租户
+func (s *MetadataService) scanExpired(ctx context.Context, scanner *expiryScanner, result *models.ExpiryScanResult) error {
+	tenants, err := s.repo.ListTenants(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, tenant := range tenants {
+		if result.Truncated {
+			break
+		}
+		if err := s.scanTenantExpired(models.WithTenant(ctx, tenant.Tenant), scanner, result, seen); err != nil {
+			return err
+		}
+	}
+
+	// 完整扫描后清理已不存在（已删除或去掉过期标签）的对象记录
+	if !result.DryRun && !result.Truncated {
+		for id := range scanner.emitted {
+			if !seen[id] {
+				delete(scanner.emitted, id)
+			}
+		}
+	}
+	return nil
+}
+
+// scanTenantExpired 按bucket/key顺序分页扫描ctx中租户带过期标签的对象
+func (s *MetadataService) scanTenantExpired(ctx context.Context, scanner *expiryScanner, result *models.ExpiryScanResult, seen map[string]bool) error {
+	filter := &models.MetadataFilter{
+		TagQuery: &models.TagExpr{
+			Op: models.TagExprOr,
+			Children: []*models.TagExpr{
+				{Op: models.TagExprExists, Key: models.TagExpiresAt},
+				{Op: models.TagExprExists, Key: models.TagExpireAfterDays},
+			},
+		},
+	}
+
+	now := result.StartedAt
+	var cursor *models.MetadataCursor
+	for !result.Truncated {
+		batch, err := s.repo.ListAfter(ctx, filter, cursor, scanner.batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list metadata: %w", err)
+		}
+
+		for _, metadata := range batch {
+			if scanner.maxTasks > 0 && result.Enqueued >= scanner.maxTasks {
+				result.Truncated = true
+				break
+			}
+			result.Scanned++
+			seen[metadata.ID] = true
+			s.evaluateExpiry(ctx, scanner, metadata, now, result)
+		}
+
+		if len(batch) < scanner.batchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		cursor = &models.MetadataCursor{Bucket: last.Bucket, Key: last.Key}
+	}
+	return nil
+}
+
+// evaluateExpiry 判断单个对象是否到期，到期且未受保护时发出删除任务
+func (s *MetadataService) evaluateExpiry(ctx context.Context, scanner *expiryScanner, metadata *models.Metadata, now time.Time, result *models.ExpiryScanResult) {
+	object := &models.ExpiredObject{
+		Bucket:  metadata.Bucket,
+		Key:     metadata.Key,
+		Version: metadata.Version,
+	}
+
+	expiresAt, err := models.ObjectExpiry(metadata)
+	if err != nil {
+		result.Invalid++
+		object.Action = models.ExpiryActionInvalid
+		object.Reason = err.Error()
+		addExpiryExample(result, object)
+		return
+	}
+	if expiresAt == nil || now.Before(*expiresAt) {
+		return
+	}
+	object.ExpiresAt = expiresAt
+	result.Expired++
+
+	// 无法解析的保留设置按受保护处理，避免误删
+	hold, err := models.ParseObjectHold(metadata.Headers)
+	if err != nil {
+		result.Held++
+		object.Action = models.ExpiryActionHeld
+		object.Reason = err.Error()
+		addExpiryExample(result, object)
+		return
+	}
+	if reason := hold.BlockReason(now, false); reason != "" {
+		result.Held++
+		object.Action = models.ExpiryActionHeld
+		object.Reason = reason
+		addExpiryExample(result, object)
+		return
+	}
+
+	if version, ok := scanner.emitted[metadata.ID]; ok && version == metadata.Version {
+		result.AlreadyEnqueued++
+		return
+	}
+
+	if result.DryRun {
+		result.Enqueued++
+		object.Action = models.ExpiryActionWouldEnqueue
+		addExpiryExample(result, object)
+		return
+	}
+
+	task := &models.Task{
+		Type:      models.TaskTypeFileDeletion,
+		ObjectKey: metadata.Key,
+		Data: map[string]interface{}{
+			"bucket":     metadata.Bucket,
+			"key":        metadata.Key,
+			"version":    metadata.Version,
+			"reason":     "expired",
+			"expires_at": expiresAt.UTC().Format(time.RFC3339),
+		},
+		Tenant:    models.TenantFromContext(ctx),
+		CreatedAt: now,
+	}
+	task.GenerateID()
+
+	if err := scanner.enqueuer.EnqueueTask(ctx, task); err != nil {
+		result.Failed++
+		object.Action = models.ExpiryActionFailed
+		object.Reason = err.Error()
+		addExpiryExample(result, object)
+		s.logger.Warn(ctx, "Failed to enqueue expiry deletion task",
+			observability.String("bucket", metadata.Bucket),
+			observability.String("key", metadata.Key),
+			observability.Error(err))
+		return
+	}
+
+	scanner.emitted[metadata.ID] = metadata.Version
+	result.Enqueued++
+	object.Action = models.ExpiryActionEnqueued
+	object.TaskID = task.ID
+	addExpiryExample(result, object)
+}
+
+// addExpiryExample 记录受影响对象示例，超过上限后丢弃
+func addExpiryExample(result *models.ExpiryScanResult, object *models.ExpiredObject) {
+	if len(result.Objects) < maxExpiryExamples {
+		result.Objects = append(result.Objects, object)
+	}
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportBatchSize 导出时每次从数据库读取的记录数
+const exportBatchSize = 1000
+
+// maxImportLineSize JSONL导入时单行的最大长度
+const maxImportLineSize = 4 * 1024 * 1024
+
+// ExportMetadata 将未删除的元数据按bucket/key顺序写出为JSONL或CSV，返回导出的记录数
+//
+// 格式无效时在写出任何内容之前返回错误；写出过程中失败时已写出的内容不完整。
+func (s *MetadataService) ExportMetadata(ctx context.Context, filter *models.MetadataFilter, format string, w io.Writer) (int, error) {
+	var write func(*models.Metadata) error
+	var flush func() error
+
+	switch format {
+	case models.MetadataFormatJSONL:
+		buffered := bufio.NewWriter(w)
+		encoder := json.NewEncoder(buffered)
+		write = func(metadata *models.Metadata) error { return encoder.Encode(metadata) }
+		flush = buffered.Flush
+	case models.MetadataFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(models.MetadataCSVColumns); err != nil {
+			return 0, fmt.Errorf("failed to write csv header: %w", err)
+		}
+		write = func(metadata *models.Metadata) error { return writer.Write(metadataToCSV(metadata)) }
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	default:
+		return 0, fmt.Errorf("%w: export format %q must be %s or %s", models.ErrInvalidArgument, format, models.MetadataFormatJSONL, models.MetadataFormatCSV)
+	}
+
+	count := 0
+	var cursor *models.MetadataCursor
+	for {
+		batch, err := s.repo.ListAfter(ctx, filter, cursor, exportBatchSize)
+		if err != nil {
+			return count, fmt.Errorf("failed to list metadata: %w", err)
+		}
+
+		for _, metadata := range batch {
+			if err := write(metadata); err != nil {
+				return count, fmt.Errorf("failed to write metadata: %w", err)
+			}
+			count++
+		}
+
+		if len(batch) < exportBatchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		cursor = &models.MetadataCursor{Bucket: last.Bucket, Key: last.Key}
+	}
+
+	if err := flush(); err != nil {
+		return count, fmt.Errorf("failed to write metadata: %w", err)
+	}
+
+	s.logger.Info(ctx, "Metadata exported",
+		observability.String("bucket", filter.Bucket),
+		observability.String("prefix", filter.Prefix),
+		observability.String("format", format),
+		observability.Int("count", count))
+	return count, nil
+}
+
+// ImportMetadata 从JSONL或CSV导入元数据，保留ID、版本和时间戳
+//
+// 每条记录单独写入，导入不是原子的；conflict决定与已有记录冲突时的处理方式，
+// fail模式下遇到第一条冲突或无效记录即停止，之前的记录保持已导入状态。
+func (s *MetadataService) ImportMetadata(ctx context.Context, r io.Reader, format, conflict string) (*models.MetadataImportResult, error) {
+	if conflict == "" {
+		conflict = models.ImportConflictSkip
+	}
+	switch conflict {
+	case models.ImportConflictSkip, models.ImportConflictOverwrite, models.ImportConflictFail:
+	default:
+		return nil, fmt.Errorf("%w: conflict mode %q must be skip, overwrite or fail", models.ErrInvalidArgument, conflict)
+	}
+
+	var next func() (*models.Metadata, int, error)
+	switch format {
+	case models.MetadataFormatJSONL:
+		next = newJSONLMetadataReader(r)
+	case models.MetadataFormatCSV:
+		var err error
+		if next, err = newCSVMetadataReader(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: import format %q must be %s or %s", models.ErrInvalidArgument, format, models.MetadataFormatJSONL, models.MetadataFormatCSV)
+	}
+
+	result := &models.MetadataImportResult{}
+	fail := func(line int, metadata *models.Metadata, err error) {
+		result.Failed++
+		importErr := &models.MetadataImportError{Line: line, Error: err.Error()}
+		if metadata != nil {
+			importErr.Bucket = metadata.Bucket
+			importErr.Key = metadata.Key
+		}
+		result.Errors = append(result.Errors, importErr)
+		if conflict == models.ImportConflictFail {
+			result.Aborted = true
+		}
+	}
+
+	for !result.Aborted {
+		metadata, line, err := next()
+		if err == io.EOF {
+			break
+		}
+		var syntaxErr *importSyntaxError
+		if errors.As(err, &syntaxErr) {
+			result.Total++
+			fail(line, nil, err)
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to read import data: %w", err)
+		}
+		result.Total++
+
+		if err := s.validateMetadata(metadata); err != nil {
+			fail(line, metadata, err)
+			continue
+		}
+		normalizeImportedMetadata(metadata)
+
+		outcome, err := s.repo.Import(ctx, metadata, conflict == models.ImportConflictOverwrite)
+		if err != nil {
+			fail(line, metadata, err)
+			continue
+		}
+
+		switch outcome {
+		case models.ImportOutcomeSkipped:
+			if conflict == models.ImportConflictFail {
+				fail(line, metadata, fmt.Errorf("metadata already exists"))
+				continue
+			}
+			result.Skipped++
+		case models.ImportOutcomeOverwritten:
+			result.Overwritten++
+			result.Imported++
+			s.indexMetadata(ctx, metadata)
+		default:
+			result.Imported++
+			s.indexMetadata(ctx, metadata)
+		}
+	}
+
+	s.logger.Info(ctx, "Metadata imported",
+		observability.String("format", format),
+		observability.String("conflict", conflict),
+		observability.Int("total", result.Total),
+		observability.Int("imported", result.Imported),
+		observability.Int("skipped", result.Skipped),
+		observability.Int("failed", result.Failed))
+	return result, nil
+}
+
+// normalizeImportedMetadata 补全缺失字段，已有的ID、版本和时间戳保持不变
+func normalizeImportedMetadata(metadata *models.Metadata) {
+	if metadata.ID == "" {
+		metadata.ID = utils.NewID()
+	}
+	if metadata.Status == "" {
+		metadata.Status = "active"
+	}
+	if metadata.Headers == nil {
+		metadata.Headers = make(map[string]string)
+	}
+	if metadata.Tags == nil {
+		metadata.Tags = make(map[string]string)
+	}
+	if metadata.StorageNodes == nil {
+		metadata.StorageNodes = make([]string, 0)
+	}
+	if metadata.Version <= 0 {
+		metadata.Version = 1
+	}
+
+	now := time.Now()
+	if metadata.CreatedAt.IsZero() {
+		metadata.CreatedAt = now
+	}
+	if metadata.UpdatedAt.IsZero() {
+		metadata.UpdatedAt = metadata.CreatedAt
+	}
+	metadata.DeletedAt = nil
+}
+
+// importSyntaxError 单条记录无法解析，跳过该记录继续导入
+type importSyntaxError struct {
+	err error
+}
+
+func (e *importSyntaxError) Error() string { return e.err.Error() }
+
+// newJSONLMetadataReader 逐行解析JSONL，跳过空行
+func newJSONLMetadataReader(r io.Reader) func() (*models.Metadata, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineSize)
+	line := 0
+
+	return func() (*models.Metadata, int, error) {
+		for scanner.Scan() {
+			line++
+			data := strings.TrimSpace(scanner.Text())
+			if data == "" {
+				continue
+			}
+
+			var metadata models.Metadata
+			if err := json.Unmarshal([]byte(data), &metadata); err != nil {
+				return nil, line, &importSyntaxError{err: fmt.Errorf("invalid json: %w", err)}
+			}
+			return &metadata, line, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, line, err
+		}
+		return nil, line, io.EOF
+	}
+}
+
+// newCSVMetadataReader 按首行列名解析CSV，未知列忽略，缺失列使用默认值
+func newCSVMetadataReader(r io.Reader) (func() (*models.Metadata, int, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return func() (*models.Metadata, int, error) { return nil, 1, io.EOF }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: csv header: %v", models.ErrInvalidArgument, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"bucket", "key"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: csv header is missing column %q", models.ErrInvalidArgument, required)
+		}
+	}
+
+	return func() (*models.Metadata, int, error) {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr.Line, &importSyntaxError{err: fmt.Errorf("invalid csv: %w", err)}
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		metadata, err := metadataFromCSV(columns, record)
+		if err != nil {
+			return nil, line, &importSyntaxError{err: err}
+		}
+		return metadata, line, nil
+	}, nil
+}
+
+// metadataToCSV 按MetadataCSVColumns的顺序生成CSV行
+func metadataToCSV(metadata *models.Metadata) []string {
+	storageNodes, _ := json.Marshal(metadata.StorageNodes)
+	headers, _ := json.Marshal(metadata.Headers)
+	tags, _ := json.Marshal(metadata.Tags)
+
+	return []string{
+		metadata.ID,
+		metadata.Bucket,
+		metadata.Key,
+		strconv.FormatInt(metadata.Size, 10),
+		metadata.ContentType,
+		metadata.MD5Hash,
+		metadata.ETag,
+		string(storageNodes),
+		string(headers),
+		string(tags),
+		metadata.Status,
+		strconv.FormatInt(metadata.Version, 10),
+		metadata.CreatedAt.UTC().Format(time.RFC3339Nano),
+		metadata.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// metadataFromCSV 解析一行CSV
+func metadataFromCSV(columns map[string]int, record []string) (*models.Metadata, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	metadata := &models.Metadata{
+		ID:          field("id"),
+		Bucket:      field("bucket"),
+		Key:         field("key"),
+		ContentType: field("content_type"),
+		MD5Hash:     field("md5_hash"),
+		ETag:        field("etag"),
+		Status:      field("status"),
+	}
+
+	var err error
+	if value := field("size"); value != "" {
+		if metadata.Size, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid size %q", value)
+		}
+	}
+	if value := field("version"); value != "" {
+		if metadata.Version, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid version %q", value)
+		}
+	}
+
+	jsonFields := []struct {
+		name   string
+		target any
+	}{
+		{"storage_nodes", &metadata.StorageNodes},
+		{"headers", &metadata.Headers},
+		{"tags", &metadata.Tags},
+	}
+	for _, jf := range jsonFields {
+		if value := field(jf.name); value != "" {
+			if err := json.Unmarshal([]byte(value), jf.target); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", jf.name, err)
+			}
+		}
+	}
+
+	timeFields := []struct {
+		name   string
+		target *time.Time
+	}{
+		{"created_at", &metadata.CreatedAt},
+		{"updated_at", &metadata.UpdatedAt},
+	}
+	for _, tf := range timeFields {
+		if value := field(tf.name); value != "" {
+			if *tf.target, err = time.Parse(time.RFC3339Nano, value); err != nil {
+				return nil, fmt.Errorf("invalid %s %q, expected RFC3339", tf.name, value)
+			}
+		}
+	}
+
+	return metadata, nil
+}
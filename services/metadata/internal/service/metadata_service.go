@@ -2,18 +2,39 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"mocks3/shared/interfaces"
+	"mocks3/shared/jsonschema"
 	"mocks3/shared/models"
 	"mocks3/shared/observability"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// 键空间分片参数
+const (
+	maxKeyspaceShards  = 1024
+	samplesPerShard    = 100
+	maxShardSampleSize = 10000
+)
+
+// maxBatchOperations 单次批量操作的最大项数
+const maxBatchOperations = 1000
+
 // MetadataService 元数据服务实现
 type MetadataService struct {
-	repo   interfaces.MetadataRepository
-	logger *observability.Logger
+	repo             interfaces.MetadataRepository
+	searchIndex      interfaces.SearchIndex
+	deletedRetention time.Duration // 软删除记录保留时长，0表示永久保留
+	changes          *changeEmitter // 变更事件发布，为空时不发布
+	statsInterval    time.Duration  // 后台统计汇总间隔，0表示实时计算
+	statsSnapshot    atomic.Pointer[statsSnapshot]
+	expiry           *expiryScanner // 对象过期扫描，为空时不扫描
+	schemas          jsonschema.Cache // 已编译的bucket schema
+	logger           *observability.Logger
 }
 
 // NewMetadataService 创建元数据服务
@@ -24,7 +45,57 @@ func NewMetadataService(repo interfaces.MetadataRepository, logger *observabilit
 	}
 }
 
-// SaveMetadata 保存元数据
+// SetSearchIndex 设置搜索索引
+func (s *MetadataService) SetSearchIndex(index interfaces.SearchIndex) {
+	s.searchIndex = index
+}
+
+// SetDeletedRetention 设置软删除记录的保留时长
+func (s *MetadataService) SetDeletedRetention(retention time.Duration) {
+	s.deletedRetention = retention
+}
+
+// RebuildSearchIndex 从仓库重建所有租户的搜索索引
+func (s *MetadataService) RebuildSearchIndex(ctx context.Context) (int, error) {
+	if s.searchIndex == nil {
+		return 0, nil
+	}
+
+	tenants, err := s.repo.ListTenants(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	const batchSize = 1000
+	indexed := 0
+	for _, tenant := range tenants {
+		tenantCtx := models.WithTenant(ctx, tenant.Tenant)
+		for offset := 0; ; offset += batchSize {
+			batch, err := s.repo.List(tenantCtx, "", "", batchSize, offset)
+			if err != nil {
+				return indexed, fmt.Errorf("failed to list metadata: %w", err)
+			}
+
+			for _, metadata := range batch {
+				if err := s.searchIndex.Index(tenantCtx, metadata); err != nil {
+					return indexed, fmt.Errorf("failed to index metadata: %w", err)
+				}
+				indexed++
+			}
+
+			if len(batch) < batchSize {
+				break
+			}
+		}
+	}
+
+	s.logger.Info(ctx, "Search index rebuilt",
+		observability.String("backend", s.searchIndex.Name()),
+		observability.Int("indexed", indexed))
+	return indexed, nil
+}
+
+// SaveMetadata 保存元数据，自定义元数据不符合bucket schema时返回*models.SchemaValidationError
 func (s *MetadataService) SaveMetadata(ctx context.Context, metadata *models.Metadata) error {
 	s.logger.Info(ctx, "Saving metadata", 
 		observability.String("bucket", metadata.Bucket), 
@@ -36,15 +107,18 @@ func (s *MetadataService) SaveMetadata(ctx context.Context, metadata *models.Met
 			observability.String("error", err.Error()), 
 			observability.String("bucket", metadata.Bucket), 
 			observability.String("key", metadata.Key))
-		return fmt.Errorf("invalid metadata: %w", err)
+		return fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+	}
+	if err := s.validateAgainstSchema(ctx, metadata); err != nil {
+		return err
 	}
 
 	// 设置默认值
 	s.setDefaults(metadata)
 
-	// 检查是否已存在
-	existing, err := s.repo.GetByKey(ctx, metadata.Bucket, metadata.Key)
-	if err != nil && !strings.Contains(err.Error(), "not found") {
+	// 检查是否已存在（读主库，避免副本延迟导致重复创建）
+	existing, err := s.repo.GetByKey(models.WithPrimaryRead(ctx), metadata.Bucket, metadata.Key)
+	if err != nil && !errors.Is(err, models.ErrMetadataNotFound) {
 		s.logger.Error(ctx, "Failed to check existing metadata", 
 			observability.String("error", err.Error()))
 		return fmt.Errorf("failed to check existing metadata: %w", err)
@@ -66,6 +140,7 @@ func (s *MetadataService) SaveMetadata(ctx context.Context, metadata *models.Met
 			observability.String("bucket", metadata.Bucket), 
 			observability.String("key", metadata.Key), 
 			observability.Int64("version", metadata.Version))
+		s.emitChange(ctx, models.MetadataUpdated, models.ChangeOpSave, metadata.Bucket, metadata.Key, metadata)
 	} else {
 		// 创建新元数据
 		if err := s.repo.Create(ctx, metadata); err != nil {
@@ -78,8 +153,10 @@ func (s *MetadataService) SaveMetadata(ctx context.Context, metadata *models.Met
 			observability.String("bucket", metadata.Bucket), 
 			observability.String("key", metadata.Key), 
 			observability.String("id", metadata.ID))
+		s.emitChange(ctx, models.MetadataCreated, models.ChangeOpSave, metadata.Bucket, metadata.Key, metadata)
 	}
 
+	s.indexMetadata(ctx, metadata)
 	return nil
 }
 
@@ -90,16 +167,16 @@ func (s *MetadataService) GetMetadata(ctx context.Context, bucket, key string) (
 		observability.String("key", key))
 
 	if err := s.validateBucketKey(bucket, key); err != nil {
-		return nil, fmt.Errorf("invalid bucket or key: %w", err)
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
 	}
 
 	metadata, err := s.repo.GetByKey(ctx, bucket, key)
 	if err != nil {
-		s.logger.Warn(ctx, "Metadata not found", 
+		s.logger.Warn(ctx, "Failed to get metadata", 
 			observability.String("bucket", bucket), 
 			observability.String("key", key), 
 			observability.String("error", err.Error()))
-		return nil, fmt.Errorf("metadata not found: %w", err)
+		return nil, err
 	}
 
 	s.logger.Debug(ctx, "Metadata retrieved", 
@@ -109,14 +186,17 @@ func (s *MetadataService) GetMetadata(ctx context.Context, bucket, key string) (
 	return metadata, nil
 }
 
-// UpdateMetadata 更新元数据
+// UpdateMetadata 更新元数据，自定义元数据不符合bucket schema时返回*models.SchemaValidationError
 func (s *MetadataService) UpdateMetadata(ctx context.Context, metadata *models.Metadata) error {
 	s.logger.Info(ctx, "Updating metadata", 
 		observability.String("bucket", metadata.Bucket), 
 		observability.String("key", metadata.Key))
 
 	if err := s.validateMetadata(metadata); err != nil {
-		return fmt.Errorf("invalid metadata: %w", err)
+		return fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+	}
+	if err := s.validateAgainstSchema(ctx, metadata); err != nil {
+		return err
 	}
 
 	if err := s.repo.Update(ctx, metadata); err != nil {
@@ -125,6 +205,9 @@ func (s *MetadataService) UpdateMetadata(ctx context.Context, metadata *models.M
 		return fmt.Errorf("failed to update metadata: %w", err)
 	}
 
+	s.indexMetadata(ctx, metadata)
+	s.emitChange(ctx, models.MetadataUpdated, models.ChangeOpUpdate, metadata.Bucket, metadata.Key, metadata)
+
 	s.logger.Info(ctx, "Metadata updated successfully", 
 		observability.String("bucket", metadata.Bucket), 
 		observability.String("key", metadata.Key))
@@ -138,10 +221,18 @@ func (s *MetadataService) DeleteMetadata(ctx context.Context, bucket, key string
 		observability.String("key", key))
 
 	if err := s.validateBucketKey(bucket, key); err != nil {
-		return fmt.Errorf("invalid bucket or key: %w", err)
+		return fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
 	}
 
 	if err := s.repo.Delete(ctx, bucket, key); err != nil {
+		var violation *models.RetentionViolationError
+		if errors.As(err, &violation) {
+			s.logger.Warn(ctx, "Delete rejected by bucket retention",
+				observability.String("bucket", bucket),
+				observability.String("key", key),
+				observability.String("retain_until", violation.RetainUntil.Format(time.RFC3339)))
+			return err
+		}
 		s.logger.Error(ctx, "Failed to delete metadata", 
 			observability.String("error", err.Error()), 
 			observability.String("bucket", bucket), 
@@ -149,17 +240,332 @@ func (s *MetadataService) DeleteMetadata(ctx context.Context, bucket, key string
 		return fmt.Errorf("failed to delete metadata: %w", err)
 	}
 
+	if s.searchIndex != nil {
+		if err := s.searchIndex.Remove(ctx, bucket, key); err != nil {
+			s.logger.Warn(ctx, "Failed to remove metadata from search index",
+				observability.String("error", err.Error()))
+		}
+	}
+
+	s.emitChange(ctx, models.MetadataDeleted, models.ChangeOpDelete, bucket, key, nil)
+
 	s.logger.Info(ctx, "Metadata deleted successfully", 
 		observability.String("bucket", bucket), 
 		observability.String("key", key))
 	return nil
 }
 
+// RestoreMetadata 恢复保留期内被删除的元数据
+func (s *MetadataService) RestoreMetadata(ctx context.Context, bucket, key string) (*models.Metadata, error) {
+	s.logger.Info(ctx, "Restoring metadata",
+		observability.String("bucket", bucket),
+		observability.String("key", key))
+
+	if err := s.validateBucketKey(bucket, key); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+	}
+
+	// 已存在未删除的同名对象时不能恢复
+	if existing, err := s.repo.GetByKey(models.WithPrimaryRead(ctx), bucket, key); err == nil && existing != nil {
+		return nil, fmt.Errorf("%w: %s/%s", models.ErrMetadataExists, bucket, key)
+	}
+
+	var deletedAfter time.Time
+	if s.deletedRetention > 0 {
+		deletedAfter = time.Now().Add(-s.deletedRetention)
+	}
+
+	metadata, err := s.repo.Restore(ctx, bucket, key, deletedAfter)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to restore metadata",
+			observability.String("error", err.Error()),
+			observability.String("bucket", bucket),
+			observability.String("key", key))
+		return nil, err
+	}
+
+	s.indexMetadata(ctx, metadata)
+	s.emitChange(ctx, models.MetadataCreated, models.ChangeOpRestore, bucket, key, metadata)
+
+	s.logger.Info(ctx, "Metadata restored successfully",
+		observability.String("bucket", bucket),
+		observability.String("key", key))
+	return metadata, nil
+}
+
+// BatchMetadata 在一个事务中执行批量创建/更新/删除，返回逐项结果
+func (s *MetadataService) BatchMetadata(ctx context.Context, req *models.MetadataBatchRequest) (*models.MetadataBatchResponse, error) {
+	s.logger.Info(ctx, "Executing metadata batch",
+		observability.Int("operations", len(req.Operations)),
+		observability.Bool("atomic", req.Atomic))
+
+	if len(req.Operations) == 0 {
+		return nil, fmt.Errorf("%w: batch has no operations", models.ErrInvalidArgument)
+	}
+	if len(req.Operations) > maxBatchOperations {
+		return nil, fmt.Errorf("%w: batch allows at most %d operations", models.ErrInvalidArgument, maxBatchOperations)
+	}
+
+	response := &models.MetadataBatchResponse{
+		Results: make([]*models.MetadataBatchItemResult, len(req.Operations)),
+		Atomic:  req.Atomic,
+	}
+
+	// 先在服务层校验，无效项不进入数据库
+	var valid []*models.MetadataBatchOperation
+	var validIndexes []int
+	for i, op := range req.Operations {
+		if op == nil {
+			op = &models.MetadataBatchOperation{}
+		}
+		op.Op = strings.ToLower(strings.TrimSpace(op.Op))
+		if err := s.prepareBatchOperation(ctx, op); err != nil {
+			response.Results[i] = &models.MetadataBatchItemResult{
+				Index: i, Op: op.Op, Bucket: op.Bucket, Key: op.Key,
+				Status: models.BatchItemFailed, Error: err.Error(),
+			}
+			continue
+		}
+		valid = append(valid, op)
+		validIndexes = append(validIndexes, i)
+	}
+
+	abort := req.Atomic && len(valid) < len(req.Operations)
+	if !abort && len(valid) > 0 {
+		results, committed, err := s.repo.ExecuteBatch(ctx, valid, req.Atomic)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to execute metadata batch",
+				observability.String("error", err.Error()))
+			return nil, fmt.Errorf("failed to execute batch: %w", err)
+		}
+		for j, result := range results {
+			result.Index = validIndexes[j]
+			response.Results[result.Index] = result
+		}
+		response.Committed = committed
+	}
+
+	for i, result := range response.Results {
+		if result == nil {
+			op := req.Operations[i]
+			result = &models.MetadataBatchItemResult{
+				Index: i, Op: op.Op, Bucket: op.Bucket, Key: op.Key,
+				Status: models.BatchItemAborted,
+			}
+			response.Results[i] = result
+		}
+
+		switch result.Status {
+		case models.BatchItemSucceeded:
+			response.Succeeded++
+			switch result.Op {
+			case models.BatchOpDelete:
+				if s.searchIndex != nil {
+					if err := s.searchIndex.Remove(ctx, result.Bucket, result.Key); err != nil {
+						s.logger.Warn(ctx, "Failed to remove metadata from search index",
+							observability.String("error", err.Error()))
+					}
+				}
+				s.emitChange(ctx, models.MetadataDeleted, models.ChangeOpBatch, result.Bucket, result.Key, nil)
+			case models.BatchOpCreate:
+				s.indexMetadata(ctx, result.Metadata)
+				s.emitChange(ctx, models.MetadataCreated, models.ChangeOpBatch, result.Bucket, result.Key, result.Metadata)
+			default:
+				s.indexMetadata(ctx, result.Metadata)
+				s.emitChange(ctx, models.MetadataUpdated, models.ChangeOpBatch, result.Bucket, result.Key, result.Metadata)
+			}
+		default:
+			response.Failed++
+		}
+	}
+
+	s.logger.Info(ctx, "Metadata batch executed",
+		observability.Int("succeeded", response.Succeeded),
+		observability.Int("failed", response.Failed),
+		observability.Bool("committed", response.Committed))
+	return response, nil
+}
+
+// prepareBatchOperation 校验批量操作项（含bucket schema）并补全默认值
+func (s *MetadataService) prepareBatchOperation(ctx context.Context, op *models.MetadataBatchOperation) error {
+	switch op.Op {
+	case models.BatchOpCreate, models.BatchOpUpdate:
+		if err := s.validateMetadata(op.Metadata); err != nil {
+			return fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+		}
+		if err := s.validateAgainstSchema(ctx, op.Metadata); err != nil {
+			return err
+		}
+		s.setDefaults(op.Metadata)
+		op.Bucket = op.Metadata.Bucket
+		op.Key = op.Metadata.Key
+	case models.BatchOpDelete:
+		if op.Metadata != nil {
+			if op.Bucket == "" {
+				op.Bucket = op.Metadata.Bucket
+			}
+			if op.Key == "" {
+				op.Key = op.Metadata.Key
+			}
+		}
+		if err := s.validateBucketKey(op.Bucket, op.Key); err != nil {
+			return fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+		}
+	default:
+		return fmt.Errorf("unsupported batch operation: %q", op.Op)
+	}
+	return nil
+}
+
+// PurgeDeleted 物理删除超过保留期的软删除记录
+func (s *MetadataService) PurgeDeleted(ctx context.Context, batchSize int) (int64, error) {
+	if s.deletedRetention <= 0 {
+		return 0, nil
+	}
+
+	deletedBefore := time.Now().Add(-s.deletedRetention)
+	var total int64
+	for {
+		purged, err := s.repo.PurgeDeleted(ctx, deletedBefore, batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to purge deleted metadata: %w", err)
+		}
+		total += purged
+
+		if purged < int64(batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info(ctx, "Purged deleted metadata",
+			observability.Int64("purged", total),
+			observability.String("deleted_before", deletedBefore.Format(time.RFC3339)))
+	}
+	return total, nil
+}
+
+// StartPurgeJob 启动后台定时清理任务
+func (s *MetadataService) StartPurgeJob(ctx context.Context, interval time.Duration, batchSize int) {
+	if interval <= 0 || s.deletedRetention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.PurgeDeleted(ctx, batchSize); err != nil {
+					s.logger.Warn(ctx, "Purge job failed",
+						observability.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+}
+
+// GetMetadataHistory 获取元数据历史版本
+func (s *MetadataService) GetMetadataHistory(ctx context.Context, bucket, key string, limit, offset int) ([]*models.MetadataVersion, error) {
+	s.logger.Debug(ctx, "Getting metadata history",
+		observability.String("bucket", bucket),
+		observability.String("key", key))
+
+	if err := s.validateBucketKey(bucket, key); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+	}
+
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	versions, err := s.repo.ListHistory(ctx, bucket, key, limit, offset)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get metadata history",
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to get metadata history: %w", err)
+	}
+
+	if versions == nil {
+		versions = make([]*models.MetadataVersion, 0)
+	}
+	return versions, nil
+}
+
+// RestoreMetadataVersion 将元数据恢复到指定历史版本
+// 恢复本身也是一次更新：当前版本会先进入历史表，版本号继续递增
+func (s *MetadataService) RestoreMetadataVersion(ctx context.Context, bucket, key string, version int64) (*models.Metadata, error) {
+	s.logger.Info(ctx, "Restoring metadata version",
+		observability.String("bucket", bucket),
+		observability.String("key", key),
+		observability.Int64("version", version))
+
+	if err := s.validateBucketKey(bucket, key); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+	}
+
+	// 基于主库的当前版本恢复，避免副本延迟导致版本冲突
+	primaryCtx := models.WithPrimaryRead(ctx)
+	current, err := s.repo.GetByKey(primaryCtx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Version == version {
+		return current, nil
+	}
+
+	target, err := s.repo.GetVersion(primaryCtx, bucket, key, version)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := target.Metadata
+	restored.ID = current.ID
+	restored.Version = current.Version
+	restored.CreatedAt = current.CreatedAt
+	restored.DeletedAt = nil
+	if restored.Status == "" || restored.Status == "deleted" {
+		restored.Status = "active"
+	}
+
+	if err := s.repo.Update(ctx, &restored); err != nil {
+		s.logger.Error(ctx, "Failed to restore metadata version",
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to restore metadata: %w", err)
+	}
+
+	s.indexMetadata(ctx, &restored)
+	s.emitChange(ctx, models.MetadataUpdated, models.ChangeOpRestoreVersion, bucket, key, &restored)
+
+	s.logger.Info(ctx, "Metadata version restored",
+		observability.String("bucket", bucket),
+		observability.String("key", key),
+		observability.Int64("restored_from", version),
+		observability.Int64("version", restored.Version))
+	return &restored, nil
+}
+
 // ListMetadata 列出元数据
 func (s *MetadataService) ListMetadata(ctx context.Context, bucket, prefix string, limit, offset int) ([]*models.Metadata, error) {
+	return s.ListMetadataFiltered(ctx, &models.MetadataFilter{Bucket: bucket, Prefix: prefix}, limit, offset)
+}
+
+// ListMetadataFiltered 按过滤条件列出元数据
+func (s *MetadataService) ListMetadataFiltered(ctx context.Context, filter *models.MetadataFilter, limit, offset int) ([]*models.Metadata, error) {
 	s.logger.Debug(ctx, "Listing metadata", 
-		observability.String("bucket", bucket), 
-		observability.String("prefix", prefix), 
+		observability.String("bucket", filter.Bucket), 
+		observability.String("prefix", filter.Prefix), 
 		observability.Int("limit", limit), 
 		observability.Int("offset", offset))
 
@@ -174,7 +580,11 @@ func (s *MetadataService) ListMetadata(ctx context.Context, bucket, prefix strin
 		offset = 0
 	}
 
-	metadataList, err := s.repo.List(ctx, bucket, prefix, limit, offset)
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	metadataList, err := s.repo.ListFiltered(ctx, filter, limit, offset)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to list metadata", 
 			observability.String("error", err.Error()))
@@ -186,6 +596,215 @@ func (s *MetadataService) ListMetadata(ctx context.Context, bucket, prefix strin
 	return metadataList, nil
 }
 
+// ListMetadataPage 基于continuation token的游标分页列表
+func (s *MetadataService) ListMetadataPage(ctx context.Context, filter *models.MetadataFilter, continuationToken string, limit int) (*models.MetadataPage, error) {
+	s.logger.Debug(ctx, "Listing metadata page",
+		observability.String("bucket", filter.Bucket),
+		observability.String("prefix", filter.Prefix),
+		observability.Int("limit", limit))
+
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	var cursor *models.MetadataCursor
+	if continuationToken != "" {
+		var err error
+		if cursor, err = models.DecodeMetadataCursor(continuationToken); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.Delimiter != "" {
+		return s.listMetadataDelimited(ctx, filter, cursor, continuationToken, limit)
+	}
+
+	// 多取一条用于判断是否还有下一页
+	metadataList, err := s.repo.ListAfter(ctx, filter, cursor, limit+1)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list metadata page",
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to list metadata: %w", err)
+	}
+
+	page := &models.MetadataPage{
+		Limit:             limit,
+		ContinuationToken: continuationToken,
+	}
+
+	if len(metadataList) > limit {
+		metadataList = metadataList[:limit]
+		page.IsTruncated = true
+
+		last := metadataList[len(metadataList)-1]
+		next := &models.MetadataCursor{Bucket: last.Bucket, Key: last.Key}
+		if filter.HasModifiedRange() {
+			updatedAt := last.UpdatedAt
+			next.UpdatedAt = &updatedAt
+		}
+		page.NextContinuationToken = next.Encode()
+	}
+
+	if metadataList == nil {
+		metadataList = make([]*models.Metadata, 0)
+	}
+	page.Metadata = metadataList
+	page.Count = len(metadataList)
+
+	return page, nil
+}
+
+// listMetadataDelimited 按分隔符分层列出对象和公共前缀，游标为上一页最后一项（对象key或公共前缀）
+func (s *MetadataService) listMetadataDelimited(ctx context.Context, filter *models.MetadataFilter, cursor *models.MetadataCursor, continuationToken string, limit int) (*models.MetadataPage, error) {
+	if filter.Bucket == "" {
+		return nil, fmt.Errorf("%w: bucket is required when delimiter is set", models.ErrInvalidArgument)
+	}
+	if filter.HasModifiedRange() {
+		return nil, fmt.Errorf("%w: delimiter cannot be combined with modified_after/modified_before", models.ErrInvalidArgument)
+	}
+
+	after := ""
+	if cursor != nil {
+		after = cursor.Key
+	}
+
+	// 多取一条用于判断是否还有下一页
+	entries, err := s.repo.ListDelimited(ctx, filter, after, limit+1)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list metadata with delimiter",
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to list metadata: %w", err)
+	}
+
+	page := &models.MetadataPage{
+		Metadata:          make([]*models.Metadata, 0),
+		Limit:             limit,
+		ContinuationToken: continuationToken,
+	}
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.IsTruncated = true
+
+		next := &models.MetadataCursor{Bucket: filter.Bucket, Key: entries[len(entries)-1].Name()}
+		page.NextContinuationToken = next.Encode()
+	}
+
+	for _, entry := range entries {
+		if entry.Prefix != "" {
+			page.CommonPrefixes = append(page.CommonPrefixes, entry.Prefix)
+		} else {
+			page.Metadata = append(page.Metadata, entry.Metadata)
+		}
+	}
+	page.Count = len(entries)
+
+	return page, nil
+}
+
+// QueryMetadataByTags 按标签查询表达式筛选元数据（游标分页）
+func (s *MetadataService) QueryMetadataByTags(ctx context.Context, query string, filter *models.MetadataFilter, continuationToken string, limit int) (*models.MetadataPage, error) {
+	expr, err := models.ParseTagQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug(ctx, "Querying metadata by tags",
+		observability.String("query", expr.String()),
+		observability.String("bucket", filter.Bucket))
+
+	filter.TagQuery = expr
+	return s.ListMetadataPage(ctx, filter, continuationToken, limit)
+}
+
+// ShardKeyspace 通过采样将bucket键空间切分为N个近似等量的范围，供外部并行列举
+func (s *MetadataService) ShardKeyspace(ctx context.Context, bucket, prefix string, shards int) (*models.MetadataShardPlan, error) {
+	s.logger.Debug(ctx, "Sharding keyspace",
+		observability.String("bucket", bucket),
+		observability.String("prefix", prefix),
+		observability.Int("shards", shards))
+
+	if bucket == "" {
+		return nil, fmt.Errorf("%w: bucket is required", models.ErrInvalidArgument)
+	}
+	if shards <= 0 || shards > maxKeyspaceShards {
+		return nil, fmt.Errorf("%w: shards must be between 1 and %d", models.ErrInvalidArgument, maxKeyspaceShards)
+	}
+
+	total, err := s.repo.Count(ctx, bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count objects: %w", err)
+	}
+
+	sampleSize := shards * samplesPerShard
+	if sampleSize > maxShardSampleSize {
+		sampleSize = maxShardSampleSize
+	}
+
+	samples, err := s.repo.SampleKeys(ctx, bucket, prefix, sampleSize)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to sample keys",
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to sample keys: %w", err)
+	}
+
+	// 取样本的分位点作为分片边界，跳过重复边界
+	boundaries := make([]string, 0, shards-1)
+	counts := make([]int, 0, shards)
+	last := 0
+	for i := 1; i < shards && len(samples) > 0; i++ {
+		idx := i * len(samples) / shards
+		if idx <= last || idx >= len(samples) {
+			continue
+		}
+		boundary := samples[idx-1]
+		if len(boundaries) > 0 && boundary <= boundaries[len(boundaries)-1] {
+			continue
+		}
+		boundaries = append(boundaries, boundary)
+		counts = append(counts, idx-last)
+		last = idx
+	}
+	counts = append(counts, len(samples)-last)
+
+	plan := &models.MetadataShardPlan{
+		Bucket:       bucket,
+		Prefix:       prefix,
+		Shards:       len(boundaries) + 1,
+		SampleSize:   len(samples),
+		TotalObjects: total,
+		Ranges:       make([]*models.MetadataKeyRange, 0, len(boundaries)+1),
+	}
+
+	startAfter := ""
+	for i := 0; i <= len(boundaries); i++ {
+		keyRange := &models.MetadataKeyRange{Index: i, StartAfter: startAfter}
+		if i < len(boundaries) {
+			keyRange.EndKey = boundaries[i]
+			startAfter = boundaries[i]
+		}
+		if len(samples) > 0 {
+			keyRange.EstimatedCount = total * int64(counts[i]) / int64(len(samples))
+		} else {
+			keyRange.EstimatedCount = total
+		}
+		plan.Ranges = append(plan.Ranges, keyRange)
+	}
+
+	s.logger.Debug(ctx, "Keyspace sharded",
+		observability.String("bucket", bucket),
+		observability.Int("shards", plan.Shards),
+		observability.Int("sample_size", plan.SampleSize))
+	return plan, nil
+}
+
 // SearchMetadata 搜索元数据
 func (s *MetadataService) SearchMetadata(ctx context.Context, query string, limit int) ([]*models.Metadata, error) {
 	s.logger.Debug(ctx, "Searching metadata", 
@@ -203,6 +822,22 @@ func (s *MetadataService) SearchMetadata(ctx context.Context, query string, limi
 		limit = 1000
 	}
 
+	// 优先使用搜索索引
+	if s.searchIndex != nil {
+		result, err := s.searchIndex.Search(ctx, &models.MetadataSearchQuery{Query: query, Limit: limit})
+		if err != nil {
+			s.logger.Error(ctx, "Failed to search metadata", 
+				observability.String("error", err.Error()))
+			return nil, fmt.Errorf("failed to search metadata: %w", err)
+		}
+
+		metadataList := make([]*models.Metadata, len(result.Hits))
+		for i, hit := range result.Hits {
+			metadataList[i] = hit.Metadata
+		}
+		return metadataList, nil
+	}
+
 	metadataList, err := s.repo.Search(ctx, query, limit)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to search metadata", 
@@ -216,10 +851,54 @@ func (s *MetadataService) SearchMetadata(ctx context.Context, query string, limi
 	return metadataList, nil
 }
 
-// GetStats 获取统计信息
+// SearchObjects 使用搜索索引进行带过滤和排序的全文搜索
+func (s *MetadataService) SearchObjects(ctx context.Context, query *models.MetadataSearchQuery) (*models.MetadataSearchResult, error) {
+	s.logger.Debug(ctx, "Searching objects",
+		observability.String("query", query.Query),
+		observability.String("bucket", query.Bucket))
+
+	if s.searchIndex == nil {
+		return nil, fmt.Errorf("search index not configured")
+	}
+
+	if strings.TrimSpace(query.Query) == "" && query.Bucket == "" && query.ContentType == "" &&
+		len(query.Tags) == 0 && query.SizeMin == nil && query.SizeMax == nil {
+		return nil, fmt.Errorf("%w: search query or at least one filter is required", models.ErrInvalidArgument)
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	if query.Limit > 1000 {
+		query.Limit = 1000
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	result, err := s.searchIndex.Search(ctx, query)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to search objects",
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to search objects: %w", err)
+	}
+
+	s.logger.Debug(ctx, "Object search completed",
+		observability.Int64("total", result.Total),
+		observability.Int("returned", len(result.Hits)))
+	return result, nil
+}
+
+// GetStats 获取统计信息，启用后台汇总时返回最近一次汇总结果
 func (s *MetadataService) GetStats(ctx context.Context) (*models.Stats, error) {
 	s.logger.Debug(ctx, "Getting statistics")
 
+	if s.statsInterval > 0 {
+		if snapshot := s.statsSnapshot.Load(); snapshot != nil {
+			return s.withStatsFreshness(snapshot.forTenant(models.TenantFromContext(ctx))), nil
+		}
+	}
+
 	stats, err := s.repo.GetStats(ctx)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to get statistics", 
@@ -232,6 +911,7 @@ func (s *MetadataService) GetStats(ctx context.Context) (*models.Stats, error) {
 		observability.Int64("total_size", stats.TotalSize),
 		observability.Int("buckets", len(stats.BucketStats)))
 
+	stats.Source = models.StatsSourceLive
 	return stats, nil
 }
 
@@ -253,6 +933,153 @@ func (s *MetadataService) CountObjects(ctx context.Context, bucket, prefix strin
 	return count, nil
 }
 
+// GetUsage 获取bucket/前缀下的对象数和字节数
+func (s *MetadataService) GetUsage(ctx context.Context, bucket, prefix string) (*models.ObjectSummary, error) {
+	usage, err := s.repo.Usage(ctx, bucket, prefix)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get usage",
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+	return usage, nil
+}
+
+// ListTagCounts 统计标签键值对的使用次数
+func (s *MetadataService) ListTagCounts(ctx context.Context, bucket string, limit int) ([]*models.TagCount, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	counts, err := s.repo.TagCounts(ctx, bucket, limit)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count tags",
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ListTenants 按租户汇总用量（所有租户）
+func (s *MetadataService) ListTenants(ctx context.Context) ([]*models.TenantStats, error) {
+	tenants, err := s.repo.ListTenants(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list tenants",
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	if tenants == nil {
+		tenants = []*models.TenantStats{}
+	}
+	return tenants, nil
+}
+
+// PreviewLifecycleRule 预览生命周期规则的影响范围（只读，不修改任何对象）
+func (s *MetadataService) PreviewLifecycleRule(ctx context.Context, req *models.LifecyclePreviewRequest) (*models.LifecyclePreview, error) {
+	rule := req.Rule
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	preview := &models.LifecyclePreview{
+		Rule:        rule,
+		Mode:        req.Mode,
+		EvaluatedAt: time.Now().UTC(),
+	}
+
+	samplePercent := 0.0
+	switch req.Mode {
+	case "", models.LifecyclePreviewExact:
+		preview.Mode = models.LifecyclePreviewExact
+	case models.LifecyclePreviewSample:
+		samplePercent = req.SamplePercent
+		if samplePercent == 0 {
+			samplePercent = 10
+		}
+		if samplePercent < 0 || samplePercent > 100 {
+			return nil, fmt.Errorf("%w: sample_percent must be between 0 and 100", models.ErrInvalidArgument)
+		}
+		if samplePercent < 100 {
+			preview.Estimated = true
+			preview.SamplePercent = samplePercent
+		}
+	default:
+		return nil, fmt.Errorf("%w: mode must be %s or %s", models.ErrInvalidArgument, models.LifecyclePreviewExact, models.LifecyclePreviewSample)
+	}
+
+	examples := req.Examples
+	if examples <= 0 {
+		examples = 10
+	}
+	if examples > 1000 {
+		examples = 1000
+	}
+
+	s.logger.Info(ctx, "Previewing lifecycle rule",
+		observability.String("bucket", rule.Bucket),
+		observability.String("prefix", rule.Prefix),
+		observability.Int("expiration_days", rule.ExpirationDays),
+		observability.Int("transition_days", rule.TransitionDays),
+		observability.String("mode", preview.Mode))
+
+	// cutoffs[0]为过期截止时间，cutoffs[1]为转换截止时间（存在时）
+	var cutoffs []time.Time
+	if rule.ExpirationDays > 0 {
+		expireBefore := preview.EvaluatedAt.AddDate(0, 0, -rule.ExpirationDays)
+		preview.ExpireBefore = &expireBefore
+		cutoffs = append(cutoffs, expireBefore)
+	}
+	if rule.TransitionDays > 0 {
+		transitionBefore := preview.EvaluatedAt.AddDate(0, 0, -rule.TransitionDays)
+		preview.TransitionBefore = &transitionBefore
+		cutoffs = append(cutoffs, transitionBefore)
+	}
+
+	total, older, err := s.repo.SummarizeByAge(ctx, rule.Filter(), cutoffs, samplePercent)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to preview lifecycle rule",
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to preview lifecycle rule: %w", err)
+	}
+
+	preview.Matched = *total
+	if rule.ExpirationDays > 0 {
+		preview.Expire = *older[0]
+	}
+	if rule.TransitionDays > 0 {
+		transition := *older[len(older)-1]
+		// 同时满足过期条件的对象直接过期，不计入转换
+		transition.Objects -= preview.Expire.Objects
+		transition.Bytes -= preview.Expire.Bytes
+		preview.Transition = transition
+	}
+
+	if preview.Estimated {
+		scale := func(summary *models.ObjectSummary) {
+			summary.Objects = int64(math.Round(float64(summary.Objects) * 100 / samplePercent))
+			summary.Bytes = int64(math.Round(float64(summary.Bytes) * 100 / samplePercent))
+		}
+		scale(&preview.Matched)
+		scale(&preview.Expire)
+		scale(&preview.Transition)
+	}
+
+	// 示例：最早受影响的对象
+	filter := rule.Filter()
+	filter.ModifiedBefore = &cutoffs[0]
+	affected, err := s.repo.ListFiltered(ctx, filter, examples, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list affected objects: %w", err)
+	}
+	preview.Examples = make([]string, 0, len(affected))
+	for _, metadata := range affected {
+		preview.Examples = append(preview.Examples, metadata.Key)
+	}
+
+	return preview, nil
+}
+
 // HealthCheck 健康检查
 func (s *MetadataService) HealthCheck(ctx context.Context) error {
 	s.logger.Debug(ctx, "Performing health check")
@@ -264,6 +1091,20 @@ func (s *MetadataService) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// indexMetadata 更新搜索索引，失败不影响主流程
+func (s *MetadataService) indexMetadata(ctx context.Context, metadata *models.Metadata) {
+	if s.searchIndex == nil {
+		return
+	}
+
+	if err := s.searchIndex.Index(ctx, metadata); err != nil {
+		s.logger.Warn(ctx, "Failed to index metadata",
+			observability.String("bucket", metadata.Bucket),
+			observability.String("key", metadata.Key),
+			observability.String("error", err.Error()))
+	}
+}
+
 // validateMetadata 验证元数据
 func (s *MetadataService) validateMetadata(metadata *models.Metadata) error {
 	if metadata == nil {
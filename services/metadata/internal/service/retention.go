@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"strings"
+)
+
+// GetBucketRetention 获取bucket的保留策略
+func (s *MetadataService) GetBucketRetention(ctx context.Context, bucket string) (*models.BucketRetention, error) {
+	if strings.TrimSpace(bucket) == "" {
+		return nil, fmt.Errorf("%w: bucket cannot be empty", models.ErrInvalidArgument)
+	}
+
+	retention, err := s.repo.GetBucketRetention(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if retention == nil {
+		return nil, fmt.Errorf("%w: %s", models.ErrBucketRetentionNotFound, bucket)
+	}
+	return retention, nil
+}
+
+// SetBucketRetention 设置bucket的最短保留天数，只影响之后的删除请求
+func (s *MetadataService) SetBucketRetention(ctx context.Context, retention *models.BucketRetention) error {
+	if len(retention.Bucket) < 3 || len(retention.Bucket) > 63 {
+		return fmt.Errorf("%w: bucket name must be between 3 and 63 characters", models.ErrInvalidArgument)
+	}
+	if retention.MinRetentionDays <= 0 || retention.MinRetentionDays > models.MaxRetentionDays {
+		return fmt.Errorf("%w: min_retention_days must be between 1 and %d", models.ErrInvalidArgument, models.MaxRetentionDays)
+	}
+
+	if err := s.repo.PutBucketRetention(ctx, retention); err != nil {
+		s.logger.Error(ctx, "Failed to set bucket retention",
+			observability.String("bucket", retention.Bucket),
+			observability.String("error", err.Error()))
+		return err
+	}
+
+	s.logger.Info(ctx, "Bucket retention set",
+		observability.String("bucket", retention.Bucket),
+		observability.Int("min_retention_days", retention.MinRetentionDays))
+	return nil
+}
+
+// DeleteBucketRetention 删除bucket的保留策略
+func (s *MetadataService) DeleteBucketRetention(ctx context.Context, bucket string) error {
+	if strings.TrimSpace(bucket) == "" {
+		return fmt.Errorf("%w: bucket cannot be empty", models.ErrInvalidArgument)
+	}
+
+	deleted, err := s.repo.DeleteBucketRetention(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: %s", models.ErrBucketRetentionNotFound, bucket)
+	}
+
+	s.logger.Info(ctx, "Bucket retention removed", observability.String("bucket", bucket))
+	return nil
+}
+
+// ListBucketRetention 列出所有bucket的保留策略
+func (s *MetadataService) ListBucketRetention(ctx context.Context) ([]*models.BucketRetention, error) {
+	return s.repo.ListBucketRetention(ctx)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"strings"
+)
+
+// GetBucketSchema 获取bucket注册的schema
+func (s *MetadataService) GetBucketSchema(ctx context.Context, bucket string) (*models.BucketSchema, error) {
+	if strings.TrimSpace(bucket) == "" {
+		return nil, fmt.Errorf("%w: bucket cannot be empty", models.ErrInvalidArgument)
+	}
+
+	schema, err := s.repo.GetBucketSchema(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("%w: %s", models.ErrBucketSchemaNotFound, bucket)
+	}
+	return schema, nil
+}
+
+// SetBucketSchema 注册bucket的schema，只影响之后的写入，已有元数据不回溯校验
+func (s *MetadataService) SetBucketSchema(ctx context.Context, schema *models.BucketSchema) error {
+	if len(schema.Bucket) < 3 || len(schema.Bucket) > 63 {
+		return fmt.Errorf("%w: bucket name must be between 3 and 63 characters", models.ErrInvalidArgument)
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, schema.Schema); err != nil {
+		return fmt.Errorf("%w: invalid schema: %v", models.ErrInvalidArgument, err)
+	}
+	if compacted.Len() > models.MaxBucketSchemaSize {
+		return fmt.Errorf("%w: schema exceeds %d bytes", models.ErrInvalidArgument, models.MaxBucketSchemaSize)
+	}
+	if _, err := s.schemas.Get(compacted.Bytes()); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+	}
+	schema.Schema = compacted.Bytes()
+
+	if err := s.repo.PutBucketSchema(ctx, schema); err != nil {
+		s.logger.Error(ctx, "Failed to set bucket schema",
+			observability.String("bucket", schema.Bucket),
+			observability.String("error", err.Error()))
+		return err
+	}
+
+	s.logger.Info(ctx, "Bucket schema set",
+		observability.String("bucket", schema.Bucket),
+		observability.Int("size", len(schema.Schema)))
+	return nil
+}
+
+// DeleteBucketSchema 删除bucket的schema
+func (s *MetadataService) DeleteBucketSchema(ctx context.Context, bucket string) error {
+	if strings.TrimSpace(bucket) == "" {
+		return fmt.Errorf("%w: bucket cannot be empty", models.ErrInvalidArgument)
+	}
+
+	deleted, err := s.repo.DeleteBucketSchema(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: %s", models.ErrBucketSchemaNotFound, bucket)
+	}
+
+	s.logger.Info(ctx, "Bucket schema removed", observability.String("bucket", bucket))
+	return nil
+}
+
+// ListBucketSchemas 列出所有bucket的schema
+func (s *MetadataService) ListBucketSchemas(ctx context.Context) ([]*models.BucketSchema, error) {
+	return s.repo.ListBucketSchemas(ctx)
+}
+
+// ValidateMetadataSchema 按bucket注册的schema校验自定义元数据（不写入），供写入存储前预检
+func (s *MetadataService) ValidateMetadataSchema(ctx context.Context, metadata *models.Metadata) error {
+	if metadata == nil || strings.TrimSpace(metadata.Bucket) == "" {
+		return fmt.Errorf("%w: bucket cannot be empty", models.ErrInvalidArgument)
+	}
+	return s.validateAgainstSchema(ctx, metadata)
+}
+
+// validateAgainstSchema 按bucket注册的schema校验自定义元数据，不符合时返回*models.SchemaValidationError
+func (s *MetadataService) validateAgainstSchema(ctx context.Context, metadata *models.Metadata) error {
+	schema, err := s.repo.GetBucketSchema(ctx, metadata.Bucket)
+	if err != nil {
+		return fmt.Errorf("failed to load bucket schema: %w", err)
+	}
+	if schema == nil {
+		return nil
+	}
+
+	compiled, err := s.schemas.Get(schema.Schema)
+	if err != nil {
+		return fmt.Errorf("failed to compile bucket schema: %w", err)
+	}
+	violations := compiled.Validate(models.SchemaDocument(metadata))
+	if len(violations) == 0 {
+		return nil
+	}
+
+	validationErr := &models.SchemaValidationError{
+		Bucket:     metadata.Bucket,
+		Key:        metadata.Key,
+		Violations: make([]models.SchemaViolation, 0, len(violations)),
+	}
+	for _, violation := range violations {
+		validationErr.Violations = append(validationErr.Violations, models.SchemaViolation{
+			Path:    violation.Path,
+			Keyword: violation.Keyword,
+			Message: violation.Message,
+		})
+	}
+	s.logger.Warn(ctx, "Metadata rejected by bucket schema",
+		observability.String("bucket", metadata.Bucket),
+		observability.String("key", metadata.Key),
+		observability.Int("violations", len(violations)))
+	return validationErr
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"time"
+)
+
+// statsSnapshot 后台汇总的各租户统计
+type statsSnapshot struct {
+	tenants   map[string]*models.Stats
+	updatedAt time.Time
+}
+
+// forTenant 获取租户的统计，汇总时没有任何记录的租户返回空统计
+func (s *statsSnapshot) forTenant(tenant string) *models.Stats {
+	if stats, ok := s.tenants[tenant]; ok {
+		return stats
+	}
+	return &models.Stats{
+		BucketStats:  make(map[string]int64),
+		ContentTypes: make(map[string]int64),
+		Buckets:      make(map[string]*models.BucketRollup),
+		LastUpdated:  s.updatedAt,
+		Source:       models.StatsSourceRollup,
+	}
+}
+
+// StartStatsAggregator 启动后台统计汇总任务，interval<=0时GetStats每次实时计算
+//
+// 启动时先加载上次保存的快照，使重启后立即可用（超过两个周期未刷新时标记为stale），
+// 随后立即汇总一次并按interval定时刷新。
+func (s *MetadataService) StartStatsAggregator(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	s.statsInterval = interval
+
+	if tenants, err := s.repo.LoadStatsSnapshot(ctx); err != nil {
+		s.logger.Warn(ctx, "Failed to load stats snapshot",
+			observability.String("error", err.Error()))
+	} else if tenants != nil {
+		snapshot := &statsSnapshot{tenants: tenants}
+		for _, stats := range tenants {
+			if stats.LastUpdated.After(snapshot.updatedAt) {
+				snapshot.updatedAt = stats.LastUpdated
+			}
+		}
+		s.statsSnapshot.Store(snapshot)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.RefreshStats(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Warn(ctx, "Stats aggregation failed",
+					observability.String("error", err.Error()))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RefreshStats 立即汇总所有租户的统计并保存快照，返回ctx中租户的统计
+func (s *MetadataService) RefreshStats(ctx context.Context) (*models.Stats, error) {
+	start := time.Now()
+	tenants, err := s.repo.ListTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate statistics: %w", err)
+	}
+
+	snapshot := &statsSnapshot{
+		tenants:   make(map[string]*models.Stats, len(tenants)),
+		updatedAt: time.Now(),
+	}
+	var totalObjects int64
+	for _, tenant := range tenants {
+		stats, err := s.repo.GetStats(models.WithTenant(ctx, tenant.Tenant))
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate statistics for tenant %s: %w", tenant.Tenant, err)
+		}
+		stats.Source = models.StatsSourceRollup
+		snapshot.tenants[tenant.Tenant] = stats
+		totalObjects += stats.TotalObjects
+	}
+	s.statsSnapshot.Store(snapshot)
+
+	// 快照保存失败不影响本实例使用内存中的结果
+	if err := s.repo.SaveStatsSnapshot(ctx, snapshot.tenants); err != nil {
+		s.logger.Warn(ctx, "Failed to save stats snapshot",
+			observability.String("error", err.Error()))
+	}
+
+	s.logger.Debug(ctx, "Statistics aggregated",
+		observability.Int64("total_objects", totalObjects),
+		observability.Int("tenants", len(tenants)),
+		observability.Duration("duration", time.Since(start)))
+	return s.withStatsFreshness(snapshot.forTenant(models.TenantFromContext(ctx))), nil
+}
+
+// withStatsFreshness 返回带新鲜度信息的快照副本
+func (s *MetadataService) withStatsFreshness(snapshot *models.Stats) *models.Stats {
+	stats := *snapshot
+	age := time.Since(stats.LastUpdated)
+	stats.AgeSeconds = age.Seconds()
+	stats.Stale = s.statsInterval > 0 && age > 2*s.statsInterval
+	if s.statsInterval > 0 {
+		next := stats.LastUpdated.Add(s.statsInterval)
+		stats.NextRefreshAt = &next
+	}
+	return &stats
+}
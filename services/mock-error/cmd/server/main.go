@@ -2,14 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"mocks3/services/mock-error/internal/config"
 	"mocks3/services/mock-error/internal/handler"
 	"mocks3/services/mock-error/internal/repository"
 	"mocks3/services/mock-error/internal/service"
+	"mocks3/shared/client"
 	"mocks3/shared/middleware"
 	"mocks3/shared/models"
 	"mocks3/shared/observability"
+	"mocks3/shared/utils"
 	"net/http"
 	"os"
 	"os/signal"
@@ -35,6 +38,15 @@ func main() {
 		Environment:    cfg.Server.Environment,
 		OTLPEndpoint:   "http://localhost:4318",
 		LogLevel:       cfg.LogLevel,
+		Debug:          observability.DebugConfigFromEnv(),
+		Profiling:      observability.ProfilingConfigFromEnv(),
+		AuditLog:       observability.AuditLogConfigFromEnv(),
+		LogSampling:    observability.LogSamplingConfigFromEnv(),
+		LogFile:        observability.LogFileConfigFromEnv(),
+		SLOs: []observability.SLO{
+			observability.AvailabilitySLO("availability", 0.999),
+			observability.LatencySLO("latency", 0.99, 100*time.Millisecond),
+		},
 	}
 
 	obs, err := observability.New(context.Background(), obsConfig)
@@ -45,6 +57,13 @@ func main() {
 
 	logger := obs.Logger()
 
+	// 初始化全局ID生成器
+	idStrategy, err := utils.ConfigureIDGeneratorFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure ID generator: %v", err)
+	}
+	logger.Info(context.Background(), "ID generator configured", observability.String("strategy", idStrategy))
+
 	// 初始化Consul管理器
 	var consulManager *middleware.ConsulManager
 	if cfg.Consul.Enabled {
@@ -54,16 +73,63 @@ func main() {
 		}
 	}
 
-	// 初始化仓库
+	// 初始化仓库，配置了规则存储时规则、触发次数和变更审计在重启后保留
 	ruleRepo := repository.NewRuleRepository()
+	var auditStore repository.RuleAuditStore
+	if cfg.RuleStore.Persistent() {
+		ruleStore, err := repository.NewSQLRuleStore(cfg.RuleStore.Driver, cfg.RuleStore.DSN)
+		if err != nil {
+			log.Fatalf("Failed to open rule store: %v", err)
+		}
+		defer ruleStore.Close()
+		ruleRepo = repository.NewPersistentRuleRepository(ruleStore)
+		auditStore = ruleStore
+	}
 	statsRepo := repository.NewStatsRepository(10000, cfg.ErrorEngine.StatRetentionHours)
 
 	// 初始化规则引擎
 	ruleEngine := service.NewRuleEngine(logger)
+	ruleEngine.SetSchedulingEnabled(cfg.ErrorEngine.EnableScheduling)
+	ruleEngine.SetRandomSeed(cfg.ErrorEngine.RandomSeed)
 
 	// 初始化错误注入服务
 	errorService := service.NewErrorInjectorService(cfg, ruleRepo, statsRepo, ruleEngine, logger)
 
+	errorService.SetRuleAuditRepository(repository.NewRuleAuditRepository(auditStore, 0))
+	errorService.SetMetricCollector(obs.Collector())
+	errorService.SetAuditLogger(obs.AuditLogger())
+
+	if consulManager != nil {
+		errorService.SetServiceChecker(consulManager)
+	}
+
+	// executor为toxiproxy的规则在Toxiproxy中创建toxic
+	if cfg.Toxiproxy.Enabled {
+		errorService.SetToxiproxy(client.NewToxiproxyClient(cfg.Toxiproxy.URL, cfg.Toxiproxy.GetTimeout()), cfg.Toxiproxy.Proxies)
+	}
+
+	loadedRules, err := errorService.LoadRules(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to load persisted rules: %v", err)
+	}
+	if cfg.RuleStore.Persistent() {
+		logger.Info(context.Background(), "Rules loaded from rule store",
+			observability.String("driver", cfg.RuleStore.Driver),
+			observability.Int("count", loadedRules))
+	}
+	// 熔断开关与规则一起保存，重启后保持开启
+	if err := errorService.LoadKillSwitch(context.Background()); err != nil {
+		log.Fatalf("Failed to load kill switch: %v", err)
+	}
+
+	// 后台检测过期规则、同步Toxiproxy、检查规则TTL、同步其他实例的熔断开关
+	checkerCtx, stopChecker := context.WithCancel(context.Background())
+	defer stopChecker()
+	errorService.StartStaleRuleChecker(checkerCtx)
+	errorService.StartToxiproxySync(checkerCtx)
+	errorService.StartSafetyGuard(checkerCtx)
+	errorService.StartKillSwitchSync(checkerCtx)
+
 	// 初始化处理器
 	errorHandler := handler.NewErrorHandler(errorService, logger)
 
@@ -99,9 +165,28 @@ func main() {
 	// 添加中间件
 	router.Use(gin.Logger())
 	router.Use(middleware.GinRecoveryMiddleware(middleware.DefaultRecoveryConfig()))
+	// 请求ID：沿用或生成X-Request-ID，日志和出站调用自动带上
+	router.Use(middleware.RequestIDMiddleware())
+	// baggage：读取上游透传的租户、测试运行ID和场景ID，日志和错误注入条件自动带上
+	router.Use(obs.GinBaggageMiddleware())
 	// 使用统一可观测性中间件
 	router.Use(obs.GinMiddleware())
 
+	// 管理接口的修改操作写入审计日志
+	router.Use(obs.AuditLogger().GinAdminMiddleware("/api/v1/admin"))
+
+	// 规则管理鉴权（注入检查接口不鉴权）和飞行记录器
+	authorizer, flightRecorder, err := setupAdmin(router, cfg, obs, client.NewStorageClient(cfg.FlightRecorder.StorageURL, 10*time.Second))
+	if err != nil {
+		log.Fatalf("Failed to set up admin endpoints: %v", err)
+	}
+
+	// 解析操作者，记录在规则变更审计中
+	router.Use(middleware.AuditActorMiddleware(authorizer))
+
+	// 运行时修改日志级别，排查问题时临时切换到debug
+	middleware.NewLogLevelController(logger).RegisterAdminRoutes(router.Group("/api/v1/admin"))
+
 	// 设置路由
 	errorHandler.RegisterRoutes(router)
 
@@ -126,10 +211,17 @@ func main() {
 				"enable_statistics":      cfg.ErrorEngine.EnableStatistics,
 				"enable_scheduling":      cfg.ErrorEngine.EnableScheduling,
 				"global_probability":     cfg.Injection.GlobalProbability,
+				"dry_run":                errorService.IsDryRun(),
+				"random_seed":            errorService.RandomSeed(),
 				"enable_http_errors":     cfg.Injection.EnableHTTPErrors,
 				"enable_network_errors":  cfg.Injection.EnableNetworkErrors,
 				"enable_database_errors": cfg.Injection.EnableDatabaseErrors,
 				"enable_storage_errors":  cfg.Injection.EnableStorageErrors,
+				"toxiproxy_enabled":      cfg.Toxiproxy.Enabled,
+				"kill_switch":            errorService.KillSwitch().Engaged,
+				"max_active_rules":       cfg.Safety.MaxActiveRules,
+				"max_probability":        cfg.Safety.MaxProbability,
+				"rule_ttl_mins":          cfg.Safety.RuleTTLMins,
 			},
 		})
 	})
@@ -143,8 +235,8 @@ func main() {
 		observability.Bool("enable_statistics", cfg.ErrorEngine.EnableStatistics),
 		observability.Float64("global_probability", cfg.Injection.GlobalProbability))
 
-	// 添加一些示例规则（仅在开发环境）
-	if cfg.Server.Environment == "development" {
+	// 添加一些示例规则（仅在开发环境，已从存储加载规则时不再添加）
+	if cfg.Server.Environment == "development" && loadedRules == 0 {
 		addSampleRules(ctx, errorService, logger)
 	}
 
@@ -177,13 +269,80 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// 停止运行中的实验，禁用其规则
+	errorService.StopExperiments(models.WithAuditActor(ctx, models.AuditActor{Name: "shutdown"}))
+
+	// 结束事件流长连接，避免阻塞关闭
+	errorService.CloseEventStreams()
+
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// 停止后台同步并删除创建的toxic，避免故障在服务停止后残留
+	stopChecker()
+	if err := errorService.ClearToxics(ctx); err != nil {
+		logger.Warn(ctx, "Failed to clear toxics",
+			observability.String("error", err.Error()))
+	}
+
+	// 等待进行中的事件包上传完成
+	if flightRecorder != nil {
+		flightRecorder.Wait(ctx)
+	}
+
 	logger.Info(context.Background(), "Mock error service stopped")
 }
 
+// setupAdmin 安装管理接口鉴权和飞行记录器，未启用的功能返回nil；
+// gin在注册路由时固定处理链，鉴权中间件必须先于任何管理接口注册，否则这些接口不经过鉴权
+func setupAdmin(router *gin.Engine, cfg *config.Config, obs *observability.Observability, store middleware.IncidentStore) (*middleware.Authorizer, *middleware.FlightRecorder, error) {
+	var authorizer *middleware.Authorizer
+	if cfg.RBAC.Enabled {
+		var err error
+		authorizer, err = newAuthorizer(cfg.RBAC)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize authorizer: %w", err)
+		}
+		router.Use(authorizer.GinMiddleware())
+		authorizer.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	// 飞行记录器：保留最近的请求、日志和span，SLO违规或护栏触发时转储事件包到存储bucket
+	var flightRecorder *middleware.FlightRecorder
+	if cfg.FlightRecorder.Enabled {
+		var err error
+		flightRecorder, err = newFlightRecorder(cfg.FlightRecorder, store, obs.Logger())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize flight recorder: %w", err)
+		}
+		flightRecorder.SetMetricCollector(obs.Collector())
+		flightRecorder.Attach(obs)
+		router.Use(obs.GinTracingMiddleware())
+		router.Use(flightRecorder.GinMiddleware())
+		flightRecorder.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	return authorizer, flightRecorder, nil
+}
+
+// newAuthorizer 根据配置创建鉴权器：规则、统计、事件、试运行开关、随机种子、实验和Toxiproxy同步的查看需要rules:read，变更需要rules:write
+func newAuthorizer(cfg config.RBACConfig) (*middleware.Authorizer, error) {
+	rbacConfig := middleware.DefaultRBACConfig()
+	rbacConfig.Header = cfg.Header
+	for _, prefix := range []string{"/api/v1/rules", "/api/v1/stats", "/api/v1/events", "/api/v1/dry-run", "/api/v1/random-seed", "/api/v1/experiments", "/api/v1/toxiproxy"} {
+		rbacConfig.Rules = append(rbacConfig.Rules, &middleware.RBACRule{
+			PathPrefix:      prefix,
+			ReadCapability:  models.CapabilityRulesRead,
+			WriteCapability: models.CapabilityRulesWrite,
+		})
+	}
+	for _, key := range cfg.Keys {
+		rbacConfig.Keys = append(rbacConfig.Keys, &models.APIKey{Name: key.Name, Key: key.Key, Role: key.Role})
+	}
+	return middleware.NewAuthorizer(rbacConfig)
+}
+
 // addSampleRules 添加示例规则
 func addSampleRules(ctx context.Context, service *service.ErrorInjectorService, logger *observability.Logger) {
 	logger.Info(context.Background(), "Adding sample error injection rules for development")
@@ -268,3 +427,19 @@ func addSampleRules(ctx context.Context, service *service.ErrorInjectorService,
 		}
 	}
 }
+
+// newFlightRecorder 根据配置创建飞行记录器
+func newFlightRecorder(cfg config.FlightRecorderConfig, store middleware.IncidentStore, logger *observability.Logger) (*middleware.FlightRecorder, error) {
+	recorderConfig := middleware.DefaultFlightRecorderConfig("mock-error-service")
+	recorderConfig.Bucket = cfg.Bucket
+	recorderConfig.RequestCapacity = cfg.RequestCapacity
+	recorderConfig.LogCapacity = cfg.LogCapacity
+	recorderConfig.SpanCapacity = cfg.SpanCapacity
+	recorderConfig.SLOWindow = time.Duration(cfg.SLOWindowSecs) * time.Second
+	recorderConfig.MinRequests = cfg.MinRequests
+	recorderConfig.MaxErrorRate = cfg.MaxErrorRate
+	recorderConfig.LatencyThreshold = time.Duration(cfg.LatencyThresholdMs) * time.Millisecond
+	recorderConfig.MaxSlowRate = cfg.MaxSlowRate
+	recorderConfig.Cooldown = time.Duration(cfg.CooldownSecs) * time.Second
+	return middleware.NewFlightRecorder(recorderConfig, store, logger)
+}
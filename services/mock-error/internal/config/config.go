@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"mocks3/shared/models"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // ServerConfig 服务器配置
@@ -32,6 +35,14 @@ type ErrorEngineConfig struct {
 	DefaultProbability float64 `json:"default_probability"`
 	EnableStatistics   bool    `json:"enable_statistics"`
 	StatRetentionHours int     `json:"stat_retention_hours"`
+
+	// 过期规则检测
+	StaleRuleDays          int  `json:"stale_rule_days"`
+	StaleCheckIntervalMins int  `json:"stale_check_interval_mins"`
+	AutoDisableStaleRules  bool `json:"auto_disable_stale_rules"`
+
+	// 全局随机种子，为nil时概率判定不可复现
+	RandomSeed *int64 `json:"random_seed,omitempty"`
 }
 
 // InjectionConfig 注入配置
@@ -42,15 +53,114 @@ type InjectionConfig struct {
 	EnableDatabaseErrors bool    `json:"enable_database_errors"`
 	EnableStorageErrors  bool    `json:"enable_storage_errors"`
 	GlobalProbability    float64 `json:"global_probability"`
+	DryRun               bool    `json:"dry_run"`                // 全局试运行，所有规则只记录匹配不执行动作
+	MaxPressureMs        int     `json:"max_pressure_ms"`        // 资源压力动作的最长持续时间
+	MaxPressureMemoryMB  int     `json:"max_pressure_memory_mb"` // 内存压力动作最多分配的内存
+}
+
+// SafetyConfig 混沌注入的安全限制，避免遗忘或配置错误的规则长期影响环境，0表示不限制
+type SafetyConfig struct {
+	MaxActiveRules int     `json:"max_active_rules"` // 同时启用的规则数上限
+	MaxProbability float64 `json:"max_probability"`  // 命中规则的请求中实际注入的比例上限，与全局触发概率取较小值
+	RuleTTLMins    int     `json:"rule_ttl_mins"`    // 规则启用或最近修改后超过该时间自动禁用
+}
+
+// GetRuleTTL 获取规则自动禁用前的最长生效时间
+func (s *SafetyConfig) GetRuleTTL() time.Duration {
+	return time.Duration(s.RuleTTLMins) * time.Minute
+}
+
+// RBACConfig 规则管理与管理接口鉴权配置
+type RBACConfig struct {
+	Enabled bool           `json:"enabled"`
+	Header  string         `json:"header"`
+	Keys    []APIKeyConfig `json:"keys"`
+}
+
+// APIKeyConfig 初始API key
+type APIKeyConfig struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	Role string `json:"role"` // viewer, operator, chaos-admin, super-admin
+}
+
+// FlightRecorderConfig 飞行记录器配置：保留最近的请求、日志和span，SLO违规或护栏触发时转储到存储bucket
+type FlightRecorderConfig struct {
+	Enabled            bool    `json:"enabled"`
+	StorageURL         string  `json:"storage_url"`
+	Bucket             string  `json:"bucket"`
+	RequestCapacity    int     `json:"request_capacity"`
+	LogCapacity        int     `json:"log_capacity"`
+	SpanCapacity       int     `json:"span_capacity"`
+	SLOWindowSecs      int     `json:"slo_window_secs"`
+	MinRequests        int     `json:"min_requests"`   // 窗口内请求数少于该值时不判定SLO
+	MaxErrorRate       float64 `json:"max_error_rate"` // 5xx比例阈值，0表示不检查
+	LatencyThresholdMs int     `json:"latency_threshold_ms"`
+	MaxSlowRate        float64 `json:"max_slow_rate"` // 慢请求比例阈值，0表示不检查
+	CooldownSecs       int     `json:"cooldown_secs"` // 两次自动抓取的最小间隔
+}
+
+// WebhookConfig 规则触发通知的投递配置
+type WebhookConfig struct {
+	MaxAttempts  int `json:"max_attempts"`   // 最多投递次数（含首次）
+	BackoffMs    int `json:"backoff_ms"`     // 第一次重试前的等待时间，之后每次翻倍
+	MaxBackoffMs int `json:"max_backoff_ms"` // 等待时间上限
+	TimeoutMs    int `json:"timeout_ms"`     // 单次请求超时
+}
+
+// GetBackoff 获取第一次重试前的等待时间
+func (w *WebhookConfig) GetBackoff() time.Duration {
+	return time.Duration(w.BackoffMs) * time.Millisecond
+}
+
+// GetMaxBackoff 获取等待时间上限
+func (w *WebhookConfig) GetMaxBackoff() time.Duration {
+	return time.Duration(w.MaxBackoffMs) * time.Millisecond
+}
+
+// GetTimeout 获取单次请求超时
+func (w *WebhookConfig) GetTimeout() time.Duration {
+	return time.Duration(w.TimeoutMs) * time.Millisecond
+}
+
+// ToxiproxyConfig Toxiproxy执行器配置，开启后executor为toxiproxy的规则在Toxiproxy中创建toxic
+type ToxiproxyConfig struct {
+	Enabled          bool              `json:"enabled"`
+	URL              string            `json:"url"`                // 管理接口地址
+	Proxies          map[string]string `json:"proxies"`            // 服务名到代理名的映射，未列出的服务使用同名代理
+	SyncIntervalSecs int               `json:"sync_interval_secs"` // 后台同步间隔，使调度窗口和外部改动生效
+	TimeoutMs        int               `json:"timeout_ms"`         // 单次请求超时
+}
+
+// GetTimeout 获取单次请求超时
+func (t *ToxiproxyConfig) GetTimeout() time.Duration {
+	return time.Duration(t.TimeoutMs) * time.Millisecond
+}
+
+// RuleStoreConfig 规则持久化配置，driver为空或memory时规则只保存在内存中
+type RuleStoreConfig struct {
+	Driver string `json:"driver"` // memory, postgres, sqlite3
+	DSN    string `json:"dsn"`
+}
+
+// Persistent 是否持久化规则
+func (r *RuleStoreConfig) Persistent() bool {
+	return r.Driver != "" && r.Driver != "memory"
 }
 
 // Config 应用配置
 type Config struct {
-	Server      ServerConfig      `json:"server"`
-	Consul      ConsulConfig      `json:"consul"`
-	ErrorEngine ErrorEngineConfig `json:"error_engine"`
-	Injection   InjectionConfig   `json:"injection"`
-	LogLevel    string            `json:"log_level"`
+	Server         ServerConfig         `json:"server"`
+	Consul         ConsulConfig         `json:"consul"`
+	ErrorEngine    ErrorEngineConfig    `json:"error_engine"`
+	Injection      InjectionConfig      `json:"injection"`
+	Safety         SafetyConfig         `json:"safety"`
+	RuleStore      RuleStoreConfig      `json:"rule_store"`
+	Webhook        WebhookConfig        `json:"webhook"`
+	Toxiproxy      ToxiproxyConfig      `json:"toxiproxy"`
+	RBAC           RBACConfig           `json:"rbac"`
+	FlightRecorder FlightRecorderConfig `json:"flight_recorder"`
+	LogLevel       string               `json:"log_level"`
 }
 
 // Load 加载配置
@@ -72,6 +182,12 @@ func Load() *Config {
 			DefaultProbability: getEnvAsFloat("ERROR_DEFAULT_PROBABILITY", 0.1),
 			EnableStatistics:   getEnvAsBool("ERROR_ENABLE_STATISTICS", true),
 			StatRetentionHours: getEnvAsInt("ERROR_STAT_RETENTION_HOURS", 24),
+
+			StaleRuleDays:          getEnvAsInt("ERROR_STALE_RULE_DAYS", 7),
+			StaleCheckIntervalMins: getEnvAsInt("ERROR_STALE_CHECK_INTERVAL_MINS", 60),
+			AutoDisableStaleRules:  getEnvAsBool("ERROR_AUTO_DISABLE_STALE_RULES", false),
+
+			RandomSeed: getEnvAsOptionalInt64("ERROR_RANDOM_SEED"),
 		},
 		Injection: InjectionConfig{
 			MaxDelayMs:           getEnvAsInt("INJECTION_MAX_DELAY_MS", 10000),
@@ -80,6 +196,50 @@ func Load() *Config {
 			EnableDatabaseErrors: getEnvAsBool("INJECTION_ENABLE_DATABASE_ERRORS", true),
 			EnableStorageErrors:  getEnvAsBool("INJECTION_ENABLE_STORAGE_ERRORS", true),
 			GlobalProbability:    getEnvAsFloat("INJECTION_GLOBAL_PROBABILITY", 1.0),
+			DryRun:               getEnvAsBool("INJECTION_DRY_RUN", false),
+			MaxPressureMs:        getEnvAsInt("INJECTION_MAX_PRESSURE_MS", 60000),
+			MaxPressureMemoryMB:  getEnvAsInt("INJECTION_MAX_PRESSURE_MEMORY_MB", 512),
+		},
+		Safety: SafetyConfig{
+			MaxActiveRules: getEnvAsInt("SAFETY_MAX_ACTIVE_RULES", 0),
+			MaxProbability: getEnvAsFloat("SAFETY_MAX_PROBABILITY", 1.0),
+			RuleTTLMins:    getEnvAsInt("SAFETY_RULE_TTL_MINS", 0),
+		},
+		RuleStore: RuleStoreConfig{
+			Driver: getEnv("RULE_STORE_DRIVER", "memory"),
+			DSN:    getEnv("RULE_STORE_DSN", "mock-error-rules.db"),
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:  getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 3),
+			BackoffMs:    getEnvAsInt("WEBHOOK_BACKOFF_MS", 1000),
+			MaxBackoffMs: getEnvAsInt("WEBHOOK_MAX_BACKOFF_MS", 10000),
+			TimeoutMs:    getEnvAsInt("WEBHOOK_TIMEOUT_MS", 5000),
+		},
+		Toxiproxy: ToxiproxyConfig{
+			Enabled:          getEnvAsBool("TOXIPROXY_ENABLED", false),
+			URL:              getEnv("TOXIPROXY_URL", "http://localhost:8474"),
+			Proxies:          getEnvAsMap("TOXIPROXY_PROXIES"),
+			SyncIntervalSecs: getEnvAsInt("TOXIPROXY_SYNC_INTERVAL_SECS", 10),
+			TimeoutMs:        getEnvAsInt("TOXIPROXY_TIMEOUT_MS", 2000),
+		},
+		RBAC: RBACConfig{
+			Enabled: getEnvAsBool("RBAC_ENABLED", false),
+			Header:  getEnv("RBAC_HEADER", "X-Api-Key"),
+			Keys:    getEnvAsAPIKeys("RBAC_API_KEYS"),
+		},
+		FlightRecorder: FlightRecorderConfig{
+			Enabled:            getEnvAsBool("FLIGHT_RECORDER_ENABLED", false),
+			StorageURL:         getEnv("FLIGHT_RECORDER_STORAGE_URL", "http://localhost:8082"),
+			Bucket:             getEnv("FLIGHT_RECORDER_BUCKET", models.DefaultIncidentBucket),
+			RequestCapacity:    getEnvAsInt("FLIGHT_RECORDER_REQUEST_CAPACITY", 200),
+			LogCapacity:        getEnvAsInt("FLIGHT_RECORDER_LOG_CAPACITY", 500),
+			SpanCapacity:       getEnvAsInt("FLIGHT_RECORDER_SPAN_CAPACITY", 500),
+			SLOWindowSecs:      getEnvAsInt("FLIGHT_RECORDER_SLO_WINDOW_SECS", 60),
+			MinRequests:        getEnvAsInt("FLIGHT_RECORDER_MIN_REQUESTS", 20),
+			MaxErrorRate:       getEnvAsFloat("FLIGHT_RECORDER_MAX_ERROR_RATE", 0.1),
+			LatencyThresholdMs: getEnvAsInt("FLIGHT_RECORDER_LATENCY_THRESHOLD_MS", 1000),
+			MaxSlowRate:        getEnvAsFloat("FLIGHT_RECORDER_MAX_SLOW_RATE", 0.2),
+			CooldownSecs:       getEnvAsInt("FLIGHT_RECORDER_COOLDOWN_SECS", 300),
 		},
 		LogLevel: getEnv("LOG_LEVEL", "info"),
 	}
@@ -101,14 +261,75 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("default_probability must be between 0 and 1")
 	}
 
+	if c.ErrorEngine.StaleRuleDays <= 0 {
+		return fmt.Errorf("stale_rule_days must be positive")
+	}
+
 	if c.Injection.MaxDelayMs < 0 {
 		return fmt.Errorf("max_delay_ms must be non-negative")
 	}
 
+	if c.Injection.MaxPressureMs < 0 || c.Injection.MaxPressureMemoryMB < 0 {
+		return fmt.Errorf("max_pressure_ms and max_pressure_memory_mb must be non-negative")
+	}
+
 	if c.Injection.GlobalProbability < 0 || c.Injection.GlobalProbability > 1 {
 		return fmt.Errorf("global_probability must be between 0 and 1")
 	}
 
+	if c.Safety.MaxActiveRules < 0 || c.Safety.RuleTTLMins < 0 {
+		return fmt.Errorf("safety max_active_rules and rule_ttl_mins must be non-negative")
+	}
+
+	if c.Safety.MaxProbability < 0 || c.Safety.MaxProbability > 1 {
+		return fmt.Errorf("safety max_probability must be between 0 and 1")
+	}
+
+	switch c.RuleStore.Driver {
+	case "", "memory":
+	case "postgres", "sqlite3":
+		if c.RuleStore.DSN == "" {
+			return fmt.Errorf("rule store dsn is required for driver %s", c.RuleStore.Driver)
+		}
+	default:
+		return fmt.Errorf("unsupported rule store driver: %s", c.RuleStore.Driver)
+	}
+
+	if c.Webhook.MaxAttempts <= 0 || c.Webhook.TimeoutMs <= 0 || c.Webhook.BackoffMs < 0 || c.Webhook.MaxBackoffMs < c.Webhook.BackoffMs {
+		return fmt.Errorf("invalid webhook delivery config")
+	}
+
+	if c.Toxiproxy.Enabled && (c.Toxiproxy.URL == "" || c.Toxiproxy.SyncIntervalSecs < 0 || c.Toxiproxy.TimeoutMs <= 0) {
+		return fmt.Errorf("invalid toxiproxy config")
+	}
+
+	if c.RBAC.Enabled {
+		if c.RBAC.Header == "" {
+			return fmt.Errorf("rbac header is required")
+		}
+		for _, key := range c.RBAC.Keys {
+			if key.Name == "" || key.Key == "" {
+				return fmt.Errorf("rbac api key name and key are required")
+			}
+			if !models.IsValidRole(key.Role) {
+				return fmt.Errorf("rbac api key %s: unknown role %q", key.Name, key.Role)
+			}
+		}
+	}
+
+	if c.FlightRecorder.Enabled {
+		if c.FlightRecorder.StorageURL == "" {
+			return fmt.Errorf("flight recorder storage URL is required")
+		}
+		if c.FlightRecorder.Bucket == "" {
+			return fmt.Errorf("flight recorder bucket is required")
+		}
+		if c.FlightRecorder.MaxErrorRate < 0 || c.FlightRecorder.MaxErrorRate > 1 ||
+			c.FlightRecorder.MaxSlowRate < 0 || c.FlightRecorder.MaxSlowRate > 1 {
+			return fmt.Errorf("flight recorder rates must be between 0 and 1")
+		}
+	}
+
 	return nil
 }
 
@@ -130,6 +351,16 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsOptionalInt64 获取环境变量并转换为int64，未设置或无法解析时返回nil
+func getEnvAsOptionalInt64(key string) *int64 {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return &intValue
+		}
+	}
+	return nil
+}
+
 // getEnvAsBool 获取环境变量并转换为bool
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -149,3 +380,40 @@ func getEnvAsFloat(key string, defaultValue float64) float64 {
 	}
 	return defaultValue
 }
+
+// getEnvAsMap 获取环境变量并解析为映射，格式为 key=value，多个以逗号分隔
+func getEnvAsMap(key string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	result := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if ok && k != "" && v != "" {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// getEnvAsAPIKeys 获取环境变量并解析为API key列表，格式为 name:key:role，多个以逗号分隔
+func getEnvAsAPIKeys(key string) []APIKeyConfig {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var keys []APIKeyConfig
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 {
+			// 保留格式错误的条目，由Validate报告
+			keys = append(keys, APIKeyConfig{Name: entry})
+			continue
+		}
+		keys = append(keys, APIKeyConfig{Name: parts[0], Key: parts[1], Role: parts[2]})
+	}
+	return keys
+}
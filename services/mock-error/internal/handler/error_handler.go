@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"mocks3/services/mock-error/internal/service"
@@ -36,18 +39,46 @@ func (h *ErrorHandler) RegisterRoutes(router *gin.Engine) {
 		api.PUT("/rules/:id", h.UpdateErrorRule)
 		api.DELETE("/rules/:id", h.RemoveErrorRule)
 		api.GET("/rules", h.ListErrorRules)
+		api.GET("/rules/export", h.ExportRules)
+		api.POST("/rules/import", h.ImportRules)
+		api.GET("/rules/templates", h.ListRuleTemplates)
+		api.POST("/rules/from-template/:name", h.CreateRuleFromTemplate)
 
 		// 错误注入控制
 		api.POST("/inject/:service/:operation", h.CheckErrorInjection)
+		api.GET("/dry-run", h.GetDryRun)
+		api.PUT("/dry-run", h.SetDryRun)
+		api.GET("/random-seed", h.GetRandomSeed)
+		api.PUT("/random-seed", h.SetRandomSeed)
 
 		// 统计信息
 		api.GET("/stats", h.GetErrorStats)
 		api.POST("/stats/reset", h.ResetErrorStats)
 		api.GET("/events", h.GetErrorEvents)
+		api.GET("/events/stream", h.StreamErrorEvents)
 
 		// 规则控制
 		api.POST("/rules/:id/enable", h.EnableRule)
 		api.POST("/rules/:id/disable", h.DisableRule)
+		api.GET("/rules/stale", h.GetStaleRules)
+		api.POST("/rules/stale/disable", h.DisableStaleRules)
+		api.GET("/rules/audit", h.GetRuleAudit)
+		api.POST("/rules/:id/would-trigger", h.WouldTrigger)
+
+		// 混沌实验
+		api.POST("/experiments", h.StartExperiment)
+		api.GET("/experiments", h.ListExperiments)
+		api.GET("/experiments/:id", h.GetExperiment)
+		api.POST("/experiments/:id/stop", h.StopExperiment)
+
+		// 安全控制
+		api.GET("/admin/kill-switch", h.GetKillSwitch)
+		api.POST("/admin/kill-switch", h.EngageKillSwitch)
+		api.DELETE("/admin/kill-switch", h.ReleaseKillSwitch)
+
+		// Toxiproxy执行器
+		api.GET("/toxiproxy", h.GetToxiproxyStatus)
+		api.POST("/toxiproxy/sync", h.SyncToxics)
 	}
 }
 
@@ -55,6 +86,7 @@ func (h *ErrorHandler) RegisterRoutes(router *gin.Engine) {
 type AddErrorRuleRequest struct {
 	Name        string                  `json:"name" binding:"required"`
 	Description string                  `json:"description"`
+	Namespace   string                  `json:"namespace"` // 为空时使用X-Mocks3-Namespace请求头
 	Service     string                  `json:"service"`
 	Operation   string                  `json:"operation"`
 	Conditions  []models.ErrorCondition `json:"conditions"`
@@ -63,6 +95,12 @@ type AddErrorRuleRequest struct {
 	Priority    int                     `json:"priority"`
 	MaxTriggers int                     `json:"max_triggers"`
 	Schedule    *models.ErrorSchedule   `json:"schedule,omitempty"`
+	Ramp        *models.ErrorRamp       `json:"ramp,omitempty"`
+	Burst       *models.ErrorBurst      `json:"burst,omitempty"`
+	Webhooks    []*models.RuleWebhook   `json:"webhooks,omitempty"`
+	DryRun      bool                    `json:"dry_run"`
+	Seed        *int64                  `json:"seed,omitempty"`     // 概率判定的随机种子，为空时使用全局种子
+	Executor    string                  `json:"executor,omitempty"` // inline（默认）或toxiproxy
 	Metadata    map[string]string       `json:"metadata,omitempty"`
 }
 
@@ -81,6 +119,7 @@ func (h *ErrorHandler) AddErrorRule(c *gin.Context) {
 	rule := &models.ErrorRule{
 		Name:        req.Name,
 		Description: req.Description,
+		Namespace:   requestNamespace(c, req.Namespace),
 		Service:     req.Service,
 		Operation:   req.Operation,
 		Conditions:  req.Conditions,
@@ -89,11 +128,21 @@ func (h *ErrorHandler) AddErrorRule(c *gin.Context) {
 		Priority:    req.Priority,
 		MaxTriggers: req.MaxTriggers,
 		Schedule:    req.Schedule,
+		Ramp:        req.Ramp,
+		Burst:       req.Burst,
+		Webhooks:    req.Webhooks,
+		DryRun:      req.DryRun,
+		Seed:        req.Seed,
+		Executor:    req.Executor,
 		Metadata:    req.Metadata,
 		Triggered:   0,
 	}
 
 	if err := h.service.AddErrorRule(c.Request.Context(), rule); err != nil {
+		if errors.Is(err, service.ErrSafetyLimit) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to add error rule", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to add error rule",
@@ -153,6 +202,7 @@ func (h *ErrorHandler) UpdateErrorRule(c *gin.Context) {
 		ID:          ruleID,
 		Name:        req.Name,
 		Description: req.Description,
+		Namespace:   requestNamespace(c, req.Namespace),
 		Service:     req.Service,
 		Operation:   req.Operation,
 		Conditions:  req.Conditions,
@@ -161,10 +211,20 @@ func (h *ErrorHandler) UpdateErrorRule(c *gin.Context) {
 		Priority:    req.Priority,
 		MaxTriggers: req.MaxTriggers,
 		Schedule:    req.Schedule,
+		Ramp:        req.Ramp,
+		Burst:       req.Burst,
+		Webhooks:    req.Webhooks,
+		DryRun:      req.DryRun,
+		Seed:        req.Seed,
+		Executor:    req.Executor,
 		Metadata:    req.Metadata,
 	}
 
 	if err := h.service.UpdateErrorRule(c.Request.Context(), rule); err != nil {
+		if errors.Is(err, service.ErrSafetyLimit) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to update error rule", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update error rule",
@@ -200,9 +260,9 @@ func (h *ErrorHandler) RemoveErrorRule(c *gin.Context) {
 	})
 }
 
-// ListErrorRules 列出错误规则
+// ListErrorRules 列出错误规则，指定命名空间时只列出该命名空间的规则
 func (h *ErrorHandler) ListErrorRules(c *gin.Context) {
-	rules, err := h.service.ListErrorRules(c.Request.Context())
+	rules, err := h.service.ListNamespaceRules(c.Request.Context(), requestNamespace(c, c.Query("namespace")))
 	if err != nil {
 		h.logger.ErrorContext(c.Request.Context(), "Failed to list error rules", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -217,17 +277,110 @@ func (h *ErrorHandler) ListErrorRules(c *gin.Context) {
 	})
 }
 
+// maxRuleSetSize 导入规则集的最大字节数
+const maxRuleSetSize = 4 << 20
+
+// ExportRules 导出规则为YAML，指定命名空间时只导出该命名空间的规则
+func (h *ErrorHandler) ExportRules(c *gin.Context) {
+	data, err := h.service.ExportRules(c.Request.Context(), requestNamespace(c, c.Query("namespace")))
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to export error rules", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to export error rules",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="error-rules.yaml"`)
+	c.Data(http.StatusOK, "application/x-yaml; charset=utf-8", data)
+}
+
+// ImportRules 从请求体中的YAML导入规则集，mode参数为merge（默认）或replace，指定命名空间时只导入到该命名空间
+func (h *ErrorHandler) ImportRules(c *gin.Context) {
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRuleSetSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.service.ImportRules(c.Request.Context(), data, c.Query("mode"), requestNamespace(c, c.Query("namespace")))
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Failed to import error rules", "error", err)
+		response := gin.H{
+			"error":   "Failed to import error rules",
+			"details": err.Error(),
+		}
+		// 规则集验证通过后部分规则应用失败
+		if result != nil {
+			response["result"] = result
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListRuleTemplates 列出内置规则模板
+func (h *ErrorHandler) ListRuleTemplates(c *gin.Context) {
+	templates := h.service.ListRuleTemplates()
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// CreateRuleFromTemplate 用模板和参数创建规则
+func (h *ErrorHandler) CreateRuleFromTemplate(c *gin.Context) {
+	var req models.RuleFromTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.Namespace = requestNamespace(c, req.Namespace)
+	rule, err := h.service.CreateRuleFromTemplate(c.Request.Context(), c.Param("name"), &req)
+	if errors.Is(err, service.ErrRuleTemplateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Rule template not found",
+		})
+		return
+	}
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Failed to create rule from template", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create rule from template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"rule_id": rule.ID,
+		"rule":    rule,
+		"message": "Error rule added successfully",
+	})
+}
+
 // CheckErrorInjectionRequest 检查错误注入请求
 type CheckErrorInjectionRequest struct {
-	Metadata map[string]string `json:"metadata"`
+	Metadata map[string]string `json:"metadata"` // 请求元数据，键见models.ErrorMetadata*
 }
 
 // CheckErrorInjection 检查错误注入
 func (h *ErrorHandler) CheckErrorInjection(c *gin.Context) {
-	service := c.Param("service")
+	serviceName := c.Param("service")
 	operation := c.Param("operation")
 
-	if service == "" || operation == "" {
+	if serviceName == "" || operation == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Service and operation are required",
 		})
@@ -240,12 +393,17 @@ func (h *ErrorHandler) CheckErrorInjection(c *gin.Context) {
 		req.Metadata = make(map[string]string)
 	}
 
-	action, shouldInject := h.service.ShouldInjectError(c.Request.Context(), service, operation)
+	namespace := models.RuleNamespace(requestNamespace(c, ""))
+	action, shouldInject := h.service.ShouldInjectErrorWithMetadata(c.Request.Context(), namespace, serviceName, operation, req.Metadata)
 
 	response := gin.H{
 		"should_inject": shouldInject,
-		"service":       service,
+		"namespace":     namespace,
+		"service":       serviceName,
 		"operation":     operation,
+		"dry_run":       h.service.IsDryRun(),
+		"kill_switch":   h.service.KillSwitch().Engaged,
+		"request_hash":  service.RequestHash(req.Metadata),
 	}
 
 	if shouldInject && action != nil {
@@ -255,6 +413,209 @@ func (h *ErrorHandler) CheckErrorInjection(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// SetDryRunRequest 全局试运行开关请求
+type SetDryRunRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// GetDryRun 获取全局试运行状态
+func (h *ErrorHandler) GetDryRun(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run": h.service.IsDryRun(),
+	})
+}
+
+// SetDryRun 开启或关闭全局试运行
+func (h *ErrorHandler) SetDryRun(c *gin.Context) {
+	var req SetDryRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Invalid request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.service.SetDryRun(c.Request.Context(), *req.Enabled)
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run": *req.Enabled,
+	})
+}
+
+// SetRandomSeedRequest 全局随机种子请求，seed为null时清除
+type SetRandomSeedRequest struct {
+	Seed *int64 `json:"seed"`
+}
+
+// GetRandomSeed 获取全局随机种子
+func (h *ErrorHandler) GetRandomSeed(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"seed": h.service.RandomSeed(),
+	})
+}
+
+// SetRandomSeed 设置或清除全局随机种子
+func (h *ErrorHandler) SetRandomSeed(c *gin.Context) {
+	var req SetRandomSeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Invalid request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.SetRandomSeed(c.Request.Context(), req.Seed); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to set random seed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set random seed",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"seed": req.Seed,
+	})
+}
+
+// WouldTriggerRequest 查询请求能否通过规则概率判定的请求，可以直接给出请求哈希，也可以给出请求元数据由服务端计算
+type WouldTriggerRequest struct {
+	RequestHashes []string            `json:"request_hashes"`
+	Requests      []map[string]string `json:"requests"` // 请求元数据，键见models.ErrorMetadata*
+}
+
+// WouldTrigger 按随机种子判定哪些请求哈希会通过规则的概率条件
+func (h *ErrorHandler) WouldTrigger(c *gin.Context) {
+	ruleID := c.Param("id")
+
+	var req WouldTriggerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Invalid request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.service.GetErrorRule(c.Request.Context(), ruleID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Rule not found",
+		})
+		return
+	}
+
+	hashes := req.RequestHashes
+	for _, metadata := range req.Requests {
+		hashes = append(hashes, service.RequestHash(metadata))
+	}
+
+	decisions, err := h.service.WouldTrigger(c.Request.Context(), ruleID, hashes)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to evaluate rule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to evaluate rule",
+		})
+		return
+	}
+
+	triggered := 0
+	for _, decision := range decisions {
+		if decision.Triggers {
+			triggered++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"rule_id":   ruleID,
+		"decisions": decisions,
+		"triggered": triggered,
+		"total":     len(decisions),
+	})
+}
+
+// EngageKillSwitchRequest 熔断开关请求
+type EngageKillSwitchRequest struct {
+	Reason string `json:"reason"`
+}
+
+// GetKillSwitch 获取全局熔断开关状态
+func (h *ErrorHandler) GetKillSwitch(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.KillSwitch())
+}
+
+// EngageKillSwitch 开启全局熔断开关，立即停止所有错误注入
+func (h *ErrorHandler) EngageKillSwitch(c *gin.Context) {
+	var req EngageKillSwitchRequest
+	// 紧急情况下不要求请求体
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.WarnContext(c.Request.Context(), "Invalid request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	status, err := h.service.EngageKillSwitch(c.Request.Context(), req.Reason)
+	if err != nil {
+		// 本实例已停止注入，但重启或其他实例不会生效
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":       "Kill switch engaged on this instance but failed to persist",
+			"details":     err.Error(),
+			"kill_switch": status,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// ReleaseKillSwitch 关闭全局熔断开关
+func (h *ErrorHandler) ReleaseKillSwitch(c *gin.Context) {
+	status, err := h.service.ReleaseKillSwitch(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":       "Failed to release kill switch",
+			"details":     err.Error(),
+			"kill_switch": status,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// GetToxiproxyStatus 获取Toxiproxy执行器的同步状态和生效的toxic
+func (h *ErrorHandler) GetToxiproxyStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.ToxiproxyStatus())
+}
+
+// SyncToxics 立即按当前规则同步Toxiproxy中的toxic
+func (h *ErrorHandler) SyncToxics(c *gin.Context) {
+	if !h.service.ToxiproxyStatus().Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Toxiproxy executor is not enabled",
+		})
+		return
+	}
+
+	status, err := h.service.SyncToxics(c.Request.Context())
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Failed to sync toxics", "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Failed to sync toxics",
+			"details": err.Error(),
+			"status":  status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
 // GetErrorStats 获取错误统计
 func (h *ErrorHandler) GetErrorStats(c *gin.Context) {
 	stats, err := h.service.GetErrorStats(c.Request.Context())
@@ -303,6 +664,273 @@ func (h *ErrorHandler) GetErrorEvents(c *gin.Context) {
 	})
 }
 
+// eventStreamKeepAlive 事件流空闲时发送注释行的间隔，避免代理断开空闲连接
+const eventStreamKeepAlive = 15 * time.Second
+
+// StreamErrorEvents 以SSE实时推送错误注入事件，可按namespace、service、rule_id过滤
+func (h *ErrorHandler) StreamErrorEvents(c *gin.Context) {
+	filter := service.EventFilter{
+		Namespace: requestNamespace(c, c.Query("namespace")),
+		Service:   c.Query("service"),
+		RuleID:    c.Query("rule_id"),
+	}
+	if filter.Namespace != "" {
+		if err := service.ValidateNamespace(filter.Namespace); err != nil {
+			h.logger.WarnContext(c.Request.Context(), "Invalid request", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	subscription := h.service.SubscribeEvents(filter)
+	defer h.service.UnsubscribeEvents(subscription)
+
+	// 事件流是长连接，不受服务器写超时限制
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return false
+			}
+			// 先告知消费不及时丢弃的事件数，操作台据此提示事件不完整
+			if dropped := subscription.Dropped(); dropped > 0 {
+				c.SSEvent("dropped", gin.H{"count": dropped})
+			}
+			c.SSEvent("injection", event)
+		case <-keepAlive.C:
+			if dropped := subscription.Dropped(); dropped > 0 {
+				c.SSEvent("dropped", gin.H{"count": dropped})
+				return true
+			}
+			_, _ = io.WriteString(w, ": keepalive\n\n")
+		}
+		return true
+	})
+}
+
+// GetStaleRules 获取过期规则报告，只读
+func (h *ErrorHandler) GetStaleRules(c *gin.Context) {
+	h.staleRules(c, false)
+}
+
+// DisableStaleRules 检测并禁用过期规则，返回的报告中标记已禁用的规则
+func (h *ErrorHandler) DisableStaleRules(c *gin.Context) {
+	h.staleRules(c, true)
+}
+
+// staleRules 按idle_days参数检测过期规则
+func (h *ErrorHandler) staleRules(c *gin.Context, disable bool) {
+	idleDays := h.service.DefaultStaleRuleDays()
+	if idleDaysStr := c.Query("idle_days"); idleDaysStr != "" {
+		days, err := strconv.Atoi(idleDaysStr)
+		if err != nil || days <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid idle_days parameter",
+			})
+			return
+		}
+		idleDays = days
+	}
+
+	report, err := h.service.GetStaleRules(c.Request.Context(), time.Duration(idleDays)*24*time.Hour, disable)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get stale rules", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get stale rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetRuleAudit 分页查询规则变更审计记录
+func (h *ErrorHandler) GetRuleAudit(c *gin.Context) {
+	query := &models.RuleAuditQuery{
+		RuleID: c.Query("rule_id"),
+		Action: c.Query("action"),
+	}
+
+	var err error
+	if query.Since, err = parseOptionalTime(c.Query("since")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since parameter, expected RFC3339"})
+		return
+	}
+	if query.Until, err = parseOptionalTime(c.Query("until")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid until parameter, expected RFC3339"})
+		return
+	}
+	if value := c.Query("after"); value != "" {
+		if query.After, err = strconv.ParseInt(value, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid after parameter"})
+			return
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		if query.Limit, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+			return
+		}
+	}
+
+	page, err := h.service.ListRuleAudit(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list rule audit", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list rule audit",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// StartExperimentRequest 开始混沌实验请求
+type StartExperimentRequest struct {
+	Name              string                     `json:"name" binding:"required"`
+	Description       string                     `json:"description"`
+	Rules             []*models.ErrorRule        `json:"rules" binding:"required"`
+	Probes            []*models.SteadyStateProbe `json:"probes" binding:"required"`
+	DurationSecs      int                        `json:"duration_secs"`
+	ProbeIntervalSecs int                        `json:"probe_interval_secs"`
+}
+
+// StartExperiment 开始混沌实验
+func (h *ErrorHandler) StartExperiment(c *gin.Context) {
+	var req StartExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Invalid request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// 实验规则默认属于请求的命名空间
+	for _, rule := range req.Rules {
+		if rule != nil {
+			rule.Namespace = requestNamespace(c, rule.Namespace)
+		}
+	}
+
+	experiment, err := h.service.StartExperiment(c.Request.Context(), &models.Experiment{
+		Name:              req.Name,
+		Description:       req.Description,
+		Rules:             req.Rules,
+		Probes:            req.Probes,
+		DurationSecs:      req.DurationSecs,
+		ProbeIntervalSecs: req.ProbeIntervalSecs,
+	})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to start experiment", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to start experiment",
+		})
+		return
+	}
+
+	// 注入前稳态不满足时实验直接结束
+	if experiment.Status == models.ExperimentStatusFailed {
+		c.JSON(http.StatusConflict, experiment)
+		return
+	}
+	c.JSON(http.StatusCreated, experiment)
+}
+
+// ListExperiments 列出混沌实验
+func (h *ErrorHandler) ListExperiments(c *gin.Context) {
+	experiments, err := h.service.ListExperiments(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list experiments", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list experiments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"experiments": experiments,
+		"count":       len(experiments),
+	})
+}
+
+// GetExperiment 获取混沌实验
+func (h *ErrorHandler) GetExperiment(c *gin.Context) {
+	experiment, err := h.service.GetExperiment(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Experiment not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, experiment)
+}
+
+// StopExperiment 停止混沌实验并禁用其规则
+func (h *ErrorHandler) StopExperiment(c *gin.Context) {
+	experimentID := c.Param("id")
+	if _, err := h.service.GetExperiment(c.Request.Context(), experimentID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Experiment not found",
+		})
+		return
+	}
+
+	experiment, err := h.service.StopExperiment(c.Request.Context(), experimentID)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, experiment)
+}
+
+// requestNamespace 请求的命名空间：value不为空时使用value，否则使用X-Mocks3-Namespace请求头，都为空时返回空
+func requestNamespace(c *gin.Context, value string) string {
+	if value != "" {
+		return value
+	}
+	return c.GetHeader(models.HeaderNamespace)
+}
+
+// parseOptionalTime 解析可选的RFC3339时间参数
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // EnableRule 启用规则
 func (h *ErrorHandler) EnableRule(c *gin.Context) {
 	ruleID := c.Param("id")
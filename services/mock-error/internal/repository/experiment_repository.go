@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"sort"
+	"sync"
+)
+
+// ExperimentRepository 混沌实验仓库，只保存在内存中
+type ExperimentRepository struct {
+	experiments map[string]*models.Experiment
+	mu          sync.RWMutex
+}
+
+// NewExperimentRepository 创建混沌实验仓库
+func NewExperimentRepository() *ExperimentRepository {
+	return &ExperimentRepository{
+		experiments: make(map[string]*models.Experiment),
+	}
+}
+
+// Save 保存实验，已存在时覆盖
+func (r *ExperimentRepository) Save(ctx context.Context, experiment *models.Experiment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.experiments[experiment.ID] = copyExperiment(experiment)
+	return nil
+}
+
+// Get 获取实验
+func (r *ExperimentRepository) Get(ctx context.Context, experimentID string) (*models.Experiment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	experiment, exists := r.experiments[experimentID]
+	if !exists {
+		return nil, fmt.Errorf("experiment not found: %s", experimentID)
+	}
+	return copyExperiment(experiment), nil
+}
+
+// List 列出所有实验，最近开始的在前
+func (r *ExperimentRepository) List(ctx context.Context) ([]*models.Experiment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	experiments := make([]*models.Experiment, 0, len(r.experiments))
+	for _, experiment := range r.experiments {
+		experiments = append(experiments, copyExperiment(experiment))
+	}
+	sort.Slice(experiments, func(i, j int) bool {
+		return experiments[i].StartedAt.After(experiments[j].StartedAt)
+	})
+	return experiments, nil
+}
+
+// copyExperiment 复制实验，运行中的实验在后台更新状态和探针结果
+func copyExperiment(experiment *models.Experiment) *models.Experiment {
+	experimentCopy := *experiment
+	experimentCopy.RuleIDs = append([]string(nil), experiment.RuleIDs...)
+	experimentCopy.ProbeResults = make([]*models.ProbeResult, len(experiment.ProbeResults))
+	for i, result := range experiment.ProbeResults {
+		resultCopy := *result
+		experimentCopy.ProbeResults[i] = &resultCopy
+	}
+	return &experimentCopy
+}
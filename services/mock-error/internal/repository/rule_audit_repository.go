@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"mocks3/shared/models"
+	"sync"
+)
+
+// RuleAuditStore 规则审计记录的持久化存储
+type RuleAuditStore interface {
+	AppendRuleAudit(ctx context.Context, entry *models.RuleAuditEntry) error // 写入后设置entry.ID
+	ListRuleAudit(ctx context.Context, query *models.RuleAuditQuery) ([]*models.RuleAuditEntry, error)
+}
+
+// RuleAuditRepository 规则审计仓库，未配置存储时在内存中保留最近的记录
+type RuleAuditRepository struct {
+	store    RuleAuditStore
+	entries  []*models.RuleAuditEntry
+	capacity int
+	nextID   int64
+	mu       sync.RWMutex
+}
+
+// NewRuleAuditRepository 创建规则审计仓库，store为nil时只在内存中保留capacity条记录
+func NewRuleAuditRepository(store RuleAuditStore, capacity int) *RuleAuditRepository {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	return &RuleAuditRepository{
+		store:    store,
+		capacity: capacity,
+	}
+}
+
+// Append 追加审计记录
+func (r *RuleAuditRepository) Append(ctx context.Context, entry *models.RuleAuditEntry) error {
+	if r.store != nil {
+		return r.store.AppendRuleAudit(ctx, entry)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	entry.ID = r.nextID
+	r.entries = append(r.entries, entry)
+	if len(r.entries) > r.capacity {
+		r.entries = r.entries[len(r.entries)-r.capacity:]
+	}
+	return nil
+}
+
+// List 按条件查询审计记录（按ID升序）
+func (r *RuleAuditRepository) List(ctx context.Context, query *models.RuleAuditQuery) ([]*models.RuleAuditEntry, error) {
+	if r.store != nil {
+		return r.store.ListRuleAudit(ctx, query)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*models.RuleAuditEntry, 0)
+	for _, entry := range r.entries {
+		if len(entries) >= query.Limit {
+			break
+		}
+		if entry.ID <= query.After ||
+			(query.RuleID != "" && entry.RuleID != query.RuleID) ||
+			(query.Action != "" && entry.Action != query.Action) ||
+			(query.Since != nil && entry.CreatedAt.Before(*query.Since)) ||
+			(query.Until != nil && !entry.CreatedAt.Before(*query.Until)) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -10,8 +10,11 @@ import (
 )
 
 // RuleRepository 错误规则仓库
+//
+// 规则副本保存在内存中，只整体替换不原地修改；配置了持久化存储时每次变更同步写入存储，重启后通过Load恢复。
 type RuleRepository struct {
 	rules map[string]*models.ErrorRule
+	store RuleStore // 为nil时只保存在内存中
 	mu    sync.RWMutex
 }
 
@@ -22,6 +25,33 @@ func NewRuleRepository() *RuleRepository {
 	}
 }
 
+// NewPersistentRuleRepository 创建写入持久化存储的错误规则仓库
+func NewPersistentRuleRepository(store RuleStore) *RuleRepository {
+	repo := NewRuleRepository()
+	repo.store = store
+	return repo
+}
+
+// Load 从持久化存储加载规则，仓库和规则引擎各自保存副本
+func (r *RuleRepository) Load(ctx context.Context) ([]*models.ErrorRule, error) {
+	if r.store == nil {
+		return nil, nil
+	}
+
+	rules, err := r.store.LoadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rule := range rules {
+		ruleCopy := *rule
+		r.rules[rule.ID] = &ruleCopy
+	}
+	return rules, nil
+}
+
 // Add 添加规则
 func (r *RuleRepository) Add(ctx context.Context, rule *models.ErrorRule) error {
 	r.mu.Lock()
@@ -35,7 +65,11 @@ func (r *RuleRepository) Add(ctx context.Context, rule *models.ErrorRule) error
 	rule.CreatedAt = now
 	rule.UpdatedAt = now
 
-	r.rules[rule.ID] = rule
+	if err := r.save(ctx, rule); err != nil {
+		return err
+	}
+	ruleCopy := *rule
+	r.rules[rule.ID] = &ruleCopy
 	return nil
 }
 
@@ -44,12 +78,21 @@ func (r *RuleRepository) Update(ctx context.Context, rule *models.ErrorRule) err
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.rules[rule.ID]; !exists {
+	existing, exists := r.rules[rule.ID]
+	if !exists {
 		return fmt.Errorf("rule not found: %s", rule.ID)
 	}
 
+	// 创建时间是调度和概率爬升的默认起点
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = existing.CreatedAt
+	}
 	rule.UpdatedAt = time.Now()
-	r.rules[rule.ID] = rule
+	if err := r.save(ctx, rule); err != nil {
+		return err
+	}
+	ruleCopy := *rule
+	r.rules[rule.ID] = &ruleCopy
 	return nil
 }
 
@@ -62,6 +105,11 @@ func (r *RuleRepository) Delete(ctx context.Context, ruleID string) error {
 		return fmt.Errorf("rule not found: %s", ruleID)
 	}
 
+	if r.store != nil {
+		if err := r.store.DeleteRule(ctx, ruleID); err != nil {
+			return err
+		}
+	}
 	delete(r.rules, ruleID)
 	return nil
 }
@@ -164,9 +212,13 @@ func (r *RuleRepository) IncrementTriggerCount(ctx context.Context, ruleID strin
 		return fmt.Errorf("rule not found: %s", ruleID)
 	}
 
-	rule.Triggered++
-	rule.UpdatedAt = time.Now()
-	return nil
+	// 持久化失败时内存中的计数仍然生效，max_triggers不受影响
+	now := time.Now()
+	updated := *rule
+	updated.Triggered++
+	updated.LastTriggeredAt = &now
+	r.rules[ruleID] = &updated
+	return r.save(ctx, &updated)
 }
 
 // EnableRule 启用规则
@@ -179,8 +231,13 @@ func (r *RuleRepository) EnableRule(ctx context.Context, ruleID string) error {
 		return fmt.Errorf("rule not found: %s", ruleID)
 	}
 
-	rule.Enabled = true
-	rule.UpdatedAt = time.Now()
+	updated := *rule
+	updated.Enabled = true
+	updated.UpdatedAt = time.Now()
+	if err := r.save(ctx, &updated); err != nil {
+		return err
+	}
+	r.rules[ruleID] = &updated
 	return nil
 }
 
@@ -194,11 +251,37 @@ func (r *RuleRepository) DisableRule(ctx context.Context, ruleID string) error {
 		return fmt.Errorf("rule not found: %s", ruleID)
 	}
 
-	rule.Enabled = false
-	rule.UpdatedAt = time.Now()
+	updated := *rule
+	updated.Enabled = false
+	updated.UpdatedAt = time.Now()
+	if err := r.save(ctx, &updated); err != nil {
+		return err
+	}
+	r.rules[ruleID] = &updated
 	return nil
 }
 
+// Persistent 是否配置了持久化存储
+func (r *RuleRepository) Persistent() bool {
+	return r.store != nil
+}
+
+// LoadKillSwitch 从持久化存储加载熔断开关状态，未开启或未配置存储时返回nil
+func (r *RuleRepository) LoadKillSwitch(ctx context.Context) (*models.KillSwitchStatus, error) {
+	if r.store == nil {
+		return nil, nil
+	}
+	return r.store.LoadKillSwitch(ctx)
+}
+
+// SaveKillSwitch 把熔断开关状态写入持久化存储，status为nil表示关闭；未配置存储时直接返回
+func (r *RuleRepository) SaveKillSwitch(ctx context.Context, status *models.KillSwitchStatus) error {
+	if r.store == nil {
+		return nil
+	}
+	return r.store.SaveKillSwitch(ctx, status)
+}
+
 // Count 获取规则数量
 func (r *RuleRepository) Count(ctx context.Context) (int, error) {
 	r.mu.RLock()
@@ -216,6 +299,17 @@ func (r *RuleRepository) CountActive(ctx context.Context) (int, error) {
 	return len(rules), nil
 }
 
+// save 写入持久化存储，未配置存储时直接返回
+func (r *RuleRepository) save(ctx context.Context, rule *models.ErrorRule) error {
+	if r.store == nil {
+		return nil
+	}
+	if err := r.store.SaveRule(ctx, rule); err != nil {
+		return fmt.Errorf("failed to persist rule %s: %w", rule.ID, err)
+	}
+	return nil
+}
+
 // isRuleExpired 检查规则是否已过期
 func (r *RuleRepository) isRuleExpired(rule *models.ErrorRule) bool {
 	if rule.Schedule == nil || rule.Schedule.EndTime == nil {
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mocks3/shared/models"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"           // PostgreSQL driver
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+)
+
+// 支持的规则存储驱动
+const (
+	RuleStoreDriverPostgres = "postgres"
+	RuleStoreDriverSQLite   = "sqlite3"
+)
+
+// RuleStore 规则的持久化存储
+type RuleStore interface {
+	LoadRules(ctx context.Context) ([]*models.ErrorRule, error)
+	SaveRule(ctx context.Context, rule *models.ErrorRule) error // 不存在时插入，存在时覆盖
+	DeleteRule(ctx context.Context, ruleID string) error
+	LoadKillSwitch(ctx context.Context) (*models.KillSwitchStatus, error) // 未开启时返回nil
+	SaveKillSwitch(ctx context.Context, status *models.KillSwitchStatus) error
+	Close() error
+}
+
+// killSwitchSetting 熔断开关在设置表中的名称
+const killSwitchSetting = "kill_switch"
+
+// SQLRuleStore 基于PostgreSQL或SQLite的规则和规则审计存储，规则和审计记录整体以JSON保存
+type SQLRuleStore struct {
+	db     *sql.DB
+	driver string
+}
+
+// NewSQLRuleStore 打开数据库并创建规则表和审计表
+func NewSQLRuleStore(driver, dsn string) (*SQLRuleStore, error) {
+	if driver != RuleStoreDriverPostgres && driver != RuleStoreDriverSQLite {
+		return nil, fmt.Errorf("unsupported rule store driver: %s", driver)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rule store: %w", err)
+	}
+	if driver == RuleStoreDriverSQLite {
+		// SQLite同一时间只允许一个写入者
+		db.SetMaxOpenConns(1)
+	} else {
+		db.SetMaxOpenConns(10)
+		db.SetMaxIdleConns(2)
+		db.SetConnMaxLifetime(5 * time.Minute)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping rule store: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS error_rules (
+		id VARCHAR(255) PRIMARY KEY,
+		data TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create rule table: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS error_settings (
+		name VARCHAR(64) PRIMARY KEY,
+		data TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create settings table: %w", err)
+	}
+
+	idColumn := "BIGSERIAL PRIMARY KEY"
+	if driver == RuleStoreDriverSQLite {
+		idColumn = "INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+	for _, statement := range []string{
+		`CREATE TABLE IF NOT EXISTS rule_audit (
+			id ` + idColumn + `,
+			rule_id VARCHAR(255) NOT NULL,
+			action VARCHAR(32) NOT NULL,
+			data TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_rule_audit_rule_id ON rule_audit (rule_id)`,
+	} {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create rule audit table: %w", err)
+		}
+	}
+
+	return &SQLRuleStore{db: db, driver: driver}, nil
+}
+
+// LoadRules 加载所有规则
+func (s *SQLRuleStore) LoadRules(ctx context.Context) ([]*models.ErrorRule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, data FROM error_rules`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*models.ErrorRule
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan rule: %w", err)
+		}
+
+		var rule models.ErrorRule
+		if err := json.Unmarshal([]byte(data), &rule); err != nil {
+			return nil, fmt.Errorf("failed to decode rule %s: %w", id, err)
+		}
+		rules = append(rules, &rule)
+	}
+	return rules, rows.Err()
+}
+
+// SaveRule 保存规则
+func (s *SQLRuleStore) SaveRule(ctx context.Context, rule *models.ErrorRule) error {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("failed to encode rule: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, s.rebind(`INSERT INTO error_rules (id, data, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`),
+		rule.ID, string(data), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteRule 删除规则，规则不存在时不报错
+func (s *SQLRuleStore) DeleteRule(ctx context.Context, ruleID string) error {
+	if _, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM error_rules WHERE id = $1`), ruleID); err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+	return nil
+}
+
+// LoadKillSwitch 加载熔断开关状态，未开启时返回nil
+func (s *SQLRuleStore) LoadKillSwitch(ctx context.Context) (*models.KillSwitchStatus, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT data FROM error_settings WHERE name = $1`), killSwitchSetting).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kill switch: %w", err)
+	}
+
+	var status models.KillSwitchStatus
+	if err := json.Unmarshal([]byte(data), &status); err != nil {
+		return nil, fmt.Errorf("failed to decode kill switch: %w", err)
+	}
+	if !status.Engaged {
+		return nil, nil
+	}
+	return &status, nil
+}
+
+// SaveKillSwitch 保存熔断开关状态，status为nil表示关闭
+func (s *SQLRuleStore) SaveKillSwitch(ctx context.Context, status *models.KillSwitchStatus) error {
+	if status == nil {
+		status = &models.KillSwitchStatus{}
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to encode kill switch: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, s.rebind(`INSERT INTO error_settings (name, data, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`),
+		killSwitchSetting, string(data), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save kill switch: %w", err)
+	}
+	return nil
+}
+
+// AppendRuleAudit 写入规则审计记录
+func (s *SQLRuleStore) AppendRuleAudit(ctx context.Context, entry *models.RuleAuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode rule audit: %w", err)
+	}
+
+	err = s.db.QueryRowContext(ctx, s.rebind(`INSERT INTO rule_audit (rule_id, action, data, created_at)
+		VALUES ($1, $2, $3, $4) RETURNING id`),
+		entry.RuleID, entry.Action, string(data), entry.CreatedAt.UTC()).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record rule audit: %w", err)
+	}
+	return nil
+}
+
+// ListRuleAudit 按条件查询规则审计记录（按ID升序）
+func (s *SQLRuleStore) ListRuleAudit(ctx context.Context, query *models.RuleAuditQuery) ([]*models.RuleAuditEntry, error) {
+	conditions := []string{"id > $1"}
+	args := []interface{}{query.After}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if query.RuleID != "" {
+		addCondition("rule_id = $%d", query.RuleID)
+	}
+	if query.Action != "" {
+		addCondition("action = $%d", query.Action)
+	}
+	if query.Since != nil {
+		addCondition("created_at >= $%d", query.Since.UTC())
+	}
+	if query.Until != nil {
+		addCondition("created_at < $%d", query.Until.UTC())
+	}
+	args = append(args, query.Limit)
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(fmt.Sprintf(`SELECT id, data FROM rule_audit
+		WHERE %s ORDER BY id LIMIT $%d`, strings.Join(conditions, " AND "), len(args))), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rule audit: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*models.RuleAuditEntry, 0)
+	for rows.Next() {
+		var id int64
+		var data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan rule audit: %w", err)
+		}
+
+		var entry models.RuleAuditEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode rule audit %d: %w", id, err)
+		}
+		entry.ID = id
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
+
+// Close 关闭数据库连接
+func (s *SQLRuleStore) Close() error {
+	return s.db.Close()
+}
+
+// rebind SQL按PostgreSQL书写，SQLite执行前将$N占位符转换为?N
+func (s *SQLRuleStore) rebind(query string) string {
+	if s.driver == RuleStoreDriverPostgres {
+		return query
+	}
+	return strings.ReplaceAll(query, "$", "?")
+}
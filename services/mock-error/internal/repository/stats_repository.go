@@ -156,9 +156,15 @@ func (r *StatsRepository) GetServiceStats(ctx context.Context, service string) (
 // updateStats 更新统计信息
 func (r *StatsRepository) updateStats(event *models.ErrorEvent) {
 	now := time.Now()
-	r.stats.TotalTriggers++
 	r.stats.LastUpdate = now
 
+	// 试运行命中只计入命中次数，不计入触发和错误率
+	if event.DryRun {
+		r.updateDryRunStats(event)
+		return
+	}
+	r.stats.TotalTriggers++
+
 	// 计算小时和日期范围
 	oneHourAgo := now.Add(-1 * time.Hour)
 	oneDayAgo := now.Add(-24 * time.Hour)
@@ -247,6 +253,25 @@ func (r *StatsRepository) updateStats(event *models.ErrorEvent) {
 	}
 }
 
+// updateDryRunStats 更新试运行命中统计
+func (r *StatsRepository) updateDryRunStats(event *models.ErrorEvent) {
+	r.stats.DryRunMatches++
+	if event.RuleID == "" {
+		return
+	}
+
+	ruleStat, exists := r.stats.RuleStats[event.RuleID]
+	if !exists {
+		ruleStat = &models.RuleStat{
+			RuleID:      event.RuleID,
+			RuleName:    event.RuleName,
+			ErrorCounts: make(map[string]int64),
+		}
+		r.stats.RuleStats[event.RuleID] = ruleStat
+	}
+	ruleStat.DryRunMatches++
+}
+
 // cleanupExpiredData 清理过期数据
 func (r *StatsRepository) cleanupExpiredData() {
 	now := time.Now()
@@ -260,7 +285,7 @@ func (r *StatsRepository) cleanupExpiredData() {
 	oneDayAgo := now.Add(-24 * time.Hour)
 
 	for _, event := range r.events {
-		if event.Timestamp.After(cutoff) {
+		if event.Timestamp.After(cutoff) && !event.DryRun {
 			if event.Timestamp.After(oneHourAgo) {
 				r.stats.TriggersLastHour++
 			}
@@ -8,18 +8,38 @@ import (
 	"mocks3/shared/interfaces"
 	"mocks3/shared/models"
 	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"sync/atomic"
 	"time"
-
-	"github.com/google/uuid"
 )
 
+// triggerRecorder 自己维护触发次数的规则引擎
+type triggerRecorder interface {
+	RecordTrigger(ruleID string) (int, bool)
+}
+
+// ServiceChecker 服务存在性检查器（通常由Consul提供）
+type ServiceChecker interface {
+	ServiceExists(ctx context.Context, serviceName string) (bool, error)
+}
+
 // ErrorInjectorService 错误注入服务实现
 type ErrorInjectorService struct {
-	config     *config.Config
-	ruleRepo   *repository.RuleRepository
-	statsRepo  *repository.StatsRepository
-	ruleEngine interfaces.ErrorRuleEngine
-	logger     *observability.Logger
+	config         *config.Config
+	ruleRepo       *repository.RuleRepository
+	statsRepo      *repository.StatsRepository
+	auditRepo      *repository.RuleAuditRepository
+	auditLog       *observability.AuditLogger
+	webhooks       *webhookNotifier
+	experiments    *experimentRunner
+	eventStream    *eventBroadcaster
+	toxiproxy      *toxiproxyExecutor
+	killSwitch     atomic.Pointer[models.KillSwitchStatus] // 为nil时未开启
+	dryRun         atomic.Bool
+	ruleEngine     interfaces.ErrorRuleEngine
+	serviceChecker ServiceChecker
+	metrics        *observability.MetricCollector
+	logger         *observability.Logger
 }
 
 // NewErrorInjectorService 创建错误注入服务
@@ -30,13 +50,42 @@ func NewErrorInjectorService(
 	ruleEngine interfaces.ErrorRuleEngine,
 	logger *observability.Logger,
 ) *ErrorInjectorService {
-	return &ErrorInjectorService{
-		config:     cfg,
-		ruleRepo:   ruleRepo,
-		statsRepo:  statsRepo,
-		ruleEngine: ruleEngine,
-		logger:     logger,
-	}
+	s := &ErrorInjectorService{
+		config:      cfg,
+		ruleRepo:    ruleRepo,
+		statsRepo:   statsRepo,
+		auditRepo:   repository.NewRuleAuditRepository(nil, 0),
+		webhooks:    newWebhookNotifier(cfg.Webhook),
+		experiments: newExperimentRunner(),
+		eventStream: newEventBroadcaster(),
+		ruleEngine:  ruleEngine,
+		logger:      logger,
+	}
+	s.dryRun.Store(cfg.Injection.DryRun)
+	return s
+}
+
+// SetDryRun 开启或关闭全局试运行，开启后所有规则命中时只记录不执行动作
+func (s *ErrorInjectorService) SetDryRun(ctx context.Context, enabled bool) {
+	s.dryRun.Store(enabled)
+	s.logger.Info(ctx, "Global dry run changed",
+		observability.Bool("dry_run", enabled))
+	s.syncToxicsAfterChange(ctx)
+}
+
+// IsDryRun 是否开启了全局试运行
+func (s *ErrorInjectorService) IsDryRun() bool {
+	return s.dryRun.Load()
+}
+
+// SetMetricCollector 设置指标收集器，记录规则命中次数和注入的延迟
+func (s *ErrorInjectorService) SetMetricCollector(metrics *observability.MetricCollector) {
+	s.metrics = metrics
+}
+
+// SetServiceChecker 设置服务存在性检查器
+func (s *ErrorInjectorService) SetServiceChecker(checker ServiceChecker) {
+	s.serviceChecker = checker
 }
 
 // AddErrorRule 添加错误规则
@@ -52,6 +101,11 @@ func (s *ErrorInjectorService) AddErrorRule(ctx context.Context, rule *models.Er
 		return fmt.Errorf("invalid rule: %w", err)
 	}
 
+	// 检查同时启用的规则数
+	if err := s.checkActiveRuleLimit(ctx, rule, nil); err != nil {
+		return err
+	}
+
 	// 检查规则数量限制
 	count, err := s.ruleRepo.Count(ctx)
 	if err != nil {
@@ -64,7 +118,7 @@ func (s *ErrorInjectorService) AddErrorRule(ctx context.Context, rule *models.Er
 
 	// 生成ID
 	if rule.ID == "" {
-		rule.ID = uuid.New().String()
+		rule.ID = utils.NewID()
 	}
 
 	// 添加到仓库
@@ -83,6 +137,11 @@ func (s *ErrorInjectorService) AddErrorRule(ctx context.Context, rule *models.Er
 		return fmt.Errorf("failed to add rule to engine: %w", err)
 	}
 
+	s.recordRuleAudit(ctx, nil, rule)
+	if rule.Executor == models.RuleExecutorToxiproxy {
+		s.syncToxicsAfterChange(ctx)
+	}
+
 	// 更新统计
 	s.updateRuleCounts(ctx)
 
@@ -92,11 +151,33 @@ func (s *ErrorInjectorService) AddErrorRule(ctx context.Context, rule *models.Er
 	return nil
 }
 
+// LoadRules 从仓库的持久化存储加载规则到规则引擎，返回加载的规则数量
+func (s *ErrorInjectorService) LoadRules(ctx context.Context) (int, error) {
+	rules, err := s.ruleRepo.Load(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		if err := s.ruleEngine.AddRule(rule); err != nil {
+			return 0, fmt.Errorf("failed to add rule %s to engine: %w", rule.ID, err)
+		}
+	}
+
+	if len(rules) > 0 {
+		s.updateRuleCounts(ctx)
+	}
+	return len(rules), nil
+}
+
 // RemoveErrorRule 移除错误规则
 func (s *ErrorInjectorService) RemoveErrorRule(ctx context.Context, ruleID string) error {
 	s.logger.Info(ctx, "Removing error rule", 
 		observability.String("rule_id", ruleID))
 
+	// 删除前的规则用于审计
+	before, _ := s.ruleRepo.Get(ctx, ruleID)
+
 	// 从仓库删除
 	if err := s.ruleRepo.Delete(ctx, ruleID); err != nil {
 		s.logger.Warn(ctx, "Failed to remove rule from repository", 
@@ -112,6 +193,14 @@ func (s *ErrorInjectorService) RemoveErrorRule(ctx context.Context, ruleID strin
 			observability.String("error", err.Error()))
 	}
 
+	if before != nil {
+		s.recordRuleAudit(ctx, before, nil)
+	}
+	s.webhooks.forget(ruleID)
+	if before != nil && before.Executor == models.RuleExecutorToxiproxy {
+		s.syncToxicsAfterChange(ctx)
+	}
+
 	// 更新统计
 	s.updateRuleCounts(ctx)
 
@@ -131,6 +220,24 @@ func (s *ErrorInjectorService) UpdateErrorRule(ctx context.Context, rule *models
 		return fmt.Errorf("invalid rule: %w", err)
 	}
 
+	// 更新前的规则用于审计
+	before, _ := s.ruleRepo.Get(ctx, rule.ID)
+	if err := s.checkActiveRuleLimit(ctx, rule, before); err != nil {
+		return err
+	}
+
+	return s.applyRuleUpdate(ctx, rule, before)
+}
+
+// disableRule 禁用规则，rule为仓库返回的副本；与手动更新走同一路径，引擎中的计数和连续失败状态会一并重置
+func (s *ErrorInjectorService) disableRule(ctx context.Context, rule *models.ErrorRule) error {
+	disabled := *rule
+	disabled.Enabled = false
+	return s.applyRuleUpdate(ctx, &disabled, rule)
+}
+
+// applyRuleUpdate 把已校验的规则写入仓库和规则引擎，并记录审计
+func (s *ErrorInjectorService) applyRuleUpdate(ctx context.Context, rule, before *models.ErrorRule) error {
 	// 更新仓库
 	if err := s.ruleRepo.Update(ctx, rule); err != nil {
 		s.logger.Error(ctx, "Failed to update rule in repository", 
@@ -145,6 +252,15 @@ func (s *ErrorInjectorService) UpdateErrorRule(ctx context.Context, rule *models
 		return fmt.Errorf("failed to update rule in engine: %w", err)
 	}
 
+	if before != nil {
+		s.recordRuleAudit(ctx, before, rule)
+	}
+	// webhook地址或阈值可能已变化，重新统计触发率
+	s.webhooks.forget(rule.ID)
+	if rule.Executor == models.RuleExecutorToxiproxy || (before != nil && before.Executor == models.RuleExecutorToxiproxy) {
+		s.syncToxicsAfterChange(ctx)
+	}
+
 	s.logger.Info(ctx, "Error rule updated successfully", 
 		observability.String("rule_id", rule.ID))
 	return nil
@@ -181,45 +297,262 @@ func (s *ErrorInjectorService) ListErrorRules(ctx context.Context) ([]*models.Er
 	return rules, nil
 }
 
-// ShouldInjectError 检查是否应该注入错误
-func (s *ErrorInjectorService) ShouldInjectError(ctx context.Context, service, operation string) (*models.ErrorAction, bool) {
-	// 检查全局概率
-	if s.config.Injection.GlobalProbability < 1.0 {
-		// TODO: 实现全局概率检查
+// ListNamespaceRules 列出命名空间的规则，namespace为空时列出全部规则
+func (s *ErrorInjectorService) ListNamespaceRules(ctx context.Context, namespace string) ([]*models.ErrorRule, error) {
+	rules, err := s.ListErrorRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filterNamespaceRules(rules, namespace), nil
+}
+
+// ShouldInjectError 检查命名空间中的规则是否应该注入错误
+func (s *ErrorInjectorService) ShouldInjectError(ctx context.Context, namespace, service, operation string) (*models.ErrorAction, bool) {
+	return s.ShouldInjectErrorWithMetadata(ctx, namespace, service, operation, nil)
+}
+
+// ShouldInjectErrorWithMetadata 按请求元数据（请求头、路径、请求大小等）检查命名空间中的规则是否应该注入错误
+func (s *ErrorInjectorService) ShouldInjectErrorWithMetadata(ctx context.Context, namespace, service, operation string, requestMetadata map[string]string) (*models.ErrorAction, bool) {
+	// 熔断开关开启时不评估规则
+	if s.isKilled() {
+		return nil, false
 	}
 
-	// 从请求上下文中提取元数据
+	// 从请求上下文中提取元数据，请求中携带的元数据优先
 	metadata := s.extractMetadata(ctx)
+	for key, value := range requestMetadata {
+		metadata[key] = value
+	}
 
 	// 使用规则引擎评估
-	action, shouldInject := s.ruleEngine.EvaluateRules(ctx, service, operation, metadata)
-
-	if shouldInject {
-		s.logger.Debug(ctx, "Error injection triggered",
-			observability.String("service", service),
-			observability.String("operation", operation),
-			observability.String("action_type", action.Type))
-
-		// 记录事件
-		event := &models.ErrorEvent{
-			ID:        uuid.New().String(),
-			Service:   service,
-			Operation: operation,
-			Action:    *action,
-			Timestamp: time.Now(),
-			Success:   true,
+	rule, shouldInject := s.ruleEngine.MatchRule(ctx, namespace, service, operation, metadata)
+	if !shouldInject {
+		return nil, false
+	}
+
+	// 全局触发概率和安全上限限制实际受影响的请求比例
+	if !s.passesProbabilityCap(rule, RequestHash(metadata)) {
+		return nil, false
+	}
+	action := &rule.Action
+	if action.Latency != nil {
+		action = s.sampleLatency(action)
+	}
+
+	// 试运行只记录命中，不计入触发次数，也不通知webhook
+	dryRun := rule.DryRun || s.IsDryRun()
+	s.recordInjectionMetrics(ctx, namespace, rule, service, action, dryRun)
+	if dryRun {
+		s.recordDryRun(ctx, rule, namespace, service, operation, action, metadata)
+		return nil, false
+	}
+
+	s.logger.Debug(ctx, "Error injection triggered",
+		observability.String("service", service),
+		observability.String("operation", operation),
+		observability.String("action_type", action.Type))
+
+	// 更新规则触发计数：仓库记录持久化的计数，引擎按自己的计数判断max_triggers
+	if err := s.ruleRepo.IncrementTriggerCount(ctx, rule.ID); err != nil {
+		s.logger.Warn(ctx, "Failed to increment trigger count",
+			observability.String("rule_id", rule.ID),
+			observability.String("error", err.Error()))
+	}
+	triggered := rule.Triggered + 1
+	if recorder, ok := s.ruleEngine.(triggerRecorder); ok {
+		if count, exists := recorder.RecordTrigger(rule.ID); exists {
+			triggered = count
 		}
+	}
+
+	// 通知规则的webhook
+	if len(rule.Webhooks) > 0 {
+		s.notifyRuleTriggered(ctx, rule, triggered, service, operation, action)
+	}
+
+	// 记录事件并推送给实时订阅者
+	event := newInjectionEvent(rule, namespace, service, operation, action, metadata)
+	event.Success = true
+	s.eventStream.publish(event)
+
+	// 异步记录统计
+	go func() {
+		if err := s.statsRepo.RecordEvent(context.Background(), event); err != nil {
+			s.logger.Warn(context.Background(), "Failed to record error event", 
+			observability.String("error", err.Error()))
+		}
+	}()
+
+	return action, true
+}
 
-		// 异步记录统计
-		go func() {
-			if err := s.statsRepo.RecordEvent(context.Background(), event); err != nil {
-				s.logger.Warn(context.Background(), "Failed to record error event", 
+// recordInjectionMetrics 记录规则命中和注入的延迟指标
+func (s *ErrorInjectorService) recordInjectionMetrics(ctx context.Context, namespace string, rule *models.ErrorRule, service string, action *models.ErrorAction, dryRun bool) {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.RecordErrorInjection(ctx, models.RuleNamespace(namespace), rule.ID, service, action.Type, dryRun)
+	if !dryRun && action.Delay != nil && *action.Delay > 0 {
+		s.metrics.RecordInjectedDelay(ctx, service, action.Type, *action.Delay)
+	}
+}
+
+// recordDryRun 记录试运行命中
+func (s *ErrorInjectorService) recordDryRun(ctx context.Context, rule *models.ErrorRule, namespace, service, operation string, action *models.ErrorAction, metadata map[string]string) {
+	s.logger.Debug(ctx, "Error injection dry run matched",
+		observability.String("rule_id", rule.ID),
+		observability.String("service", service),
+		observability.String("operation", operation),
+		observability.String("action_type", action.Type))
+
+	event := newInjectionEvent(rule, namespace, service, operation, action, metadata)
+	event.DryRun = true
+	s.eventStream.publish(event)
+	go func() {
+		if err := s.statsRepo.RecordEvent(context.Background(), event); err != nil {
+			s.logger.Warn(context.Background(), "Failed to record dry run event",
 				observability.String("error", err.Error()))
+		}
+	}()
+}
+
+// staleRuleAuditContext 后台检测自动禁用规则时没有请求的操作者，审计中记为过期规则检测
+func staleRuleAuditContext(ctx context.Context) context.Context {
+	if models.AuditActorFromContext(ctx).Name != "" {
+		return ctx
+	}
+	return models.WithAuditActor(ctx, models.AuditActor{Name: "stale-rule-checker"})
+}
+
+// GetStaleRules 检测长时间未命中、已过期或目标服务已消失的规则
+func (s *ErrorInjectorService) GetStaleRules(ctx context.Context, idleThreshold time.Duration, autoDisable bool) (*models.StaleRuleReport, error) {
+	rules, err := s.ruleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+
+	now := time.Now()
+	report := &models.StaleRuleReport{
+		IdleThreshold: idleThreshold.String(),
+		TotalRules:    len(rules),
+		StaleRules:    make([]*models.StaleRule, 0),
+		GeneratedAt:   now,
+	}
+
+	// 同一服务只查询一次
+	serviceExists := make(map[string]bool)
+
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		// 未触发过的规则从创建时间开始计算空闲时长
+		lastActive := rule.CreatedAt
+		if rule.LastTriggeredAt != nil {
+			lastActive = *rule.LastTriggeredAt
+		}
+		idleFor := now.Sub(lastActive)
+
+		var expiresAt *time.Time
+		if rule.Schedule != nil {
+			expiresAt = rule.Schedule.ExpiresAt(rule.CreatedAt)
+		}
+
+		reason := ""
+		switch {
+		case expiresAt != nil && now.After(*expiresAt):
+			reason = models.StaleRuleReasonExpired
+		case idleFor >= idleThreshold && rule.Executor != models.RuleExecutorToxiproxy:
+			// Toxiproxy执行的规则不按请求命中，没有触发记录
+			reason = models.StaleRuleReasonNeverMatched
+		case rule.Service != "" && s.serviceChecker != nil:
+			exists, checked := serviceExists[rule.Service]
+			if !checked {
+				var checkErr error
+				exists, checkErr = s.serviceChecker.ServiceExists(ctx, rule.Service)
+				if checkErr != nil {
+					s.logger.Warn(ctx, "Failed to check service existence",
+						observability.String("service", rule.Service),
+						observability.String("error", checkErr.Error()))
+					exists = true
+				}
+				serviceExists[rule.Service] = exists
 			}
-		}()
+			if !exists {
+				reason = models.StaleRuleReasonServiceMissing
+			}
+		}
+
+		if reason == "" {
+			continue
+		}
+
+		staleRule := &models.StaleRule{
+			RuleID:          rule.ID,
+			RuleName:        rule.Name,
+			Service:         rule.Service,
+			Reason:          reason,
+			LastTriggeredAt: rule.LastTriggeredAt,
+			IdleFor:         idleFor.Round(time.Second).String(),
+		}
+
+		if autoDisable {
+			if err := s.disableRule(staleRuleAuditContext(ctx), rule); err != nil {
+				s.logger.Warn(ctx, "Failed to disable stale rule",
+					observability.String("rule_id", rule.ID),
+					observability.String("error", err.Error()))
+			} else {
+				staleRule.Disabled = true
+				report.DisabledCount++
+			}
+		}
+
+		s.logger.Warn(ctx, "Stale error rule detected",
+			observability.String("rule_id", rule.ID),
+			observability.String("rule_name", rule.Name),
+			observability.String("reason", reason),
+			observability.Bool("disabled", staleRule.Disabled))
+
+		report.StaleRules = append(report.StaleRules, staleRule)
+	}
+
+	if report.DisabledCount > 0 {
+		s.updateRuleCounts(ctx)
+	}
+
+	return report, nil
+}
+
+// DefaultStaleRuleDays 默认的规则空闲天数阈值
+func (s *ErrorInjectorService) DefaultStaleRuleDays() int {
+	return s.config.ErrorEngine.StaleRuleDays
+}
+
+// StartStaleRuleChecker 启动后台过期规则检测
+func (s *ErrorInjectorService) StartStaleRuleChecker(ctx context.Context) {
+	interval := time.Duration(s.config.ErrorEngine.StaleCheckIntervalMins) * time.Minute
+	if interval <= 0 {
+		return
 	}
+	idleThreshold := time.Duration(s.config.ErrorEngine.StaleRuleDays) * 24 * time.Hour
 
-	return action, shouldInject
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.GetStaleRules(ctx, idleThreshold, s.config.ErrorEngine.AutoDisableStaleRules); err != nil {
+					s.logger.Warn(ctx, "Stale rule check failed",
+						observability.String("error", err.Error()))
+				}
+			}
+		}
+	}()
 }
 
 // InjectError 执行错误注入
@@ -230,8 +563,12 @@ func (s *ErrorInjectorService) InjectError(ctx context.Context, action *models.E
 	switch action.Type {
 	case models.ErrorActionTypeDelay:
 		return s.injectDelay(ctx, action)
-	case models.ErrorActionTypeHTTPError:
-		// HTTP错误由中间件处理
+	case models.ErrorActionTypeHTTPError, models.ErrorActionTypeThrottle, models.ErrorActionTypeSlowDrip,
+		models.ErrorActionTypeDisconnect, models.ErrorActionTypeCPUPressure, models.ErrorActionTypeMemoryPressure:
+		// HTTP错误、限速、滴流、断开和资源压力由目标服务的中间件处理
+		return nil
+	case models.ErrorActionTypeDNSFailure, models.ErrorActionTypeConnectionRefused, models.ErrorActionTypeTLSHandshakeError:
+		// 连接级故障由目标服务出站HTTP客户端的传输层处理
 		return nil
 	case models.ErrorActionTypeNetworkError:
 		return s.injectNetworkError(ctx, action)
@@ -296,20 +633,31 @@ func (s *ErrorInjectorService) validateRule(rule *models.ErrorRule) error {
 		return fmt.Errorf("rule name is required")
 	}
 
+	if err := ValidateNamespace(rule.Namespace); err != nil {
+		return err
+	}
+
 	if rule.Action.Type == "" {
 		return fmt.Errorf("action type is required")
 	}
 
 	// 验证动作类型
 	validActionTypes := map[string]bool{
-		models.ErrorActionTypeHTTPError:     true,
-		models.ErrorActionTypeNetworkError:  true,
-		models.ErrorActionTypeTimeout:       true,
-		models.ErrorActionTypeDelay:         true,
-		models.ErrorActionTypeCorruption:    true,
-		models.ErrorActionTypeDisconnect:    true,
-		models.ErrorActionTypeDatabaseError: true,
-		models.ErrorActionTypeStorageError:  true,
+		models.ErrorActionTypeHTTPError:         true,
+		models.ErrorActionTypeNetworkError:      true,
+		models.ErrorActionTypeTimeout:           true,
+		models.ErrorActionTypeDelay:             true,
+		models.ErrorActionTypeCorruption:        true,
+		models.ErrorActionTypeDisconnect:        true,
+		models.ErrorActionTypeDatabaseError:     true,
+		models.ErrorActionTypeStorageError:      true,
+		models.ErrorActionTypeThrottle:          true,
+		models.ErrorActionTypeSlowDrip:          true,
+		models.ErrorActionTypeCPUPressure:       true,
+		models.ErrorActionTypeMemoryPressure:    true,
+		models.ErrorActionTypeDNSFailure:        true,
+		models.ErrorActionTypeConnectionRefused: true,
+		models.ErrorActionTypeTLSHandshakeError: true,
 	}
 
 	if !validActionTypes[rule.Action.Type] {
@@ -323,6 +671,79 @@ func (s *ErrorInjectorService) validateRule(rule *models.ErrorRule) error {
 		}
 	}
 
+	// 验证限速带宽
+	if rule.Action.Type == models.ErrorActionTypeThrottle && rule.Action.BytesPerSecond <= 0 {
+		return fmt.Errorf("throttle action requires positive bytes_per_second")
+	}
+
+	// 验证滴流
+	if rule.Action.Type == models.ErrorActionTypeSlowDrip {
+		if rule.Action.BytesPerSecond <= 0 {
+			return fmt.Errorf("slow_drip action requires positive bytes_per_second")
+		}
+		if rule.Action.AbortAfterBytes < 0 {
+			return fmt.Errorf("invalid abort_after_bytes: %d", rule.Action.AbortAfterBytes)
+		}
+	}
+
+	// 验证断开
+	if rule.Action.Type == models.ErrorActionTypeDisconnect && (rule.Action.AbortAfterBytes < 0 || rule.Action.BytesPerSecond < 0) {
+		return fmt.Errorf("disconnect action requires non-negative abort_after_bytes and bytes_per_second")
+	}
+
+	// 验证资源压力
+	if rule.Action.Type == models.ErrorActionTypeCPUPressure || rule.Action.Type == models.ErrorActionTypeMemoryPressure {
+		if err := s.validatePressure(&rule.Action); err != nil {
+			return err
+		}
+	}
+
+	// 验证执行方式
+	switch rule.Executor {
+	case "", models.RuleExecutorInline:
+	case models.RuleExecutorToxiproxy:
+		if err := s.validateToxiproxyRule(rule); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid executor: %s", rule.Executor)
+	}
+
+	// 验证调度
+	if rule.Schedule != nil {
+		if err := ValidateSchedule(rule.Schedule); err != nil {
+			return err
+		}
+	}
+
+	// 验证概率爬升
+	if rule.Ramp != nil {
+		if err := ValidateRamp(rule.Ramp); err != nil {
+			return err
+		}
+	}
+
+	// 验证连续失败
+	if rule.Burst != nil {
+		if err := ValidateBurst(rule.Burst); err != nil {
+			return err
+		}
+	}
+
+	// 验证webhook
+	for i, webhook := range rule.Webhooks {
+		if err := ValidateWebhook(webhook); err != nil {
+			return fmt.Errorf("invalid webhook %d: %w", i, err)
+		}
+	}
+
+	// 验证条件
+	for i, condition := range rule.Conditions {
+		if err := ValidateCondition(condition); err != nil {
+			return fmt.Errorf("invalid condition %d: %w", i, err)
+		}
+	}
+
 	// 验证延迟时间
 	if rule.Action.Delay != nil {
 		maxDelay := time.Duration(s.config.Injection.MaxDelayMs) * time.Millisecond
@@ -331,9 +752,80 @@ func (s *ErrorInjectorService) validateRule(rule *models.ErrorRule) error {
 		}
 	}
 
+	// 验证延迟分布
+	if rule.Action.Latency != nil {
+		if err := ValidateLatency(rule.Action.Latency); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// validatePressure 验证资源压力动作的持续时间和分配量不超过配置的上限
+func (s *ErrorInjectorService) validatePressure(action *models.ErrorAction) error {
+	if action.Duration == nil || *action.Duration <= 0 {
+		return fmt.Errorf("%s action requires positive duration", action.Type)
+	}
+	if maxDuration := time.Duration(s.config.Injection.MaxPressureMs) * time.Millisecond; *action.Duration > maxDuration {
+		return fmt.Errorf("pressure duration exceeds maximum allowed: %v", maxDuration)
+	}
+
+	if action.Type == models.ErrorActionTypeCPUPressure {
+		if action.CPUCores < 0 {
+			return fmt.Errorf("invalid cpu_cores: %d", action.CPUCores)
+		}
+		return nil
+	}
+	if action.MemoryBytes <= 0 {
+		return fmt.Errorf("memory_pressure action requires positive memory_bytes")
+	}
+	if maxBytes := int64(s.config.Injection.MaxPressureMemoryMB) << 20; action.MemoryBytes > maxBytes {
+		return fmt.Errorf("memory_bytes exceeds maximum allowed: %d", maxBytes)
+	}
+	return nil
+}
+
+// ValidateLatency 验证延迟分布参数
+func ValidateLatency(latency *models.LatencyDistribution) error {
+	switch latency.Type {
+	case models.LatencyDistributionNormal:
+		if latency.MeanMs <= 0 || latency.StddevMs < 0 {
+			return fmt.Errorf("normal latency requires positive mean_ms and non-negative stddev_ms")
+		}
+	case models.LatencyDistributionExponential:
+		if latency.MeanMs <= 0 {
+			return fmt.Errorf("exponential latency requires positive mean_ms")
+		}
+	case models.LatencyDistributionPareto:
+		if latency.ScaleMs <= 0 || latency.Shape <= 0 {
+			return fmt.Errorf("pareto latency requires positive scale_ms and shape")
+		}
+	default:
+		return fmt.Errorf("invalid latency distribution: %s", latency.Type)
+	}
+
+	if latency.MinMs < 0 || latency.MaxMs < 0 {
+		return fmt.Errorf("latency bounds must not be negative")
+	}
+	if latency.MaxMs > 0 && latency.MaxMs < latency.MinMs {
+		return fmt.Errorf("latency max_ms must not be less than min_ms")
+	}
+	return nil
+}
+
+// sampleLatency 按延迟分布采样，返回带有本次Delay的动作副本，不超过允许的最大延迟
+func (s *ErrorInjectorService) sampleLatency(action *models.ErrorAction) *models.ErrorAction {
+	delay := action.Latency.Sample(nil)
+	if maxDelay := time.Duration(s.config.Injection.MaxDelayMs) * time.Millisecond; delay > maxDelay {
+		delay = maxDelay
+	}
+
+	sampled := *action
+	sampled.Delay = &delay
+	return &sampled
+}
+
 // extractMetadata 从上下文提取元数据
 func (s *ErrorInjectorService) extractMetadata(ctx context.Context) map[string]string {
 	metadata := make(map[string]string)
@@ -341,11 +833,11 @@ func (s *ErrorInjectorService) extractMetadata(ctx context.Context) map[string]s
 	// 从上下文中提取信息（根据实际需要实现）
 	// 这里是示例实现
 	if userAgent := ctx.Value("user_agent"); userAgent != nil {
-		metadata["user_agent"] = fmt.Sprintf("%v", userAgent)
+		metadata[models.ErrorMetadataUserAgent] = fmt.Sprintf("%v", userAgent)
 	}
 
 	if remoteAddr := ctx.Value("remote_addr"); remoteAddr != nil {
-		metadata["remote_addr"] = fmt.Sprintf("%v", remoteAddr)
+		metadata[models.ErrorMetadataRemoteAddr] = fmt.Sprintf("%v", remoteAddr)
 	}
 
 	return metadata
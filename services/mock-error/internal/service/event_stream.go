@@ -0,0 +1,167 @@
+package service
+
+import (
+	"mocks3/shared/models"
+	"mocks3/shared/utils"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// eventStreamBuffer 每个订阅者缓存的事件数，订阅者消费不及时时丢弃新事件
+const eventStreamBuffer = 256
+
+// EventFilter 事件订阅过滤条件，为空的字段不过滤
+type EventFilter struct {
+	Namespace string
+	Service   string
+	RuleID    string
+}
+
+// matches 检查事件是否满足过滤条件
+func (f EventFilter) matches(event *models.ErrorEvent) bool {
+	if f.Namespace != "" && models.RuleNamespace(f.Namespace) != event.Namespace {
+		return false
+	}
+	if f.Service != "" && f.Service != event.Service {
+		return false
+	}
+	return f.RuleID == "" || f.RuleID == event.RuleID
+}
+
+// EventSubscription 错误注入事件订阅，服务关闭时Events被关闭
+type EventSubscription struct {
+	Events  <-chan *models.ErrorEvent
+	events  chan *models.ErrorEvent
+	filter  EventFilter
+	dropped atomic.Int64
+}
+
+// Dropped 返回上次调用以来因消费不及时丢弃的事件数
+func (s *EventSubscription) Dropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+// eventBroadcaster 把错误注入事件实时推送给订阅者
+type eventBroadcaster struct {
+	mu          sync.Mutex
+	subscribers map[*EventSubscription]struct{}
+	closed      bool
+}
+
+// newEventBroadcaster 创建事件广播器
+func newEventBroadcaster() *eventBroadcaster {
+	return &eventBroadcaster{
+		subscribers: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// subscribe 添加订阅者，广播器已关闭时返回的订阅立即结束
+func (b *eventBroadcaster) subscribe(filter EventFilter) *EventSubscription {
+	events := make(chan *models.ErrorEvent, eventStreamBuffer)
+	subscription := &EventSubscription{Events: events, events: events, filter: filter}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(events)
+		return subscription
+	}
+	b.subscribers[subscription] = struct{}{}
+	return subscription
+}
+
+// unsubscribe 移除订阅者
+func (b *eventBroadcaster) unsubscribe(subscription *EventSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, exists := b.subscribers[subscription]; exists {
+		delete(b.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// publish 推送事件，不阻塞注入检查
+func (b *eventBroadcaster) publish(event *models.ErrorEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for subscription := range b.subscribers {
+		if !subscription.filter.matches(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.dropped.Add(1)
+		}
+	}
+}
+
+// close 结束所有订阅，之后的订阅立即结束
+func (b *eventBroadcaster) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for subscription := range b.subscribers {
+		delete(b.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// SubscribeEvents 订阅实时的错误注入事件（包括试运行命中），使用后需调用UnsubscribeEvents
+func (s *ErrorInjectorService) SubscribeEvents(filter EventFilter) *EventSubscription {
+	return s.eventStream.subscribe(filter)
+}
+
+// UnsubscribeEvents 取消事件订阅
+func (s *ErrorInjectorService) UnsubscribeEvents(subscription *EventSubscription) {
+	s.eventStream.unsubscribe(subscription)
+}
+
+// CloseEventStreams 结束所有事件订阅，服务关闭前调用，避免长连接阻塞关闭
+func (s *ErrorInjectorService) CloseEventStreams() {
+	s.eventStream.close()
+}
+
+// newInjectionEvent 创建规则命中事件，记录受影响请求的信息
+func newInjectionEvent(rule *models.ErrorRule, namespace, service, operation string, action *models.ErrorAction, metadata map[string]string) *models.ErrorEvent {
+	event := &models.ErrorEvent{
+		ID:          utils.NewID(),
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Namespace:   models.RuleNamespace(namespace),
+		Service:     service,
+		Operation:   operation,
+		Action:      *action,
+		UserAgent:   metadata[models.ErrorMetadataUserAgent],
+		RemoteAddr:  metadata[models.ErrorMetadataRemoteAddr],
+		RequestHash: RequestHash(metadata),
+		Timestamp:   time.Now(),
+	}
+
+	// 请求头只保留请求ID，避免认证信息出现在事件中
+	for key, value := range metadata {
+		if strings.EqualFold(key, models.ErrorMetadataHeaderPrefix+"X-Request-Id") {
+			event.RequestID = value
+			break
+		}
+	}
+	for _, key := range []string{
+		models.ErrorMetadataPath,
+		models.ErrorMetadataTraceID,
+		models.ErrorMetadataUserID,
+		models.ErrorMetadataTenant,
+		models.ErrorMetadataObjectKey,
+		models.ErrorMetadataTestRunID,
+		models.ErrorMetadataScenarioID,
+	} {
+		if value, ok := metadata[key]; ok && value != "" {
+			if event.Params == nil {
+				event.Params = make(map[string]interface{})
+			}
+			event.Params[key] = value
+		}
+	}
+	return event
+}
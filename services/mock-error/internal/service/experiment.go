@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mocks3/services/mock-error/internal/repository"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 稳态探针默认配置
+const (
+	defaultProbeInterval = 10 * time.Second
+	defaultProbeTimeout  = 5 * time.Second
+)
+
+// experimentRunner 运行中实验的后台检查
+type experimentRunner struct {
+	repo   *repository.ExperimentRepository
+	client *http.Client
+
+	mu      sync.Mutex // 串行化实验状态变更
+	cancels map[string]context.CancelFunc
+}
+
+// newExperimentRunner 创建实验运行器
+func newExperimentRunner() *experimentRunner {
+	return &experimentRunner{
+		repo:    repository.NewExperimentRepository(),
+		client:  &http.Client{},
+		cancels: make(map[string]context.CancelFunc),
+	}
+}
+
+// StartExperiment 开始混沌实验：先确认稳态，再创建场景规则并在后台持续检查探针
+//
+// 注入前稳态不满足时实验记为failed且不创建规则；规则创建失败时禁用已创建的规则并返回错误。
+func (s *ErrorInjectorService) StartExperiment(ctx context.Context, experiment *models.Experiment) (*models.Experiment, error) {
+	if err := s.validateExperiment(experiment); err != nil {
+		return nil, fmt.Errorf("invalid experiment: %w", err)
+	}
+
+	experiment.ID = utils.NewID()
+	experiment.Status = models.ExperimentStatusRunning
+	experiment.Reason = ""
+	experiment.RuleIDs = nil
+	experiment.EndedAt = nil
+	experiment.CreatedBy = models.AuditActorFromContext(ctx).Name
+	experiment.StartedAt = time.Now()
+
+	s.logger.Info(ctx, "Starting experiment",
+		observability.String("experiment_id", experiment.ID),
+		observability.String("experiment_name", experiment.Name))
+
+	// 注入前确认稳态
+	experiment.ProbeResults = s.runProbes(ctx, experiment.Probes, nil)
+	for _, result := range experiment.ProbeResults {
+		if !result.Success {
+			s.endExperiment(experiment, models.ExperimentStatusFailed,
+				fmt.Sprintf("steady state not met before injection: probe %s: %s", result.Probe, result.Error))
+			return experiment, s.experiments.repo.Save(ctx, experiment)
+		}
+	}
+
+	for _, rule := range experiment.Rules {
+		experimentRule := *rule
+		experimentRule.ID = ""
+		experimentRule.Enabled = true
+		experimentRule.Triggered = 0
+		experimentRule.Metadata = make(map[string]string, len(rule.Metadata)+1)
+		for key, value := range rule.Metadata {
+			experimentRule.Metadata[key] = value
+		}
+		experimentRule.Metadata[models.ExperimentMetadataKey] = experiment.ID
+
+		if err := s.AddErrorRule(ctx, &experimentRule); err != nil {
+			s.disableExperimentRules(ctx, experiment)
+			s.endExperiment(experiment, models.ExperimentStatusFailed, fmt.Sprintf("failed to create rule %s: %v", rule.Name, err))
+			s.experiments.repo.Save(ctx, experiment)
+			return nil, fmt.Errorf("failed to create experiment rule %s: %w", rule.Name, err)
+		}
+		experiment.RuleIDs = append(experiment.RuleIDs, experimentRule.ID)
+	}
+
+	if err := s.experiments.repo.Save(ctx, experiment); err != nil {
+		return nil, fmt.Errorf("failed to save experiment: %w", err)
+	}
+
+	monitorCtx, cancel := context.WithCancel(context.Background())
+	s.experiments.mu.Lock()
+	s.experiments.cancels[experiment.ID] = cancel
+	s.experiments.mu.Unlock()
+	go s.monitorExperiment(monitorCtx, experiment.ID)
+
+	return experiment, nil
+}
+
+// StopExperiment 手动停止运行中的实验并禁用其规则
+func (s *ErrorInjectorService) StopExperiment(ctx context.Context, experimentID string) (*models.Experiment, error) {
+	experiment, err := s.finishExperiment(ctx, experimentID, models.ExperimentStatusAborted, "stopped manually")
+	if err != nil {
+		return nil, err
+	}
+	if experiment == nil {
+		return nil, fmt.Errorf("experiment is not running: %s", experimentID)
+	}
+	return experiment, nil
+}
+
+// StopExperiments 停止所有运行中的实验，服务关闭时调用，避免规则在实验结束后继续生效
+func (s *ErrorInjectorService) StopExperiments(ctx context.Context) {
+	s.experiments.mu.Lock()
+	ids := make([]string, 0, len(s.experiments.cancels))
+	for id := range s.experiments.cancels {
+		ids = append(ids, id)
+	}
+	s.experiments.mu.Unlock()
+
+	for _, id := range ids {
+		if _, err := s.finishExperiment(ctx, id, models.ExperimentStatusAborted, "service shutting down"); err != nil {
+			s.logger.Warn(ctx, "Failed to stop experiment",
+				observability.String("experiment_id", id),
+				observability.String("error", err.Error()))
+		}
+	}
+}
+
+// GetExperiment 获取实验
+func (s *ErrorInjectorService) GetExperiment(ctx context.Context, experimentID string) (*models.Experiment, error) {
+	return s.experiments.repo.Get(ctx, experimentID)
+}
+
+// ListExperiments 列出实验
+func (s *ErrorInjectorService) ListExperiments(ctx context.Context) ([]*models.Experiment, error) {
+	return s.experiments.repo.List(ctx)
+}
+
+// monitorExperiment 按间隔检查探针，稳态被破坏时回滚，持续时间结束时完成
+func (s *ErrorInjectorService) monitorExperiment(ctx context.Context, experimentID string) {
+	experiment, err := s.experiments.repo.Get(ctx, experimentID)
+	if err != nil {
+		return
+	}
+
+	interval := time.Duration(experiment.ProbeIntervalSecs) * time.Second
+	if interval <= 0 {
+		interval = defaultProbeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var deadline <-chan time.Time
+	if experiment.DurationSecs > 0 {
+		timer := time.NewTimer(time.Duration(experiment.DurationSecs) * time.Second)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	// 规则状态变更记到实验名下
+	auditCtx := models.WithAuditActor(context.Background(), models.AuditActor{Name: "experiment:" + experimentID})
+	results := experiment.ProbeResults
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			s.finishExperiment(auditCtx, experimentID, models.ExperimentStatusCompleted, "")
+			return
+		case <-ticker.C:
+			results = s.runProbes(ctx, experiment.Probes, results)
+			if !s.updateProbeResults(ctx, experimentID, results) {
+				return
+			}
+
+			for i, result := range results {
+				if !result.Success && result.ConsecutiveFailures > experiment.Probes[i].Tolerance {
+					s.finishExperiment(auditCtx, experimentID, models.ExperimentStatusRolledBack,
+						fmt.Sprintf("probe %s failed %d times: %s", result.Probe, result.ConsecutiveFailures, result.Error))
+					return
+				}
+			}
+		}
+	}
+}
+
+// updateProbeResults 保存探针结果，实验已结束时返回false
+func (s *ErrorInjectorService) updateProbeResults(ctx context.Context, experimentID string, results []*models.ProbeResult) bool {
+	s.experiments.mu.Lock()
+	defer s.experiments.mu.Unlock()
+
+	experiment, err := s.experiments.repo.Get(ctx, experimentID)
+	if err != nil || experiment.Status != models.ExperimentStatusRunning {
+		return false
+	}
+	experiment.ProbeResults = results
+	return s.experiments.repo.Save(ctx, experiment) == nil
+}
+
+// finishExperiment 结束运行中的实验并禁用其规则，实验已结束时返回nil
+func (s *ErrorInjectorService) finishExperiment(ctx context.Context, experimentID, status, reason string) (*models.Experiment, error) {
+	s.experiments.mu.Lock()
+	defer s.experiments.mu.Unlock()
+
+	experiment, err := s.experiments.repo.Get(ctx, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Status != models.ExperimentStatusRunning {
+		return nil, nil
+	}
+
+	if cancel, exists := s.experiments.cancels[experimentID]; exists {
+		cancel()
+		delete(s.experiments.cancels, experimentID)
+	}
+
+	s.disableExperimentRules(ctx, experiment)
+	s.endExperiment(experiment, status, reason)
+
+	logFields := []observability.Field{
+		observability.String("experiment_id", experimentID),
+		observability.String("status", status),
+	}
+	if status == models.ExperimentStatusRolledBack {
+		s.logger.Warn(ctx, "Experiment rolled back", append(logFields, observability.String("reason", reason))...)
+	} else {
+		s.logger.Info(ctx, "Experiment finished", logFields...)
+	}
+
+	if err := s.experiments.repo.Save(ctx, experiment); err != nil {
+		return nil, fmt.Errorf("failed to save experiment: %w", err)
+	}
+	return experiment, nil
+}
+
+// endExperiment 设置实验的结束状态
+func (s *ErrorInjectorService) endExperiment(experiment *models.Experiment, status, reason string) {
+	now := time.Now()
+	experiment.Status = status
+	experiment.Reason = reason
+	experiment.EndedAt = &now
+}
+
+// disableExperimentRules 禁用实验创建的规则，保留规则和触发记录供复盘
+func (s *ErrorInjectorService) disableExperimentRules(ctx context.Context, experiment *models.Experiment) {
+	for _, ruleID := range experiment.RuleIDs {
+		rule, err := s.GetErrorRule(ctx, ruleID)
+		if err != nil || !rule.Enabled {
+			continue
+		}
+		rule.Enabled = false
+		if err := s.UpdateErrorRule(ctx, rule); err != nil {
+			s.logger.Error(ctx, "Failed to disable experiment rule",
+				observability.String("experiment_id", experiment.ID),
+				observability.String("rule_id", ruleID),
+				observability.String("error", err.Error()))
+		}
+	}
+}
+
+// runProbes 依次检查探针，previous为上一轮结果，用于累计连续失败次数
+func (s *ErrorInjectorService) runProbes(ctx context.Context, probes []*models.SteadyStateProbe, previous []*models.ProbeResult) []*models.ProbeResult {
+	results := make([]*models.ProbeResult, len(probes))
+	for i, probe := range probes {
+		result := s.checkProbe(ctx, probe)
+		if !result.Success {
+			result.ConsecutiveFailures = 1
+			if i < len(previous) {
+				result.ConsecutiveFailures += previous[i].ConsecutiveFailures
+			}
+		}
+		results[i] = result
+	}
+	return results
+}
+
+// checkProbe 检查一个稳态探针
+func (s *ErrorInjectorService) checkProbe(ctx context.Context, probe *models.SteadyStateProbe) *models.ProbeResult {
+	result := &models.ProbeResult{Probe: probe.Name, CheckedAt: time.Now()}
+
+	timeout := time.Duration(probe.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method := probe.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, probe.URL, nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	for key, value := range probe.Headers {
+		req.Header.Set(key, value)
+	}
+
+	start := time.Now()
+	resp, err := s.experiments.client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read response: %v", err)
+		return result
+	}
+
+	switch probe.Type {
+	case models.ProbeTypeHTTP:
+		if !expectedProbeStatus(probe, resp.StatusCode) {
+			result.Error = fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
+			return result
+		}
+		if probe.MaxLatencyMs > 0 && result.LatencyMs > int64(probe.MaxLatencyMs) {
+			result.Error = fmt.Sprintf("latency %dms exceeds %dms", result.LatencyMs, probe.MaxLatencyMs)
+			return result
+		}
+	case models.ProbeTypeMetric:
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			result.Error = fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
+			return result
+		}
+		value, err := metricValue(body, probe.Field)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Value = &value
+		if probe.Min != nil && value < *probe.Min {
+			result.Error = fmt.Sprintf("%s is %g, below minimum %g", probe.Field, value, *probe.Min)
+			return result
+		}
+		if probe.Max != nil && value > *probe.Max {
+			result.Error = fmt.Sprintf("%s is %g, above maximum %g", probe.Field, value, *probe.Max)
+			return result
+		}
+	}
+
+	result.Success = true
+	return result
+}
+
+// expectedProbeStatus 状态码是否符合探针预期，未配置时要求2xx
+func expectedProbeStatus(probe *models.SteadyStateProbe, statusCode int) bool {
+	if len(probe.ExpectedStatus) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+	for _, expected := range probe.ExpectedStatus {
+		if statusCode == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// metricValue 按以.分隔的路径读取JSON中的数值
+func metricValue(body []byte, field string) (float64, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return 0, fmt.Errorf("invalid JSON response: %w", err)
+	}
+
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return 0, fmt.Errorf("field %s not found", field)
+		}
+		if value, ok = object[key]; !ok {
+			return 0, fmt.Errorf("field %s not found", field)
+		}
+	}
+
+	number, ok := value.(float64)
+	if !ok {
+		return 0, fmt.Errorf("field %s is not a number", field)
+	}
+	return number, nil
+}
+
+// validateExperiment 验证实验配置和场景规则
+func (s *ErrorInjectorService) validateExperiment(experiment *models.Experiment) error {
+	if experiment.Name == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	if len(experiment.Rules) == 0 {
+		return fmt.Errorf("at least one rule is required")
+	}
+	if len(experiment.Probes) == 0 {
+		return fmt.Errorf("at least one steady-state probe is required")
+	}
+	if experiment.DurationSecs < 0 {
+		return fmt.Errorf("invalid duration: %ds", experiment.DurationSecs)
+	}
+	if experiment.ProbeIntervalSecs < 0 {
+		return fmt.Errorf("invalid probe interval: %ds", experiment.ProbeIntervalSecs)
+	}
+
+	for i, rule := range experiment.Rules {
+		if err := s.validateRule(rule); err != nil {
+			return fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+	}
+	for i, probe := range experiment.Probes {
+		if probe.Name == "" {
+			probe.Name = fmt.Sprintf("probe-%d", i)
+		}
+		if err := ValidateProbe(probe); err != nil {
+			return fmt.Errorf("invalid probe %s: %w", probe.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateProbe 验证稳态探针
+func ValidateProbe(probe *models.SteadyStateProbe) error {
+	u, err := url.Parse(probe.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url: %q", probe.URL)
+	}
+	if probe.TimeoutMs < 0 {
+		return fmt.Errorf("invalid timeout: %dms", probe.TimeoutMs)
+	}
+	if probe.Tolerance < 0 {
+		return fmt.Errorf("invalid tolerance: %d", probe.Tolerance)
+	}
+
+	switch probe.Type {
+	case models.ProbeTypeHTTP:
+		if probe.MaxLatencyMs < 0 {
+			return fmt.Errorf("invalid max_latency_ms: %d", probe.MaxLatencyMs)
+		}
+	case models.ProbeTypeMetric:
+		if probe.Field == "" {
+			return fmt.Errorf("field is required for metric probe")
+		}
+		if probe.Min == nil && probe.Max == nil {
+			return fmt.Errorf("min or max is required for metric probe")
+		}
+		if probe.Min != nil && probe.Max != nil && *probe.Min > *probe.Max {
+			return fmt.Errorf("min %g is greater than max %g", *probe.Min, *probe.Max)
+		}
+	default:
+		return fmt.Errorf("unsupported probe type: %s", probe.Type)
+	}
+	return nil
+}
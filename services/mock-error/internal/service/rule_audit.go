@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/services/mock-error/internal/repository"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// validRuleAuditActions 可查询的审计操作
+var validRuleAuditActions = map[string]bool{
+	models.RuleAuditCreate:  true,
+	models.RuleAuditUpdate:  true,
+	models.RuleAuditDelete:  true,
+	models.RuleAuditEnable:  true,
+	models.RuleAuditDisable: true,
+}
+
+// ruleAuditIgnoredFields 审计差异中忽略的运行时字段
+var ruleAuditIgnoredFields = map[string]bool{
+	"triggered":         true,
+	"last_triggered_at": true,
+	"created_at":        true,
+	"updated_at":        true,
+}
+
+// SetRuleAuditRepository 设置规则审计仓库，默认只在内存中保留最近的记录
+func (s *ErrorInjectorService) SetRuleAuditRepository(auditRepo *repository.RuleAuditRepository) {
+	s.auditRepo = auditRepo
+}
+
+// SetAuditLogger 设置审计日志器，规则变更同时写入独立的审计日志
+func (s *ErrorInjectorService) SetAuditLogger(auditLog *observability.AuditLogger) {
+	s.auditLog = auditLog
+}
+
+// ListRuleAudit 按条件分页查询规则变更审计记录
+func (s *ErrorInjectorService) ListRuleAudit(ctx context.Context, query *models.RuleAuditQuery) (*models.RuleAuditPage, error) {
+	if query.Action != "" && !validRuleAuditActions[query.Action] {
+		return nil, fmt.Errorf("%w: audit action %s", models.ErrInvalidArgument, query.Action)
+	}
+	if query.After < 0 {
+		return nil, fmt.Errorf("%w: after %d must not be negative", models.ErrInvalidArgument, query.After)
+	}
+	if query.Since != nil && query.Until != nil && !query.Until.After(*query.Since) {
+		return nil, fmt.Errorf("%w: until must be after since", models.ErrInvalidArgument)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	// 多取一条判断是否还有下一页
+	paged := *query
+	paged.Limit = limit + 1
+	entries, err := s.auditRepo.List(ctx, &paged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rule audit: %w", err)
+	}
+
+	page := &models.RuleAuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.IsTruncated = true
+		page.NextAfter = page.Entries[limit-1].ID
+	}
+	return page, nil
+}
+
+// recordRuleAudit 记录一次规则变更，before为nil表示创建，after为nil表示删除；失败时只记录日志
+func (s *ErrorInjectorService) recordRuleAudit(ctx context.Context, before, after *models.ErrorRule) {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+
+	entry := &models.RuleAuditEntry{
+		RuleID:    subject.ID,
+		RuleName:  subject.Name,
+		Actor:     models.AuditActorFromContext(ctx),
+		Before:    snapshotRule(before),
+		After:     snapshotRule(after),
+		CreatedAt: time.Now(),
+	}
+	// 创建和删除的完整规则已在快照中，只为修改记录字段差异
+	if before != nil && after != nil {
+		entry.Changes = ruleChanges(entry.Before, entry.After)
+	}
+
+	switch {
+	case before == nil:
+		entry.Action = models.RuleAuditCreate
+	case after == nil:
+		entry.Action = models.RuleAuditDelete
+	case len(entry.Changes) == 1 && entry.Changes[0].Field == "enabled" && after.Enabled:
+		entry.Action = models.RuleAuditEnable
+	case len(entry.Changes) == 1 && entry.Changes[0].Field == "enabled":
+		entry.Action = models.RuleAuditDisable
+	default:
+		entry.Action = models.RuleAuditUpdate
+	}
+
+	if err := s.auditRepo.Append(ctx, entry); err != nil {
+		s.logger.Warn(ctx, "Failed to record rule audit",
+			observability.String("rule_id", entry.RuleID),
+			observability.String("action", entry.Action),
+			observability.String("error", err.Error()))
+	}
+
+	details := map[string]any{"rule_name": entry.RuleName}
+	if len(entry.Changes) > 0 {
+		details["changes"] = entry.Changes
+	}
+	s.auditLog.Record(ctx, &observability.AuditLogEntry{
+		Category: observability.AuditCategoryRuleChange,
+		Action:   entry.Action,
+		Resource: "rules/" + entry.RuleID,
+		Details:  details,
+	})
+}
+
+// snapshotRule 复制规则，避免审计记录随共享的规则变化
+func snapshotRule(rule *models.ErrorRule) *models.ErrorRule {
+	if rule == nil {
+		return nil
+	}
+	snapshot := *rule
+	return &snapshot
+}
+
+// ruleChanges 比较规则的顶层JSON字段，按字段名排序
+func ruleChanges(before, after *models.ErrorRule) []*models.RuleFieldChange {
+	beforeFields := ruleFields(before)
+	afterFields := ruleFields(after)
+
+	names := make(map[string]bool, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names[name] = true
+	}
+	for name := range afterFields {
+		names[name] = true
+	}
+
+	var changes []*models.RuleFieldChange
+	for name := range names {
+		if ruleAuditIgnoredFields[name] || reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+			continue
+		}
+		changes = append(changes, &models.RuleFieldChange{
+			Field:  name,
+			Before: beforeFields[name],
+			After:  afterFields[name],
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// ruleFields 规则的顶层JSON字段
+func ruleFields(rule *models.ErrorRule) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if rule == nil {
+		return fields
+	}
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
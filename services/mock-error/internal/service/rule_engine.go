@@ -11,45 +11,117 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/baggage"
 )
 
 // RuleEngine 错误规则引擎实现
+//
+// 引擎保存规则的副本，不与仓库共享；规则只会被整体替换，MatchRule返回的规则可以在锁外读取。
 type RuleEngine struct {
-	rules  map[string]*models.ErrorRule
-	logger *observability.Logger
+	rulesMu sync.RWMutex
+	rules   map[string]*models.ErrorRule
+	logger  *observability.Logger
 	rand   *rand.Rand
+
+	// every_nth条件的计数器，键为规则ID和条件序号；连续失败的剩余次数，键为规则ID、服务和操作
+	countersMu sync.Mutex
+	counters   map[string]int64
+	bursts     map[string]*burstState
+
+	// 关闭时忽略规则的调度配置
+	schedulingEnabled bool
+	cronCache         sync.Map // cron表达式 -> *cronWindow
+
+	// 全局随机种子，规则没有设置种子时使用，为nil时概率判定不可复现
+	seed atomic.Pointer[int64]
 }
 
 // NewRuleEngine 创建错误规则引擎
 func NewRuleEngine(logger *observability.Logger) *RuleEngine {
 	return &RuleEngine{
-		rules:  make(map[string]*models.ErrorRule),
-		logger: logger,
-		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		rules:    make(map[string]*models.ErrorRule),
+		logger:   logger,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		counters: make(map[string]int64),
+		bursts:   make(map[string]*burstState),
+
+		schedulingEnabled: true,
 	}
 }
 
+// SetRandomSeed 设置全局随机种子，为nil时清除
+func (e *RuleEngine) SetRandomSeed(seed *int64) {
+	if seed == nil {
+		e.seed.Store(nil)
+		return
+	}
+	value := *seed
+	e.seed.Store(&value)
+}
+
+// RandomSeed 返回全局随机种子，未设置时为nil
+func (e *RuleEngine) RandomSeed() *int64 {
+	if seed := e.seed.Load(); seed != nil {
+		value := *seed
+		return &value
+	}
+	return nil
+}
+
+// SetSchedulingEnabled 设置是否按规则的调度配置决定规则是否生效
+func (e *RuleEngine) SetSchedulingEnabled(enabled bool) {
+	e.schedulingEnabled = enabled
+}
+
 // EvaluateRules 评估规则
-func (e *RuleEngine) EvaluateRules(ctx context.Context, service, operation string, metadata map[string]string) (*models.ErrorAction, bool) {
+func (e *RuleEngine) EvaluateRules(ctx context.Context, namespace, service, operation string, metadata map[string]string) (*models.ErrorAction, bool) {
+	rule, matched := e.MatchRule(ctx, namespace, service, operation, metadata)
+	if !matched {
+		return nil, false
+	}
+	return &rule.Action, true
+}
+
+// MatchRule 评估命名空间中的规则并返回命中的规则
+func (e *RuleEngine) MatchRule(ctx context.Context, namespace, service, operation string, metadata map[string]string) (*models.ErrorRule, bool) {
 	// 按优先级获取匹配的规则
-	matchedRules := e.getMatchingRules(service, operation)
+	matchedRules := e.getMatchingRules(models.RuleNamespace(namespace), service, operation)
 
 	for _, rule := range matchedRules {
+		// Toxiproxy执行的规则在TCP层生效，不按请求命中
+		if rule.Executor == models.RuleExecutorToxiproxy {
+			continue
+		}
+
 		// 检查规则是否活跃
 		if !e.isRuleActive(rule) {
 			continue
 		}
 
-		// 评估条件
-		if e.evaluateConditions(rule.Conditions, metadata) {
+		// 连续失败未结束时直接命中
+		if e.consumeBurst(rule, service, operation) {
+			e.logger.Debug(ctx, "Rule matched in burst",
+				observability.String("rule_id", rule.ID),
+				observability.String("service", service),
+				observability.String("operation", operation))
+			return rule, true
+		}
+
+		// 评估条件，再按概率爬升的当前概率决定是否触发
+		requestHash := RequestHash(metadata)
+		if e.evaluateConditions(rule, operation, metadata, requestHash) && e.evaluateRamp(rule, requestHash) {
 			e.logger.Debug(ctx, "Rule matched",
 				observability.String("rule_id", rule.ID),
 				observability.String("rule_name", rule.Name),
 				observability.String("service", service),
 				observability.String("operation", operation))
 
-			return &rule.Action, true
+			e.startBurst(rule, service, operation)
+			return rule, true
 		}
 	}
 
@@ -62,7 +134,10 @@ func (e *RuleEngine) AddRule(rule *models.ErrorRule) error {
 		return fmt.Errorf("rule ID is required")
 	}
 
-	e.rules[rule.ID] = rule
+	ruleCopy := *rule
+	e.rulesMu.Lock()
+	e.rules[rule.ID] = &ruleCopy
+	e.rulesMu.Unlock()
 	e.logger.Debug(context.Background(), "Rule added", 
 		observability.String("rule_id", rule.ID), 
 		observability.String("rule_name", rule.Name))
@@ -71,11 +146,15 @@ func (e *RuleEngine) AddRule(rule *models.ErrorRule) error {
 
 // RemoveRule 移除规则
 func (e *RuleEngine) RemoveRule(ruleID string) error {
+	e.rulesMu.Lock()
 	if _, exists := e.rules[ruleID]; !exists {
+		e.rulesMu.Unlock()
 		return fmt.Errorf("rule not found: %s", ruleID)
 	}
-
 	delete(e.rules, ruleID)
+	e.rulesMu.Unlock()
+
+	e.resetCounters(ruleID)
 	e.logger.Debug(context.Background(), "Rule removed", 
 		observability.String("rule_id", ruleID))
 	return nil
@@ -83,20 +162,42 @@ func (e *RuleEngine) RemoveRule(ruleID string) error {
 
 // UpdateRule 更新规则
 func (e *RuleEngine) UpdateRule(rule *models.ErrorRule) error {
+	ruleCopy := *rule
+	e.rulesMu.Lock()
 	if _, exists := e.rules[rule.ID]; !exists {
+		e.rulesMu.Unlock()
 		return fmt.Errorf("rule not found: %s", rule.ID)
 	}
+	e.rules[rule.ID] = &ruleCopy
+	e.rulesMu.Unlock()
 
-	e.rules[rule.ID] = rule
+	e.resetCounters(rule.ID)
 	e.logger.Debug(context.Background(), "Rule updated", 
 		observability.String("rule_id", rule.ID), 
 		observability.String("rule_name", rule.Name))
 	return nil
 }
 
+// RecordTrigger 记录规则触发一次，返回累计触发次数；用替换副本的方式更新，已返回给调用方的规则不受影响
+func (e *RuleEngine) RecordTrigger(ruleID string) (int, bool) {
+	e.rulesMu.Lock()
+	defer e.rulesMu.Unlock()
+
+	rule, exists := e.rules[ruleID]
+	if !exists {
+		return 0, false
+	}
+	ruleCopy := *rule
+	ruleCopy.Triggered++
+	e.rules[ruleID] = &ruleCopy
+	return ruleCopy.Triggered, true
+}
+
 // GetRule 获取规则
 func (e *RuleEngine) GetRule(ruleID string) (*models.ErrorRule, error) {
+	e.rulesMu.RLock()
 	rule, exists := e.rules[ruleID]
+	e.rulesMu.RUnlock()
 	if !exists {
 		return nil, fmt.Errorf("rule not found: %s", ruleID)
 	}
@@ -108,6 +209,9 @@ func (e *RuleEngine) GetRule(ruleID string) (*models.ErrorRule, error) {
 
 // ListRules 列出所有规则
 func (e *RuleEngine) ListRules() []*models.ErrorRule {
+	e.rulesMu.RLock()
+	defer e.rulesMu.RUnlock()
+
 	rules := make([]*models.ErrorRule, 0, len(e.rules))
 	for _, rule := range e.rules {
 		ruleCopy := *rule
@@ -118,14 +222,16 @@ func (e *RuleEngine) ListRules() []*models.ErrorRule {
 }
 
 // getMatchingRules 获取匹配的规则
-func (e *RuleEngine) getMatchingRules(service, operation string) []*models.ErrorRule {
+func (e *RuleEngine) getMatchingRules(namespace, service, operation string) []*models.ErrorRule {
 	var matched []*models.ErrorRule
 
+	e.rulesMu.RLock()
 	for _, rule := range e.rules {
-		if e.isRuleMatching(rule, service, operation) {
+		if e.isRuleMatching(rule, namespace, service, operation) {
 			matched = append(matched, rule)
 		}
 	}
+	e.rulesMu.RUnlock()
 
 	// 按优先级排序
 	for i := 0; i < len(matched)-1; i++ {
@@ -139,8 +245,13 @@ func (e *RuleEngine) getMatchingRules(service, operation string) []*models.Error
 	return matched
 }
 
-// isRuleMatching 检查规则是否匹配服务和操作
-func (e *RuleEngine) isRuleMatching(rule *models.ErrorRule, service, operation string) bool {
+// isRuleMatching 检查规则是否匹配命名空间、服务和操作
+func (e *RuleEngine) isRuleMatching(rule *models.ErrorRule, namespace, service, operation string) bool {
+	// 检查命名空间匹配
+	if models.RuleNamespace(rule.Namespace) != namespace {
+		return false
+	}
+
 	// 检查服务匹配
 	if rule.Service != "" && rule.Service != service {
 		return false
@@ -167,8 +278,8 @@ func (e *RuleEngine) isRuleActive(rule *models.ErrorRule) bool {
 	}
 
 	// 检查时间调度
-	if rule.Schedule != nil {
-		if !e.isScheduleActive(rule.Schedule) {
+	if rule.Schedule != nil && e.schedulingEnabled {
+		if !e.isScheduleActive(rule) {
 			return false
 		}
 	}
@@ -177,7 +288,8 @@ func (e *RuleEngine) isRuleActive(rule *models.ErrorRule) bool {
 }
 
 // isScheduleActive 检查调度是否活跃
-func (e *RuleEngine) isScheduleActive(schedule *models.ErrorSchedule) bool {
+func (e *RuleEngine) isScheduleActive(rule *models.ErrorRule) bool {
+	schedule := rule.Schedule
 	now := time.Now()
 
 	// 检查时区
@@ -193,11 +305,19 @@ func (e *RuleEngine) isScheduleActive(schedule *models.ErrorSchedule) bool {
 		return false
 	}
 
-	// 检查结束时间
-	if schedule.EndTime != nil && now.After(*schedule.EndTime) {
+	// 检查结束时间和最长生效时间
+	if end := schedule.ExpiresAt(rule.CreatedAt); end != nil && now.After(*end) {
 		return false
 	}
 
+	// 检查cron窗口
+	if schedule.Cron != "" {
+		window, err := e.cronWindow(schedule.Cron)
+		if err != nil || !window.matches(now) {
+			return false
+		}
+	}
+
 	// 检查日期
 	if len(schedule.Days) > 0 {
 		dayName := strings.ToLower(now.Weekday().String())
@@ -231,15 +351,94 @@ func (e *RuleEngine) isScheduleActive(schedule *models.ErrorSchedule) bool {
 	return true
 }
 
+// evaluateRamp 按概率爬升的当前概率决定是否触发，没有配置时总是触发
+func (e *RuleEngine) evaluateRamp(rule *models.ErrorRule, requestHash string) bool {
+	if rule.Ramp == nil {
+		return true
+	}
+
+	probability := rule.Ramp.ProbabilityAt(rule.CreatedAt, time.Now())
+	if probability <= 0 {
+		return false
+	}
+	return probability >= 1 || e.draw(rule, rampDrawPath, requestHash) < probability
+}
+
+// burstState 一次连续失败的剩余次数
+type burstState struct {
+	remaining int
+	expiresAt time.Time // 零值表示不过期
+}
+
+// startBurst 规则触发后开始连续失败，第一次触发已计入次数
+func (e *RuleEngine) startBurst(rule *models.ErrorRule, service, operation string) {
+	if rule.Burst == nil || rule.Burst.Count <= 1 {
+		return
+	}
+
+	state := &burstState{remaining: rule.Burst.Count - 1}
+	if rule.Burst.WindowSecs > 0 {
+		state.expiresAt = time.Now().Add(time.Duration(rule.Burst.WindowSecs) * time.Second)
+	}
+
+	e.countersMu.Lock()
+	e.bursts[burstKey(rule.ID, service, operation)] = state
+	e.countersMu.Unlock()
+}
+
+// consumeBurst 消耗一次连续失败，没有进行中的连续失败时返回false
+func (e *RuleEngine) consumeBurst(rule *models.ErrorRule, service, operation string) bool {
+	if rule.Burst == nil {
+		return false
+	}
+
+	key := burstKey(rule.ID, service, operation)
+	e.countersMu.Lock()
+	defer e.countersMu.Unlock()
+
+	state, exists := e.bursts[key]
+	if !exists {
+		return false
+	}
+	if !state.expiresAt.IsZero() && time.Now().After(state.expiresAt) {
+		delete(e.bursts, key)
+		return false
+	}
+
+	state.remaining--
+	if state.remaining <= 0 {
+		delete(e.bursts, key)
+	}
+	return true
+}
+
+// burstKey 连续失败按规则、服务和操作分别计数
+func burstKey(ruleID, service, operation string) string {
+	return ruleID + "/" + service + "/" + operation
+}
+
+// cronWindow 解析并缓存cron表达式
+func (e *RuleEngine) cronWindow(expr string) (*cronWindow, error) {
+	if cached, ok := e.cronCache.Load(expr); ok {
+		return cached.(*cronWindow), nil
+	}
+	window, err := parseCronWindow(expr)
+	if err != nil {
+		return nil, err
+	}
+	e.cronCache.Store(expr, window)
+	return window, nil
+}
+
 // evaluateConditions 评估条件
-func (e *RuleEngine) evaluateConditions(conditions []models.ErrorCondition, metadata map[string]string) bool {
-	if len(conditions) == 0 {
+func (e *RuleEngine) evaluateConditions(rule *models.ErrorRule, operation string, metadata map[string]string, requestHash string) bool {
+	if len(rule.Conditions) == 0 {
 		return true
 	}
 
-	// 所有条件都必须满足（AND 逻辑）
-	for _, condition := range conditions {
-		if !e.evaluateCondition(condition, metadata) {
+	// 所有条件都必须满足（AND 逻辑），前面的条件不满足时不再评估后面的条件
+	for i, condition := range rule.Conditions {
+		if !e.evaluateCondition(rule, strconv.Itoa(i), condition, operation, metadata, requestHash) {
 			return false
 		}
 	}
@@ -247,11 +446,13 @@ func (e *RuleEngine) evaluateConditions(conditions []models.ErrorCondition, meta
 	return true
 }
 
-// evaluateCondition 评估单个条件
-func (e *RuleEngine) evaluateCondition(condition models.ErrorCondition, metadata map[string]string) bool {
+// evaluateCondition 评估单个条件，path是条件在条件树中的位置，如"0.1"
+func (e *RuleEngine) evaluateCondition(rule *models.ErrorRule, path string, condition models.ErrorCondition, operation string, metadata map[string]string, requestHash string) bool {
 	switch condition.Type {
+	case models.ErrorConditionTypeAnd, models.ErrorConditionTypeOr, models.ErrorConditionTypeNot:
+		return e.evaluateConditionGroup(rule, path, condition, operation, metadata, requestHash)
 	case models.ErrorConditionTypeProbability:
-		return e.evaluateProbabilityCondition(condition)
+		return e.evaluateProbabilityCondition(rule, path, condition, requestHash)
 	case models.ErrorConditionTypeHeader:
 		return e.evaluateHeaderCondition(condition, metadata)
 	case models.ErrorConditionTypeParam:
@@ -264,6 +465,20 @@ func (e *RuleEngine) evaluateCondition(condition models.ErrorCondition, metadata
 		return e.evaluateIPCondition(condition, metadata)
 	case models.ErrorConditionTypeCount:
 		return e.evaluateCountCondition(condition, metadata)
+	case models.ErrorConditionTypePath:
+		return e.evaluatePathCondition(condition, metadata)
+	case models.ErrorConditionTypeOperation:
+		return e.compareValues(operation, fmt.Sprintf("%v", condition.Value), condition.Operator)
+	case models.ErrorConditionTypeRequestSize:
+		return e.evaluateRequestSizeCondition(condition, metadata)
+	case models.ErrorConditionTypeTimeOfDay:
+		return e.evaluateTimeOfDayCondition(condition)
+	case models.ErrorConditionTypeEveryNth:
+		return e.evaluateEveryNthCondition(rule.ID, path, condition)
+	case models.ErrorConditionTypeTraceID, models.ErrorConditionTypeUserID,
+		models.ErrorConditionTypeTenant, models.ErrorConditionTypeObjectKey,
+		models.ErrorConditionTypeTestRunID, models.ErrorConditionTypeScenarioID:
+		return e.evaluateAttributeCondition(condition, metadata)
 	default:
 		e.logger.Warn(context.Background(), "Unknown condition type", 
 			observability.String("type", condition.Type))
@@ -271,36 +486,72 @@ func (e *RuleEngine) evaluateCondition(condition models.ErrorCondition, metadata
 	}
 }
 
-// evaluateProbabilityCondition 评估概率条件
-func (e *RuleEngine) evaluateProbabilityCondition(condition models.ErrorCondition) bool {
-	probability, ok := condition.Value.(float64)
-	if !ok {
-		// 尝试从字符串解析
-		if str, ok := condition.Value.(string); ok {
-			if p, err := strconv.ParseFloat(str, 64); err == nil {
-				probability = p
-			} else {
+// evaluateConditionGroup 评估and/or/not条件，结果确定后不再评估后面的子条件
+func (e *RuleEngine) evaluateConditionGroup(rule *models.ErrorRule, path string, condition models.ErrorCondition, operation string, metadata map[string]string, requestHash string) bool {
+	switch condition.Type {
+	case models.ErrorConditionTypeNot:
+		if len(condition.Conditions) != 1 {
+			return false
+		}
+		return !e.evaluateCondition(rule, path+".0", condition.Conditions[0], operation, metadata, requestHash)
+	case models.ErrorConditionTypeOr:
+		for i, child := range condition.Conditions {
+			if e.evaluateCondition(rule, path+"."+strconv.Itoa(i), child, operation, metadata, requestHash) {
+				return true
+			}
+		}
+		return false
+	default:
+		for i, child := range condition.Conditions {
+			if !e.evaluateCondition(rule, path+"."+strconv.Itoa(i), child, operation, metadata, requestHash) {
 				return false
 			}
-		} else {
-			return false
 		}
+		return len(condition.Conditions) > 0
 	}
+}
 
-	if probability <= 0 {
+// evaluateProbabilityCondition 评估概率条件
+func (e *RuleEngine) evaluateProbabilityCondition(rule *models.ErrorRule, path string, condition models.ErrorCondition, requestHash string) bool {
+	probability, ok := conditionProbability(condition)
+	if !ok || probability <= 0 {
 		return false
 	}
 	if probability >= 1 {
 		return true
 	}
 
-	random := e.rand.Float64()
+	random := e.draw(rule, path, requestHash)
 	return random < probability
 }
 
-// evaluateHeaderCondition 评估请求头条件
+// conditionProbability 解析概率条件的值
+func conditionProbability(condition models.ErrorCondition) (float64, bool) {
+	probability, ok := condition.Value.(float64)
+	if !ok {
+		// 尝试从字符串解析
+		str, ok := condition.Value.(string)
+		if !ok {
+			return 0, false
+		}
+		p, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return 0, false
+		}
+		probability = p
+	}
+	return probability, true
+}
+
+// evaluateHeaderCondition 评估请求头条件，请求头名称不区分大小写，exists/not_exists只检查是否存在
 func (e *RuleEngine) evaluateHeaderCondition(condition models.ErrorCondition, metadata map[string]string) bool {
-	headerValue, exists := metadata["header_"+condition.Field]
+	headerValue, exists := lookupHeader(metadata, condition.Field)
+	switch condition.Operator {
+	case "exists":
+		return exists
+	case "not_exists":
+		return !exists
+	}
 	if !exists {
 		return false
 	}
@@ -391,32 +642,133 @@ func (e *RuleEngine) evaluateCountCondition(condition models.ErrorCondition, met
 		return false
 	}
 
-	count, err := strconv.Atoi(countStr)
+	count, err := strconv.ParseInt(countStr, 10, 64)
 	if err != nil {
 		return false
 	}
 
-	expectedCount, err := strconv.Atoi(fmt.Sprintf("%v", condition.Value))
+	expectedCount, err := strconv.ParseInt(fmt.Sprintf("%v", condition.Value), 10, 64)
 	if err != nil {
 		return false
 	}
 
+	return compareNumbers(count, expectedCount, condition.Operator)
+}
+
+// evaluatePathCondition 评估请求路径条件
+func (e *RuleEngine) evaluatePathCondition(condition models.ErrorCondition, metadata map[string]string) bool {
+	path, exists := metadata[models.ErrorMetadataPath]
+	if !exists {
+		return false
+	}
+
+	return e.compareValues(path, fmt.Sprintf("%v", condition.Value), condition.Operator)
+}
+
+// evaluateAttributeCondition 评估trace ID、用户、租户和对象条件
+//
+// 操作符默认为eq，in/not_in的值为列表或逗号分隔的字符串；请求没有该属性时只有not_exists和not_in满足。
+func (e *RuleEngine) evaluateAttributeCondition(condition models.ErrorCondition, metadata map[string]string) bool {
+	actual, exists := requestAttribute(condition.Type, metadata)
 	switch condition.Operator {
-	case "eq":
-		return count == expectedCount
-	case "ne":
-		return count != expectedCount
-	case "gt":
-		return count > expectedCount
-	case "lt":
-		return count < expectedCount
-	case "gte":
-		return count >= expectedCount
-	case "lte":
-		return count <= expectedCount
-	default:
+	case "exists":
+		return exists
+	case "not_exists":
+		return !exists
+	case "in", "not_in":
+		found := false
+		if exists {
+			for _, value := range conditionValues(condition.Value) {
+				if matchesAttribute(condition.Type, actual, value) {
+					found = true
+					break
+				}
+			}
+		}
+		return found == (condition.Operator == "in")
+	case "", "eq":
+		return exists && matchesAttribute(condition.Type, actual, fmt.Sprintf("%v", condition.Value))
+	}
+	if !exists {
+		return false
+	}
+
+	return e.compareValues(actual, fmt.Sprintf("%v", condition.Value), condition.Operator)
+}
+
+// evaluateRequestSizeCondition 评估请求大小条件
+func (e *RuleEngine) evaluateRequestSizeCondition(condition models.ErrorCondition, metadata map[string]string) bool {
+	size, err := strconv.ParseInt(metadata[models.ErrorMetadataRequestSize], 10, 64)
+	if err != nil {
 		return false
 	}
+
+	threshold, err := parseInt64(condition.Value)
+	if err != nil {
+		return false
+	}
+
+	return compareNumbers(size, threshold, condition.Operator)
+}
+
+// evaluateTimeOfDayCondition 评估每天的时间窗口条件，not_in表示窗口之外
+func (e *RuleEngine) evaluateTimeOfDayCondition(condition models.ErrorCondition) bool {
+	start, end, err := parseTimeWindow(fmt.Sprintf("%v", condition.Value))
+	if err != nil {
+		return false
+	}
+
+	now := time.Now()
+	if condition.Field != "" {
+		loc, err := time.LoadLocation(condition.Field)
+		if err != nil {
+			return false
+		}
+		now = now.In(loc)
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	// 结束时间早于开始时间的窗口跨越午夜
+	inWindow := minute >= start && minute < end
+	if end <= start {
+		inWindow = minute >= start || minute < end
+	}
+
+	if condition.Operator == "not_in" {
+		return !inWindow
+	}
+	return inWindow
+}
+
+// evaluateEveryNthCondition 评估每N个请求条件，只统计满足前面条件的请求
+func (e *RuleEngine) evaluateEveryNthCondition(ruleID, path string, condition models.ErrorCondition) bool {
+	n, err := parseInt64(condition.Value)
+	if err != nil || n <= 0 {
+		return false
+	}
+
+	key := ruleID + "/" + path
+
+	e.countersMu.Lock()
+	defer e.countersMu.Unlock()
+	e.counters[key]++
+	return e.counters[key]%n == 0
+}
+
+// resetCounters 清除规则的every_nth计数
+func (e *RuleEngine) resetCounters(ruleID string) {
+	e.countersMu.Lock()
+	defer e.countersMu.Unlock()
+	for key := range e.counters {
+		if strings.HasPrefix(key, ruleID+"/") {
+			delete(e.counters, key)
+		}
+	}
+	for key := range e.bursts {
+		if strings.HasPrefix(key, ruleID+"/") {
+			delete(e.bursts, key)
+		}
+	}
 }
 
 // compareValues 比较值
@@ -452,5 +804,323 @@ func (e *RuleEngine) compareValues(actual, expected, operator string) bool {
 	}
 }
 
+// requestAttribute 从元数据获取请求属性，元数据没有时回退到对应的请求头
+func requestAttribute(conditionType string, metadata map[string]string) (string, bool) {
+	var key, header, baggageKey string
+	switch conditionType {
+	case models.ErrorConditionTypeTraceID:
+		key, header = models.ErrorMetadataTraceID, "traceparent"
+	case models.ErrorConditionTypeUserID:
+		key, header = models.ErrorMetadataUserID, models.HeaderUserID
+	case models.ErrorConditionTypeTenant:
+		key, header, baggageKey = models.ErrorMetadataTenant, models.HeaderTenantID, models.BaggageTenant
+	case models.ErrorConditionTypeObjectKey:
+		key = models.ErrorMetadataObjectKey
+	case models.ErrorConditionTypeTestRunID:
+		key, baggageKey = models.ErrorMetadataTestRunID, models.BaggageTestRunID
+	case models.ErrorConditionTypeScenarioID:
+		key, baggageKey = models.ErrorMetadataScenarioID, models.BaggageScenarioID
+	}
+
+	if value := metadata[key]; value != "" {
+		return value, true
+	}
+	value, exists := "", false
+	if header != "" {
+		value, exists = lookupHeader(metadata, header)
+	}
+	if !exists || value == "" {
+		// 调用方未单独传递时，从透传的baggage请求头中取
+		return baggageAttribute(metadata, baggageKey)
+	}
+	if conditionType == models.ErrorConditionTypeTraceID {
+		// traceparent: <version>-<trace-id>-<parent-id>-<flags>
+		parts := strings.Split(value, "-")
+		if len(parts) < 4 || len(parts[1]) != 32 {
+			return "", false
+		}
+		value = parts[1]
+	}
+	return value, true
+}
+
+// baggageAttribute 从baggage请求头解析属性值
+func baggageAttribute(metadata map[string]string, baggageKey string) (string, bool) {
+	if baggageKey == "" {
+		return "", false
+	}
+	header, exists := lookupHeader(metadata, models.HeaderBaggage)
+	if !exists || header == "" {
+		return "", false
+	}
+	bag, err := baggage.Parse(header)
+	if err != nil {
+		return "", false
+	}
+	value := bag.Member(baggageKey).Value()
+	return value, value != ""
+}
+
+// matchesAttribute 比较属性值，trace ID不区分大小写
+func matchesAttribute(conditionType, actual, expected string) bool {
+	if conditionType == models.ErrorConditionTypeTraceID {
+		return strings.EqualFold(actual, expected)
+	}
+	return actual == expected
+}
+
+// conditionValues 把条件值转为字符串列表，字符串按逗号分隔
+func conditionValues(value interface{}) []string {
+	var values []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			values = append(values, fmt.Sprintf("%v", item))
+		}
+	case []string:
+		values = v
+	case string:
+		values = strings.Split(v, ",")
+	case nil:
+		return nil
+	default:
+		values = []string{fmt.Sprintf("%v", v)}
+	}
+
+	result := values[:0:0]
+	for _, item := range values {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// maxConditionDepth 条件树的最大嵌套层数
+const maxConditionDepth = 8
+
+// ValidateCondition 验证条件的值能被解析，and/or/not条件递归验证子条件
+func ValidateCondition(condition models.ErrorCondition) error {
+	return validateCondition(condition, 1)
+}
+
+// validateCondition 验证depth层的条件
+func validateCondition(condition models.ErrorCondition, depth int) error {
+	if depth > maxConditionDepth {
+		return fmt.Errorf("condition tree exceeds maximum depth %d", maxConditionDepth)
+	}
+	switch condition.Type {
+	case models.ErrorConditionTypeAnd, models.ErrorConditionTypeOr, models.ErrorConditionTypeNot:
+		if condition.Type == models.ErrorConditionTypeNot && len(condition.Conditions) != 1 {
+			return fmt.Errorf("not condition requires exactly one sub-condition")
+		}
+		if len(condition.Conditions) == 0 {
+			return fmt.Errorf("%s condition requires at least one sub-condition", condition.Type)
+		}
+		for i, child := range condition.Conditions {
+			if err := validateCondition(child, depth+1); err != nil {
+				return fmt.Errorf("%s sub-condition %d: %w", condition.Type, i, err)
+			}
+		}
+		return nil
+	}
+	if len(condition.Conditions) > 0 {
+		return fmt.Errorf("%s condition cannot have sub-conditions", condition.Type)
+	}
+
+	switch condition.Type {
+	case models.ErrorConditionTypeProbability, models.ErrorConditionTypeParam, models.ErrorConditionTypeTime,
+		models.ErrorConditionTypeUserAgent, models.ErrorConditionTypeIP, models.ErrorConditionTypeCount:
+	case models.ErrorConditionTypeHeader:
+		if condition.Field == "" {
+			return fmt.Errorf("header condition requires a field")
+		}
+	case models.ErrorConditionTypePath, models.ErrorConditionTypeOperation:
+		if condition.Operator == "regex" {
+			if _, err := regexp.Compile(fmt.Sprintf("%v", condition.Value)); err != nil {
+				return fmt.Errorf("invalid %s regex: %w", condition.Type, err)
+			}
+		}
+	case models.ErrorConditionTypeRequestSize:
+		if size, err := parseInt64(condition.Value); err != nil || size < 0 {
+			return fmt.Errorf("invalid request_size value: %v", condition.Value)
+		}
+	case models.ErrorConditionTypeTimeOfDay:
+		if _, _, err := parseTimeWindow(fmt.Sprintf("%v", condition.Value)); err != nil {
+			return err
+		}
+		if condition.Field != "" {
+			if _, err := time.LoadLocation(condition.Field); err != nil {
+				return fmt.Errorf("invalid time_of_day timezone: %w", err)
+			}
+		}
+	case models.ErrorConditionTypeEveryNth:
+		if n, err := parseInt64(condition.Value); err != nil || n <= 0 {
+			return fmt.Errorf("invalid every_nth value: %v", condition.Value)
+		}
+	case models.ErrorConditionTypeTraceID, models.ErrorConditionTypeUserID,
+		models.ErrorConditionTypeTenant, models.ErrorConditionTypeObjectKey,
+		models.ErrorConditionTypeTestRunID, models.ErrorConditionTypeScenarioID:
+		switch condition.Operator {
+		case "exists", "not_exists":
+		case "in", "not_in":
+			if len(conditionValues(condition.Value)) == 0 {
+				return fmt.Errorf("%s condition with %s requires at least one value", condition.Type, condition.Operator)
+			}
+		case "regex":
+			if _, err := regexp.Compile(fmt.Sprintf("%v", condition.Value)); err != nil {
+				return fmt.Errorf("invalid %s regex: %w", condition.Type, err)
+			}
+		default:
+			if condition.Value == nil || fmt.Sprintf("%v", condition.Value) == "" {
+				return fmt.Errorf("%s condition requires a value", condition.Type)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown condition type: %s", condition.Type)
+	}
+	return nil
+}
+
+// ValidateSchedule 验证调度配置
+func ValidateSchedule(schedule *models.ErrorSchedule) error {
+	if schedule.StartTime != nil && schedule.EndTime != nil && !schedule.EndTime.After(*schedule.StartTime) {
+		return fmt.Errorf("schedule end time must be after start time")
+	}
+	if schedule.Timezone != "" {
+		if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+			return fmt.Errorf("invalid schedule timezone: %w", err)
+		}
+	}
+	for _, day := range schedule.Days {
+		valid := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(day, d.String()) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid schedule day: %s", day)
+		}
+	}
+	for _, hour := range schedule.Hours {
+		if hour < 0 || hour > 23 {
+			return fmt.Errorf("invalid schedule hour: %d", hour)
+		}
+	}
+	if schedule.Cron != "" {
+		if _, err := parseCronWindow(schedule.Cron); err != nil {
+			return err
+		}
+	}
+	if schedule.MaxDurationMins < 0 {
+		return fmt.Errorf("invalid schedule max duration: %d", schedule.MaxDurationMins)
+	}
+	return nil
+}
+
+// ValidateRamp 验证概率爬升配置
+func ValidateRamp(ramp *models.ErrorRamp) error {
+	if ramp.StartProbability < 0 || ramp.StartProbability > 1 {
+		return fmt.Errorf("invalid ramp start probability: %g", ramp.StartProbability)
+	}
+	if ramp.TargetProbability < 0 || ramp.TargetProbability > 1 {
+		return fmt.Errorf("invalid ramp target probability: %g", ramp.TargetProbability)
+	}
+	if ramp.DurationSecs <= 0 {
+		return fmt.Errorf("invalid ramp duration: %ds", ramp.DurationSecs)
+	}
+	return nil
+}
+
+// ValidateBurst 验证连续失败配置
+func ValidateBurst(burst *models.ErrorBurst) error {
+	if burst.Count <= 0 {
+		return fmt.Errorf("invalid burst count: %d", burst.Count)
+	}
+	if burst.WindowSecs < 0 {
+		return fmt.Errorf("invalid burst window: %ds", burst.WindowSecs)
+	}
+	return nil
+}
+
+// namespacePattern 命名空间：小写字母、数字、-和_，以字母或数字开头，最长63个字符
+var namespacePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidateNamespace 验证命名空间，空表示default
+func ValidateNamespace(namespace string) error {
+	if namespace != "" && !namespacePattern.MatchString(namespace) {
+		return fmt.Errorf("invalid namespace: %q", namespace)
+	}
+	return nil
+}
+
+// lookupHeader 从元数据查找请求头，名称不区分大小写
+func lookupHeader(metadata map[string]string, name string) (string, bool) {
+	if value, exists := metadata[models.ErrorMetadataHeaderPrefix+name]; exists {
+		return value, true
+	}
+	for key, value := range metadata {
+		if header, ok := strings.CutPrefix(key, models.ErrorMetadataHeaderPrefix); ok && strings.EqualFold(header, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// compareNumbers 比较数值
+func compareNumbers(actual, expected int64, operator string) bool {
+	switch operator {
+	case "eq":
+		return actual == expected
+	case "ne":
+		return actual != expected
+	case "gt":
+		return actual > expected
+	case "lt":
+		return actual < expected
+	case "gte":
+		return actual >= expected
+	case "lte":
+		return actual <= expected
+	default:
+		return false
+	}
+}
+
+// parseInt64 解析条件中的整数值，JSON数字解码为float64
+func parseInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case float64:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	default:
+		return strconv.ParseInt(fmt.Sprintf("%v", value), 10, 64)
+	}
+}
+
+// parseTimeWindow 解析"HH:MM-HH:MM"时间窗口，返回一天中的开始和结束分钟
+func parseTimeWindow(window string) (int, int, error) {
+	startStr, endStr, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid time window %q: expected HH:MM-HH:MM", window)
+	}
+
+	start, err := time.Parse("15:04", strings.TrimSpace(startStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time window %q: %w", window, err)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(endStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time window %q: %w", window, err)
+	}
+
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute(), nil
+}
+
 // 确保实现了接口
 var _ interfaces.ErrorRuleEngine = (*RuleEngine)(nil)
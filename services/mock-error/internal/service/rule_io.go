@@ -0,0 +1,237 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ruleRuntimeFields 导出时去掉的运行时字段，导入时忽略
+var ruleRuntimeFields = []string{"triggered", "last_triggered_at", "created_at", "updated_at"}
+
+// ExportRules 导出命名空间的规则为YAML（namespace为空时导出全部），字段名与JSON接口一致，不包括触发次数等运行时状态
+func (s *ErrorInjectorService) ExportRules(ctx context.Context, namespace string) ([]byte, error) {
+	rules, err := s.ListNamespaceRules(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	// 经过JSON转换，使YAML字段名使用json标签，延迟等字段与JSON接口格式相同
+	data, err := json.Marshal(&models.ErrorRuleSet{Version: models.ErrorRuleSetVersion, Rules: rules})
+	if err != nil {
+		return nil, err
+	}
+	// 保留整数格式，如延迟的纳秒数
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if ruleDocs, ok := doc["rules"].([]interface{}); ok {
+		for _, ruleDoc := range ruleDocs {
+			if fields, ok := ruleDoc.(map[string]interface{}); ok {
+				for _, field := range ruleRuntimeFields {
+					delete(fields, field)
+				}
+			}
+		}
+	}
+
+	return yaml.Marshal(pruneEmpty(doc))
+}
+
+// pruneEmpty 去掉值为null或空字符串的字段并还原数字类型，使导出的YAML便于阅读
+func pruneEmpty(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	case map[string]interface{}:
+		for key, field := range v {
+			if field == nil || field == "" {
+				delete(v, key)
+				continue
+			}
+			v[key] = pruneEmpty(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = pruneEmpty(item)
+		}
+	}
+	return value
+}
+
+// ImportRules 从YAML导入规则集，所有规则通过验证后才会生效
+//
+// merge模式按ID更新已有规则（保留触发次数），没有ID或ID不存在的规则作为新规则添加；
+// replace模式还会删除规则集中没有的规则。指定namespace时只导入到该命名空间，replace只删除该命名空间的规则。
+func (s *ErrorInjectorService) ImportRules(ctx context.Context, data []byte, mode, namespace string) (*models.RuleImportResult, error) {
+	if mode == "" {
+		mode = models.RuleImportModeMerge
+	}
+	if mode != models.RuleImportModeMerge && mode != models.RuleImportModeReplace {
+		return nil, fmt.Errorf("invalid import mode: %s", mode)
+	}
+
+	ruleSet, err := parseRuleSet(data)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := s.ruleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	allByID := make(map[string]*models.ErrorRule, len(all))
+	for _, rule := range all {
+		allByID[rule.ID] = rule
+	}
+	existing := filterNamespaceRules(all, namespace)
+	existingByID := make(map[string]*models.ErrorRule, len(existing))
+	for _, rule := range existing {
+		existingByID[rule.ID] = rule
+	}
+
+	// 先验证整个规则集
+	seen := make(map[string]bool, len(ruleSet.Rules))
+	created := 0
+	for i, rule := range ruleSet.Rules {
+		if rule == nil {
+			return nil, fmt.Errorf("rule %d is empty", i)
+		}
+		if namespace != "" {
+			if rule.Namespace == "" {
+				rule.Namespace = namespace
+			}
+			if models.RuleNamespace(rule.Namespace) != models.RuleNamespace(namespace) {
+				return nil, fmt.Errorf("rule %d (%s): namespace %s does not match %s", i, rule.Name, rule.Namespace, namespace)
+			}
+			// 不能通过ID覆盖其他命名空间的规则
+			if rule.ID != "" && allByID[rule.ID] != nil && existingByID[rule.ID] == nil {
+				return nil, fmt.Errorf("rule %d (%s): rule ID %s belongs to another namespace", i, rule.Name, rule.ID)
+			}
+		}
+		if err := s.validateRule(rule); err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %w", i, rule.Name, err)
+		}
+		if rule.ID != "" {
+			if seen[rule.ID] {
+				return nil, fmt.Errorf("rule %d (%s): duplicate rule ID %s", i, rule.Name, rule.ID)
+			}
+			seen[rule.ID] = true
+		}
+		if existingByID[rule.ID] == nil {
+			created++
+		}
+	}
+
+	total := len(all) + created
+	if mode == models.RuleImportModeReplace {
+		total = len(all) - len(existing) + len(ruleSet.Rules)
+	}
+	if total > s.config.ErrorEngine.MaxRules {
+		return nil, fmt.Errorf("maximum number of rules exceeded: %d > %d", total, s.config.ErrorEngine.MaxRules)
+	}
+
+	result := &models.RuleImportResult{Mode: mode, RuleIDs: make([]string, 0, len(ruleSet.Rules))}
+
+	if mode == models.RuleImportModeReplace {
+		for _, rule := range existing {
+			if seen[rule.ID] {
+				continue
+			}
+			if err := s.RemoveErrorRule(ctx, rule.ID); err != nil {
+				return result, err
+			}
+			result.Deleted++
+		}
+	}
+
+	for _, rule := range ruleSet.Rules {
+		if current := existingByID[rule.ID]; current != nil {
+			rule.Triggered = current.Triggered
+			rule.LastTriggeredAt = current.LastTriggeredAt
+			rule.CreatedAt = current.CreatedAt
+			rule.CreatedBy = current.CreatedBy
+			if err := s.UpdateErrorRule(ctx, rule); err != nil {
+				return result, err
+			}
+			result.Updated++
+		} else {
+			if err := s.AddErrorRule(ctx, rule); err != nil {
+				return result, err
+			}
+			result.Created++
+		}
+		result.RuleIDs = append(result.RuleIDs, rule.ID)
+	}
+
+	s.logger.Info(ctx, "Error rules imported",
+		observability.String("mode", mode),
+		observability.Int("created", result.Created),
+		observability.Int("updated", result.Updated),
+		observability.Int("deleted", result.Deleted))
+	return result, nil
+}
+
+// filterNamespaceRules 过滤命名空间的规则，namespace为空时不过滤
+func filterNamespaceRules(rules []*models.ErrorRule, namespace string) []*models.ErrorRule {
+	if namespace == "" {
+		return rules
+	}
+	filtered := make([]*models.ErrorRule, 0, len(rules))
+	for _, rule := range rules {
+		if models.RuleNamespace(rule.Namespace) == models.RuleNamespace(namespace) {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered
+}
+
+// parseRuleSet 解析YAML规则集，经过JSON转换使字段名与JSON接口一致
+func parseRuleSet(data []byte) (*models.ErrorRuleSet, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid rule set YAML: %w", err)
+	}
+	fields, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid rule set: expected a mapping with rules")
+	}
+
+	if ruleDocs, ok := fields["rules"].([]interface{}); ok {
+		for _, ruleDoc := range ruleDocs {
+			if ruleFields, ok := ruleDoc.(map[string]interface{}); ok {
+				for _, field := range ruleRuntimeFields {
+					delete(ruleFields, field)
+				}
+			}
+		}
+	}
+
+	jsonData, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule set: %w", err)
+	}
+	var ruleSet models.ErrorRuleSet
+	if err := json.Unmarshal(jsonData, &ruleSet); err != nil {
+		return nil, fmt.Errorf("invalid rule set: %w", err)
+	}
+	if ruleSet.Version != 0 && ruleSet.Version != models.ErrorRuleSetVersion {
+		return nil, fmt.Errorf("unsupported rule set version: %d", ruleSet.Version)
+	}
+	return &ruleSet, nil
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mocks3/shared/models"
+	"sort"
+	"time"
+)
+
+// ErrRuleTemplateNotFound 规则模板不存在
+var ErrRuleTemplateNotFound = errors.New("rule template not found")
+
+// ruleTemplate 规则模板及根据参数生成规则内容的函数
+type ruleTemplate struct {
+	info  models.RuleTemplate
+	build func(params map[string]float64, rule *models.ErrorRule)
+}
+
+// 模板共用的参数
+var (
+	probabilityParam = func(def float64) *models.RuleTemplateParameter {
+		return &models.RuleTemplateParameter{Name: "probability", Description: "命中请求的比例", Default: def, Min: 0, Max: 1}
+	}
+	durationParam = func(def float64) *models.RuleTemplateParameter {
+		return &models.RuleTemplateParameter{Name: "duration_minutes", Description: "规则生效的分钟数，0表示不自动结束", Default: def, Min: 0, Max: 7 * 24 * 60}
+	}
+	httpCodeParam = func(def float64) *models.RuleTemplateParameter {
+		return &models.RuleTemplateParameter{Name: "http_code", Description: "返回的HTTP状态码", Default: def, Min: 400, Max: 599}
+	}
+)
+
+// ruleTemplates 内置规则模板
+var ruleTemplates = map[string]*ruleTemplate{
+	"latency-spike": {
+		info: models.RuleTemplate{
+			Description: "p99延迟尖刺：少量请求增加固定延迟",
+			ActionType:  models.ErrorActionTypeDelay,
+			Parameters: []*models.RuleTemplateParameter{
+				probabilityParam(0.01),
+				{Name: "delay_ms", Description: "增加的延迟毫秒数", Default: 2000, Min: 1, Max: 300000},
+				durationParam(0),
+			},
+		},
+		build: func(params map[string]float64, rule *models.ErrorRule) {
+			delay := time.Duration(params["delay_ms"]) * time.Millisecond
+			rule.Conditions = probabilityConditions(params["probability"])
+			rule.Action = models.ErrorAction{Type: models.ErrorActionTypeDelay, Delay: &delay}
+		},
+	},
+	"dependency-outage": {
+		info: models.RuleTemplate{
+			Description: "依赖不可用：所有请求返回错误，持续一段时间后自动结束",
+			ActionType:  models.ErrorActionTypeHTTPError,
+			Parameters: []*models.RuleTemplateParameter{
+				httpCodeParam(503),
+				durationParam(10),
+			},
+		},
+		build: func(params map[string]float64, rule *models.ErrorRule) {
+			rule.Action = models.ErrorAction{
+				Type:     models.ErrorActionTypeHTTPError,
+				HTTPCode: int(params["http_code"]),
+				Message:  "Dependency unavailable (injected)",
+			}
+		},
+	},
+	"error-storm": {
+		info: models.RuleTemplate{
+			Description: "错误风暴：大比例请求返回5xx，可限制总触发次数",
+			ActionType:  models.ErrorActionTypeHTTPError,
+			Parameters: []*models.RuleTemplateParameter{
+				probabilityParam(0.5),
+				httpCodeParam(500),
+				{Name: "max_triggers", Description: "最多触发次数，0表示不限制", Default: 0, Min: 0, Max: 1e9},
+				durationParam(5),
+			},
+		},
+		build: func(params map[string]float64, rule *models.ErrorRule) {
+			rule.Conditions = probabilityConditions(params["probability"])
+			rule.MaxTriggers = int(params["max_triggers"])
+			rule.Action = models.ErrorAction{
+				Type:     models.ErrorActionTypeHTTPError,
+				HTTPCode: int(params["http_code"]),
+				Message:  "Internal error (injected)",
+			}
+		},
+	},
+	"flaky-requests": {
+		info: models.RuleTemplate{
+			Description: "偶发失败：每N个请求失败一次",
+			ActionType:  models.ErrorActionTypeHTTPError,
+			Parameters: []*models.RuleTemplateParameter{
+				{Name: "every_nth", Description: "每N个请求失败一次", Default: 10, Min: 1, Max: 1e6},
+				httpCodeParam(500),
+				durationParam(0),
+			},
+		},
+		build: func(params map[string]float64, rule *models.ErrorRule) {
+			rule.Conditions = []models.ErrorCondition{{Type: models.ErrorConditionTypeEveryNth, Value: int(params["every_nth"])}}
+			rule.Action = models.ErrorAction{
+				Type:     models.ErrorActionTypeHTTPError,
+				HTTPCode: int(params["http_code"]),
+				Message:  "Flaky request (injected)",
+			}
+		},
+	},
+	"request-timeout": {
+		info: models.RuleTemplate{
+			Description: "请求超时：少量请求挂起后返回超时",
+			ActionType:  models.ErrorActionTypeTimeout,
+			Parameters: []*models.RuleTemplateParameter{
+				probabilityParam(0.05),
+				{Name: "timeout_ms", Description: "挂起的毫秒数", Default: 30000, Min: 1, Max: 300000},
+				durationParam(0),
+			},
+		},
+		build: func(params map[string]float64, rule *models.ErrorRule) {
+			delay := time.Duration(params["timeout_ms"]) * time.Millisecond
+			rule.Conditions = probabilityConditions(params["probability"])
+			rule.Action = models.ErrorAction{Type: models.ErrorActionTypeTimeout, Delay: &delay}
+		},
+	},
+	"slow-network": {
+		info: models.RuleTemplate{
+			Description: "慢速网络：限制响应带宽",
+			ActionType:  models.ErrorActionTypeThrottle,
+			Parameters: []*models.RuleTemplateParameter{
+				probabilityParam(1),
+				{Name: "bytes_per_second", Description: "响应带宽（字节/秒）", Default: 64 * 1024, Min: 1, Max: 1 << 30},
+				durationParam(0),
+			},
+		},
+		build: func(params map[string]float64, rule *models.ErrorRule) {
+			rule.Conditions = probabilityConditions(params["probability"])
+			rule.Action = models.ErrorAction{Type: models.ErrorActionTypeThrottle, BytesPerSecond: int64(params["bytes_per_second"])}
+		},
+	},
+}
+
+func init() {
+	for name, template := range ruleTemplates {
+		template.info.Name = name
+	}
+}
+
+// ListRuleTemplates 列出内置规则模板
+func (s *ErrorInjectorService) ListRuleTemplates() []*models.RuleTemplate {
+	templates := make([]*models.RuleTemplate, 0, len(ruleTemplates))
+	for _, template := range ruleTemplates {
+		info := template.info
+		templates = append(templates, &info)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// CreateRuleFromTemplate 用模板和参数生成规则并添加
+func (s *ErrorInjectorService) CreateRuleFromTemplate(ctx context.Context, name string, req *models.RuleFromTemplateRequest) (*models.ErrorRule, error) {
+	template, ok := ruleTemplates[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRuleTemplateNotFound, name)
+	}
+
+	params, err := template.params(req.Params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template parameters: %w", err)
+	}
+
+	rule := &models.ErrorRule{
+		Name:        req.Name,
+		Description: template.info.Description,
+		Namespace:   req.Namespace,
+		Service:     req.Service,
+		Operation:   req.Operation,
+		Priority:    req.Priority,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Metadata:    map[string]string{"template": name},
+	}
+	if rule.Name == "" {
+		rule.Name = name
+		if req.Service != "" {
+			rule.Name = name + ": " + req.Service
+		}
+	}
+	template.build(params, rule)
+	rule.Conditions = append(rule.Conditions, req.Conditions...)
+
+	if minutes := params["duration_minutes"]; minutes > 0 {
+		now := time.Now()
+		end := now.Add(time.Duration(minutes * float64(time.Minute)))
+		rule.Schedule = &models.ErrorSchedule{StartTime: &now, EndTime: &end}
+	}
+
+	if err := s.AddErrorRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// params 合并默认值并检查参数范围
+func (t *ruleTemplate) params(values map[string]float64) (map[string]float64, error) {
+	params := make(map[string]float64, len(t.info.Parameters))
+	for _, param := range t.info.Parameters {
+		params[param.Name] = param.Default
+	}
+
+	for name, value := range values {
+		var param *models.RuleTemplateParameter
+		for _, p := range t.info.Parameters {
+			if p.Name == name {
+				param = p
+				break
+			}
+		}
+		if param == nil {
+			return nil, fmt.Errorf("unknown parameter: %s", name)
+		}
+		if value < param.Min || value > param.Max {
+			return nil, fmt.Errorf("parameter %s must be between %g and %g", name, param.Min, param.Max)
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+// probabilityConditions 概率小于1时生成概率条件
+func probabilityConditions(probability float64) []models.ErrorCondition {
+	if probability >= 1 {
+		return nil
+	}
+	return []models.ErrorCondition{{Type: models.ErrorConditionTypeProbability, Value: probability}}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"time"
+)
+
+// ErrSafetyLimit 规则超出安全限制
+var ErrSafetyLimit = errors.New("safety limit exceeded")
+
+// safetyCheckInterval 规则TTL检查间隔
+const safetyCheckInterval = time.Minute
+
+// killSwitchSyncInterval 配置了规则存储时从存储同步熔断开关的间隔，其他实例的开关变更在该时间内生效
+const killSwitchSyncInterval = 5 * time.Second
+
+// capDrawPath 注入比例上限的随机数在判定结果中的位置
+const capDrawPath = "cap"
+
+// EngageKillSwitch 开启全局熔断开关：立即停止所有错误注入（包括试运行记录和Toxiproxy的toxic），并停止运行中的实验；
+// 配置了规则存储时写入存储，重启和其他实例都会生效。写入失败时本实例仍然开启，返回错误
+func (s *ErrorInjectorService) EngageKillSwitch(ctx context.Context, reason string) (*models.KillSwitchStatus, error) {
+	now := time.Now()
+	status := &models.KillSwitchStatus{
+		Engaged:   true,
+		Reason:    reason,
+		EngagedBy: models.AuditActorFromContext(ctx).Name,
+		EngagedAt: &now,
+	}
+	s.killSwitch.Store(status)
+
+	s.logger.Warn(ctx, "Kill switch engaged, all error injection stopped",
+		observability.String("reason", reason),
+		observability.String("engaged_by", status.EngagedBy))
+
+	s.StopExperiments(ctx)
+	s.syncToxicsAfterChange(ctx)
+
+	if err := s.ruleRepo.SaveKillSwitch(ctx, status); err != nil {
+		s.logger.Error(ctx, "Failed to persist kill switch",
+			observability.String("error", err.Error()))
+		return status, fmt.Errorf("failed to persist kill switch: %w", err)
+	}
+	return status, nil
+}
+
+// ReleaseKillSwitch 关闭全局熔断开关，规则恢复按各自的状态生效；写入规则存储失败时保持开启
+func (s *ErrorInjectorService) ReleaseKillSwitch(ctx context.Context) (*models.KillSwitchStatus, error) {
+	if err := s.ruleRepo.SaveKillSwitch(ctx, nil); err != nil {
+		s.logger.Error(ctx, "Failed to persist kill switch release",
+			observability.String("error", err.Error()))
+		return s.KillSwitch(), fmt.Errorf("failed to persist kill switch: %w", err)
+	}
+
+	if previous := s.killSwitch.Swap(nil); previous != nil {
+		s.logger.Info(ctx, "Kill switch released",
+			observability.String("released_by", models.AuditActorFromContext(ctx).Name))
+		s.syncToxicsAfterChange(ctx)
+	}
+	return &models.KillSwitchStatus{}, nil
+}
+
+// LoadKillSwitch 从规则存储加载熔断开关状态，启动时和定期同步时调用
+func (s *ErrorInjectorService) LoadKillSwitch(ctx context.Context) error {
+	status, err := s.ruleRepo.LoadKillSwitch(ctx)
+	if err != nil {
+		return err
+	}
+
+	previous := s.killSwitch.Swap(status)
+	switch {
+	case status != nil && previous == nil:
+		s.logger.Warn(ctx, "Kill switch engaged from rule store, all error injection stopped",
+			observability.String("reason", status.Reason),
+			observability.String("engaged_by", status.EngagedBy))
+		s.StopExperiments(ctx)
+		s.syncToxicsAfterChange(ctx)
+	case status == nil && previous != nil:
+		s.logger.Info(ctx, "Kill switch released from rule store")
+		s.syncToxicsAfterChange(ctx)
+	}
+	return nil
+}
+
+// StartKillSwitchSync 配置了规则存储时定期同步熔断开关，使其他实例开启或关闭的开关在本实例生效
+func (s *ErrorInjectorService) StartKillSwitchSync(ctx context.Context) {
+	if !s.ruleRepo.Persistent() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(killSwitchSyncInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.LoadKillSwitch(ctx); err != nil {
+					s.logger.Warn(ctx, "Kill switch sync failed",
+						observability.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+}
+
+// KillSwitch 返回全局熔断开关状态
+func (s *ErrorInjectorService) KillSwitch() *models.KillSwitchStatus {
+	if status := s.killSwitch.Load(); status != nil {
+		return status
+	}
+	return &models.KillSwitchStatus{}
+}
+
+// isKilled 全局熔断开关是否开启
+func (s *ErrorInjectorService) isKilled() bool {
+	return s.killSwitch.Load() != nil
+}
+
+// injectionProbability 命中规则的请求中实际注入的比例：全局触发概率和安全上限中较小的一个
+func (s *ErrorInjectorService) injectionProbability() float64 {
+	return min(s.config.Injection.GlobalProbability, s.config.Safety.MaxProbability)
+}
+
+// passesProbabilityCap 按全局触发概率和安全上限决定命中的规则是否注入，与规则的概率条件一样按种子和请求哈希判定
+func (s *ErrorInjectorService) passesProbabilityCap(rule *models.ErrorRule, requestHash string) bool {
+	probability := s.injectionProbability()
+	if probability >= 1 {
+		return true
+	}
+	if probability <= 0 {
+		return false
+	}
+	if engine, ok := s.ruleEngine.(seededEngine); ok {
+		return engine.draw(rule, capDrawPath, requestHash) < probability
+	}
+	return rand.Float64() < probability
+}
+
+// checkActiveRuleLimit 启用规则前检查同时启用的规则数，before为更新前的规则
+func (s *ErrorInjectorService) checkActiveRuleLimit(ctx context.Context, rule, before *models.ErrorRule) error {
+	limit := s.config.Safety.MaxActiveRules
+	if limit <= 0 || !rule.Enabled || (before != nil && before.Enabled) {
+		return nil
+	}
+
+	active, err := s.ruleRepo.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count active rules: %w", err)
+	}
+	count := 0
+	for _, existing := range active {
+		if existing.ID != rule.ID {
+			count++
+		}
+	}
+	if count >= limit {
+		return fmt.Errorf("%w: maximum active rules reached: %d", ErrSafetyLimit, limit)
+	}
+	return nil
+}
+
+// safetyAuditContext 安全检查禁用规则时的审计操作者
+func safetyAuditContext(ctx context.Context) context.Context {
+	if models.AuditActorFromContext(ctx).Name != "" {
+		return ctx
+	}
+	return models.WithAuditActor(ctx, models.AuditActor{Name: "safety-guard"})
+}
+
+// DisableExpiredRules 禁用启用（或最近修改）后超过TTL的规则，返回禁用的规则数；未配置TTL时不做任何事
+func (s *ErrorInjectorService) DisableExpiredRules(ctx context.Context) (int, error) {
+	ttl := s.config.Safety.GetRuleTTL()
+	if ttl <= 0 {
+		return 0, nil
+	}
+
+	rules, err := s.ruleRepo.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list rules: %w", err)
+	}
+
+	now := time.Now()
+	disabled := 0
+	for _, rule := range rules {
+		enabledSince := rule.UpdatedAt
+		if enabledSince.IsZero() {
+			enabledSince = rule.CreatedAt
+		}
+		if !rule.Enabled || now.Sub(enabledSince) < ttl {
+			continue
+		}
+
+		if err := s.disableRule(safetyAuditContext(ctx), rule); err != nil {
+			s.logger.Warn(ctx, "Failed to disable expired rule",
+				observability.String("rule_id", rule.ID),
+				observability.String("error", err.Error()))
+			continue
+		}
+		disabled++
+
+		s.logger.Warn(ctx, "Error rule disabled after TTL",
+			observability.String("rule_id", rule.ID),
+			observability.String("rule_name", rule.Name),
+			observability.String("ttl", ttl.String()))
+	}
+
+	if disabled > 0 {
+		s.updateRuleCounts(ctx)
+	}
+	return disabled, nil
+}
+
+// StartSafetyGuard 启动后台规则TTL检查
+func (s *ErrorInjectorService) StartSafetyGuard(ctx context.Context) {
+	if s.config.Safety.GetRuleTTL() <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(safetyCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.DisableExpiredRules(ctx); err != nil {
+					s.logger.Warn(ctx, "Rule TTL check failed",
+						observability.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+}
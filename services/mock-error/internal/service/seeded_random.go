@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"strconv"
+	"time"
+)
+
+// rampDrawPath 概率爬升的随机数在判定结果中的位置
+const rampDrawPath = "ramp"
+
+// maxWouldTriggerHashes 单次查询最多判定的请求哈希数
+const maxWouldTriggerHashes = 1000
+
+// seededEngine 支持随机种子的规则引擎
+type seededEngine interface {
+	SetRandomSeed(seed *int64)
+	RandomSeed() *int64
+	seededDecision(rule *models.ErrorRule, requestHash string) *models.SeededDecision
+	draw(rule *models.ErrorRule, path, requestHash string) float64
+}
+
+// RequestHash 计算请求哈希，依次取X-Request-Id请求头、trace ID和路径；都没有时返回空字符串，概率判定不可复现
+func RequestHash(metadata map[string]string) string {
+	key, _ := lookupHeader(metadata, "X-Request-Id")
+	if key == "" {
+		key = metadata[models.ErrorMetadataTraceID]
+	}
+	if key == "" {
+		key = metadata[models.ErrorMetadataPath]
+	}
+	if key == "" {
+		return ""
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// seededDraw 由种子、条件位置和请求哈希确定[0,1)的随机数，同一输入在任何进程中结果相同
+func seededDraw(seed int64, path, requestHash string) float64 {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(seed))
+	h := sha256.New()
+	h.Write(buf[:])
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write([]byte(requestHash))
+	return float64(binary.BigEndian.Uint64(h.Sum(nil))>>11) / (1 << 53)
+}
+
+// ruleSeed 规则的种子优先，其次是全局种子
+func (e *RuleEngine) ruleSeed(rule *models.ErrorRule) *int64 {
+	if rule.Seed != nil {
+		return rule.Seed
+	}
+	return e.seed.Load()
+}
+
+// draw 返回概率判定使用的随机数，有种子和请求哈希时可复现
+func (e *RuleEngine) draw(rule *models.ErrorRule, path, requestHash string) float64 {
+	seed := e.ruleSeed(rule)
+	if seed == nil || requestHash == "" {
+		return e.rand.Float64()
+	}
+	return seededDraw(*seed, path, requestHash)
+}
+
+// seededDecision 按种子判定请求哈希能否通过规则的概率条件和概率爬升，其他条件视为满足
+func (e *RuleEngine) seededDecision(rule *models.ErrorRule, requestHash string) *models.SeededDecision {
+	decision := &models.SeededDecision{
+		RequestHash: requestHash,
+		Triggers:    true,
+		Draws:       make(map[string]float64),
+	}
+	for i, condition := range rule.Conditions {
+		if !e.seededCondition(rule, strconv.Itoa(i), condition, requestHash, decision.Draws) {
+			decision.Triggers = false
+		}
+	}
+
+	if rule.Ramp != nil {
+		probability := rule.Ramp.ProbabilityAt(rule.CreatedAt, time.Now())
+		if probability < 1 {
+			random := e.draw(rule, rampDrawPath, requestHash)
+			decision.Draws[rampDrawPath] = random
+			if probability <= 0 || random >= probability {
+				decision.Triggers = false
+			}
+		}
+	}
+	return decision
+}
+
+// seededCondition 评估条件树中的概率条件并记录随机数，评估全部子条件以便列出所有随机数
+func (e *RuleEngine) seededCondition(rule *models.ErrorRule, path string, condition models.ErrorCondition, requestHash string, draws map[string]float64) bool {
+	switch condition.Type {
+	case models.ErrorConditionTypeProbability:
+		probability, ok := conditionProbability(condition)
+		if !ok || probability <= 0 {
+			return false
+		}
+		if probability >= 1 {
+			return true
+		}
+		random := e.draw(rule, path, requestHash)
+		draws[path] = random
+		return random < probability
+	case models.ErrorConditionTypeNot:
+		if len(condition.Conditions) != 1 {
+			return false
+		}
+		return !e.seededCondition(rule, path+".0", condition.Conditions[0], requestHash, draws)
+	case models.ErrorConditionTypeAnd, models.ErrorConditionTypeOr:
+		matched, all := false, true
+		for i, child := range condition.Conditions {
+			if e.seededCondition(rule, path+"."+strconv.Itoa(i), child, requestHash, draws) {
+				matched = true
+			} else {
+				all = false
+			}
+		}
+		if condition.Type == models.ErrorConditionTypeOr {
+			return matched
+		}
+		return all && len(condition.Conditions) > 0
+	default:
+		return true
+	}
+}
+
+// SetRandomSeed 设置全局随机种子，为nil时清除；规则自己的种子优先
+func (s *ErrorInjectorService) SetRandomSeed(ctx context.Context, seed *int64) error {
+	engine, ok := s.ruleEngine.(seededEngine)
+	if !ok {
+		return fmt.Errorf("rule engine does not support random seeds")
+	}
+	engine.SetRandomSeed(seed)
+
+	if seed == nil {
+		s.logger.Info(ctx, "Global random seed cleared")
+	} else {
+		s.logger.Info(ctx, "Global random seed changed",
+			observability.Int64("seed", *seed))
+	}
+	return nil
+}
+
+// RandomSeed 返回全局随机种子，未设置时为nil
+func (s *ErrorInjectorService) RandomSeed() *int64 {
+	if engine, ok := s.ruleEngine.(seededEngine); ok {
+		return engine.RandomSeed()
+	}
+	return nil
+}
+
+// WouldTrigger 判定请求哈希能否通过规则的概率条件，用于排查结果不稳定的混沌测试；规则和全局都没有种子时返回错误
+func (s *ErrorInjectorService) WouldTrigger(ctx context.Context, ruleID string, requestHashes []string) ([]*models.SeededDecision, error) {
+	engine, ok := s.ruleEngine.(seededEngine)
+	if !ok {
+		return nil, fmt.Errorf("rule engine does not support random seeds")
+	}
+	if len(requestHashes) == 0 || len(requestHashes) > maxWouldTriggerHashes {
+		return nil, fmt.Errorf("invalid request hashes: between 1 and %d required", maxWouldTriggerHashes)
+	}
+
+	rule, err := s.GetErrorRule(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Seed == nil && engine.RandomSeed() == nil {
+		return nil, fmt.Errorf("invalid rule: no random seed set for rule or globally")
+	}
+
+	decisions := make([]*models.SeededDecision, 0, len(requestHashes))
+	for _, requestHash := range requestHashes {
+		if requestHash == "" {
+			return nil, fmt.Errorf("invalid request hashes: empty hash")
+		}
+		decision := engine.seededDecision(rule, requestHash)
+		// 命中规则的请求还要通过注入比例上限
+		if probability := s.injectionProbability(); probability < 1 {
+			random := engine.draw(rule, capDrawPath, requestHash)
+			decision.Draws[capDrawPath] = random
+			if probability <= 0 || random >= probability {
+				decision.Triggers = false
+			}
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, nil
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// toxiproxyStreamKey 动作元数据中指定toxic流向的键，默认downstream
+const toxiproxyStreamKey = "toxiproxy_stream"
+
+// ToxiproxyClient Toxiproxy管理接口（由client.ToxiproxyClient实现）
+type ToxiproxyClient interface {
+	ListToxics(ctx context.Context, proxy string) ([]*models.Toxic, error)
+	CreateToxic(ctx context.Context, proxy string, toxic *models.Toxic) error
+	DeleteToxic(ctx context.Context, proxy, name string) error
+}
+
+// ruleActivityChecker 检查规则当前是否生效（启用、未达到触发上限、在调度窗口内）
+type ruleActivityChecker interface {
+	isRuleActive(rule *models.ErrorRule) bool
+}
+
+// toxiproxyExecutor 把executor为toxiproxy的规则同步为Toxiproxy中的toxic
+type toxiproxyExecutor struct {
+	client  ToxiproxyClient
+	proxies map[string]string // 服务名到代理名
+
+	mu      sync.Mutex          // 串行执行同步
+	managed map[string]struct{} // 上次同步后可能有本服务toxic的代理，规则删除后需要清理
+	status  models.ToxiproxyStatus
+}
+
+// newToxiproxyExecutor 创建Toxiproxy执行器，配置的代理在首次同步时清理上次运行残留的toxic
+func newToxiproxyExecutor(client ToxiproxyClient, proxies map[string]string) *toxiproxyExecutor {
+	managed := make(map[string]struct{})
+	for _, proxy := range proxies {
+		managed[proxy] = struct{}{}
+	}
+	return &toxiproxyExecutor{
+		client:  client,
+		proxies: proxies,
+		managed: managed,
+		status:  models.ToxiproxyStatus{Enabled: true, Toxics: make([]*models.ToxiproxyToxic, 0)},
+	}
+}
+
+// proxyFor 返回服务对应的代理名
+func (t *toxiproxyExecutor) proxyFor(service string) string {
+	if proxy, ok := t.proxies[service]; ok {
+		return proxy
+	}
+	return service
+}
+
+// sync 使各代理上带前缀的toxic与期望一致：删除多余或已变化的，创建缺少的；其他toxic不受影响
+func (t *toxiproxyExecutor) sync(ctx context.Context, desired map[string]map[string]*models.Toxic, toxics []*models.ToxiproxyToxic) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	proxies := make([]string, 0, len(desired)+len(t.managed))
+	for proxy := range t.managed {
+		proxies = append(proxies, proxy)
+	}
+	for proxy := range desired {
+		if _, ok := t.managed[proxy]; !ok {
+			proxies = append(proxies, proxy)
+		}
+	}
+	sort.Strings(proxies)
+
+	var errs []error
+	managed := make(map[string]struct{})
+	for _, proxy := range proxies {
+		want := desired[proxy]
+		if err := t.syncProxy(ctx, proxy, want); err != nil {
+			errs = append(errs, err)
+			// 失败的代理下次继续清理
+			managed[proxy] = struct{}{}
+			continue
+		}
+		if len(want) > 0 {
+			managed[proxy] = struct{}{}
+		}
+	}
+	t.managed = managed
+
+	now := time.Now()
+	err := errors.Join(errs...)
+	t.status.Toxics = toxics
+	t.status.LastSyncAt = &now
+	t.status.LastError = ""
+	if err != nil {
+		t.status.LastError = err.Error()
+	}
+	return err
+}
+
+// syncProxy 同步单个代理上的toxic
+func (t *toxiproxyExecutor) syncProxy(ctx context.Context, proxy string, want map[string]*models.Toxic) error {
+	existing, err := t.client.ListToxics(ctx, proxy)
+	if err != nil {
+		return err
+	}
+
+	present := make(map[string]bool)
+	for _, toxic := range existing {
+		if !strings.HasPrefix(toxic.Name, models.ToxicNamePrefix) {
+			continue
+		}
+		if target, ok := want[toxic.Name]; ok && sameToxic(toxic, target) {
+			present[toxic.Name] = true
+			continue
+		}
+		if err := t.client.DeleteToxic(ctx, proxy, toxic.Name); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(want))
+	for name := range want {
+		if !present[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := t.client.CreateToxic(ctx, proxy, want[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshot 返回同步状态的副本
+func (t *toxiproxyExecutor) snapshot() *models.ToxiproxyStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status := t.status
+	status.Toxics = append([]*models.ToxiproxyToxic(nil), t.status.Toxics...)
+	return &status
+}
+
+// sameToxic 比较toxic的类型、流向、比例和规则设置的属性，Toxiproxy返回的默认属性不参与比较
+func sameToxic(existing, target *models.Toxic) bool {
+	if existing.Type != target.Type || existing.Stream != target.Stream || existing.Toxicity != target.Toxicity {
+		return false
+	}
+	for key, value := range target.Attributes {
+		if existing.Attributes[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleToxic 把规则转换为toxic：概率条件成为受影响连接的比例，动作映射为对应类型的toxic
+func ruleToxic(rule *models.ErrorRule) (*models.Toxic, error) {
+	toxicity := 1.0
+	switch {
+	case len(rule.Conditions) == 0:
+	case len(rule.Conditions) == 1 && rule.Conditions[0].Type == models.ErrorConditionTypeProbability:
+		probability, ok := conditionProbability(rule.Conditions[0])
+		if !ok {
+			return nil, fmt.Errorf("invalid probability condition")
+		}
+		toxicity = probability
+	default:
+		return nil, fmt.Errorf("toxiproxy executor only supports a single probability condition")
+	}
+
+	stream := models.ToxicStreamDownstream
+	if value, ok := rule.Action.Metadata[toxiproxyStreamKey]; ok {
+		stream, _ = value.(string)
+		if stream != models.ToxicStreamDownstream && stream != models.ToxicStreamUpstream {
+			return nil, fmt.Errorf("invalid %s: %v", toxiproxyStreamKey, value)
+		}
+	}
+
+	var delayMs int64
+	if rule.Action.Delay != nil {
+		delayMs = rule.Action.Delay.Milliseconds()
+	}
+
+	toxic := &models.Toxic{
+		Name:     models.ToxicNamePrefix + rule.ID,
+		Stream:   stream,
+		Toxicity: toxicity,
+	}
+	switch rule.Action.Type {
+	case models.ErrorActionTypeDelay:
+		if delayMs <= 0 {
+			return nil, fmt.Errorf("toxiproxy delay action requires positive delay")
+		}
+		toxic.Type = models.ToxicTypeLatency
+		toxic.Attributes = map[string]int64{"latency": delayMs}
+	case models.ErrorActionTypeTimeout:
+		toxic.Type = models.ToxicTypeTimeout
+		toxic.Attributes = map[string]int64{"timeout": delayMs}
+	case models.ErrorActionTypeThrottle:
+		if rule.Action.BytesPerSecond <= 0 {
+			return nil, fmt.Errorf("throttle action requires positive bytes_per_second")
+		}
+		toxic.Type = models.ToxicTypeBandwidth
+		toxic.Attributes = map[string]int64{"rate": max(rule.Action.BytesPerSecond/1024, 1)}
+	case models.ErrorActionTypeNetworkError:
+		toxic.Type = models.ToxicTypeResetPeer
+		toxic.Attributes = map[string]int64{"timeout": delayMs}
+	case models.ErrorActionTypeDisconnect:
+		if rule.Action.AbortAfterBytes <= 0 {
+			return nil, fmt.Errorf("toxiproxy disconnect action requires positive abort_after_bytes")
+		}
+		toxic.Type = models.ToxicTypeLimitData
+		toxic.Attributes = map[string]int64{"bytes": rule.Action.AbortAfterBytes}
+	default:
+		return nil, fmt.Errorf("action type %s is not supported by toxiproxy executor", rule.Action.Type)
+	}
+	return toxic, nil
+}
+
+// validateToxiproxyRule 验证由Toxiproxy执行的规则：只能指定服务，不支持按请求评估的设置
+func (s *ErrorInjectorService) validateToxiproxyRule(rule *models.ErrorRule) error {
+	if s.toxiproxy == nil {
+		return fmt.Errorf("toxiproxy executor is not enabled")
+	}
+	if rule.Service == "" {
+		return fmt.Errorf("toxiproxy executor requires service")
+	}
+	if rule.Operation != "" {
+		return fmt.Errorf("toxiproxy executor does not support operation")
+	}
+	if rule.Ramp != nil || rule.Burst != nil {
+		return fmt.Errorf("toxiproxy executor does not support ramp or burst")
+	}
+	_, err := ruleToxic(rule)
+	return err
+}
+
+// SetToxiproxy 启用Toxiproxy执行器，proxies为服务名到代理名的映射
+func (s *ErrorInjectorService) SetToxiproxy(client ToxiproxyClient, proxies map[string]string) {
+	s.toxiproxy = newToxiproxyExecutor(client, proxies)
+}
+
+// ToxiproxyStatus 返回Toxiproxy同步状态
+func (s *ErrorInjectorService) ToxiproxyStatus() *models.ToxiproxyStatus {
+	if s.toxiproxy == nil {
+		return &models.ToxiproxyStatus{Toxics: make([]*models.ToxiproxyToxic, 0)}
+	}
+	return s.toxiproxy.snapshot()
+}
+
+// SyncToxics 按当前生效的规则同步Toxiproxy中的toxic；全局或规则试运行、熔断开关开启时不创建toxic
+func (s *ErrorInjectorService) SyncToxics(ctx context.Context) (*models.ToxiproxyStatus, error) {
+	if s.toxiproxy == nil {
+		return nil, fmt.Errorf("toxiproxy executor is not enabled")
+	}
+
+	rules, err := s.ruleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+
+	checker, _ := s.ruleEngine.(ruleActivityChecker)
+	desired := make(map[string]map[string]*models.Toxic)
+	toxics := make([]*models.ToxiproxyToxic, 0)
+	for _, rule := range rules {
+		if rule.Executor != models.RuleExecutorToxiproxy || rule.DryRun || s.IsDryRun() || s.isKilled() || !rule.Enabled {
+			continue
+		}
+		if checker != nil && !checker.isRuleActive(rule) {
+			continue
+		}
+
+		toxic, err := ruleToxic(rule)
+		if err != nil {
+			s.logger.Warn(ctx, "Skipping toxiproxy rule",
+				observability.String("rule_id", rule.ID),
+				observability.String("error", err.Error()))
+			continue
+		}
+		proxy := s.toxiproxy.proxyFor(rule.Service)
+		if desired[proxy] == nil {
+			desired[proxy] = make(map[string]*models.Toxic)
+		}
+		desired[proxy][toxic.Name] = toxic
+		toxics = append(toxics, &models.ToxiproxyToxic{RuleID: rule.ID, Proxy: proxy, Toxic: toxic})
+	}
+
+	if err := s.toxiproxy.sync(ctx, desired, toxics); err != nil {
+		return s.toxiproxy.snapshot(), fmt.Errorf("failed to sync toxics: %w", err)
+	}
+	return s.toxiproxy.snapshot(), nil
+}
+
+// syncToxicsAfterChange 规则或试运行变化后立即同步，失败时由后台同步重试
+func (s *ErrorInjectorService) syncToxicsAfterChange(ctx context.Context) {
+	if s.toxiproxy == nil {
+		return
+	}
+	if _, err := s.SyncToxics(ctx); err != nil {
+		s.logger.Warn(ctx, "Toxiproxy sync failed",
+			observability.String("error", err.Error()))
+	}
+}
+
+// StartToxiproxySync 启动后台同步，使调度窗口的开始和结束、实验停止等变化在Toxiproxy中生效
+func (s *ErrorInjectorService) StartToxiproxySync(ctx context.Context) {
+	if s.toxiproxy == nil {
+		return
+	}
+	s.syncToxicsAfterChange(ctx)
+
+	interval := time.Duration(s.config.Toxiproxy.SyncIntervalSecs) * time.Second
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.syncToxicsAfterChange(ctx)
+			}
+		}
+	}()
+}
+
+// ClearToxics 删除本服务在Toxiproxy中创建的所有toxic，服务关闭前调用，避免故障在服务停止后残留
+func (s *ErrorInjectorService) ClearToxics(ctx context.Context) error {
+	if s.toxiproxy == nil {
+		return nil
+	}
+	return s.toxiproxy.sync(ctx, nil, make([]*models.ToxiproxyToxic, 0))
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/services/mock-error/internal/config"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// 规则触发通知的请求头
+const (
+	headerWebhookEvent   = "X-Mocks3-Event"
+	headerWebhookRuleID  = "X-Mocks3-Rule-ID"
+	headerWebhookAttempt = "X-Mocks3-Attempt"
+)
+
+// rateWindow 触发率的统计窗口
+const rateWindow = time.Minute
+
+// webhookNotifier 将规则触发通知POST到规则的webhook，失败时退避重试
+type webhookNotifier struct {
+	config config.WebhookConfig
+	client *http.Client
+
+	mu     sync.Mutex
+	states map[string]*webhookState // 键为规则ID和webhook地址
+}
+
+// webhookState 单个webhook的触发记录
+type webhookState struct {
+	triggers []time.Time // 统计窗口内的触发时间
+	lastSent time.Time
+}
+
+// newWebhookNotifier 创建通知投递器
+func newWebhookNotifier(cfg config.WebhookConfig) *webhookNotifier {
+	return &webhookNotifier{
+		config: cfg,
+		client: &http.Client{Timeout: cfg.GetTimeout()},
+		states: make(map[string]*webhookState),
+	}
+}
+
+// ValidateWebhook 验证规则的webhook配置
+func ValidateWebhook(webhook *models.RuleWebhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url: %q", webhook.URL)
+	}
+	if webhook.MinTriggersPerMinute < 0 {
+		return fmt.Errorf("invalid webhook min_triggers_per_minute: %d", webhook.MinTriggersPerMinute)
+	}
+	if webhook.CooldownSecs < 0 {
+		return fmt.Errorf("invalid webhook cooldown: %ds", webhook.CooldownSecs)
+	}
+	return nil
+}
+
+// notifyRuleTriggered 规则触发后按各webhook的条件异步发送通知，投递不阻塞注入检查
+func (s *ErrorInjectorService) notifyRuleTriggered(ctx context.Context, rule *models.ErrorRule, triggered int, service, operation string, action *models.ErrorAction) {
+	now := time.Now()
+	for _, webhook := range rule.Webhooks {
+		event, rate, ok := s.webhooks.record(rule.ID, webhook, now)
+		if !ok {
+			continue
+		}
+
+		payload := &models.RuleTriggerEvent{
+			Event:             event,
+			RuleID:            rule.ID,
+			RuleName:          rule.Name,
+			Service:           service,
+			Operation:         operation,
+			Action:            *action,
+			Triggered:         triggered,
+			TriggersPerMinute: rate,
+			Timestamp:         now,
+		}
+		if event == models.RuleEventRateExceeded {
+			payload.Text = fmt.Sprintf("Error rule %q triggered %d times in the last minute on %s %s (%s)",
+				rule.Name, rate, service, operation, action.Type)
+		} else {
+			payload.Text = fmt.Sprintf("Error rule %q injected %s into %s %s",
+				rule.Name, action.Type, service, operation)
+		}
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to marshal rule trigger event",
+				observability.String("rule_id", rule.ID),
+				observability.String("error", err.Error()))
+			continue
+		}
+		go s.deliverWebhook(rule.ID, webhook.URL, event, body)
+	}
+}
+
+// record 记录一次触发，返回是否需要通知、通知事件和最近一分钟的触发次数
+func (n *webhookNotifier) record(ruleID string, webhook *models.RuleWebhook, now time.Time) (string, int, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := ruleID + "|" + webhook.URL
+	state, exists := n.states[key]
+	if !exists {
+		state = &webhookState{}
+		n.states[key] = state
+	}
+
+	cooldown := time.Duration(webhook.CooldownSecs) * time.Second
+	if webhook.MinTriggersPerMinute == 0 {
+		if now.Sub(state.lastSent) < cooldown {
+			return "", 0, false
+		}
+		state.lastSent = now
+		return models.RuleEventTriggered, 0, true
+	}
+
+	// 只保留统计窗口内的触发时间
+	state.triggers = append(state.triggers, now)
+	cutoff := now.Add(-rateWindow)
+	for len(state.triggers) > 0 && !state.triggers[0].After(cutoff) {
+		state.triggers = state.triggers[1:]
+	}
+
+	rate := len(state.triggers)
+	if cooldown == 0 {
+		cooldown = rateWindow
+	}
+	if rate < webhook.MinTriggersPerMinute || now.Sub(state.lastSent) < cooldown {
+		return "", rate, false
+	}
+	state.lastSent = now
+	return models.RuleEventRateExceeded, rate, true
+}
+
+// forget 删除规则的触发记录
+func (n *webhookNotifier) forget(ruleID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for key := range n.states {
+		if len(key) > len(ruleID) && key[:len(ruleID)+1] == ruleID+"|" {
+			delete(n.states, key)
+		}
+	}
+}
+
+// deliverWebhook 投递通知直到成功、遇到不可重试的响应或用尽次数
+func (s *ErrorInjectorService) deliverWebhook(ruleID, webhookURL, event string, body []byte) {
+	notifier := s.webhooks
+	backoff := notifier.config.GetBackoff()
+	ctx := context.Background()
+
+	var err error
+	for attempt := 1; attempt <= notifier.config.MaxAttempts; attempt++ {
+		var retryable bool
+		if retryable, err = notifier.post(ctx, webhookURL, event, ruleID, attempt, body); err == nil {
+			s.logger.Debug(ctx, "Rule trigger event delivered",
+				observability.String("rule_id", ruleID),
+				observability.String("event", event),
+				observability.Int("attempt", attempt))
+			return
+		}
+		if !retryable || attempt == notifier.config.MaxAttempts {
+			break
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if maxBackoff := notifier.config.GetMaxBackoff(); backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	s.logger.Warn(ctx, "Failed to deliver rule trigger event",
+		observability.String("rule_id", ruleID),
+		observability.String("event", event),
+		observability.String("webhook_url", webhookURL),
+		observability.String("error", err.Error()))
+}
+
+// post 发送一次通知，返回失败是否可重试：网络错误、408、429和5xx可重试，其他非2xx响应不重试
+func (n *webhookNotifier) post(ctx context.Context, webhookURL, event, ruleID string, attempt int, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerWebhookEvent, event)
+	req.Header.Set(headerWebhookRuleID, ruleID)
+	req.Header.Set(headerWebhookAttempt, fmt.Sprint(attempt))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("do request: %w", err)
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return true, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+}
@@ -8,8 +8,11 @@ import (
 	"mocks3/services/queue/internal/handler"
 	"mocks3/services/queue/internal/repository"
 	"mocks3/services/queue/internal/service"
+	"mocks3/shared/client"
 	"mocks3/shared/middleware"
+	"mocks3/shared/models"
 	"mocks3/shared/observability"
+	"mocks3/shared/utils"
 	"net/http"
 	"os"
 	"os/signal"
@@ -22,6 +25,9 @@ import (
 func main() {
 	// 加载配置
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
 
 	// 初始化统一可观测性
 	obsConfig := &observability.Config{
@@ -30,6 +36,15 @@ func main() {
 		Environment:    cfg.Server.Environment,
 		OTLPEndpoint:   "http://localhost:4318",
 		LogLevel:       cfg.LogLevel,
+		Debug:          observability.DebugConfigFromEnv(),
+		Profiling:      observability.ProfilingConfigFromEnv(),
+		AuditLog:       observability.AuditLogConfigFromEnv(),
+		LogSampling:    observability.LogSamplingConfigFromEnv(),
+		LogFile:        observability.LogFileConfigFromEnv(),
+		SLOs: []observability.SLO{
+			observability.AvailabilitySLO("availability", 0.999),
+			observability.LatencySLO("latency", 0.99, 200*time.Millisecond),
+		},
 	}
 
 	obs, err := observability.New(context.Background(), obsConfig)
@@ -40,23 +55,39 @@ func main() {
 
 	logger := obs.Logger()
 
+	// 初始化全局ID生成器
+	idStrategy, err := utils.ConfigureIDGeneratorFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure ID generator: %v", err)
+	}
+	logger.Info(context.Background(), "ID generator configured", observability.String("strategy", idStrategy))
+
 	// 初始化Consul管理器
 	consulManager, err := middleware.NewDefaultConsulManager("queue-service")
 	if err != nil {
 		log.Fatalf("Failed to initialize consul: %v", err)
 	}
 
-	// 初始化Redis仓库
-	redisRepo, err := repository.NewRedisRepository(&cfg.Redis, &cfg.Queue)
+	// 初始化队列：默认队列和QUEUE_QUEUES中的命名队列，各自有独立的任务流、worker池和重试策略
+	defaultQueue, err := newQueueService(cfg, &cfg.Queue, logger)
 	if err != nil {
-		log.Fatalf("Failed to initialize Redis repository: %v", err)
+		log.Fatalf("Failed to initialize queue %s: %v", cfg.Queue.Name, err)
 	}
-
-	// 初始化服务
-	queueService := service.NewQueueService(redisRepo, logger)
+	queues := service.NewQueueRegistry(cfg.Queue.Name, defaultQueue)
+	for i := range cfg.Queues {
+		queueConfig := &cfg.Queues[i]
+		queueService, err := newQueueService(cfg, queueConfig, logger)
+		if err != nil {
+			log.Fatalf("Failed to initialize queue %s: %v", queueConfig.Name, err)
+		}
+		queues.Register(queueConfig.Name, queueService, queueConfig.TaskTypes)
+	}
+	logger.Info(context.Background(), "Queue backend initialized",
+		observability.String("backend", cfg.GetBackend()),
+		observability.Int("queues", len(queues.Names())))
 
 	// 初始化处理器
-	queueHandler := handler.NewQueueHandler(queueService, logger)
+	queueHandler := handler.NewQueueHandler(queues, logger)
 
 	// 注册服务到Consul
 	ctx := context.Background()
@@ -76,17 +107,15 @@ func main() {
 	}
 	defer consulManager.DeregisterService(ctx)
 
-	// 启动默认工作节点
-	for i := 1; i <= cfg.Queue.MaxWorkers; i++ {
-		workerID := fmt.Sprintf("worker-%d", i)
-		if err := queueService.StartWorker(ctx, workerID); err != nil {
-			logger.Error(context.Background(), "Failed to start worker", 
-				observability.String("worker_id", workerID), 
-				observability.String("error", err.Error()))
-		} else {
-			logger.Info(context.Background(), "Started worker", 
-				observability.String("worker_id", workerID))
-		}
+	// 每个队列启动各自的worker池（按积压和处理耗时自动伸缩）、延迟任务调度和心跳超时检查
+	for _, queueConfig := range cfg.AllQueues() {
+		queueService, _ := queues.Get(queueConfig.Name)
+		// 后续任务按类型路由，可以进入其他队列
+		queueService.SetNextEnqueuer(queues.AddTask)
+		queueService.SetMetricCollector(obs.Collector())
+		queueService.StartAutoscaler(queueConfig.MinWorkers, queueConfig.MaxWorkers, queueConfig.Autoscale)
+		queueService.StartScheduler(queueConfig.GetSchedulerInterval())
+		queueService.StartReaper()
 	}
 
 	// 设置Gin模式
@@ -100,15 +129,59 @@ func main() {
 	// 添加中间件
 	router.Use(gin.Logger())
 	router.Use(middleware.GinRecoveryMiddleware(middleware.DefaultRecoveryConfig()))
+	// 请求ID：沿用或生成X-Request-ID，日志和出站调用自动带上
+	router.Use(middleware.RequestIDMiddleware())
+	// baggage：读取上游透传的租户、测试运行ID和场景ID，日志和错误注入条件自动带上
+	router.Use(obs.GinBaggageMiddleware())
 	// 使用统一可观测性中间件
 	router.Use(obs.GinMiddleware())
 
+	// 管理接口的修改操作写入审计日志
+	router.Use(obs.AuditLogger().GinAdminMiddleware("/api/v1/admin"))
+
+	// 管理接口鉴权和飞行记录器
+	authorizer, flightRecorder, err := setupAdmin(router, cfg, obs, client.NewStorageClient(cfg.FlightRecorder.StorageURL, 10*time.Second))
+	if err != nil {
+		log.Fatalf("Failed to set up admin endpoints: %v", err)
+	}
+
+	// 多租户：任务归属请求租户，查询只返回该租户的任务
+	if cfg.Tenancy.Enabled {
+		tenantResolver, err := newTenantResolver(cfg.Tenancy, authorizer)
+		if err != nil {
+			log.Fatalf("Failed to initialize tenant resolver: %v", err)
+		}
+		router.Use(tenantResolver.GinMiddleware())
+	}
+
+	// 运行时修改日志级别，排查问题时临时切换到debug
+	middleware.NewLogLevelController(logger).RegisterAdminRoutes(router.Group("/api/v1/admin"))
+
+	// 错误注入：每个请求向mock-error查询是否注入错误，放在业务路由之前
+	if cfg.FaultInjection.Enabled {
+		faultInjector, err := newFaultInjector(cfg.FaultInjection, logger)
+		if err != nil {
+			log.Fatalf("Failed to initialize fault injector: %v", err)
+		}
+		router.Use(faultInjector.GinMiddleware())
+	}
+
 	// 设置路由
 	queueHandler.RegisterRoutes(router)
 
-	// 健康检查
+	// 健康检查，附带队列是否已暂停
 	router.GET("/health", func(c *gin.Context) {
-		if err := queueService.HealthCheck(c.Request.Context()); err != nil {
+		if err := queues.HealthCheck(c.Request.Context()); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":  "unhealthy",
+				"service": "queue-service",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		states, err := queues.QueueStates(c.Request.Context())
+		if err != nil {
 			c.JSON(http.StatusServiceUnavailable, gin.H{
 				"status":  "unhealthy",
 				"service": "queue-service",
@@ -122,6 +195,7 @@ func main() {
 			"service":   "queue-service",
 			"version":   cfg.Server.Version,
 			"timestamp": time.Now().Format(time.RFC3339),
+			"queues":    states,
 		})
 	})
 
@@ -155,7 +229,7 @@ func main() {
 	defer cancel()
 
 	// 停止队列服务
-	if err := queueService.Stop(); err != nil {
+	if err := queues.Stop(); err != nil {
 		logger.Error(context.Background(), "Failed to stop queue service", 
 			observability.String("error", err.Error()))
 	}
@@ -165,5 +239,125 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// 等待进行中的事件包上传完成
+	if flightRecorder != nil {
+		flightRecorder.Wait(ctx)
+	}
+
 	logger.Info(context.Background(), "Queue service stopped")
 }
+
+// newQueueService 按队列配置创建存储后端和队列服务
+func newQueueService(cfg *config.Config, queueConfig *config.QueueConfig, logger *observability.Logger) (*service.QueueService, error) {
+	var repo repository.QueueRepository
+	var err error
+	switch cfg.GetBackend() {
+	case config.BackendMemory:
+		repo = repository.NewMemoryRepository(queueConfig)
+	case config.BackendKafka:
+		repo, err = repository.NewKafkaRepository(&cfg.Redis, queueConfig, cfg.KafkaFor(queueConfig))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Kafka repository: %w", err)
+		}
+	case config.BackendNats:
+		repo, err = repository.NewNatsRepository(&cfg.Redis, queueConfig, cfg.NatsFor(queueConfig))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize NATS repository: %w", err)
+		}
+	default:
+		repo, err = repository.NewRedisRepository(&cfg.Redis, queueConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Redis repository: %w", err)
+		}
+	}
+
+	queueService := service.NewQueueService(repo, logger)
+	queueService.SetRetryPolicies(queueConfig.Retry, queueConfig.RetryPolicies)
+	queueService.SetMaxEnqueueBatch(queueConfig.MaxEnqueueBatch)
+	queueService.SetWebhookConfig(queueConfig.Webhook)
+	queueService.SetDedupWindow(queueConfig.GetDedupWindow())
+	queueService.SetHeartbeat(queueConfig.GetHeartbeatInterval(), queueConfig.GetHeartbeatTimeout())
+	// Kafka后端的消费者组成员独占分区，外部消费者领取任务只支持Redis任务流和NATS持久消费者
+	if queueConfig.Backend == config.BackendRedis || queueConfig.Backend == config.BackendNats {
+		queueService.SetVisibilityTimeout(queueConfig.GetVisibilityTimeout(), queueConfig.GetMaxVisibilityTimeout())
+	}
+	queueService.SetRateLimits(queueConfig.RateLimits)
+	return queueService, nil
+}
+
+// setupAdmin 安装管理接口鉴权和飞行记录器，未启用的功能返回nil；
+// gin在注册路由时固定处理链，鉴权中间件必须先于任何管理接口注册，否则这些接口不经过鉴权
+func setupAdmin(router *gin.Engine, cfg *config.Config, obs *observability.Observability, store middleware.IncidentStore) (*middleware.Authorizer, *middleware.FlightRecorder, error) {
+	var authorizer *middleware.Authorizer
+	if cfg.RBAC.Enabled {
+		var err error
+		authorizer, err = newAuthorizer(cfg.RBAC)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize authorizer: %w", err)
+		}
+		router.Use(authorizer.GinMiddleware())
+		authorizer.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	// 飞行记录器：保留最近的请求、日志和span，SLO违规或护栏触发时转储事件包到存储bucket
+	var flightRecorder *middleware.FlightRecorder
+	if cfg.FlightRecorder.Enabled {
+		var err error
+		flightRecorder, err = newFlightRecorder(cfg.FlightRecorder, store, obs.Logger())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize flight recorder: %w", err)
+		}
+		flightRecorder.SetMetricCollector(obs.Collector())
+		flightRecorder.Attach(obs)
+		router.Use(obs.GinTracingMiddleware())
+		router.Use(flightRecorder.GinMiddleware())
+		flightRecorder.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	return authorizer, flightRecorder, nil
+}
+
+// newAuthorizer 根据配置创建管理接口鉴权器（/api/v1/admin/*）
+func newAuthorizer(cfg config.RBACConfig) (*middleware.Authorizer, error) {
+	rbacConfig := middleware.DefaultRBACConfig()
+	rbacConfig.Header = cfg.Header
+	for _, key := range cfg.Keys {
+		rbacConfig.Keys = append(rbacConfig.Keys, &models.APIKey{Name: key.Name, Key: key.Key, Role: key.Role})
+	}
+	return middleware.NewAuthorizer(rbacConfig)
+}
+
+// newTenantResolver 根据配置创建租户解析器，authorizer为nil时只从请求头读取租户
+func newTenantResolver(cfg config.TenancyConfig, authorizer *middleware.Authorizer) (*middleware.TenantResolver, error) {
+	tenantConfig := middleware.DefaultTenantConfig()
+	tenantConfig.Header = cfg.Header
+	tenantConfig.DefaultTenant = cfg.DefaultTenant
+	tenantConfig.Required = cfg.Required
+	return middleware.NewTenantResolver(tenantConfig, authorizer)
+}
+
+// newFlightRecorder 根据配置创建飞行记录器
+func newFlightRecorder(cfg config.FlightRecorderConfig, store middleware.IncidentStore, logger *observability.Logger) (*middleware.FlightRecorder, error) {
+	recorderConfig := middleware.DefaultFlightRecorderConfig("queue-service")
+	recorderConfig.Bucket = cfg.Bucket
+	recorderConfig.RequestCapacity = cfg.RequestCapacity
+	recorderConfig.LogCapacity = cfg.LogCapacity
+	recorderConfig.SpanCapacity = cfg.SpanCapacity
+	recorderConfig.SLOWindow = time.Duration(cfg.SLOWindowSecs) * time.Second
+	recorderConfig.MinRequests = cfg.MinRequests
+	recorderConfig.MaxErrorRate = cfg.MaxErrorRate
+	recorderConfig.LatencyThreshold = time.Duration(cfg.LatencyThresholdMs) * time.Millisecond
+	recorderConfig.MaxSlowRate = cfg.MaxSlowRate
+	recorderConfig.Cooldown = time.Duration(cfg.CooldownSecs) * time.Second
+	return middleware.NewFlightRecorder(recorderConfig, store, logger)
+}
+
+// newFaultInjector 根据配置创建错误注入客户端中间件
+func newFaultInjector(cfg config.FaultInjectionConfig, logger *observability.Logger) (*middleware.FaultInjector, error) {
+	timeout := time.Duration(cfg.CheckTimeoutMs) * time.Millisecond
+	injectionConfig := middleware.DefaultFaultInjectionConfig("queue-service")
+	injectionConfig.CheckTimeout = timeout
+	mockErrorClient := client.NewMockErrorClient(cfg.MockErrorURL, timeout)
+	mockErrorClient.SetNamespace(cfg.Namespace)
+	return middleware.NewFaultInjector(injectionConfig, mockErrorClient, logger)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"mocks3/services/queue/internal/config"
+	"mocks3/shared/middleware"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testAdminKey = "test-admin-key"
+
+// nopIncidentStore 测试用事件包存储，不保存任何内容
+type nopIncidentStore struct{}
+
+func (nopIncidentStore) WriteObject(ctx context.Context, object *models.Object) error {
+	return nil
+}
+
+func (nopIncidentStore) ReadObject(ctx context.Context, bucket, key string) (*models.Object, error) {
+	return nil, errors.New("not found")
+}
+
+// newTestObservability 创建不导出数据的可观测性实例
+func newTestObservability(t *testing.T) *observability.Observability {
+	t.Helper()
+	obs, err := observability.New(context.Background(), &observability.Config{
+		ServiceName:    "queue-service",
+		ServiceVersion: "test",
+		Environment:    "test",
+		OTLPEndpoint:   "localhost:4318",
+		LogLevel:       "error",
+	})
+	if err != nil {
+		t.Fatalf("observability.New: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		obs.Shutdown(ctx)
+	})
+	return obs
+}
+
+// newTestAdminRouter 按main的顺序安装鉴权、飞行记录器和日志级别接口
+func newTestAdminRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Load()
+	cfg.RBAC.Enabled = true
+	cfg.RBAC.Keys = []config.APIKeyConfig{{Name: "admin", Key: testAdminKey, Role: models.RoleSuperAdmin}}
+	cfg.FlightRecorder.Enabled = true
+
+	obs := newTestObservability(t)
+	router := gin.New()
+	if _, _, err := setupAdmin(router, cfg, obs, nopIncidentStore{}); err != nil {
+		t.Fatalf("setupAdmin: %v", err)
+	}
+	middleware.NewLogLevelController(obs.Logger()).RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	return router
+}
+
+func TestIncidentRoutesRequireAPIKey(t *testing.T) {
+	router := newTestAdminRouter(t)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/v1/admin/incidents"},
+		{http.MethodPost, "/api/v1/admin/incidents"},
+		{http.MethodGet, "/api/v1/admin/incidents/some-id"},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s without api key: status = %d, want %d", tc.method, tc.path, w.Code, http.StatusUnauthorized)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/incidents", nil)
+	req.Header.Set("X-Api-Key", testAdminKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /api/v1/admin/incidents with api key: status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestLogLevelRequiresAPIKey(t *testing.T) {
+	router := newTestAdminRouter(t)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/loglevel", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("PUT /api/v1/admin/loglevel without api key: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -2,8 +2,13 @@ package config
 
 import (
 	"fmt"
+	"mocks3/shared/models"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // ServerConfig 服务器配置
@@ -21,6 +26,7 @@ func (s *ServerConfig) GetAddress() string {
 
 // RedisConfig Redis配置
 type RedisConfig struct {
+	Enabled  bool   `json:"enabled"` // 关闭时使用进程内队列，不依赖Redis
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
 	Password string `json:"password"`
@@ -32,22 +38,302 @@ func (r *RedisConfig) GetAddress() string {
 	return fmt.Sprintf("%s:%d", r.Host, r.Port)
 }
 
+// 队列后端
+const (
+	BackendRedis  = "redis"
+	BackendKafka  = "kafka"
+	BackendNats   = "nats"
+	BackendMemory = "memory" // Redis未启用时使用，不能通过QUEUE_BACKEND选择
+)
+
 // QueueConfig 队列配置
 type QueueConfig struct {
-	MaxWorkers     int    `json:"max_workers"`
-	MaxRetries     int    `json:"max_retries"`
-	StreamName     string `json:"stream_name"`
-	ConsumerGroup  string `json:"consumer_group"`
-	BatchSize      int    `json:"batch_size"`
-	ProcessTimeout int    `json:"process_timeout_seconds"`
+	Name            string `json:"name"`    // 队列名称，用于管理接口和指标
+	Backend         string `json:"backend"` // 任务流后端：redis、kafka或nats
+	MinWorkers      int    `json:"min_workers"`
+	MaxWorkers      int    `json:"max_workers"`
+	StreamName      string `json:"stream_name"`
+	ConsumerGroup   string `json:"consumer_group"`
+	ConsumerName    string `json:"consumer_name"` // 本实例在消费者组中的名称前缀，每个worker的消费者名为前缀:worker ID
+	BatchSize       int    `json:"batch_size"`
+	MaxEnqueueBatch int    `json:"max_enqueue_batch"` // 批量入队接口单次最多接受的任务数
+	ProcessTimeout  int    `json:"process_timeout_seconds"`
+	TopicPrefix     string `json:"topic_prefix"`  // 事件主题流名称前缀
+	TopicMaxLen     int64  `json:"topic_max_len"` // 每个主题保留的最大事件数（近似裁剪）
+
+	DeadLetterQueue     string `json:"dead_letter_queue"`     // 超过重试次数的任务所在的死信队列，为空时为stream_name加:failed
+	SchedulerIntervalMs int    `json:"scheduler_interval_ms"` // 检查到期延迟任务的间隔，0表示不移动延迟任务
+	JobTTLSeconds       int    `json:"job_ttl_seconds"`       // 任务执行状态的保留时间，从最后一次更新开始计算
+	ClaimIdleMs         int    `json:"claim_idle_ms"`         // 已投递但超过该时间未确认的任务视为持有的worker已崩溃，由其他worker接管，0表示不接管
+	MaxDeliveries       int    `json:"max_deliveries"`        // 任务最多被投递的次数，接管时超过该次数的任务移入死信队列，0表示不限制
+	DedupWindowSecs     int    `json:"dedup_window_secs"`     // 该时间内payload相同的任务只入队一次，0表示不去重
+	HeartbeatIntervalMs int    `json:"heartbeat_interval_ms"` // worker处理任务时上报心跳的间隔，也是检查心跳超时的间隔
+	HeartbeatTimeoutMs  int    `json:"heartbeat_timeout_ms"`  // 超过该时间未上报心跳的任务视为worker已崩溃，重新入队，0表示不检查
+
+	VisibilityTimeoutSecs    int `json:"visibility_timeout_secs"`     // 外部消费者领取任务时默认的不可见时间
+	MaxVisibilityTimeoutSecs int `json:"max_visibility_timeout_secs"` // 领取或延长租约时不可见时间的上限
+
+	Retry         RetryPolicy            `json:"retry"`          // 默认重试策略
+	RetryPolicies map[string]RetryPolicy `json:"retry_policies"` // 按任务类型覆盖的重试策略
+
+	RateLimits map[string]models.TaskRateLimit `json:"rate_limits"` // 启动时按任务类型设置的处理速率限制，运行时可通过API修改
+
+	Autoscale AutoscaleConfig `json:"autoscale"`
+	Webhook   WebhookConfig   `json:"webhook"`
+
+	TaskTypes []string `json:"task_types"` // 入队时未指定队列的这些类型的任务进入该命名队列，其他任务进入默认队列
+}
+
+// WebhookConfig 任务结束通知的投递配置
+type WebhookConfig struct {
+	MaxAttempts  int `json:"max_attempts"`   // 最多投递次数（含首次）
+	BackoffMs    int `json:"backoff_ms"`     // 第一次重试前的等待时间，之后每次翻倍
+	MaxBackoffMs int `json:"max_backoff_ms"` // 等待时间上限
+	TimeoutMs    int `json:"timeout_ms"`     // 单次请求超时
+}
+
+// GetBackoff 获取第一次重试前的等待时间
+func (w *WebhookConfig) GetBackoff() time.Duration {
+	return time.Duration(w.BackoffMs) * time.Millisecond
+}
+
+// GetMaxBackoff 获取等待时间上限
+func (w *WebhookConfig) GetMaxBackoff() time.Duration {
+	return time.Duration(w.MaxBackoffMs) * time.Millisecond
+}
+
+// GetTimeout 获取单次请求超时
+func (w *WebhookConfig) GetTimeout() time.Duration {
+	return time.Duration(w.TimeoutMs) * time.Millisecond
+}
+
+// AutoscaleConfig worker池自动伸缩配置，worker数在MinWorkers和MaxWorkers之间
+type AutoscaleConfig struct {
+	IntervalMs             int `json:"interval_ms"`               // 检查积压和处理耗时的间隔
+	TargetBacklogPerWorker int `json:"target_backlog_per_worker"` // 每个worker期望承担的积压任务数
+	TargetLatencyMs        int `json:"target_latency_ms"`         // 平均处理耗时超过该值且有积压时增加worker，0表示不按耗时扩容
+	ScaleDownCooldownSecs  int `json:"scale_down_cooldown_secs"`  // 距上次伸缩至少该时间后才减少worker
+}
+
+// GetInterval 获取检查间隔
+func (a *AutoscaleConfig) GetInterval() time.Duration {
+	return time.Duration(a.IntervalMs) * time.Millisecond
+}
+
+// GetTargetLatency 获取目标处理耗时
+func (a *AutoscaleConfig) GetTargetLatency() time.Duration {
+	return time.Duration(a.TargetLatencyMs) * time.Millisecond
+}
+
+// GetScaleDownCooldown 获取缩容冷却时间
+func (a *AutoscaleConfig) GetScaleDownCooldown() time.Duration {
+	return time.Duration(a.ScaleDownCooldownSecs) * time.Second
+}
+
+// GetSchedulerInterval 获取检查到期延迟任务的间隔
+func (q *QueueConfig) GetSchedulerInterval() time.Duration {
+	return time.Duration(q.SchedulerIntervalMs) * time.Millisecond
+}
+
+// GetClaimIdle 获取接管未确认任务前的空闲时间
+func (q *QueueConfig) GetClaimIdle() time.Duration {
+	return time.Duration(q.ClaimIdleMs) * time.Millisecond
+}
+
+// GetVisibilityTimeout 获取默认的不可见时间
+func (q *QueueConfig) GetVisibilityTimeout() time.Duration {
+	return time.Duration(q.VisibilityTimeoutSecs) * time.Second
+}
+
+// GetMaxVisibilityTimeout 获取不可见时间的上限
+func (q *QueueConfig) GetMaxVisibilityTimeout() time.Duration {
+	return time.Duration(q.MaxVisibilityTimeoutSecs) * time.Second
+}
+
+// GetDedupWindow 获取按payload去重的时间窗口
+func (q *QueueConfig) GetDedupWindow() time.Duration {
+	return time.Duration(q.DedupWindowSecs) * time.Second
+}
+
+// GetHeartbeatInterval 获取worker上报心跳的间隔
+func (q *QueueConfig) GetHeartbeatInterval() time.Duration {
+	return time.Duration(q.HeartbeatIntervalMs) * time.Millisecond
+}
+
+// GetHeartbeatTimeout 获取心跳超时时间
+func (q *QueueConfig) GetHeartbeatTimeout() time.Duration {
+	return time.Duration(q.HeartbeatTimeoutMs) * time.Millisecond
+}
+
+// GetJobTTL 获取任务执行状态的保留时间
+func (q *QueueConfig) GetJobTTL() time.Duration {
+	return time.Duration(q.JobTTLSeconds) * time.Second
+}
+
+// KafkaConfig Kafka后端配置：任务流写入Kafka topic，由消费者组按分区分配给worker
+type KafkaConfig struct {
+	Brokers             []string `json:"brokers"`
+	Topic               string   `json:"topic"`
+	Partitions          int      `json:"partitions"` // 启动时topic不存在则按该分区数创建
+	ReplicationFactor   int      `json:"replication_factor"`
+	ClientID            string   `json:"client_id"`
+	SessionTimeoutMs    int      `json:"session_timeout_ms"`
+	HeartbeatIntervalMs int      `json:"heartbeat_interval_ms"`
+	Compression         string   `json:"compression"` // 写入消息的压缩算法：none、gzip、snappy、lz4或zstd
+	TLS                 bool     `json:"tls"`
+	TLSCAFile           string   `json:"tls_ca_file"`    // 为空时使用系统根证书
+	SASLMechanism       string   `json:"sasl_mechanism"` // 为空时不认证：PLAIN、SCRAM-SHA-256或SCRAM-SHA-512
+	SASLUsername        string   `json:"sasl_username"`
+	SASLPassword        string   `json:"sasl_password"`
+}
+
+// Kafka SASL认证机制
+const (
+	KafkaSASLPlain       = "PLAIN"
+	KafkaSASLScramSHA256 = "SCRAM-SHA-256"
+	KafkaSASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// kafkaCompressions 支持的消息压缩算法
+var kafkaCompressions = []string{"none", "gzip", "snappy", "lz4", "zstd"}
+
+// GetSessionTimeout 获取消费者组会话超时
+func (k *KafkaConfig) GetSessionTimeout() time.Duration {
+	return time.Duration(k.SessionTimeoutMs) * time.Millisecond
+}
+
+// GetHeartbeatInterval 获取心跳间隔
+func (k *KafkaConfig) GetHeartbeatInterval() time.Duration {
+	return time.Duration(k.HeartbeatIntervalMs) * time.Millisecond
+}
+
+// kafkaTopicPattern Kafka topic名称允许的字符
+var kafkaTopicPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+
+// Validate 验证Kafka后端配置
+func (k *KafkaConfig) Validate() error {
+	if len(k.Brokers) == 0 {
+		return fmt.Errorf("at least one kafka broker is required")
+	}
+	if !kafkaTopicPattern.MatchString(k.Topic) {
+		return fmt.Errorf("invalid kafka topic: %q", k.Topic)
+	}
+	if k.Partitions <= 0 {
+		return fmt.Errorf("invalid kafka partitions: %d", k.Partitions)
+	}
+	if k.ReplicationFactor <= 0 {
+		return fmt.Errorf("invalid kafka replication factor: %d", k.ReplicationFactor)
+	}
+	if k.HeartbeatIntervalMs <= 0 || k.SessionTimeoutMs < 3*k.HeartbeatIntervalMs {
+		return fmt.Errorf("kafka session timeout %dms must be at least three heartbeat intervals (%dms)", k.SessionTimeoutMs, k.HeartbeatIntervalMs)
+	}
+	if !slices.Contains(kafkaCompressions, k.Compression) {
+		return fmt.Errorf("invalid kafka compression: %q", k.Compression)
+	}
+	switch k.SASLMechanism {
+	case "":
+	case KafkaSASLPlain, KafkaSASLScramSHA256, KafkaSASLScramSHA512:
+		if k.SASLUsername == "" {
+			return fmt.Errorf("kafka sasl username is required for %s", k.SASLMechanism)
+		}
+	default:
+		return fmt.Errorf("unsupported kafka sasl mechanism: %q", k.SASLMechanism)
+	}
+	return nil
+}
+
+// NatsConfig NATS JetStream后端配置：每个队列对应stream中的一个subject，由该队列的持久拉取消费者分发给worker
+type NatsConfig struct {
+	Servers       []string `json:"servers"`
+	Stream        string   `json:"stream"`         // 启动时不存在则创建，保留策略为workqueue（确认后删除）
+	SubjectPrefix string   `json:"subject_prefix"` // 队列的subject为前缀加.队列名称
+	Subject       string   `json:"subject"`        // 队列的subject，由NatsFor设置
+	Replicas      int      `json:"replicas"`
+	ClientName    string   `json:"client_name"`
+}
+
+// natsNamePattern NATS stream和消费者名称、subject token允许的字符
+var natsNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// natsSubjectPattern 由点分隔的token组成的subject，不含通配符
+var natsSubjectPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+(\.[a-zA-Z0-9_-]+)*$`)
+
+// Validate 验证NATS后端配置
+func (n *NatsConfig) Validate() error {
+	if len(n.Servers) == 0 {
+		return fmt.Errorf("at least one nats server is required")
+	}
+	if !natsNamePattern.MatchString(n.Stream) {
+		return fmt.Errorf("invalid nats stream: %q", n.Stream)
+	}
+	if !natsSubjectPattern.MatchString(n.SubjectPrefix) {
+		return fmt.Errorf("invalid nats subject prefix: %q", n.SubjectPrefix)
+	}
+	if n.Replicas < 1 || n.Replicas > 5 {
+		return fmt.Errorf("invalid nats replicas: %d", n.Replicas)
+	}
+	return nil
+}
+
+// FlightRecorderConfig 飞行记录器配置：保留最近的请求、日志和span，SLO违规或护栏触发时转储到存储bucket
+type FlightRecorderConfig struct {
+	Enabled            bool    `json:"enabled"`
+	StorageURL         string  `json:"storage_url"`
+	Bucket             string  `json:"bucket"`
+	RequestCapacity    int     `json:"request_capacity"`
+	LogCapacity        int     `json:"log_capacity"`
+	SpanCapacity       int     `json:"span_capacity"`
+	SLOWindowSecs      int     `json:"slo_window_secs"`
+	MinRequests        int     `json:"min_requests"`   // 窗口内请求数少于该值时不判定SLO
+	MaxErrorRate       float64 `json:"max_error_rate"` // 5xx比例阈值，0表示不检查
+	LatencyThresholdMs int     `json:"latency_threshold_ms"`
+	MaxSlowRate        float64 `json:"max_slow_rate"` // 慢请求比例阈值，0表示不检查
+	CooldownSecs       int     `json:"cooldown_secs"` // 两次自动抓取的最小间隔
+}
+
+// FaultInjectionConfig 错误注入配置：每个请求向mock-error查询是否注入错误并执行命中的动作
+type FaultInjectionConfig struct {
+	Enabled        bool   `json:"enabled"`
+	MockErrorURL   string `json:"mock_error_url"`
+	Namespace      string `json:"namespace"`        // 只评估该命名空间的规则，为空时为default
+	CheckTimeoutMs int    `json:"check_timeout_ms"` // 单次检查超时，超时或失败时不注入
+}
+
+// TenancyConfig 多租户配置：任务按X-Tenant-Id请求头归属租户
+type TenancyConfig struct {
+	Enabled       bool   `json:"enabled"`
+	Header        string `json:"header"`
+	DefaultTenant string `json:"default_tenant"` // 未指定租户的请求使用的租户
+	Required      bool   `json:"required"`       // 要求每个请求显式指定租户
+}
+
+// RBACConfig 管理接口鉴权配置
+type RBACConfig struct {
+	Enabled bool           `json:"enabled"`
+	Header  string         `json:"header"`
+	Keys    []APIKeyConfig `json:"keys"`
+}
+
+// APIKeyConfig 初始API key
+type APIKeyConfig struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	Role string `json:"role"` // viewer, operator, chaos-admin, super-admin
 }
 
 // Config 应用配置
 type Config struct {
-	Server   ServerConfig `json:"server"`
-	Redis    RedisConfig  `json:"redis"`
-	Queue    QueueConfig  `json:"queue"`
-	LogLevel string       `json:"log_level"`
+	Server         ServerConfig         `json:"server"`
+	Redis          RedisConfig          `json:"redis"`
+	Queue          QueueConfig          `json:"queue"`  // 默认队列
+	Queues         []QueueConfig        `json:"queues"` // 命名队列，各自有独立的任务流、worker池和重试策略
+	Kafka          KafkaConfig          `json:"kafka"`
+	Nats           NatsConfig           `json:"nats"`
+	RBAC           RBACConfig           `json:"rbac"`
+	FlightRecorder FlightRecorderConfig `json:"flight_recorder"`
+	FaultInjection FaultInjectionConfig `json:"fault_injection"`
+	Tenancy        TenancyConfig        `json:"tenancy"`
+	LogLevel       string               `json:"log_level"`
 }
 
 // Load 加载配置
@@ -60,25 +346,198 @@ func Load() *Config {
 			Version:     getEnv("VERSION", "1.0.0"),
 		},
 		Redis: RedisConfig{
+			Enabled:  getEnvAsBool("REDIS_ENABLED", true),
 			Host:     getEnv("REDIS_HOST", "localhost"),
 			Port:     getEnvAsInt("REDIS_PORT", 6379),
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
 		Queue: QueueConfig{
-			MaxWorkers:     getEnvAsInt("QUEUE_MAX_WORKERS", 3),
-			MaxRetries:     getEnvAsInt("QUEUE_MAX_RETRIES", 3),
-			StreamName:     getEnv("QUEUE_STREAM_NAME", "mocks3:tasks"),
-			ConsumerGroup:  getEnv("QUEUE_CONSUMER_GROUP", "queue-workers"),
-			BatchSize:      getEnvAsInt("QUEUE_BATCH_SIZE", 10),
-			ProcessTimeout: getEnvAsInt("QUEUE_PROCESS_TIMEOUT", 30),
+			Name:            getEnv("QUEUE_NAME", "default"),
+			Backend:         getEnv("QUEUE_BACKEND", BackendRedis),
+			MinWorkers:      getEnvAsInt("QUEUE_MIN_WORKERS", 1),
+			MaxWorkers:      getEnvAsInt("QUEUE_MAX_WORKERS", 3),
+			StreamName:      getEnv("QUEUE_STREAM_NAME", "mocks3:tasks"),
+			ConsumerGroup:   getEnv("QUEUE_CONSUMER_GROUP", "queue-workers"),
+			ConsumerName:    getEnv("QUEUE_CONSUMER_NAME", defaultConsumerName()),
+			BatchSize:       getEnvAsInt("QUEUE_BATCH_SIZE", 10),
+			MaxEnqueueBatch: getEnvAsInt("QUEUE_MAX_ENQUEUE_BATCH", 1000),
+			ProcessTimeout:  getEnvAsInt("QUEUE_PROCESS_TIMEOUT", 30),
+			TopicPrefix:     getEnv("QUEUE_TOPIC_PREFIX", "mocks3:topics:"),
+			TopicMaxLen:     int64(getEnvAsInt("QUEUE_TOPIC_MAX_LEN", 100000)),
+
+			DeadLetterQueue:     getEnv("QUEUE_DEAD_LETTER_QUEUE", ""),
+			SchedulerIntervalMs: getEnvAsInt("QUEUE_SCHEDULER_INTERVAL_MS", 1000),
+			JobTTLSeconds:       getEnvAsInt("QUEUE_JOB_TTL_SECONDS", 86400),
+			ClaimIdleMs:         getEnvAsInt("QUEUE_CLAIM_IDLE_MS", 120000),
+			MaxDeliveries:       getEnvAsInt("QUEUE_MAX_DELIVERIES", 5),
+			DedupWindowSecs:     getEnvAsInt("QUEUE_DEDUP_WINDOW_SECS", 0),
+			HeartbeatIntervalMs: getEnvAsInt("QUEUE_HEARTBEAT_INTERVAL_MS", 5000),
+			HeartbeatTimeoutMs:  getEnvAsInt("QUEUE_HEARTBEAT_TIMEOUT_MS", 30000),
+
+			VisibilityTimeoutSecs:    getEnvAsInt("QUEUE_VISIBILITY_TIMEOUT_SECS", 30),
+			MaxVisibilityTimeoutSecs: getEnvAsInt("QUEUE_MAX_VISIBILITY_TIMEOUT_SECS", 43200),
+
+			Retry: RetryPolicy{
+				MaxAttempts:  getEnvAsInt("QUEUE_MAX_RETRIES", 3),
+				BackoffMs:    getEnvAsInt("QUEUE_RETRY_BACKOFF_MS", 1000),
+				MaxBackoffMs: getEnvAsInt("QUEUE_RETRY_MAX_BACKOFF_MS", 60000),
+				Multiplier:   getEnvAsFloat("QUEUE_RETRY_MULTIPLIER", 2),
+				Jitter:       getEnvAsFloat("QUEUE_RETRY_JITTER", 0.2),
+			},
+
+			Autoscale: AutoscaleConfig{
+				IntervalMs:             getEnvAsInt("QUEUE_AUTOSCALE_INTERVAL_MS", 5000),
+				TargetBacklogPerWorker: getEnvAsInt("QUEUE_TARGET_BACKLOG_PER_WORKER", 10),
+				TargetLatencyMs:        getEnvAsInt("QUEUE_TARGET_LATENCY_MS", 5000),
+				ScaleDownCooldownSecs:  getEnvAsInt("QUEUE_SCALE_DOWN_COOLDOWN_SECS", 60),
+			},
+
+			Webhook: WebhookConfig{
+				MaxAttempts:  getEnvAsInt("QUEUE_WEBHOOK_MAX_ATTEMPTS", 5),
+				BackoffMs:    getEnvAsInt("QUEUE_WEBHOOK_BACKOFF_MS", 1000),
+				MaxBackoffMs: getEnvAsInt("QUEUE_WEBHOOK_MAX_BACKOFF_MS", 30000),
+				TimeoutMs:    getEnvAsInt("QUEUE_WEBHOOK_TIMEOUT_MS", 5000),
+			},
+		},
+		Kafka: KafkaConfig{
+			Brokers:             getEnvAsList("KAFKA_BROKERS", []string{"localhost:9092"}),
+			Topic:               getEnv("KAFKA_TOPIC", "mocks3.tasks"),
+			Partitions:          getEnvAsInt("KAFKA_PARTITIONS", 3),
+			ReplicationFactor:   getEnvAsInt("KAFKA_REPLICATION_FACTOR", 1),
+			ClientID:            getEnv("KAFKA_CLIENT_ID", "queue-service"),
+			SessionTimeoutMs:    getEnvAsInt("KAFKA_SESSION_TIMEOUT_MS", 30000),
+			HeartbeatIntervalMs: getEnvAsInt("KAFKA_HEARTBEAT_INTERVAL_MS", 3000),
+			Compression:         getEnv("KAFKA_COMPRESSION", "none"),
+			TLS:                 getEnvAsBool("KAFKA_TLS", false),
+			TLSCAFile:           getEnv("KAFKA_TLS_CA_FILE", ""),
+			SASLMechanism:       strings.ToUpper(getEnv("KAFKA_SASL_MECHANISM", "")),
+			SASLUsername:        getEnv("KAFKA_SASL_USERNAME", ""),
+			SASLPassword:        getEnv("KAFKA_SASL_PASSWORD", ""),
+		},
+		Nats: NatsConfig{
+			Servers:       getEnvAsList("NATS_SERVERS", []string{"nats://localhost:4222"}),
+			Stream:        getEnv("NATS_STREAM", "MOCKS3_TASKS"),
+			SubjectPrefix: getEnv("NATS_SUBJECT_PREFIX", "mocks3.tasks"),
+			Replicas:      getEnvAsInt("NATS_REPLICAS", 1),
+			ClientName:    getEnv("NATS_CLIENT_NAME", "queue-service"),
+		},
+		RBAC: RBACConfig{
+			Enabled: getEnvAsBool("RBAC_ENABLED", false),
+			Header:  getEnv("RBAC_HEADER", "X-Api-Key"),
+			Keys:    getEnvAsAPIKeys("RBAC_API_KEYS"),
+		},
+		FlightRecorder: FlightRecorderConfig{
+			Enabled:            getEnvAsBool("FLIGHT_RECORDER_ENABLED", false),
+			StorageURL:         getEnv("FLIGHT_RECORDER_STORAGE_URL", "http://localhost:8082"),
+			Bucket:             getEnv("FLIGHT_RECORDER_BUCKET", models.DefaultIncidentBucket),
+			RequestCapacity:    getEnvAsInt("FLIGHT_RECORDER_REQUEST_CAPACITY", 200),
+			LogCapacity:        getEnvAsInt("FLIGHT_RECORDER_LOG_CAPACITY", 500),
+			SpanCapacity:       getEnvAsInt("FLIGHT_RECORDER_SPAN_CAPACITY", 500),
+			SLOWindowSecs:      getEnvAsInt("FLIGHT_RECORDER_SLO_WINDOW_SECS", 60),
+			MinRequests:        getEnvAsInt("FLIGHT_RECORDER_MIN_REQUESTS", 20),
+			MaxErrorRate:       getEnvAsFloat("FLIGHT_RECORDER_MAX_ERROR_RATE", 0.1),
+			LatencyThresholdMs: getEnvAsInt("FLIGHT_RECORDER_LATENCY_THRESHOLD_MS", 1000),
+			MaxSlowRate:        getEnvAsFloat("FLIGHT_RECORDER_MAX_SLOW_RATE", 0.2),
+			CooldownSecs:       getEnvAsInt("FLIGHT_RECORDER_COOLDOWN_SECS", 300),
+		},
+		FaultInjection: FaultInjectionConfig{
+			Enabled:        getEnvAsBool("FAULT_INJECTION_ENABLED", false),
+			MockErrorURL:   getEnv("FAULT_INJECTION_MOCK_ERROR_URL", "http://localhost:8085"),
+			Namespace:      getEnv("FAULT_INJECTION_NAMESPACE", ""),
+			CheckTimeoutMs: getEnvAsInt("FAULT_INJECTION_CHECK_TIMEOUT_MS", 200),
+		},
+		Tenancy: TenancyConfig{
+			Enabled:       getEnvAsBool("TENANCY_ENABLED", false),
+			Header:        getEnv("TENANT_HEADER", models.HeaderTenantID),
+			DefaultTenant: getEnv("DEFAULT_TENANT", models.DefaultTenant),
+			Required:      getEnvAsBool("TENANT_REQUIRED", false),
 		},
 		LogLevel: getEnv("LOG_LEVEL", "info"),
 	}
 
+	policies, err := getEnvAsRetryPolicies("QUEUE_RETRY_POLICIES", config.Queue.Retry)
+	if err != nil {
+		fmt.Printf("Warning: %v, using default retry policy for all task types\n", err)
+	}
+	config.Queue.RetryPolicies = policies
+
+	limits, err := getEnvAsRateLimits("QUEUE_RATE_LIMITS")
+	if err != nil {
+		fmt.Printf("Warning: %v, task types are not rate limited\n", err)
+	}
+	config.Queue.RateLimits = limits
+
+	queues, err := getEnvAsQueues("QUEUE_QUEUES", config.Queue)
+	if err != nil {
+		fmt.Printf("Warning: %v, only the default queue is available\n", err)
+	}
+	config.Queues = queues
+
 	return config
 }
 
+// Validate 验证配置
+func (c *Config) Validate() error {
+	if err := c.validateQueues(); err != nil {
+		return err
+	}
+	if c.Queue.Backend == BackendKafka {
+		// 延迟任务、死信队列和事件主题保存在Redis中
+		if !c.Redis.Enabled {
+			return fmt.Errorf("kafka backend requires redis")
+		}
+		if err := c.Kafka.Validate(); err != nil {
+			return fmt.Errorf("invalid kafka config: %w", err)
+		}
+	}
+	if c.Queue.Backend == BackendNats {
+		// 延迟任务、死信队列和事件主题保存在Redis中
+		if !c.Redis.Enabled {
+			return fmt.Errorf("nats backend requires redis")
+		}
+		if err := c.Nats.Validate(); err != nil {
+			return fmt.Errorf("invalid nats config: %w", err)
+		}
+		// 队列名称是subject的最后一个token，消费者组名和队列名称组成持久消费者名称
+		for _, queue := range c.AllQueues() {
+			if !natsNamePattern.MatchString(queue.Name) || !natsNamePattern.MatchString(queue.ConsumerGroup) {
+				return fmt.Errorf("queue %s: name and consumer group must match %s for the nats backend", queue.Name, natsNamePattern)
+			}
+		}
+	}
+	if c.RBAC.Enabled {
+		if c.RBAC.Header == "" {
+			return fmt.Errorf("rbac header is required")
+		}
+		for _, key := range c.RBAC.Keys {
+			if key.Name == "" || key.Key == "" {
+				return fmt.Errorf("rbac api key name and key are required")
+			}
+			if !models.IsValidRole(key.Role) {
+				return fmt.Errorf("rbac api key %s: unknown role %q", key.Name, key.Role)
+			}
+		}
+	}
+	if c.FaultInjection.Enabled {
+		if c.FaultInjection.MockErrorURL == "" {
+			return fmt.Errorf("fault injection mock error URL is required")
+		}
+		if c.FaultInjection.CheckTimeoutMs <= 0 {
+			return fmt.Errorf("invalid fault injection check timeout: %dms", c.FaultInjection.CheckTimeoutMs)
+		}
+	}
+	return nil
+}
+
+// GetBackend 实际使用的队列后端，Redis未启用时为进程内队列
+func (c *Config) GetBackend() string {
+	if !c.Redis.Enabled {
+		return BackendMemory
+	}
+	return c.Queue.Backend
+}
+
 // getEnv 获取环境变量，如果不存在则返回默认值
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -87,6 +546,20 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvAsList 获取逗号分隔的环境变量
+func getEnvAsList(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
+
 // getEnvAsInt 获取环境变量并转换为int
 func getEnvAsInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
@@ -96,3 +569,52 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsBool 获取环境变量并转换为bool
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsFloat 获取环境变量并转换为float64
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// defaultConsumerName 默认消费者名前缀为主机名，多个实例共享消费者组时互不冲突
+func defaultConsumerName() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "queue-service"
+	}
+	return hostname
+}
+
+// getEnvAsAPIKeys 获取环境变量并解析为API key列表，格式为 name:key:role，多个以逗号分隔
+func getEnvAsAPIKeys(key string) []APIKeyConfig {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var keys []APIKeyConfig
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 {
+			// 保留格式错误的条目，由Validate报告
+			keys = append(keys, APIKeyConfig{Name: entry})
+			continue
+		}
+		keys = append(keys, APIKeyConfig{Name: parts[0], Key: parts[1], Role: parts[2]})
+	}
+	return keys
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"sort"
+)
+
+// AllQueues 默认队列和QUEUE_QUEUES中的命名队列，默认队列在前
+func (c *Config) AllQueues() []*QueueConfig {
+	queues := []*QueueConfig{&c.Queue}
+	for i := range c.Queues {
+		queues = append(queues, &c.Queues[i])
+	}
+	return queues
+}
+
+// KafkaFor 队列使用的Kafka配置，命名队列的topic为KAFKA_TOPIC加.队列名称
+func (c *Config) KafkaFor(queue *QueueConfig) *KafkaConfig {
+	if queue == &c.Queue {
+		return &c.Kafka
+	}
+	kafkaConfig := c.Kafka
+	kafkaConfig.Topic = c.Kafka.Topic + "." + queue.Name
+	return &kafkaConfig
+}
+
+// NatsFor 队列使用的NATS配置，subject为NATS_SUBJECT_PREFIX加.队列名称
+func (c *Config) NatsFor(queue *QueueConfig) *NatsConfig {
+	natsConfig := c.Nats
+	natsConfig.Subject = c.Nats.SubjectPrefix + "." + queue.Name
+	return &natsConfig
+}
+
+// validateQueues 验证所有队列的配置，队列名称、任务流和任务类型路由不能重复
+func (c *Config) validateQueues() error {
+	names := make(map[string]bool)
+	streams := make(map[string]string)
+	taskTypes := make(map[string]string)
+	for _, queue := range c.AllQueues() {
+		if err := queue.Validate(); err != nil {
+			return fmt.Errorf("queue %s: %w", queue.Name, err)
+		}
+		if names[queue.Name] {
+			return fmt.Errorf("duplicate queue name: %s", queue.Name)
+		}
+		names[queue.Name] = true
+		if other, ok := streams[queue.StreamName]; ok {
+			return fmt.Errorf("queues %s and %s use the same stream: %s", other, queue.Name, queue.StreamName)
+		}
+		streams[queue.StreamName] = queue.Name
+		for _, taskType := range queue.TaskTypes {
+			if other, ok := taskTypes[taskType]; ok {
+				return fmt.Errorf("task type %s is routed to both queues %s and %s", taskType, other, queue.Name)
+			}
+			taskTypes[taskType] = queue.Name
+		}
+	}
+	return nil
+}
+
+// getEnvAsQueues 解析命名队列的配置（JSON对象：队列名称 -> 配置），按名称排序
+//
+// 每个队列以默认队列的配置为基础，只需写出不同的字段，例如
+// {"deletes": {"max_workers": 5, "task_types": ["file_deletion"], "retry": {"max_attempts": 5}}}。
+// 未指定stream_name时为默认队列的stream_name加:队列名称，死信队列默认跟随任务流。
+func getEnvAsQueues(key string, base QueueConfig) ([]QueueConfig, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	queues := make([]QueueConfig, 0, len(raw))
+	for _, name := range names {
+		queue := base
+		queue.Name = name
+		queue.StreamName = base.StreamName + ":" + name
+		queue.DeadLetterQueue = ""
+		queue.TaskTypes = nil
+		// 解码会合并到已有的map中，复制后再解码，避免修改默认队列的配置
+		queue.RetryPolicies = maps.Clone(base.RetryPolicies)
+		queue.RateLimits = maps.Clone(base.RateLimits)
+		if err := json.Unmarshal(raw[name], &queue); err != nil {
+			return nil, fmt.Errorf("invalid %s for queue %s: %w", key, name, err)
+		}
+		queue.Name = name
+		for taskType, limit := range queue.RateLimits {
+			limit.Type = taskType
+			queue.RateLimits[taskType] = limit
+		}
+		queues = append(queues, queue)
+	}
+	return queues, nil
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"os"
+	"strings"
+	"time"
+)
+
+// RetryPolicy 任务失败后的重试策略：指数退避加随机抖动
+type RetryPolicy struct {
+	MaxAttempts  int     `json:"max_attempts"`   // 最多执行次数（含首次），用尽后移入死信队列
+	BackoffMs    int     `json:"backoff_ms"`     // 第一次重试前的等待时间
+	MaxBackoffMs int     `json:"max_backoff_ms"` // 等待时间上限
+	Multiplier   float64 `json:"multiplier"`     // 每次重试等待时间的倍数
+	Jitter       float64 `json:"jitter"`         // 抖动比例（0-1），等待时间在±jitter范围内随机浮动
+}
+
+// GetBackoff 获取第一次重试前的等待时间
+func (p *RetryPolicy) GetBackoff() time.Duration {
+	return time.Duration(p.BackoffMs) * time.Millisecond
+}
+
+// GetMaxBackoff 获取等待时间上限
+func (p *RetryPolicy) GetMaxBackoff() time.Duration {
+	return time.Duration(p.MaxBackoffMs) * time.Millisecond
+}
+
+// Validate 验证重试策略
+func (p *RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("max_attempts must be at least 1, got %d", p.MaxAttempts)
+	}
+	if p.BackoffMs < 0 || p.MaxBackoffMs < 0 {
+		return fmt.Errorf("backoff must not be negative")
+	}
+	if p.MaxBackoffMs < p.BackoffMs {
+		return fmt.Errorf("max_backoff_ms %d is less than backoff_ms %d", p.MaxBackoffMs, p.BackoffMs)
+	}
+	if p.Multiplier < 1 {
+		return fmt.Errorf("multiplier must be at least 1, got %v", p.Multiplier)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("jitter must be between 0 and 1, got %v", p.Jitter)
+	}
+	return nil
+}
+
+// Validate 验证队列配置
+func (q *QueueConfig) Validate() error {
+	if q.Backend != BackendRedis && q.Backend != BackendKafka && q.Backend != BackendNats {
+		return fmt.Errorf("unsupported queue backend: %s", q.Backend)
+	}
+	if q.Name == "" || strings.Contains(q.Name, "/") {
+		return fmt.Errorf("invalid queue name: %q", q.Name)
+	}
+	if q.MinWorkers < 0 || q.MaxWorkers < q.MinWorkers {
+		return fmt.Errorf("invalid worker bounds: min %d, max %d", q.MinWorkers, q.MaxWorkers)
+	}
+	if q.Autoscale.IntervalMs <= 0 || q.Autoscale.TargetBacklogPerWorker <= 0 || q.Autoscale.TargetLatencyMs < 0 || q.Autoscale.ScaleDownCooldownSecs < 0 {
+		return fmt.Errorf("invalid autoscale config: %+v", q.Autoscale)
+	}
+	if q.JobTTLSeconds <= 0 {
+		return fmt.Errorf("invalid job ttl: %d", q.JobTTLSeconds)
+	}
+	if q.ConsumerGroup == "" || q.ConsumerName == "" {
+		return fmt.Errorf("consumer group and consumer name must not be empty")
+	}
+	// 接管时间短于处理超时会把仍在处理的任务交给其他worker重复执行
+	if q.ClaimIdleMs < 0 || (q.ClaimIdleMs > 0 && q.GetClaimIdle() < time.Duration(q.ProcessTimeout)*time.Second) {
+		return fmt.Errorf("invalid claim idle: %dms, must be 0 or at least the process timeout (%ds)", q.ClaimIdleMs, q.ProcessTimeout)
+	}
+	if w := q.Webhook; w.MaxAttempts < 1 || w.BackoffMs < 0 || w.MaxBackoffMs < w.BackoffMs || w.TimeoutMs <= 0 {
+		return fmt.Errorf("invalid webhook config: %+v", q.Webhook)
+	}
+	if q.VisibilityTimeoutSecs <= 0 || q.MaxVisibilityTimeoutSecs < q.VisibilityTimeoutSecs {
+		return fmt.Errorf("invalid visibility timeout: %ds, max %ds", q.VisibilityTimeoutSecs, q.MaxVisibilityTimeoutSecs)
+	}
+	if q.MaxEnqueueBatch <= 0 {
+		return fmt.Errorf("invalid max enqueue batch: %d", q.MaxEnqueueBatch)
+	}
+	if q.MaxDeliveries < 0 {
+		return fmt.Errorf("invalid max deliveries: %d", q.MaxDeliveries)
+	}
+	if q.DedupWindowSecs < 0 {
+		return fmt.Errorf("invalid dedup window: %d", q.DedupWindowSecs)
+	}
+	// 超时至少为两个心跳间隔，避免一次心跳延迟就把仍在处理的任务重新入队
+	if q.HeartbeatIntervalMs <= 0 || q.HeartbeatTimeoutMs < 0 || (q.HeartbeatTimeoutMs > 0 && q.HeartbeatTimeoutMs < 2*q.HeartbeatIntervalMs) {
+		return fmt.Errorf("invalid heartbeat timeout: %dms, must be 0 or at least two heartbeat intervals (%dms)", q.HeartbeatTimeoutMs, q.HeartbeatIntervalMs)
+	}
+	if err := q.Retry.Validate(); err != nil {
+		return fmt.Errorf("invalid retry policy: %w", err)
+	}
+	for taskType, policy := range q.RetryPolicies {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("invalid retry policy for task type %s: %w", taskType, err)
+		}
+	}
+	for taskType, limit := range q.RateLimits {
+		if err := limit.Validate(); err != nil {
+			return fmt.Errorf("invalid rate limit for task type %s: %w", taskType, err)
+		}
+	}
+	return nil
+}
+
+// getEnvAsRetryPolicies 解析按任务类型配置的重试策略（JSON对象：任务类型 -> 策略）
+//
+// 每个类型只需写出与默认策略不同的字段，例如 {"file_deletion": {"max_attempts": 5}}。
+func getEnvAsRetryPolicies(key string, defaultPolicy RetryPolicy) (map[string]RetryPolicy, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	policies := make(map[string]RetryPolicy, len(raw))
+	for taskType, data := range raw {
+		policy := defaultPolicy
+		if err := json.Unmarshal(data, &policy); err != nil {
+			return nil, fmt.Errorf("invalid %s for task type %s: %w", key, taskType, err)
+		}
+		policies[taskType] = policy
+	}
+	return policies, nil
+}
+
+// getEnvAsRateLimits 解析按任务类型配置的速率限制（JSON对象：任务类型 -> 限制），
+// 例如 {"file_deletion": {"rate_per_second": 5, "burst": 10}}
+func getEnvAsRateLimits(key string) (map[string]models.TaskRateLimit, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	var limits map[string]models.TaskRateLimit
+	if err := json.Unmarshal([]byte(value), &limits); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	for taskType, limit := range limits {
+		limit.Type = taskType
+		limits[taskType] = limit
+	}
+	return limits, nil
+}
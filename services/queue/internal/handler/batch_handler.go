@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"mocks3/shared/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddTasksRequest 批量添加任务请求，每个任务的字段与单个添加相同
+type AddTasksRequest struct {
+	Tasks []AddTaskRequest `json:"tasks" binding:"required"`
+}
+
+// AddTasks 批量添加任务，逐个返回结果
+//
+// 全部成功返回201，部分失败返回207，单个任务的错误不影响其他任务；任务数超过上限时整批返回400。
+// 去重窗口内重复的任务视为成功，结果的job_id和duplicate_of为已入队的任务。
+func (h *QueueHandler) AddTasks(c *gin.Context) {
+	var req AddTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Invalid request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err := h.queues.Default().ValidateBatchSize(len(req.Tasks)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	results := make([]models.BatchEnqueueResult, len(req.Tasks))
+	var tasks []*models.Task
+	var taskIndex []int
+	for i := range req.Tasks {
+		results[i].Index = i
+		if req.Tasks[i].Type == "" {
+			results[i].Error = "invalid task: type is required"
+			continue
+		}
+		task, err := req.Tasks[i].task(now)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		tasks = append(tasks, task)
+		taskIndex = append(taskIndex, i)
+	}
+
+	if len(tasks) > 0 {
+		errs, err := h.queues.AddTasks(c.Request.Context(), tasks)
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidArgument) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": err.Error(),
+				})
+				return
+			}
+			h.logger.ErrorContext(c.Request.Context(), "Failed to add tasks", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to add tasks",
+			})
+			return
+		}
+
+		for j, task := range tasks {
+			result := &results[taskIndex[j]]
+			var duplicateErr *models.DuplicateTaskError
+			if errors.As(errs[j], &duplicateErr) {
+				result.JobID = duplicateErr.DuplicateOf
+				result.DuplicateOf = duplicateErr.DuplicateOf
+				continue
+			}
+			if errs[j] != nil {
+				result.Error = errs[j].Error()
+				continue
+			}
+			scheduledAt := task.ScheduledAt
+			result.JobID = task.ID
+			result.Status = task.Status
+			result.ScheduledAt = &scheduledAt
+		}
+	}
+
+	resp := models.BatchEnqueueResponse{Results: results}
+	for _, result := range results {
+		if result.Error != "" {
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+	}
+
+	status := http.StatusCreated
+	if resp.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, resp)
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mocks3/services/queue/internal/service"
+	"mocks3/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListDeadLetters 分页列出死信任务（?type=&before=&limit=&offset=）
+func (h *QueueHandler) ListDeadLetters(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := h.deadLetterFilter(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	page, err := qs.ListDeadLetters(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		h.writeDeadLetterError(c, "Failed to list dead letters", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetDeadLetter 获取死信任务详情（含payload和失败原因）
+func (h *QueueHandler) GetDeadLetter(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	task, err := qs.GetDeadLetter(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeDeadLetterError(c, "Failed to get dead letter", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// RequeueDeadLetter 将死信任务移回任务流
+func (h *QueueHandler) RequeueDeadLetter(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	task, err := qs.RequeueDeadLetter(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeDeadLetterError(c, "Failed to requeue dead letter", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"task_id":   task.ID,
+		"stream_id": task.StreamID,
+		"status":    task.Status,
+	})
+}
+
+// RequeueDeadLetters 将符合条件的死信任务全部移回任务流（?type=&before=）
+func (h *QueueHandler) RequeueDeadLetters(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := h.deadLetterFilter(c)
+	if !ok {
+		return
+	}
+
+	requeued, err := qs.RequeueDeadLetters(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to requeue dead letters", "requeued", requeued, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":    "Failed to requeue dead letters",
+			"requeued": requeued,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"requeued": requeued,
+	})
+}
+
+// DeleteDeadLetter 删除死信任务
+func (h *QueueHandler) DeleteDeadLetter(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	if err := qs.DeleteDeadLetter(c.Request.Context(), c.Param("id")); err != nil {
+		h.writeDeadLetterError(c, "Failed to delete dead letter", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"task_id": c.Param("id"),
+		"status":  "deleted",
+	})
+}
+
+// PurgeDeadLetters 删除符合条件的死信任务（?type=&before=，不指定时清空）
+func (h *QueueHandler) PurgeDeadLetters(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := h.deadLetterFilter(c)
+	if !ok {
+		return
+	}
+
+	purged, err := qs.PurgeDeadLetters(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to purge dead letters", "purged", purged, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "Failed to purge dead letters",
+			"purged": purged,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"purged": purged,
+	})
+}
+
+// deadLetterFilter 解析死信过滤参数，参数无效时已写入400响应并返回false
+func (h *QueueHandler) deadLetterFilter(c *gin.Context) (*models.DeadLetterFilter, bool) {
+	filter := &models.DeadLetterFilter{Type: c.Query("type")}
+	if before := c.Query("before"); before != "" {
+		t, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid before parameter, expected RFC3339 time",
+			})
+			return nil, false
+		}
+		filter.Before = &t
+	}
+	return filter, true
+}
+
+// writeDeadLetterError 按错误类型返回死信接口的错误响应
+func (h *QueueHandler) writeDeadLetterError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrDeadLetterNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, models.ErrInvalidArgument):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		h.logger.ErrorContext(c.Request.Context(), message, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
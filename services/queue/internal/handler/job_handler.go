@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"mocks3/services/queue/internal/service"
+	"mocks3/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportProgressRequest 上报任务进度请求
+type ReportProgressRequest struct {
+	Progress *int `json:"progress" binding:"required"` // 0-100
+}
+
+// GetJob 获取任务执行状态（queued、running、retrying、done、failed）、执行次数和进度
+func (h *QueueHandler) GetJob(c *gin.Context) {
+	job, err := h.queues.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeJobError(c, "Failed to get job", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ReportJobProgress 外部worker上报正在执行的任务的进度
+func (h *QueueHandler) ReportJobProgress(c *gin.Context) {
+	var req ReportProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	job, err := h.queues.ReportProgress(c.Request.Context(), c.Param("id"), *req.Progress)
+	if err != nil {
+		h.writeJobError(c, "Failed to report job progress", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// writeJobError 按错误类型返回任务状态接口的错误响应
+func (h *QueueHandler) writeJobError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrJobNotRunning):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, models.ErrInvalidArgument):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		h.logger.ErrorContext(c.Request.Context(), message, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"mocks3/services/queue/internal/service"
+	"mocks3/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReceiveTasksRequest 领取任务请求，请求体可省略
+type ReceiveTasksRequest struct {
+	MaxTasks          int `json:"max_tasks"`          // 最多领取的任务数，默认1
+	VisibilityTimeout int `json:"visibility_timeout"` // 不可见时间（秒），为0时使用QUEUE_VISIBILITY_TIMEOUT_SECS
+	WaitTime          int `json:"wait_time"`          // 没有任务时最多等待的时间（秒）
+}
+
+// NackLeaseRequest 放回任务请求，请求体可省略
+type NackLeaseRequest struct {
+	Reason string `json:"reason"`
+	Delay  string `json:"delay,omitempty"` // 重新可见前的等待时间（Go duration），为空时按重试策略退避
+}
+
+// AckLeaseRequest 确认任务请求，请求体可省略
+type AckLeaseRequest struct {
+	Output map[string]interface{} `json:"output,omitempty"` // 任务输出，合并到后续任务的数据中
+}
+
+// ExtendLeaseRequest 延长租约请求
+type ExtendLeaseRequest struct {
+	VisibilityTimeout int `json:"visibility_timeout" binding:"required"` // 从现在起的不可见时间（秒）
+}
+
+// ReceiveTasks 外部消费者领取任务，返回的租约凭证用于确认或放回任务
+func (h *QueueHandler) ReceiveTasks(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	req := ReceiveTasksRequest{MaxTasks: 1}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	leases, err := qs.ReceiveTasks(c.Request.Context(), req.MaxTasks,
+		time.Duration(req.VisibilityTimeout)*time.Second, time.Duration(req.WaitTime)*time.Second)
+	if err != nil {
+		h.writeLeaseError(c, "Failed to receive tasks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"leases": leases,
+		"count":  len(leases),
+	})
+}
+
+// AckLease 确认租约中的任务已处理完成
+func (h *QueueHandler) AckLease(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	var req AckLeaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	task, err := qs.AckLease(c.Request.Context(), c.Param("receipt"), req.Output)
+	if err != nil {
+		h.writeLeaseError(c, "Failed to ack task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"receipt_handle": c.Param("receipt"),
+		"task_id":        task.ID,
+		"status":         task.Status,
+		"next":           task.NextIDs(),
+	})
+}
+
+// NackLease 放回租约中的任务，在delay后重新对消费者可见
+func (h *QueueHandler) NackLease(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	var req NackLeaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var delay *time.Duration
+	if req.Delay != "" {
+		d, err := time.ParseDuration(req.Delay)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("invalid delay: %v", err),
+			})
+			return
+		}
+		delay = &d
+	}
+
+	task, err := qs.NackLease(c.Request.Context(), c.Param("receipt"), req.Reason, delay)
+	if err != nil {
+		h.writeLeaseError(c, "Failed to nack task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"receipt_handle": c.Param("receipt"),
+		"task_id":        task.ID,
+		"status":         task.Status,
+		"retry_count":    task.RetryCount,
+		"scheduled_at":   task.ScheduledAt,
+	})
+}
+
+// ExtendLease 延长租约的不可见时间
+func (h *QueueHandler) ExtendLease(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	var req ExtendLeaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	lease, err := qs.ExtendLease(c.Request.Context(), c.Param("receipt"), time.Duration(req.VisibilityTimeout)*time.Second)
+	if err != nil {
+		h.writeLeaseError(c, "Failed to extend lease", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lease)
+}
+
+// writeLeaseError 按错误类型返回租约接口的错误响应
+func (h *QueueHandler) writeLeaseError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrLeaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, models.ErrInvalidArgument):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrReceiveUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		h.logger.ErrorContext(c.Request.Context(), message, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"mocks3/services/queue/internal/service"
 	"mocks3/shared/models"
@@ -12,42 +16,199 @@ import (
 )
 
 // QueueHandler 队列处理器
+//
+// 任务按请求中的queue字段或任务类型路由到队列；按任务ID查询时在所有队列中查找；
+// 其他接口通过查询参数queue指定队列，未指定时为默认队列。
 type QueueHandler struct {
-	service *service.QueueService
-	logger  *observability.Logger
+	queues *service.QueueRegistry
+	logger *observability.Logger
 }
 
 // NewQueueHandler 创建队列处理器
-func NewQueueHandler(service *service.QueueService, logger *observability.Logger) *QueueHandler {
+func NewQueueHandler(queues *service.QueueRegistry, logger *observability.Logger) *QueueHandler {
 	return &QueueHandler{
-		service: service,
-		logger:  logger,
+		queues: queues,
+		logger: logger,
 	}
 }
 
+// queue 按查询参数queue选择队列，未指定时为默认队列，队列不存在时返回404
+func (h *QueueHandler) queue(c *gin.Context) (*service.QueueService, bool) {
+	qs, err := h.queues.Get(c.Query("queue"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	return qs, true
+}
+
 // RegisterRoutes 注册路由
 func (h *QueueHandler) RegisterRoutes(router *gin.Engine) {
 	api := router.Group("/api/v1")
 	{
 		// 任务管理
 		api.POST("/tasks", h.AddTask)
+		api.POST("/tasks/batch", h.AddTasks)
 		api.GET("/tasks/:id", h.GetTask)
 		api.GET("/tasks", h.ListTasks)
 
+		// 外部消费者：领取任务后在不可见时间内确认或放回
+		api.POST("/tasks/receive", h.ReceiveTasks)
+		api.POST("/leases/:receipt/ack", h.AckLease)
+		api.POST("/leases/:receipt/nack", h.NackLease)
+		api.PUT("/leases/:receipt/visibility", h.ExtendLease)
+
+		// 任务执行状态
+		api.GET("/jobs/:id", h.GetJob)
+		api.PUT("/jobs/:id/progress", h.ReportJobProgress)
+
+		// 队列暂停和恢复
+		api.GET("/queues", h.ListQueues)
+		api.POST("/queues/:name/pause", h.PauseQueue)
+		api.POST("/queues/:name/resume", h.ResumeQueue)
+
 		// 工作节点管理
 		api.POST("/workers/:id/start", h.StartWorker)
 		api.POST("/workers/:id/stop", h.StopWorker)
 
+		// 死信队列
+		api.GET("/dlq", h.ListDeadLetters)
+		api.DELETE("/dlq", h.PurgeDeadLetters)
+		api.POST("/dlq/requeue", h.RequeueDeadLetters)
+		api.GET("/dlq/:id", h.GetDeadLetter)
+		api.DELETE("/dlq/:id", h.DeleteDeadLetter)
+		api.POST("/dlq/:id/requeue", h.RequeueDeadLetter)
+
+		// 按任务类型的payload schema
+		api.GET("/schemas", h.ListTaskSchemas)
+		api.GET("/schemas/:type", h.GetTaskSchema)
+		api.PUT("/schemas/:type", h.PutTaskSchema)
+		api.DELETE("/schemas/:type", h.DeleteTaskSchema)
+
+		// 按任务类型的处理速率限制
+		api.GET("/rate-limits", h.ListRateLimits)
+		api.PUT("/rate-limits/:type", h.SetRateLimit)
+		api.DELETE("/rate-limits/:type", h.DeleteRateLimit)
+
 		// 统计信息
 		api.GET("/stats", h.GetStats)
+
+		// 事件主题（只追加，供审计导出等场景使用）
+		api.POST("/topics/:topic/events", h.PublishEvent)
+		api.GET("/topics/:topic/events", h.ReadEvents)
 	}
 }
 
 // AddTaskRequest 添加任务请求
+//
+// EnqueueAt和Delay最多指定一个，任务在该时间之后才对worker可见。
+// Next中的任务在本任务完成后入队，不能指定可见时间和TTL。
 type AddTaskRequest struct {
-	Type     string                 `json:"type" binding:"required"`
-	Priority int                    `json:"priority"`
-	Data     map[string]interface{} `json:"data"`
+	Type      string                 `json:"type" binding:"required"`
+	Queue     string                 `json:"queue,omitempty"` // 省略时按任务类型路由，未配置路由的类型进入默认队列
+	Priority  int                    `json:"priority"`
+	Data      map[string]interface{} `json:"data"`
+	EnqueueAt *time.Time             `json:"enqueue_at,omitempty"` // RFC3339时间
+	Delay     string                 `json:"delay,omitempty"`      // Go duration，如 30s、5m
+
+	CallbackURL string `json:"callback_url,omitempty"` // 任务完成、最终失败或过期时POST通知的http(s)地址
+	TTL         string `json:"ttl,omitempty"`          // Go duration，从入队起超过该时间仍未开始执行的任务被丢弃
+
+	Next []*AddTaskRequest `json:"next,omitempty"` // 本任务完成后入队的后续任务，本任务的输出合并到其数据中
+}
+
+// expiresAt 解析任务过期时间，未指定TTL时返回nil；任务必须在可见之后才过期
+func (r *AddTaskRequest) expiresAt(now, scheduledAt time.Time) (*time.Time, error) {
+	if r.TTL == "" {
+		return nil, nil
+	}
+
+	ttl, err := time.ParseDuration(r.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ttl: %w", err)
+	}
+	if ttl <= 0 || ttl > service.MaxTaskDelay {
+		return nil, fmt.Errorf("invalid ttl: must be between 1ns and %s", service.MaxTaskDelay)
+	}
+	expiresAt := now.Add(ttl)
+	if !expiresAt.After(scheduledAt) {
+		return nil, fmt.Errorf("invalid ttl: task would expire before it is enqueued")
+	}
+	return &expiresAt, nil
+}
+
+// scheduledAt 解析任务可见时间，未指定时返回零值
+func (r *AddTaskRequest) scheduledAt(now time.Time) (time.Time, error) {
+	if r.EnqueueAt != nil && r.Delay != "" {
+		return time.Time{}, fmt.Errorf("invalid schedule: enqueue_at and delay are mutually exclusive")
+	}
+	if r.EnqueueAt != nil {
+		return *r.EnqueueAt, nil
+	}
+	if r.Delay == "" {
+		return time.Time{}, nil
+	}
+
+	delay, err := time.ParseDuration(r.Delay)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid delay: %w", err)
+	}
+	if delay < 0 {
+		return time.Time{}, fmt.Errorf("invalid delay: must not be negative")
+	}
+	return now.Add(delay), nil
+}
+
+// task 由请求创建任务并生成任务ID
+func (r *AddTaskRequest) task(now time.Time) (*models.Task, error) {
+	scheduledAt, err := r.scheduledAt(now)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := r.expiresAt(now, scheduledAt)
+	if err != nil {
+		return nil, err
+	}
+	if r.CallbackURL != "" {
+		if u, err := url.Parse(r.CallbackURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid callback_url: must be an absolute http or https URL")
+		}
+	}
+
+	task := &models.Task{
+		Type:        r.Type,
+		Queue:       r.Queue,
+		Priority:    r.Priority,
+		Data:        r.Data,
+		ScheduledAt: scheduledAt,
+		CallbackURL: r.CallbackURL,
+		ExpiresAt:   expiresAt,
+	}
+	for _, next := range r.Next {
+		if next == nil {
+			return nil, fmt.Errorf("invalid next: task must not be null")
+		}
+		if next.EnqueueAt != nil || next.Delay != "" || next.TTL != "" {
+			return nil, fmt.Errorf("invalid next: enqueue_at, delay and ttl are not supported for next tasks")
+		}
+		nextTask, err := next.task(now)
+		if err != nil {
+			return nil, err
+		}
+		task.Next = append(task.Next, nextTask)
+	}
+	if err := task.ValidateNext(); err != nil {
+		return nil, err
+	}
+
+	// 有后续任务时本任务是工作流的第一个任务
+	task.GenerateIDs()
+	if len(task.Next) > 0 {
+		task.WorkflowID = task.ID
+	}
+	return task, nil
 }
 
 // AddTask 添加任务
@@ -62,18 +223,47 @@ func (h *QueueHandler) AddTask(c *gin.Context) {
 		return
 	}
 
-	// 创建任务
-	task := &models.Task{
-		Type:     req.Type,
-		Priority: req.Priority,
-		Data:     req.Data,
+	task, err := req.task(time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
 	}
 
-	// 生成任务ID
-	task.GenerateID()
-
 	// 添加到队列
-	if err := h.service.AddTask(c.Request.Context(), task); err != nil {
+	if err := h.queues.AddTask(c.Request.Context(), task); err != nil {
+		// 去重窗口内已有相同payload的任务，返回该任务而不重复入队
+		var duplicateErr *models.DuplicateTaskError
+		if errors.As(err, &duplicateErr) {
+			c.JSON(http.StatusOK, gin.H{
+				"job_id":       duplicateErr.DuplicateOf,
+				"task_id":      duplicateErr.DuplicateOf,
+				"duplicate":    true,
+				"duplicate_of": duplicateErr.DuplicateOf,
+			})
+			return
+		}
+		var payloadErr *models.TaskPayloadError
+		if errors.As(err, &payloadErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      err.Error(),
+				"violations": payloadErr.Violations,
+			})
+			return
+		}
+		if errors.Is(err, models.ErrInvalidArgument) || errors.Is(err, models.ErrInvalidPayload) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrQueueNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to add task", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to add task",
@@ -82,9 +272,13 @@ func (h *QueueHandler) AddTask(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"task_id":   task.ID,
-		"stream_id": task.StreamID,
-		"status":    "pending",
+		"job_id":       task.ID,
+		"task_id":      task.ID,
+		"queue":        task.Queue,
+		"stream_id":    task.StreamID,
+		"status":       task.Status,
+		"scheduled_at": task.ScheduledAt,
+		"next":         task.NextIDs(),
 	})
 }
 
@@ -98,7 +292,7 @@ func (h *QueueHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.service.GetTask(c.Request.Context(), taskID)
+	task, err := h.queues.GetTask(c.Request.Context(), taskID)
 	if err != nil {
 		h.logger.WarnContext(c.Request.Context(), "Task not found", "task_id", taskID)
 		c.JSON(http.StatusNotFound, gin.H{
@@ -112,6 +306,11 @@ func (h *QueueHandler) GetTask(c *gin.Context) {
 
 // ListTasks 列出任务
 func (h *QueueHandler) ListTasks(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
 	status := c.Query("status")
 	limitStr := c.DefaultQuery("limit", "100")
 
@@ -120,7 +319,7 @@ func (h *QueueHandler) ListTasks(c *gin.Context) {
 		limit = 100
 	}
 
-	tasks, err := h.service.ListTasks(c.Request.Context(), status, limit)
+	tasks, err := qs.ListTasks(c.Request.Context(), status, limit)
 	if err != nil {
 		h.logger.ErrorContext(c.Request.Context(), "Failed to list tasks", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -137,6 +336,11 @@ func (h *QueueHandler) ListTasks(c *gin.Context) {
 
 // StartWorker 启动工作节点
 func (h *QueueHandler) StartWorker(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
 	workerID := c.Param("id")
 	if workerID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -145,7 +349,7 @@ func (h *QueueHandler) StartWorker(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.StartWorker(c.Request.Context(), workerID); err != nil {
+	if err := qs.StartWorker(c.Request.Context(), workerID); err != nil {
 		h.logger.ErrorContext(c.Request.Context(), "Failed to start worker", "worker_id", workerID, "error", err)
 		c.JSON(http.StatusConflict, gin.H{
 			"error": err.Error(),
@@ -161,6 +365,11 @@ func (h *QueueHandler) StartWorker(c *gin.Context) {
 
 // StopWorker 停止工作节点
 func (h *QueueHandler) StopWorker(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
 	workerID := c.Param("id")
 	if workerID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -169,7 +378,7 @@ func (h *QueueHandler) StopWorker(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.StopWorker(c.Request.Context(), workerID); err != nil {
+	if err := qs.StopWorker(c.Request.Context(), workerID); err != nil {
 		h.logger.ErrorContext(c.Request.Context(), "Failed to stop worker", "worker_id", workerID, "error", err)
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": err.Error(),
@@ -185,7 +394,12 @@ func (h *QueueHandler) StopWorker(c *gin.Context) {
 
 // GetStats 获取统计信息
 func (h *QueueHandler) GetStats(c *gin.Context) {
-	stats, err := h.service.GetStats(c.Request.Context())
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	stats, err := qs.GetStats(c.Request.Context())
 	if err != nil {
 		h.logger.ErrorContext(c.Request.Context(), "Failed to get stats", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -196,3 +410,76 @@ func (h *QueueHandler) GetStats(c *gin.Context) {
 
 	c.JSON(http.StatusOK, stats)
 }
+
+// PublishEvent 发布事件到主题（请求体按原样保存）
+func (h *QueueHandler) PublishEvent(c *gin.Context) {
+	topic := c.Param("topic")
+
+	payload, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to read request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.queues.Default().PublishEvent(c.Request.Context(), topic, payload)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to publish event", "topic", topic, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to publish event",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":           event.ID,
+		"topic":        event.Topic,
+		"published_at": event.PublishedAt,
+	})
+}
+
+// ReadEvents 读取主题事件
+func (h *QueueHandler) ReadEvents(c *gin.Context) {
+	topic := c.Param("topic")
+	after := c.Query("after")
+
+	count, err := strconv.Atoi(c.DefaultQuery("count", "100"))
+	if err != nil {
+		count = 100
+	}
+
+	events, err := h.queues.Default().ReadEvents(c.Request.Context(), topic, after, count)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to read events", "topic", topic, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to read events",
+		})
+		return
+	}
+
+	nextAfter := after
+	if len(events) > 0 {
+		nextAfter = events[len(events)-1].ID
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"topic":      topic,
+		"events":     events,
+		"count":      len(events),
+		"next_after": nextAfter,
+	})
+}
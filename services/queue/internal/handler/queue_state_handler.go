@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"mocks3/services/queue/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PauseQueueRequest 暂停队列请求，请求体可省略
+type PauseQueueRequest struct {
+	Reason string `json:"reason"` // 暂停原因，如正在进行的混沌实验
+}
+
+// ListQueues 列出队列的运行状态
+func (h *QueueHandler) ListQueues(c *gin.Context) {
+	queues, err := h.queues.QueueStates(c.Request.Context())
+	if err != nil {
+		h.writeQueueStateError(c, "Failed to list queues", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queues": queues,
+		"count":  len(queues),
+	})
+}
+
+// PauseQueue 暂停队列，worker不再拉取新任务但保持运行
+func (h *QueueHandler) PauseQueue(c *gin.Context) {
+	var req PauseQueueRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	state, err := h.queues.PauseQueue(c.Request.Context(), c.Param("name"), req.Reason)
+	if err != nil {
+		h.writeQueueStateError(c, "Failed to pause queue", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// ResumeQueue 恢复已暂停的队列
+func (h *QueueHandler) ResumeQueue(c *gin.Context) {
+	state, err := h.queues.ResumeQueue(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.writeQueueStateError(c, "Failed to resume queue", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// writeQueueStateError 按错误类型返回队列状态接口的错误响应
+func (h *QueueHandler) writeQueueStateError(c *gin.Context, message string, err error) {
+	if errors.Is(err, service.ErrQueueNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	h.logger.ErrorContext(c.Request.Context(), message, "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": message,
+	})
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"mocks3/services/queue/internal/service"
+	"mocks3/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetRateLimitRequest 设置速率限制请求
+type SetRateLimitRequest struct {
+	RatePerSecond float64 `json:"rate_per_second" binding:"required"`
+	Burst         int     `json:"burst"` // 令牌桶容量，为0时取ceil(rate_per_second)
+}
+
+// ListRateLimits 列出按任务类型的处理速率限制
+func (h *QueueHandler) ListRateLimits(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	limits := qs.ListRateLimits()
+	c.JSON(http.StatusOK, gin.H{
+		"rate_limits": limits,
+		"count":       len(limits),
+	})
+}
+
+// SetRateLimit 创建或更新任务类型的处理速率限制，立即对本实例的所有worker生效
+func (h *QueueHandler) SetRateLimit(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	var req SetRateLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	limit, err := qs.SetRateLimit(c.Request.Context(), models.TaskRateLimit{
+		Type:          c.Param("type"),
+		RatePerSecond: req.RatePerSecond,
+		Burst:         req.Burst,
+	})
+	if err != nil {
+		h.writeRateLimitError(c, "Failed to set rate limit", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, limit)
+}
+
+// DeleteRateLimit 取消任务类型的处理速率限制
+func (h *QueueHandler) DeleteRateLimit(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	if err := qs.DeleteRateLimit(c.Request.Context(), c.Param("type")); err != nil {
+		h.writeRateLimitError(c, "Failed to delete rate limit", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"type":   c.Param("type"),
+		"status": "deleted",
+	})
+}
+
+// writeRateLimitError 按错误类型返回速率限制接口的错误响应
+func (h *QueueHandler) writeRateLimitError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrRateLimitNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, models.ErrInvalidArgument):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		h.logger.ErrorContext(c.Request.Context(), message, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"mocks3/services/queue/internal/service"
+	"mocks3/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTaskSchemas 列出所有任务类型的payload schema
+func (h *QueueHandler) ListTaskSchemas(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	schemas, err := qs.ListTaskSchemas(c.Request.Context())
+	if err != nil {
+		h.writeSchemaError(c, "Failed to list task schemas", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schemas": schemas,
+		"count":   len(schemas),
+	})
+}
+
+// GetTaskSchema 获取任务类型注册的payload schema
+func (h *QueueHandler) GetTaskSchema(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	schema, err := qs.GetTaskSchema(c.Request.Context(), c.Param("type"))
+	if err != nil {
+		h.writeSchemaError(c, "Failed to get task schema", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// PutTaskSchema 注册任务类型的payload schema，请求体即JSON Schema文档
+func (h *QueueHandler) PutTaskSchema(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, models.MaxTaskSchemaSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !json.Valid(body) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid schema: request body must be a JSON document",
+		})
+		return
+	}
+
+	schema := &models.TaskSchema{Type: c.Param("type"), Schema: body}
+	if err := qs.SetTaskSchema(c.Request.Context(), schema); err != nil {
+		h.writeSchemaError(c, "Failed to set task schema", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// DeleteTaskSchema 删除任务类型的payload schema
+func (h *QueueHandler) DeleteTaskSchema(c *gin.Context) {
+	qs, ok := h.queue(c)
+	if !ok {
+		return
+	}
+
+	if err := qs.DeleteTaskSchema(c.Request.Context(), c.Param("type")); err != nil {
+		h.writeSchemaError(c, "Failed to delete task schema", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"type":   c.Param("type"),
+		"status": "deleted",
+	})
+}
+
+// writeSchemaError 按错误类型返回schema接口的错误响应
+func (h *QueueHandler) writeSchemaError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrTaskSchemaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, models.ErrInvalidArgument):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		h.logger.ErrorContext(c.Request.Context(), message, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
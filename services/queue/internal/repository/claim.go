@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// claimStaleTasks 接管空闲超过ClaimIdle仍未确认的任务（持有任务的worker已崩溃或卡住）
+//
+// 被投递次数超过MaxDeliveries的任务不再返回，直接移入死信队列，避免导致worker崩溃的任务被反复接管。
+func (r *RedisRepository) claimStaleTasks(ctx context.Context, consumer string, count int64) ([]*models.Task, error) {
+	if r.config.ClaimIdleMs <= 0 || !r.claimDue() {
+		return nil, nil
+	}
+
+	messages, _, err := r.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   r.config.StreamName,
+		Group:    r.config.ConsumerGroup,
+		Consumer: consumer,
+		MinIdle:  r.config.GetClaimIdle(),
+		Start:    "0-0",
+		Count:    count,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending messages: %w", err)
+	}
+	// 接管满一批时可能还有超时任务，下次读取前继续接管
+	if int64(len(messages)) < count {
+		r.deferClaim()
+		r.removeIdleConsumers(ctx)
+	}
+
+	var tasks []*models.Task
+	for _, task := range r.messagesToTasks(ctx, messages) {
+		deliveries, err := r.deliveryCount(ctx, task.StreamID)
+		if err != nil {
+			return nil, err
+		}
+		if r.config.MaxDeliveries > 0 && deliveries > int64(r.config.MaxDeliveries) {
+			task.Error = fmt.Sprintf("exceeded max deliveries: delivered %d times without acknowledgement", deliveries-1)
+			if err := r.DeadLetterTask(ctx, task); err != nil {
+				return nil, err
+			}
+			// 任务执行状态保留worker崩溃前的running，更新为failed
+			if err := r.SaveJob(ctx, task); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// deliveryCount 获取待确认任务被投递的次数（含本次接管）
+func (r *RedisRepository) deliveryCount(ctx context.Context, streamID string) (int64, error) {
+	pending, err := r.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: r.config.StreamName,
+		Group:  r.config.ConsumerGroup,
+		Start:  streamID,
+		End:    streamID,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get delivery count of message %s: %w", streamID, err)
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+	return pending[0].RetryCount, nil
+}
+
+// removeIdleConsumers 删除没有待确认任务且空闲超过ClaimIdle的消费者，如已停止的worker和已下线的实例
+//
+// 仍在运行的worker下次读取时会被自动重新加入消费者组。
+func (r *RedisRepository) removeIdleConsumers(ctx context.Context) {
+	consumers, err := r.client.XInfoConsumers(ctx, r.config.StreamName, r.config.ConsumerGroup).Result()
+	if err != nil {
+		return
+	}
+	for _, consumer := range consumers {
+		if consumer.Pending == 0 && consumer.Idle > r.config.GetClaimIdle() {
+			r.client.XGroupDelConsumer(ctx, r.config.StreamName, r.config.ConsumerGroup, consumer.Name)
+		}
+	}
+}
+
+// claimDue 是否到了接管超时任务的时间
+func (r *RedisRepository) claimDue() bool {
+	r.claimMu.Lock()
+	defer r.claimMu.Unlock()
+	return !time.Now().Before(r.nextClaim)
+}
+
+// deferClaim 超时任务已接管完，半个ClaimIdle后再检查
+func (r *RedisRepository) deferClaim() {
+	r.claimMu.Lock()
+	r.nextClaim = time.Now().Add(r.config.GetClaimIdle() / 2)
+	r.claimMu.Unlock()
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DeadLetterTask 将任务移入死信队列并确认原消息（同一事务）
+func (r *RedisRepository) DeadLetterTask(ctx context.Context, task *models.Task) error {
+	taskData, err := deadLetterMember(task)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, r.deadLetterKey(), taskData)
+		pipe.XAck(ctx, r.config.StreamName, r.config.ConsumerGroup, task.StreamID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move task %s to dead letter queue: %w", task.ID, err)
+	}
+	return nil
+}
+
+// deadLetterMember 将任务标记为失败，返回死信队列成员
+func deadLetterMember(task *models.Task) ([]byte, error) {
+	now := time.Now()
+	task.Status = models.TaskStatusFailed
+	task.FailedAt = &now
+	task.UpdatedAt = now
+
+	taskData, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
+	}
+	return taskData, nil
+}
+
+// deadLetterEntry 死信队列中的一项，raw为列表中的原始成员，用于LREM
+type deadLetterEntry struct {
+	task *models.Task
+	raw  string
+}
+
+// deadLetterEntries 读取对请求租户可见且符合过滤条件的死信任务（按进入时间倒序）
+func (r *RedisRepository) deadLetterEntries(ctx context.Context, filter *models.DeadLetterFilter) ([]*deadLetterEntry, error) {
+	members, err := r.client.LRange(ctx, r.deadLetterKey(), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letter queue: %w", err)
+	}
+
+	entries := make([]*deadLetterEntry, 0, len(members))
+	for _, member := range members {
+		var task models.Task
+		if json.Unmarshal([]byte(member), &task) != nil {
+			continue
+		}
+		if !visibleToTenant(ctx, &task) || !filter.Matches(&task) {
+			continue
+		}
+		entries = append(entries, &deadLetterEntry{task: &task, raw: member})
+	}
+	return entries, nil
+}
+
+// ListDeadLetters 分页列出死信任务
+func (r *RedisRepository) ListDeadLetters(ctx context.Context, filter *models.DeadLetterFilter, limit, offset int) (*models.DeadLetterPage, error) {
+	entries, err := r.deadLetterEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.DeadLetterPage{Tasks: make([]*models.Task, 0), Total: len(entries), Offset: offset, Limit: limit}
+	for i := offset; i < len(entries) && len(page.Tasks) < limit; i++ {
+		page.Tasks = append(page.Tasks, entries[i].task)
+	}
+	return page, nil
+}
+
+// GetDeadLetter 获取死信任务，不存在时返回nil
+func (r *RedisRepository) GetDeadLetter(ctx context.Context, taskID string) (*models.Task, error) {
+	entry, err := r.findDeadLetter(ctx, taskID)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	return entry.task, nil
+}
+
+// RemoveDeadLetter 从死信队列移除任务，返回被移除的任务（不存在或已被并发移除时返回nil）
+func (r *RedisRepository) RemoveDeadLetter(ctx context.Context, taskID string) (*models.Task, error) {
+	entry, err := r.findDeadLetter(ctx, taskID)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	removed, err := r.removeDeadLetterEntry(ctx, entry)
+	if err != nil || !removed {
+		return nil, err
+	}
+	return entry.task, nil
+}
+
+// RemoveDeadLetters 移除符合过滤条件的死信任务，返回被移除的任务
+func (r *RedisRepository) RemoveDeadLetters(ctx context.Context, filter *models.DeadLetterFilter) ([]*models.Task, error) {
+	entries, err := r.deadLetterEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*models.Task, 0, len(entries))
+	for _, entry := range entries {
+		removed, err := r.removeDeadLetterEntry(ctx, entry)
+		if err != nil {
+			return tasks, err
+		}
+		if removed {
+			tasks = append(tasks, entry.task)
+		}
+	}
+	return tasks, nil
+}
+
+// RestoreDeadLetter 将任务放回死信队列（重新入队失败时使用）
+func (r *RedisRepository) RestoreDeadLetter(ctx context.Context, task *models.Task) error {
+	taskData, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	if err := r.client.LPush(ctx, r.deadLetterKey(), taskData).Err(); err != nil {
+		return fmt.Errorf("failed to restore dead letter task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+// findDeadLetter 按任务ID查找对请求租户可见的死信任务
+func (r *RedisRepository) findDeadLetter(ctx context.Context, taskID string) (*deadLetterEntry, error) {
+	entries, err := r.deadLetterEntries(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.task.ID == taskID {
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
+
+// removeDeadLetterEntry 按原始成员移除一项，并发移除时只有一个调用方返回true
+func (r *RedisRepository) removeDeadLetterEntry(ctx context.Context, entry *deadLetterEntry) (bool, error) {
+	removed, err := r.client.LRem(ctx, r.deadLetterKey(), 1, entry.raw).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove dead letter task %s: %w", entry.task.ID, err)
+	}
+	return removed > 0, nil
+}
+
+// deadLetterKey 死信队列（list，最新的在前），未配置时为任务流名称加:failed
+func (r *RedisRepository) deadLetterKey() string {
+	if r.config.DeadLetterQueue != "" {
+		return r.config.DeadLetterQueue
+	}
+	return r.config.StreamName + ":failed"
+}
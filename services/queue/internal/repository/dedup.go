@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseDedupScript 只有记录的仍是该任务时才删除去重记录，避免删掉之后其他任务写入的记录
+//
+// KEYS[1] 去重记录；ARGV[1] 任务ID。
+var releaseDedupScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// ClaimPayloadHash 记录payload哈希首次出现的任务，window后过期
+//
+// 窗口内首次出现时返回空字符串，否则返回首次出现的任务ID。
+func (r *RedisRepository) ClaimPayloadHash(ctx context.Context, hash, taskID string, window time.Duration) (string, error) {
+	key := r.dedupKey(hash)
+	// 已有记录恰好在SETNX和GET之间过期时重试一次
+	for attempt := 0; attempt < 2; attempt++ {
+		claimed, err := r.client.SetNX(ctx, key, taskID, window).Result()
+		if err != nil {
+			return "", fmt.Errorf("failed to claim payload hash: %w", err)
+		}
+		if claimed {
+			return "", nil
+		}
+
+		existing, err := r.client.Get(ctx, key).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get payload hash: %w", err)
+		}
+		return existing, nil
+	}
+	return "", nil
+}
+
+// ReleasePayloadHash 删除任务的去重记录，任务入队失败时调用
+func (r *RedisRepository) ReleasePayloadHash(ctx context.Context, hash, taskID string) error {
+	if err := releaseDedupScript.Run(ctx, r.client, []string{r.dedupKey(hash)}, taskID).Err(); err != nil {
+		return fmt.Errorf("failed to release payload hash: %w", err)
+	}
+	return nil
+}
+
+// dedupKey payload哈希去重记录的键
+func (r *RedisRepository) dedupKey(hash string) string {
+	return r.config.StreamName + ":dedup:" + hash
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// promoteDueScript 原子地将到期的延迟任务移入任务流
+//
+// KEYS[1] 延迟暂存区，KEYS[2] 任务流；ARGV[1] 当前毫秒时间戳，ARGV[2] 单次最多移动的任务数。
+// 流消息字段与AddTask一致，暂存区成员即任务JSON。
+var promoteDueScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+for _, data in ipairs(due) do
+	local task = cjson.decode(data)
+	redis.call('XADD', KEYS[2], '*',
+		'task_id', task.id,
+		'task_type', task.type,
+		'tenant', task.tenant,
+		'priority', tostring(task.priority),
+		'data', data,
+		'created_at', task.created_at)
+	redis.call('ZREM', KEYS[1], data)
+end
+return #due
+`)
+
+// ScheduleTask 将任务放入延迟暂存区，到达task.ScheduledAt后才由PromoteDueTasks移入任务流对worker可见
+func (r *RedisRepository) ScheduleTask(ctx context.Context, task *models.Task) error {
+	// 暂存区中的任务以移入任务流后的状态保存
+	queued := *task
+	queued.Status = models.TaskStatusPending
+	member, err := delayedMember(&queued)
+	if err != nil {
+		return err
+	}
+
+	err = r.client.ZAdd(ctx, r.delayedKey(), redis.Z{
+		Score:  float64(task.ScheduledAt.UnixMilli()),
+		Member: member,
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to schedule task: %w", err)
+	}
+	return nil
+}
+
+// PromoteDueTasks 将到期（ScheduledAt不晚于now）的延迟任务移入任务流，返回移动的任务数
+func (r *RedisRepository) PromoteDueTasks(ctx context.Context, now time.Time, limit int64) (int64, error) {
+	promoted, err := promoteDueScript.Run(ctx, r.client,
+		[]string{r.delayedKey(), r.config.StreamName},
+		strconv.FormatInt(now.UnixMilli(), 10), limit,
+	).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to promote due tasks: %w", err)
+	}
+	return promoted, nil
+}
+
+// promoteDueTasksTo 将到期的延迟任务逐个交给add写入外部任务流（Kafka、NATS），返回移动的任务数
+//
+// 从暂存区删除成功的实例负责写入，多个实例同时运行时每个任务只入队一次；写入失败时放回暂存区。
+func (r *RedisRepository) promoteDueTasksTo(ctx context.Context, now time.Time, limit int64, add func(ctx context.Context, task *models.Task) error) (int64, error) {
+	due, err := r.client.ZRangeByScoreWithScores(ctx, r.delayedKey(), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to promote due tasks: %w", err)
+	}
+
+	var promoted int64
+	for _, z := range due {
+		member := z.Member.(string)
+		removed, err := r.client.ZRem(ctx, r.delayedKey(), member).Result()
+		if err != nil {
+			return promoted, fmt.Errorf("failed to promote due tasks: %w", err)
+		}
+		if removed == 0 {
+			continue
+		}
+
+		var task models.Task
+		if err := json.Unmarshal([]byte(member), &task); err != nil {
+			continue
+		}
+		if err := add(ctx, &task); err != nil {
+			r.client.ZAdd(ctx, r.delayedKey(), z)
+			return promoted, fmt.Errorf("failed to promote task %s: %w", task.ID, err)
+		}
+		promoted++
+	}
+	return promoted, nil
+}
+
+// scheduleRetry 将等待重试的任务放入延迟暂存区，到达retryAt后重新入队
+func (r *RedisRepository) scheduleRetry(ctx context.Context, task *models.Task, retryAt time.Time) error {
+	member, err := delayedMember(task)
+	if err != nil {
+		return err
+	}
+	err = r.client.ZAdd(ctx, r.delayedKey(), redis.Z{
+		Score:  float64(retryAt.UnixMilli()),
+		Member: member,
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to schedule retry for task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+// ListScheduledTasks 按可见时间升序列出尚未到期的延迟任务
+func (r *RedisRepository) ListScheduledTasks(ctx context.Context, limit int64) ([]*models.Task, error) {
+	// 按租户过滤时需要扫描整个暂存区
+	stop := limit - 1
+	if _, ok := models.LookupTenant(ctx); ok || limit <= 0 {
+		stop = -1
+	}
+	members, err := r.client.ZRange(ctx, r.delayedKey(), 0, stop).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled tasks: %w", err)
+	}
+
+	tasks := make([]*models.Task, 0, len(members))
+	for _, member := range members {
+		if limit > 0 && int64(len(tasks)) >= limit {
+			break
+		}
+		task, err := scheduledTask(member)
+		if err != nil || !visibleToTenant(ctx, task) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// findScheduledTask 在延迟暂存区中查找任务，不存在时返回nil
+func (r *RedisRepository) findScheduledTask(ctx context.Context, taskID string) (*models.Task, error) {
+	members, err := r.client.ZRange(ctx, r.delayedKey(), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search scheduled tasks: %w", err)
+	}
+	for _, member := range members {
+		task, err := scheduledTask(member)
+		if err == nil && task.ID == taskID && visibleToTenant(ctx, task) {
+			return task, nil
+		}
+	}
+	return nil, nil
+}
+
+// scheduledTaskCount 统计延迟暂存区中的任务数，tenant非空时只统计该租户
+func (r *RedisRepository) scheduledTaskCount(ctx context.Context, tenant string) (int64, error) {
+	if tenant == "" {
+		return r.client.ZCard(ctx, r.delayedKey()).Result()
+	}
+
+	members, err := r.client.ZRange(ctx, r.delayedKey(), 0, -1).Result()
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, member := range members {
+		if task, err := scheduledTask(member); err == nil && task.TenantOrDefault() == tenant {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// delayedMember 延迟暂存区成员：任务JSON，租户补全为默认租户，不含流消息ID
+func delayedMember(task *models.Task) (string, error) {
+	queued := *task
+	queued.Tenant = task.TenantOrDefault()
+	queued.StreamID = ""
+	taskData, err := json.Marshal(&queued)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal task: %w", err)
+	}
+	return string(taskData), nil
+}
+
+// scheduledTask 解析暂存区成员，等待重试的任务状态为retrying，其余为scheduled
+func scheduledTask(member string) (*models.Task, error) {
+	var task models.Task
+	if err := json.Unmarshal([]byte(member), &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scheduled task: %w", err)
+	}
+	if task.Status != models.TaskStatusRetrying {
+		task.Status = models.TaskStatusScheduled
+	}
+	return &task, nil
+}
+
+// delayedKey 延迟任务暂存区（sorted set，score为可见时间的毫秒时间戳）
+func (r *RedisRepository) delayedKey() string {
+	return r.config.StreamName + ":delayed"
+}
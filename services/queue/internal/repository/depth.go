@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Depth 统计任务流、延迟暂存区和死信队列中的任务数，以及最早未确认任务的等待时间
+//
+// 无法确定消费者组的lag（Redis 7以下或流中有删除的消息）时Ready为0，与Backlog一致。
+func (r *RedisRepository) Depth(ctx context.Context) (*QueueDepth, error) {
+	depth := &QueueDepth{Queue: r.config.Name}
+
+	groups, err := r.client.XInfoGroups(ctx, r.config.StreamName).Result()
+	if err != nil && err.Error() != "ERR no such key" {
+		return nil, fmt.Errorf("failed to get consumer group info: %w", err)
+	}
+	lastDelivered := "0-0"
+	groupFound := false
+	for _, group := range groups {
+		if group.Name != r.config.ConsumerGroup {
+			continue
+		}
+		groupFound = true
+		lastDelivered = group.LastDeliveredID
+		depth.InFlight = group.Pending
+		if group.Lag > 0 {
+			depth.Ready = group.Lag
+		}
+		break
+	}
+	if err == nil && !groupFound {
+		// 消费者组尚未创建，流中的消息都未被读取
+		if depth.Ready, err = r.client.XLen(ctx, r.config.StreamName).Result(); err != nil {
+			return nil, fmt.Errorf("failed to get stream length: %w", err)
+		}
+	}
+
+	// 最早的未确认任务：有待确认的消息时取其中ID最小的，否则取第一条未投递的消息
+	var oldestID string
+	if depth.InFlight > 0 {
+		summary, err := r.client.XPending(ctx, r.config.StreamName, r.config.ConsumerGroup).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pending summary: %w", err)
+		}
+		oldestID = summary.Lower
+	} else if groupFound || depth.Ready > 0 {
+		messages, err := r.client.XRangeN(ctx, r.config.StreamName, "("+lastDelivered, "+", 1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stream: %w", err)
+		}
+		if len(messages) > 0 {
+			oldestID = messages[0].ID
+		}
+	}
+	if oldestID != "" {
+		if id, err := parseMemoryID(oldestID); err == nil {
+			depth.OldestAge = max(time.Since(time.UnixMilli(id.ms)), 0)
+		}
+	}
+
+	if err := r.stagedDepth(ctx, depth); err != nil {
+		return nil, err
+	}
+	return depth, nil
+}
+
+// stagedDepth 填充延迟暂存区和死信队列中的任务数，并将租约中的任务计入InFlight
+func (r *RedisRepository) stagedDepth(ctx context.Context, depth *QueueDepth) error {
+	scheduled, err := r.scheduledTaskCount(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to count scheduled tasks: %w", err)
+	}
+	deadLetter, err := r.client.LLen(ctx, r.deadLetterKey()).Result()
+	if err != nil {
+		return fmt.Errorf("failed to count dead letters: %w", err)
+	}
+	leased, err := r.client.ZCard(ctx, r.leasesKey()).Result()
+	if err != nil {
+		return fmt.Errorf("failed to count leases: %w", err)
+	}
+	depth.Scheduled = scheduled
+	depth.DeadLetter = deadLetter
+	depth.InFlight += leased
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// claimStaleHeartbeatsScript 原子地取出心跳超时的任务，多个实例同时检查时每个任务只被一个实例取回
+//
+// KEYS[1] 心跳有序集合（成员为消息ID，score为最近一次心跳的毫秒时间戳），KEYS[2] 处理中任务的哈希；
+// ARGV[1] 超时时间点的毫秒时间戳，ARGV[2] 单次最多取回的任务数。返回消息ID和任务JSON交替的数组。
+var claimStaleHeartbeatsScript = redis.NewScript(`
+local stale = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+local result = {}
+for _, id in ipairs(stale) do
+	local data = redis.call('HGET', KEYS[2], id)
+	redis.call('ZREM', KEYS[1], id)
+	redis.call('HDEL', KEYS[2], id)
+	if data then
+		table.insert(result, id)
+		table.insert(result, data)
+	end
+end
+return result
+`)
+
+// StartHeartbeat 记录worker开始处理任务，保存任务以便心跳超时后重新入队
+func (r *RedisRepository) StartHeartbeat(ctx context.Context, task *models.Task, at time.Time) error {
+	taskData, err := jobSnapshot(task)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, r.runningKey(), task.StreamID, taskData)
+		pipe.ZAdd(ctx, r.heartbeatsKey(), redis.Z{Score: float64(at.UnixMilli()), Member: task.StreamID})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start heartbeat for task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+// Heartbeat 更新任务的心跳时间，返回false表示任务已因心跳超时被取回
+func (r *RedisRepository) Heartbeat(ctx context.Context, streamID string, at time.Time) (bool, error) {
+	updated, err := r.client.ZAddArgs(ctx, r.heartbeatsKey(), redis.ZAddArgs{
+		XX:      true,
+		Ch:      true,
+		Members: []redis.Z{{Score: float64(at.UnixMilli()), Member: streamID}},
+	}).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to heartbeat message %s: %w", streamID, err)
+	}
+	if updated > 0 {
+		return true, nil
+	}
+	// 心跳时间未变化（同一毫秒内）时CH不计数，再确认记录是否存在
+	_, err = r.client.ZScore(ctx, r.heartbeatsKey(), streamID).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to heartbeat message %s: %w", streamID, err)
+	}
+	return true, nil
+}
+
+// ClearHeartbeat 删除任务的心跳记录，worker处理结束后调用
+func (r *RedisRepository) ClearHeartbeat(ctx context.Context, streamID string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, r.heartbeatsKey(), streamID)
+		pipe.HDel(ctx, r.runningKey(), streamID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear heartbeat of message %s: %w", streamID, err)
+	}
+	return nil
+}
+
+// ClaimStaleHeartbeats 取出最近一次心跳早于before的任务并删除其心跳记录，任务的StreamID为原消息ID
+func (r *RedisRepository) ClaimStaleHeartbeats(ctx context.Context, before time.Time, limit int64) ([]*models.Task, error) {
+	values, err := claimStaleHeartbeatsScript.Run(ctx, r.client,
+		[]string{r.heartbeatsKey(), r.runningKey()},
+		strconv.FormatInt(before.UnixMilli(), 10), limit,
+	).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim stale heartbeats: %w", err)
+	}
+
+	tasks := make([]*models.Task, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		var task models.Task
+		if err := json.Unmarshal([]byte(values[i+1]), &task); err != nil {
+			continue
+		}
+		task.StreamID = values[i]
+		tasks = append(tasks, &task)
+	}
+	return tasks, nil
+}
+
+// heartbeatsKey 处理中任务最近一次心跳时间的有序集合键
+func (r *RedisRepository) heartbeatsKey() string {
+	return r.config.StreamName + ":heartbeats"
+}
+
+// runningKey 处理中任务的哈希键，按消息ID保存任务
+func (r *RedisRepository) runningKey() string {
+	return r.config.StreamName + ":running"
+}
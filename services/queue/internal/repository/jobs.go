@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SaveJob 保存任务执行状态的快照，每次保存重新计算过期时间
+func (r *RedisRepository) SaveJob(ctx context.Context, task *models.Task) error {
+	taskData, err := jobSnapshot(task)
+	if err != nil {
+		return err
+	}
+
+	if err := r.client.Set(ctx, r.jobKey(task.ID), taskData, r.config.GetJobTTL()).Err(); err != nil {
+		return fmt.Errorf("failed to save job %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+// SaveJobs 用一次pipeline保存多个任务的执行状态
+func (r *RedisRepository) SaveJobs(ctx context.Context, tasks []*models.Task) error {
+	pipe := r.client.Pipeline()
+	for _, task := range tasks {
+		taskData, err := jobSnapshot(task)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, r.jobKey(task.ID), taskData, r.config.GetJobTTL())
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save jobs: %w", err)
+	}
+	return nil
+}
+
+// jobSnapshot 任务执行状态快照，不含后端的消息ID
+func jobSnapshot(task *models.Task) ([]byte, error) {
+	snapshot := *task
+	snapshot.StreamID = ""
+	taskData, err := json.Marshal(&snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
+	}
+	return taskData, nil
+}
+
+// GetJob 获取任务执行状态，不存在、已过期或对请求租户不可见时返回nil
+func (r *RedisRepository) GetJob(ctx context.Context, taskID string) (*models.Task, error) {
+	taskData, err := r.client.Get(ctx, r.jobKey(taskID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job %s: %w", taskID, err)
+	}
+
+	var task models.Task
+	if err := json.Unmarshal(taskData, &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job %s: %w", taskID, err)
+	}
+	if !visibleToTenant(ctx, &task) {
+		return nil, nil
+	}
+	return &task, nil
+}
+
+// jobKey 任务执行状态的键
+func (r *RedisRepository) jobKey(taskID string) string {
+	return r.config.StreamName + ":jobs:" + taskID
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"mocks3/services/queue/internal/config"
+	"os"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// kafkaClientOptions 连接broker的公共选项：客户端ID、压缩、TLS和SASL认证
+func kafkaClientOptions(kafkaConfig *config.KafkaConfig) ([]kgo.Opt, error) {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(kafkaConfig.Brokers...),
+		kgo.ClientID(kafkaConfig.ClientID),
+		kgo.ProducerBatchCompression(kafkaCompression(kafkaConfig.Compression)),
+	}
+
+	if kafkaConfig.TLS {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if kafkaConfig.TLSCAFile != "" {
+			pem, err := os.ReadFile(kafkaConfig.TLSCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read kafka CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in kafka CA file %s", kafkaConfig.TLSCAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		opts = append(opts, kgo.DialTLSConfig(tlsConfig))
+	}
+
+	switch kafkaConfig.SASLMechanism {
+	case config.KafkaSASLPlain:
+		opts = append(opts, kgo.SASL(plain.Auth{User: kafkaConfig.SASLUsername, Pass: kafkaConfig.SASLPassword}.AsMechanism()))
+	case config.KafkaSASLScramSHA256:
+		opts = append(opts, kgo.SASL(scram.Auth{User: kafkaConfig.SASLUsername, Pass: kafkaConfig.SASLPassword}.AsSha256Mechanism()))
+	case config.KafkaSASLScramSHA512:
+		opts = append(opts, kgo.SASL(scram.Auth{User: kafkaConfig.SASLUsername, Pass: kafkaConfig.SASLPassword}.AsSha512Mechanism()))
+	}
+	return opts, nil
+}
+
+// kafkaCompression 压缩算法名称对应的编码器，拉取时按消息批次自带的算法解压
+func kafkaCompression(name string) kgo.CompressionCodec {
+	switch name {
+	case "gzip":
+		return kgo.GzipCompression()
+	case "snappy":
+		return kgo.SnappyCompression()
+	case "lz4":
+		return kgo.Lz4Compression()
+	case "zstd":
+		return kgo.ZstdCompression()
+	default:
+		return kgo.NoCompression()
+	}
+}
@@ -0,0 +1,840 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"mocks3/services/queue/internal/config"
+	"mocks3/shared/models"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// Kafka拉取参数
+const (
+	kafkaFetchMaxWait  = 5 * time.Second
+	kafkaFetchMaxBytes = 1 << 20
+)
+
+// KafkaRepository Kafka队列仓库
+//
+// 任务流是一个多分区的Kafka topic，每个worker作为消费者组成员按range策略分到一部分分区，
+// 确认任务后按分区提交连续已确认的最大offset，未确认的任务在重平衡或重启后重新投递。
+// 延迟暂存区、死信队列和事件主题仍保存在Redis中。
+type KafkaRepository struct {
+	*RedisRepository
+
+	client      *kgo.Client // 写入任务、提交offset和查询分区状态，不加入消费者组
+	admin       *kadm.Client
+	clientOpts  []kgo.Opt
+	config      *config.QueueConfig
+	kafkaConfig *config.KafkaConfig
+
+	mu         sync.Mutex
+	consumers  map[string]*kafkaConsumer
+	partitions map[int32]*partitionState // 本进程中的消费者当前拥有的分区
+}
+
+// kafkaConsumer 一个worker对应的消费者组成员
+type kafkaConsumer struct {
+	client *kgo.Client
+	mu     sync.Mutex // 同一消费者的拉取串行执行
+}
+
+// partitionState 分区的拉取位置和未确认的消息
+type partitionState struct {
+	consumer   *kafkaConsumer
+	generation int32          // 分配分区时的消费者组generation，提交offset时由broker校验
+	memberID   string         // 分配分区时的成员ID
+	next       int64          // 下一条要拉取的offset，还没有拉取过时为-1
+	inflight   map[int64]bool // 已拉取未确认的offset
+	committed  int64          // 本次分配中提交过的offset，没有提交过时为-1
+}
+
+// commitOffset 可以提交的offset：最小的未确认offset，全部确认时为下一条要拉取的offset
+func (p *partitionState) commitOffset() int64 {
+	offset := p.next
+	for inflight := range p.inflight {
+		if inflight < offset {
+			offset = inflight
+		}
+	}
+	return offset
+}
+
+var _ QueueRepository = (*KafkaRepository)(nil)
+
+// NewKafkaRepository 创建Kafka仓库，topic不存在时按配置的分区数创建
+func NewKafkaRepository(redisConfig *config.RedisConfig, queueConfig *config.QueueConfig, kafkaConfig *config.KafkaConfig) (*KafkaRepository, error) {
+	redisRepo, err := NewRedisRepository(redisConfig, queueConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOpts, err := kafkaClientOptions(kafkaConfig)
+	if err != nil {
+		redisRepo.Close()
+		return nil, err
+	}
+	client, err := kgo.NewClient(clientOpts...)
+	if err != nil {
+		redisRepo.Close()
+		return nil, fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+	admin := kadm.NewClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := ensureKafkaTopic(ctx, admin, kafkaConfig); err != nil {
+		client.Close()
+		redisRepo.Close()
+		return nil, fmt.Errorf("failed to create Kafka topic %s: %w", kafkaConfig.Topic, err)
+	}
+
+	return &KafkaRepository{
+		RedisRepository: redisRepo,
+		client:          client,
+		admin:           admin,
+		clientOpts:      clientOpts,
+		config:          queueConfig,
+		kafkaConfig:     kafkaConfig,
+		consumers:       make(map[string]*kafkaConsumer),
+		partitions:      make(map[int32]*partitionState),
+	}, nil
+}
+
+// ensureKafkaTopic 创建topic（已存在时忽略），并等待所有分区选出leader
+func ensureKafkaTopic(ctx context.Context, admin *kadm.Client, kafkaConfig *config.KafkaConfig) error {
+	topic := kafkaConfig.Topic
+	_, err := admin.CreateTopic(ctx, int32(kafkaConfig.Partitions), int16(kafkaConfig.ReplicationFactor), nil, topic)
+	if err != nil && !errors.Is(err, kerr.TopicAlreadyExists) {
+		return err
+	}
+
+	// 新建的topic可能需要一段时间才能选出leader
+	for {
+		offsets, err := admin.ListEndOffsets(ctx, topic)
+		if err == nil {
+			err = offsets.Error()
+		}
+		if err == nil && len(offsets[topic]) > 0 {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("topic %s has no partitions", topic)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// AddTask 写入任务，同一对象的任务进入同一分区以保持顺序
+func (r *KafkaRepository) AddTask(ctx context.Context, task *models.Task) error {
+	taskData, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	key := task.ObjectKey
+	if key == "" {
+		key = task.ID
+	}
+
+	// 默认分区器按key的murmur2哈希选择分区，与Java客户端一致
+	record := &kgo.Record{
+		Topic: r.kafkaConfig.Topic,
+		Key:   []byte(key),
+		Value: taskData,
+		Headers: []kgo.RecordHeader{
+			{Key: "task_id", Value: []byte(task.ID)},
+			{Key: "task_type", Value: []byte(task.Type)},
+			{Key: "tenant", Value: []byte(task.TenantOrDefault())},
+		},
+	}
+	if err := r.client.ProduceSync(ctx, record).FirstErr(); err != nil {
+		return fmt.Errorf("failed to add task to topic: %w", err)
+	}
+
+	task.StreamID = kafkaStreamID(record.Partition, record.Offset)
+	return nil
+}
+
+// AddTasks 逐条写入任务，返回与tasks一一对应的错误
+func (r *KafkaRepository) AddTasks(ctx context.Context, tasks []*models.Task) []error {
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		errs[i] = r.AddTask(ctx, task)
+	}
+	return errs
+}
+
+// GetTasks 以consumerName加入消费者组并从分配到的分区拉取任务，没有任务时最多等待kafkaFetchMaxWait
+func (r *KafkaRepository) GetTasks(ctx context.Context, consumerName string, count int64) ([]*models.Task, error) {
+	consumer, err := r.consumer(consumerName)
+	if err != nil {
+		return nil, err
+	}
+	consumer.mu.Lock()
+	defer consumer.mu.Unlock()
+
+	// 拉取到消息后客户端阻塞重平衡，直到记录完拉取位置
+	pollCtx, cancel := context.WithTimeout(ctx, kafkaFetchMaxWait)
+	fetches := consumer.client.PollRecords(pollCtx, int(count))
+	cancel()
+	defer consumer.client.AllowRebalance()
+
+	if fetches.IsClientClosed() {
+		return nil, fmt.Errorf("kafka consumer %s is closed", consumerName)
+	}
+
+	tasks := []*models.Task{}
+	r.mu.Lock()
+	fetches.EachRecord(func(msg *kgo.Record) {
+		state, ok := r.partitions[msg.Partition]
+		if !ok || state.consumer != consumer {
+			return
+		}
+		state.next = msg.Offset + 1
+
+		var task models.Task
+		if err := json.Unmarshal(msg.Value, &task); err != nil {
+			// 无法解析的消息直接跳过，不阻塞分区
+			return
+		}
+		task.StreamID = kafkaStreamID(msg.Partition, msg.Offset)
+		state.inflight[msg.Offset] = true
+		tasks = append(tasks, &task)
+	})
+	r.mu.Unlock()
+
+	if len(tasks) == 0 {
+		if err := kafkaFetchError(fetches); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+// kafkaFetchError 拉取结果中的分区错误，忽略拉取超时
+//
+// 消息已过保留期被删除时客户端自动从最早的offset继续，offset被截断时报告ErrDataLoss后继续拉取，都不作为错误。
+func kafkaFetchError(fetches kgo.Fetches) error {
+	var fetchErr error
+	fetches.EachError(func(_ string, partition int32, err error) {
+		var dataLoss *kgo.ErrDataLoss
+		if fetchErr != nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || errors.As(err, &dataLoss) {
+			return
+		}
+		fetchErr = fmt.Errorf("failed to fetch partition %d: %w", partition, err)
+	})
+	return fetchErr
+}
+
+// AckTask 确认任务，提交该分区连续已确认的offset
+//
+// 分区已分配给其他成员时忽略确认，消息会被重新投递。
+func (r *KafkaRepository) AckTask(ctx context.Context, streamID string) error {
+	partition, offset, err := parseKafkaStreamID(streamID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	state, ok := r.partitions[partition]
+	if !ok || !state.inflight[offset] {
+		r.mu.Unlock()
+		return nil
+	}
+	delete(state.inflight, offset)
+	commitOffset := state.commitOffset()
+	if commitOffset <= state.committed {
+		r.mu.Unlock()
+		return nil
+	}
+	generation, memberID := state.generation, state.memberID
+	r.mu.Unlock()
+
+	if err := r.commitOffsets(ctx, generation, memberID, map[int32]int64{partition: commitOffset}); err != nil {
+		return fmt.Errorf("failed to ack message %s: %w", streamID, err)
+	}
+
+	r.mu.Lock()
+	if state.committed < commitOffset {
+		state.committed = commitOffset
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// RetryTask 将失败的任务在retryAt重新入队并确认原消息，等待重试的任务放在Redis延迟暂存区中
+func (r *KafkaRepository) RetryTask(ctx context.Context, task *models.Task, retryAt time.Time) error {
+	streamID := task.StreamID
+	now := time.Now()
+	task.Status = models.TaskStatusRetrying
+	task.UpdatedAt = now
+	task.ScheduledAt = retryAt
+
+	if !retryAt.After(now) {
+		if err := r.AddTask(ctx, task); err != nil {
+			return err
+		}
+		return r.AckTask(ctx, streamID)
+	}
+
+	if err := r.scheduleRetry(ctx, task, retryAt); err != nil {
+		return err
+	}
+	task.StreamID = ""
+	return r.AckTask(ctx, streamID)
+}
+
+// DeadLetterTask 将任务移入Redis死信队列并确认原消息
+func (r *KafkaRepository) DeadLetterTask(ctx context.Context, task *models.Task) error {
+	taskData, err := deadLetterMember(task)
+	if err != nil {
+		return err
+	}
+	if err := r.RedisRepository.client.LPush(ctx, r.deadLetterKey(), taskData).Err(); err != nil {
+		return fmt.Errorf("failed to move task %s to dead letter queue: %w", task.ID, err)
+	}
+	return r.AckTask(ctx, task.StreamID)
+}
+
+// PromoteDueTasks 将到期的延迟任务写入Kafka，返回移动的任务数
+func (r *KafkaRepository) PromoteDueTasks(ctx context.Context, now time.Time, limit int64) (int64, error) {
+	return r.promoteDueTasksTo(ctx, now, limit, r.AddTask)
+}
+
+// GetTaskStatus 获取任务状态：依次查找未消费的消息、延迟暂存区和死信队列
+func (r *KafkaRepository) GetTaskStatus(ctx context.Context, taskID string) (*models.Task, error) {
+	var found *models.Task
+	err := r.scanPending(ctx, func(task *models.Task) bool {
+		if task.ID == taskID && visibleToTenant(ctx, task) {
+			found = task
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found != nil {
+		return found, nil
+	}
+
+	if task, err := r.findScheduledTask(ctx, taskID); err != nil {
+		return nil, err
+	} else if task != nil {
+		return task, nil
+	}
+
+	if task, err := r.GetDeadLetter(ctx, taskID); err == nil && task != nil {
+		return task, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", models.ErrTaskNotFound, taskID)
+}
+
+// ListTasks 列出任务，pending为消费者组尚未提交的消息
+func (r *KafkaRepository) ListTasks(ctx context.Context, status string, limit int64) ([]*models.Task, error) {
+	switch status {
+	case "pending", "processing", "":
+		var tasks []*models.Task
+		err := r.scanPending(ctx, func(task *models.Task) bool {
+			if limit > 0 && int64(len(tasks)) >= limit {
+				return false
+			}
+			if visibleToTenant(ctx, task) && (status == "" || string(task.Status) == status) {
+				tasks = append(tasks, task)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pending tasks: %w", err)
+		}
+		return tasks, nil
+	default:
+		// 延迟任务和失败任务在Redis中
+		return r.RedisRepository.ListTasks(ctx, status, limit)
+	}
+}
+
+// GetStats 获取队列统计信息，pending_count为消费者组在各分区的积压（lag）之和
+func (r *KafkaRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	lags, err := r.partitionLags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]interface{})
+	var pendingCount int64
+	partitions := make([]map[string]interface{}, 0, len(lags))
+	for _, lag := range lags {
+		pendingCount += lag.highWatermark - lag.committed
+		partitions = append(partitions, map[string]interface{}{
+			"partition":      lag.partition,
+			"committed":      lag.committed,
+			"high_watermark": lag.highWatermark,
+			"lag":            lag.highWatermark - lag.committed,
+		})
+	}
+	stats["pending_count"] = pendingCount
+
+	failedCount, err := r.RedisRepository.client.LLen(ctx, r.deadLetterKey()).Result()
+	if err == nil {
+		stats["failed_count"] = failedCount
+	}
+
+	scheduledCount, err := r.scheduledTaskCount(ctx, "")
+	if err == nil {
+		stats["scheduled_count"] = scheduledCount
+	}
+
+	r.mu.Lock()
+	localConsumers := len(r.consumers)
+	r.mu.Unlock()
+	stats["consumer_group"] = map[string]interface{}{
+		"name":       r.config.ConsumerGroup,
+		"consumers":  localConsumers,
+		"partitions": partitions,
+	}
+
+	if tenant, ok := models.LookupTenant(ctx); ok {
+		tenantStats, err := r.tenantTaskCounts(ctx, tenant)
+		if err != nil {
+			return nil, err
+		}
+		stats["tenant"] = tenantStats
+	}
+
+	stats["backend"] = config.BackendKafka
+	stats["stream_name"] = r.kafkaConfig.Topic
+	stats["max_retries"] = r.config.Retry.MaxAttempts
+
+	return stats, nil
+}
+
+// Backlog 消费者组在各分区的积压（lag）之和，包括已拉取未确认的消息
+func (r *KafkaRepository) Backlog(ctx context.Context) (int64, error) {
+	lags, err := r.partitionLags(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var backlog int64
+	for _, lag := range lags {
+		backlog += lag.highWatermark - lag.committed
+	}
+	return backlog, nil
+}
+
+// Depth 统计各状态的任务数，以及各分区第一条未提交消息中最早的写入时间
+//
+// InFlight只包括本进程中的消费者已拉取未确认的消息。
+func (r *KafkaRepository) Depth(ctx context.Context) (*QueueDepth, error) {
+	lags, err := r.partitionLags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	depth := &QueueDepth{Queue: r.config.Name}
+	var uncommitted int64
+	var oldest time.Time
+	var reader *partitionReader
+	for _, lag := range lags {
+		if lag.committed >= lag.highWatermark {
+			continue
+		}
+		uncommitted += lag.highWatermark - lag.committed
+
+		if reader == nil {
+			if reader, err = r.newPartitionReader(ctx); err != nil {
+				return nil, err
+			}
+		}
+		messages, err := reader.fetch(ctx, lag.partition, lag.committed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch partition %d: %w", lag.partition, err)
+		}
+		if len(messages) > 0 && (oldest.IsZero() || messages[0].Timestamp.Before(oldest)) {
+			oldest = messages[0].Timestamp
+		}
+	}
+
+	r.mu.Lock()
+	for _, state := range r.partitions {
+		depth.InFlight += int64(len(state.inflight))
+	}
+	r.mu.Unlock()
+	depth.Ready = max(uncommitted-depth.InFlight, 0)
+	if !oldest.IsZero() {
+		depth.OldestAge = max(time.Since(oldest), 0)
+	}
+
+	if err := r.stagedDepth(ctx, depth); err != nil {
+		return nil, err
+	}
+	return depth, nil
+}
+
+// tenantTaskCounts 统计租户在未消费消息、死信队列和延迟暂存区中的任务数
+func (r *KafkaRepository) tenantTaskCounts(ctx context.Context, tenant string) (map[string]interface{}, error) {
+	var pending int64
+	err := r.scanPending(ctx, func(task *models.Task) bool {
+		if task.TenantOrDefault() == tenant {
+			pending++
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant tasks: %w", err)
+	}
+
+	failed, err := r.deadLetterEntries(models.WithTenant(ctx, tenant), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant failed tasks: %w", err)
+	}
+
+	scheduled, err := r.scheduledTaskCount(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant scheduled tasks: %w", err)
+	}
+
+	return map[string]interface{}{
+		"name":            tenant,
+		"pending_count":   pending,
+		"failed_count":    int64(len(failed)),
+		"scheduled_count": scheduled,
+	}, nil
+}
+
+// Close 离开消费者组并关闭连接
+func (r *KafkaRepository) Close() error {
+	r.mu.Lock()
+	consumers := make([]*kafkaConsumer, 0, len(r.consumers))
+	for _, consumer := range r.consumers {
+		consumers = append(consumers, consumer)
+	}
+	r.mu.Unlock()
+
+	// 离开消费者组时撤销分区，提交已确认的进度
+	for _, consumer := range consumers {
+		consumer.client.CloseAllowingRebalance()
+	}
+
+	r.client.Close()
+	return r.RedisRepository.Close()
+}
+
+// consumer 获取或创建worker对应的消费者，消费者组名沿用QUEUE_CONSUMER_GROUP
+func (r *KafkaRepository) consumer(name string) (*kafkaConsumer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if consumer, ok := r.consumers[name]; ok {
+		return consumer, nil
+	}
+
+	topic := r.kafkaConfig.Topic
+	consumer := &kafkaConsumer{}
+	opts := append(slices.Clone(r.clientOpts),
+		kgo.ConsumerGroup(r.config.ConsumerGroup),
+		kgo.ConsumeTopics(topic),
+		kgo.Balancers(kgo.RangeBalancer()),
+		kgo.SessionTimeout(r.kafkaConfig.GetSessionTimeout()),
+		kgo.RebalanceTimeout(r.kafkaConfig.GetSessionTimeout()),
+		kgo.HeartbeatInterval(r.kafkaConfig.GetHeartbeatInterval()),
+		kgo.FetchMaxBytes(kafkaFetchMaxBytes),
+		// 没有已提交offset的分区从最早的offset开始拉取
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+		kgo.DisableAutoCommit(),
+		kgo.BlockRebalanceOnPoll(),
+		kgo.OnPartitionsAssigned(func(_ context.Context, cl *kgo.Client, assigned map[string][]int32) {
+			r.assignPartitions(consumer, cl, assigned[topic])
+		}),
+		kgo.OnPartitionsRevoked(func(ctx context.Context, _ *kgo.Client, revoked map[string][]int32) {
+			r.revokePartitions(ctx, consumer, revoked[topic], true)
+		}),
+		kgo.OnPartitionsLost(func(ctx context.Context, _ *kgo.Client, lost map[string][]int32) {
+			r.revokePartitions(ctx, consumer, lost[topic], false)
+		}),
+	)
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka consumer: %w", err)
+	}
+	consumer.client = client
+	r.consumers[name] = consumer
+	return consumer, nil
+}
+
+// assignPartitions 记录重平衡后分配给消费者的分区，客户端从已提交的offset继续拉取
+func (r *KafkaRepository) assignPartitions(consumer *kafkaConsumer, client *kgo.Client, assigned []int32) {
+	memberID, generation := client.GroupMetadata()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, partition := range assigned {
+		r.partitions[partition] = &partitionState{
+			consumer:   consumer,
+			generation: generation,
+			memberID:   memberID,
+			next:       -1,
+			inflight:   make(map[int64]bool),
+			committed:  -1,
+		}
+	}
+}
+
+// revokePartitions 移除消费者失去的分区，正常撤销时先提交已确认的进度，减少重复投递
+func (r *KafkaRepository) revokePartitions(ctx context.Context, consumer *kafkaConsumer, revoked []int32, commit bool) {
+	r.mu.Lock()
+	var generation int32
+	var memberID string
+	pending := make(map[int32]int64)
+	for _, partition := range revoked {
+		state, ok := r.partitions[partition]
+		if !ok || state.consumer != consumer {
+			continue
+		}
+		if offset := state.commitOffset(); offset > state.committed {
+			pending[partition] = offset
+			generation, memberID = state.generation, state.memberID
+		}
+		delete(r.partitions, partition)
+	}
+	r.mu.Unlock()
+
+	if commit && len(pending) > 0 {
+		commitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		r.commitOffsets(commitCtx, generation, memberID, pending)
+	}
+}
+
+// commitOffsets 以分配分区时的generation和成员ID提交offset
+//
+// 分区已在重平衡中重新分配时broker拒绝提交，不会覆盖新成员的进度。
+func (r *KafkaRepository) commitOffsets(ctx context.Context, generation int32, memberID string, offsets map[int32]int64) error {
+	req := kmsg.NewPtrOffsetCommitRequest()
+	req.Group = r.config.ConsumerGroup
+	req.Generation = generation
+	req.MemberID = memberID
+
+	topic := kmsg.NewOffsetCommitRequestTopic()
+	topic.Topic = r.kafkaConfig.Topic
+	for partition, offset := range offsets {
+		p := kmsg.NewOffsetCommitRequestTopicPartition()
+		p.Partition = partition
+		p.Offset = offset
+		topic.Partitions = append(topic.Partitions, p)
+	}
+	req.Topics = append(req.Topics, topic)
+
+	resp, err := req.RequestWith(ctx, r.client)
+	if err != nil {
+		return err
+	}
+	for _, t := range resp.Topics {
+		for _, p := range t.Partitions {
+			if err := kerr.ErrorForCode(p.ErrorCode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// partitionLag 消费者组在分区上的进度
+type partitionLag struct {
+	partition     int32
+	committed     int64 // 已提交的offset，没有提交过时为最早的offset
+	highWatermark int64
+}
+
+// partitionLags 查询消费者组在各分区上的进度
+func (r *KafkaRepository) partitionLags(ctx context.Context) ([]*partitionLag, error) {
+	topic := r.kafkaConfig.Topic
+	earliest, err := r.admin.ListStartOffsets(ctx, topic)
+	if err == nil {
+		err = earliest.Error()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partition offsets: %w", err)
+	}
+	latest, err := r.admin.ListEndOffsets(ctx, topic)
+	if err == nil {
+		err = latest.Error()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partition offsets: %w", err)
+	}
+
+	// 还没有成员加入过的消费者组不存在，视为没有提交过offset
+	committed, err := r.admin.FetchOffsets(ctx, r.config.ConsumerGroup)
+	if err == nil {
+		err = committed.Error()
+	}
+	if err != nil && !errors.Is(err, kerr.GroupIDNotFound) {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", err)
+	}
+
+	ids := slices.Sorted(maps.Keys(latest[topic]))
+	lags := make([]*partitionLag, 0, len(ids))
+	for _, id := range ids {
+		start, _ := earliest.Lookup(topic, id)
+		end, _ := latest.Lookup(topic, id)
+		offset := start.Offset
+		if c, ok := committed.Lookup(topic, id); ok && c.At > offset {
+			offset = c.At
+		}
+		lags = append(lags, &partitionLag{partition: id, committed: offset, highWatermark: end.Offset})
+	}
+	return lags, nil
+}
+
+// scanPending 按分区顺序遍历消费者组尚未提交的任务，visit返回false时停止
+func (r *KafkaRepository) scanPending(ctx context.Context, visit func(task *models.Task) bool) error {
+	lags, err := r.partitionLags(ctx)
+	if err != nil {
+		return err
+	}
+
+	reader, err := r.newPartitionReader(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, lag := range lags {
+		offset := lag.committed
+		for offset < lag.highWatermark {
+			messages, err := reader.fetch(ctx, lag.partition, offset)
+			if err != nil {
+				return err
+			}
+			if len(messages) == 0 {
+				break
+			}
+
+			for _, msg := range messages {
+				if msg.Offset >= lag.highWatermark {
+					break
+				}
+				offset = msg.Offset + 1
+
+				var task models.Task
+				if json.Unmarshal(msg.Value, &task) != nil {
+					continue
+				}
+				task.StreamID = kafkaStreamID(msg.Partition, msg.Offset)
+				if !visit(&task) {
+					return nil
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// partitionReader 不加入消费者组，直接从分区leader拉取指定offset开始的消息
+type partitionReader struct {
+	client  *kgo.Client
+	topic   string
+	topicID [16]byte // broker不支持topic ID时为全0
+	leaders map[int32]int32
+}
+
+// newPartitionReader 查询topic各分区当前的leader
+func (r *KafkaRepository) newPartitionReader(ctx context.Context) (*partitionReader, error) {
+	metadata, err := r.admin.Metadata(ctx, r.kafkaConfig.Topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic metadata: %w", err)
+	}
+	detail, ok := metadata.Topics[r.kafkaConfig.Topic]
+	if !ok {
+		return nil, fmt.Errorf("topic %s not found", r.kafkaConfig.Topic)
+	}
+	if detail.Err != nil {
+		return nil, fmt.Errorf("failed to get topic metadata: %w", detail.Err)
+	}
+
+	reader := &partitionReader{
+		client:  r.client,
+		topic:   r.kafkaConfig.Topic,
+		topicID: detail.ID,
+		leaders: make(map[int32]int32, len(detail.Partitions)),
+	}
+	for id, partition := range detail.Partitions {
+		reader.leaders[id] = partition.Leader
+	}
+	return reader, nil
+}
+
+// fetch 拉取分区中从offset开始的一批消息，不等待新消息写入
+func (p *partitionReader) fetch(ctx context.Context, partition int32, offset int64) ([]*kgo.Record, error) {
+	leader, ok := p.leaders[partition]
+	if !ok || leader < 0 {
+		return nil, fmt.Errorf("partition %d has no leader", partition)
+	}
+
+	req := kmsg.NewPtrFetchRequest()
+	req.MaxWaitMillis = 0
+	req.MaxBytes = kafkaFetchMaxBytes
+	topic := kmsg.NewFetchRequestTopic()
+	topic.Topic = p.topic
+	topic.TopicID = p.topicID
+	fetchPartition := kmsg.NewFetchRequestTopicPartition()
+	fetchPartition.Partition = partition
+	fetchPartition.FetchOffset = offset
+	fetchPartition.PartitionMaxBytes = kafkaFetchMaxBytes
+	topic.Partitions = append(topic.Partitions, fetchPartition)
+	req.Topics = append(req.Topics, topic)
+
+	resp, err := req.RequestWith(ctx, p.client.Broker(int(leader)))
+	if err != nil {
+		return nil, err
+	}
+	if err := kerr.ErrorForCode(resp.ErrorCode); err != nil {
+		return nil, err
+	}
+	if len(resp.Topics) == 0 || len(resp.Topics[0].Partitions) == 0 {
+		return nil, nil
+	}
+
+	// 按消息批次的压缩算法解压，跳过offset之前的消息
+	fetched, _ := kgo.ProcessFetchPartition(kgo.ProcessFetchPartitionOpts{
+		Topic:     p.topic,
+		Partition: partition,
+		Offset:    offset,
+	}, &resp.Topics[0].Partitions[0], kgo.DefaultDecompressor(), nil)
+	if fetched.Err != nil {
+		return nil, fetched.Err
+	}
+	return fetched.Records, nil
+}
+
+// kafkaStreamID 消息ID：分区-offset
+func kafkaStreamID(partition int32, offset int64) string {
+	return fmt.Sprintf("%d-%d", partition, offset)
+}
+
+// parseKafkaStreamID 解析消息ID
+func parseKafkaStreamID(streamID string) (int32, int64, error) {
+	partitionPart, offsetPart, ok := strings.Cut(streamID, "-")
+	partition, partitionErr := strconv.ParseInt(partitionPart, 10, 32)
+	offset, offsetErr := strconv.ParseInt(offsetPart, 10, 64)
+	if !ok || partitionErr != nil || offsetErr != nil {
+		return 0, 0, fmt.Errorf("invalid stream id: %s", streamID)
+	}
+	return int32(partition), offset, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseLeaseScript 原子地删除租约，需要重新入队时将任务放入延迟暂存区
+//
+// KEYS[1] 租约有序集合，KEYS[2] 租约任务哈希，KEYS[3] 延迟暂存区；
+// ARGV[1] 租约凭证，ARGV[2] 延迟暂存区成员（为空时不重新入队），ARGV[3] 可见时间毫秒时间戳。
+// 租约已被确认、放回或到期处理时返回0。
+var releaseLeaseScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HDEL', KEYS[2], ARGV[1])
+if ARGV[2] ~= '' then
+	redis.call('ZADD', KEYS[3], ARGV[3], ARGV[2])
+end
+return 1
+`)
+
+// extendLeaseScript 租约存在时更新可见时间
+//
+// KEYS[1] 租约有序集合；ARGV[1] 租约凭证，ARGV[2] 新的可见时间毫秒时间戳。
+var extendLeaseScript = redis.NewScript(`
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1
+`)
+
+// SaveLease 保存租约，到达VisibleAt前由ReleaseLease确认或放回，否则由ListExpiredLeases返回
+func (r *RedisRepository) SaveLease(ctx context.Context, lease *models.TaskLease) error {
+	data, err := json.Marshal(lease.Task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, r.leaseTasksKey(), lease.ReceiptHandle, data)
+		pipe.ZAdd(ctx, r.leasesKey(), redis.Z{
+			Score:  float64(lease.VisibleAt.UnixMilli()),
+			Member: lease.ReceiptHandle,
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save lease for task %s: %w", lease.Task.ID, err)
+	}
+	return nil
+}
+
+// GetLease 获取租约，不存在（已确认、放回或到期处理）时返回nil
+func (r *RedisRepository) GetLease(ctx context.Context, receipt string) (*models.TaskLease, error) {
+	visibleAt, err := r.client.ZScore(ctx, r.leasesKey(), receipt).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lease: %w", err)
+	}
+	data, err := r.client.HGet(ctx, r.leaseTasksKey(), receipt).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lease: %w", err)
+	}
+	return leaseFromData(receipt, data, visibleAt)
+}
+
+// ExtendLease 更新租约的可见时间，租约不存在时返回false
+func (r *RedisRepository) ExtendLease(ctx context.Context, receipt string, visibleAt time.Time) (bool, error) {
+	extended, err := extendLeaseScript.Run(ctx, r.client,
+		[]string{r.leasesKey()},
+		receipt, strconv.FormatInt(visibleAt.UnixMilli(), 10),
+	).Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to extend lease: %w", err)
+	}
+	return extended == 1, nil
+}
+
+// ReleaseLease 删除租约，requeue非nil时将其放入延迟暂存区，到达requeue.ScheduledAt后重新入队；租约不存在时返回false
+func (r *RedisRepository) ReleaseLease(ctx context.Context, receipt string, requeue *models.Task) (bool, error) {
+	member, score := "", "0"
+	if requeue != nil {
+		var err error
+		if member, err = delayedMember(requeue); err != nil {
+			return false, err
+		}
+		score = strconv.FormatInt(requeue.ScheduledAt.UnixMilli(), 10)
+	}
+
+	released, err := releaseLeaseScript.Run(ctx, r.client,
+		[]string{r.leasesKey(), r.leaseTasksKey(), r.delayedKey()},
+		receipt, member, score,
+	).Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to release lease: %w", err)
+	}
+	return released == 1, nil
+}
+
+// ListExpiredLeases 按可见时间升序列出最多limit个不晚于now到期的租约
+func (r *RedisRepository) ListExpiredLeases(ctx context.Context, now time.Time, limit int64) ([]*models.TaskLease, error) {
+	expired, err := r.client.ZRangeByScoreWithScores(ctx, r.leasesKey(), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired leases: %w", err)
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	receipts := make([]string, len(expired))
+	for i, z := range expired {
+		receipts[i] = z.Member.(string)
+	}
+	values, err := r.client.HMGet(ctx, r.leaseTasksKey(), receipts...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired leases: %w", err)
+	}
+
+	leases := make([]*models.TaskLease, 0, len(expired))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// 任务数据已被删除，租约无法恢复
+			r.client.ZRem(ctx, r.leasesKey(), receipts[i])
+			continue
+		}
+		lease, err := leaseFromData(receipts[i], []byte(data), expired[i].Score)
+		if err != nil {
+			continue
+		}
+		leases = append(leases, lease)
+	}
+	return leases, nil
+}
+
+// leaseFromData 由租约凭证、任务JSON和可见时间构造租约
+func leaseFromData(receipt string, data []byte, visibleAtMs float64) (*models.TaskLease, error) {
+	var task models.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal leased task: %w", err)
+	}
+	return &models.TaskLease{
+		ReceiptHandle: receipt,
+		Task:          &task,
+		VisibleAt:     time.UnixMilli(int64(visibleAtMs)),
+	}, nil
+}
+
+// leasesKey 租约有序集合的键，成员为租约凭证，分数为可见时间
+func (r *RedisRepository) leasesKey() string {
+	return r.config.StreamName + ":leases"
+}
+
+// leaseTasksKey 租约任务哈希的键，字段为租约凭证，值为任务JSON
+func (r *RedisRepository) leaseTasksKey() string {
+	return r.config.StreamName + ":leases:tasks"
+}
@@ -0,0 +1,917 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/services/queue/internal/config"
+	"mocks3/shared/models"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryRepository 进程内队列仓库，用于不依赖Redis的独立运行模式（如CI）
+//
+// 语义与RedisRepository一致：任务流按消费者组投递，未确认的消息保留在待确认列表中，
+// 延迟任务、死信队列和事件主题均保存在内存中。数据不持久化，也不在多个实例间共享；
+// 与Redis不同，已确认的任务会从任务流中移除。
+type MemoryRepository struct {
+	config *config.QueueConfig
+
+	mu          sync.Mutex
+	arrived     chan struct{} // 有新任务时关闭并替换，唤醒等待中的GetTasks
+	lastID      memoryID
+	stream      []*memoryMessage          // 已确认的消息会被移除
+	delivered   int                       // stream中已投递给消费者组的消息数
+	pending     map[string]*memoryMessage // 已投递未确认的消息
+	consumers   map[string]bool
+	delayed     []*memoryDelayed // 按可见时间升序
+	deadLetters [][]byte         // 最新的在前
+	topics      map[string][]*models.TopicEvent
+	jobs        map[string]*memoryJob
+	jobsSweep   time.Time // 上次清理过期快照的时间
+	schemas     map[string]models.TaskSchema
+	state       models.QueueState
+	leases      map[string]*memoryLease     // 按租约凭证
+	dedup       map[string]*memoryDedup     // 按payload哈希
+	dedupSweep  time.Time                   // 上次清理过期去重记录的时间
+	heartbeats  map[string]*memoryHeartbeat // 按消息ID
+}
+
+// memoryHeartbeat 处理中任务的心跳记录，data为任务JSON
+type memoryHeartbeat struct {
+	data []byte
+	at   time.Time
+}
+
+// memoryDedup payload哈希去重记录
+type memoryDedup struct {
+	taskID    string
+	expiresAt time.Time
+}
+
+// memoryJob 任务执行状态快照
+type memoryJob struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// memoryLease 外部消费者持有的租约，data为任务JSON
+type memoryLease struct {
+	data      []byte
+	visibleAt time.Time
+}
+
+// memoryMessage 任务流中的一条消息，data为任务JSON
+type memoryMessage struct {
+	id   string
+	data []byte
+}
+
+// memoryDelayed 延迟暂存区中的一项，member与Redis有序集合成员格式相同
+type memoryDelayed struct {
+	visibleAt time.Time
+	member    string
+}
+
+// memoryID 与Redis Stream相同格式的消息ID：毫秒时间戳-序号
+type memoryID struct {
+	ms  int64
+	seq int64
+}
+
+func (id memoryID) String() string {
+	return fmt.Sprintf("%d-%d", id.ms, id.seq)
+}
+
+func (id memoryID) less(other memoryID) bool {
+	return id.ms < other.ms || (id.ms == other.ms && id.seq < other.seq)
+}
+
+// parseMemoryID 解析消息ID，只有时间戳时序号为0
+func parseMemoryID(s string) (memoryID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+	ms, err := strconv.ParseInt(msPart, 10, 64)
+	if err != nil {
+		return memoryID{}, fmt.Errorf("invalid stream id: %s", s)
+	}
+	var seq int64
+	if hasSeq {
+		if seq, err = strconv.ParseInt(seqPart, 10, 64); err != nil {
+			return memoryID{}, fmt.Errorf("invalid stream id: %s", s)
+		}
+	}
+	return memoryID{ms: ms, seq: seq}, nil
+}
+
+var _ QueueRepository = (*MemoryRepository)(nil)
+
+// NewMemoryRepository 创建内存仓库
+func NewMemoryRepository(queueConfig *config.QueueConfig) *MemoryRepository {
+	return &MemoryRepository{
+		config:     queueConfig,
+		arrived:    make(chan struct{}),
+		pending:    make(map[string]*memoryMessage),
+		consumers:  make(map[string]bool),
+		topics:     make(map[string][]*models.TopicEvent),
+		jobs:       make(map[string]*memoryJob),
+		schemas:    make(map[string]models.TaskSchema),
+		leases:     make(map[string]*memoryLease),
+		dedup:      make(map[string]*memoryDedup),
+		heartbeats: make(map[string]*memoryHeartbeat),
+	}
+}
+
+// AddTask 添加任务到队列
+func (r *MemoryRepository) AddTask(ctx context.Context, task *models.Task) error {
+	taskData, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task.StreamID = r.appendLocked(taskData)
+	return nil
+}
+
+// AddTasks 批量添加任务到队列，返回与tasks一一对应的错误
+func (r *MemoryRepository) AddTasks(ctx context.Context, tasks []*models.Task) []error {
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		errs[i] = r.AddTask(ctx, task)
+	}
+	return errs
+}
+
+// appendLocked 追加消息到任务流并唤醒等待的消费者，调用方持有r.mu
+func (r *MemoryRepository) appendLocked(taskData []byte) string {
+	id := r.nextIDLocked()
+	r.stream = append(r.stream, &memoryMessage{id: id, data: taskData})
+	close(r.arrived)
+	r.arrived = make(chan struct{})
+	return id
+}
+
+// nextIDLocked 生成单调递增的消息ID，调用方持有r.mu
+func (r *MemoryRepository) nextIDLocked() string {
+	ms := time.Now().UnixMilli()
+	if ms > r.lastID.ms {
+		r.lastID = memoryID{ms: ms}
+	} else {
+		r.lastID.seq++
+	}
+	return r.lastID.String()
+}
+
+// GetTasks 获取待处理任务，没有新任务时最多等待ProcessTimeout
+func (r *MemoryRepository) GetTasks(ctx context.Context, consumerName string, count int64) ([]*models.Task, error) {
+	timer := time.NewTimer(time.Duration(r.config.ProcessTimeout) * time.Second)
+	defer timer.Stop()
+
+	for {
+		r.mu.Lock()
+		r.consumers[consumerName] = true
+		if r.delivered < len(r.stream) {
+			tasks := r.deliverLocked(count)
+			r.mu.Unlock()
+			return tasks, nil
+		}
+		arrived := r.arrived
+		r.mu.Unlock()
+
+		select {
+		case <-arrived:
+		case <-timer.C:
+			return []*models.Task{}, nil
+		case <-ctx.Done():
+			return []*models.Task{}, nil
+		}
+	}
+}
+
+// deliverLocked 投递最多count条未投递的消息，调用方持有r.mu
+func (r *MemoryRepository) deliverLocked(count int64) []*models.Task {
+	var tasks []*models.Task
+	for r.delivered < len(r.stream) && (count <= 0 || int64(len(tasks)) < count) {
+		msg := r.stream[r.delivered]
+		r.delivered++
+		r.pending[msg.id] = msg
+
+		var task models.Task
+		if json.Unmarshal(msg.data, &task) != nil {
+			continue
+		}
+		task.StreamID = msg.id
+		tasks = append(tasks, &task)
+	}
+	return tasks
+}
+
+// AckTask 确认任务完成，从任务流中移除消息
+func (r *MemoryRepository) AckTask(ctx context.Context, streamID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ackLocked(streamID)
+	return nil
+}
+
+// ackLocked 确认消息，消息不在待确认列表中时忽略，调用方持有r.mu
+func (r *MemoryRepository) ackLocked(streamID string) {
+	if _, ok := r.pending[streamID]; !ok {
+		return
+	}
+	delete(r.pending, streamID)
+	for i, msg := range r.stream[:r.delivered] {
+		if msg.id == streamID {
+			r.stream = append(r.stream[:i], r.stream[i+1:]...)
+			r.delivered--
+			return
+		}
+	}
+}
+
+// RetryTask 将失败的任务在retryAt重新入队并确认原消息，retryAt未晚于当前时间时立即入队
+func (r *MemoryRepository) RetryTask(ctx context.Context, task *models.Task, retryAt time.Time) error {
+	streamID := task.StreamID
+	now := time.Now()
+	task.Status = models.TaskStatusRetrying
+	task.UpdatedAt = now
+	task.ScheduledAt = retryAt
+
+	if !retryAt.After(now) {
+		taskData, err := json.Marshal(task)
+		if err != nil {
+			return fmt.Errorf("failed to marshal task: %w", err)
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		task.StreamID = r.appendLocked(taskData)
+		r.ackLocked(streamID)
+		return nil
+	}
+
+	member, err := delayedMember(task)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delayLocked(retryAt, member)
+	r.ackLocked(streamID)
+	task.StreamID = ""
+	return nil
+}
+
+// DeadLetterTask 将任务移入死信队列并确认原消息
+func (r *MemoryRepository) DeadLetterTask(ctx context.Context, task *models.Task) error {
+	taskData, err := deadLetterMember(task)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deadLetters = append([][]byte{taskData}, r.deadLetters...)
+	r.ackLocked(task.StreamID)
+	return nil
+}
+
+// GetTaskStatus 获取任务状态：依次查找任务流、延迟暂存区和死信队列
+func (r *MemoryRepository) GetTaskStatus(ctx context.Context, taskID string) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.stream) - 1; i >= 0; i-- {
+		task, err := r.stream[i].task()
+		if err == nil && task.ID == taskID && visibleToTenant(ctx, task) {
+			return task, nil
+		}
+	}
+
+	for _, item := range r.delayed {
+		task, err := scheduledTask(item.member)
+		if err == nil && task.ID == taskID && visibleToTenant(ctx, task) {
+			return task, nil
+		}
+	}
+
+	for _, task := range r.deadLetterTasksLocked(ctx, nil) {
+		if task.ID == taskID {
+			return task, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", models.ErrTaskNotFound, taskID)
+}
+
+// ListTasks 列出任务
+func (r *MemoryRepository) ListTasks(ctx context.Context, status string, limit int64) ([]*models.Task, error) {
+	switch status {
+	case "pending", "processing", "":
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		var tasks []*models.Task
+		for i := len(r.stream) - 1; i >= 0; i-- {
+			if limit > 0 && int64(len(tasks)) >= limit {
+				break
+			}
+			task, err := r.stream[i].task()
+			if err != nil || !visibleToTenant(ctx, task) {
+				continue
+			}
+			if status == "" || string(task.Status) == status {
+				tasks = append(tasks, task)
+			}
+		}
+		return tasks, nil
+
+	case string(models.TaskStatusScheduled):
+		return r.ListScheduledTasks(ctx, limit)
+
+	case "failed":
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		tasks := r.deadLetterTasksLocked(ctx, nil)
+		if limit > 0 && int64(len(tasks)) > limit {
+			tasks = tasks[:limit]
+		}
+		return tasks, nil
+	}
+
+	return nil, nil
+}
+
+// GetStats 获取队列统计信息
+func (r *MemoryRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := map[string]interface{}{
+		"pending_count":   int64(len(r.stream)),
+		"failed_count":    int64(len(r.deadLetters)),
+		"scheduled_count": int64(len(r.delayed)),
+		"consumer_group": map[string]interface{}{
+			"name":      r.config.ConsumerGroup,
+			"consumers": int64(len(r.consumers)),
+			"pending":   int64(len(r.pending)),
+		},
+	}
+
+	if tenant, ok := models.LookupTenant(ctx); ok {
+		var pending, scheduled int64
+		for _, msg := range r.stream {
+			if task, err := msg.task(); err == nil && task.TenantOrDefault() == tenant {
+				pending++
+			}
+		}
+		for _, item := range r.delayed {
+			if task, err := scheduledTask(item.member); err == nil && task.TenantOrDefault() == tenant {
+				scheduled++
+			}
+		}
+		stats["tenant"] = map[string]interface{}{
+			"name":            tenant,
+			"pending_count":   pending,
+			"failed_count":    int64(len(r.deadLetterTasksLocked(models.WithTenant(ctx, tenant), nil))),
+			"scheduled_count": scheduled,
+		}
+	}
+
+	stats["backend"] = config.BackendMemory
+	stats["stream_name"] = r.config.StreamName
+	stats["max_retries"] = r.config.Retry.MaxAttempts
+
+	return stats, nil
+}
+
+// SaveJob 保存任务执行状态的快照，每分钟最多清理一次已过期的快照
+func (r *MemoryRepository) SaveJob(ctx context.Context, task *models.Task) error {
+	taskData, err := jobSnapshot(task)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.jobsSweep) > time.Minute {
+		for id, job := range r.jobs {
+			if now.After(job.expiresAt) {
+				delete(r.jobs, id)
+			}
+		}
+		r.jobsSweep = now
+	}
+	r.jobs[task.ID] = &memoryJob{data: taskData, expiresAt: now.Add(r.config.GetJobTTL())}
+	return nil
+}
+
+// SaveJobs 保存多个任务的执行状态
+func (r *MemoryRepository) SaveJobs(ctx context.Context, tasks []*models.Task) error {
+	for _, task := range tasks {
+		if err := r.SaveJob(ctx, task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetJob 获取任务执行状态，不存在、已过期或对请求租户不可见时返回nil
+func (r *MemoryRepository) GetJob(ctx context.Context, taskID string) (*models.Task, error) {
+	r.mu.Lock()
+	job, ok := r.jobs[taskID]
+	r.mu.Unlock()
+	if !ok || time.Now().After(job.expiresAt) {
+		return nil, nil
+	}
+
+	var task models.Task
+	if err := json.Unmarshal(job.data, &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job %s: %w", taskID, err)
+	}
+	if !visibleToTenant(ctx, &task) {
+		return nil, nil
+	}
+	return &task, nil
+}
+
+// Backlog 任务流中尚未确认的任务数
+func (r *MemoryRepository) Backlog(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.stream)), nil
+}
+
+// StartHeartbeat 记录worker开始处理任务
+func (r *MemoryRepository) StartHeartbeat(ctx context.Context, task *models.Task, at time.Time) error {
+	taskData, err := jobSnapshot(task)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeats[task.StreamID] = &memoryHeartbeat{data: taskData, at: at}
+	return nil
+}
+
+// Heartbeat 更新任务的心跳时间，返回false表示任务已因心跳超时被取回
+func (r *MemoryRepository) Heartbeat(ctx context.Context, streamID string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	heartbeat, ok := r.heartbeats[streamID]
+	if !ok {
+		return false, nil
+	}
+	heartbeat.at = at
+	return true, nil
+}
+
+// ClearHeartbeat 删除任务的心跳记录
+func (r *MemoryRepository) ClearHeartbeat(ctx context.Context, streamID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.heartbeats, streamID)
+	return nil
+}
+
+// ClaimStaleHeartbeats 取出最近一次心跳早于before的任务并删除其心跳记录，按心跳时间升序
+func (r *MemoryRepository) ClaimStaleHeartbeats(ctx context.Context, before time.Time, limit int64) ([]*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stale []string
+	for streamID, heartbeat := range r.heartbeats {
+		if !heartbeat.at.After(before) {
+			stale = append(stale, streamID)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return r.heartbeats[stale[i]].at.Before(r.heartbeats[stale[j]].at) })
+	if limit > 0 && int64(len(stale)) > limit {
+		stale = stale[:limit]
+	}
+
+	tasks := make([]*models.Task, 0, len(stale))
+	for _, streamID := range stale {
+		var task models.Task
+		if err := json.Unmarshal(r.heartbeats[streamID].data, &task); err == nil {
+			task.StreamID = streamID
+			tasks = append(tasks, &task)
+		}
+		delete(r.heartbeats, streamID)
+	}
+	return tasks, nil
+}
+
+// SaveQueueState 保存队列运行状态
+func (r *MemoryRepository) SaveQueueState(ctx context.Context, state *models.QueueState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state = *state
+	return nil
+}
+
+// GetQueueState 获取队列运行状态
+func (r *MemoryRepository) GetQueueState(ctx context.Context) (*models.QueueState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.state
+	state.Name = r.config.Name
+	return &state, nil
+}
+
+// SaveLease 保存租约
+func (r *MemoryRepository) SaveLease(ctx context.Context, lease *models.TaskLease) error {
+	data, err := json.Marshal(lease.Task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.leases[lease.ReceiptHandle] = &memoryLease{data: data, visibleAt: lease.VisibleAt}
+	return nil
+}
+
+// GetLease 获取租约，不存在时返回nil
+func (r *MemoryRepository) GetLease(ctx context.Context, receipt string) (*models.TaskLease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lease, ok := r.leases[receipt]
+	if !ok {
+		return nil, nil
+	}
+	return leaseFromData(receipt, lease.data, float64(lease.visibleAt.UnixMilli()))
+}
+
+// ExtendLease 更新租约的可见时间，租约不存在时返回false
+func (r *MemoryRepository) ExtendLease(ctx context.Context, receipt string, visibleAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lease, ok := r.leases[receipt]
+	if !ok {
+		return false, nil
+	}
+	lease.visibleAt = visibleAt
+	return true, nil
+}
+
+// ReleaseLease 删除租约，requeue非nil时将其放入延迟暂存区；租约不存在时返回false
+func (r *MemoryRepository) ReleaseLease(ctx context.Context, receipt string, requeue *models.Task) (bool, error) {
+	var member string
+	if requeue != nil {
+		var err error
+		if member, err = delayedMember(requeue); err != nil {
+			return false, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.leases[receipt]; !ok {
+		return false, nil
+	}
+	delete(r.leases, receipt)
+	if requeue != nil {
+		r.delayLocked(requeue.ScheduledAt, member)
+	}
+	return true, nil
+}
+
+// ListExpiredLeases 按可见时间升序列出最多limit个不晚于now到期的租约
+func (r *MemoryRepository) ListExpiredLeases(ctx context.Context, now time.Time, limit int64) ([]*models.TaskLease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var leases []*models.TaskLease
+	for receipt, lease := range r.leases {
+		if lease.visibleAt.After(now) {
+			continue
+		}
+		if expired, err := leaseFromData(receipt, lease.data, float64(lease.visibleAt.UnixMilli())); err == nil {
+			leases = append(leases, expired)
+		}
+	}
+	sort.Slice(leases, func(i, j int) bool { return leases[i].VisibleAt.Before(leases[j].VisibleAt) })
+	if limit > 0 && int64(len(leases)) > limit {
+		leases = leases[:limit]
+	}
+	return leases, nil
+}
+
+// Depth 统计各状态的任务数，以及最早未确认任务的等待时间
+func (r *MemoryRepository) Depth(ctx context.Context) (*QueueDepth, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	depth := &QueueDepth{
+		Queue:      r.config.Name,
+		Ready:      int64(len(r.stream) - r.delivered),
+		InFlight:   int64(len(r.pending) + len(r.leases)),
+		Scheduled:  int64(len(r.delayed)),
+		DeadLetter: int64(len(r.deadLetters)),
+	}
+	if len(r.stream) > 0 {
+		if id, err := parseMemoryID(r.stream[0].id); err == nil {
+			depth.OldestAge = max(time.Since(time.UnixMilli(id.ms)), 0)
+		}
+	}
+	return depth, nil
+}
+
+// ClaimPayloadHash 记录payload哈希首次出现的任务，每分钟最多清理一次已过期的记录
+func (r *MemoryRepository) ClaimPayloadHash(ctx context.Context, hash, taskID string, window time.Duration) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.dedupSweep) > time.Minute {
+		for h, record := range r.dedup {
+			if now.After(record.expiresAt) {
+				delete(r.dedup, h)
+			}
+		}
+		r.dedupSweep = now
+	}
+	if record, ok := r.dedup[hash]; ok && !now.After(record.expiresAt) {
+		return record.taskID, nil
+	}
+	r.dedup[hash] = &memoryDedup{taskID: taskID, expiresAt: now.Add(window)}
+	return "", nil
+}
+
+// ReleasePayloadHash 删除任务的去重记录
+func (r *MemoryRepository) ReleasePayloadHash(ctx context.Context, hash, taskID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if record, ok := r.dedup[hash]; ok && record.taskID == taskID {
+		delete(r.dedup, hash)
+	}
+	return nil
+}
+
+// ScheduleTask 将任务放入延迟暂存区，到达task.ScheduledAt后才由PromoteDueTasks移入任务流对worker可见
+func (r *MemoryRepository) ScheduleTask(ctx context.Context, task *models.Task) error {
+	queued := *task
+	queued.Status = models.TaskStatusPending
+	member, err := delayedMember(&queued)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delayLocked(task.ScheduledAt, member)
+	return nil
+}
+
+// delayLocked 按可见时间插入延迟暂存区，调用方持有r.mu
+func (r *MemoryRepository) delayLocked(visibleAt time.Time, member string) {
+	i := sort.Search(len(r.delayed), func(i int) bool {
+		return r.delayed[i].visibleAt.After(visibleAt)
+	})
+	r.delayed = append(r.delayed, nil)
+	copy(r.delayed[i+1:], r.delayed[i:])
+	r.delayed[i] = &memoryDelayed{visibleAt: visibleAt, member: member}
+}
+
+// PromoteDueTasks 将到期（ScheduledAt不晚于now）的延迟任务移入任务流，返回移动的任务数
+func (r *MemoryRepository) PromoteDueTasks(ctx context.Context, now time.Time, limit int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var promoted int64
+	for len(r.delayed) > 0 && !r.delayed[0].visibleAt.After(now) && (limit <= 0 || promoted < limit) {
+		r.appendLocked([]byte(r.delayed[0].member))
+		r.delayed = r.delayed[1:]
+		promoted++
+	}
+	return promoted, nil
+}
+
+// ListScheduledTasks 按可见时间升序列出尚未到期的延迟任务
+func (r *MemoryRepository) ListScheduledTasks(ctx context.Context, limit int64) ([]*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := make([]*models.Task, 0)
+	for _, item := range r.delayed {
+		if limit > 0 && int64(len(tasks)) >= limit {
+			break
+		}
+		task, err := scheduledTask(item.member)
+		if err != nil || !visibleToTenant(ctx, task) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// deadLetterTasksLocked 对请求租户可见且符合过滤条件的死信任务（按进入时间倒序），调用方持有r.mu
+func (r *MemoryRepository) deadLetterTasksLocked(ctx context.Context, filter *models.DeadLetterFilter) []*models.Task {
+	tasks := make([]*models.Task, 0, len(r.deadLetters))
+	for _, data := range r.deadLetters {
+		var task models.Task
+		if json.Unmarshal(data, &task) != nil {
+			continue
+		}
+		if visibleToTenant(ctx, &task) && filter.Matches(&task) {
+			tasks = append(tasks, &task)
+		}
+	}
+	return tasks
+}
+
+// ListDeadLetters 分页列出死信任务
+func (r *MemoryRepository) ListDeadLetters(ctx context.Context, filter *models.DeadLetterFilter, limit, offset int) (*models.DeadLetterPage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := r.deadLetterTasksLocked(ctx, filter)
+	page := &models.DeadLetterPage{Tasks: make([]*models.Task, 0), Total: len(tasks), Offset: offset, Limit: limit}
+	for i := offset; i < len(tasks) && len(page.Tasks) < limit; i++ {
+		page.Tasks = append(page.Tasks, tasks[i])
+	}
+	return page, nil
+}
+
+// GetDeadLetter 获取死信任务，不存在时返回nil
+func (r *MemoryRepository) GetDeadLetter(ctx context.Context, taskID string) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, task := range r.deadLetterTasksLocked(ctx, nil) {
+		if task.ID == taskID {
+			return task, nil
+		}
+	}
+	return nil, nil
+}
+
+// RemoveDeadLetter 从死信队列移除任务，不存在时返回nil
+func (r *MemoryRepository) RemoveDeadLetter(ctx context.Context, taskID string) (*models.Task, error) {
+	removed := r.removeDeadLetters(ctx, func(task *models.Task) bool {
+		return task.ID == taskID
+	}, 1)
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	return removed[0], nil
+}
+
+// RemoveDeadLetters 移除符合过滤条件的死信任务，返回被移除的任务
+func (r *MemoryRepository) RemoveDeadLetters(ctx context.Context, filter *models.DeadLetterFilter) ([]*models.Task, error) {
+	return r.removeDeadLetters(ctx, filter.Matches, 0), nil
+}
+
+// removeDeadLetters 移除对请求租户可见且match的死信任务，limit为0时不限数量
+func (r *MemoryRepository) removeDeadLetters(ctx context.Context, match func(task *models.Task) bool, limit int) []*models.Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := make([]*models.Task, 0)
+	kept := r.deadLetters[:0]
+	for _, data := range r.deadLetters {
+		var task models.Task
+		if (limit == 0 || len(removed) < limit) && json.Unmarshal(data, &task) == nil &&
+			visibleToTenant(ctx, &task) && match(&task) {
+			removed = append(removed, &task)
+			continue
+		}
+		kept = append(kept, data)
+	}
+	r.deadLetters = kept
+	return removed
+}
+
+// RestoreDeadLetter 将任务放回死信队列（重新入队失败时使用）
+func (r *MemoryRepository) RestoreDeadLetter(ctx context.Context, task *models.Task) error {
+	taskData, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deadLetters = append([][]byte{taskData}, r.deadLetters...)
+	return nil
+}
+
+// PutTaskSchema 注册或替换任务类型的schema
+func (r *MemoryRepository) PutTaskSchema(ctx context.Context, schema *models.TaskSchema) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[schema.Type] = *schema
+	return nil
+}
+
+// GetTaskSchema 获取任务类型的schema，未注册时返回nil
+func (r *MemoryRepository) GetTaskSchema(ctx context.Context, taskType string) (*models.TaskSchema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	schema, ok := r.schemas[taskType]
+	if !ok {
+		return nil, nil
+	}
+	return &schema, nil
+}
+
+// DeleteTaskSchema 删除任务类型的schema，返回是否存在
+func (r *MemoryRepository) DeleteTaskSchema(ctx context.Context, taskType string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.schemas[taskType]
+	delete(r.schemas, taskType)
+	return ok, nil
+}
+
+// ListTaskSchemas 按任务类型排序列出所有schema
+func (r *MemoryRepository) ListTaskSchemas(ctx context.Context) ([]*models.TaskSchema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	schemas := make([]*models.TaskSchema, 0, len(r.schemas))
+	for _, schema := range r.schemas {
+		schema := schema
+		schemas = append(schemas, &schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Type < schemas[j].Type })
+	return schemas, nil
+}
+
+// PublishEvent 发布事件到主题，超过TopicMaxLen时丢弃最早的事件
+func (r *MemoryRepository) PublishEvent(ctx context.Context, topic, payload string) (*models.TopicEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event := &models.TopicEvent{
+		ID:          r.nextIDLocked(),
+		Topic:       topic,
+		Payload:     payload,
+		PublishedAt: time.Now(),
+	}
+	events := append(r.topics[topic], event)
+	if r.config.TopicMaxLen > 0 && int64(len(events)) > r.config.TopicMaxLen {
+		events = events[int64(len(events))-r.config.TopicMaxLen:]
+	}
+	r.topics[topic] = events
+	return event, nil
+}
+
+// ReadEvents 按顺序读取主题中afterID之后的事件
+func (r *MemoryRepository) ReadEvents(ctx context.Context, topic, afterID string, count int64) ([]*models.TopicEvent, error) {
+	var after memoryID
+	if afterID != "" {
+		var err error
+		if after, err = parseMemoryID(afterID); err != nil {
+			return nil, fmt.Errorf("failed to read events from topic %s: %w", topic, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := r.topics[topic]
+	start := 0
+	if afterID != "" {
+		start = sort.Search(len(events), func(i int) bool {
+			id, _ := parseMemoryID(events[i].ID)
+			return after.less(id)
+		})
+	}
+	end := len(events)
+	if count > 0 && int64(end-start) > count {
+		end = start + int(count)
+	}
+	return append([]*models.TopicEvent{}, events[start:end]...), nil
+}
+
+// GetTopicLength 获取主题中的事件数
+func (r *MemoryRepository) GetTopicLength(ctx context.Context, topic string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.topics[topic])), nil
+}
+
+// Close 内存仓库无需关闭连接
+func (r *MemoryRepository) Close() error {
+	return nil
+}
+
+// task 解析消息中的任务
+func (m *memoryMessage) task() (*models.Task, error) {
+	var task models.Task
+	if err := json.Unmarshal(m.data, &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task data: %w", err)
+	}
+	task.StreamID = m.id
+	return &task, nil
+}
@@ -0,0 +1,511 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mocks3/services/queue/internal/config"
+	"mocks3/shared/models"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATS拉取参数
+const (
+	natsFetchMaxWait = 5 * time.Second
+	// natsAckTimeout 等待服务器收到确认的时间
+	natsAckTimeout = 5 * time.Second
+	// natsMaxAckWait 不接管未确认任务（QUEUE_CLAIM_IDLE_MS为0）时的确认超时
+	natsMaxAckWait = 24 * time.Hour
+)
+
+// NatsRepository NATS JetStream队列仓库
+//
+// 所有队列共用一个workqueue保留策略的stream，每个队列对应其中一个subject，
+// 由名为<消费者组>_<队列名称>的持久拉取消费者分发给所有实例的worker，确认后消息从stream中删除，
+// 超过确认超时未确认的消息重新投递。延迟暂存区、死信队列和事件主题仍保存在Redis中。
+//
+// 投递的任务的StreamID为JetStream确认subject，任何实例都可以用它确认消息；
+// 入队和直接从stream读取的任务的StreamID为stream序号。
+type NatsRepository struct {
+	*RedisRepository
+
+	conn         *nats.Conn
+	js           jetstream.JetStream
+	stream       jetstream.Stream
+	consumer     jetstream.Consumer
+	config       *config.QueueConfig
+	natsConfig   *config.NatsConfig
+	consumerName string // 持久消费者名称
+}
+
+var _ QueueRepository = (*NatsRepository)(nil)
+
+// NewNatsRepository 创建NATS仓库，stream和持久消费者不存在时创建
+func NewNatsRepository(redisConfig *config.RedisConfig, queueConfig *config.QueueConfig, natsConfig *config.NatsConfig) (*NatsRepository, error) {
+	redisRepo, err := NewRedisRepository(redisConfig, queueConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// 连接断开后客户端自动重连，服务器地址支持tls://和带用户名密码的URL
+	conn, err := nats.Connect(strings.Join(natsConfig.Servers, ","),
+		nats.Name(natsConfig.ClientName),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		redisRepo.Close()
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		redisRepo.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	stream, err := ensureNatsStream(ctx, js, natsConfig)
+	if err != nil {
+		conn.Close()
+		redisRepo.Close()
+		return nil, fmt.Errorf("failed to create NATS stream %s: %w", natsConfig.Stream, err)
+	}
+
+	ackWait := queueConfig.GetClaimIdle()
+	if ackWait <= 0 {
+		ackWait = natsMaxAckWait
+	}
+	consumerName := queueConfig.ConsumerGroup + "_" + queueConfig.Name
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       consumerName,
+		FilterSubject: natsConfig.Subject,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       ackWait,
+		MaxDeliver:    -1, // 超过QUEUE_MAX_DELIVERIES的任务由GetTasks移入死信队列
+		MaxAckPending: -1,
+	})
+	if err != nil {
+		conn.Close()
+		redisRepo.Close()
+		return nil, fmt.Errorf("failed to create NATS consumer %s: %w", consumerName, err)
+	}
+
+	return &NatsRepository{
+		RedisRepository: redisRepo,
+		conn:            conn,
+		js:              js,
+		stream:          stream,
+		consumer:        consumer,
+		config:          queueConfig,
+		natsConfig:      natsConfig,
+		consumerName:    consumerName,
+	}, nil
+}
+
+// ensureNatsStream 创建stream，已存在时补充队列的subject
+//
+// stream包括所有队列的subject，每个队列只添加自己的subject。
+func ensureNatsStream(ctx context.Context, js jetstream.JetStream, natsConfig *config.NatsConfig) (jetstream.Stream, error) {
+	stream, err := js.Stream(ctx, natsConfig.Stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		return js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:      natsConfig.Stream,
+			Subjects:  []string{natsConfig.Subject},
+			Retention: jetstream.WorkQueuePolicy,
+			Storage:   jetstream.FileStorage,
+			Replicas:  natsConfig.Replicas,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	streamConfig := stream.CachedInfo().Config
+	if slices.Contains(streamConfig.Subjects, natsConfig.Subject) {
+		return stream, nil
+	}
+	streamConfig.Subjects = append(streamConfig.Subjects, natsConfig.Subject)
+	return js.UpdateStream(ctx, streamConfig)
+}
+
+// AddTask 发布任务到队列的subject
+func (r *NatsRepository) AddTask(ctx context.Context, task *models.Task) error {
+	taskData, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	ack, err := r.js.PublishMsg(ctx, &nats.Msg{
+		Subject: r.natsConfig.Subject,
+		Header: nats.Header{
+			"Mocks3-Task-Id":   {task.ID},
+			"Mocks3-Task-Type": {task.Type},
+			"Mocks3-Tenant":    {task.TenantOrDefault()},
+		},
+		Data: taskData,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add task to stream: %w", err)
+	}
+
+	task.StreamID = strconv.FormatUint(ack.Sequence, 10)
+	return nil
+}
+
+// AddTasks 逐条发布任务，返回与tasks一一对应的错误
+func (r *NatsRepository) AddTasks(ctx context.Context, tasks []*models.Task) []error {
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		errs[i] = r.AddTask(ctx, task)
+	}
+	return errs
+}
+
+// GetTasks 从持久消费者拉取任务，没有任务时最多等待natsFetchMaxWait
+//
+// 所有worker共用一个消费者，consumerName只用于接口兼容。被投递次数超过MaxDeliveries的任务移入死信队列。
+func (r *NatsRepository) GetTasks(ctx context.Context, consumerName string, count int64) ([]*models.Task, error) {
+	wait := natsFetchMaxWait
+	if deadline, ok := ctx.Deadline(); ok {
+		wait = max(min(wait, time.Until(deadline)-time.Second), 0)
+	}
+
+	msgs, err := r.fetch(int(count), wait)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from stream: %w", err)
+	}
+
+	tasks := []*models.Task{}
+	for _, msg := range msgs {
+		var task models.Task
+		if err := json.Unmarshal(msg.Data(), &task); err != nil {
+			// 无法解析的消息不再投递
+			msg.Term()
+			continue
+		}
+		task.StreamID = msg.Reply()
+
+		meta, err := msg.Metadata()
+		if err == nil && r.config.MaxDeliveries > 0 && meta.NumDelivered > uint64(r.config.MaxDeliveries) {
+			task.Error = fmt.Sprintf("exceeded max deliveries: delivered %d times without acknowledgement", meta.NumDelivered-1)
+			if err := r.DeadLetterTask(ctx, &task); err != nil {
+				return nil, err
+			}
+			// 任务执行状态保留worker崩溃前的running，更新为failed
+			if err := r.SaveJob(ctx, &task); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		tasks = append(tasks, &task)
+	}
+	return tasks, nil
+}
+
+// fetch 从持久消费者拉取最多batch条消息，没有消息时最多等待wait，收到第一条消息后立即返回
+//
+// 拉取请求在服务器上凑满batch条或到期才结束，因此先不等待地拉取已有的消息，没有时再等待一条。
+func (r *NatsRepository) fetch(batch int, wait time.Duration) ([]jetstream.Msg, error) {
+	msgs, err := natsBatchMessages(r.consumer.FetchNoWait(batch))
+	if err != nil || len(msgs) > 0 || wait <= 0 {
+		return msgs, err
+	}
+	return natsBatchMessages(r.consumer.Fetch(1, jetstream.FetchMaxWait(wait)))
+}
+
+// natsBatchMessages 接收拉取请求返回的所有消息
+func natsBatchMessages(batch jetstream.MessageBatch, err error) ([]jetstream.Msg, error) {
+	if err != nil {
+		return nil, err
+	}
+	var msgs []jetstream.Msg
+	for msg := range batch.Messages() {
+		msgs = append(msgs, msg)
+	}
+	return msgs, batch.Error()
+}
+
+// AckTask 确认任务，消息从stream中删除
+//
+// 投递的消息向确认subject发送+ACK，任何实例都可以确认；尚未投递的消息（StreamID为stream序号）直接删除。
+func (r *NatsRepository) AckTask(ctx context.Context, streamID string) error {
+	if strings.HasPrefix(streamID, "$JS.ACK.") {
+		if err := r.conn.Publish(streamID, []byte("+ACK")); err != nil {
+			return fmt.Errorf("failed to ack message %s: %w", streamID, err)
+		}
+		flushCtx, cancel := context.WithTimeout(ctx, natsAckTimeout)
+		defer cancel()
+		if err := r.conn.FlushWithContext(flushCtx); err != nil {
+			return fmt.Errorf("failed to ack message %s: %w", streamID, err)
+		}
+		return nil
+	}
+
+	seq, err := strconv.ParseUint(streamID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid stream id: %s", streamID)
+	}
+	if err := r.stream.DeleteMsg(ctx, seq); err != nil {
+		// 消息已被确认或删除时忽略
+		if _, getErr := r.stream.GetMsg(ctx, seq); errors.Is(getErr, jetstream.ErrMsgNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to ack message %s: %w", streamID, err)
+	}
+	return nil
+}
+
+// RetryTask 将失败的任务在retryAt重新入队并确认原消息，等待重试的任务放在Redis延迟暂存区中
+func (r *NatsRepository) RetryTask(ctx context.Context, task *models.Task, retryAt time.Time) error {
+	streamID := task.StreamID
+	now := time.Now()
+	task.Status = models.TaskStatusRetrying
+	task.UpdatedAt = now
+	task.ScheduledAt = retryAt
+
+	if !retryAt.After(now) {
+		if err := r.AddTask(ctx, task); err != nil {
+			return err
+		}
+		return r.AckTask(ctx, streamID)
+	}
+
+	if err := r.scheduleRetry(ctx, task, retryAt); err != nil {
+		return err
+	}
+	task.StreamID = ""
+	return r.AckTask(ctx, streamID)
+}
+
+// DeadLetterTask 将任务移入Redis死信队列并确认原消息
+func (r *NatsRepository) DeadLetterTask(ctx context.Context, task *models.Task) error {
+	taskData, err := deadLetterMember(task)
+	if err != nil {
+		return err
+	}
+	if err := r.RedisRepository.client.LPush(ctx, r.deadLetterKey(), taskData).Err(); err != nil {
+		return fmt.Errorf("failed to move task %s to dead letter queue: %w", task.ID, err)
+	}
+	return r.AckTask(ctx, task.StreamID)
+}
+
+// PromoteDueTasks 将到期的延迟任务发布到NATS，返回移动的任务数
+func (r *NatsRepository) PromoteDueTasks(ctx context.Context, now time.Time, limit int64) (int64, error) {
+	return r.promoteDueTasksTo(ctx, now, limit, r.AddTask)
+}
+
+// GetTaskStatus 获取任务状态：依次查找stream中未确认的消息、延迟暂存区和死信队列
+func (r *NatsRepository) GetTaskStatus(ctx context.Context, taskID string) (*models.Task, error) {
+	var found *models.Task
+	err := r.scanPending(ctx, func(task *models.Task) bool {
+		if task.ID == taskID && visibleToTenant(ctx, task) {
+			found = task
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found != nil {
+		return found, nil
+	}
+
+	if task, err := r.findScheduledTask(ctx, taskID); err != nil {
+		return nil, err
+	} else if task != nil {
+		return task, nil
+	}
+
+	if task, err := r.GetDeadLetter(ctx, taskID); err == nil && task != nil {
+		return task, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", models.ErrTaskNotFound, taskID)
+}
+
+// ListTasks 列出任务，pending为stream中尚未确认的消息
+func (r *NatsRepository) ListTasks(ctx context.Context, status string, limit int64) ([]*models.Task, error) {
+	switch status {
+	case "pending", "processing", "":
+		var tasks []*models.Task
+		err := r.scanPending(ctx, func(task *models.Task) bool {
+			if limit > 0 && int64(len(tasks)) >= limit {
+				return false
+			}
+			if visibleToTenant(ctx, task) && (status == "" || string(task.Status) == status) {
+				tasks = append(tasks, task)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pending tasks: %w", err)
+		}
+		return tasks, nil
+	default:
+		// 延迟任务和失败任务在Redis中
+		return r.RedisRepository.ListTasks(ctx, status, limit)
+	}
+}
+
+// GetStats 获取队列统计信息，pending_count为持久消费者尚未投递和已投递未确认的消息数之和
+func (r *NatsRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	info, err := r.consumer.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer info: %w", err)
+	}
+
+	stats := make(map[string]interface{})
+	stats["pending_count"] = int64(info.NumPending) + int64(info.NumAckPending)
+
+	failedCount, err := r.RedisRepository.client.LLen(ctx, r.deadLetterKey()).Result()
+	if err == nil {
+		stats["failed_count"] = failedCount
+	}
+
+	scheduledCount, err := r.scheduledTaskCount(ctx, "")
+	if err == nil {
+		stats["scheduled_count"] = scheduledCount
+	}
+
+	stats["consumer"] = map[string]interface{}{
+		"name":            r.consumerName,
+		"subject":         r.natsConfig.Subject,
+		"num_pending":     info.NumPending,
+		"num_ack_pending": info.NumAckPending,
+		"num_redelivered": info.NumRedelivered,
+		"num_waiting":     info.NumWaiting,
+	}
+
+	if tenant, ok := models.LookupTenant(ctx); ok {
+		tenantStats, err := r.tenantTaskCounts(ctx, tenant)
+		if err != nil {
+			return nil, err
+		}
+		stats["tenant"] = tenantStats
+	}
+
+	stats["backend"] = config.BackendNats
+	stats["stream_name"] = r.natsConfig.Stream
+	stats["max_retries"] = r.config.Retry.MaxAttempts
+
+	return stats, nil
+}
+
+// Backlog 持久消费者尚未投递和已投递未确认的消息数之和
+func (r *NatsRepository) Backlog(ctx context.Context) (int64, error) {
+	info, err := r.consumer.Info(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get consumer info: %w", err)
+	}
+	return int64(info.NumPending) + int64(info.NumAckPending), nil
+}
+
+// Depth 统计各状态的任务数，以及队列subject中第一条消息的写入时间
+//
+// workqueue保留策略下已确认的消息被删除，subject中的第一条消息即最早的未确认任务。
+func (r *NatsRepository) Depth(ctx context.Context) (*QueueDepth, error) {
+	info, err := r.consumer.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer info: %w", err)
+	}
+
+	depth := &QueueDepth{
+		Queue:    r.config.Name,
+		Ready:    int64(info.NumPending),
+		InFlight: int64(info.NumAckPending),
+	}
+	if info.NumPending > 0 || info.NumAckPending > 0 {
+		msg, err := r.stream.GetMsg(ctx, 1, jetstream.WithGetMsgSubject(r.natsConfig.Subject))
+		if err != nil && !errors.Is(err, jetstream.ErrMsgNotFound) {
+			return nil, fmt.Errorf("failed to get oldest message: %w", err)
+		}
+		if msg != nil {
+			depth.OldestAge = max(time.Since(msg.Time), 0)
+		}
+	}
+
+	if err := r.stagedDepth(ctx, depth); err != nil {
+		return nil, err
+	}
+	return depth, nil
+}
+
+// tenantTaskCounts 统计租户在stream、死信队列和延迟暂存区中的任务数
+func (r *NatsRepository) tenantTaskCounts(ctx context.Context, tenant string) (map[string]interface{}, error) {
+	var pending int64
+	err := r.scanPending(ctx, func(task *models.Task) bool {
+		if task.TenantOrDefault() == tenant {
+			pending++
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant tasks: %w", err)
+	}
+
+	failed, err := r.deadLetterEntries(models.WithTenant(ctx, tenant), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant failed tasks: %w", err)
+	}
+
+	scheduled, err := r.scheduledTaskCount(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant scheduled tasks: %w", err)
+	}
+
+	return map[string]interface{}{
+		"name":            tenant,
+		"pending_count":   pending,
+		"failed_count":    int64(len(failed)),
+		"scheduled_count": scheduled,
+	}, nil
+}
+
+// Close 关闭NATS和Redis连接，持久消费者保留在服务器上
+func (r *NatsRepository) Close() error {
+	r.conn.Close()
+	return r.RedisRepository.Close()
+}
+
+// scanPending 按序号遍历队列subject中尚未确认的任务，visit返回false时停止
+func (r *NatsRepository) scanPending(ctx context.Context, visit func(task *models.Task) bool) error {
+	info, err := r.stream.Info(ctx)
+	if err != nil {
+		return err
+	}
+	if info.State.Msgs == 0 {
+		return nil
+	}
+
+	for seq := max(info.State.FirstSeq, 1); seq <= info.State.LastSeq; {
+		msg, err := r.stream.GetMsg(ctx, seq, jetstream.WithGetMsgSubject(r.natsConfig.Subject))
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		seq = msg.Sequence + 1
+
+		var task models.Task
+		if json.Unmarshal(msg.Data, &task) != nil {
+			continue
+		}
+		task.StreamID = strconv.FormatUint(msg.Sequence, 10)
+		if !visit(&task) {
+			return nil
+		}
+	}
+	return nil
+}
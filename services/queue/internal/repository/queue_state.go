@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SaveQueueState 保存队列运行状态，恢复运行时删除保存的状态
+func (r *RedisRepository) SaveQueueState(ctx context.Context, state *models.QueueState) error {
+	if !state.Paused {
+		if err := r.client.Del(ctx, r.queueStateKey()).Err(); err != nil {
+			return fmt.Errorf("failed to clear queue state: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal queue state: %w", err)
+	}
+	if err := r.client.Set(ctx, r.queueStateKey(), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save queue state: %w", err)
+	}
+	return nil
+}
+
+// GetQueueState 获取队列运行状态，没有保存的状态时队列处于运行中
+func (r *RedisRepository) GetQueueState(ctx context.Context) (*models.QueueState, error) {
+	data, err := r.client.Get(ctx, r.queueStateKey()).Bytes()
+	if err == redis.Nil {
+		return &models.QueueState{Name: r.config.Name}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue state: %w", err)
+	}
+
+	var state models.QueueState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal queue state: %w", err)
+	}
+	state.Name = r.config.Name
+	return &state, nil
+}
+
+// queueStateKey 队列运行状态的键
+func (r *RedisRepository) queueStateKey() string {
+	return r.config.StreamName + ":state"
+}
@@ -7,6 +7,9 @@ import (
 	"mocks3/services/queue/internal/config"
 	"mocks3/shared/models"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -16,6 +19,10 @@ import (
 type RedisRepository struct {
 	client *redis.Client
 	config *config.QueueConfig
+
+	groupReady atomic.Bool // 消费者组已创建
+	claimMu    sync.Mutex
+	nextClaim  time.Time // 下次接管超时未确认消息的时间
 }
 
 // NewRedisRepository 创建Redis仓库
@@ -42,43 +49,94 @@ func NewRedisRepository(redisConfig *config.RedisConfig, queueConfig *config.Que
 
 // AddTask 添加任务到队列
 func (r *RedisRepository) AddTask(ctx context.Context, task *models.Task) error {
+	args, err := r.taskMessage(task)
+	if err != nil {
+		return err
+	}
+
+	msgID, err := r.client.XAdd(ctx, args).Result()
+	if err != nil {
+		return fmt.Errorf("failed to add task to stream: %w", err)
+	}
+
+	task.StreamID = msgID
+	return nil
+}
+
+// AddTasks 用一次pipeline批量添加任务到队列，返回与tasks一一对应的错误
+func (r *RedisRepository) AddTasks(ctx context.Context, tasks []*models.Task) []error {
+	errs := make([]error, len(tasks))
+	cmds := make([]*redis.StringCmd, len(tasks))
+
+	pipe := r.client.Pipeline()
+	for i, task := range tasks {
+		args, err := r.taskMessage(task)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		cmds[i] = pipe.XAdd(ctx, args)
+	}
+	// 每条命令的结果单独检查，Exec返回的第一个错误不代表整批失败
+	pipe.Exec(ctx)
+
+	for i, cmd := range cmds {
+		if cmd == nil {
+			continue
+		}
+		msgID, err := cmd.Result()
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to add task to stream: %w", err)
+			continue
+		}
+		tasks[i].StreamID = msgID
+	}
+	return errs
+}
+
+// taskMessage 任务流消息
+func (r *RedisRepository) taskMessage(task *models.Task) (*redis.XAddArgs, error) {
 	taskData, err := json.Marshal(task)
 	if err != nil {
-		return fmt.Errorf("failed to marshal task: %w", err)
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
 	}
 
-	args := &redis.XAddArgs{
+	return &redis.XAddArgs{
 		Stream: r.config.StreamName,
 		Values: map[string]interface{}{
 			"task_id":    task.ID,
 			"task_type":  task.Type,
+			"tenant":     task.TenantOrDefault(),
 			"priority":   task.Priority,
 			"data":       string(taskData),
 			"created_at": task.CreatedAt.Format(time.RFC3339),
 		},
-	}
-
-	msgID, err := r.client.XAdd(ctx, args).Result()
-	if err != nil {
-		return fmt.Errorf("failed to add task to stream: %w", err)
-	}
-
-	task.StreamID = msgID
-	return nil
+	}, nil
 }
 
-// GetTasks 获取待处理任务
+// GetTasks 以消费者组成员身份读取任务，读取的消息在AckTask前保留在待确认列表中
+//
+// 优先接管其他消费者超时未确认的消息（持有消息的worker已崩溃），没有时再读取新消息。
 func (r *RedisRepository) GetTasks(ctx context.Context, consumerName string, count int64) ([]*models.Task, error) {
 	// 创建消费者组（如果不存在）
 	err := r.ensureConsumerGroup(ctx)
 	if err != nil {
 		return nil, err
 	}
+	consumer := r.consumerName(consumerName)
+
+	claimed, err := r.claimStaleTasks(ctx, consumer, count)
+	if err != nil {
+		return nil, err
+	}
+	if len(claimed) > 0 {
+		return claimed, nil
+	}
 
 	// 读取消息
 	streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
 		Group:    r.config.ConsumerGroup,
-		Consumer: consumerName,
+		Consumer: consumer,
 		Streams:  []string{r.config.StreamName, ">"},
 		Count:    count,
 		Block:    time.Duration(r.config.ProcessTimeout) * time.Second,
@@ -88,25 +146,41 @@ func (r *RedisRepository) GetTasks(ctx context.Context, consumerName string, cou
 		if err == redis.Nil {
 			return []*models.Task{}, nil
 		}
+		// 流或消费者组被删除后重新创建
+		if strings.HasPrefix(err.Error(), "NOGROUP") {
+			r.groupReady.Store(false)
+		}
 		return nil, fmt.Errorf("failed to read from stream: %w", err)
 	}
 
 	var tasks []*models.Task
 	for _, stream := range streams {
-		for _, msg := range stream.Messages {
-			task, err := r.messageToTask(msg)
-			if err != nil {
-				// 记录错误但继续处理其他消息
-				continue
-			}
-			task.StreamID = msg.ID
-			tasks = append(tasks, task)
-		}
+		tasks = append(tasks, r.messagesToTasks(ctx, stream.Messages)...)
 	}
 
 	return tasks, nil
 }
 
+// messagesToTasks 解析读取到的消息，无法解析的消息直接确认，避免一直留在待确认列表中被反复接管
+func (r *RedisRepository) messagesToTasks(ctx context.Context, messages []redis.XMessage) []*models.Task {
+	tasks := make([]*models.Task, 0, len(messages))
+	for _, msg := range messages {
+		task, err := r.messageToTask(msg)
+		if err != nil {
+			r.client.XAck(ctx, r.config.StreamName, r.config.ConsumerGroup, msg.ID)
+			continue
+		}
+		task.StreamID = msg.ID
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// consumerName 消费者组中的消费者名：实例名:worker ID，多个实例的同名worker互不影响
+func (r *RedisRepository) consumerName(workerID string) string {
+	return r.config.ConsumerName + ":" + workerID
+}
+
 // AckTask 确认任务完成
 func (r *RedisRepository) AckTask(ctx context.Context, streamID string) error {
 	err := r.client.XAck(ctx, r.config.StreamName, r.config.ConsumerGroup, streamID).Err()
@@ -116,29 +190,42 @@ func (r *RedisRepository) AckTask(ctx context.Context, streamID string) error {
 	return nil
 }
 
-// RejectTask 拒绝任务（重新入队）
-func (r *RedisRepository) RejectTask(ctx context.Context, task *models.Task) error {
-	// 增加重试次数
-	task.RetryCount++
-
-	if task.RetryCount >= r.config.MaxRetries {
-		// 超过最大重试次数，标记为失败
-		task.Status = models.TaskStatusFailed
-		task.UpdatedAt = time.Now()
-
-		// 记录到失败队列（可选）
-		failedData, _ := json.Marshal(task)
-		r.client.LPush(ctx, r.config.StreamName+":failed", failedData)
+// RetryTask 将失败的任务在retryAt重新入队并确认原消息，retryAt未晚于当前时间时立即入队
+//
+// 等待重试的任务放在延迟暂存区中，状态为retrying，由调度器到期后移入任务流。
+func (r *RedisRepository) RetryTask(ctx context.Context, task *models.Task, retryAt time.Time) error {
+	streamID := task.StreamID
+	now := time.Now()
+	task.Status = models.TaskStatusRetrying
+	task.UpdatedAt = now
+	task.ScheduledAt = retryAt
 
-		// 确认原消息
-		return r.AckTask(ctx, task.StreamID)
+	if !retryAt.After(now) {
+		// 重新添加到队列后确认原消息，避免原消息一直留在消费者组的待确认列表中
+		if err := r.AddTask(ctx, task); err != nil {
+			return err
+		}
+		return r.AckTask(ctx, streamID)
 	}
 
-	// 重新添加到队列
-	task.Status = models.TaskStatusRetrying
-	task.UpdatedAt = time.Now()
+	member, err := delayedMember(task)
+	if err != nil {
+		return err
+	}
 
-	return r.AddTask(ctx, task)
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, r.delayedKey(), redis.Z{
+			Score:  float64(retryAt.UnixMilli()),
+			Member: member,
+		})
+		pipe.XAck(ctx, r.config.StreamName, r.config.ConsumerGroup, streamID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule retry for task %s: %w", task.ID, err)
+	}
+	task.StreamID = ""
+	return nil
 }
 
 // GetTaskStatus 获取任务状态
@@ -152,23 +239,37 @@ func (r *RedisRepository) GetTaskStatus(ctx context.Context, taskID string) (*mo
 	for _, msg := range result {
 		if taskIDValue, exists := msg.Values["task_id"]; exists {
 			if taskIDValue == taskID {
-				return r.messageToTask(msg)
+				task, err := r.messageToTask(msg)
+				if err != nil {
+					return nil, err
+				}
+				if !visibleToTenant(ctx, task) {
+					break
+				}
+				return task, nil
 			}
 		}
 	}
 
+	// 从延迟暂存区查找
+	if task, err := r.findScheduledTask(ctx, taskID); err != nil {
+		return nil, err
+	} else if task != nil {
+		return task, nil
+	}
+
 	// 从失败队列查找
-	failedTasks, err := r.client.LRange(ctx, r.config.StreamName+":failed", 0, -1).Result()
+	failedTasks, err := r.client.LRange(ctx, r.deadLetterKey(), 0, -1).Result()
 	if err == nil {
 		for _, taskData := range failedTasks {
 			var task models.Task
-			if json.Unmarshal([]byte(taskData), &task) == nil && task.ID == taskID {
+			if json.Unmarshal([]byte(taskData), &task) == nil && task.ID == taskID && visibleToTenant(ctx, &task) {
 				return &task, nil
 			}
 		}
 	}
 
-	return nil, fmt.Errorf("task not found: %s", taskID)
+	return nil, fmt.Errorf("%w: %s", models.ErrTaskNotFound, taskID)
 }
 
 // ListTasks 列出任务
@@ -194,6 +295,10 @@ func (r *RedisRepository) ListTasks(ctx context.Context, status string, limit in
 				continue
 			}
 
+			if !visibleToTenant(ctx, task) {
+				continue
+			}
+
 			if status == "" || string(task.Status) == status {
 				task.StreamID = msg.ID
 				tasks = append(tasks, task)
@@ -201,16 +306,27 @@ func (r *RedisRepository) ListTasks(ctx context.Context, status string, limit in
 			}
 		}
 
+	case string(models.TaskStatusScheduled):
+		// 从延迟暂存区获取，按可见时间升序
+		return r.ListScheduledTasks(ctx, limit)
+
 	case "failed":
-		// 从失败队列获取
-		failedTasks, err := r.client.LRange(ctx, r.config.StreamName+":failed", 0, limit-1).Result()
+		// 从失败队列获取，按租户过滤时需要扫描整个列表
+		stop := limit - 1
+		if _, ok := models.LookupTenant(ctx); ok {
+			stop = -1
+		}
+		failedTasks, err := r.client.LRange(ctx, r.deadLetterKey(), 0, stop).Result()
 		if err != nil {
 			return nil, fmt.Errorf("failed to list failed tasks: %w", err)
 		}
 
 		for _, taskData := range failedTasks {
+			if limit > 0 && int64(len(tasks)) >= limit {
+				break
+			}
 			var task models.Task
-			if json.Unmarshal([]byte(taskData), &task) == nil {
+			if json.Unmarshal([]byte(taskData), &task) == nil && visibleToTenant(ctx, &task) {
 				tasks = append(tasks, &task)
 			}
 		}
@@ -230,11 +346,17 @@ func (r *RedisRepository) GetStats(ctx context.Context) (map[string]interface{},
 	}
 
 	// 失败任务数
-	failedCount, err := r.client.LLen(ctx, r.config.StreamName+":failed").Result()
+	failedCount, err := r.client.LLen(ctx, r.deadLetterKey()).Result()
 	if err == nil {
 		stats["failed_count"] = failedCount
 	}
 
+	// 尚未到期的延迟任务数
+	scheduledCount, err := r.scheduledTaskCount(ctx, "")
+	if err == nil {
+		stats["scheduled_count"] = scheduledCount
+	}
+
 	// 消费者组信息
 	groups, err := r.client.XInfoGroups(ctx, r.config.StreamName).Result()
 	if err == nil {
@@ -250,44 +372,181 @@ func (r *RedisRepository) GetStats(ctx context.Context) (map[string]interface{},
 		}
 	}
 
+	// 请求指定租户时附加该租户的任务数
+	if tenant, ok := models.LookupTenant(ctx); ok {
+		tenantStats, err := r.tenantTaskCounts(ctx, tenant)
+		if err != nil {
+			return nil, err
+		}
+		stats["tenant"] = tenantStats
+	}
+
 	stats["stream_name"] = r.config.StreamName
-	stats["max_retries"] = r.config.MaxRetries
+	stats["max_retries"] = r.config.Retry.MaxAttempts
 
 	return stats, nil
 }
 
-// Close 关闭连接
-func (r *RedisRepository) Close() error {
-	return r.client.Close()
-}
-
-// ensureConsumerGroup 确保消费者组存在
-func (r *RedisRepository) ensureConsumerGroup(ctx context.Context) error {
-	// 检查消费者组是否存在
+// Backlog 消费者组尚未读取的消息数（lag）与待确认的消息数之和
+func (r *RedisRepository) Backlog(ctx context.Context) (int64, error) {
 	groups, err := r.client.XInfoGroups(ctx, r.config.StreamName).Result()
 	if err != nil {
-		// 如果stream不存在，先创建一个空的消息
 		if err.Error() == "ERR no such key" {
-			r.client.XAdd(ctx, &redis.XAddArgs{
-				Stream: r.config.StreamName,
-				Values: map[string]interface{}{"init": "true"},
-			})
+			return 0, nil
 		}
+		return 0, fmt.Errorf("failed to get consumer group info: %w", err)
 	}
 
-	// 检查消费者组是否已存在
 	for _, group := range groups {
-		if group.Name == r.config.ConsumerGroup {
-			return nil
+		if group.Name != r.config.ConsumerGroup {
+			continue
+		}
+		// 无法确定lag（Redis 7以下或流中有删除的消息）时只统计待确认的消息
+		backlog := group.Pending
+		if group.Lag > 0 {
+			backlog += group.Lag
+		}
+		return backlog, nil
+	}
+
+	// 消费者组尚未创建，流中的消息都未被读取
+	return r.client.XLen(ctx, r.config.StreamName).Result()
+}
+
+// tenantTaskCounts 统计租户在主队列、失败队列和延迟暂存区中的任务数
+func (r *RedisRepository) tenantTaskCounts(ctx context.Context, tenant string) (map[string]interface{}, error) {
+	messages, err := r.client.XRange(ctx, r.config.StreamName, "-", "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant tasks: %w", err)
+	}
+
+	var pending int64
+	for _, msg := range messages {
+		task, err := r.messageToTask(msg)
+		if err == nil && task.TenantOrDefault() == tenant {
+			pending++
+		}
+	}
+
+	failedTasks, err := r.client.LRange(ctx, r.deadLetterKey(), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant failed tasks: %w", err)
+	}
+
+	var failed int64
+	for _, taskData := range failedTasks {
+		var task models.Task
+		if json.Unmarshal([]byte(taskData), &task) == nil && task.TenantOrDefault() == tenant {
+			failed++
 		}
 	}
 
-	// 创建消费者组
-	err = r.client.XGroupCreate(ctx, r.config.StreamName, r.config.ConsumerGroup, "0").Err()
-	if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
+	scheduled, err := r.scheduledTaskCount(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant scheduled tasks: %w", err)
+	}
+
+	return map[string]interface{}{
+		"name":            tenant,
+		"pending_count":   pending,
+		"failed_count":    failed,
+		"scheduled_count": scheduled,
+	}, nil
+}
+
+// visibleToTenant 检查任务对请求租户是否可见，请求未指定租户时（未启用多租户）可见所有任务
+func visibleToTenant(ctx context.Context, task *models.Task) bool {
+	tenant, ok := models.LookupTenant(ctx)
+	return !ok || task.TenantOrDefault() == tenant
+}
+
+// PublishEvent 发布事件到主题，payload按原样写入不做校验
+func (r *RedisRepository) PublishEvent(ctx context.Context, topic, payload string) (*models.TopicEvent, error) {
+	publishedAt := time.Now()
+
+	args := &redis.XAddArgs{
+		Stream: r.topicStream(topic),
+		Values: map[string]interface{}{
+			"payload":      payload,
+			"published_at": publishedAt.Format(time.RFC3339Nano),
+		},
+	}
+	if r.config.TopicMaxLen > 0 {
+		args.MaxLen = r.config.TopicMaxLen
+		args.Approx = true
+	}
+
+	msgID, err := r.client.XAdd(ctx, args).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish event to topic %s: %w", topic, err)
+	}
+
+	return &models.TopicEvent{
+		ID:          msgID,
+		Topic:       topic,
+		Payload:     payload,
+		PublishedAt: publishedAt,
+	}, nil
+}
+
+// ReadEvents 按顺序读取主题中afterID之后的事件（只读，不创建消费者组、不确认消息）
+func (r *RedisRepository) ReadEvents(ctx context.Context, topic, afterID string, count int64) ([]*models.TopicEvent, error) {
+	start := "-"
+	if afterID != "" {
+		start = "(" + afterID
+	}
+
+	messages, err := r.client.XRangeN(ctx, r.topicStream(topic), start, "+", count).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events from topic %s: %w", topic, err)
+	}
+
+	events := make([]*models.TopicEvent, 0, len(messages))
+	for _, msg := range messages {
+		event := &models.TopicEvent{ID: msg.ID, Topic: topic}
+		if payload, ok := msg.Values["payload"].(string); ok {
+			event.Payload = payload
+		}
+		if publishedAt, ok := msg.Values["published_at"].(string); ok {
+			event.PublishedAt, _ = time.Parse(time.RFC3339Nano, publishedAt)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// GetTopicLength 获取主题中的事件数
+func (r *RedisRepository) GetTopicLength(ctx context.Context, topic string) (int64, error) {
+	length, err := r.client.XLen(ctx, r.topicStream(topic)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get topic length: %w", err)
+	}
+	return length, nil
+}
+
+// topicStream 获取主题对应的流名称
+func (r *RedisRepository) topicStream(topic string) string {
+	return r.config.TopicPrefix + topic
+}
+
+// Close 关闭连接
+func (r *RedisRepository) Close() error {
+	return r.client.Close()
+}
+
+// ensureConsumerGroup 确保消费者组存在，流不存在时一并创建，从流的第一条消息开始投递
+func (r *RedisRepository) ensureConsumerGroup(ctx context.Context) error {
+	if r.groupReady.Load() {
+		return nil
+	}
+
+	err := r.client.XGroupCreateMkStream(ctx, r.config.StreamName, r.config.ConsumerGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
 		return fmt.Errorf("failed to create consumer group: %w", err)
 	}
 
+	r.groupReady.Store(true)
 	return nil
 }
 
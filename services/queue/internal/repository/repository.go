@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"mocks3/shared/models"
+	"time"
+)
+
+// QueueRepository 队列存储后端
+//
+// 任务流的消息ID（Task.StreamID）由后端生成，只用于AckTask/RetryTask/DeadLetterTask确认原消息。
+type QueueRepository interface {
+	// 任务流
+	AddTask(ctx context.Context, task *models.Task) error
+	AddTasks(ctx context.Context, tasks []*models.Task) []error // 批量入队，返回与tasks一一对应的错误
+	GetTasks(ctx context.Context, consumerName string, count int64) ([]*models.Task, error)
+	AckTask(ctx context.Context, streamID string) error
+	RetryTask(ctx context.Context, task *models.Task, retryAt time.Time) error
+	DeadLetterTask(ctx context.Context, task *models.Task) error
+	GetTaskStatus(ctx context.Context, taskID string) (*models.Task, error)
+	ListTasks(ctx context.Context, status string, limit int64) ([]*models.Task, error)
+	GetStats(ctx context.Context) (map[string]interface{}, error)
+	Backlog(ctx context.Context) (int64, error) // 尚未投递和已投递未确认的任务数
+	Depth(ctx context.Context) (*QueueDepth, error)
+
+	// worker心跳：处理中的任务按消息ID记录最近一次心跳，超时未更新的任务由ClaimStaleHeartbeats取回后重新入队
+	StartHeartbeat(ctx context.Context, task *models.Task, at time.Time) error
+	Heartbeat(ctx context.Context, streamID string, at time.Time) (bool, error)
+	ClearHeartbeat(ctx context.Context, streamID string) error
+	ClaimStaleHeartbeats(ctx context.Context, before time.Time, limit int64) ([]*models.Task, error)
+
+	// 队列运行状态，所有实例共享
+	SaveQueueState(ctx context.Context, state *models.QueueState) error
+	GetQueueState(ctx context.Context) (*models.QueueState, error)
+
+	// 外部消费者的租约：任务领取后从任务流确认，到期未确认或放回时经延迟暂存区重新入队；GetLease不存在时返回nil
+	SaveLease(ctx context.Context, lease *models.TaskLease) error
+	GetLease(ctx context.Context, receipt string) (*models.TaskLease, error)
+	ExtendLease(ctx context.Context, receipt string, visibleAt time.Time) (bool, error)
+	ReleaseLease(ctx context.Context, receipt string, requeue *models.Task) (bool, error)
+	ListExpiredLeases(ctx context.Context, now time.Time, limit int64) ([]*models.TaskLease, error)
+
+	// 任务执行状态，按QUEUE_JOB_TTL_SECONDS过期；GetJob不存在时返回nil
+	SaveJob(ctx context.Context, task *models.Task) error
+	SaveJobs(ctx context.Context, tasks []*models.Task) error
+	GetJob(ctx context.Context, taskID string) (*models.Task, error)
+
+	// 按payload哈希去重：窗口内首次出现时ClaimPayloadHash返回空字符串，否则返回首次出现的任务ID
+	ClaimPayloadHash(ctx context.Context, hash, taskID string, window time.Duration) (string, error)
+	ReleasePayloadHash(ctx context.Context, hash, taskID string) error
+
+	// 延迟任务
+	ScheduleTask(ctx context.Context, task *models.Task) error
+	PromoteDueTasks(ctx context.Context, now time.Time, limit int64) (int64, error)
+	ListScheduledTasks(ctx context.Context, limit int64) ([]*models.Task, error)
+
+	// 死信队列
+	ListDeadLetters(ctx context.Context, filter *models.DeadLetterFilter, limit, offset int) (*models.DeadLetterPage, error)
+	GetDeadLetter(ctx context.Context, taskID string) (*models.Task, error)
+	RemoveDeadLetter(ctx context.Context, taskID string) (*models.Task, error)
+	RemoveDeadLetters(ctx context.Context, filter *models.DeadLetterFilter) ([]*models.Task, error)
+	RestoreDeadLetter(ctx context.Context, task *models.Task) error
+
+	// 任务类型的payload schema；GetTaskSchema不存在时返回nil
+	PutTaskSchema(ctx context.Context, schema *models.TaskSchema) error
+	GetTaskSchema(ctx context.Context, taskType string) (*models.TaskSchema, error)
+	DeleteTaskSchema(ctx context.Context, taskType string) (bool, error)
+	ListTaskSchemas(ctx context.Context) ([]*models.TaskSchema, error)
+
+	// 事件主题
+	PublishEvent(ctx context.Context, topic, payload string) (*models.TopicEvent, error)
+	ReadEvents(ctx context.Context, topic, afterID string, count int64) ([]*models.TopicEvent, error)
+	GetTopicLength(ctx context.Context, topic string) (int64, error)
+
+	Close() error
+}
+
+// QueueDepth 一次采样时队列各状态的任务数
+type QueueDepth struct {
+	Queue      string        // 队列名称
+	Ready      int64         // 尚未投递
+	InFlight   int64         // 已投递未确认，包括外部消费者持有租约的任务
+	Scheduled  int64         // 延迟暂存区中尚未到期（包括等待重试）
+	DeadLetter int64         // 死信队列
+	OldestAge  time.Duration // 最早的未确认任务进入任务流至今的时间，任务流为空时为0
+}
+
+var _ QueueRepository = (*RedisRepository)(nil)
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PutTaskSchema 注册或替换任务类型的schema
+func (r *RedisRepository) PutTaskSchema(ctx context.Context, schema *models.TaskSchema) error {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task schema: %w", err)
+	}
+	if err := r.client.HSet(ctx, r.schemasKey(), schema.Type, data).Err(); err != nil {
+		return fmt.Errorf("failed to save task schema %s: %w", schema.Type, err)
+	}
+	return nil
+}
+
+// GetTaskSchema 获取任务类型的schema，未注册时返回nil
+func (r *RedisRepository) GetTaskSchema(ctx context.Context, taskType string) (*models.TaskSchema, error) {
+	data, err := r.client.HGet(ctx, r.schemasKey(), taskType).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task schema %s: %w", taskType, err)
+	}
+
+	var schema models.TaskSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task schema %s: %w", taskType, err)
+	}
+	return &schema, nil
+}
+
+// DeleteTaskSchema 删除任务类型的schema，返回是否存在
+func (r *RedisRepository) DeleteTaskSchema(ctx context.Context, taskType string) (bool, error) {
+	deleted, err := r.client.HDel(ctx, r.schemasKey(), taskType).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete task schema %s: %w", taskType, err)
+	}
+	return deleted > 0, nil
+}
+
+// ListTaskSchemas 按任务类型排序列出所有schema
+func (r *RedisRepository) ListTaskSchemas(ctx context.Context) ([]*models.TaskSchema, error) {
+	values, err := r.client.HGetAll(ctx, r.schemasKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task schemas: %w", err)
+	}
+
+	schemas := make([]*models.TaskSchema, 0, len(values))
+	for taskType, data := range values {
+		var schema models.TaskSchema
+		if err := json.Unmarshal([]byte(data), &schema); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal task schema %s: %w", taskType, err)
+		}
+		schemas = append(schemas, &schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Type < schemas[j].Type })
+	return schemas, nil
+}
+
+// schemasKey 任务类型schema的哈希键
+func (r *RedisRepository) schemasKey() string {
+	return r.config.StreamName + ":schemas"
+}
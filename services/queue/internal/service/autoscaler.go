@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/services/queue/internal/config"
+	"mocks3/shared/observability"
+	"sync"
+	"time"
+)
+
+// workerPool 自动伸缩的worker池，只由伸缩协程访问
+type workerPool struct {
+	config    config.AutoscaleConfig
+	min       int
+	max       int
+	workers   []string // 池启动的worker，按启动顺序，缩容时先停止最后启动的
+	nextID    int
+	lastScale time.Time
+}
+
+// desiredWorkers 按积压和平均处理耗时计算期望的worker数，返回伸缩原因
+//
+// 每个worker承担TargetBacklogPerWorker个积压任务；平均处理耗时超过目标且积压多于worker数时再增加一个worker。
+func (p *workerPool) desiredWorkers(backlog int64, latency time.Duration) (int, string) {
+	current := len(p.workers)
+	perWorker := int64(p.config.TargetBacklogPerWorker)
+	desired := int((backlog + perWorker - 1) / perWorker)
+	reason := "backlog"
+	if backlog == 0 {
+		reason = "idle"
+	}
+
+	if target := p.config.GetTargetLatency(); target > 0 && latency > target && backlog > int64(current) && desired <= current {
+		desired = current + 1
+		reason = "latency"
+	}
+
+	if desired < p.min {
+		desired = p.min
+	}
+	if desired > p.max {
+		desired = p.max
+	}
+	return desired, reason
+}
+
+// latencyWindow 一个伸缩周期内的任务处理耗时
+type latencyWindow struct {
+	mu    sync.Mutex
+	total time.Duration
+	count int
+}
+
+// observe 记录一次任务处理耗时
+func (l *latencyWindow) observe(d time.Duration) {
+	l.mu.Lock()
+	l.total += d
+	l.count++
+	l.mu.Unlock()
+}
+
+// reset 返回周期内的平均耗时并开始新的周期，没有处理任务时返回0
+func (l *latencyWindow) reset() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var avg time.Duration
+	if l.count > 0 {
+		avg = l.total / time.Duration(l.count)
+	}
+	l.total, l.count = 0, 0
+	return avg
+}
+
+// StartAutoscaler 启动minWorkers个worker，并按interval根据积压和处理耗时在[minWorkers, maxWorkers]之间伸缩，随服务停止
+//
+// 扩容立即生效；缩容每次只减少一个worker，且距上次伸缩至少ScaleDownCooldown。
+// 通过API手动启动的worker不计入池中，也不会被缩容停止。
+func (qs *QueueService) StartAutoscaler(minWorkers, maxWorkers int, cfg config.AutoscaleConfig) {
+	pool := &workerPool{config: cfg, min: minWorkers, max: maxWorkers}
+	qs.scaleWorkers(qs.ctx, pool, minWorkers, "startup")
+
+	if minWorkers == maxWorkers {
+		qs.logger.Info(qs.ctx, "Worker pool autoscaling disabled",
+			observability.Int("workers", minWorkers))
+		return
+	}
+
+	qs.logger.Info(qs.ctx, "Worker pool autoscaler started",
+		observability.Int("min_workers", minWorkers),
+		observability.Int("max_workers", maxWorkers),
+		observability.Duration("interval", cfg.GetInterval()))
+
+	go func() {
+		ticker := time.NewTicker(cfg.GetInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-qs.ctx.Done():
+				return
+			case <-ticker.C:
+				qs.autoscale(pool)
+			}
+		}
+	}()
+}
+
+// autoscale 执行一次伸缩检查
+func (qs *QueueService) autoscale(pool *workerPool) {
+	ctx, cancel := context.WithTimeout(qs.ctx, pool.config.GetInterval())
+	defer cancel()
+
+	qs.pruneWorkers(ctx, pool)
+
+	// 队列暂停期间积压增长不代表处理能力不足
+	if qs.queuePaused(ctx) {
+		qs.latency.reset()
+		return
+	}
+
+	backlog, err := qs.repo.Backlog(ctx)
+	if err != nil {
+		qs.logger.Warn(ctx, "Failed to get queue backlog",
+			observability.String("error", err.Error()))
+		return
+	}
+	latency := qs.latency.reset()
+
+	current := len(pool.workers)
+	desired, reason := pool.desiredWorkers(backlog, latency)
+	switch {
+	case desired > current:
+	case desired < current && time.Since(pool.lastScale) >= pool.config.GetScaleDownCooldown():
+		desired = current - 1
+	default:
+		return
+	}
+
+	qs.logger.Info(ctx, "Scaling worker pool",
+		observability.Int("from", current),
+		observability.Int("to", desired),
+		observability.String("reason", reason),
+		observability.Int64("backlog", backlog),
+		observability.Duration("avg_latency", latency))
+	qs.scaleWorkers(ctx, pool, desired, reason)
+}
+
+// scaleWorkers 启动或停止池中的worker，使其数量为target
+func (qs *QueueService) scaleWorkers(ctx context.Context, pool *workerPool, target int, reason string) {
+	before := len(pool.workers)
+
+	for len(pool.workers) < target {
+		pool.nextID++
+		workerID := fmt.Sprintf("worker-%d", pool.nextID)
+		if err := qs.StartWorker(ctx, workerID); err != nil {
+			qs.logger.Error(ctx, "Failed to start worker",
+				observability.String("worker_id", workerID),
+				observability.String("error", err.Error()))
+			continue
+		}
+		pool.workers = append(pool.workers, workerID)
+	}
+
+	for len(pool.workers) > target {
+		workerID := pool.workers[len(pool.workers)-1]
+		pool.workers = pool.workers[:len(pool.workers)-1]
+		if err := qs.StopWorker(ctx, workerID); err != nil {
+			qs.logger.Warn(ctx, "Failed to stop worker",
+				observability.String("worker_id", workerID),
+				observability.String("error", err.Error()))
+		}
+	}
+
+	delta := len(pool.workers) - before
+	if delta == 0 {
+		return
+	}
+	pool.lastScale = time.Now()
+
+	direction := "up"
+	if delta < 0 {
+		direction = "down"
+	}
+	if qs.metrics != nil {
+		qs.metrics.RecordWorkerScale(ctx, direction, reason, int64(delta))
+	}
+}
+
+// pruneWorkers 移除已通过API停止的worker
+func (qs *QueueService) pruneWorkers(ctx context.Context, pool *workerPool) {
+	qs.mu.RLock()
+	before := len(pool.workers)
+	running := pool.workers[:0]
+	for _, workerID := range pool.workers {
+		if _, ok := qs.workers[workerID]; ok {
+			running = append(running, workerID)
+		}
+	}
+	pool.workers = running
+	qs.mu.RUnlock()
+
+	if stopped := before - len(pool.workers); stopped > 0 && qs.metrics != nil {
+		qs.metrics.RecordWorkerScale(ctx, "down", "manual", -int64(stopped))
+	}
+}
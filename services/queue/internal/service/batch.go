@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"time"
+)
+
+// defaultMaxEnqueueBatch 未调用SetMaxEnqueueBatch时批量入队单次最多接受的任务数
+const defaultMaxEnqueueBatch = 1000
+
+// SetMaxEnqueueBatch 设置批量入队单次最多接受的任务数
+func (qs *QueueService) SetMaxEnqueueBatch(limit int) {
+	qs.maxEnqueueBatch = limit
+}
+
+// ValidateBatchSize 检查批量入队的任务数
+func (qs *QueueService) ValidateBatchSize(size int) error {
+	limit := qs.maxEnqueueBatch
+	if limit <= 0 {
+		limit = defaultMaxEnqueueBatch
+	}
+	if size == 0 || size > limit {
+		return fmt.Errorf("%w: batch size %d must be between 1 and %d", models.ErrInvalidArgument, size, limit)
+	}
+	return nil
+}
+
+// AddTasks 批量添加任务，返回与tasks一一对应的错误，某个任务失败不影响其他任务
+//
+// 立即可见的任务由后端一次写入（Redis为单次pipeline），执行状态也一次保存；延迟任务逐个放入延迟暂存区。
+// 启用去重时，与去重窗口内的任务payload相同的任务返回DuplicateTaskError。
+// 任务数超过上限时整批拒绝。
+func (qs *QueueService) AddTasks(ctx context.Context, tasks []*models.Task) ([]error, error) {
+	if err := qs.ValidateBatchSize(len(tasks)); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	errs := make([]error, len(tasks))
+	hashes := make([]string, len(tasks))
+	var ready []*models.Task
+	var readyIndex []int
+	schemas := make(map[string]*models.TaskSchema)
+	for i, task := range tasks {
+		if err := prepareTask(ctx, task, now); err != nil {
+			errs[i] = err
+			continue
+		}
+		if err := qs.validatePayload(ctx, task, schemas); err != nil {
+			errs[i] = err
+			continue
+		}
+		// 同一批中payload相同的任务也只入队第一个
+		hash, err := qs.claimPayload(ctx, task)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		hashes[i] = hash
+		if task.ScheduledAt.After(now) {
+			if errs[i] = qs.scheduleTask(ctx, task); errs[i] != nil {
+				qs.releasePayload(ctx, hash, task)
+			}
+			continue
+		}
+		task.ScheduledAt = now
+		ready = append(ready, task)
+		readyIndex = append(readyIndex, i)
+	}
+
+	var added []*models.Task
+	if len(ready) > 0 {
+		for j, err := range qs.repo.AddTasks(ctx, ready) {
+			if err != nil {
+				errs[readyIndex[j]] = fmt.Errorf("failed to add task: %w", err)
+				qs.releasePayload(ctx, hashes[readyIndex[j]], ready[j])
+				continue
+			}
+			added = append(added, ready[j])
+		}
+	}
+	if len(added) > 0 {
+		if err := qs.repo.SaveJobs(ctx, added); err != nil {
+			qs.logger.Warn(ctx, "Failed to save job status",
+				observability.Int("tasks", len(added)),
+				observability.String("error", err.Error()))
+		}
+	}
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	qs.logger.Info(ctx, "Task batch added",
+		observability.Int("tasks", len(tasks)),
+		observability.Int("failed", failed))
+	return errs, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+)
+
+// 死信队列分页限制
+const (
+	defaultDeadLetterLimit = 100
+	maxDeadLetterLimit     = 1000
+)
+
+// ListDeadLetters 分页列出死信任务（按进入死信队列的时间倒序）
+func (qs *QueueService) ListDeadLetters(ctx context.Context, filter *models.DeadLetterFilter, limit, offset int) (*models.DeadLetterPage, error) {
+	if limit <= 0 {
+		limit = defaultDeadLetterLimit
+	}
+	if limit > maxDeadLetterLimit {
+		limit = maxDeadLetterLimit
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", models.ErrInvalidArgument)
+	}
+
+	page, err := qs.repo.ListDeadLetters(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return page, nil
+}
+
+// GetDeadLetter 获取死信任务（含完整payload和最后一次失败原因）
+func (qs *QueueService) GetDeadLetter(ctx context.Context, taskID string) (*models.Task, error) {
+	task, err := qs.repo.GetDeadLetter(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	if task == nil {
+		return nil, fmt.Errorf("%w: %s", ErrDeadLetterNotFound, taskID)
+	}
+	return task, nil
+}
+
+// RequeueDeadLetter 将死信任务移回任务流，重试次数清零
+func (qs *QueueService) RequeueDeadLetter(ctx context.Context, taskID string) (*models.Task, error) {
+	task, err := qs.repo.RemoveDeadLetter(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue dead letter: %w", err)
+	}
+	if task == nil {
+		return nil, fmt.Errorf("%w: %s", ErrDeadLetterNotFound, taskID)
+	}
+
+	if err := qs.requeue(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// RequeueDeadLetters 将符合条件的死信任务全部移回任务流，返回移回的任务数
+func (qs *QueueService) RequeueDeadLetters(ctx context.Context, filter *models.DeadLetterFilter) (int, error) {
+	tasks, err := qs.repo.RemoveDeadLetters(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue dead letters: %w", err)
+	}
+
+	for i, task := range tasks {
+		if err := qs.requeue(ctx, task); err != nil {
+			// 其余已移出的任务放回死信队列
+			for _, rest := range tasks[i+1:] {
+				if restoreErr := qs.repo.RestoreDeadLetter(ctx, rest); restoreErr != nil {
+					qs.logger.Error(ctx, "Failed to restore dead letter",
+						observability.String("task_id", rest.ID),
+						observability.String("error", restoreErr.Error()))
+				}
+			}
+			return i, err
+		}
+	}
+
+	qs.logger.Info(ctx, "Dead letters requeued", observability.Int("count", len(tasks)))
+	return len(tasks), nil
+}
+
+// DeleteDeadLetter 从死信队列删除任务
+func (qs *QueueService) DeleteDeadLetter(ctx context.Context, taskID string) error {
+	task, err := qs.repo.RemoveDeadLetter(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+	if task == nil {
+		return fmt.Errorf("%w: %s", ErrDeadLetterNotFound, taskID)
+	}
+
+	qs.logger.Info(ctx, "Dead letter deleted", observability.String("task_id", taskID))
+	return nil
+}
+
+// PurgeDeadLetters 删除符合条件的死信任务，返回删除数
+func (qs *QueueService) PurgeDeadLetters(ctx context.Context, filter *models.DeadLetterFilter) (int, error) {
+	tasks, err := qs.repo.RemoveDeadLetters(ctx, filter)
+	if err != nil {
+		return len(tasks), fmt.Errorf("failed to purge dead letters: %w", err)
+	}
+
+	qs.logger.Info(ctx, "Dead letters purged", observability.Int("count", len(tasks)))
+	return len(tasks), nil
+}
+
+// requeue 重置死信任务的重试状态后重新入队，失败时放回死信队列
+func (qs *QueueService) requeue(ctx context.Context, task *models.Task) error {
+	deadLetter := *task
+
+	task.RetryCount = 0
+	task.Error = ""
+	task.FailedAt = nil
+	task.StreamID = ""
+	if err := qs.AddTask(ctx, task); err != nil {
+		if restoreErr := qs.repo.RestoreDeadLetter(ctx, &deadLetter); restoreErr != nil {
+			qs.logger.Error(ctx, "Failed to restore dead letter",
+				observability.String("task_id", task.ID),
+				observability.String("error", restoreErr.Error()))
+		}
+		return fmt.Errorf("failed to requeue task %s: %w", task.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"time"
+)
+
+// SetDedupWindow 设置按payload去重的时间窗口，为0时不去重
+func (qs *QueueService) SetDedupWindow(window time.Duration) {
+	qs.dedupWindow = window
+}
+
+// claimPayload 记录任务的payload哈希，去重窗口内已有相同payload的任务时返回DuplicateTaskError
+//
+// 返回的哈希在任务入队失败时交给releasePayload，未启用去重时为空。
+func (qs *QueueService) claimPayload(ctx context.Context, task *models.Task) (string, error) {
+	if qs.dedupWindow <= 0 {
+		return "", nil
+	}
+
+	hash, err := payloadHash(task)
+	if err != nil {
+		return "", err
+	}
+	duplicateOf, err := qs.repo.ClaimPayloadHash(ctx, hash, task.ID, qs.dedupWindow)
+	if err != nil {
+		return "", fmt.Errorf("failed to add task: %w", err)
+	}
+	if duplicateOf == "" {
+		return hash, nil
+	}
+
+	if qs.metrics != nil {
+		qs.metrics.RecordDedupHit(ctx, task.Type)
+	}
+	qs.logger.Info(ctx, "Duplicate task dropped",
+		observability.String("task_id", task.ID),
+		observability.String("type", task.Type),
+		observability.String("duplicate_of", duplicateOf))
+	return "", &models.DuplicateTaskError{TaskID: task.ID, DuplicateOf: duplicateOf}
+}
+
+// releasePayload 删除入队失败的任务的去重记录，使之后相同payload的任务可以入队
+func (qs *QueueService) releasePayload(ctx context.Context, hash string, task *models.Task) {
+	if hash == "" {
+		return
+	}
+	if err := qs.repo.ReleasePayloadHash(ctx, hash, task.ID); err != nil {
+		qs.logger.Warn(ctx, "Failed to release payload hash",
+			observability.String("task_id", task.ID),
+			observability.String("error", err.Error()))
+	}
+}
+
+// payloadHash 按租户、任务类型和data计算的SHA-256，data的键按字典序编码，与请求中的顺序无关
+func payloadHash(task *models.Task) (string, error) {
+	data, err := json.Marshal(task.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal task data: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(task.TenantOrDefault()))
+	h.Write([]byte{0})
+	h.Write([]byte(task.Type))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
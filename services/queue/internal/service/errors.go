@@ -0,0 +1,25 @@
+package service
+
+import "errors"
+
+// 服务返回的错误，handler用errors.Is映射HTTP状态码
+var (
+	// ErrQueueNotFound 队列不存在
+	ErrQueueNotFound = errors.New("queue not found")
+	// ErrJobNotFound 任务执行状态不存在
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotRunning 任务不在执行中
+	ErrJobNotRunning = errors.New("job is not running")
+	// ErrLeaseNotFound 租约不存在（已确认、放回或到期）
+	ErrLeaseNotFound = errors.New("lease not found")
+	// ErrReceiveUnavailable 队列后端或配置不支持领取任务
+	ErrReceiveUnavailable = errors.New("receive unavailable")
+	// ErrDeadLetterNotFound 死信任务不存在
+	ErrDeadLetterNotFound = errors.New("dead letter task not found")
+	// ErrRateLimitNotFound 任务类型没有速率限制
+	ErrRateLimitNotFound = errors.New("rate limit not found")
+	// ErrTaskSchemaNotFound 任务类型没有payload schema
+	ErrTaskSchemaNotFound = errors.New("task schema not found")
+	// ErrWorkerNotFound worker不存在
+	ErrWorkerNotFound = errors.New("worker not found")
+)
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"time"
+)
+
+// dropExpired 丢弃已过期的任务：确认任务流中的消息，记录expired状态并通知回调地址，返回任务是否已过期
+//
+// 确认失败时任务保持未确认，之后重新投递时再次丢弃。
+func (qs *QueueService) dropExpired(ctx context.Context, task *models.Task) bool {
+	now := time.Now()
+	if !task.Expired(now) {
+		return false
+	}
+
+	if err := qs.repo.AckTask(ctx, task.StreamID); err != nil {
+		qs.logger.Error(ctx, "Failed to ack expired task",
+			observability.String("task_id", task.ID),
+			observability.String("error", err.Error()))
+		return true
+	}
+
+	task.Status = models.TaskStatusExpired
+	task.UpdatedAt = now
+	qs.trackJob(ctx, task)
+	qs.notifyJob(ctx, task)
+	if qs.metrics != nil {
+		qs.metrics.RecordTaskExpired(ctx, task.Type)
+	}
+
+	qs.logger.Warn(ctx, "Expired task dropped",
+		observability.String("task_id", task.ID),
+		observability.String("type", task.Type),
+		observability.String("expires_at", task.ExpiresAt.Format(time.RFC3339Nano)),
+		observability.Duration("late_by", now.Sub(*task.ExpiresAt)))
+	return true
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"time"
+)
+
+// reapBatchSize 每次取回的心跳超时任务数上限
+const reapBatchSize = 100
+
+// errHeartbeatLost 心跳超时的任务重新入队时记录的失败原因
+var errHeartbeatLost = errors.New("worker heartbeat lost")
+
+// heartbeatConfig worker上报心跳的间隔和判定任务卡住的超时时间
+type heartbeatConfig struct {
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// SetHeartbeat 设置worker上报心跳的间隔和心跳超时时间，timeout为0时不上报心跳也不检查
+func (qs *QueueService) SetHeartbeat(interval, timeout time.Duration) {
+	qs.heartbeat = heartbeatConfig{interval: interval, timeout: timeout}
+}
+
+// startHeartbeat 记录任务开始处理并按间隔上报心跳，直到返回的函数被调用或ctx结束
+//
+// 返回的函数停止上报并删除心跳记录，必须在确认或重试任务之前调用，否则检查心跳时可能把已处理完的任务重新入队。
+func (qs *QueueService) startHeartbeat(ctx context.Context, task *models.Task) func() {
+	hb := qs.heartbeat
+	if hb.timeout <= 0 || task.StreamID == "" {
+		return func() {}
+	}
+
+	if err := qs.repo.StartHeartbeat(ctx, task, time.Now()); err != nil {
+		qs.logger.Warn(ctx, "Failed to start task heartbeat",
+			observability.String("task_id", task.ID),
+			observability.String("error", err.Error()))
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(hb.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				alive, err := qs.repo.Heartbeat(ctx, task.StreamID, time.Now())
+				if err != nil {
+					if ctx.Err() == nil {
+						qs.logger.Warn(ctx, "Failed to heartbeat task",
+							observability.String("task_id", task.ID),
+							observability.String("error", err.Error()))
+					}
+					continue
+				}
+				// 心跳记录已被删除说明任务已被当作卡住的任务重新入队，本次执行的结果可能与重新入队的执行重复
+				if !alive {
+					qs.logger.Warn(ctx, "Task was requeued while still running",
+						observability.String("task_id", task.ID),
+						observability.String("worker_id", task.WorkerID))
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+		if err := qs.repo.ClearHeartbeat(context.WithoutCancel(ctx), task.StreamID); err != nil {
+			qs.logger.Warn(ctx, "Failed to clear task heartbeat",
+				observability.String("task_id", task.ID),
+				observability.String("error", err.Error()))
+		}
+	}
+}
+
+// ReapStuckJobs 将心跳超时的任务按重试策略重新入队，返回重新入队的任务数
+//
+// 任务由Redis脚本原子取回，多个实例同时检查时每个任务只会被重新入队一次。
+func (qs *QueueService) ReapStuckJobs(ctx context.Context) (int, error) {
+	timeout := qs.heartbeat.timeout
+	if timeout <= 0 {
+		return 0, nil
+	}
+
+	var total int
+	for {
+		tasks, err := qs.repo.ClaimStaleHeartbeats(ctx, time.Now().Add(-timeout), reapBatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		for _, task := range tasks {
+			qs.logger.Warn(ctx, "Task worker stopped heartbeating, requeuing",
+				observability.String("task_id", task.ID),
+				observability.String("type", task.Type),
+				observability.String("worker_id", task.WorkerID))
+			if qs.metrics != nil {
+				qs.metrics.RecordStuckJob(ctx, task.Type)
+			}
+			if err := qs.retryTask(ctx, task, errHeartbeatLost); err != nil {
+				qs.logger.Error(ctx, "Failed to requeue stuck task",
+					observability.String("task_id", task.ID),
+					observability.String("error", err.Error()))
+				continue
+			}
+			total++
+		}
+
+		if len(tasks) < reapBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// StartReaper 启动后台任务，按心跳间隔检查并重新入队心跳超时的任务，随服务停止
+func (qs *QueueService) StartReaper() {
+	hb := qs.heartbeat
+	if hb.timeout <= 0 || hb.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(hb.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-qs.ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := qs.ReapStuckJobs(qs.ctx); err != nil && qs.ctx.Err() == nil {
+					qs.logger.Warn(qs.ctx, "Failed to reap stuck tasks",
+						observability.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+}
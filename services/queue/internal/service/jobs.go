@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"time"
+)
+
+// trackJob 记录任务执行状态，失败时只记录日志，不影响任务处理
+func (qs *QueueService) trackJob(ctx context.Context, task *models.Task) {
+	if err := qs.repo.SaveJob(ctx, task); err != nil {
+		qs.logger.Warn(ctx, "Failed to save job status",
+			observability.String("task_id", task.ID),
+			observability.String("error", err.Error()))
+	}
+}
+
+// GetJob 获取任务执行状态，执行状态已过期时按队列中的任务推断
+func (qs *QueueService) GetJob(ctx context.Context, jobID string) (*models.JobStatus, error) {
+	task, err := qs.repo.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		if task, err = qs.repo.GetTaskStatus(ctx, jobID); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+		}
+	}
+	return models.NewJobStatus(task), nil
+}
+
+// ReportProgress 更新正在执行的任务的进度（0-100）
+func (qs *QueueService) ReportProgress(ctx context.Context, jobID string, progress int) (*models.JobStatus, error) {
+	if progress < 0 || progress > 100 {
+		return nil, fmt.Errorf("%w: progress %d must be between 0 and 100", models.ErrInvalidArgument, progress)
+	}
+
+	task, err := qs.repo.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	}
+	if task.Status != models.TaskStatusRunning {
+		return nil, fmt.Errorf("%w: %s is %s", ErrJobNotRunning, jobID, task.JobState())
+	}
+
+	task.Progress = &progress
+	task.UpdatedAt = time.Now()
+	if err := qs.repo.SaveJob(ctx, task); err != nil {
+		return nil, err
+	}
+	return models.NewJobStatus(task), nil
+}
+
+// reportProgress worker上报当前任务的进度
+func (w *Worker) reportProgress(ctx context.Context, task *models.Task, progress int) {
+	task.Progress = &progress
+	task.UpdatedAt = time.Now()
+	w.service.trackJob(ctx, task)
+}
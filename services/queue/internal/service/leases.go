@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"time"
+)
+
+const (
+	// MaxReceiveTasks 外部消费者单次最多领取的任务数
+	MaxReceiveTasks = 10
+	// MaxReceiveWait 领取任务时等待新任务的最长时间
+	MaxReceiveWait = 20 * time.Second
+
+	// receiveMinWait 不等待（短轮询）时读取任务流的超时时间
+	receiveMinWait = 200 * time.Millisecond
+	// leaseConsumer 外部消费者在消费者组中使用的worker ID
+	leaseConsumer = "api"
+)
+
+// errVisibilityTimeout 租约到期仍未确认，视为一次失败的执行
+var errVisibilityTimeout = errors.New("visibility timeout expired")
+
+// visibilityConfig 租约的默认不可见时间和上限
+type visibilityConfig struct {
+	timeout time.Duration
+	max     time.Duration
+}
+
+// SetVisibilityTimeout 设置领取任务时默认的不可见时间和上限，未设置时不支持外部消费者领取任务
+func (qs *QueueService) SetVisibilityTimeout(timeout, max time.Duration) {
+	qs.visibility = visibilityConfig{timeout: timeout, max: max}
+}
+
+// ReceiveTasks 外部消费者领取最多count个任务，任务在visibility内对其他消费者不可见
+//
+// visibility为0时使用默认的不可见时间；没有可领取的任务时最多等待wait。
+// 租约到期前需调用AckLease确认或NackLease放回，否则由调度器放回队列并计入执行次数。
+func (qs *QueueService) ReceiveTasks(ctx context.Context, count int, visibility, wait time.Duration) ([]*models.TaskLease, error) {
+	if qs.visibility.timeout == 0 {
+		return nil, fmt.Errorf("%w: not supported by the queue backend", ErrReceiveUnavailable)
+	}
+	if count < 1 || count > MaxReceiveTasks {
+		return nil, fmt.Errorf("%w: max_tasks must be between 1 and %d", models.ErrInvalidArgument, MaxReceiveTasks)
+	}
+	if visibility == 0 {
+		visibility = qs.visibility.timeout
+	}
+	if visibility < 0 || visibility > qs.visibility.max {
+		return nil, fmt.Errorf("%w: visibility timeout must be between 1s and %s", models.ErrInvalidArgument, qs.visibility.max)
+	}
+	if wait < 0 || wait > MaxReceiveWait {
+		return nil, fmt.Errorf("%w: wait time must be between 0 and %s", models.ErrInvalidArgument, MaxReceiveWait)
+	}
+	// 到期的租约由调度器放回队列
+	if !qs.schedulerRunning.Load() {
+		return nil, fmt.Errorf("%w: visibility timeout requires the scheduler", ErrReceiveUnavailable)
+	}
+	if qs.queuePaused(ctx) {
+		return []*models.TaskLease{}, nil
+	}
+
+	pollCtx, cancel := context.WithTimeout(ctx, max(wait, receiveMinWait))
+	tasks, err := qs.repo.GetTasks(pollCtx, leaseConsumer, int64(count))
+	timedOut := pollCtx.Err() != nil
+	cancel()
+	if err != nil {
+		// 等待超时不是错误，已读取但未返回的消息由接管机制重新投递
+		if !timedOut || ctx.Err() != nil {
+			return nil, err
+		}
+		tasks = nil
+	}
+
+	now := time.Now()
+	leases := make([]*models.TaskLease, 0, len(tasks))
+	for _, task := range tasks {
+		if qs.dropExpired(ctx, task) {
+			continue
+		}
+		task.Status = models.TaskStatusRunning
+		task.StartedAt = &now
+		task.UpdatedAt = now
+		task.WorkerID = leaseConsumer
+		task.Progress = nil
+		lease := &models.TaskLease{
+			ReceiptHandle: newReceiptHandle(),
+			Task:          task,
+			VisibleAt:     now.Add(visibility),
+		}
+
+		// 先保存租约再确认任务流中的消息，确认失败时消息可能被重复投递，但不会丢失
+		if err := qs.repo.SaveLease(ctx, lease); err != nil {
+			qs.logger.Error(ctx, "Failed to save task lease",
+				observability.String("task_id", task.ID),
+				observability.String("error", err.Error()))
+			continue
+		}
+		if err := qs.repo.AckTask(ctx, task.StreamID); err != nil {
+			qs.logger.Warn(ctx, "Failed to ack leased task",
+				observability.String("task_id", task.ID),
+				observability.String("error", err.Error()))
+		}
+		qs.trackJob(ctx, task)
+		leases = append(leases, lease)
+	}
+
+	if len(leases) > 0 {
+		qs.logger.Info(ctx, "Tasks leased",
+			observability.Int("count", len(leases)),
+			observability.Duration("visibility_timeout", visibility))
+	}
+	return leases, nil
+}
+
+// AckLease 确认租约中的任务已处理完成，output为任务输出，合并到后续任务的数据中
+func (qs *QueueService) AckLease(ctx context.Context, receipt string, output map[string]interface{}) (*models.Task, error) {
+	lease, err := qs.getLease(ctx, receipt)
+	if err != nil {
+		return nil, err
+	}
+	released, err := qs.repo.ReleaseLease(ctx, receipt, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !released {
+		return nil, fmt.Errorf("%w: %s", ErrLeaseNotFound, receipt)
+	}
+
+	task := lease.Task
+	completedAt := time.Now()
+	progress := 100
+	task.Status = models.TaskStatusCompleted
+	task.CompletedAt = &completedAt
+	task.UpdatedAt = completedAt
+	task.Progress = &progress
+	task.Output = output
+	qs.trackJob(ctx, task)
+	qs.notifyJob(ctx, task)
+	qs.enqueueFollowUps(ctx, task)
+
+	qs.logger.Info(ctx, "Leased task acknowledged",
+		observability.String("task_id", task.ID))
+	return task, nil
+}
+
+// NackLease 放回租约中的任务，计入执行次数
+//
+// delay为nil时按任务类型的重试策略退避，次数用尽时移入死信队列。
+func (qs *QueueService) NackLease(ctx context.Context, receipt, reason string, delay *time.Duration) (*models.Task, error) {
+	if delay != nil && (*delay < 0 || *delay > MaxTaskDelay) {
+		return nil, fmt.Errorf("%w: delay must be between 0 and %s", models.ErrInvalidArgument, MaxTaskDelay)
+	}
+	lease, err := qs.getLease(ctx, receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	cause := errors.New("nacked by consumer")
+	if reason != "" {
+		cause = errors.New(reason)
+	}
+	return qs.failLease(ctx, lease, cause, delay)
+}
+
+// ExtendLease 将租约的不可见时间延长为从现在起visibility
+func (qs *QueueService) ExtendLease(ctx context.Context, receipt string, visibility time.Duration) (*models.TaskLease, error) {
+	if visibility <= 0 || visibility > qs.visibility.max {
+		return nil, fmt.Errorf("%w: visibility timeout must be between 1s and %s", models.ErrInvalidArgument, qs.visibility.max)
+	}
+
+	visibleAt := time.Now().Add(visibility)
+	extended, err := qs.repo.ExtendLease(ctx, receipt, visibleAt)
+	if err != nil {
+		return nil, err
+	}
+	if !extended {
+		return nil, fmt.Errorf("%w: %s", ErrLeaseNotFound, receipt)
+	}
+	return qs.getLease(ctx, receipt)
+}
+
+// ExpireLeases 将到期仍未确认的租约中的任务放回队列，返回处理的租约数
+func (qs *QueueService) ExpireLeases(ctx context.Context) (int64, error) {
+	leases, err := qs.repo.ListExpiredLeases(ctx, time.Now(), promoteBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int64
+	immediately := time.Duration(0)
+	for _, lease := range leases {
+		if _, err := qs.failLease(ctx, lease, errVisibilityTimeout, &immediately); err != nil {
+			// 租约已被确认或放回
+			if errors.Is(err, ErrLeaseNotFound) {
+				continue
+			}
+			return expired, err
+		}
+		expired++
+	}
+
+	if expired > 0 {
+		qs.logger.Warn(ctx, "Expired task leases requeued", observability.Int64("expired", expired))
+	}
+	return expired, nil
+}
+
+// failLease 记录失败原因和执行次数，在delay后重新入队，用尽次数或payload无效时移入死信队列
+func (qs *QueueService) failLease(ctx context.Context, lease *models.TaskLease, cause error, delay *time.Duration) (*models.Task, error) {
+	task := lease.Task
+	task.RetryCount++
+	task.Error = cause.Error()
+
+	policy := qs.RetryPolicyFor(task.Type)
+	if task.RetryCount >= policy.MaxAttempts || errors.Is(cause, models.ErrInvalidPayload) {
+		// 先写入死信队列再删除租约，删除失败时租约到期后再次处理
+		if err := qs.repo.DeadLetterTask(ctx, task); err != nil {
+			return nil, err
+		}
+		released, err := qs.repo.ReleaseLease(ctx, lease.ReceiptHandle, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !released {
+			// 租约已被确认或放回，撤回刚写入的死信，避免任务重复
+			if _, err := qs.repo.RemoveDeadLetter(ctx, task.ID); err != nil {
+				qs.logger.Error(ctx, "Failed to withdraw dead letter",
+					observability.String("task_id", task.ID),
+					observability.String("error", err.Error()))
+			}
+			return nil, fmt.Errorf("%w: %s", ErrLeaseNotFound, lease.ReceiptHandle)
+		}
+		qs.trackJob(ctx, task)
+		qs.notifyJob(ctx, task)
+		qs.logger.Warn(ctx, "Leased task moved to dead letter queue",
+			observability.String("task_id", task.ID),
+			observability.String("type", task.Type),
+			observability.Int("attempts", task.RetryCount))
+		return task, nil
+	}
+
+	if delay == nil {
+		backoff := retryBackoff(policy, task.RetryCount)
+		delay = &backoff
+	}
+	now := time.Now()
+	task.Status = models.TaskStatusRetrying
+	task.UpdatedAt = now
+	task.ScheduledAt = now.Add(*delay)
+
+	released, err := qs.repo.ReleaseLease(ctx, lease.ReceiptHandle, task)
+	if err != nil {
+		return nil, err
+	}
+	if !released {
+		return nil, fmt.Errorf("%w: %s", ErrLeaseNotFound, lease.ReceiptHandle)
+	}
+	qs.trackJob(ctx, task)
+
+	qs.logger.Info(ctx, "Leased task released for retry",
+		observability.String("task_id", task.ID),
+		observability.String("reason", task.Error),
+		observability.Int("attempts", task.RetryCount),
+		observability.String("retry_at", task.ScheduledAt.Format(time.RFC3339Nano)))
+	return task, nil
+}
+
+// getLease 获取租约，不存在时返回not found错误
+func (qs *QueueService) getLease(ctx context.Context, receipt string) (*models.TaskLease, error) {
+	lease, err := qs.repo.GetLease(ctx, receipt)
+	if err != nil {
+		return nil, err
+	}
+	if lease == nil {
+		return nil, fmt.Errorf("%w: %s", ErrLeaseNotFound, receipt)
+	}
+	return lease, nil
+}
+
+// newReceiptHandle 生成随机的租约凭证
+func newReceiptHandle() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
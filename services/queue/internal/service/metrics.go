@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"mocks3/shared/observability"
+	"time"
+)
+
+// depthSampleTimeout 采集指标时查询队列深度的超时时间
+const depthSampleTimeout = 5 * time.Second
+
+// SetMetricCollector 设置指标收集器，并注册队列深度采样
+func (qs *QueueService) SetMetricCollector(metrics *observability.MetricCollector) {
+	qs.metrics = metrics
+	if err := metrics.ObserveQueueDepth(qs.sampleDepth); err != nil {
+		qs.logger.Warn(qs.ctx, "Failed to register queue depth metrics",
+			observability.String("error", err.Error()))
+	}
+}
+
+// sampleDepth 按状态采样队列深度
+func (qs *QueueService) sampleDepth(ctx context.Context) ([]observability.QueueDepthSample, error) {
+	ctx, cancel := context.WithTimeout(ctx, depthSampleTimeout)
+	defer cancel()
+
+	depth, err := qs.repo.Depth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []observability.QueueDepthSample{{
+		Queue: depth.Queue,
+		States: map[string]int64{
+			"ready":       depth.Ready,
+			"in_flight":   depth.InFlight,
+			"scheduled":   depth.Scheduled,
+			"dead_letter": depth.DeadLetter,
+		},
+		OldestAge: depth.OldestAge,
+	}}, nil
+}
+
+// observeTask 记录任务处理耗时和worker吞吐，用于自动伸缩和指标
+func (qs *QueueService) observeTask(ctx context.Context, workerID, taskType string, err error, duration time.Duration) {
+	qs.latency.observe(duration)
+	if qs.metrics != nil {
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		qs.metrics.RecordTaskDuration(ctx, taskType, result, duration)
+		qs.metrics.RecordWorkerTask(ctx, workerID, taskType, result)
+	}
+}
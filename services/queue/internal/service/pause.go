@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"sync"
+	"time"
+)
+
+// pauseCheckInterval worker重新读取队列暂停状态的间隔，在其他实例上暂停或恢复最迟在该时间后生效
+const pauseCheckInterval = time.Second
+
+// pauseState 本实例缓存的队列暂停状态
+type pauseState struct {
+	mu        sync.Mutex
+	paused    bool
+	checkedAt time.Time
+}
+
+// set 更新缓存的暂停状态
+func (p *pauseState) set(paused bool) {
+	p.mu.Lock()
+	p.paused = paused
+	p.checkedAt = time.Now()
+	p.mu.Unlock()
+}
+
+// queuePaused 返回队列是否已暂停，每pauseCheckInterval最多查询一次存储后端，查询失败时沿用上次的状态
+func (qs *QueueService) queuePaused(ctx context.Context) bool {
+	qs.pause.mu.Lock()
+	defer qs.pause.mu.Unlock()
+	if time.Since(qs.pause.checkedAt) < pauseCheckInterval {
+		return qs.pause.paused
+	}
+
+	state, err := qs.repo.GetQueueState(ctx)
+	if err != nil {
+		qs.logger.Warn(ctx, "Failed to get queue state",
+			observability.String("error", err.Error()))
+	} else {
+		qs.pause.paused = state.Paused
+	}
+	qs.pause.checkedAt = time.Now()
+	return qs.pause.paused
+}
+
+// QueueStates 列出队列的运行状态
+func (qs *QueueService) QueueStates(ctx context.Context) ([]*models.QueueState, error) {
+	state, err := qs.repo.GetQueueState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.QueueState{state}, nil
+}
+
+// PauseQueue 暂停队列：worker处理完已拉取的任务后不再拉取新任务，入队和延迟任务的调度不受影响
+//
+// 已暂停的队列保持原来的暂停原因和时间。
+func (qs *QueueService) PauseQueue(ctx context.Context, name, reason string) (*models.QueueState, error) {
+	state, err := qs.queueState(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if state.Paused {
+		return state, nil
+	}
+
+	now := time.Now()
+	state.Paused = true
+	state.Reason = reason
+	state.PausedAt = &now
+	if err := qs.repo.SaveQueueState(ctx, state); err != nil {
+		qs.logger.Error(ctx, "Failed to pause queue",
+			observability.String("queue", name),
+			observability.String("error", err.Error()))
+		return nil, err
+	}
+	qs.pause.set(true)
+
+	qs.logger.Info(ctx, "Queue paused",
+		observability.String("queue", name),
+		observability.String("reason", reason))
+	return state, nil
+}
+
+// ResumeQueue 恢复已暂停的队列
+func (qs *QueueService) ResumeQueue(ctx context.Context, name string) (*models.QueueState, error) {
+	state, err := qs.queueState(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Paused {
+		return state, nil
+	}
+
+	pausedAt := state.PausedAt
+	state = &models.QueueState{Name: name}
+	if err := qs.repo.SaveQueueState(ctx, state); err != nil {
+		qs.logger.Error(ctx, "Failed to resume queue",
+			observability.String("queue", name),
+			observability.String("error", err.Error()))
+		return nil, err
+	}
+	qs.pause.set(false)
+
+	fields := []observability.Field{observability.String("queue", name)}
+	if pausedAt != nil {
+		fields = append(fields, observability.Duration("paused_for", time.Since(*pausedAt)))
+	}
+	qs.logger.Info(ctx, "Queue resumed", fields...)
+	return state, nil
+}
+
+// queueState 获取指定名称的队列的运行状态
+func (qs *QueueService) queueState(ctx context.Context, name string) (*models.QueueState, error) {
+	state, err := qs.repo.GetQueueState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if state.Name != name {
+		return nil, fmt.Errorf("%w: %s", ErrQueueNotFound, name)
+	}
+	return state, nil
+}
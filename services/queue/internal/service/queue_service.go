@@ -3,22 +3,47 @@ package service
 import (
 	"context"
 	"fmt"
+	"mocks3/services/queue/internal/config"
 	"mocks3/services/queue/internal/repository"
 	"mocks3/shared/interfaces"
+	"mocks3/shared/jsonschema"
 	"mocks3/shared/models"
 	"mocks3/shared/observability"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // QueueService 队列服务实现
 type QueueService struct {
-	repo    *repository.RedisRepository
+	repo    repository.QueueRepository
 	logger  *observability.Logger
 	workers map[string]*Worker
 	mu      sync.RWMutex
 	ctx     context.Context
 	cancel  context.CancelFunc
+
+	retryPolicy      config.RetryPolicy            // 默认重试策略
+	retryPolicies    map[string]config.RetryPolicy // 按任务类型覆盖的重试策略
+	schedulerRunning atomic.Bool                   // 调度器运行时失败任务才能退避后重试
+
+	metrics *observability.MetricCollector
+	latency latencyWindow // 自动伸缩周期内的任务处理耗时
+
+	maxEnqueueBatch int // 批量入队单次最多接受的任务数
+
+	webhooks *webhookNotifier // 任务结束通知
+	limiter  typeLimiter      // 按任务类型的处理速率限制
+	schemas  jsonschema.Cache // 已编译的任务payload schema
+	pause    pauseState       // 队列是否已暂停
+
+	visibility visibilityConfig // 外部消费者租约的不可见时间，为零值时不支持领取任务
+
+	dedupWindow time.Duration // payload相同的任务在该时间内只入队一次，为0时不去重
+
+	heartbeat heartbeatConfig // worker处理任务时上报心跳，心跳超时的任务重新入队
+
+	enqueueNext func(ctx context.Context, task *models.Task) error // 后续任务的入队方式，为nil时进入本队列
 }
 
 // Worker 工作节点
@@ -32,7 +57,7 @@ type Worker struct {
 }
 
 // NewQueueService 创建队列服务
-func NewQueueService(repo *repository.RedisRepository, logger *observability.Logger) *QueueService {
+func NewQueueService(repo repository.QueueRepository, logger *observability.Logger) *QueueService {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &QueueService{
@@ -41,26 +66,48 @@ func NewQueueService(repo *repository.RedisRepository, logger *observability.Log
 		workers: make(map[string]*Worker),
 		ctx:     ctx,
 		cancel:  cancel,
+
+		retryPolicy: defaultRetryPolicy,
+		webhooks:    newWebhookNotifier(defaultWebhookConfig),
 	}
 }
 
-// AddTask 添加任务到队列
+// AddTask 添加任务到队列，ScheduledAt晚于当前时间的任务到期后才对worker可见
 func (qs *QueueService) AddTask(ctx context.Context, task *models.Task) error {
+	if err := prepareTask(ctx, task, time.Now()); err != nil {
+		return err
+	}
+	if err := qs.validatePayload(ctx, task, nil); err != nil {
+		return err
+	}
+	hash, err := qs.claimPayload(ctx, task)
+	if err != nil {
+		return err
+	}
+
 	qs.logger.Info(ctx, "Adding task to queue", 
 		observability.String("task_id", task.ID), 
-		observability.String("type", task.Type))
-
-	// 设置任务状态和时间戳
-	task.Status = "pending"
-	task.CreatedAt = time.Now()
-	task.UpdatedAt = task.CreatedAt
+		observability.String("type", task.Type),
+		observability.String("tenant", task.Tenant))
+
+	// 指定了未来的可见时间时放入延迟暂存区
+	if task.ScheduledAt.After(task.CreatedAt) {
+		if err := qs.scheduleTask(ctx, task); err != nil {
+			qs.releasePayload(ctx, hash, task)
+			return err
+		}
+		return nil
+	}
+	task.ScheduledAt = task.CreatedAt
 
 	if err := qs.repo.AddTask(ctx, task); err != nil {
+		qs.releasePayload(ctx, hash, task)
 		qs.logger.Error(ctx, "Failed to add task", 
 			observability.String("error", err.Error()), 
 			observability.String("task_id", task.ID))
 		return fmt.Errorf("failed to add task: %w", err)
 	}
+	qs.trackJob(ctx, task)
 
 	qs.logger.Info(ctx, "Task added successfully", 
 		observability.String("task_id", task.ID), 
@@ -68,6 +115,22 @@ func (qs *QueueService) AddTask(ctx context.Context, task *models.Task) error {
 	return nil
 }
 
+// prepareTask 设置入队任务的租户、状态和时间戳
+func prepareTask(ctx context.Context, task *models.Task, now time.Time) error {
+	// 未指定租户的任务归属请求租户，请求指定了租户时不能为其他租户入队
+	if tenant, ok := models.LookupTenant(ctx); ok && task.Tenant != "" && task.Tenant != tenant {
+		return fmt.Errorf("task tenant %s does not match request tenant %s", task.Tenant, tenant)
+	}
+	if task.Tenant == "" {
+		task.Tenant = models.TenantFromContext(ctx)
+	}
+
+	task.Status = models.TaskStatusPending
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	return nil
+}
+
 // GetTask 获取任务
 func (qs *QueueService) GetTask(ctx context.Context, taskID string) (*models.Task, error) {
 	qs.logger.Debug(ctx, "Getting task", 
@@ -78,7 +141,7 @@ func (qs *QueueService) GetTask(ctx context.Context, taskID string) (*models.Tas
 		qs.logger.Warn(ctx, "Task not found", 
 			observability.String("task_id", taskID), 
 			observability.String("error", err.Error()))
-		return nil, fmt.Errorf("task not found: %w", err)
+		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
 	return task, nil
@@ -175,7 +238,7 @@ func (qs *QueueService) StopWorker(ctx context.Context, workerID string) error {
 
 	worker, exists := qs.workers[workerID]
 	if !exists {
-		return fmt.Errorf("worker %s not found", workerID)
+		return fmt.Errorf("%w: %s", ErrWorkerNotFound, workerID)
 	}
 
 	worker.stop()
@@ -218,10 +281,10 @@ func (qs *QueueService) Stop() error {
 func (qs *QueueService) HealthCheck(ctx context.Context) error {
 	qs.logger.DebugContext(ctx, "Performing health check")
 
-	// 检查Redis连接
+	// 检查队列后端连接
 	_, err := qs.repo.GetStats(ctx)
 	if err != nil {
-		return fmt.Errorf("redis health check failed: %w", err)
+		return fmt.Errorf("queue backend health check failed: %w", err)
 	}
 
 	return nil
@@ -370,6 +433,12 @@ func (w *Worker) processTasks() {
 	ctx, cancel := context.WithTimeout(w.service.ctx, 30*time.Second)
 	defer cancel()
 
+	// 队列暂停时不拉取新任务
+	if w.service.queuePaused(ctx) {
+		time.Sleep(pauseCheckInterval)
+		return
+	}
+
 	// 获取待处理任务
 	tasks, err := w.service.repo.GetTasks(ctx, w.ID, 5)
 	if err != nil {
@@ -382,8 +451,14 @@ func (w *Worker) processTasks() {
 		return
 	}
 
-	// 处理每个任务
+	// 处理每个任务，已过期的任务直接丢弃，受速率限制的任务先取令牌
 	for _, task := range tasks {
+		if w.service.dropExpired(ctx, task) {
+			continue
+		}
+		if !w.throttle(ctx, task) {
+			continue
+		}
 		w.processTask(ctx, task)
 	}
 
@@ -393,21 +468,29 @@ func (w *Worker) processTasks() {
 	}
 }
 
-// processTask 处理单个任务
+// processTask 处理单个任务，调用其他服务时透传任务所属租户
 func (w *Worker) processTask(ctx context.Context, task *models.Task) {
+	ctx = models.WithTenant(ctx, task.TenantOrDefault())
 	w.logger.InfoContext(ctx, "Processing task",
 		"worker_id", w.ID,
 		"task_id", task.ID,
-		"task_type", task.Type)
-
-	// 更新任务状态
-	task.Status = "processing"
-	task.UpdatedAt = time.Now()
+		"task_type", task.Type,
+		"tenant", task.TenantOrDefault())
+
+	// 更新任务状态，进度只统计本次执行
+	startedAt := time.Now()
+	task.Status = models.TaskStatusRunning
+	task.StartedAt = &startedAt
+	task.UpdatedAt = startedAt
+	task.WorkerID = w.ID
+	task.Progress = nil
+	w.service.trackJob(ctx, task)
+	stopHeartbeat := w.service.startHeartbeat(ctx, task)
 
 	// 根据任务类型处理
 	var err error
 	switch task.Type {
-	case "file_deletion":
+	case models.TaskTypeFileDeletion:
 		err = w.processFileDeletion(ctx, task)
 	case "metadata_cleanup":
 		err = w.processMetadataCleanup(ctx, task)
@@ -416,6 +499,8 @@ func (w *Worker) processTask(ctx context.Context, task *models.Task) {
 	default:
 		err = fmt.Errorf("unknown task type: %s", task.Type)
 	}
+	stopHeartbeat()
+	w.service.observeTask(ctx, w.ID, task.Type, err, time.Since(startedAt))
 
 	if err != nil {
 		w.logger.ErrorContext(ctx, "Task processing failed",
@@ -423,9 +508,9 @@ func (w *Worker) processTask(ctx context.Context, task *models.Task) {
 			"task_id", task.ID,
 			"error", err)
 
-		// 拒绝任务（重试或标记失败）
-		if rejectErr := w.service.repo.RejectTask(ctx, task); rejectErr != nil {
-			w.logger.ErrorContext(ctx, "Failed to reject task", "task_id", task.ID, "error", rejectErr)
+		// 按重试策略退避后重试，次数用尽时移入死信队列
+		if retryErr := w.service.retryTask(ctx, task, err); retryErr != nil {
+			w.logger.ErrorContext(ctx, "Failed to retry task", "task_id", task.ID, "error", retryErr)
 		}
 		return
 	}
@@ -436,6 +521,13 @@ func (w *Worker) processTask(ctx context.Context, task *models.Task) {
 		return
 	}
 
+	completedAt := time.Now()
+	task.Status = models.TaskStatusCompleted
+	task.CompletedAt = &completedAt
+	w.reportProgress(ctx, task, 100)
+	w.service.notifyJob(ctx, task)
+	w.service.enqueueFollowUps(ctx, task)
+
 	w.logger.InfoContext(ctx, "Task completed successfully",
 		"worker_id", w.ID,
 		"task_id", task.ID)
@@ -445,25 +537,21 @@ func (w *Worker) processTask(ctx context.Context, task *models.Task) {
 func (w *Worker) processFileDeletion(ctx context.Context, task *models.Task) error {
 	w.logger.InfoContext(ctx, "Processing file deletion", "task_id", task.ID)
 
-	// 解析任务数据
-	if task.Data == nil {
-		return fmt.Errorf("task data is nil")
-	}
-
-	bucket, exists := task.Data["bucket"]
-	if !exists {
-		return fmt.Errorf("bucket not specified in task data")
+	// 解析任务数据，无效的payload不重试
+	var payload models.FileDeletionPayload
+	if err := task.DecodeData(&payload); err != nil {
+		return err
 	}
-
-	key, exists := task.Data["key"]
-	if !exists {
-		return fmt.Errorf("key not specified in task data")
+	if err := payload.Validate(); err != nil {
+		return err
 	}
+	bucket, key := payload.Bucket, payload.Key
 
 	w.logger.InfoContext(ctx, "Deleting file",
 		"bucket", bucket,
 		"key", key,
 		"task_id", task.ID)
+	w.reportProgress(ctx, task, 50)
 
 	// 这里应该调用存储服务来删除文件
 	// 由于我们还没有实现存储服务的接口调用，先模拟处理
@@ -477,6 +565,8 @@ func (w *Worker) processFileDeletion(ctx context.Context, task *models.Task) err
 		"key", key,
 		"task_id", task.ID)
 
+	// 后续任务可以使用被删除的对象
+	task.Output = map[string]interface{}{"bucket": bucket, "key": key}
 	return nil
 }
 
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"sort"
+	"sync"
+	"time"
+)
+
+// rateLimitMaxWait worker为取令牌最多等待的时间，需要等待更久的任务放回延迟暂存区，不占用worker
+const rateLimitMaxWait = 10 * time.Second
+
+// typeBucket 任务类型的令牌桶，tokens为负表示已被预约的未来令牌
+type typeBucket struct {
+	limit      models.TaskRateLimit
+	tokens     float64
+	lastRefill time.Time
+}
+
+// refill 按经过的时间补充令牌，不超过桶容量
+func (b *typeBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.RatePerSecond)
+		b.lastRefill = now
+	}
+}
+
+// typeLimiter 按任务类型限流，同一实例的所有worker共享
+type typeLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*typeBucket
+}
+
+// set 设置任务类型的速率限制，令牌桶重置为满
+func (l *typeLimiter) set(limit models.TaskRateLimit, now time.Time) models.TaskRateLimit {
+	if limit.Burst == 0 {
+		limit.Burst = int(math.Ceil(limit.RatePerSecond))
+	}
+	limit.UpdatedAt = now
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buckets == nil {
+		l.buckets = make(map[string]*typeBucket)
+	}
+	l.buckets[limit.Type] = &typeBucket{limit: limit, tokens: float64(limit.Burst), lastRefill: now}
+	return limit
+}
+
+// remove 取消任务类型的速率限制，不存在时返回false
+func (l *typeLimiter) remove(taskType string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.buckets[taskType]; !ok {
+		return false
+	}
+	delete(l.buckets, taskType)
+	return true
+}
+
+// list 按任务类型排序列出速率限制
+func (l *typeLimiter) list() []models.TaskRateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limits := make([]models.TaskRateLimit, 0, len(l.buckets))
+	for _, bucket := range l.buckets {
+		limits = append(limits, bucket.limit)
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Type < limits[j].Type })
+	return limits
+}
+
+// reserve 为任务类型预约一个令牌，返回令牌可用前需要等待的时间；没有限制的类型返回0
+func (l *typeLimiter) reserve(taskType string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bucket, ok := l.buckets[taskType]
+	if !ok {
+		return 0
+	}
+
+	bucket.refill(now)
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.tokens / bucket.limit.RatePerSecond * float64(time.Second))
+}
+
+// cancel 归还预约的令牌
+func (l *typeLimiter) cancel(taskType string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if bucket, ok := l.buckets[taskType]; ok {
+		bucket.tokens = math.Min(float64(bucket.limit.Burst), bucket.tokens+1)
+	}
+}
+
+// SetRateLimits 设置启动时的速率限制
+func (qs *QueueService) SetRateLimits(limits map[string]models.TaskRateLimit) {
+	now := time.Now()
+	for taskType, limit := range limits {
+		limit.Type = taskType
+		qs.limiter.set(limit, now)
+	}
+}
+
+// ListRateLimits 列出所有任务类型的速率限制
+func (qs *QueueService) ListRateLimits() []models.TaskRateLimit {
+	return qs.limiter.list()
+}
+
+// SetRateLimit 创建或更新任务类型的速率限制，立即生效
+func (qs *QueueService) SetRateLimit(ctx context.Context, limit models.TaskRateLimit) (*models.TaskRateLimit, error) {
+	if limit.Type == "" {
+		return nil, fmt.Errorf("%w: rate limit task type is required", models.ErrInvalidArgument)
+	}
+	if err := limit.Validate(); err != nil {
+		return nil, err
+	}
+
+	stored := qs.limiter.set(limit, time.Now())
+	qs.logger.Info(ctx, "Task rate limit set",
+		observability.String("type", stored.Type),
+		observability.Float64("rate_per_second", stored.RatePerSecond),
+		observability.Int("burst", stored.Burst))
+	return &stored, nil
+}
+
+// DeleteRateLimit 取消任务类型的速率限制
+func (qs *QueueService) DeleteRateLimit(ctx context.Context, taskType string) error {
+	if !qs.limiter.remove(taskType) {
+		return fmt.Errorf("%w: %s", ErrRateLimitNotFound, taskType)
+	}
+	qs.logger.Info(ctx, "Task rate limit removed",
+		observability.String("type", taskType))
+	return nil
+}
+
+// throttle 处理任务前按任务类型的速率限制取令牌，返回false表示本次不处理该任务
+//
+// 需要等待超过rateLimitMaxWait或ctx剩余时间的任务放回延迟暂存区，到期后重新投递，不计入执行次数；
+// 调度器未运行时只能在worker中等待。ctx结束时任务保持未确认，由消费者组重新投递。
+func (w *Worker) throttle(ctx context.Context, task *models.Task) bool {
+	wait := w.service.limiter.reserve(task.Type, time.Now())
+	if wait == 0 {
+		return true
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	if (wait > rateLimitMaxWait || hasDeadline && time.Until(deadline) < wait) && w.service.schedulerRunning.Load() {
+		w.service.limiter.cancel(task.Type)
+		if err := w.service.repo.RetryTask(ctx, task, time.Now().Add(wait)); err != nil {
+			w.logger.ErrorContext(ctx, "Failed to defer rate limited task", "task_id", task.ID, "error", err)
+			return false
+		}
+		w.logger.DebugContext(ctx, "Task deferred by rate limit",
+			"task_id", task.ID,
+			"task_type", task.Type,
+			"retry_at", task.ScheduledAt)
+		return false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		w.service.limiter.cancel(task.Type)
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mocks3/shared/models"
+)
+
+// QueueRegistry 按名称管理多个队列，每个队列有独立的任务流、worker池和重试策略
+//
+// 队列在启动时注册，之后只读，不需要加锁。
+type QueueRegistry struct {
+	defaultName string
+	names       []string // 按注册顺序，默认队列在前
+	queues      map[string]*QueueService
+	taskTypes   map[string]string // 任务类型 -> 未指定队列时进入的队列
+}
+
+// NewQueueRegistry 创建队列注册表，未指定队列的请求使用默认队列
+func NewQueueRegistry(defaultName string, defaultQueue *QueueService) *QueueRegistry {
+	return &QueueRegistry{
+		defaultName: defaultName,
+		names:       []string{defaultName},
+		queues:      map[string]*QueueService{defaultName: defaultQueue},
+		taskTypes:   make(map[string]string),
+	}
+}
+
+// Register 注册命名队列，taskTypes中的任务在入队时未指定队列时进入该队列
+func (r *QueueRegistry) Register(name string, qs *QueueService, taskTypes []string) {
+	if _, exists := r.queues[name]; !exists {
+		r.names = append(r.names, name)
+	}
+	r.queues[name] = qs
+	for _, taskType := range taskTypes {
+		r.taskTypes[taskType] = name
+	}
+}
+
+// Default 默认队列
+func (r *QueueRegistry) Default() *QueueService {
+	return r.queues[r.defaultName]
+}
+
+// Names 按注册顺序列出队列名称
+func (r *QueueRegistry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// Get 获取队列，名称为空时为默认队列
+func (r *QueueRegistry) Get(name string) (*QueueService, error) {
+	if name == "" {
+		name = r.defaultName
+	}
+	qs, ok := r.queues[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrQueueNotFound, name)
+	}
+	return qs, nil
+}
+
+// Route 选择任务进入的队列并设置task.Queue：优先使用任务指定的队列，其次按任务类型，最后为默认队列
+func (r *QueueRegistry) Route(task *models.Task) (*QueueService, error) {
+	name := task.Queue
+	if name == "" {
+		name = r.taskTypes[task.Type]
+	}
+	if name == "" {
+		name = r.defaultName
+	}
+	qs, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	task.Queue = name
+	return qs, nil
+}
+
+// AddTask 添加任务到路由到的队列
+func (r *QueueRegistry) AddTask(ctx context.Context, task *models.Task) error {
+	qs, err := r.Route(task)
+	if err != nil {
+		return err
+	}
+	return qs.AddTask(ctx, task)
+}
+
+// AddTasks 批量添加任务，按路由到的队列分组后分别入队，返回与tasks一一对应的错误
+//
+// 任务数上限按默认队列的配置检查。
+func (r *QueueRegistry) AddTasks(ctx context.Context, tasks []*models.Task) ([]error, error) {
+	if err := r.Default().ValidateBatchSize(len(tasks)); err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(tasks))
+	groups := make(map[*QueueService][]int)
+	var order []*QueueService
+	for i, task := range tasks {
+		qs, err := r.Route(task)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if _, ok := groups[qs]; !ok {
+			order = append(order, qs)
+		}
+		groups[qs] = append(groups[qs], i)
+	}
+
+	for _, qs := range order {
+		indexes := groups[qs]
+		group := make([]*models.Task, len(indexes))
+		for j, i := range indexes {
+			group[j] = tasks[i]
+		}
+		groupErrs, err := qs.AddTasks(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range indexes {
+			errs[i] = groupErrs[j]
+		}
+	}
+	return errs, nil
+}
+
+// GetTask 在所有队列中查找任务
+func (r *QueueRegistry) GetTask(ctx context.Context, taskID string) (*models.Task, error) {
+	for _, name := range r.names {
+		task, err := r.queues[name].GetTask(ctx, taskID)
+		if err == nil {
+			return task, nil
+		}
+		if !errors.Is(err, models.ErrTaskNotFound) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", models.ErrTaskNotFound, taskID)
+}
+
+// GetJob 在所有队列中查找任务执行状态
+func (r *QueueRegistry) GetJob(ctx context.Context, jobID string) (*models.JobStatus, error) {
+	_, job, err := r.findJob(ctx, jobID)
+	return job, err
+}
+
+// ReportProgress 更新任务所在队列中正在执行的任务的进度
+func (r *QueueRegistry) ReportProgress(ctx context.Context, jobID string, progress int) (*models.JobStatus, error) {
+	qs, _, err := r.findJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return qs.ReportProgress(ctx, jobID, progress)
+}
+
+// findJob 返回任务所在的队列和执行状态
+func (r *QueueRegistry) findJob(ctx context.Context, jobID string) (*QueueService, *models.JobStatus, error) {
+	for _, name := range r.names {
+		qs := r.queues[name]
+		job, err := qs.GetJob(ctx, jobID)
+		if err == nil {
+			return qs, job, nil
+		}
+		if !errors.Is(err, ErrJobNotFound) {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+}
+
+// QueueStates 列出所有队列的运行状态
+func (r *QueueRegistry) QueueStates(ctx context.Context) ([]*models.QueueState, error) {
+	var states []*models.QueueState
+	for _, name := range r.names {
+		queueStates, err := r.queues[name].QueueStates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, queueStates...)
+	}
+	return states, nil
+}
+
+// PauseQueue 暂停指定名称的队列
+func (r *QueueRegistry) PauseQueue(ctx context.Context, name, reason string) (*models.QueueState, error) {
+	qs, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return qs.PauseQueue(ctx, name, reason)
+}
+
+// ResumeQueue 恢复指定名称的队列
+func (r *QueueRegistry) ResumeQueue(ctx context.Context, name string) (*models.QueueState, error) {
+	qs, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return qs.ResumeQueue(ctx, name)
+}
+
+// HealthCheck 检查所有队列的存储后端
+func (r *QueueRegistry) HealthCheck(ctx context.Context) error {
+	for _, name := range r.names {
+		if err := r.queues[name].HealthCheck(ctx); err != nil {
+			return fmt.Errorf("queue %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Stop 停止所有队列，返回遇到的所有错误
+func (r *QueueRegistry) Stop() error {
+	var errs []error
+	for _, name := range r.names {
+		if err := r.queues[name].Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("queue %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"mocks3/services/queue/internal/config"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"time"
+)
+
+// defaultRetryPolicy 未调用SetRetryPolicies时使用的重试策略
+var defaultRetryPolicy = config.RetryPolicy{
+	MaxAttempts:  3,
+	BackoffMs:    1000,
+	MaxBackoffMs: 60000,
+	Multiplier:   2,
+	Jitter:       0.2,
+}
+
+// SetRetryPolicies 设置默认重试策略和按任务类型覆盖的策略
+func (qs *QueueService) SetRetryPolicies(defaultPolicy config.RetryPolicy, policies map[string]config.RetryPolicy) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qs.retryPolicy = defaultPolicy
+	qs.retryPolicies = policies
+}
+
+// RetryPolicyFor 获取任务类型的重试策略
+func (qs *QueueService) RetryPolicyFor(taskType string) config.RetryPolicy {
+	qs.mu.RLock()
+	defer qs.mu.RUnlock()
+	if policy, ok := qs.retryPolicies[taskType]; ok {
+		return policy
+	}
+	return qs.retryPolicy
+}
+
+// retryTask 记录失败原因和执行次数，按任务类型的重试策略退避后重新入队，用尽次数或payload无效时移入死信队列
+func (qs *QueueService) retryTask(ctx context.Context, task *models.Task, cause error) error {
+	task.RetryCount++
+	task.Error = cause.Error()
+
+	policy := qs.RetryPolicyFor(task.Type)
+	if task.RetryCount >= policy.MaxAttempts || errors.Is(cause, models.ErrInvalidPayload) {
+		if err := qs.repo.DeadLetterTask(ctx, task); err != nil {
+			return err
+		}
+		qs.trackJob(ctx, task)
+		qs.notifyJob(ctx, task)
+		qs.logger.Warn(ctx, "Task moved to dead letter queue",
+			observability.String("task_id", task.ID),
+			observability.String("type", task.Type),
+			observability.Int("attempts", task.RetryCount))
+		return nil
+	}
+
+	// 调度器未运行时延迟暂存区中的任务不会到期入队，只能立即重试
+	delay := time.Duration(0)
+	if qs.schedulerRunning.Load() {
+		delay = retryBackoff(policy, task.RetryCount)
+	}
+	if err := qs.repo.RetryTask(ctx, task, time.Now().Add(delay)); err != nil {
+		return err
+	}
+	qs.trackJob(ctx, task)
+
+	qs.logger.Info(ctx, "Task scheduled for retry",
+		observability.String("task_id", task.ID),
+		observability.Int("attempts", task.RetryCount),
+		observability.Int("max_attempts", policy.MaxAttempts),
+		observability.String("retry_at", task.ScheduledAt.Format(time.RFC3339Nano)))
+	return nil
+}
+
+// retryBackoff 第retry次重试前的等待时间：backoff * multiplier^(retry-1)，不超过上限，再加±jitter的随机抖动
+func retryBackoff(policy config.RetryPolicy, retry int) time.Duration {
+	delay := float64(policy.GetBackoff()) * math.Pow(policy.Multiplier, float64(retry-1))
+	if maxDelay := float64(policy.GetMaxBackoff()); delay > maxDelay {
+		delay = maxDelay
+	}
+	if policy.Jitter > 0 {
+		delay += delay * policy.Jitter * (rand.Float64()*2 - 1)
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"time"
+)
+
+// MaxTaskDelay 延迟任务最远可见时间
+const MaxTaskDelay = 365 * 24 * time.Hour
+
+// promoteBatchSize 每次移入任务流的最大延迟任务数
+const promoteBatchSize = 100
+
+// scheduleTask 将任务放入延迟暂存区
+func (qs *QueueService) scheduleTask(ctx context.Context, task *models.Task) error {
+	if task.ScheduledAt.Sub(task.CreatedAt) > MaxTaskDelay {
+		return fmt.Errorf("%w: task cannot be delayed more than %s", models.ErrInvalidArgument, MaxTaskDelay)
+	}
+
+	if err := qs.repo.ScheduleTask(ctx, task); err != nil {
+		qs.logger.Error(ctx, "Failed to schedule task",
+			observability.String("error", err.Error()),
+			observability.String("task_id", task.ID))
+		return fmt.Errorf("failed to add task: %w", err)
+	}
+	task.Status = models.TaskStatusScheduled
+	qs.trackJob(ctx, task)
+
+	qs.logger.Info(ctx, "Task scheduled",
+		observability.String("task_id", task.ID),
+		observability.String("scheduled_at", task.ScheduledAt.Format(time.RFC3339)))
+	return nil
+}
+
+// PromoteDueTasks 将到期的延迟任务移入任务流，返回移动的任务数
+func (qs *QueueService) PromoteDueTasks(ctx context.Context) (int64, error) {
+	var total int64
+	for {
+		promoted, err := qs.repo.PromoteDueTasks(ctx, time.Now(), promoteBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += promoted
+
+		if promoted < promoteBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		qs.logger.Info(ctx, "Promoted due tasks", observability.Int64("promoted", total))
+	}
+	return total, nil
+}
+
+// StartScheduler 启动后台任务，按interval放回到期的租约并将到期的延迟任务移入任务流，随服务停止
+//
+// 移动由Redis脚本原子完成，多个实例同时运行时每个任务只会入队一次。
+func (qs *QueueService) StartScheduler(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	qs.schedulerRunning.Store(true)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-qs.ctx.Done():
+				return
+			case <-ticker.C:
+				// 先放回到期的租约，使其在本次一起移入任务流
+				if _, err := qs.ExpireLeases(qs.ctx); err != nil && qs.ctx.Err() == nil {
+					qs.logger.Warn(qs.ctx, "Failed to expire task leases",
+						observability.String("error", err.Error()))
+				}
+				if _, err := qs.PromoteDueTasks(qs.ctx); err != nil && qs.ctx.Err() == nil {
+					qs.logger.Warn(qs.ctx, "Failed to promote due tasks",
+						observability.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+}
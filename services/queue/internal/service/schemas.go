@@ -0,0 +1,133 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"strings"
+	"time"
+)
+
+// GetTaskSchema 获取任务类型注册的payload schema
+func (qs *QueueService) GetTaskSchema(ctx context.Context, taskType string) (*models.TaskSchema, error) {
+	schema, err := qs.repo.GetTaskSchema(ctx, taskType)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTaskSchemaNotFound, taskType)
+	}
+	return schema, nil
+}
+
+// ListTaskSchemas 列出所有任务类型的payload schema
+func (qs *QueueService) ListTaskSchemas(ctx context.Context) ([]*models.TaskSchema, error) {
+	return qs.repo.ListTaskSchemas(ctx)
+}
+
+// SetTaskSchema 注册任务类型的payload schema，只影响之后的入队，已入队的任务不回溯校验
+func (qs *QueueService) SetTaskSchema(ctx context.Context, schema *models.TaskSchema) error {
+	if strings.TrimSpace(schema.Type) == "" {
+		return fmt.Errorf("%w: task type cannot be empty", models.ErrInvalidArgument)
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, schema.Schema); err != nil {
+		return fmt.Errorf("%w: invalid schema: %v", models.ErrInvalidArgument, err)
+	}
+	if compacted.Len() > models.MaxTaskSchemaSize {
+		return fmt.Errorf("%w: schema exceeds %d bytes", models.ErrInvalidArgument, models.MaxTaskSchemaSize)
+	}
+	if _, err := qs.schemas.Get(compacted.Bytes()); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+	}
+	schema.Schema = compacted.Bytes()
+	schema.UpdatedAt = time.Now()
+
+	if err := qs.repo.PutTaskSchema(ctx, schema); err != nil {
+		qs.logger.Error(ctx, "Failed to set task schema",
+			observability.String("type", schema.Type),
+			observability.String("error", err.Error()))
+		return err
+	}
+
+	qs.logger.Info(ctx, "Task schema set",
+		observability.String("type", schema.Type),
+		observability.Int("size", len(schema.Schema)))
+	return nil
+}
+
+// DeleteTaskSchema 删除任务类型的payload schema
+func (qs *QueueService) DeleteTaskSchema(ctx context.Context, taskType string) error {
+	deleted, err := qs.repo.DeleteTaskSchema(ctx, taskType)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: %s", ErrTaskSchemaNotFound, taskType)
+	}
+
+	qs.logger.Info(ctx, "Task schema removed", observability.String("type", taskType))
+	return nil
+}
+
+// validatePayload 按任务类型注册的schema校验任务data，不符合时返回*models.TaskPayloadError
+//
+// schemas缓存本次调用已加载的schema（批量入队时同一类型只加载一次），可为nil。
+func (qs *QueueService) validatePayload(ctx context.Context, task *models.Task, schemas map[string]*models.TaskSchema) error {
+	schema, ok := schemas[task.Type]
+	if !ok {
+		var err error
+		if schema, err = qs.repo.GetTaskSchema(ctx, task.Type); err != nil {
+			return fmt.Errorf("failed to load task schema: %w", err)
+		}
+		if schemas != nil {
+			schemas[task.Type] = schema
+		}
+	}
+	if schema == nil {
+		return nil
+	}
+
+	compiled, err := qs.schemas.Get(schema.Schema)
+	if err != nil {
+		return fmt.Errorf("failed to compile task schema: %w", err)
+	}
+	// 按JSON文档校验，与worker解码时看到的数据一致
+	var document interface{}
+	data, err := json.Marshal(task.Data)
+	if err == nil {
+		err = json.Unmarshal(data, &document)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidPayload, err)
+	}
+	if document == nil {
+		document = map[string]interface{}{}
+	}
+
+	violations := compiled.Validate(document)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	payloadErr := &models.TaskPayloadError{
+		Type:       task.Type,
+		Violations: make([]models.SchemaViolation, 0, len(violations)),
+	}
+	for _, violation := range violations {
+		payloadErr.Violations = append(payloadErr.Violations, models.SchemaViolation{
+			Path:    violation.Path,
+			Keyword: violation.Keyword,
+			Message: violation.Message,
+		})
+	}
+	qs.logger.Warn(ctx, "Task rejected by payload schema",
+		observability.String("task_id", task.ID),
+		observability.String("type", task.Type),
+		observability.Int("violations", len(violations)))
+	return payloadErr
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"regexp"
+)
+
+// 主题读取数量限制
+const (
+	defaultTopicReadCount = 100
+	maxTopicReadCount     = 1000
+)
+
+var (
+	topicNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+	streamIDPattern  = regexp.MustCompile(`^\d+(-\d+)?$`)
+)
+
+// PublishEvent 发布事件到主题
+// 主题是只追加的事件流，payload不做任何校验，以便下游消费者能收到畸形事件
+func (qs *QueueService) PublishEvent(ctx context.Context, topic string, payload []byte) (*models.TopicEvent, error) {
+	if !topicNamePattern.MatchString(topic) {
+		return nil, fmt.Errorf("%w: topic name %s", models.ErrInvalidArgument, topic)
+	}
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("%w: event payload is required", models.ErrInvalidArgument)
+	}
+
+	event, err := qs.repo.PublishEvent(ctx, topic, string(payload))
+	if err != nil {
+		qs.logger.Error(ctx, "Failed to publish event",
+			observability.String("topic", topic),
+			observability.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	qs.logger.Debug(ctx, "Event published",
+		observability.String("topic", topic),
+		observability.String("event_id", event.ID))
+	return event, nil
+}
+
+// ReadEvents 读取主题中afterID之后的事件
+func (qs *QueueService) ReadEvents(ctx context.Context, topic, afterID string, count int) ([]*models.TopicEvent, error) {
+	if !topicNamePattern.MatchString(topic) {
+		return nil, fmt.Errorf("%w: topic name %s", models.ErrInvalidArgument, topic)
+	}
+	if afterID != "" && !streamIDPattern.MatchString(afterID) {
+		return nil, fmt.Errorf("%w: event id %s", models.ErrInvalidArgument, afterID)
+	}
+	if count <= 0 {
+		count = defaultTopicReadCount
+	}
+	if count > maxTopicReadCount {
+		count = maxTopicReadCount
+	}
+
+	events, err := qs.repo.ReadEvents(ctx, topic, afterID, int64(count))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+	return events, nil
+}
+
+// GetTopicLength 获取主题中的事件数
+func (qs *QueueService) GetTopicLength(ctx context.Context, topic string) (int64, error) {
+	if !topicNamePattern.MatchString(topic) {
+		return 0, fmt.Errorf("%w: topic name %s", models.ErrInvalidArgument, topic)
+	}
+	return qs.repo.GetTopicLength(ctx, topic)
+}
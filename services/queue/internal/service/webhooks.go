@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mocks3/services/queue/internal/config"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"net/http"
+	"time"
+)
+
+// 任务结束通知的请求头
+const (
+	headerWebhookEvent   = "X-Mocks3-Event"
+	headerWebhookJobID   = "X-Mocks3-Job-ID"
+	headerWebhookAttempt = "X-Mocks3-Attempt"
+)
+
+// defaultWebhookConfig 未调用SetWebhookConfig时的投递配置
+var defaultWebhookConfig = config.WebhookConfig{
+	MaxAttempts:  5,
+	BackoffMs:    1000,
+	MaxBackoffMs: 30000,
+	TimeoutMs:    5000,
+}
+
+// webhookNotifier 将任务结束通知POST到任务的回调地址，失败时退避重试
+type webhookNotifier struct {
+	config config.WebhookConfig
+	client *http.Client
+}
+
+// newWebhookNotifier 创建通知投递器
+func newWebhookNotifier(cfg config.WebhookConfig) *webhookNotifier {
+	return &webhookNotifier{
+		config: cfg,
+		client: &http.Client{Timeout: cfg.GetTimeout()},
+	}
+}
+
+// SetWebhookConfig 设置任务结束通知的投递配置
+func (qs *QueueService) SetWebhookConfig(cfg config.WebhookConfig) {
+	qs.webhooks = newWebhookNotifier(cfg)
+}
+
+// notifyJob 任务完成、最终失败或过期时异步通知回调地址，未设置回调地址时不通知
+//
+// 投递在后台进行，不阻塞worker；服务停止时放弃尚未成功的投递。
+func (qs *QueueService) notifyJob(ctx context.Context, task *models.Task) {
+	if task.CallbackURL == "" {
+		return
+	}
+
+	event := models.JobEventDone
+	switch task.JobState() {
+	case models.JobStateFailed:
+		event = models.JobEventFailed
+	case models.JobStateExpired:
+		event = models.JobEventExpired
+	}
+	body, err := json.Marshal(&models.JobEvent{
+		Event:     event,
+		Job:       models.NewJobStatus(task),
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		qs.logger.Error(ctx, "Failed to marshal job event",
+			observability.String("task_id", task.ID),
+			observability.String("error", err.Error()))
+		return
+	}
+
+	go qs.deliverWebhook(task.ID, task.CallbackURL, event, body)
+}
+
+// deliverWebhook 投递通知直到成功、遇到不可重试的响应或用尽次数
+func (qs *QueueService) deliverWebhook(jobID, callbackURL, event string, body []byte) {
+	notifier := qs.webhooks
+	backoff := notifier.config.GetBackoff()
+
+	var err error
+	for attempt := 1; attempt <= notifier.config.MaxAttempts; attempt++ {
+		var retryable bool
+		if retryable, err = notifier.post(qs.ctx, callbackURL, event, jobID, attempt, body); err == nil {
+			qs.logger.Debug(qs.ctx, "Job event delivered",
+				observability.String("task_id", jobID),
+				observability.String("event", event),
+				observability.Int("attempt", attempt))
+			return
+		}
+		if !retryable || attempt == notifier.config.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-qs.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if maxBackoff := notifier.config.GetMaxBackoff(); backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	qs.logger.Warn(qs.ctx, "Failed to deliver job event",
+		observability.String("task_id", jobID),
+		observability.String("event", event),
+		observability.String("callback_url", callbackURL),
+		observability.String("error", err.Error()))
+}
+
+// post 发送一次通知，返回失败是否可重试：网络错误、408、429和5xx可重试，其他非2xx响应不重试
+func (n *webhookNotifier) post(ctx context.Context, callbackURL, event, jobID string, attempt int, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerWebhookEvent, event)
+	req.Header.Set(headerWebhookJobID, jobID)
+	req.Header.Set(headerWebhookAttempt, fmt.Sprint(attempt))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("do request: %w", err)
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return true, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+)
+
+// SetNextEnqueuer 设置后续任务的入队方式，多队列时由队列注册表按任务类型路由到对应队列
+//
+// 未设置时后续任务进入本队列。
+func (qs *QueueService) SetNextEnqueuer(enqueue func(ctx context.Context, task *models.Task) error) {
+	qs.enqueueNext = enqueue
+}
+
+// enqueueFollowUps 任务完成后入队其后续任务，后续任务的数据合并任务输出
+//
+// 任务已经完成，入队失败时只记录日志，不影响任务状态。
+func (qs *QueueService) enqueueFollowUps(ctx context.Context, task *models.Task) {
+	if len(task.Next) == 0 {
+		return
+	}
+
+	enqueue := qs.enqueueNext
+	if enqueue == nil {
+		enqueue = qs.AddTask
+	}
+	for _, next := range task.FollowUps() {
+		if err := enqueue(ctx, next); err != nil {
+			var duplicateErr *models.DuplicateTaskError
+			if errors.As(err, &duplicateErr) {
+				continue
+			}
+			qs.logger.Error(ctx, "Failed to enqueue next task",
+				observability.String("task_id", task.ID),
+				observability.String("next_task_id", next.ID),
+				observability.String("next_type", next.Type),
+				observability.String("error", err.Error()))
+			continue
+		}
+		qs.logger.Info(ctx, "Next task enqueued",
+			observability.String("task_id", task.ID),
+			observability.String("next_task_id", next.ID),
+			observability.String("workflow_id", next.WorkflowID))
+	}
+}
@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"mocks3/services/storage/internal/config"
 	"mocks3/services/storage/internal/handler"
 	"mocks3/services/storage/internal/service"
+	"mocks3/shared/client"
 	"mocks3/shared/middleware"
+	"mocks3/shared/models"
 	"mocks3/shared/observability"
+	"mocks3/shared/utils"
 	"net/http"
 	"os"
 	"os/signal"
@@ -20,6 +24,9 @@ import (
 func main() {
 	// 加载配置
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// 初始化统一可观测性
 	obsConfig := &observability.Config{
@@ -28,6 +35,15 @@ func main() {
 		Environment:    cfg.Server.Environment,
 		OTLPEndpoint:   "http://localhost:4318",
 		LogLevel:       cfg.LogLevel,
+		Debug:          observability.DebugConfigFromEnv(),
+		Profiling:      observability.ProfilingConfigFromEnv(),
+		AuditLog:       observability.AuditLogConfigFromEnv(),
+		LogSampling:    observability.LogSamplingConfigFromEnv(),
+		LogFile:        observability.LogFileConfigFromEnv(),
+		SLOs: []observability.SLO{
+			observability.AvailabilitySLO("availability", 0.999),
+			observability.LatencySLO("latency", 0.99, 500*time.Millisecond),
+		},
 	}
 
 	obs, err := observability.New(context.Background(), obsConfig)
@@ -38,6 +54,13 @@ func main() {
 
 	loggerInstance := obs.Logger()
 
+	// 初始化全局ID生成器
+	idStrategy, err := utils.ConfigureIDGeneratorFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure ID generator: %v", err)
+	}
+	loggerInstance.Info(context.Background(), "ID generator configured", observability.String("strategy", idStrategy))
+
 	// 初始化Consul管理器
 	consulManager, err := middleware.NewDefaultConsulManager("storage-service")
 	if err != nil {
@@ -49,6 +72,13 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to initialize storage service: %v", err)
 	}
+	storageService.SetMetricCollector(obs.Collector())
+	storageService.SetAuditLogger(obs.AuditLogger())
+
+	// 定期中止过期的分片上传，释放内存中的分片
+	multipartCtx, stopMultipartSweeper := context.WithCancel(context.Background())
+	defer stopMultipartSweeper()
+	storageService.StartMultipartSweeper(multipartCtx)
 
 	// 初始化处理器
 	storageHandler := handler.NewStorageHandler(storageService, loggerInstance)
@@ -82,9 +112,90 @@ func main() {
 	// 添加中间件
 	router.Use(gin.Logger())
 	router.Use(middleware.GinRecoveryMiddleware(middleware.DefaultRecoveryConfig()))
+	// 请求ID：沿用或生成X-Request-ID，日志和出站调用自动带上
+	router.Use(middleware.RequestIDMiddleware())
+	// baggage：读取上游透传的租户、测试运行ID和场景ID，日志和错误注入条件自动带上
+	router.Use(obs.GinBaggageMiddleware())
 	// 使用统一可观测性中间件
 	router.Use(obs.GinMiddleware())
 
+	// 管理接口的修改操作写入审计日志
+	router.Use(obs.AuditLogger().GinAdminMiddleware("/api/v1/admin"))
+
+	// 管理接口鉴权和飞行记录器（鉴权放在限流之前，管理接口不受限流）
+	authorizer, flightRecorder, err := setupAdmin(router, cfg, obs, storageService)
+	if err != nil {
+		log.Fatalf("Failed to set up admin endpoints: %v", err)
+	}
+
+	// 审计事件导出（放在限流之前，被限流的请求同样会产生事件）
+	if cfg.AuditExport.Enabled {
+		auditExporter, err := newAuditExporter(cfg.AuditExport, loggerInstance)
+		if err != nil {
+			log.Fatalf("Failed to initialize audit exporter: %v", err)
+		}
+		auditCtx, stopAudit := context.WithCancel(context.Background())
+		defer stopAudit()
+		auditExporter.Start(auditCtx)
+		router.Use(auditExporter.GinMiddleware())
+		auditExporter.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	// 多租户：解析请求租户，对象按租户隔离存储，调用元数据服务时透传租户
+	if cfg.Tenancy.Enabled {
+		tenantResolver, err := newTenantResolver(cfg.Tenancy, authorizer)
+		if err != nil {
+			log.Fatalf("Failed to initialize tenant resolver: %v", err)
+		}
+		router.Use(tenantResolver.GinMiddleware())
+	}
+
+	// 解析操作者，调用元数据服务时透传供变更审计
+	router.Use(middleware.AuditActorMiddleware(authorizer))
+
+	// 运行时修改日志级别，排查问题时临时切换到debug
+	middleware.NewLogLevelController(loggerInstance).RegisterAdminRoutes(router.Group("/api/v1/admin"))
+
+	// 租户限流
+	if cfg.RateLimit.Enabled {
+		rateLimiter, err := newRateLimiter(cfg.RateLimit)
+		if err != nil {
+			log.Fatalf("Failed to initialize rate limiter: %v", err)
+		}
+		rateLimiter.SetMetricCollector(obs.Collector())
+		if authorizer != nil {
+			rateLimiter.SetAuthorizer(authorizer)
+		}
+		router.Use(rateLimiter.GinMiddleware())
+		rateLimiter.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	// bucket复制模拟
+	if cfg.Replication.Enabled {
+		replicator := service.NewReplicator(cfg.Replication, storageService, loggerInstance)
+		replicator.SetMetricCollector(obs.Collector())
+		if cfg.Replication.MockErrorURL != "" {
+			replicator.SetInjectionChecker(client.NewMockErrorClient(cfg.Replication.MockErrorURL, 5*time.Second))
+		}
+		replicationCtx, stopReplication := context.WithCancel(context.Background())
+		defer stopReplication()
+		replicator.Start(replicationCtx)
+		storageService.SetReplicator(replicator)
+		handler.NewReplicationHandler(replicator, loggerInstance).RegisterRoutes(router.Group("/api/v1/admin"))
+	}
+
+	// 错误注入：每个请求向mock-error查询是否注入错误，放在业务路由之前
+	if cfg.FaultInjection.Enabled {
+		faultInjector, mockErrorClient, checkTimeout, err := newFaultInjector(cfg.FaultInjection, loggerInstance)
+		if err != nil {
+			log.Fatalf("Failed to initialize fault injector: %v", err)
+		}
+		router.Use(faultInjector.GinMiddleware())
+
+		// 调用元数据和第三方服务的出站请求模拟DNS、连接和TLS故障
+		storageService.UseOutboundFaultTransport(mockErrorClient, checkTimeout)
+	}
+
 	// 设置路由
 	storageHandler.RegisterRoutes(router)
 
@@ -131,5 +242,171 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// 等待进行中的事件包上传完成
+	if flightRecorder != nil {
+		flightRecorder.Wait(ctx)
+	}
+
 	loggerInstance.Info(context.Background(), "Storage service stopped")
 }
+
+// newRateLimiter 根据配置创建限流器
+func newRateLimiter(cfg config.RateLimitConfig) (*middleware.RateLimiter, error) {
+	rlConfig := middleware.DefaultRateLimitConfig()
+	if len(cfg.TenantHeaders) > 0 {
+		rlConfig.TenantHeaders = cfg.TenantHeaders
+	}
+	if cfg.IdleTTL != "" {
+		idleTTL, err := time.ParseDuration(cfg.IdleTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit idle_ttl: %w", err)
+		}
+		rlConfig.IdleTTL = idleTTL
+	}
+	if cfg.MaxTenants > 0 {
+		rlConfig.MaxTenants = cfg.MaxTenants
+	}
+
+	plans := make([]*models.RatePlan, 0, len(cfg.Plans))
+	for _, plan := range cfg.Plans {
+		ratePlan := &models.RatePlan{
+			Name:              plan.Name,
+			RequestsPerSecond: plan.RequestsPerSecond,
+			Burst:             plan.Burst,
+			MaxConcurrent:     plan.MaxConcurrent,
+		}
+		if plan.Name == cfg.DefaultPlan {
+			rlConfig.DefaultPlan = ratePlan
+		}
+		plans = append(plans, ratePlan)
+	}
+
+	limiter, err := middleware.NewRateLimiter(rlConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, plan := range plans {
+		if _, err := limiter.SetPlan(plan); err != nil {
+			return nil, err
+		}
+	}
+	for tenant, plan := range cfg.Assignments {
+		if err := limiter.AssignPlan(tenant, plan); err != nil {
+			return nil, err
+		}
+	}
+
+	return limiter, nil
+}
+
+// newAuditExporter 根据配置创建审计导出器
+func newAuditExporter(cfg config.AuditExportConfig, logger *observability.Logger) (*middleware.AuditExporter, error) {
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit export timeout: %w", err)
+	}
+
+	exportConfig := middleware.DefaultAuditExportConfig("storage-service")
+	exportConfig.Topic = cfg.Topic
+	exportConfig.PublishTimeout = timeout
+	if cfg.BufferSize > 0 {
+		exportConfig.BufferSize = cfg.BufferSize
+	}
+
+	exporter, err := middleware.NewAuditExporter(exportConfig, client.NewQueueClient(cfg.QueueURL, timeout), logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MockErrorURL != "" {
+		exporter.SetInjectionChecker(client.NewMockErrorClient(cfg.MockErrorURL, timeout))
+	}
+
+	return exporter, nil
+}
+
+// setupAdmin 安装管理接口鉴权和飞行记录器，未启用的功能返回nil；
+// gin在注册路由时固定处理链，鉴权中间件必须先于任何管理接口注册，否则这些接口不经过鉴权
+func setupAdmin(router *gin.Engine, cfg *config.Config, obs *observability.Observability, store middleware.IncidentStore) (*middleware.Authorizer, *middleware.FlightRecorder, error) {
+	var authorizer *middleware.Authorizer
+	if cfg.RBAC.Enabled {
+		var err error
+		authorizer, err = newAuthorizer(cfg.RBAC)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize authorizer: %w", err)
+		}
+		router.Use(authorizer.GinMiddleware())
+		authorizer.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	// 飞行记录器：保留最近的请求、日志和span，SLO违规或护栏触发时转储事件包到存储bucket
+	var flightRecorder *middleware.FlightRecorder
+	if cfg.FlightRecorder.Enabled {
+		var err error
+		flightRecorder, err = newFlightRecorder(cfg.FlightRecorder, store, obs.Logger())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize flight recorder: %w", err)
+		}
+		flightRecorder.SetMetricCollector(obs.Collector())
+		flightRecorder.Attach(obs)
+		router.Use(obs.GinTracingMiddleware())
+		router.Use(flightRecorder.GinMiddleware())
+		flightRecorder.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	return authorizer, flightRecorder, nil
+}
+
+// newAuthorizer 根据配置创建管理接口鉴权器（/api/v1/admin/*）
+func newAuthorizer(cfg config.RBACConfig) (*middleware.Authorizer, error) {
+	rbacConfig := middleware.DefaultRBACConfig()
+	rbacConfig.Header = cfg.Header
+	for _, key := range cfg.Keys {
+		rbacConfig.Keys = append(rbacConfig.Keys, &models.APIKey{Name: key.Name, Key: key.Key, Role: key.Role, Tenant: key.Tenant})
+	}
+	return middleware.NewAuthorizer(rbacConfig)
+}
+
+// newTenantResolver 根据配置创建租户解析器，authorizer为nil时只从请求头读取租户
+func newTenantResolver(cfg config.TenancyConfig, authorizer *middleware.Authorizer) (*middleware.TenantResolver, error) {
+	tenantConfig := middleware.DefaultTenantConfig()
+	tenantConfig.Header = cfg.Header
+	tenantConfig.DefaultTenant = cfg.DefaultTenant
+	tenantConfig.Required = cfg.Required
+	return middleware.NewTenantResolver(tenantConfig, authorizer)
+}
+
+// newFlightRecorder 根据配置创建飞行记录器
+func newFlightRecorder(cfg config.FlightRecorderConfig, store middleware.IncidentStore, logger *observability.Logger) (*middleware.FlightRecorder, error) {
+	recorderConfig := middleware.DefaultFlightRecorderConfig("storage-service")
+	recorderConfig.Bucket = cfg.Bucket
+	recorderConfig.RequestCapacity = cfg.RequestCapacity
+	recorderConfig.LogCapacity = cfg.LogCapacity
+	recorderConfig.SpanCapacity = cfg.SpanCapacity
+	recorderConfig.SLOWindow = time.Duration(cfg.SLOWindowSecs) * time.Second
+	recorderConfig.MinRequests = cfg.MinRequests
+	recorderConfig.MaxErrorRate = cfg.MaxErrorRate
+	recorderConfig.LatencyThreshold = time.Duration(cfg.LatencyThresholdMs) * time.Millisecond
+	recorderConfig.MaxSlowRate = cfg.MaxSlowRate
+	recorderConfig.Cooldown = time.Duration(cfg.CooldownSecs) * time.Second
+	return middleware.NewFlightRecorder(recorderConfig, store, logger)
+}
+
+// newFaultInjector 根据配置创建错误注入客户端中间件，同时返回查询使用的mock-error客户端和检查超时，供出站请求复用
+func newFaultInjector(cfg config.FaultInjectionConfig, logger *observability.Logger) (*middleware.FaultInjector, *client.MockErrorClient, time.Duration, error) {
+	timeout, err := time.ParseDuration(cfg.CheckTimeout)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("invalid fault injection check timeout: %w", err)
+	}
+
+	injectionConfig := middleware.DefaultFaultInjectionConfig("storage-service")
+	injectionConfig.CheckTimeout = timeout
+	mockErrorClient := client.NewMockErrorClient(cfg.MockErrorURL, timeout)
+	mockErrorClient.SetNamespace(cfg.Namespace)
+	faultInjector, err := middleware.NewFaultInjector(injectionConfig, mockErrorClient, logger)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return faultInjector, mockErrorClient, timeout, nil
+}
@@ -2,16 +2,174 @@ package config
 
 import (
 	"fmt"
+	"mocks3/shared/models"
 	"mocks3/shared/utils"
+	"net/http"
+	"time"
 )
 
 // Config 存储服务配置
 type Config struct {
-	Server     ServerConfig     `yaml:"server" json:"server"`
-	Storage    StorageConfig    `yaml:"storage" json:"storage"`
-	Metadata   MetadataConfig   `yaml:"metadata" json:"metadata"`
-	ThirdParty ThirdPartyConfig `yaml:"third_party" json:"third_party"`
-	LogLevel   string           `yaml:"log_level" json:"log_level"`
+	Server         ServerConfig         `yaml:"server" json:"server"`
+	Storage        StorageConfig        `yaml:"storage" json:"storage"`
+	Metadata       MetadataConfig       `yaml:"metadata" json:"metadata"`
+	ThirdParty     ThirdPartyConfig     `yaml:"third_party" json:"third_party"`
+	RateLimit      RateLimitConfig      `yaml:"rate_limit" json:"rate_limit"`
+	AuditExport    AuditExportConfig    `yaml:"audit_export" json:"audit_export"`
+	Replication    ReplicationConfig    `yaml:"replication" json:"replication"`
+	RBAC           RBACConfig           `yaml:"rbac" json:"rbac"`
+	Tenancy        TenancyConfig        `yaml:"tenancy" json:"tenancy"`
+	FlightRecorder FlightRecorderConfig `yaml:"flight_recorder" json:"flight_recorder"`
+	FaultInjection FaultInjectionConfig `yaml:"fault_injection" json:"fault_injection"`
+	Dedup          DedupConfig          `yaml:"dedup" json:"dedup"`
+	Multipart      MultipartConfig      `yaml:"multipart" json:"multipart"`
+	LogLevel       string               `yaml:"log_level" json:"log_level"`
+
+	// BucketPolicies bucket名 -> 写入策略，"*" 匹配所有bucket
+	BucketPolicies map[string]BucketPolicyConfig `yaml:"bucket_policies" json:"bucket_policies"`
+}
+
+// BucketPolicyWildcard 匹配所有bucket的策略名
+const BucketPolicyWildcard = "*"
+
+// BucketPolicyConfig bucket级默认头与标签策略，写入时合并到对象
+type BucketPolicyConfig struct {
+	Headers map[string]string `yaml:"headers" json:"headers"` // 默认响应头，如Cache-Control
+	Tags    map[string]string `yaml:"tags" json:"tags"`       // 自动附加的标签，如owner=team-x
+	Enforce bool              `yaml:"enforce" json:"enforce"` // true时覆盖请求中的同名值，否则仅补充缺失项
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Enabled       bool              `yaml:"enabled" json:"enabled"`
+	DefaultPlan   string            `yaml:"default_plan" json:"default_plan"`
+	TenantHeaders []string          `yaml:"tenant_headers" json:"tenant_headers"`
+	IdleTTL       string            `yaml:"idle_ttl" json:"idle_ttl"`       // 超过该时间没有请求的租户状态被清理，为空时使用默认值
+	MaxTenants    int               `yaml:"max_tenants" json:"max_tenants"` // 最多保留的租户状态数，0时使用默认值
+	Plans         []RatePlanConfig  `yaml:"plans" json:"plans"`
+	Assignments   map[string]string `yaml:"assignments" json:"assignments"` // 租户或apikey:<API key名称> -> plan
+}
+
+// RatePlanConfig 限流套餐配置
+type RatePlanConfig struct {
+	Name              string  `yaml:"name" json:"name"`
+	RequestsPerSecond float64 `yaml:"requests_per_second" json:"requests_per_second"`
+	Burst             int     `yaml:"burst" json:"burst"`
+	MaxConcurrent     int     `yaml:"max_concurrent" json:"max_concurrent"`
+}
+
+// RBACConfig 管理接口鉴权配置
+type RBACConfig struct {
+	Enabled bool           `yaml:"enabled" json:"enabled"`
+	Header  string         `yaml:"header" json:"header"`
+	Keys    []APIKeyConfig `yaml:"keys" json:"keys"`
+}
+
+// FaultInjectionConfig 错误注入配置：每个请求向mock-error查询是否注入错误并执行命中的动作
+type FaultInjectionConfig struct {
+	Enabled      bool   `yaml:"enabled" json:"enabled"`
+	MockErrorURL string `yaml:"mock_error_url" json:"mock_error_url"`
+	Namespace    string `yaml:"namespace" json:"namespace"`         // 只评估该命名空间的规则，为空时为default
+	CheckTimeout string `yaml:"check_timeout" json:"check_timeout"` // 单次检查超时，超时或失败时不注入
+}
+
+// FlightRecorderConfig 飞行记录器配置：保留最近的请求、日志和span，SLO违规或护栏触发时转储到存储bucket
+type FlightRecorderConfig struct {
+	Enabled            bool    `yaml:"enabled" json:"enabled"`
+	Bucket             string  `yaml:"bucket" json:"bucket"`
+	RequestCapacity    int     `yaml:"request_capacity" json:"request_capacity"`
+	LogCapacity        int     `yaml:"log_capacity" json:"log_capacity"`
+	SpanCapacity       int     `yaml:"span_capacity" json:"span_capacity"`
+	SLOWindowSecs      int     `yaml:"slo_window_secs" json:"slo_window_secs"`
+	MinRequests        int     `yaml:"min_requests" json:"min_requests"`     // 窗口内请求数少于该值时不判定SLO
+	MaxErrorRate       float64 `yaml:"max_error_rate" json:"max_error_rate"` // 5xx比例阈值，0表示不检查
+	LatencyThresholdMs int     `yaml:"latency_threshold_ms" json:"latency_threshold_ms"`
+	MaxSlowRate        float64 `yaml:"max_slow_rate" json:"max_slow_rate"` // 慢请求比例阈值，0表示不检查
+	CooldownSecs       int     `yaml:"cooldown_secs" json:"cooldown_secs"` // 两次自动抓取的最小间隔
+}
+
+// DedupConfig 写入去重配置：开启后内容（MD5和大小）与已有对象相同的PUT不再写入存储，直接返回已有对象引用
+type DedupConfig struct {
+	Enabled bool  `yaml:"enabled" json:"enabled"`
+	MinSize int64 `yaml:"min_size" json:"min_size"` // 小于该字节数的对象不去重
+}
+
+// MultipartConfig 分片上传配置：未完成的分片保存在内存中，超过上限时拒绝新的上传
+type MultipartConfig struct {
+	UploadTTL     string `yaml:"upload_ttl" json:"upload_ttl"`         // 创建后超过该时间未完成的分片上传被中止
+	SweepInterval string `yaml:"sweep_interval" json:"sweep_interval"` // 检查过期分片上传的间隔
+	MaxUploads    int    `yaml:"max_uploads" json:"max_uploads"`       // 同时进行的分片上传数上限，0表示不限制
+	MaxBytes      int64  `yaml:"max_bytes" json:"max_bytes"`           // 进行中的分片上传已上传分片的总字节数上限，0表示不限制
+}
+
+// GetUploadTTL 获取分片上传过期时间
+func (m *MultipartConfig) GetUploadTTL() time.Duration {
+	ttl, err := time.ParseDuration(m.UploadTTL)
+	if err != nil {
+		return 24 * time.Hour
+	}
+	return ttl
+}
+
+// GetSweepInterval 获取检查过期分片上传的间隔
+func (m *MultipartConfig) GetSweepInterval() time.Duration {
+	interval, err := time.ParseDuration(m.SweepInterval)
+	if err != nil {
+		return 10 * time.Minute
+	}
+	return interval
+}
+
+// TenancyConfig 多租户配置：按API key绑定的租户或租户请求头隔离数据
+type TenancyConfig struct {
+	Enabled       bool   `yaml:"enabled" json:"enabled"`
+	Header        string `yaml:"header" json:"header"`
+	DefaultTenant string `yaml:"default_tenant" json:"default_tenant"` // 未指定租户的请求使用的租户
+	Required      bool   `yaml:"required" json:"required"`             // 要求每个请求显式指定租户
+}
+
+// APIKeyConfig 初始API key
+type APIKeyConfig struct {
+	Name   string `yaml:"name" json:"name"`
+	Key    string `yaml:"key" json:"key"`
+	Role   string `yaml:"role" json:"role"`     // viewer, operator, chaos-admin, super-admin
+	Tenant string `yaml:"tenant" json:"tenant"` // 绑定的租户，为空时不绑定
+}
+
+// AuditExportConfig 审计事件导出配置
+type AuditExportConfig struct {
+	Enabled      bool   `yaml:"enabled" json:"enabled"`
+	QueueURL     string `yaml:"queue_url" json:"queue_url"`
+	Topic        string `yaml:"topic" json:"topic"`
+	BufferSize   int    `yaml:"buffer_size" json:"buffer_size"`
+	Timeout      string `yaml:"timeout" json:"timeout"`
+	MockErrorURL string `yaml:"mock_error_url" json:"mock_error_url"` // 为空时不注入畸形事件
+}
+
+// ReplicationConfig bucket复制模拟配置
+type ReplicationConfig struct {
+	Enabled       bool                    `yaml:"enabled" json:"enabled"`
+	Rules         []ReplicationRuleConfig `yaml:"rules" json:"rules"`
+	Workers       int                     `yaml:"workers" json:"workers"`
+	QueueSize     int                     `yaml:"queue_size" json:"queue_size"`
+	MaxAttempts   int                     `yaml:"max_attempts" json:"max_attempts"`
+	RetryInterval string                  `yaml:"retry_interval" json:"retry_interval"`
+	MockErrorURL  string                  `yaml:"mock_error_url" json:"mock_error_url"` // 为空时不注入复制延迟和失败
+}
+
+// ReplicationRuleConfig 复制规则：写入源bucket的对象异步复制到目标bucket
+type ReplicationRuleConfig struct {
+	SourceBucket      string `yaml:"source_bucket" json:"source_bucket"`
+	DestinationBucket string `yaml:"destination_bucket" json:"destination_bucket"`
+}
+
+// GetRetryInterval 获取复制重试间隔
+func (r *ReplicationConfig) GetRetryInterval() time.Duration {
+	interval, err := time.ParseDuration(r.RetryInterval)
+	if err != nil {
+		return 5 * time.Second
+	}
+	return interval
 }
 
 // ServerConfig 服务器配置
@@ -34,10 +192,18 @@ type NodeConfig struct {
 	Path string `yaml:"path" json:"path"`
 }
 
+// 元数据服务调用方式
+const (
+	MetadataTransportHTTP = "http"
+	MetadataTransportGRPC = "grpc"
+)
+
 // MetadataConfig 元数据服务配置
 type MetadataConfig struct {
-	ServiceURL string `yaml:"service_url" json:"service_url"`
-	Timeout    string `yaml:"timeout" json:"timeout"`
+	ServiceURL  string `yaml:"service_url" json:"service_url"`
+	Timeout     string `yaml:"timeout" json:"timeout"`
+	Transport   string `yaml:"transport" json:"transport"`       // http 或 grpc，默认http
+	GRPCAddress string `yaml:"grpc_address" json:"grpc_address"` // transport为grpc时的host:port
 }
 
 // ThirdPartyConfig 第三方服务配置
@@ -47,6 +213,15 @@ type ThirdPartyConfig struct {
 	Enabled    bool   `yaml:"enabled" json:"enabled"`
 }
 
+// reservedPolicyHeaders 由对象本身决定、不允许策略设置的响应头
+var reservedPolicyHeaders = map[string]bool{
+	"Content-Type":   true,
+	"Content-Length": true,
+	"Content-Md5":    true,
+	"Etag":           true,
+	"Last-Modified":  true,
+}
+
 // GetAddress 获取服务器地址
 func (s *ServerConfig) GetAddress() string {
 	return fmt.Sprintf("%s:%d", s.Host, s.Port)
@@ -80,14 +255,76 @@ func Load() *Config {
 			},
 		},
 		Metadata: MetadataConfig{
-			ServiceURL: "http://localhost:8081",
-			Timeout:    "30s",
+			ServiceURL:  "http://localhost:8081",
+			Timeout:     "30s",
+			Transport:   MetadataTransportHTTP,
+			GRPCAddress: "localhost:9081",
 		},
 		ThirdParty: ThirdPartyConfig{
 			ServiceURL: "http://localhost:8084",
 			Timeout:    "30s",
 			Enabled:    true,
 		},
+		RateLimit: RateLimitConfig{
+			Enabled:       false,
+			DefaultPlan:   "default",
+			TenantHeaders: []string{"X-Api-Key", "X-Tenant-Id"},
+			Plans: []RatePlanConfig{
+				{Name: "default", RequestsPerSecond: 100, Burst: 200},
+			},
+		},
+		AuditExport: AuditExportConfig{
+			Enabled:    false,
+			QueueURL:   "http://localhost:8083",
+			Topic:      "audit-events",
+			BufferSize: 1000,
+			Timeout:    "5s",
+		},
+		Replication: ReplicationConfig{
+			Enabled:       false,
+			Workers:       4,
+			QueueSize:     10000,
+			MaxAttempts:   3,
+			RetryInterval: "5s",
+		},
+		RBAC: RBACConfig{
+			Enabled: false,
+			Header:  "X-Api-Key",
+		},
+		Tenancy: TenancyConfig{
+			Enabled:       false,
+			Header:        models.HeaderTenantID,
+			DefaultTenant: models.DefaultTenant,
+			Required:      false,
+		},
+		FlightRecorder: FlightRecorderConfig{
+			Enabled:            false,
+			Bucket:             models.DefaultIncidentBucket,
+			RequestCapacity:    200,
+			LogCapacity:        500,
+			SpanCapacity:       500,
+			SLOWindowSecs:      60,
+			MinRequests:        20,
+			MaxErrorRate:       0.1,
+			LatencyThresholdMs: 1000,
+			MaxSlowRate:        0.2,
+			CooldownSecs:       300,
+		},
+		Dedup: DedupConfig{
+			Enabled: false,
+			MinSize: 0,
+		},
+		Multipart: MultipartConfig{
+			UploadTTL:     "24h",
+			SweepInterval: "10m",
+			MaxUploads:    1000,
+			MaxBytes:      1 << 30,
+		},
+		FaultInjection: FaultInjectionConfig{
+			Enabled:      false,
+			MockErrorURL: "http://localhost:8085",
+			CheckTimeout: "200ms",
+		},
 		LogLevel: "info",
 	}
 
@@ -126,6 +363,156 @@ func (c *Config) Validate() error {
 	if c.Metadata.ServiceURL == "" {
 		return fmt.Errorf("metadata service URL is required")
 	}
+	switch c.Metadata.Transport {
+	case "", MetadataTransportHTTP:
+	case MetadataTransportGRPC:
+		if c.Metadata.GRPCAddress == "" {
+			return fmt.Errorf("metadata gRPC address is required when transport is grpc")
+		}
+	default:
+		return fmt.Errorf("invalid metadata transport: %s", c.Metadata.Transport)
+	}
+
+	if c.RateLimit.Enabled {
+		plans := make(map[string]bool, len(c.RateLimit.Plans))
+		for _, plan := range c.RateLimit.Plans {
+			if plan.Name == "" {
+				return fmt.Errorf("rate plan name is required")
+			}
+			plans[plan.Name] = true
+		}
+		if !plans[c.RateLimit.DefaultPlan] {
+			return fmt.Errorf("default rate plan not defined: %s", c.RateLimit.DefaultPlan)
+		}
+		for tenant, plan := range c.RateLimit.Assignments {
+			if !plans[plan] {
+				return fmt.Errorf("rate plan %s assigned to %s is not defined", plan, tenant)
+			}
+		}
+		if c.RateLimit.IdleTTL != "" {
+			if _, err := time.ParseDuration(c.RateLimit.IdleTTL); err != nil {
+				return fmt.Errorf("invalid rate limit idle_ttl: %w", err)
+			}
+		}
+		if c.RateLimit.MaxTenants < 0 {
+			return fmt.Errorf("rate limit max_tenants cannot be negative")
+		}
+	}
+
+	if ttl, err := time.ParseDuration(c.Multipart.UploadTTL); err != nil || ttl <= 0 {
+		return fmt.Errorf("invalid multipart upload_ttl: %s", c.Multipart.UploadTTL)
+	}
+	if interval, err := time.ParseDuration(c.Multipart.SweepInterval); err != nil || interval <= 0 {
+		return fmt.Errorf("invalid multipart sweep_interval: %s", c.Multipart.SweepInterval)
+	}
+	if c.Multipart.MaxUploads < 0 || c.Multipart.MaxBytes < 0 {
+		return fmt.Errorf("multipart max_uploads and max_bytes cannot be negative")
+	}
+
+	if c.Dedup.MinSize < 0 {
+		return fmt.Errorf("dedup min_size cannot be negative")
+	}
+
+	if c.FaultInjection.Enabled {
+		if c.FaultInjection.MockErrorURL == "" {
+			return fmt.Errorf("fault injection mock error URL is required")
+		}
+		if _, err := time.ParseDuration(c.FaultInjection.CheckTimeout); err != nil {
+			return fmt.Errorf("invalid fault injection check timeout: %w", err)
+		}
+	}
+
+	if c.FlightRecorder.Enabled {
+		if c.FlightRecorder.Bucket == "" {
+			return fmt.Errorf("flight recorder bucket is required")
+		}
+		if c.FlightRecorder.MaxErrorRate < 0 || c.FlightRecorder.MaxErrorRate > 1 ||
+			c.FlightRecorder.MaxSlowRate < 0 || c.FlightRecorder.MaxSlowRate > 1 {
+			return fmt.Errorf("flight recorder rates must be between 0 and 1")
+		}
+	}
+
+	if c.RBAC.Enabled {
+		if c.RBAC.Header == "" {
+			return fmt.Errorf("rbac header is required")
+		}
+		for _, key := range c.RBAC.Keys {
+			if key.Name == "" || key.Key == "" {
+				return fmt.Errorf("rbac api key name and key are required")
+			}
+			if !models.IsValidRole(key.Role) {
+				return fmt.Errorf("rbac api key %s: unknown role %q", key.Name, key.Role)
+			}
+			if key.Tenant != "" {
+				if err := models.ValidateTenantID(key.Tenant); err != nil {
+					return fmt.Errorf("rbac api key %s: %w", key.Name, err)
+				}
+			}
+		}
+	}
+
+	if c.Tenancy.Enabled {
+		if c.Tenancy.Header == "" {
+			return fmt.Errorf("tenancy header is required")
+		}
+		if err := models.ValidateTenantID(c.Tenancy.DefaultTenant); err != nil {
+			return fmt.Errorf("invalid default tenant: %w", err)
+		}
+	}
+
+	for bucket, policy := range c.BucketPolicies {
+		if bucket == "" {
+			return fmt.Errorf("bucket policy name is required")
+		}
+		for name := range policy.Headers {
+			if name == "" {
+				return fmt.Errorf("bucket policy %s: header name is required", bucket)
+			}
+			if reservedPolicyHeaders[http.CanonicalHeaderKey(name)] {
+				return fmt.Errorf("bucket policy %s: header %s cannot be overridden", bucket, name)
+			}
+		}
+		for name := range policy.Tags {
+			if name == "" {
+				return fmt.Errorf("bucket policy %s: tag name is required", bucket)
+			}
+		}
+	}
+
+	if c.AuditExport.Enabled {
+		if c.AuditExport.QueueURL == "" {
+			return fmt.Errorf("audit export queue URL is required")
+		}
+		if c.AuditExport.Topic == "" {
+			return fmt.Errorf("audit export topic is required")
+		}
+		if _, err := time.ParseDuration(c.AuditExport.Timeout); err != nil {
+			return fmt.Errorf("invalid audit export timeout: %w", err)
+		}
+	}
+
+	if c.Replication.Enabled {
+		if c.Replication.Workers <= 0 {
+			return fmt.Errorf("invalid replication workers: %d", c.Replication.Workers)
+		}
+		if c.Replication.QueueSize <= 0 {
+			return fmt.Errorf("invalid replication queue size: %d", c.Replication.QueueSize)
+		}
+		if c.Replication.MaxAttempts <= 0 {
+			return fmt.Errorf("invalid replication max attempts: %d", c.Replication.MaxAttempts)
+		}
+		if _, err := time.ParseDuration(c.Replication.RetryInterval); err != nil {
+			return fmt.Errorf("invalid replication retry interval: %w", err)
+		}
+		for _, rule := range c.Replication.Rules {
+			if rule.SourceBucket == "" || rule.DestinationBucket == "" {
+				return fmt.Errorf("replication rule requires source and destination bucket")
+			}
+			if rule.SourceBucket == rule.DestinationBucket {
+				return fmt.Errorf("replication rule %s: destination must differ from source", rule.SourceBucket)
+			}
+		}
+	}
 
 	return nil
 }
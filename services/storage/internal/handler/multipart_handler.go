@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"mocks3/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateMultipartUpload 创建分片上传（POST /:bucket/:key?uploads）
+func (h *StorageHandler) CreateMultipartUpload(c *gin.Context) {
+	upload := &models.MultipartUpload{
+		Bucket:      c.Param("bucket"),
+		Key:         c.Param("key"),
+		ContentType: c.GetHeader("Content-Type"),
+		Headers:     make(map[string]string),
+		Tags:        make(map[string]string),
+	}
+
+	for name, values := range c.Request.Header {
+		if len(values) == 0 {
+			continue
+		}
+		switch {
+		case name == "Cache-Control", name == "Content-Disposition", name == "Content-Encoding", name == "Content-Language":
+			upload.Headers[name] = values[0]
+		case strings.HasPrefix(name, "X-Amz-Meta-"):
+			upload.Headers[name] = values[0]
+		}
+	}
+
+	if tagging := c.GetHeader("x-amz-tagging"); tagging != "" {
+		tags, err := url.ParseQuery(tagging)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid x-amz-tagging header", "code": models.ErrCodeInvalidArgument})
+			return
+		}
+		for k, v := range tags {
+			if len(v) > 0 {
+				upload.Tags[k] = v[0]
+			}
+		}
+	}
+
+	result, err := h.service.CreateMultipartUpload(c.Request.Context(), upload)
+	if err != nil {
+		h.writeMultipartError(c, "create multipart upload", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket":    result.Bucket,
+		"key":       result.Key,
+		"upload_id": result.UploadID,
+	})
+}
+
+// UploadPart 上传分片（PUT /:bucket/:key?partNumber=N&uploadId=ID）
+func (h *StorageHandler) UploadPart(c *gin.Context) {
+	partNumber, err := strconv.Atoi(c.Query("partNumber"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partNumber parameter", "code": models.ErrCodeInvalidArgument})
+		return
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to read request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	etag, err := h.service.UploadPart(c.Request.Context(), c.Param("bucket"), c.Param("key"), c.Query("uploadId"), partNumber, data)
+	if err != nil {
+		h.writeMultipartError(c, "upload part", err)
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Status(http.StatusOK)
+}
+
+// CompleteMultipartUpload 完成分片上传（POST /:bucket/:key?uploadId=ID），请求体为S3 CompleteMultipartUpload XML或JSON
+func (h *StorageHandler) CompleteMultipartUpload(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to read request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	var req models.CompleteMultipartUploadRequest
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		err = json.Unmarshal(body, &req)
+	} else {
+		err = xml.Unmarshal(body, &req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The XML you provided was not well-formed", "code": "MalformedXML"})
+		return
+	}
+
+	object, err := h.service.CompleteMultipartUpload(c.Request.Context(), c.Param("bucket"), c.Param("key"), c.Query("uploadId"), &req)
+	if err != nil {
+		h.writeMultipartError(c, "complete multipart upload", err)
+		return
+	}
+
+	c.Header("ETag", object.ETag)
+	c.JSON(http.StatusOK, gin.H{
+		"bucket": object.Bucket,
+		"key":    object.Key,
+		"etag":   object.ETag,
+		"size":   object.Size,
+	})
+}
+
+// AbortMultipartUpload 中止分片上传（DELETE /:bucket/:key?uploadId=ID）
+func (h *StorageHandler) AbortMultipartUpload(c *gin.Context) {
+	if err := h.service.AbortMultipartUpload(c.Request.Context(), c.Param("bucket"), c.Param("key"), c.Query("uploadId")); err != nil {
+		h.writeMultipartError(c, "abort multipart upload", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// writeMultipartError 返回分片上传错误
+func (h *StorageHandler) writeMultipartError(c *gin.Context, operation string, err error) {
+	var apiErr *models.APIError
+	if errors.As(err, &apiErr) {
+		h.logger.WarnContext(c.Request.Context(), "Multipart request rejected", "operation", operation, "code", apiErr.Code, "error", apiErr.Message)
+		c.JSON(apiErrorStatus(apiErr.Code), gin.H{"error": apiErr.Message, "code": apiErr.Code})
+		return
+	}
+	if h.writeSchemaViolation(c, err) {
+		return
+	}
+	h.logger.ErrorContext(c.Request.Context(), "Failed to "+operation, "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + operation, "code": "InternalError"})
+}
+
+// objectPartRange 解析?partNumber参数，未指定时返回nil；参数无效时已写入错误响应并返回false
+func (h *StorageHandler) objectPartRange(c *gin.Context, headers map[string]string, size int64) (*models.PartRange, bool) {
+	value, ok := c.GetQuery("partNumber")
+	if !ok {
+		return nil, true
+	}
+
+	partNumber, err := strconv.Atoi(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partNumber parameter", "code": models.ErrCodeInvalidArgument})
+		return nil, false
+	}
+
+	part, err := models.ObjectPartRange(headers, size, partNumber)
+	if err != nil {
+		h.writeMultipartError(c, "get object part", err)
+		return nil, false
+	}
+	return part, true
+}
+
+// setPartHeaders 设置分片响应头
+func setPartHeaders(c *gin.Context, part *models.PartRange, size int64) {
+	c.Header("Content-Length", strconv.FormatInt(part.Length, 10))
+	if part.Length > 0 {
+		c.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", part.Offset, part.Offset+part.Length-1, size))
+	}
+	if part.PartsCount > 0 {
+		c.Header(models.HeaderMultipartPartsCount, strconv.Itoa(part.PartsCount))
+	}
+}
+
+// setObjectHeaders 设置对象自定义头，不返回内部头
+func setObjectHeaders(c *gin.Context, headers map[string]string) {
+	for key, value := range headers {
+		if models.IsInternalHeader(key) {
+			continue
+		}
+		c.Header(key, value)
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReplicationReporter bucket复制状态查询
+type ReplicationReporter interface {
+	Report() *models.ReplicationReport
+}
+
+// ReplicationHandler bucket复制管理处理器
+type ReplicationHandler struct {
+	replication ReplicationReporter
+	logger      *observability.Logger
+}
+
+// NewReplicationHandler 创建bucket复制管理处理器
+func NewReplicationHandler(replication ReplicationReporter, logger *observability.Logger) *ReplicationHandler {
+	return &ReplicationHandler{
+		replication: replication,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *ReplicationHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.GET("/replication/status", h.GetStatus)
+}
+
+// GetStatus 获取各复制目标的延迟、待复制对象数和失败原因分布
+func (h *ReplicationHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.replication.Report(),
+	})
+}
@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"mocks3/shared/interfaces"
@@ -12,7 +16,6 @@ import (
 	"mocks3/shared/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 // StorageHandler 存储处理器
@@ -33,6 +36,7 @@ func NewStorageHandler(service interfaces.StorageService, logger *observability.
 func (h *StorageHandler) RegisterRoutes(router *gin.Engine) {
 	// S3兼容API
 	router.PUT("/:bucket/:key", h.PutObject)
+	router.POST("/:bucket/:key", h.AppendObject)
 	router.GET("/:bucket/:key", h.GetObject)
 	router.DELETE("/:bucket/:key", h.DeleteObject)
 	router.HEAD("/:bucket/:key", h.HeadObject)
@@ -43,6 +47,7 @@ func (h *StorageHandler) RegisterRoutes(router *gin.Engine) {
 	{
 		v1.POST("/objects", h.CreateObject)
 		v1.GET("/objects/:bucket/:key", h.GetObjectInfo)
+		v1.GET("/objects/:bucket/:key/replicas", h.GetObjectReplicas)
 		v1.DELETE("/objects/:bucket/:key", h.DeleteObjectAPI)
 		v1.GET("/objects", h.ListObjectsAPI)
 		v1.GET("/stats", h.GetStats)
@@ -54,6 +59,18 @@ func (h *StorageHandler) PutObject(c *gin.Context) {
 	bucket := c.Param("bucket")
 	key := c.Param("key")
 
+	// 带x-amz-copy-source头的PUT为复制请求
+	if c.GetHeader("x-amz-copy-source") != "" {
+		h.CopyObject(c)
+		return
+	}
+
+	// 带uploadId参数的PUT为上传分片
+	if _, ok := c.GetQuery("uploadId"); ok {
+		h.UploadPart(c)
+		return
+	}
+
 	// 读取请求体
 	data, err := io.ReadAll(c.Request.Body)
 	if err != nil {
@@ -64,7 +81,7 @@ func (h *StorageHandler) PutObject(c *gin.Context) {
 
 	// 构建对象
 	object := &models.Object{
-		ID:          uuid.New().String(),
+		ID:          utils.NewID(),
 		Key:         key,
 		Bucket:      bucket,
 		Size:        int64(len(data)),
@@ -89,29 +106,195 @@ func (h *StorageHandler) PutObject(c *gin.Context) {
 				object.MD5Hash = values[0]
 			case "Cache-Control", "Content-Disposition", "Content-Encoding", "Content-Language":
 				object.Headers[key] = values[0]
+			case models.HeaderObjectLockMode, models.HeaderObjectLockRetainUntil, models.HeaderObjectLockLegalHold:
+				object.Headers[key] = values[0]
 			}
 		}
 	}
 
-	// 写入对象
-	if err := h.service.WriteObject(c.Request.Context(), object); err != nil {
+	// 校验对象锁定设置
+	if hold, err := models.ParseObjectHold(object.Headers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": models.ErrCodeInvalidArgument})
+		return
+	} else if hold != nil && hold.RetainUntil != nil && !hold.RetainUntil.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The retain until date must be in the future", "code": models.ErrCodeInvalidArgument})
+		return
+	}
+
+	// 写入对象（开启写入去重时内容重复的对象不再写入）
+	duplicate, err := h.service.WriteObjectDedup(c.Request.Context(), object)
+	if err != nil {
+		if h.writeSchemaViolation(c, err) {
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to write object", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write object"})
 		return
 	}
 
 	// 设置响应头
+	if duplicate != nil {
+		c.Header(models.HeaderDuplicateOf, duplicate.Bucket+"/"+duplicate.Key)
+	}
 	c.Header("ETag", object.ETag)
 	c.Header("Content-MD5", object.MD5Hash)
 
 	c.Status(http.StatusOK)
 }
 
+// AppendObject 追加写对象接口（POST /:bucket/:key?append&position=N）
+func (h *StorageHandler) AppendObject(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := c.Param("key")
+
+	// 分片上传的创建（?uploads）和完成（?uploadId=）同样使用POST
+	if _, ok := c.GetQuery("uploads"); ok {
+		h.CreateMultipartUpload(c)
+		return
+	}
+	if _, ok := c.GetQuery("uploadId"); ok {
+		h.CompleteMultipartUpload(c)
+		return
+	}
+
+	if _, ok := c.GetQuery("append"); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing append parameter"})
+		return
+	}
+
+	position, err := strconv.ParseInt(c.DefaultQuery("position", "0"), 10, 64)
+	if err != nil || position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid position parameter"})
+		return
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to read request body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	object, err := h.service.AppendObject(c.Request.Context(), bucket, key, position, data)
+	if err != nil {
+		if errors.Is(err, models.ErrAppendPositionMismatch) {
+			h.logger.WarnContext(c.Request.Context(), "Append position mismatch", "bucket", bucket, "key", key, "position", position)
+			c.JSON(http.StatusConflict, gin.H{"error": "Position not equal to object length"})
+			return
+		}
+		if h.writeSchemaViolation(c, err) {
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to append object", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to append object"})
+		return
+	}
+
+	c.Header("ETag", object.ETag)
+	c.Header("Content-MD5", object.MD5Hash)
+	c.Header("X-Next-Append-Position", strconv.FormatInt(object.Size, 10))
+
+	c.Status(http.StatusOK)
+}
+
+// CopyObject S3兼容的复制对象接口（PUT /:bucket/:key + x-amz-copy-source）
+func (h *StorageHandler) CopyObject(c *gin.Context) {
+	sourceBucket, sourceKey, err := parseCopySource(c.GetHeader("x-amz-copy-source"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": models.ErrCodeInvalidArgument})
+		return
+	}
+
+	req := &models.CopyObjectRequest{
+		SourceBucket:      sourceBucket,
+		SourceKey:         sourceKey,
+		DestBucket:        c.Param("bucket"),
+		DestKey:           c.Param("key"),
+		IfMatch:           c.GetHeader("x-amz-copy-source-if-match"),
+		IfNoneMatch:       c.GetHeader("x-amz-copy-source-if-none-match"),
+		MetadataDirective: c.GetHeader("x-amz-metadata-directive"),
+		TaggingDirective:  c.GetHeader("x-amz-tagging-directive"),
+		ContentType:       c.GetHeader("Content-Type"),
+		Headers:           make(map[string]string),
+		Tags:              make(map[string]string),
+	}
+
+	for header, target := range map[string]**time.Time{
+		"x-amz-copy-source-if-modified-since":   &req.IfModifiedSince,
+		"x-amz-copy-source-if-unmodified-since": &req.IfUnmodifiedSince,
+	} {
+		value := c.GetHeader(header)
+		if value == "" {
+			continue
+		}
+		t, err := http.ParseTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + header + " header", "code": models.ErrCodeInvalidArgument})
+			return
+		}
+		*target = &t
+	}
+
+	// 复制相关的HTTP头以及用户元数据
+	for name, values := range c.Request.Header {
+		if len(values) == 0 {
+			continue
+		}
+		switch {
+		case name == "Cache-Control", name == "Content-Disposition", name == "Content-Encoding", name == "Content-Language":
+			req.Headers[name] = values[0]
+		case strings.HasPrefix(name, "X-Amz-Meta-"):
+			req.Headers[name] = values[0]
+		}
+	}
+
+	if tagging := c.GetHeader("x-amz-tagging"); tagging != "" {
+		tags, err := url.ParseQuery(tagging)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid x-amz-tagging header", "code": models.ErrCodeInvalidArgument})
+			return
+		}
+		for k, v := range tags {
+			if len(v) > 0 {
+				req.Tags[k] = v[0]
+			}
+		}
+	}
+
+	result, err := h.service.CopyObject(c.Request.Context(), req)
+	if err != nil {
+		var apiErr *models.APIError
+		if errors.As(err, &apiErr) {
+			h.logger.WarnContext(c.Request.Context(), "Copy object rejected", "code", apiErr.Code, "error", apiErr.Message)
+			c.JSON(apiErrorStatus(apiErr.Code), gin.H{"error": apiErr.Message, "code": apiErr.Code})
+			return
+		}
+		if h.writeSchemaViolation(c, err) {
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to copy object", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to copy object", "code": "InternalError"})
+		return
+	}
+
+	c.Header("ETag", result.ETag)
+	c.JSON(http.StatusOK, gin.H{
+		"etag":          result.ETag,
+		"last_modified": result.LastModified.UTC().Format(time.RFC3339),
+	})
+}
+
 // GetObject S3兼容的GET对象接口
 func (h *StorageHandler) GetObject(c *gin.Context) {
 	bucket := c.Param("bucket")
 	key := c.Param("key")
 
+	// 带tagging参数的GET为获取对象标签
+	if _, ok := c.GetQuery("tagging"); ok {
+		h.GetObjectTagging(c)
+		return
+	}
+
 	object, err := h.service.ReadObject(c.Request.Context(), bucket, key)
 	if err != nil {
 		h.logger.WarnContext(c.Request.Context(), "Object not found", "bucket", bucket, "key", key)
@@ -119,28 +302,49 @@ func (h *StorageHandler) GetObject(c *gin.Context) {
 		return
 	}
 
+	// ?partNumber=N 只返回该分片的字节范围
+	part, ok := h.objectPartRange(c, object.Headers, object.Size)
+	if !ok {
+		return
+	}
+
 	// 设置响应头
 	c.Header("Content-Type", object.ContentType)
-	c.Header("Content-Length", strconv.FormatInt(object.Size, 10))
 	c.Header("ETag", object.ETag)
-	c.Header("Content-MD5", object.MD5Hash)
 	c.Header("Last-Modified", object.UpdatedAt.Format(http.TimeFormat))
+	if part == nil {
+		c.Header("Content-Length", strconv.FormatInt(object.Size, 10))
+		c.Header("Content-MD5", object.MD5Hash)
+	} else {
+		setPartHeaders(c, part, object.Size)
+	}
 
 	// 设置自定义头
-	for key, value := range object.Headers {
-		c.Header(key, value)
-	}
+	setObjectHeaders(c, object.Headers)
+
+	// 标签只返回数量，内容通过?tagging获取
+	c.Header("X-Amz-Tagging-Count", strconv.Itoa(len(object.Tags)))
 
 	// 返回文件数据
+	if part != nil {
+		c.Data(http.StatusPartialContent, object.ContentType, object.Data[part.Offset:part.Offset+part.Length])
+		return
+	}
 	c.Data(http.StatusOK, object.ContentType, object.Data)
 }
 
 // DeleteObject S3兼容的DELETE对象接口
 func (h *StorageHandler) DeleteObject(c *gin.Context) {
-	bucket := c.Param("bucket")
-	key := c.Param("key")
+	// 带uploadId参数的DELETE为中止分片上传
+	if _, ok := c.GetQuery("uploadId"); ok {
+		h.AbortMultipartUpload(c)
+		return
+	}
 
-	if err := h.service.DeleteObject(c.Request.Context(), bucket, key); err != nil {
+	if err := h.service.DeleteObject(c.Request.Context(), deleteObjectRequest(c)); err != nil {
+		if h.writeObjectHoldError(c, err) || h.writeRetentionViolation(c, err) {
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to delete object", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete object"})
 		return
@@ -154,28 +358,57 @@ func (h *StorageHandler) HeadObject(c *gin.Context) {
 	bucket := c.Param("bucket")
 	key := c.Param("key")
 
-	object, err := h.service.ReadObject(c.Request.Context(), bucket, key)
+	info, err := h.service.HeadObject(c.Request.Context(), bucket, key)
 	if err != nil {
 		h.logger.WarnContext(c.Request.Context(), "Object not found", "bucket", bucket, "key", key)
 		c.Status(http.StatusNotFound)
 		return
 	}
 
+	part, ok := h.objectPartRange(c, info.Headers, info.Size)
+	if !ok {
+		return
+	}
+
 	// 设置响应头（不返回body）
-	c.Header("Content-Type", object.ContentType)
-	c.Header("Content-Length", strconv.FormatInt(object.Size, 10))
-	c.Header("ETag", object.ETag)
-	c.Header("Content-MD5", object.MD5Hash)
-	c.Header("Last-Modified", object.UpdatedAt.Format(http.TimeFormat))
+	c.Header("Content-Type", info.ContentType)
+	c.Header("ETag", info.ETag)
+	c.Header("Last-Modified", info.UpdatedAt.Format(http.TimeFormat))
+	if part == nil {
+		c.Header("Content-Length", strconv.FormatInt(info.Size, 10))
+		c.Header("Content-MD5", info.MD5Hash)
+	} else {
+		setPartHeaders(c, part, info.Size)
+	}
 
 	// 设置自定义头
-	for key, value := range object.Headers {
-		c.Header(key, value)
-	}
+	setObjectHeaders(c, info.Headers)
+
+	// 标签只返回数量，内容通过?tagging获取
+	c.Header("X-Amz-Tagging-Count", strconv.Itoa(len(info.Tags)))
 
+	if part != nil {
+		c.Status(http.StatusPartialContent)
+		return
+	}
 	c.Status(http.StatusOK)
 }
 
+// GetObjectTagging 获取对象标签（GET /:bucket/:key?tagging），返回S3 Tagging XML
+func (h *StorageHandler) GetObjectTagging(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := c.Param("key")
+
+	info, err := h.service.HeadObject(c.Request.Context(), bucket, key)
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Object not found", "bucket", bucket, "key", key)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Object not found"})
+		return
+	}
+
+	c.XML(http.StatusOK, models.NewTagging(info.Tags))
+}
+
 // ListObjects S3兼容的列表接口
 func (h *StorageHandler) ListObjects(c *gin.Context) {
 	bucket := c.Param("bucket")
@@ -217,7 +450,7 @@ func (h *StorageHandler) CreateObject(c *gin.Context) {
 	}
 
 	object := &models.Object{
-		ID:          uuid.New().String(),
+		ID:          utils.NewID(),
 		Key:         req.Key,
 		Bucket:      req.Bucket,
 		Size:        int64(len(req.Data)),
@@ -241,21 +474,34 @@ func (h *StorageHandler) CreateObject(c *gin.Context) {
 		object.Tags = make(map[string]string)
 	}
 
-	if err := h.service.WriteObject(c.Request.Context(), object); err != nil {
+	duplicate, err := h.service.WriteObjectDedup(c.Request.Context(), object)
+	if err != nil {
+		if h.writeSchemaViolation(c, err) {
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to create object", "error", err)
 		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to create object")
 		return
 	}
 
 	response := &models.UploadResponse{
-		Success:   true,
-		ObjectID:  object.ID,
-		Key:       object.Key,
-		Bucket:    object.Bucket,
-		Size:      object.Size,
-		MD5Hash:   object.MD5Hash,
-		ETag:      object.ETag,
-		Timestamp: time.Now().Format(time.RFC3339),
+		Success:     true,
+		ObjectID:    object.ID,
+		Key:         object.Key,
+		Bucket:      object.Bucket,
+		Size:        object.Size,
+		MD5Hash:     object.MD5Hash,
+		ETag:        object.ETag,
+		DuplicateOf: duplicate,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+
+	// 去重命中时未创建新对象
+	if duplicate != nil {
+		response.ObjectID = duplicate.ID
+		response.Message = "duplicate content, object not written"
+		c.JSON(http.StatusOK, response)
+		return
 	}
 
 	c.JSON(http.StatusCreated, response)
@@ -296,10 +542,10 @@ func (h *StorageHandler) GetObjectInfo(c *gin.Context) {
 
 // DeleteObjectAPI 管理API - 删除对象
 func (h *StorageHandler) DeleteObjectAPI(c *gin.Context) {
-	bucket := c.Param("bucket")
-	key := c.Param("key")
-
-	if err := h.service.DeleteObject(c.Request.Context(), bucket, key); err != nil {
+	if err := h.service.DeleteObject(c.Request.Context(), deleteObjectRequest(c)); err != nil {
+		if h.writeObjectHoldError(c, err) || h.writeRetentionViolation(c, err) {
+			return
+		}
 		h.logger.ErrorContext(c.Request.Context(), "Failed to delete object", "error", err)
 		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to delete object")
 		return
@@ -342,6 +588,32 @@ func (h *StorageHandler) ListObjectsAPI(c *gin.Context) {
 	})
 }
 
+// GetObjectReplicas 管理API - 获取对象各节点副本状态
+func (h *StorageHandler) GetObjectReplicas(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := c.Param("key")
+
+	replicas, err := h.service.InspectReplicas(c.Request.Context(), bucket, key)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidArgument) {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to inspect replicas", "bucket", bucket, "key", key, "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusInternalServerError, "Failed to inspect replicas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"bucket":   bucket,
+			"key":      key,
+			"replicas": replicas,
+		},
+	})
+}
+
 // GetStats 获取存储统计信息
 func (h *StorageHandler) GetStats(c *gin.Context) {
 	stats, err := h.service.GetStats(c.Request.Context())
@@ -356,3 +628,102 @@ func (h *StorageHandler) GetStats(c *gin.Context) {
 		"data":    stats,
 	})
 }
+
+// parseCopySource 解析x-amz-copy-source头（/bucket/key，可URL编码）
+func parseCopySource(source string) (string, string, error) {
+	decoded, err := url.PathUnescape(source)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid x-amz-copy-source header: %w", err)
+	}
+
+	// 忽略versionId等查询参数
+	if idx := strings.Index(decoded, "?"); idx >= 0 {
+		decoded = decoded[:idx]
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(decoded, "/"), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid x-amz-copy-source header: expected /bucket/key")
+	}
+	return parts[0], parts[1], nil
+}
+
+// apiErrorStatus S3错误码对应的HTTP状态码
+func apiErrorStatus(code string) int {
+	switch code {
+	case models.ErrCodePreconditionFailed:
+		return http.StatusPreconditionFailed
+	case models.ErrCodeNoSuchKey, models.ErrCodeNoSuchUpload:
+		return http.StatusNotFound
+	case models.ErrCodeInvalidArgument, models.ErrCodeInvalidRequest, models.ErrCodeInvalidPart, models.ErrCodeInvalidPartOrder:
+		return http.StatusBadRequest
+	case models.ErrCodeInvalidPartNumber:
+		return http.StatusRequestedRangeNotSatisfiable
+	case models.ErrCodeSlowDown:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// deleteObjectRequest 从请求构建删除对象请求
+func deleteObjectRequest(c *gin.Context) *models.DeleteObjectRequest {
+	bypass, _ := strconv.ParseBool(c.GetHeader(models.HeaderBypassGovernance))
+	return &models.DeleteObjectRequest{
+		Bucket:                    c.Param("bucket"),
+		Key:                       c.Param("key"),
+		BypassGovernanceRetention: bypass,
+	}
+}
+
+// writeRetentionViolation 对象仍在bucket保留期内时返回403 RetentionViolation及保留详情，返回是否已处理
+func (h *StorageHandler) writeRetentionViolation(c *gin.Context, err error) bool {
+	var violation *models.RetentionViolationError
+	if !errors.As(err, &violation) {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":     violation.Error(),
+		"code":      models.ErrCodeRetentionViolation,
+		"success":   false,
+		"retention": violation,
+	})
+	return true
+}
+
+// writeSchemaViolation 对象的自定义元数据不符合bucket schema时返回400及逐项违规，返回是否已处理
+func (h *StorageHandler) writeSchemaViolation(c *gin.Context, err error) bool {
+	var validationErr *models.SchemaValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+
+	h.logger.WarnContext(c.Request.Context(), "Write rejected by bucket schema",
+		"bucket", validationErr.Bucket, "key", validationErr.Key, "violations", len(validationErr.Violations))
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":      validationErr.Error(),
+		"code":       models.ErrCodeSchemaViolation,
+		"success":    false,
+		"violations": validationErr.Violations,
+	})
+	return true
+}
+
+// writeObjectHoldError 对象受保留保护时返回S3 AccessDenied及保留详情，返回是否已处理
+func (h *StorageHandler) writeObjectHoldError(c *gin.Context, err error) bool {
+	var holdErr *models.ObjectHoldError
+	if !errors.As(err, &holdErr) {
+		return false
+	}
+
+	h.logger.WarnContext(c.Request.Context(), "Delete rejected by object hold",
+		"bucket", holdErr.Bucket, "key", holdErr.Key, "reason", holdErr.Reason)
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   "Access Denied because object protected by object lock.",
+		"code":    models.ErrCodeAccessDenied,
+		"success": false,
+		"hold":    holdErr,
+	})
+	return true
+}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mocks3/shared/interfaces"
 	"mocks3/shared/models"
+	"mocks3/shared/observability"
 	"sync"
 )
 
@@ -12,13 +14,15 @@ import (
 type StorageManager struct {
 	nodes             []interfaces.StorageNode
 	thirdPartyService interfaces.ThirdPartyService
+	logger            *observability.Logger
 	mu                sync.RWMutex
 }
 
 // NewStorageManager 创建存储管理器
-func NewStorageManager() *StorageManager {
+func NewStorageManager(logger *observability.Logger) *StorageManager {
 	return &StorageManager{
-		nodes: make([]interfaces.StorageNode, 0),
+		nodes:  make([]interfaces.StorageNode, 0),
+		logger: logger,
 	}
 }
 
@@ -84,6 +88,85 @@ func (sm *StorageManager) WriteToAllNodes(ctx context.Context, object *models.Ob
 	return nil
 }
 
+// AppendToAllNodes 向所有节点追加数据，返回第一个节点上的对象和追加的节点ID
+//
+// 所有节点都必须追加成功，否则截断已追加的节点回到追加前的大小并返回错误，避免副本不一致；
+// 节点上的对象大小与position不一致时返回的错误包含models.ErrAppendPositionMismatch
+func (sm *StorageManager) AppendToAllNodes(ctx context.Context, bucket, key string, position int64, data []byte) (*models.Object, []string, error) {
+	sm.mu.RLock()
+	nodes := make([]interfaces.StorageNode, len(sm.nodes))
+	copy(nodes, sm.nodes)
+	sm.mu.RUnlock()
+
+	if len(nodes) == 0 {
+		return nil, nil, fmt.Errorf("no storage nodes available")
+	}
+
+	var result *models.Object
+	appended := make([]string, 0, len(nodes))
+
+	for _, node := range nodes {
+		var obj *models.Object
+		var err error
+		if fileNode, ok := node.(*FileStorageNode); ok {
+			obj, err = fileNode.Append(ctx, bucket, key, position, data)
+		} else {
+			err = fmt.Errorf("storage node %s does not support append operations", node.GetNodeID())
+		}
+		if err != nil {
+			sm.logger.WarnContext(ctx, "Failed to append to storage node, rolling back",
+				"node_id", node.GetNodeID(), "bucket", bucket, "key", key, "position", position,
+				"appended_nodes", appended, "error", err)
+			if len(appended) > 0 {
+				if truncErr := sm.TruncateOnNodes(ctx, appended, bucket, key, position); truncErr != nil {
+					sm.logger.ErrorContext(ctx, "Failed to rollback append", "bucket", bucket, "key", key, "error", truncErr)
+				}
+			}
+			return nil, nil, fmt.Errorf("failed to append to node %s: %w", node.GetNodeID(), err)
+		}
+
+		appended = append(appended, node.GetNodeID())
+		if result == nil {
+			result = obj
+		}
+	}
+
+	return result, appended, nil
+}
+
+// TruncateOnNodes 将指定节点上的对象截断到指定大小，用于回滚这些节点上的追加
+func (sm *StorageManager) TruncateOnNodes(ctx context.Context, nodeIDs []string, bucket, key string, size int64) error {
+	sm.mu.RLock()
+	nodes := make([]interfaces.StorageNode, len(sm.nodes))
+	copy(nodes, sm.nodes)
+	sm.mu.RUnlock()
+
+	targets := make(map[string]bool, len(nodeIDs))
+	for _, id := range nodeIDs {
+		targets[id] = true
+	}
+
+	var errors []error
+	for _, node := range nodes {
+		if !targets[node.GetNodeID()] {
+			continue
+		}
+		fileNode, ok := node.(*FileStorageNode)
+		if !ok {
+			continue
+		}
+		if err := fileNode.Truncate(ctx, bucket, key, size); err != nil {
+			errors = append(errors, fmt.Errorf("node %s: %w", node.GetNodeID(), err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to truncate on some nodes: %v", errors)
+	}
+
+	return nil
+}
+
 // ReadFromBestNode 从最佳节点读取（优先stg1）
 func (sm *StorageManager) ReadFromBestNode(ctx context.Context, bucket, key string) (*models.Object, error) {
 	sm.mu.RLock()
@@ -192,6 +275,33 @@ func (sm *StorageManager) GetAllNodes() []interfaces.StorageNode {
 	return nodes
 }
 
+// InspectReplicas 逐个节点检查对象副本（不走第三方回退）
+func (sm *StorageManager) InspectReplicas(ctx context.Context, bucket, key string) []*models.ObjectReplica {
+	nodes := sm.GetAllNodes()
+	replicas := make([]*models.ObjectReplica, 0, len(nodes))
+
+	for _, node := range nodes {
+		replica := &models.ObjectReplica{
+			NodeID:  node.GetNodeID(),
+			Healthy: node.IsHealthy(ctx),
+		}
+
+		object, err := node.Read(ctx, bucket, key)
+		switch {
+		case err == nil:
+			replica.Exists = true
+			replica.Size = object.Size
+			replica.MD5Hash = object.MD5Hash
+		case !errors.Is(err, models.ErrObjectNotFound):
+			replica.Error = err.Error()
+		}
+
+		replicas = append(replicas, replica)
+	}
+
+	return replicas
+}
+
 // GetNodeByID 根据ID获取节点
 func (sm *StorageManager) GetNodeByID(nodeID string) interfaces.StorageNode {
 	sm.mu.RLock()
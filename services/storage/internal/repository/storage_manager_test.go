@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"reflect"
+	"testing"
+)
+
+// newTestStorageManager 创建带有指定节点的存储管理器，节点数据保存在临时目录
+func newTestStorageManager(t *testing.T, nodeIDs ...string) (*StorageManager, map[string]*FileStorageNode) {
+	t.Helper()
+	manager := NewStorageManager(observability.NewLogger("storage-service", "error"))
+	nodes := make(map[string]*FileStorageNode, len(nodeIDs))
+	for _, id := range nodeIDs {
+		node, err := NewFileStorageNode(id, t.TempDir())
+		if err != nil {
+			t.Fatalf("NewFileStorageNode: %v", err)
+		}
+		manager.AddNode(node)
+		nodes[id] = node
+	}
+	return manager, nodes
+}
+
+// readData 读取节点上的对象数据，对象不存在时返回nil
+func readData(t *testing.T, node *FileStorageNode, bucket, key string) []byte {
+	t.Helper()
+	object, err := node.Read(context.Background(), bucket, key)
+	if errors.Is(err, models.ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("Read %s: %v", node.GetNodeID(), err)
+	}
+	return object.Data
+}
+
+func TestAppendToAllNodes(t *testing.T) {
+	ctx := context.Background()
+	manager, nodes := newTestStorageManager(t, "stg1", "stg2")
+
+	if _, _, err := manager.AppendToAllNodes(ctx, "bucket", "key", 0, []byte("abc")); err != nil {
+		t.Fatalf("first append: %v", err)
+	}
+	object, appended, err := manager.AppendToAllNodes(ctx, "bucket", "key", 3, []byte("def"))
+	if err != nil {
+		t.Fatalf("second append: %v", err)
+	}
+	if object.Size != 6 {
+		t.Errorf("size = %d, want 6", object.Size)
+	}
+	if !reflect.DeepEqual(appended, []string{"stg1", "stg2"}) {
+		t.Errorf("appended = %v, want [stg1 stg2]", appended)
+	}
+	for id, node := range nodes {
+		if data := readData(t, node, "bucket", "key"); string(data) != "abcdef" {
+			t.Errorf("%s data = %q, want %q", id, data, "abcdef")
+		}
+	}
+}
+
+func TestAppendToAllNodesRollsBackOnPositionMismatch(t *testing.T) {
+	ctx := context.Background()
+	manager, nodes := newTestStorageManager(t, "stg1", "stg2", "stg3")
+
+	// stg2上的对象与其他节点不一致
+	if _, err := nodes["stg2"].Append(ctx, "bucket", "key", 0, []byte("abc")); err != nil {
+		t.Fatalf("seed stg2: %v", err)
+	}
+
+	_, appended, err := manager.AppendToAllNodes(ctx, "bucket", "key", 0, []byte("xyz"))
+	if !errors.Is(err, models.ErrAppendPositionMismatch) {
+		t.Fatalf("error = %v, want ErrAppendPositionMismatch", err)
+	}
+	if appended != nil {
+		t.Errorf("appended = %v, want nil", appended)
+	}
+
+	// stg1已追加的数据被截断回滚，stg3未被写入，stg2保持原样
+	if data := readData(t, nodes["stg1"], "bucket", "key"); data != nil {
+		t.Errorf("stg1 data = %q, want rolled back", data)
+	}
+	if data := readData(t, nodes["stg2"], "bucket", "key"); string(data) != "abc" {
+		t.Errorf("stg2 data = %q, want %q", data, "abc")
+	}
+	if data := readData(t, nodes["stg3"], "bucket", "key"); data != nil {
+		t.Errorf("stg3 data = %q, want not written", data)
+	}
+}
+
+func TestAppendToAllNodesRollsBackToPosition(t *testing.T) {
+	ctx := context.Background()
+	manager, nodes := newTestStorageManager(t, "stg1", "stg2")
+
+	if _, _, err := manager.AppendToAllNodes(ctx, "bucket", "key", 0, []byte("abc")); err != nil {
+		t.Fatalf("first append: %v", err)
+	}
+	// stg2多出一次追加，下一次追加在stg2上位置不一致
+	if _, err := nodes["stg2"].Append(ctx, "bucket", "key", 3, []byte("!")); err != nil {
+		t.Fatalf("diverge stg2: %v", err)
+	}
+
+	if _, _, err := manager.AppendToAllNodes(ctx, "bucket", "key", 3, []byte("def")); !errors.Is(err, models.ErrAppendPositionMismatch) {
+		t.Fatalf("error = %v, want ErrAppendPositionMismatch", err)
+	}
+	if data := readData(t, nodes["stg1"], "bucket", "key"); string(data) != "abc" {
+		t.Errorf("stg1 data = %q, want %q", data, "abc")
+	}
+}
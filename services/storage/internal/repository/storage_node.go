@@ -6,11 +6,10 @@ import (
 	"fmt"
 	"io"
 	"mocks3/shared/models"
+	"mocks3/shared/utils"
 	"os"
 	"path/filepath"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // FileStorageNode 文件存储节点实现
@@ -44,7 +43,7 @@ func (fs *FileStorageNode) Write(ctx context.Context, object *models.Object) err
 	}
 
 	// 构建文件路径
-	filePath := fs.buildFilePath(object.Bucket, object.Key)
+	filePath := fs.buildFilePath(ctx, object.Bucket, object.Key)
 
 	// 确保目录存在
 	dir := filepath.Dir(filePath)
@@ -93,7 +92,7 @@ func (fs *FileStorageNode) Write(ctx context.Context, object *models.Object) err
 
 	// 设置对象ID（如果没有）
 	if object.ID == "" {
-		object.ID = uuid.New().String()
+		object.ID = utils.NewID()
 	}
 
 	return nil
@@ -101,13 +100,13 @@ func (fs *FileStorageNode) Write(ctx context.Context, object *models.Object) err
 
 // Read 读取对象
 func (fs *FileStorageNode) Read(ctx context.Context, bucket, key string) (*models.Object, error) {
-	filePath := fs.buildFilePath(bucket, key)
+	filePath := fs.buildFilePath(ctx, bucket, key)
 
 	// 检查文件是否存在
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("object not found: %s/%s", bucket, key)
+			return nil, fmt.Errorf("%w: %s/%s", models.ErrObjectNotFound, bucket, key)
 		}
 		return nil, fmt.Errorf("failed to stat file %s: %w", filePath, err)
 	}
@@ -140,9 +139,62 @@ func (fs *FileStorageNode) Read(ctx context.Context, bucket, key string) (*model
 	return object, nil
 }
 
+// Append 在指定位置追加数据，position必须等于当前对象大小
+func (fs *FileStorageNode) Append(ctx context.Context, bucket, key string, position int64, data []byte) (*models.Object, error) {
+	filePath := fs.buildFilePath(ctx, bucket, key)
+
+	// 确保目录存在
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file %s: %w", filePath, err)
+	}
+
+	// 追加位置必须与当前大小一致
+	if fileInfo.Size() != position {
+		return nil, fmt.Errorf("%w: current size %d, requested position %d", models.ErrAppendPositionMismatch, fileInfo.Size(), position)
+	}
+
+	if _, err := file.WriteAt(data, position); err != nil {
+		return nil, fmt.Errorf("failed to append file %s: %w", filePath, err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync file %s: %w", filePath, err)
+	}
+
+	return fs.Read(ctx, bucket, key)
+}
+
+// Truncate 将对象截断到指定大小（用于回滚追加）
+func (fs *FileStorageNode) Truncate(ctx context.Context, bucket, key string, size int64) error {
+	filePath := fs.buildFilePath(ctx, bucket, key)
+
+	// 截断到0等价于撤销创建
+	if size == 0 {
+		return fs.Delete(ctx, bucket, key)
+	}
+
+	if err := os.Truncate(filePath, size); err != nil {
+		return fmt.Errorf("failed to truncate file %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
 // Delete 删除对象
 func (fs *FileStorageNode) Delete(ctx context.Context, bucket, key string) error {
-	filePath := fs.buildFilePath(bucket, key)
+	filePath := fs.buildFilePath(ctx, bucket, key)
 
 	// 检查文件是否存在
 	if _, err := os.Stat(filePath); err != nil {
@@ -184,7 +236,7 @@ func (fs *FileStorageNode) IsHealthy(ctx context.Context) bool {
 
 // ListObjects 列出对象（目录遍历）
 func (fs *FileStorageNode) ListObjects(ctx context.Context, bucket, prefix string, limit int) ([]*models.ObjectInfo, error) {
-	bucketPath := filepath.Join(fs.basePath, bucket)
+	bucketPath := fs.buildBucketPath(ctx, bucket)
 
 	// 检查bucket目录是否存在
 	if _, err := os.Stat(bucketPath); err != nil {
@@ -295,9 +347,21 @@ func (fs *FileStorageNode) GetStats(ctx context.Context) (map[string]interface{}
 	return stats, nil
 }
 
+// TenantsDir 非默认租户的对象目录，保留为不可用的bucket名
+const TenantsDir = "_tenants"
+
+// buildBucketPath 构建ctx中租户的bucket目录，默认租户沿用启用多租户前的布局
+func (fs *FileStorageNode) buildBucketPath(ctx context.Context, bucket string) string {
+	tenant := models.TenantFromContext(ctx)
+	if tenant == models.DefaultTenant {
+		return filepath.Join(fs.basePath, bucket)
+	}
+	return filepath.Join(fs.basePath, TenantsDir, tenant, bucket)
+}
+
 // buildFilePath 构建文件路径
-func (fs *FileStorageNode) buildFilePath(bucket, key string) string {
-	return filepath.Join(fs.basePath, bucket, key)
+func (fs *FileStorageNode) buildFilePath(ctx context.Context, bucket, key string) string {
+	return filepath.Join(fs.buildBucketPath(ctx, bucket), key)
 }
 
 // detectContentType 检测内容类型
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"mocks3/services/storage/internal/config"
+	"net/http"
+)
+
+// applyBucketPolicy 将bucket策略中的默认头和标签合并到对象头和标签中
+//
+// 先应用"*"策略再应用bucket自身的策略，同名项以后者为准；
+// 非enforce策略不会覆盖请求中已有的值。
+func (s *StorageService) applyBucketPolicy(bucket string, headers, tags map[string]string) (map[string]string, map[string]string) {
+	if len(s.config.BucketPolicies) == 0 {
+		return headers, tags
+	}
+
+	policies := make([]config.BucketPolicyConfig, 0, 2)
+	if policy, ok := s.config.BucketPolicies[config.BucketPolicyWildcard]; ok {
+		policies = append(policies, policy)
+	}
+	if policy, ok := s.config.BucketPolicies[bucket]; ok && bucket != config.BucketPolicyWildcard {
+		policies = append(policies, policy)
+	}
+	if len(policies) == 0 {
+		return headers, tags
+	}
+
+	requestHeaders := make(map[string]bool, len(headers))
+	mergedHeaders := make(map[string]string, len(headers))
+	for name, value := range headers {
+		requestHeaders[http.CanonicalHeaderKey(name)] = true
+		mergedHeaders[http.CanonicalHeaderKey(name)] = value
+	}
+	mergedTags := make(map[string]string, len(tags))
+	for name, value := range tags {
+		mergedTags[name] = value
+	}
+
+	for _, policy := range policies {
+		for name, value := range policy.Headers {
+			name = http.CanonicalHeaderKey(name)
+			if policy.Enforce || !requestHeaders[name] {
+				mergedHeaders[name] = value
+			}
+		}
+		for name, value := range policy.Tags {
+			if _, exists := tags[name]; policy.Enforce || !exists {
+				mergedTags[name] = value
+			}
+		}
+	}
+
+	return mergedHeaders, mergedTags
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"mocks3/shared/models"
+)
+
+// dedupLookupLimit 去重查找的候选数，排除对象自身后仍至少剩一个
+const dedupLookupLimit = 2
+
+// WriteObjectDedup 写入对象，开启写入去重时内容与已有对象相同则跳过写入并返回已有对象的引用
+//
+// 未开启去重、对象小于min_size或没有重复时等同于WriteObject，返回nil引用。
+func (s *StorageService) WriteObjectDedup(ctx context.Context, object *models.Object) (*models.ObjectReference, error) {
+	if !s.config.Dedup.Enabled || s.validateObject(object) != nil || object.Size < s.config.Dedup.MinSize {
+		return nil, s.WriteObject(ctx, object)
+	}
+
+	// 请求携带的MD5与内容不一致时交给WriteObject报告校验错误
+	hash := fmt.Sprintf("%x", md5.Sum(object.Data))
+	if object.MD5Hash != "" && object.MD5Hash != hash {
+		return nil, s.WriteObject(ctx, object)
+	}
+
+	existing, err := s.findDuplicate(ctx, object, hash)
+	if err != nil {
+		// 查找失败不影响写入
+		s.logger.WarnContext(ctx, "Failed to look up duplicate content, writing object", "error", err,
+			"bucket", object.Bucket, "key", object.Key)
+	}
+	if existing == nil {
+		return nil, s.WriteObject(ctx, object)
+	}
+
+	object.MD5Hash = existing.MD5Hash
+	object.ETag = existing.ETag
+	s.logger.InfoContext(ctx, "Duplicate content, skipped write",
+		"bucket", object.Bucket, "key", object.Key,
+		"duplicate_bucket", existing.Bucket, "duplicate_key", existing.Key)
+	return &models.ObjectReference{
+		ID:        existing.ID,
+		Bucket:    existing.Bucket,
+		Key:       existing.Key,
+		ETag:      existing.ETag,
+		CreatedAt: existing.CreatedAt,
+	}, nil
+}
+
+// findDuplicate 查找内容相同的其他对象（排除同名对象自身），没有时返回nil
+func (s *StorageService) findDuplicate(ctx context.Context, object *models.Object, hash string) (*models.Metadata, error) {
+	candidates, err := s.metadataClient.FindMetadataByHash(ctx, hash, object.Size, dedupLookupLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		if candidate.Bucket != object.Bucket || candidate.Key != object.Key {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
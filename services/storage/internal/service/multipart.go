@@ -0,0 +1,260 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/utils"
+	"strings"
+	"sync"
+	"time"
+)
+
+// multipartUpload 进行中的分片上传，分片数据保存在内存中直到完成、中止或过期
+type multipartUpload struct {
+	mu     sync.Mutex
+	upload *models.MultipartUpload
+	parts  map[int]*uploadedPart
+	size   int64 // 已上传分片的总字节数
+	done   bool  // 已完成、中止或过期，不再接受分片
+}
+
+// uploadedPart 已上传的分片
+type uploadedPart struct {
+	data []byte
+	md5  [md5.Size]byte
+}
+
+// etag 分片ETag（带引号的MD5）
+func (p *uploadedPart) etag() string {
+	return fmt.Sprintf("\"%x\"", p.md5)
+}
+
+// CreateMultipartUpload 创建分片上传
+func (s *StorageService) CreateMultipartUpload(ctx context.Context, upload *models.MultipartUpload) (*models.MultipartUpload, error) {
+	if err := s.validateBucketKey(upload.Bucket, upload.Key); err != nil {
+		return nil, &models.APIError{Code: models.ErrCodeInvalidArgument, Message: "invalid bucket or key", Details: err.Error()}
+	}
+
+	upload.UploadID = utils.NewID()
+	upload.Initiated = time.Now()
+	if upload.ContentType == "" {
+		upload.ContentType = "application/octet-stream"
+	}
+
+	s.multipartMu.Lock()
+	if limit := s.config.Multipart.MaxUploads; limit > 0 && s.multipartCount >= limit {
+		s.multipartMu.Unlock()
+		return nil, &models.APIError{Code: models.ErrCodeSlowDown, Message: "too many multipart uploads in progress", Details: fmt.Sprintf("limit %d", limit)}
+	}
+	s.multipartCount++
+	s.multipartMu.Unlock()
+
+	s.multipartUploads.Store(upload.UploadID, &multipartUpload{
+		upload: upload,
+		parts:  make(map[int]*uploadedPart),
+	})
+
+	s.logger.InfoContext(ctx, "Multipart upload created", "bucket", upload.Bucket, "key", upload.Key, "upload_id", upload.UploadID)
+	return upload, nil
+}
+
+// UploadPart 上传分片，同一编号重复上传时覆盖，返回分片ETag
+func (s *StorageService) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, data []byte) (string, error) {
+	if partNumber < 1 || partNumber > models.MaxPartNumber {
+		return "", &models.APIError{Code: models.ErrCodeInvalidArgument, Message: "part number must be an integer between 1 and 10000"}
+	}
+
+	mu, err := s.getMultipartUpload(bucket, key, uploadID)
+	if err != nil {
+		return "", err
+	}
+
+	part := &uploadedPart{data: data, md5: md5.Sum(data)}
+
+	mu.mu.Lock()
+	if mu.done {
+		mu.mu.Unlock()
+		return "", &models.APIError{Code: models.ErrCodeNoSuchUpload, Message: "the specified upload does not exist", Details: uploadID}
+	}
+	delta := int64(len(data))
+	if old, ok := mu.parts[partNumber]; ok {
+		delta -= int64(len(old.data))
+	}
+	if err := s.reserveMultipartBytes(delta); err != nil {
+		mu.mu.Unlock()
+		return "", err
+	}
+	mu.parts[partNumber] = part
+	mu.size += delta
+	mu.mu.Unlock()
+
+	s.logger.DebugContext(ctx, "Part uploaded", "bucket", bucket, "key", key, "upload_id", uploadID, "part_number", partNumber, "size", len(data))
+	return part.etag(), nil
+}
+
+// CompleteMultipartUpload 按提交的分片列表合并对象，对象ETag为各分片MD5拼接后的MD5加"-分片数"
+func (s *StorageService) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, req *models.CompleteMultipartUploadRequest) (*models.Object, error) {
+	mu, err := s.getMultipartUpload(bucket, key, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Parts) == 0 {
+		return nil, &models.APIError{Code: models.ErrCodeInvalidRequest, Message: "you must specify at least one part"}
+	}
+
+	mu.mu.Lock()
+	if mu.done {
+		mu.mu.Unlock()
+		return nil, &models.APIError{Code: models.ErrCodeNoSuchUpload, Message: "the specified upload does not exist", Details: uploadID}
+	}
+	var data bytes.Buffer
+	digests := make([]byte, 0, len(req.Parts)*md5.Size)
+	sizes := make([]int64, 0, len(req.Parts))
+	for i, completed := range req.Parts {
+		if i > 0 && completed.PartNumber <= req.Parts[i-1].PartNumber {
+			mu.mu.Unlock()
+			return nil, &models.APIError{Code: models.ErrCodeInvalidPartOrder, Message: "the list of parts was not in ascending order"}
+		}
+		part, ok := mu.parts[completed.PartNumber]
+		if !ok || strings.Trim(completed.ETag, "\"") != hex.EncodeToString(part.md5[:]) {
+			mu.mu.Unlock()
+			return nil, &models.APIError{
+				Code:    models.ErrCodeInvalidPart,
+				Message: "one or more of the specified parts could not be found or the entity tag did not match",
+				Details: fmt.Sprintf("part %d", completed.PartNumber),
+			}
+		}
+		data.Write(part.data)
+		digests = append(digests, part.md5[:]...)
+		sizes = append(sizes, int64(len(part.data)))
+	}
+	mu.mu.Unlock()
+
+	upload := mu.upload
+	headers := make(map[string]string, len(upload.Headers)+1)
+	for name, value := range upload.Headers {
+		headers[name] = value
+	}
+	headers[models.HeaderMultipartPartSizes] = models.FormatPartSizes(sizes)
+
+	digest := md5.Sum(digests)
+	now := time.Now()
+	object := &models.Object{
+		ID:          utils.NewID(),
+		Key:         key,
+		Bucket:      bucket,
+		Size:        int64(data.Len()),
+		ContentType: upload.ContentType,
+		ETag:        fmt.Sprintf("\"%x-%d\"", digest, len(req.Parts)),
+		Data:        data.Bytes(),
+		Headers:     headers,
+		Tags:        upload.Tags,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.WriteObject(ctx, object); err != nil {
+		return nil, fmt.Errorf("failed to write multipart object: %w", err)
+	}
+
+	s.removeMultipartUpload(uploadID, mu)
+	s.logger.InfoContext(ctx, "Multipart upload completed", "bucket", bucket, "key", key, "upload_id", uploadID, "parts", len(req.Parts), "size", object.Size)
+	return object, nil
+}
+
+// AbortMultipartUpload 中止分片上传并丢弃已上传的分片
+func (s *StorageService) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	mu, err := s.getMultipartUpload(bucket, key, uploadID)
+	if err != nil {
+		return err
+	}
+
+	if !s.removeMultipartUpload(uploadID, mu) {
+		return &models.APIError{Code: models.ErrCodeNoSuchUpload, Message: "the specified upload does not exist", Details: uploadID}
+	}
+	s.logger.InfoContext(ctx, "Multipart upload aborted", "bucket", bucket, "key", key, "upload_id", uploadID)
+	return nil
+}
+
+// StartMultipartSweeper 定期中止创建后超过upload_ttl未完成的分片上传，ctx取消后停止
+func (s *StorageService) StartMultipartSweeper(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Multipart.GetSweepInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.expireMultipartUploads(ctx, now)
+			}
+		}
+	}()
+}
+
+// expireMultipartUploads 中止创建时间早于now减upload_ttl的分片上传，返回中止的上传数
+func (s *StorageService) expireMultipartUploads(ctx context.Context, now time.Time) int {
+	deadline := now.Add(-s.config.Multipart.GetUploadTTL())
+	expired := 0
+	s.multipartUploads.Range(func(key, value any) bool {
+		mu := value.(*multipartUpload)
+		if mu.upload.Initiated.After(deadline) {
+			return true
+		}
+		if s.removeMultipartUpload(key.(string), mu) {
+			expired++
+			s.logger.InfoContext(ctx, "Multipart upload expired", "bucket", mu.upload.Bucket, "key", mu.upload.Key, "upload_id", mu.upload.UploadID, "initiated", mu.upload.Initiated)
+		}
+		return true
+	})
+	return expired
+}
+
+// removeMultipartUpload 移除分片上传并释放占用的配额，已被移除时返回false
+func (s *StorageService) removeMultipartUpload(uploadID string, mu *multipartUpload) bool {
+	mu.mu.Lock()
+	if mu.done {
+		mu.mu.Unlock()
+		return false
+	}
+	mu.done = true
+	size := mu.size
+	mu.parts = nil
+	mu.mu.Unlock()
+
+	s.multipartUploads.Delete(uploadID)
+	s.multipartMu.Lock()
+	s.multipartCount--
+	s.multipartBytes -= size
+	s.multipartMu.Unlock()
+	return true
+}
+
+// reserveMultipartBytes 为分片占用delta字节配额，超过max_bytes时拒绝；delta为负时释放
+func (s *StorageService) reserveMultipartBytes(delta int64) error {
+	s.multipartMu.Lock()
+	defer s.multipartMu.Unlock()
+
+	if limit := s.config.Multipart.MaxBytes; limit > 0 && delta > 0 && s.multipartBytes+delta > limit {
+		return &models.APIError{Code: models.ErrCodeSlowDown, Message: "multipart upload memory limit exceeded", Details: fmt.Sprintf("limit %d bytes", limit)}
+	}
+	s.multipartBytes += delta
+	return nil
+}
+
+// getMultipartUpload 获取属于指定对象的分片上传
+func (s *StorageService) getMultipartUpload(bucket, key, uploadID string) (*multipartUpload, error) {
+	value, ok := s.multipartUploads.Load(uploadID)
+	if !ok {
+		return nil, &models.APIError{Code: models.ErrCodeNoSuchUpload, Message: "the specified upload does not exist", Details: uploadID}
+	}
+	mu := value.(*multipartUpload)
+	if mu.upload.Bucket != bucket || mu.upload.Key != key {
+		return nil, &models.APIError{Code: models.ErrCodeNoSuchUpload, Message: "the specified upload does not exist", Details: uploadID}
+	}
+	return mu, nil
+}
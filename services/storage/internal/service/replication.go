@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mocks3/services/storage/internal/config"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReplicationOperationPrefix 向mock-error查询复制注入时的操作名前缀，完整操作名为 replication:<目标bucket>
+const ReplicationOperationPrefix = "replication:"
+
+// replicationServiceName 向mock-error查询注入时使用的服务名
+const replicationServiceName = "storage-service"
+
+// InjectionChecker 错误注入检查器（由MockErrorClient实现）
+type InjectionChecker interface {
+	CheckInjection(ctx context.Context, service, operation string) (*models.ErrorAction, bool, error)
+}
+
+// replicationTask 待复制的对象
+type replicationTask struct {
+	id          uint64
+	destination string
+	bucket      string
+	key         string
+	writtenAt   time.Time
+	attempt     int
+}
+
+// destinationStats 复制目标的统计
+type destinationStats struct {
+	sources          []string
+	pending          map[uint64]time.Time // 任务ID -> 源对象写入时间
+	replicated       int64
+	failed           int64
+	failures         map[string]int64
+	lastLag          time.Duration
+	maxLag           time.Duration
+	lastReplicatedAt time.Time
+}
+
+// Replicator bucket复制模拟器
+//
+// 写入源bucket的对象进入内存队列，由worker异步复制到目标bucket；
+// 每次复制前向mock-error查询注入，delay动作用于制造可控的复制延迟，其他动作视为复制失败并按配置重试。
+type Replicator struct {
+	config   config.ReplicationConfig
+	service  *StorageService
+	rules    map[string][]string // 源bucket -> 目标bucket
+	injector InjectionChecker
+	logger   *observability.Logger
+	metrics  *observability.MetricCollector
+	tasks    chan *replicationTask
+	nextID   atomic.Uint64
+
+	mu           sync.Mutex
+	destinations map[string]*destinationStats
+}
+
+// NewReplicator 创建复制模拟器
+func NewReplicator(cfg config.ReplicationConfig, service *StorageService, logger *observability.Logger) *Replicator {
+	r := &Replicator{
+		config:       cfg,
+		service:      service,
+		rules:        make(map[string][]string),
+		logger:       logger,
+		tasks:        make(chan *replicationTask, cfg.QueueSize),
+		destinations: make(map[string]*destinationStats),
+	}
+
+	for _, rule := range cfg.Rules {
+		r.rules[rule.SourceBucket] = append(r.rules[rule.SourceBucket], rule.DestinationBucket)
+		stats, ok := r.destinations[rule.DestinationBucket]
+		if !ok {
+			stats = &destinationStats{
+				pending:  make(map[uint64]time.Time),
+				failures: make(map[string]int64),
+			}
+			r.destinations[rule.DestinationBucket] = stats
+		}
+		stats.sources = append(stats.sources, rule.SourceBucket)
+	}
+
+	return r
+}
+
+// SetInjectionChecker 设置错误注入检查器
+func (r *Replicator) SetInjectionChecker(checker InjectionChecker) {
+	r.injector = checker
+}
+
+// SetMetricCollector 设置指标收集器
+func (r *Replicator) SetMetricCollector(metrics *observability.MetricCollector) {
+	r.metrics = metrics
+}
+
+// Start 启动复制worker，ctx取消后停止
+func (r *Replicator) Start(ctx context.Context) {
+	for i := 0; i < r.config.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case task := <-r.tasks:
+					r.process(ctx, task)
+				}
+			}
+		}()
+	}
+}
+
+// Enqueue 对象写入后按规则加入复制队列
+func (r *Replicator) Enqueue(ctx context.Context, bucket, key string) {
+	destinations := r.rules[bucket]
+	if len(destinations) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, destination := range destinations {
+		task := &replicationTask{
+			id:          r.nextID.Add(1),
+			destination: destination,
+			bucket:      bucket,
+			key:         key,
+			writtenAt:   now,
+		}
+
+		r.mu.Lock()
+		r.destinations[destination].pending[task.id] = task.writtenAt
+		r.mu.Unlock()
+		if r.metrics != nil {
+			r.metrics.AddReplicationPending(ctx, destination, 1)
+		}
+
+		r.submit(ctx, task)
+	}
+}
+
+// submit 将任务放入队列，队列已满时放弃该对象
+func (r *Replicator) submit(ctx context.Context, task *replicationTask) {
+	select {
+	case r.tasks <- task:
+	default:
+		r.logger.WarnContext(ctx, "Replication queue full, dropping object",
+			"bucket", task.bucket, "key", task.key, "destination", task.destination)
+		r.recordFailure(ctx, task, models.ReplicationFailureQueueFull)
+		r.finish(ctx, task, false)
+	}
+}
+
+// process 执行一次复制尝试
+func (r *Replicator) process(ctx context.Context, task *replicationTask) {
+	task.attempt++
+
+	reason, err := r.replicate(ctx, task)
+	if err == nil {
+		r.finish(ctx, task, true)
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	r.logger.WarnContext(ctx, "Replication attempt failed",
+		"bucket", task.bucket, "key", task.key, "destination", task.destination,
+		"attempt", task.attempt, "reason", reason, "error", err)
+	r.recordFailure(ctx, task, reason)
+
+	if task.attempt >= r.config.MaxAttempts {
+		r.finish(ctx, task, false)
+		return
+	}
+
+	time.AfterFunc(r.config.GetRetryInterval(), func() {
+		if ctx.Err() == nil {
+			r.submit(ctx, task)
+		}
+	})
+}
+
+// replicate 将源对象复制到目标bucket，失败时返回失败原因
+func (r *Replicator) replicate(ctx context.Context, task *replicationTask) (string, error) {
+	if r.injector != nil {
+		action, inject, err := r.injector.CheckInjection(ctx, replicationServiceName, ReplicationOperationPrefix+task.destination)
+		if err != nil {
+			r.logger.WarnContext(ctx, "Failed to check replication injection", "error", err)
+		} else if inject {
+			if action.Type != models.ErrorActionTypeDelay {
+				return action.Type, fmt.Errorf("injected %s: %s", action.Type, action.Message)
+			}
+			if action.Delay != nil {
+				select {
+				case <-ctx.Done():
+					return "", ctx.Err()
+				case <-time.After(*action.Delay):
+				}
+			}
+		}
+	}
+
+	source, err := r.service.ReadObject(ctx, task.bucket, task.key)
+	if err != nil {
+		return models.ReplicationFailureSourceRead, err
+	}
+
+	headers := make(map[string]string, len(source.Headers)+1)
+	for name, value := range source.Headers {
+		headers[name] = value
+	}
+	headers[models.HeaderReplicationStatus] = models.ReplicationStatusReplica
+
+	tags := make(map[string]string, len(source.Tags))
+	for name, value := range source.Tags {
+		tags[name] = value
+	}
+
+	now := time.Now()
+	replica := &models.Object{
+		ID:          utils.NewID(),
+		Key:         source.Key,
+		Bucket:      task.destination,
+		Size:        source.Size,
+		ContentType: source.ContentType,
+		MD5Hash:     source.MD5Hash,
+		ETag:        source.ETag,
+		Data:        source.Data,
+		Headers:     headers,
+		Tags:        tags,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := r.service.WriteObject(ctx, replica); err != nil {
+		return models.ReplicationFailureDestinationWrite, err
+	}
+
+	return "", nil
+}
+
+// recordFailure 记录一次失败的复制尝试
+func (r *Replicator) recordFailure(ctx context.Context, task *replicationTask, reason string) {
+	r.mu.Lock()
+	r.destinations[task.destination].failures[reason]++
+	r.mu.Unlock()
+
+	if r.metrics != nil {
+		r.metrics.RecordReplicationFailure(ctx, task.destination, reason)
+	}
+}
+
+// finish 任务结束（复制成功或放弃），从待复制列表移除
+func (r *Replicator) finish(ctx context.Context, task *replicationTask, replicated bool) {
+	now := time.Now()
+	lag := now.Sub(task.writtenAt)
+
+	r.mu.Lock()
+	stats := r.destinations[task.destination]
+	delete(stats.pending, task.id)
+	if replicated {
+		stats.replicated++
+		stats.lastLag = lag
+		if lag > stats.maxLag {
+			stats.maxLag = lag
+		}
+		stats.lastReplicatedAt = now
+	} else {
+		stats.failed++
+	}
+	r.mu.Unlock()
+
+	if r.metrics != nil {
+		r.metrics.AddReplicationPending(ctx, task.destination, -1)
+		if replicated {
+			r.metrics.RecordReplicationLag(ctx, task.destination, lag)
+		}
+	}
+
+	if replicated {
+		r.logger.DebugContext(ctx, "Object replicated",
+			"bucket", task.bucket, "key", task.key, "destination", task.destination, "lag", lag.String())
+	} else {
+		r.logger.ErrorContext(ctx, "Replication failed permanently",
+			"bucket", task.bucket, "key", task.key, "destination", task.destination, "attempts", task.attempt)
+	}
+}
+
+// Report 获取各复制目标的状态
+func (r *Replicator) Report() *models.ReplicationReport {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &models.ReplicationReport{
+		Destinations: make([]*models.ReplicationDestinationStatus, 0, len(r.destinations)),
+		GeneratedAt:  now,
+	}
+	for destination, stats := range r.destinations {
+		status := &models.ReplicationDestinationStatus{
+			Destination:    destination,
+			SourceBuckets:  append([]string(nil), stats.sources...),
+			Pending:        int64(len(stats.pending)),
+			Replicated:     stats.replicated,
+			Failed:         stats.failed,
+			Failures:       make(map[string]int64, len(stats.failures)),
+			LastLagSeconds: stats.lastLag.Seconds(),
+			MaxLagSeconds:  stats.maxLag.Seconds(),
+			LagSeconds:     stats.lastLag.Seconds(),
+		}
+		for reason, count := range stats.failures {
+			status.Failures[reason] = count
+		}
+
+		var oldest time.Time
+		for _, writtenAt := range stats.pending {
+			if oldest.IsZero() || writtenAt.Before(oldest) {
+				oldest = writtenAt
+			}
+		}
+		if !oldest.IsZero() {
+			status.OldestPendingAt = &oldest
+			status.LagSeconds = now.Sub(oldest).Seconds()
+		}
+		if !stats.lastReplicatedAt.IsZero() {
+			lastReplicatedAt := stats.lastReplicatedAt
+			status.LastReplicatedAt = &lastReplicatedAt
+		}
+
+		report.Destinations = append(report.Destinations, status)
+	}
+
+	sort.Slice(report.Destinations, func(i, j int) bool {
+		return report.Destinations[i].Destination < report.Destinations[j].Destination
+	})
+	return report
+}
@@ -2,22 +2,49 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mocks3/services/storage/internal/config"
 	"mocks3/services/storage/internal/repository"
 	"mocks3/shared/client"
 	"mocks3/shared/models"
 	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"strings"
+	"sync"
 	"time"
 )
 
+// metadataClient 存储服务使用的元数据服务调用，HTTP与gRPC客户端均实现
+type metadataClient interface {
+	SaveMetadata(ctx context.Context, metadata *models.Metadata) error
+	GetMetadata(ctx context.Context, bucket, key string) (*models.Metadata, error)
+	DeleteMetadata(ctx context.Context, bucket, key string) error
+	FindMetadataByHash(ctx context.Context, hash string, size int64, limit int) ([]*models.Metadata, error)
+	ValidateMetadataSchema(ctx context.Context, metadata *models.Metadata) error
+	HealthCheck(ctx context.Context) error
+}
+
+var (
+	_ metadataClient = (*client.MetadataClient)(nil)
+	_ metadataClient = (*client.MetadataGRPCClient)(nil)
+)
+
 // StorageService 存储服务实现
 type StorageService struct {
 	config           *config.Config
 	storageManager   *repository.StorageManager
-	metadataClient   *client.MetadataClient
+	metadataClient   metadataClient
 	thirdPartyClient *client.ThirdPartyClient
 	logger           *observability.Logger
+	metrics          *observability.MetricCollector
+	auditLog         *observability.AuditLogger
+	appendLocks      sync.Map // bucket/key -> *sync.Mutex，串行化同一对象的追加
+	multipartUploads sync.Map // uploadID -> *multipartUpload
+	multipartMu      sync.Mutex
+	multipartCount   int   // 进行中的分片上传数
+	multipartBytes   int64 // 进行中的分片上传已上传分片的总字节数
+	replicator       *Replicator
 }
 
 // NewStorageService 创建存储服务
@@ -28,7 +55,7 @@ func NewStorageService(cfg *config.Config, logger *observability.Logger) (*Stora
 	}
 
 	// 创建存储管理器
-	storageManager := repository.NewStorageManager()
+	storageManager := repository.NewStorageManager(logger)
 
 	// 初始化存储节点
 	for _, nodeConfig := range cfg.Storage.Nodes {
@@ -47,7 +74,18 @@ func NewStorageService(cfg *config.Config, logger *observability.Logger) (*Stora
 	if err != nil {
 		metadataTimeout = 30 * time.Second
 	}
-	metadataClient := client.NewMetadataClient(cfg.Metadata.ServiceURL, metadataTimeout)
+	var metadataClient metadataClient
+	if cfg.Metadata.Transport == config.MetadataTransportGRPC {
+		grpcClient, err := client.NewMetadataGRPCClient(cfg.Metadata.GRPCAddress, metadataTimeout)
+		if err != nil {
+			return nil, err
+		}
+		metadataClient = grpcClient
+		logger.Info(context.Background(), "Metadata service gRPC client initialized",
+			observability.String("address", cfg.Metadata.GRPCAddress))
+	} else {
+		metadataClient = client.NewMetadataClient(cfg.Metadata.ServiceURL, metadataTimeout)
+	}
 
 	// 创建第三方服务客户端
 	var thirdPartyClient *client.ThirdPartyClient
@@ -72,6 +110,31 @@ func NewStorageService(cfg *config.Config, logger *observability.Logger) (*Stora
 	}, nil
 }
 
+// SetMetricCollector 设置指标收集器
+func (s *StorageService) SetMetricCollector(metrics *observability.MetricCollector) {
+	s.metrics = metrics
+}
+
+// SetAuditLogger 设置审计日志器，对象删除写入审计日志
+func (s *StorageService) SetAuditLogger(auditLog *observability.AuditLogger) {
+	s.auditLog = auditLog
+}
+
+// UseOutboundFaultTransport 元数据（HTTP传输）和第三方服务客户端的出站请求经过错误注入传输层
+func (s *StorageService) UseOutboundFaultTransport(checker client.FaultChecker, checkTimeout time.Duration) {
+	if httpClient, ok := s.metadataClient.(*client.MetadataClient); ok {
+		httpClient.UseFaultTransport(checker, "storage-service", checkTimeout)
+	}
+	if s.thirdPartyClient != nil {
+		s.thirdPartyClient.UseFaultTransport(checker, "storage-service", checkTimeout)
+	}
+}
+
+// SetReplicator 设置bucket复制模拟器，写入成功的对象按规则异步复制
+func (s *StorageService) SetReplicator(replicator *Replicator) {
+	s.replicator = replicator
+}
+
 // WriteObject 写入对象
 func (s *StorageService) WriteObject(ctx context.Context, object *models.Object) error {
 	s.logger.InfoContext(ctx, "Writing object", "bucket", object.Bucket, "key", object.Key, "size", object.Size)
@@ -82,6 +145,19 @@ func (s *StorageService) WriteObject(ctx context.Context, object *models.Object)
 		return fmt.Errorf("invalid object: %w", err)
 	}
 
+	// 合并bucket策略中的默认头和标签
+	object.Headers, object.Tags = s.applyBucketPolicy(object.Bucket, object.Headers, object.Tags)
+
+	// 写入前按bucket schema预检自定义元数据，避免覆盖写入被拒绝后回滚删除已有对象的数据
+	if err := s.metadataClient.ValidateMetadataSchema(ctx, s.objectToMetadata(object)); err != nil {
+		var validationErr *models.SchemaValidationError
+		if errors.As(err, &validationErr) {
+			return fmt.Errorf("failed to save metadata: %w", err)
+		}
+		// 预检失败不阻止写入，保存元数据时仍会校验
+		s.logger.WarnContext(ctx, "Failed to validate metadata schema", "error", err)
+	}
+
 	// 写入存储节点
 	if err := s.storageManager.WriteToAllNodes(ctx, object); err != nil {
 		s.logger.ErrorContext(ctx, "Failed to write to storage nodes", "error", err)
@@ -99,6 +175,11 @@ func (s *StorageService) WriteObject(ctx context.Context, object *models.Object)
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
+	// 副本对象不再复制
+	if s.replicator != nil && object.Headers[models.HeaderReplicationStatus] != models.ReplicationStatusReplica {
+		s.replicator.Enqueue(ctx, object.Bucket, object.Key)
+	}
+
 	s.logger.InfoContext(ctx, "Object written successfully", "bucket", object.Bucket, "key", object.Key)
 	return nil
 }
@@ -136,7 +217,7 @@ func (s *StorageService) ReadObject(ctx context.Context, bucket, key string) (*m
 
 			// 异步缓存到本地存储
 			go func() {
-				cacheCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				cacheCtx, cancel := context.WithTimeout(models.WithTenant(context.Background(), models.TenantFromContext(ctx)), 30*time.Second)
 				defer cancel()
 
 				if writeErr := s.storageManager.WriteToAllNodes(cacheCtx, thirdPartyObject); writeErr != nil {
@@ -156,6 +237,9 @@ func (s *StorageService) ReadObject(ctx context.Context, bucket, key string) (*m
 
 	// 如果元数据存在，合并一些信息
 	if metadata != nil {
+		if metadata.ETag != "" {
+			object.ETag = metadata.ETag // 分片上传对象的ETag不是内容MD5
+		}
 		object.Headers = metadata.Headers
 		object.Tags = metadata.Tags
 		object.CreatedAt = metadata.CreatedAt
@@ -166,18 +250,87 @@ func (s *StorageService) ReadObject(ctx context.Context, bucket, key string) (*m
 	return object, nil
 }
 
-// DeleteObject 删除对象
-func (s *StorageService) DeleteObject(ctx context.Context, bucket, key string) error {
+// HeadObject 获取对象元信息（不读取数据）
+func (s *StorageService) HeadObject(ctx context.Context, bucket, key string) (*models.ObjectInfo, error) {
+	s.logger.DebugContext(ctx, "Heading object", "bucket", bucket, "key", key)
+
+	if err := s.validateBucketKey(bucket, key); err != nil {
+		return nil, fmt.Errorf("invalid bucket or key: %w", err)
+	}
+
+	// 优先使用元数据，避免读取对象内容
+	metadata, err := s.metadataClient.GetMetadata(ctx, bucket, key)
+	if err == nil && metadata.Key != "" {
+		return &models.ObjectInfo{
+			ID:          metadata.ID,
+			Key:         metadata.Key,
+			Bucket:      metadata.Bucket,
+			Size:        metadata.Size,
+			ContentType: metadata.ContentType,
+			MD5Hash:     metadata.MD5Hash,
+			ETag:        metadata.ETag,
+			Headers:     metadata.Headers,
+			Tags:        metadata.Tags,
+			CreatedAt:   metadata.CreatedAt,
+			UpdatedAt:   metadata.UpdatedAt,
+		}, nil
+	}
+
+	s.logger.WarnContext(ctx, "Metadata not found, falling back to storage", "bucket", bucket, "key", key)
+
+	object, err := s.storageManager.ReadFromBestNode(ctx, bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("object not found: %w", err)
+	}
+
+	return &models.ObjectInfo{
+		ID:          object.ID,
+		Key:         object.Key,
+		Bucket:      object.Bucket,
+		Size:        object.Size,
+		ContentType: object.ContentType,
+		MD5Hash:     object.MD5Hash,
+		ETag:        object.ETag,
+		Headers:     object.Headers,
+		Tags:        object.Tags,
+		CreatedAt:   object.CreatedAt,
+		UpdatedAt:   object.UpdatedAt,
+	}, nil
+}
+
+// DeleteObject 删除对象，对象处于法律保留或保留期内时返回*models.ObjectHoldError，
+// 仍在bucket保留期内时返回*models.RetentionViolationError
+func (s *StorageService) DeleteObject(ctx context.Context, req *models.DeleteObjectRequest) (err error) {
+	bucket, key := req.Bucket, req.Key
+	defer func() { s.recordDeleteAudit(ctx, req, err) }()
 	s.logger.InfoContext(ctx, "Deleting object", "bucket", bucket, "key", key)
 
 	if err := s.validateBucketKey(bucket, key); err != nil {
 		return fmt.Errorf("invalid bucket or key: %w", err)
 	}
 
+	if err := s.checkObjectHold(ctx, req); err != nil {
+		return err
+	}
+
 	// 先删除元数据
 	if err := s.metadataClient.DeleteMetadata(ctx, bucket, key); err != nil {
-		s.logger.WarnContext(ctx, "Failed to delete metadata", "error", err)
-		// 元数据删除失败不阻止存储删除
+		// 仍在bucket保留期内的对象不删除存储
+		var violation *models.RetentionViolationError
+		if errors.As(err, &violation) {
+			s.logger.WarnContext(ctx, "Delete blocked by bucket retention", "bucket", bucket, "key", key,
+				"retain_until", violation.RetainUntil)
+			if s.metrics != nil {
+				s.metrics.RecordBlockedDelete(ctx, bucket, models.HoldReasonBucketRetention)
+			}
+			return violation
+		}
+		// 仅元数据已不存在时继续清理存储，其他失败无法确认删除是否被允许
+		if !errors.Is(err, models.ErrMetadataNotFound) {
+			s.logger.ErrorContext(ctx, "Failed to delete metadata", "bucket", bucket, "key", key, "error", err)
+			return fmt.Errorf("failed to delete metadata: %w", err)
+		}
+		s.logger.WarnContext(ctx, "Metadata already deleted", "bucket", bucket, "key", key)
 	}
 
 	// 删除存储文件
@@ -190,6 +343,212 @@ func (s *StorageService) DeleteObject(ctx context.Context, bucket, key string) e
 	return nil
 }
 
+// recordDeleteAudit 记录对象删除的审计日志，被保留设置阻止的删除记录为失败
+func (s *StorageService) recordDeleteAudit(ctx context.Context, req *models.DeleteObjectRequest, err error) {
+	entry := &observability.AuditLogEntry{
+		Category: observability.AuditCategoryDelete,
+		Action:   "delete_object",
+		Resource: req.Bucket + "/" + req.Key,
+	}
+	if req.BypassGovernanceRetention {
+		entry.Details = map[string]any{"bypass_governance_retention": true}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	s.auditLog.Record(ctx, entry)
+}
+
+// checkObjectHold 检查对象的保留设置是否阻止删除
+func (s *StorageService) checkObjectHold(ctx context.Context, req *models.DeleteObjectRequest) error {
+	metadata, err := s.metadataClient.GetMetadata(ctx, req.Bucket, req.Key)
+	if errors.Is(err, models.ErrMetadataNotFound) {
+		// 元数据不存在时没有保留设置
+		return nil
+	}
+	if err != nil {
+		// 无法确认保留设置时拒绝删除
+		return fmt.Errorf("failed to check object hold: %w", err)
+	}
+	if metadata.Key == "" {
+		return nil
+	}
+
+	hold, err := models.ParseObjectHold(metadata.Headers)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Ignoring invalid object hold", "bucket", req.Bucket, "key", req.Key, "error", err)
+		return nil
+	}
+
+	reason := hold.BlockReason(time.Now(), req.BypassGovernanceRetention)
+	if reason == "" {
+		return nil
+	}
+
+	s.logger.WarnContext(ctx, "Delete blocked by object hold", "bucket", req.Bucket, "key", req.Key, "reason", reason)
+	if s.metrics != nil {
+		s.metrics.RecordBlockedDelete(ctx, req.Bucket, reason)
+	}
+
+	return &models.ObjectHoldError{
+		Bucket: req.Bucket,
+		Key:    req.Key,
+		Reason: reason,
+		Hold:   *hold,
+	}
+}
+
+// AppendObject 在指定位置追加对象数据，并同步更新元数据中的大小和ETag
+func (s *StorageService) AppendObject(ctx context.Context, bucket, key string, position int64, data []byte) (*models.Object, error) {
+	s.logger.InfoContext(ctx, "Appending object", "bucket", bucket, "key", key, "position", position, "size", len(data))
+
+	if err := s.validateBucketKey(bucket, key); err != nil {
+		return nil, fmt.Errorf("invalid bucket or key: %w", err)
+	}
+
+	if position < 0 {
+		return nil, fmt.Errorf("invalid position: %d", position)
+	}
+
+	// 同一对象的追加必须串行执行，保证存储与元数据一致
+	lock, _ := s.appendLocks.LoadOrStore(bucket+"/"+key, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+
+	object, appendedNodes, err := s.storageManager.AppendToAllNodes(ctx, bucket, key, position, data)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to append to storage nodes", "error", err)
+		return nil, fmt.Errorf("failed to append to storage: %w", err)
+	}
+
+	// 保留已有元数据中的属性
+	metadata := s.objectToMetadata(object)
+	if existing, err := s.metadataClient.GetMetadata(ctx, bucket, key); err == nil && existing.Key != "" {
+		metadata.ID = existing.ID
+		metadata.ContentType = existing.ContentType
+		metadata.Headers = existing.Headers
+		delete(metadata.Headers, models.HeaderMultipartPartSizes) // 追加后不再是分片上传对象
+		metadata.Tags = existing.Tags
+		metadata.CreatedAt = existing.CreatedAt
+	} else {
+		metadata.ID = utils.NewID()
+		metadata.Headers, metadata.Tags = s.applyBucketPolicy(bucket, metadata.Headers, metadata.Tags)
+		metadata.CreatedAt = time.Now()
+	}
+	metadata.UpdatedAt = time.Now()
+	metadata.StorageNodes = appendedNodes
+
+	if err := s.metadataClient.SaveMetadata(ctx, metadata); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save metadata, rolling back append", "error", err)
+		// 追加成功时所有节点都已追加，全部截断回追加前的大小
+		if truncErr := s.storageManager.TruncateOnNodes(ctx, appendedNodes, bucket, key, position); truncErr != nil {
+			s.logger.ErrorContext(ctx, "Failed to rollback append", "error", truncErr)
+		}
+		return nil, fmt.Errorf("failed to save metadata: %w", err)
+	}
+
+	object.ID = metadata.ID
+	object.ContentType = metadata.ContentType
+	object.Headers = metadata.Headers
+	object.Tags = metadata.Tags
+	object.CreatedAt = metadata.CreatedAt
+	object.UpdatedAt = metadata.UpdatedAt
+
+	if s.replicator != nil && object.Headers[models.HeaderReplicationStatus] != models.ReplicationStatusReplica {
+		s.replicator.Enqueue(ctx, bucket, key)
+	}
+
+	s.logger.InfoContext(ctx, "Object appended successfully", "bucket", bucket, "key", key, "size", object.Size)
+	return object, nil
+}
+
+// CopyObject 复制对象，支持x-amz-copy-source-if-*条件以及元数据/标签的COPY、REPLACE、MERGE指令
+func (s *StorageService) CopyObject(ctx context.Context, req *models.CopyObjectRequest) (*models.CopyObjectResult, error) {
+	s.logger.InfoContext(ctx, "Copying object",
+		"source_bucket", req.SourceBucket, "source_key", req.SourceKey,
+		"dest_bucket", req.DestBucket, "dest_key", req.DestKey)
+
+	if err := s.validateBucketKey(req.SourceBucket, req.SourceKey); err != nil {
+		return nil, &models.APIError{Code: models.ErrCodeInvalidArgument, Message: "invalid copy source", Details: err.Error()}
+	}
+	if err := s.validateBucketKey(req.DestBucket, req.DestKey); err != nil {
+		return nil, &models.APIError{Code: models.ErrCodeInvalidArgument, Message: "invalid copy destination", Details: err.Error()}
+	}
+
+	metadataDirective, err := normalizeCopyDirective(req.MetadataDirective)
+	if err != nil {
+		return nil, &models.APIError{Code: models.ErrCodeInvalidArgument, Message: "unknown metadata directive", Details: err.Error()}
+	}
+	taggingDirective, err := normalizeCopyDirective(req.TaggingDirective)
+	if err != nil {
+		return nil, &models.APIError{Code: models.ErrCodeInvalidArgument, Message: "unknown tagging directive", Details: err.Error()}
+	}
+
+	// 与S3一致：原地复制必须修改元数据或标签
+	if req.SourceBucket == req.DestBucket && req.SourceKey == req.DestKey &&
+		metadataDirective == models.CopyDirectiveCopy && taggingDirective == models.CopyDirectiveCopy {
+		return nil, &models.APIError{
+			Code:    models.ErrCodeInvalidRequest,
+			Message: "copy request is illegal because it tries to copy an object to itself without changing the object's metadata or tags",
+		}
+	}
+
+	source, err := s.ReadObject(ctx, req.SourceBucket, req.SourceKey)
+	if err != nil {
+		return nil, &models.APIError{Code: models.ErrCodeNoSuchKey, Message: "the specified copy source does not exist", Details: err.Error()}
+	}
+
+	if err := checkCopyConditions(req, source); err != nil {
+		s.logger.InfoContext(ctx, "Copy precondition failed", "error", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	dest := &models.Object{
+		ID:        utils.NewID(),
+		Key:       req.DestKey,
+		Bucket:    req.DestBucket,
+		Size:      source.Size,
+		Data:      source.Data,
+		Headers:   mergeCopyMap(metadataDirective, source.Headers, req.Headers),
+		Tags:      mergeCopyMap(taggingDirective, source.Tags, req.Tags),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	// 复制生成的对象不是分片上传对象，也不是bucket复制的副本
+	delete(dest.Headers, models.HeaderMultipartPartSizes)
+	delete(dest.Headers, models.HeaderReplicationStatus)
+
+	switch metadataDirective {
+	case models.CopyDirectiveReplace:
+		dest.ContentType = req.ContentType
+	case models.CopyDirectiveMerge:
+		dest.ContentType = req.ContentType
+		if dest.ContentType == "" {
+			dest.ContentType = source.ContentType
+		}
+	default:
+		dest.ContentType = source.ContentType
+	}
+	if dest.ContentType == "" {
+		dest.ContentType = "application/octet-stream"
+	}
+
+	if err := s.WriteObject(ctx, dest); err != nil {
+		return nil, fmt.Errorf("failed to write copied object: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Object copied successfully",
+		"source_bucket", req.SourceBucket, "source_key", req.SourceKey,
+		"dest_bucket", req.DestBucket, "dest_key", req.DestKey)
+
+	return &models.CopyObjectResult{
+		ETag:         dest.ETag,
+		LastModified: dest.UpdatedAt,
+	}, nil
+}
+
 // ListObjects 列出对象
 func (s *StorageService) ListObjects(ctx context.Context, req *models.ListObjectsRequest) (*models.ListObjectsResponse, error) {
 	s.logger.DebugContext(ctx, "Listing objects", "bucket", req.Bucket, "prefix", req.Prefix, "max_keys", req.MaxKeys)
@@ -232,6 +591,17 @@ func (s *StorageService) ListObjects(ctx context.Context, req *models.ListObject
 	return response, nil
 }
 
+// InspectReplicas 获取对象在各存储节点上的副本状态
+func (s *StorageService) InspectReplicas(ctx context.Context, bucket, key string) ([]*models.ObjectReplica, error) {
+	s.logger.DebugContext(ctx, "Inspecting replicas", "bucket", bucket, "key", key)
+
+	if err := s.validateBucketKey(bucket, key); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidArgument, err)
+	}
+
+	return s.storageManager.InspectReplicas(ctx, bucket, key), nil
+}
+
 // GetStats 获取存储统计信息
 func (s *StorageService) GetStats(ctx context.Context) (map[string]interface{}, error) {
 	s.logger.DebugContext(ctx, "Getting storage statistics")
@@ -283,6 +653,10 @@ func (s *StorageService) validateObject(object *models.Object) error {
 		return fmt.Errorf("bucket cannot be empty")
 	}
 
+	if object.Bucket == repository.TenantsDir {
+		return fmt.Errorf("bucket name %s is reserved", object.Bucket)
+	}
+
 	if object.Key == "" {
 		return fmt.Errorf("key cannot be empty")
 	}
@@ -338,3 +712,67 @@ func (s *StorageService) rollbackStorage(ctx context.Context, bucket, key string
 		s.logger.ErrorContext(ctx, "Failed to rollback storage", "error", err)
 	}
 }
+
+// normalizeCopyDirective 规范化复制指令，空值默认为COPY
+func normalizeCopyDirective(directive string) (string, error) {
+	switch d := strings.ToUpper(strings.TrimSpace(directive)); d {
+	case "":
+		return models.CopyDirectiveCopy, nil
+	case models.CopyDirectiveCopy, models.CopyDirectiveReplace, models.CopyDirectiveMerge:
+		return d, nil
+	default:
+		return "", fmt.Errorf("directive must be one of COPY, REPLACE, MERGE: %s", directive)
+	}
+}
+
+// mergeCopyMap 按指令合并源对象与请求中的键值
+func mergeCopyMap(directive string, source, request map[string]string) map[string]string {
+	result := make(map[string]string)
+	if directive != models.CopyDirectiveReplace {
+		for k, v := range source {
+			result[k] = v
+		}
+	}
+	if directive != models.CopyDirectiveCopy {
+		for k, v := range request {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// checkCopyConditions 校验x-amz-copy-source-if-*条件
+// 规则与S3一致：if-match成立时忽略if-unmodified-since；if-none-match不成立时忽略if-modified-since
+func checkCopyConditions(req *models.CopyObjectRequest, source *models.Object) error {
+	lastModified := source.UpdatedAt.Truncate(time.Second)
+
+	if req.IfMatch != "" {
+		if !etagMatches(req.IfMatch, source.ETag) {
+			return &models.APIError{Code: models.ErrCodePreconditionFailed, Message: "x-amz-copy-source-if-match condition failed"}
+		}
+	} else if req.IfUnmodifiedSince != nil && lastModified.After(*req.IfUnmodifiedSince) {
+		return &models.APIError{Code: models.ErrCodePreconditionFailed, Message: "x-amz-copy-source-if-unmodified-since condition failed"}
+	}
+
+	if req.IfNoneMatch != "" {
+		if etagMatches(req.IfNoneMatch, source.ETag) {
+			return &models.APIError{Code: models.ErrCodePreconditionFailed, Message: "x-amz-copy-source-if-none-match condition failed"}
+		}
+	} else if req.IfModifiedSince != nil && !lastModified.After(*req.IfModifiedSince) {
+		return &models.APIError{Code: models.ErrCodePreconditionFailed, Message: "x-amz-copy-source-if-modified-since condition failed"}
+	}
+
+	return nil
+}
+
+// etagMatches 比较ETag列表（忽略引号，"*"匹配任意对象）
+func etagMatches(condition, etag string) bool {
+	etag = strings.Trim(etag, "\"")
+	for _, candidate := range strings.Split(condition, ",") {
+		candidate = strings.Trim(strings.TrimSpace(candidate), "\"")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
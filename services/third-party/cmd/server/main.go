@@ -2,13 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"mocks3/services/third-party/internal/config"
 	"mocks3/services/third-party/internal/handler"
 	"mocks3/services/third-party/internal/repository"
 	"mocks3/services/third-party/internal/service"
+	"mocks3/shared/client"
 	"mocks3/shared/middleware"
+	"mocks3/shared/models"
 	"mocks3/shared/observability"
+	"mocks3/shared/utils"
 	"net/http"
 	"os"
 	"os/signal"
@@ -21,6 +25,9 @@ import (
 func main() {
 	// 加载配置
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// 初始化统一可观测性
 	obsConfig := &observability.Config{
@@ -29,6 +36,15 @@ func main() {
 		Environment:    cfg.Server.Environment,
 		OTLPEndpoint:   "http://localhost:4318",
 		LogLevel:       cfg.LogLevel,
+		Debug:          observability.DebugConfigFromEnv(),
+		Profiling:      observability.ProfilingConfigFromEnv(),
+		AuditLog:       observability.AuditLogConfigFromEnv(),
+		LogSampling:    observability.LogSamplingConfigFromEnv(),
+		LogFile:        observability.LogFileConfigFromEnv(),
+		SLOs: []observability.SLO{
+			observability.AvailabilitySLO("availability", 0.99),
+			observability.LatencySLO("latency", 0.95, time.Second),
+		},
 	}
 
 	obs, err := observability.New(context.Background(), obsConfig)
@@ -39,6 +55,13 @@ func main() {
 
 	logger := obs.Logger()
 
+	// 初始化全局ID生成器
+	idStrategy, err := utils.ConfigureIDGeneratorFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure ID generator: %v", err)
+	}
+	logger.Info(context.Background(), "ID generator configured", observability.String("strategy", idStrategy))
+
 	// 初始化Consul管理器
 	consulManager, err := middleware.NewDefaultConsulManager("third-party-service")
 	if err != nil {
@@ -84,9 +107,25 @@ func main() {
 	// 添加中间件
 	router.Use(gin.Logger())
 	router.Use(middleware.GinRecoveryMiddleware(middleware.DefaultRecoveryConfig()))
+	// 请求ID：沿用或生成X-Request-ID，日志和出站调用自动带上
+	router.Use(middleware.RequestIDMiddleware())
+	// baggage：读取上游透传的租户、测试运行ID和场景ID，日志和错误注入条件自动带上
+	router.Use(obs.GinBaggageMiddleware())
 	// 使用统一可观测性中间件
 	router.Use(obs.GinMiddleware())
 
+	// 管理接口的修改操作写入审计日志
+	router.Use(obs.AuditLogger().GinAdminMiddleware("/api/v1/admin"))
+
+	// 管理接口鉴权和飞行记录器
+	_, flightRecorder, err := setupAdmin(router, cfg, obs, client.NewStorageClient(cfg.FlightRecorder.StorageURL, 10*time.Second))
+	if err != nil {
+		log.Fatalf("Failed to set up admin endpoints: %v", err)
+	}
+
+	// 运行时修改日志级别，排查问题时临时切换到debug
+	middleware.NewLogLevelController(logger).RegisterAdminRoutes(router.Group("/api/v1/admin"))
+
 	// 设置路由
 	thirdPartyHandler.RegisterRoutes(router)
 
@@ -156,5 +195,68 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// 等待进行中的事件包上传完成
+	if flightRecorder != nil {
+		flightRecorder.Wait(ctx)
+	}
+
 	logger.Info(context.Background(), "Third-party service stopped")
 }
+
+// setupAdmin 安装管理接口鉴权和飞行记录器，未启用的功能返回nil；
+// gin在注册路由时固定处理链，鉴权中间件必须先于任何管理接口注册，否则这些接口不经过鉴权
+func setupAdmin(router *gin.Engine, cfg *config.Config, obs *observability.Observability, store middleware.IncidentStore) (*middleware.Authorizer, *middleware.FlightRecorder, error) {
+	var authorizer *middleware.Authorizer
+	if cfg.RBAC.Enabled {
+		var err error
+		authorizer, err = newAuthorizer(cfg.RBAC)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize authorizer: %w", err)
+		}
+		router.Use(authorizer.GinMiddleware())
+		authorizer.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	// 飞行记录器：保留最近的请求、日志和span，SLO违规或护栏触发时转储事件包到存储bucket
+	var flightRecorder *middleware.FlightRecorder
+	if cfg.FlightRecorder.Enabled {
+		var err error
+		flightRecorder, err = newFlightRecorder(cfg.FlightRecorder, store, obs.Logger())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize flight recorder: %w", err)
+		}
+		flightRecorder.SetMetricCollector(obs.Collector())
+		flightRecorder.Attach(obs)
+		router.Use(obs.GinTracingMiddleware())
+		router.Use(flightRecorder.GinMiddleware())
+		flightRecorder.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	return authorizer, flightRecorder, nil
+}
+
+// newAuthorizer 根据配置创建管理接口鉴权器（/api/v1/admin/*）
+func newAuthorizer(cfg config.RBACConfig) (*middleware.Authorizer, error) {
+	rbacConfig := middleware.DefaultRBACConfig()
+	rbacConfig.Header = cfg.Header
+	for _, key := range cfg.Keys {
+		rbacConfig.Keys = append(rbacConfig.Keys, &models.APIKey{Name: key.Name, Key: key.Key, Role: key.Role})
+	}
+	return middleware.NewAuthorizer(rbacConfig)
+}
+
+// newFlightRecorder 根据配置创建飞行记录器
+func newFlightRecorder(cfg config.FlightRecorderConfig, store middleware.IncidentStore, logger *observability.Logger) (*middleware.FlightRecorder, error) {
+	recorderConfig := middleware.DefaultFlightRecorderConfig("third-party-service")
+	recorderConfig.Bucket = cfg.Bucket
+	recorderConfig.RequestCapacity = cfg.RequestCapacity
+	recorderConfig.LogCapacity = cfg.LogCapacity
+	recorderConfig.SpanCapacity = cfg.SpanCapacity
+	recorderConfig.SLOWindow = time.Duration(cfg.SLOWindowSecs) * time.Second
+	recorderConfig.MinRequests = cfg.MinRequests
+	recorderConfig.MaxErrorRate = cfg.MaxErrorRate
+	recorderConfig.LatencyThreshold = time.Duration(cfg.LatencyThresholdMs) * time.Millisecond
+	recorderConfig.MaxSlowRate = cfg.MaxSlowRate
+	recorderConfig.Cooldown = time.Duration(cfg.CooldownSecs) * time.Second
+	return middleware.NewFlightRecorder(recorderConfig, store, logger)
+}
@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	"mocks3/shared/models"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // ServerConfig 服务器配置
@@ -42,12 +44,44 @@ type DataSourceConfig struct {
 	ExtraConfig map[string]string `json:"extra_config"`
 }
 
+// FlightRecorderConfig 飞行记录器配置：保留最近的请求、日志和span，SLO违规或护栏触发时转储到存储bucket
+type FlightRecorderConfig struct {
+	Enabled            bool    `json:"enabled"`
+	StorageURL         string  `json:"storage_url"`
+	Bucket             string  `json:"bucket"`
+	RequestCapacity    int     `json:"request_capacity"`
+	LogCapacity        int     `json:"log_capacity"`
+	SpanCapacity       int     `json:"span_capacity"`
+	SLOWindowSecs      int     `json:"slo_window_secs"`
+	MinRequests        int     `json:"min_requests"`   // 窗口内请求数少于该值时不判定SLO
+	MaxErrorRate       float64 `json:"max_error_rate"` // 5xx比例阈值，0表示不检查
+	LatencyThresholdMs int     `json:"latency_threshold_ms"`
+	MaxSlowRate        float64 `json:"max_slow_rate"` // 慢请求比例阈值，0表示不检查
+	CooldownSecs       int     `json:"cooldown_secs"` // 两次自动抓取的最小间隔
+}
+
+// RBACConfig 管理接口鉴权配置
+type RBACConfig struct {
+	Enabled bool           `json:"enabled"`
+	Header  string         `json:"header"`
+	Keys    []APIKeyConfig `json:"keys"`
+}
+
+// APIKeyConfig 初始API key
+type APIKeyConfig struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	Role string `json:"role"` // viewer, operator, chaos-admin, super-admin
+}
+
 // Config 应用配置
 type Config struct {
-	Server      ServerConfig       `json:"server"`
-	Cache       CacheConfig        `json:"cache"`
-	DataSources []DataSourceConfig `json:"data_sources"`
-	LogLevel    string             `json:"log_level"`
+	Server         ServerConfig         `json:"server"`
+	Cache          CacheConfig          `json:"cache"`
+	DataSources    []DataSourceConfig   `json:"data_sources"`
+	RBAC           RBACConfig           `json:"rbac"`
+	FlightRecorder FlightRecorderConfig `json:"flight_recorder"`
+	LogLevel       string               `json:"log_level"`
 }
 
 // Load 加载配置
@@ -87,12 +121,50 @@ func Load() *Config {
 				Priority: 2,
 			},
 		},
+		RBAC: RBACConfig{
+			Enabled: getEnvAsBool("RBAC_ENABLED", false),
+			Header:  getEnv("RBAC_HEADER", "X-Api-Key"),
+			Keys:    getEnvAsAPIKeys("RBAC_API_KEYS"),
+		},
+		FlightRecorder: FlightRecorderConfig{
+			Enabled:            getEnvAsBool("FLIGHT_RECORDER_ENABLED", false),
+			StorageURL:         getEnv("FLIGHT_RECORDER_STORAGE_URL", "http://localhost:8082"),
+			Bucket:             getEnv("FLIGHT_RECORDER_BUCKET", models.DefaultIncidentBucket),
+			RequestCapacity:    getEnvAsInt("FLIGHT_RECORDER_REQUEST_CAPACITY", 200),
+			LogCapacity:        getEnvAsInt("FLIGHT_RECORDER_LOG_CAPACITY", 500),
+			SpanCapacity:       getEnvAsInt("FLIGHT_RECORDER_SPAN_CAPACITY", 500),
+			SLOWindowSecs:      getEnvAsInt("FLIGHT_RECORDER_SLO_WINDOW_SECS", 60),
+			MinRequests:        getEnvAsInt("FLIGHT_RECORDER_MIN_REQUESTS", 20),
+			MaxErrorRate:       getEnvAsFloat("FLIGHT_RECORDER_MAX_ERROR_RATE", 0.1),
+			LatencyThresholdMs: getEnvAsInt("FLIGHT_RECORDER_LATENCY_THRESHOLD_MS", 1000),
+			MaxSlowRate:        getEnvAsFloat("FLIGHT_RECORDER_MAX_SLOW_RATE", 0.2),
+			CooldownSecs:       getEnvAsInt("FLIGHT_RECORDER_COOLDOWN_SECS", 300),
+		},
 		LogLevel: getEnv("LOG_LEVEL", "info"),
 	}
 
 	return config
 }
 
+// Validate 验证配置
+func (c *Config) Validate() error {
+	if c.RBAC.Enabled {
+		if c.RBAC.Header == "" {
+			return fmt.Errorf("rbac header is required")
+		}
+		for _, key := range c.RBAC.Keys {
+			if key.Name == "" || key.Key == "" {
+				return fmt.Errorf("rbac api key name and key are required")
+			}
+			if !models.IsValidRole(key.Role) {
+				return fmt.Errorf("rbac api key %s: unknown role %q", key.Name, key.Role)
+			}
+		}
+	}
+
+	return nil
+}
+
 // getEnv 获取环境变量，如果不存在则返回默认值
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -130,3 +202,33 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// getEnvAsFloat 获取环境变量并转换为float64
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsAPIKeys 获取环境变量并解析为API key列表，格式为 name:key:role，多个以逗号分隔
+func getEnvAsAPIKeys(key string) []APIKeyConfig {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var keys []APIKeyConfig
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 {
+			// 保留格式错误的条目，由Validate报告
+			keys = append(keys, APIKeyConfig{Name: entry})
+			continue
+		}
+		keys = append(keys, APIKeyConfig{Name: parts[0], Key: parts[1], Role: parts[2]})
+	}
+	return keys
+}
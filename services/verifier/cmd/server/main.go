@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mocks3/services/verifier/internal/config"
+	"mocks3/services/verifier/internal/handler"
+	"mocks3/services/verifier/internal/service"
+	"mocks3/shared/client"
+	"mocks3/shared/middleware"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	// 加载配置
+	cfg := config.Load()
+
+	// 验证配置
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// 初始化统一可观测性
+	obsConfig := &observability.Config{
+		ServiceName:    "verifier-service",
+		ServiceVersion: "1.0.0",
+		Environment:    cfg.Server.Environment,
+		OTLPEndpoint:   "http://localhost:4318",
+		LogLevel:       cfg.LogLevel,
+		Debug:          observability.DebugConfigFromEnv(),
+		Profiling:      observability.ProfilingConfigFromEnv(),
+		AuditLog:       observability.AuditLogConfigFromEnv(),
+		LogSampling:    observability.LogSamplingConfigFromEnv(),
+		LogFile:        observability.LogFileConfigFromEnv(),
+		SLOs: []observability.SLO{
+			observability.AvailabilitySLO("availability", 0.99),
+			observability.LatencySLO("latency", 0.95, 2*time.Second),
+		},
+	}
+
+	obs, err := observability.New(context.Background(), obsConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize observability: %v", err)
+	}
+	defer obs.Shutdown(context.Background())
+
+	logger := obs.Logger()
+
+	// 初始化全局ID生成器
+	idStrategy, err := utils.ConfigureIDGeneratorFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure ID generator: %v", err)
+	}
+	logger.Info(context.Background(), "ID generator configured", observability.String("strategy", idStrategy))
+
+	// 初始化Consul管理器
+	var consulManager *middleware.ConsulManager
+	if cfg.Consul.Enabled {
+		consulManager, err = middleware.NewDefaultConsulManager("verifier-service")
+		if err != nil {
+			log.Fatalf("Failed to initialize consul: %v", err)
+		}
+	}
+
+	// 初始化依赖服务客户端
+	metadataClient := client.NewMetadataClient(cfg.Services.MetadataURL, cfg.Services.Timeout())
+	storageClient := client.NewStorageClient(cfg.Services.StorageURL, cfg.Services.Timeout())
+
+	// 初始化校验服务
+	verifierService := service.NewVerifierService(&cfg.Verifier, metadataClient, storageClient, logger)
+	verifierService.SetMetricCollector(obs.Collector())
+	if cfg.Verifier.PublishEvents {
+		verifierService.SetEventPublisher(client.NewQueueClient(cfg.Services.QueueURL, cfg.Services.Timeout()))
+	}
+
+	// 后台持续校验
+	verifierCtx, stopVerifier := context.WithCancel(context.Background())
+	defer stopVerifier()
+	if cfg.Verifier.Enabled {
+		verifierService.Start(verifierCtx)
+	}
+
+	// 初始化处理器
+	verifierHandler := handler.NewVerifierHandler(verifierService, logger)
+
+	// 注册服务到Consul
+	ctx := context.Background()
+	if consulManager != nil {
+		consulConfig := &middleware.ConsulConfig{
+			ServiceName: "verifier-service",
+			ServicePort: cfg.Server.Port,
+			HealthPath:  "/health",
+			Tags:        []string{"verifier", "consistency", "invariants"},
+			Metadata: map[string]string{
+				"version":     cfg.Server.Version,
+				"environment": cfg.Server.Environment,
+			},
+		}
+
+		err = consulManager.RegisterService(ctx, consulConfig)
+		if err != nil {
+			log.Fatalf("Failed to register service: %v", err)
+		}
+		defer consulManager.DeregisterService(ctx)
+	}
+
+	// 设置Gin模式
+	if cfg.Server.Environment == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// 创建路由器
+	router := gin.New()
+
+	// 添加中间件
+	router.Use(gin.Logger())
+	router.Use(middleware.GinRecoveryMiddleware(middleware.DefaultRecoveryConfig()))
+	// 请求ID：沿用或生成X-Request-ID，日志和出站调用自动带上
+	router.Use(middleware.RequestIDMiddleware())
+	// baggage：读取上游透传的租户、测试运行ID和场景ID，日志和错误注入条件自动带上
+	router.Use(obs.GinBaggageMiddleware())
+	// 使用统一可观测性中间件
+	router.Use(obs.GinMiddleware())
+
+	// 管理接口的修改操作写入审计日志
+	router.Use(obs.AuditLogger().GinAdminMiddleware("/api/v1/admin"))
+
+	// 管理接口鉴权和飞行记录器
+	_, flightRecorder, err := setupAdmin(router, cfg, obs, client.NewStorageClient(cfg.FlightRecorder.StorageURL, 10*time.Second))
+	if err != nil {
+		log.Fatalf("Failed to set up admin endpoints: %v", err)
+	}
+
+	// 运行时修改日志级别，排查问题时临时切换到debug
+	middleware.NewLogLevelController(logger).RegisterAdminRoutes(router.Group("/api/v1/admin"))
+
+	// 设置路由
+	verifierHandler.RegisterRoutes(router)
+
+	// 健康检查
+	router.GET("/health", func(c *gin.Context) {
+		if err := verifierService.HealthCheck(c.Request.Context()); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":  "unhealthy",
+				"service": "verifier-service",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status":    "healthy",
+			"service":   "verifier-service",
+			"version":   cfg.Server.Version,
+			"timestamp": time.Now().Format(time.RFC3339),
+			"config": gin.H{
+				"enabled":          cfg.Verifier.Enabled,
+				"interval_seconds": cfg.Verifier.IntervalSeconds,
+				"sample_size":      cfg.Verifier.SampleSize,
+				"bucket":           cfg.Verifier.Bucket,
+				"publish_events":   cfg.Verifier.PublishEvents,
+			},
+		})
+	})
+
+	// 创建HTTP服务器
+	server := &http.Server{
+		Addr:         cfg.Server.GetAddress(),
+		Handler:      router,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 120 * time.Second, // 手动触发的整轮校验可能较慢
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// 启动服务器
+	go func() {
+		logger.Info(context.Background(), "Starting verifier service",
+			observability.String("address", cfg.Server.GetAddress()))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// 等待中断信号
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info(context.Background(), "Shutting down verifier service...")
+
+	// 停止后台校验
+	stopVerifier()
+
+	// 优雅关闭
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Fatalf("Server forced to shutdown: %v", err)
+	}
+
+	// 等待进行中的事件包上传完成
+	if flightRecorder != nil {
+		flightRecorder.Wait(ctx)
+	}
+
+	logger.Info(context.Background(), "Verifier service stopped")
+}
+
+// setupAdmin 安装管理接口鉴权和飞行记录器，未启用的功能返回nil；
+// gin在注册路由时固定处理链，鉴权中间件必须先于任何管理接口注册，否则这些接口不经过鉴权
+func setupAdmin(router *gin.Engine, cfg *config.Config, obs *observability.Observability, store middleware.IncidentStore) (*middleware.Authorizer, *middleware.FlightRecorder, error) {
+	var authorizer *middleware.Authorizer
+	if cfg.RBAC.Enabled {
+		var err error
+		authorizer, err = newAuthorizer(cfg.RBAC)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize authorizer: %w", err)
+		}
+		router.Use(authorizer.GinMiddleware())
+		authorizer.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	// 飞行记录器：保留最近的请求、日志和span，SLO违规或护栏触发时转储事件包到存储bucket
+	var flightRecorder *middleware.FlightRecorder
+	if cfg.FlightRecorder.Enabled {
+		var err error
+		flightRecorder, err = newFlightRecorder(cfg.FlightRecorder, store, obs.Logger())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize flight recorder: %w", err)
+		}
+		flightRecorder.SetMetricCollector(obs.Collector())
+		flightRecorder.Attach(obs)
+		router.Use(obs.GinTracingMiddleware())
+		router.Use(flightRecorder.GinMiddleware())
+		flightRecorder.RegisterAdminRoutes(router.Group("/api/v1/admin"))
+	}
+
+	return authorizer, flightRecorder, nil
+}
+
+// newAuthorizer 根据配置创建管理接口鉴权器（/api/v1/admin/*）
+func newAuthorizer(cfg config.RBACConfig) (*middleware.Authorizer, error) {
+	rbacConfig := middleware.DefaultRBACConfig()
+	rbacConfig.Header = cfg.Header
+	for _, key := range cfg.Keys {
+		rbacConfig.Keys = append(rbacConfig.Keys, &models.APIKey{Name: key.Name, Key: key.Key, Role: key.Role})
+	}
+	return middleware.NewAuthorizer(rbacConfig)
+}
+
+// newFlightRecorder 根据配置创建飞行记录器
+func newFlightRecorder(cfg config.FlightRecorderConfig, store middleware.IncidentStore, logger *observability.Logger) (*middleware.FlightRecorder, error) {
+	recorderConfig := middleware.DefaultFlightRecorderConfig("verifier-service")
+	recorderConfig.Bucket = cfg.Bucket
+	recorderConfig.RequestCapacity = cfg.RequestCapacity
+	recorderConfig.LogCapacity = cfg.LogCapacity
+	recorderConfig.SpanCapacity = cfg.SpanCapacity
+	recorderConfig.SLOWindow = time.Duration(cfg.SLOWindowSecs) * time.Second
+	recorderConfig.MinRequests = cfg.MinRequests
+	recorderConfig.MaxErrorRate = cfg.MaxErrorRate
+	recorderConfig.LatencyThreshold = time.Duration(cfg.LatencyThresholdMs) * time.Millisecond
+	recorderConfig.MaxSlowRate = cfg.MaxSlowRate
+	recorderConfig.Cooldown = time.Duration(cfg.CooldownSecs) * time.Second
+	return middleware.NewFlightRecorder(recorderConfig, store, logger)
+}
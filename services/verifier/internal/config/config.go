@@ -0,0 +1,295 @@
+package config
+
+import (
+	"fmt"
+	"mocks3/shared/models"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerConfig 服务器配置
+type ServerConfig struct {
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Environment string `json:"environment"`
+	Version     string `json:"version"`
+}
+
+// GetAddress 获取服务器地址
+func (s *ServerConfig) GetAddress() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
+// ConsulConfig Consul配置
+type ConsulConfig struct {
+	Address string `json:"address"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ServicesConfig 依赖服务地址
+type ServicesConfig struct {
+	MetadataURL    string `json:"metadata_url"`
+	StorageURL     string `json:"storage_url"`
+	QueueURL       string `json:"queue_url"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// Timeout 请求超时时间
+func (s *ServicesConfig) Timeout() time.Duration {
+	return time.Duration(s.TimeoutSeconds) * time.Second
+}
+
+// VerifierConfig 校验配置
+type VerifierConfig struct {
+	Enabled          bool   `json:"enabled"` // 是否启动后台持续校验，关闭时仅支持手动触发
+	IntervalSeconds  int    `json:"interval_seconds"`
+	SampleSize       int    `json:"sample_size"`          // 每轮校验的对象数
+	OrphanSampleSize int    `json:"orphan_sample_size"`   // 每轮抽查的存储对象数
+	OrphanGraceSecs  int    `json:"orphan_grace_seconds"` // 最近写入的对象可能尚未保存元数据，跳过该时间窗口内的对象
+	HistoryDepth     int    `json:"history_depth"`        // 版本单调性校验读取的历史版本数
+	Bucket           string `json:"bucket"`               // 为空时扫描所有bucket
+	Prefix           string `json:"prefix"`
+	MaxViolations    int    `json:"max_violations"` // 内存中保留的最近违规记录数
+	PublishEvents    bool   `json:"publish_events"` // 是否将违规事件发布到队列主题
+	Topic            string `json:"topic"`
+}
+
+// Interval 校验间隔
+func (v *VerifierConfig) Interval() time.Duration {
+	return time.Duration(v.IntervalSeconds) * time.Second
+}
+
+// OrphanGrace 孤儿对象检测的宽限期
+func (v *VerifierConfig) OrphanGrace() time.Duration {
+	return time.Duration(v.OrphanGraceSecs) * time.Second
+}
+
+// FlightRecorderConfig 飞行记录器配置：保留最近的请求、日志和span，SLO违规或护栏触发时转储到存储bucket
+type FlightRecorderConfig struct {
+	Enabled            bool    `json:"enabled"`
+	StorageURL         string  `json:"storage_url"`
+	Bucket             string  `json:"bucket"`
+	RequestCapacity    int     `json:"request_capacity"`
+	LogCapacity        int     `json:"log_capacity"`
+	SpanCapacity       int     `json:"span_capacity"`
+	SLOWindowSecs      int     `json:"slo_window_secs"`
+	MinRequests        int     `json:"min_requests"`   // 窗口内请求数少于该值时不判定SLO
+	MaxErrorRate       float64 `json:"max_error_rate"` // 5xx比例阈值，0表示不检查
+	LatencyThresholdMs int     `json:"latency_threshold_ms"`
+	MaxSlowRate        float64 `json:"max_slow_rate"` // 慢请求比例阈值，0表示不检查
+	CooldownSecs       int     `json:"cooldown_secs"` // 两次自动抓取的最小间隔
+}
+
+// RBACConfig 管理接口鉴权配置
+type RBACConfig struct {
+	Enabled bool           `json:"enabled"`
+	Header  string         `json:"header"`
+	Keys    []APIKeyConfig `json:"keys"`
+}
+
+// APIKeyConfig 初始API key
+type APIKeyConfig struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	Role string `json:"role"` // viewer, operator, chaos-admin, super-admin
+}
+
+// Config 应用配置
+type Config struct {
+	Server         ServerConfig         `json:"server"`
+	Consul         ConsulConfig         `json:"consul"`
+	Services       ServicesConfig       `json:"services"`
+	Verifier       VerifierConfig       `json:"verifier"`
+	RBAC           RBACConfig           `json:"rbac"`
+	FlightRecorder FlightRecorderConfig `json:"flight_recorder"`
+	LogLevel       string               `json:"log_level"`
+}
+
+// Load 加载配置
+func Load() *Config {
+	config := &Config{
+		Server: ServerConfig{
+			Host:        getEnv("SERVER_HOST", "0.0.0.0"),
+			Port:        getEnvAsInt("SERVER_PORT", 8086),
+			Environment: getEnv("ENVIRONMENT", "development"),
+			Version:     getEnv("VERSION", "1.0.0"),
+		},
+		Consul: ConsulConfig{
+			Address: getEnv("CONSUL_ADDR", "localhost:8500"),
+			Enabled: getEnvAsBool("CONSUL_ENABLED", true),
+		},
+		Services: ServicesConfig{
+			MetadataURL:    getEnv("METADATA_SERVICE_URL", "http://localhost:8081"),
+			StorageURL:     getEnv("STORAGE_SERVICE_URL", "http://localhost:8082"),
+			QueueURL:       getEnv("QUEUE_SERVICE_URL", "http://localhost:8083"),
+			TimeoutSeconds: getEnvAsInt("SERVICE_TIMEOUT_SECONDS", 10),
+		},
+		Verifier: VerifierConfig{
+			Enabled:          getEnvAsBool("VERIFIER_ENABLED", true),
+			IntervalSeconds:  getEnvAsInt("VERIFIER_INTERVAL_SECONDS", 30),
+			SampleSize:       getEnvAsInt("VERIFIER_SAMPLE_SIZE", 50),
+			OrphanSampleSize: getEnvAsInt("VERIFIER_ORPHAN_SAMPLE_SIZE", 20),
+			OrphanGraceSecs:  getEnvAsInt("VERIFIER_ORPHAN_GRACE_SECONDS", 60),
+			HistoryDepth:     getEnvAsInt("VERIFIER_HISTORY_DEPTH", 20),
+			Bucket:           getEnv("VERIFIER_BUCKET", ""),
+			Prefix:           getEnv("VERIFIER_PREFIX", ""),
+			MaxViolations:    getEnvAsInt("VERIFIER_MAX_VIOLATIONS", 1000),
+			PublishEvents:    getEnvAsBool("VERIFIER_PUBLISH_EVENTS", true),
+			Topic:            getEnv("VERIFIER_TOPIC", models.DefaultViolationTopic),
+		},
+		RBAC: RBACConfig{
+			Enabled: getEnvAsBool("RBAC_ENABLED", false),
+			Header:  getEnv("RBAC_HEADER", "X-Api-Key"),
+			Keys:    getEnvAsAPIKeys("RBAC_API_KEYS"),
+		},
+		FlightRecorder: FlightRecorderConfig{
+			Enabled:            getEnvAsBool("FLIGHT_RECORDER_ENABLED", false),
+			StorageURL:         getEnv("FLIGHT_RECORDER_STORAGE_URL", getEnv("STORAGE_SERVICE_URL", "http://localhost:8082")),
+			Bucket:             getEnv("FLIGHT_RECORDER_BUCKET", models.DefaultIncidentBucket),
+			RequestCapacity:    getEnvAsInt("FLIGHT_RECORDER_REQUEST_CAPACITY", 200),
+			LogCapacity:        getEnvAsInt("FLIGHT_RECORDER_LOG_CAPACITY", 500),
+			SpanCapacity:       getEnvAsInt("FLIGHT_RECORDER_SPAN_CAPACITY", 500),
+			SLOWindowSecs:      getEnvAsInt("FLIGHT_RECORDER_SLO_WINDOW_SECS", 60),
+			MinRequests:        getEnvAsInt("FLIGHT_RECORDER_MIN_REQUESTS", 20),
+			MaxErrorRate:       getEnvAsFloat("FLIGHT_RECORDER_MAX_ERROR_RATE", 0.1),
+			LatencyThresholdMs: getEnvAsInt("FLIGHT_RECORDER_LATENCY_THRESHOLD_MS", 1000),
+			MaxSlowRate:        getEnvAsFloat("FLIGHT_RECORDER_MAX_SLOW_RATE", 0.2),
+			CooldownSecs:       getEnvAsInt("FLIGHT_RECORDER_COOLDOWN_SECS", 300),
+		},
+		LogLevel: getEnv("LOG_LEVEL", "info"),
+	}
+
+	return config
+}
+
+// Validate 验证配置
+func (c *Config) Validate() error {
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		return fmt.Errorf("invalid server port: %d", c.Server.Port)
+	}
+
+	if c.Services.MetadataURL == "" || c.Services.StorageURL == "" {
+		return fmt.Errorf("metadata and storage service urls are required")
+	}
+
+	if c.Services.TimeoutSeconds <= 0 {
+		return fmt.Errorf("timeout_seconds must be positive")
+	}
+
+	if c.Verifier.IntervalSeconds <= 0 {
+		return fmt.Errorf("interval_seconds must be positive")
+	}
+
+	if c.Verifier.SampleSize <= 0 || c.Verifier.SampleSize > 1000 {
+		return fmt.Errorf("sample_size must be between 1 and 1000")
+	}
+
+	if c.Verifier.OrphanSampleSize < 0 {
+		return fmt.Errorf("orphan_sample_size must be non-negative")
+	}
+
+	if c.Verifier.OrphanGraceSecs < 0 {
+		return fmt.Errorf("orphan_grace_seconds must be non-negative")
+	}
+
+	if c.Verifier.HistoryDepth < 0 {
+		return fmt.Errorf("history_depth must be non-negative")
+	}
+
+	if c.Verifier.MaxViolations <= 0 {
+		return fmt.Errorf("max_violations must be positive")
+	}
+
+	if c.Verifier.PublishEvents && (c.Services.QueueURL == "" || c.Verifier.Topic == "") {
+		return fmt.Errorf("queue_url and topic are required when publish_events is enabled")
+	}
+
+	if c.RBAC.Enabled {
+		if c.RBAC.Header == "" {
+			return fmt.Errorf("rbac header is required")
+		}
+		for _, key := range c.RBAC.Keys {
+			if key.Name == "" || key.Key == "" {
+				return fmt.Errorf("rbac api key name and key are required")
+			}
+			if !models.IsValidRole(key.Role) {
+				return fmt.Errorf("rbac api key %s: unknown role %q", key.Name, key.Role)
+			}
+		}
+	}
+
+	if c.FlightRecorder.Enabled {
+		if c.FlightRecorder.StorageURL == "" {
+			return fmt.Errorf("flight recorder storage URL is required")
+		}
+		if c.FlightRecorder.Bucket == "" {
+			return fmt.Errorf("flight recorder bucket is required")
+		}
+		if c.FlightRecorder.MaxErrorRate < 0 || c.FlightRecorder.MaxErrorRate > 1 ||
+			c.FlightRecorder.MaxSlowRate < 0 || c.FlightRecorder.MaxSlowRate > 1 {
+			return fmt.Errorf("flight recorder rates must be between 0 and 1")
+		}
+	}
+
+	return nil
+}
+
+// getEnv 获取环境变量，如果不存在则返回默认值
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsInt 获取环境变量并转换为int
+func getEnvAsInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsBool 获取环境变量并转换为bool
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsFloat 获取环境变量并转换为float64
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsAPIKeys 获取环境变量并解析为API key列表，格式为 name:key:role，多个以逗号分隔
+func getEnvAsAPIKeys(key string) []APIKeyConfig {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var keys []APIKeyConfig
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 {
+			// 保留格式错误的条目，由Validate报告
+			keys = append(keys, APIKeyConfig{Name: entry})
+			continue
+		}
+		keys = append(keys, APIKeyConfig{Name: parts[0], Key: parts[1], Role: parts[2]})
+	}
+	return keys
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"mocks3/services/verifier/internal/service"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifierHandler 一致性校验处理器
+type VerifierHandler struct {
+	service *service.VerifierService
+	logger  *observability.Logger
+}
+
+// NewVerifierHandler 创建一致性校验处理器
+func NewVerifierHandler(service *service.VerifierService, logger *observability.Logger) *VerifierHandler {
+	return &VerifierHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *VerifierHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.GET("/violations", h.ListViolations)
+		v1.GET("/stats", h.GetStats)
+		v1.POST("/verify", h.RunRound)
+		v1.POST("/verify/:bucket/:key", h.VerifyObject)
+	}
+}
+
+// ListViolations 列出最近的违规记录
+func (h *VerifierHandler) ListViolations(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 0 {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid limit parameter")
+		return
+	}
+
+	violations := h.service.ListViolations(c.Query("invariant"), c.Query("bucket"), limit)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"violations": violations,
+			"count":      len(violations),
+		},
+	})
+}
+
+// GetStats 获取校验统计
+func (h *VerifierHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.service.GetStats(),
+	})
+}
+
+// RunRound 立即执行一轮校验
+func (h *VerifierHandler) RunRound(c *gin.Context) {
+	round, err := h.service.RunRound(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to run verification round", "error", err)
+		utils.SetErrorResponse(c.Writer, http.StatusBadGateway, "Failed to run verification round: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    round,
+	})
+}
+
+// VerifyObject 立即校验单个对象
+func (h *VerifierHandler) VerifyObject(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := c.Param("key")
+
+	violations, err := h.service.VerifyObject(c.Request.Context(), bucket, key)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidArgument):
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+		case errors.Is(err, models.ErrMetadataNotFound):
+			utils.SetErrorResponse(c.Writer, http.StatusNotFound, err.Error())
+		default:
+			h.logger.ErrorContext(c.Request.Context(), "Failed to verify object", "bucket", bucket, "key", key, "error", err)
+			utils.SetErrorResponse(c.Writer, http.StatusBadGateway, "Failed to verify object: "+err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"bucket":     bucket,
+			"key":        key,
+			"consistent": len(violations) == 0,
+			"violations": violations,
+		},
+	})
+}
@@ -0,0 +1,538 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"mocks3/services/verifier/internal/config"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 校验结果（指标标签）
+const (
+	checkResultPass      = "pass"
+	checkResultViolation = "violation"
+	checkResultError     = "error"
+)
+
+// MetadataReader 校验所需的元数据读取能力（由MetadataClient实现）
+type MetadataReader interface {
+	ListMetadataPage(ctx context.Context, bucket, prefix, continuationToken string, limit int) (*models.MetadataPage, error)
+	GetMetadataHistory(ctx context.Context, bucket, key string, limit, offset int) ([]*models.MetadataVersion, error)
+	HealthCheck(ctx context.Context) error
+}
+
+// ReplicaReader 校验所需的存储读取能力（由StorageClient实现）
+type ReplicaReader interface {
+	GetObjectReplicas(ctx context.Context, bucket, key string) ([]*models.ObjectReplica, error)
+	ListObjects(ctx context.Context, req *models.ListObjectsRequest) (*models.ListObjectsResponse, error)
+	HealthCheck(ctx context.Context) error
+}
+
+// EventPublisher 违规事件发布者（由QueueClient实现）
+type EventPublisher interface {
+	PublishEvent(ctx context.Context, topic string, payload []byte) (*models.TopicEvent, error)
+}
+
+// VerifierService 一致性校验服务，持续滚动扫描元数据并校验不变量
+type VerifierService struct {
+	config    *config.VerifierConfig
+	metadata  MetadataReader
+	storage   ReplicaReader
+	publisher EventPublisher
+	metrics   *observability.MetricCollector
+	logger    *observability.Logger
+
+	runMu   sync.Mutex // 保证同一时间只有一轮校验
+	cursor  string     // 元数据滚动扫描游标
+	buckets map[string]struct{}
+
+	mu         sync.RWMutex
+	violations []*models.InvariantViolation // 最近违规记录（环形缓冲）
+	next       int
+	stats      models.VerifierStats
+}
+
+// NewVerifierService 创建一致性校验服务
+func NewVerifierService(cfg *config.VerifierConfig, metadata MetadataReader, storage ReplicaReader, logger *observability.Logger) *VerifierService {
+	s := &VerifierService{
+		config:     cfg,
+		metadata:   metadata,
+		storage:    storage,
+		logger:     logger,
+		buckets:    make(map[string]struct{}),
+		violations: make([]*models.InvariantViolation, 0, cfg.MaxViolations),
+		stats: models.VerifierStats{
+			ViolationsByInvariant: make(map[string]int64),
+		},
+	}
+	if cfg.Bucket != "" {
+		s.buckets[cfg.Bucket] = struct{}{}
+	}
+	return s
+}
+
+// SetEventPublisher 设置违规事件发布者
+func (s *VerifierService) SetEventPublisher(publisher EventPublisher) {
+	s.publisher = publisher
+}
+
+// SetMetricCollector 设置指标收集器
+func (s *VerifierService) SetMetricCollector(metrics *observability.MetricCollector) {
+	s.metrics = metrics
+}
+
+// Start 启动后台持续校验
+func (s *VerifierService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval())
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.RunRound(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error(ctx, "Verification round failed", observability.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunRound 执行一轮校验：取下一页元数据逐个校验，并抽查存储节点上的孤儿对象
+func (s *VerifierService) RunRound(ctx context.Context) (*models.VerificationRound, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	round := &models.VerificationRound{
+		StartedAt:  time.Now(),
+		Violations: []*models.InvariantViolation{},
+	}
+
+	page, err := s.metadata.ListMetadataPage(ctx, s.config.Bucket, s.config.Prefix, s.cursor, s.config.SampleSize)
+	if err != nil {
+		// 游标可能因数据变化失效，下一轮从头扫描
+		s.cursor = ""
+		return nil, fmt.Errorf("failed to list metadata: %w", err)
+	}
+
+	for _, metadata := range page.Metadata {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		s.buckets[metadata.Bucket] = struct{}{}
+
+		violations, checkErrors := s.verifyConfirmed(ctx, metadata)
+		round.ObjectsChecked++
+		round.CheckErrors += checkErrors
+		round.Violations = append(round.Violations, violations...)
+	}
+
+	if page.IsTruncated && page.NextContinuationToken != "" {
+		s.cursor = page.NextContinuationToken
+	} else {
+		s.cursor = ""
+		round.Wrapped = true
+	}
+
+	orphans, checked, checkErrors := s.checkOrphans(ctx)
+	round.OrphansChecked = checked
+	round.CheckErrors += checkErrors
+	round.Violations = append(round.Violations, orphans...)
+
+	round.Duration = time.Since(round.StartedAt)
+	s.report(ctx, round)
+
+	s.logger.Info(ctx, "Verification round completed",
+		observability.Int("objects_checked", round.ObjectsChecked),
+		observability.Int("orphans_checked", round.OrphansChecked),
+		observability.Int("violations", len(round.Violations)),
+		observability.Int("check_errors", round.CheckErrors),
+		observability.Duration("duration", round.Duration))
+
+	return round, nil
+}
+
+// VerifyObject 立即校验单个对象
+func (s *VerifierService) VerifyObject(ctx context.Context, bucket, key string) ([]*models.InvariantViolation, error) {
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("%w: bucket and key are required", models.ErrInvalidArgument)
+	}
+
+	metadata, err := s.getMetadata(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil {
+		return nil, fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, bucket, key)
+	}
+
+	violations, _ := s.verifyConfirmed(ctx, metadata)
+	s.report(ctx, &models.VerificationRound{
+		StartedAt:      time.Now(),
+		ObjectsChecked: 1,
+		Violations:     violations,
+	})
+	return violations, nil
+}
+
+// ListViolations 按条件列出最近的违规记录（新的在前）
+func (s *VerifierService) ListViolations(invariant, bucket string, limit int) []*models.InvariantViolation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.InvariantViolation, 0)
+	n := len(s.violations)
+	for i := 0; i < n && (limit <= 0 || len(result) < limit); i++ {
+		// 从最新写入位置倒序遍历环形缓冲
+		v := s.violations[(s.next-1-i+n)%n]
+		if invariant != "" && v.Invariant != invariant {
+			continue
+		}
+		if bucket != "" && v.Bucket != bucket {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// GetStats 获取累计统计
+func (s *VerifierService) GetStats() *models.VerifierStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := s.stats
+	stats.ViolationsByInvariant = make(map[string]int64, len(s.stats.ViolationsByInvariant))
+	for invariant, count := range s.stats.ViolationsByInvariant {
+		stats.ViolationsByInvariant[invariant] = count
+	}
+	return &stats
+}
+
+// HealthCheck 健康检查（依赖的元数据和存储服务均可用）
+func (s *VerifierService) HealthCheck(ctx context.Context) error {
+	if err := s.metadata.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("metadata service unavailable: %w", err)
+	}
+	if err := s.storage.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("storage service unavailable: %w", err)
+	}
+	return nil
+}
+
+// verifyConfirmed 校验对象，发现违规时重新读取后复核，过滤并发写入造成的瞬时不一致
+func (s *VerifierService) verifyConfirmed(ctx context.Context, metadata *models.Metadata) ([]*models.InvariantViolation, int) {
+	violations, results := s.verifyObject(ctx, metadata)
+	if len(violations) > 0 {
+		current, err := s.getMetadata(ctx, metadata.Bucket, metadata.Key)
+		switch {
+		case err != nil:
+			return nil, countErrors(results) + 1
+		case current == nil:
+			// 复核时对象已被删除
+			return nil, countErrors(results)
+		}
+		violations, results = s.verifyObject(ctx, current)
+	}
+
+	for invariant, result := range results {
+		s.recordCheck(ctx, invariant, result)
+	}
+	return violations, countErrors(results)
+}
+
+// verifyObject 校验单个对象的大小、副本和版本不变量，返回违规记录和各不变量的校验结果
+func (s *VerifierService) verifyObject(ctx context.Context, metadata *models.Metadata) ([]*models.InvariantViolation, map[string]string) {
+	var violations []*models.InvariantViolation
+	results := make(map[string]string)
+
+	if metadata.Status != "" && metadata.Status != "active" {
+		return nil, results
+	}
+
+	replicas, err := s.storage.GetObjectReplicas(ctx, metadata.Bucket, metadata.Key)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to inspect replicas",
+			observability.String("bucket", metadata.Bucket),
+			observability.String("key", metadata.Key),
+			observability.Error(err))
+		results[models.InvariantReplicaPresence] = checkResultError
+	} else {
+		found := checkReplicas(metadata, replicas)
+		setResults(results, found, models.InvariantReplicaPresence, models.InvariantSizeMatch, models.InvariantReplicaChecksum)
+		violations = append(violations, found...)
+	}
+
+	if s.config.HistoryDepth > 0 {
+		history, err := s.metadata.GetMetadataHistory(ctx, metadata.Bucket, metadata.Key, s.config.HistoryDepth, 0)
+		if err != nil {
+			results[models.InvariantVersionMonotonic] = checkResultError
+		} else {
+			found := checkVersions(metadata, history)
+			setResults(results, found, models.InvariantVersionMonotonic)
+			violations = append(violations, found...)
+		}
+	}
+
+	return violations, results
+}
+
+// setResults 根据违规记录设置各不变量的校验结果
+func setResults(results map[string]string, violations []*models.InvariantViolation, invariants ...string) {
+	for _, invariant := range invariants {
+		results[invariant] = checkResultPass
+	}
+	for _, v := range violations {
+		results[v.Invariant] = checkResultViolation
+	}
+}
+
+// countErrors 统计校验出错的不变量数
+func countErrors(results map[string]string) int {
+	count := 0
+	for _, result := range results {
+		if result == checkResultError {
+			count++
+		}
+	}
+	return count
+}
+
+// checkReplicas 校验副本存在性、大小和校验和
+func checkReplicas(metadata *models.Metadata, replicas []*models.ObjectReplica) []*models.InvariantViolation {
+	var violations []*models.InvariantViolation
+
+	expectedMD5 := metadata.MD5Hash
+	existing := 0
+	for _, replica := range replicas {
+		if replica.Error != "" || !replica.Exists {
+			// 不健康节点缺失副本属于可用性问题，不计为违规
+			if replica.Error == "" && replica.Healthy {
+				violations = append(violations, newViolation(models.InvariantReplicaPresence, metadata, replica.NodeID,
+					"present", "missing", "replica missing on healthy node"))
+			}
+			continue
+		}
+		existing++
+
+		if replica.Size != metadata.Size {
+			violations = append(violations, newViolation(models.InvariantSizeMatch, metadata, replica.NodeID,
+				strconv.FormatInt(metadata.Size, 10), strconv.FormatInt(replica.Size, 10),
+				"replica size differs from metadata size"))
+		}
+
+		if expectedMD5 == "" {
+			// 元数据未记录MD5时以第一个副本为基准比较副本间一致性
+			expectedMD5 = replica.MD5Hash
+			continue
+		}
+		if replica.MD5Hash != expectedMD5 {
+			violations = append(violations, newViolation(models.InvariantReplicaChecksum, metadata, replica.NodeID,
+				expectedMD5, replica.MD5Hash, "replica checksum differs"))
+		}
+	}
+
+	if existing == 0 && len(replicas) > 0 {
+		violations = append(violations, newViolation(models.InvariantReplicaPresence, metadata, "",
+			"at least one replica", "none", "metadata exists but no storage node holds the object"))
+	}
+
+	return violations
+}
+
+// checkVersions 校验历史版本号严格递增且小于当前版本（历史按版本号降序返回）
+func checkVersions(metadata *models.Metadata, history []*models.MetadataVersion) []*models.InvariantViolation {
+	var violations []*models.InvariantViolation
+
+	upper := metadata.Version
+	for _, version := range history {
+		// 同名对象删除后重建会产生新ID，其历史版本不参与比较
+		if version.ID != metadata.ID {
+			continue
+		}
+		if version.Version >= upper {
+			violations = append(violations, newViolation(models.InvariantVersionMonotonic, metadata, "",
+				fmt.Sprintf("< %d", upper), strconv.FormatInt(version.Version, 10),
+				fmt.Sprintf("history version %d (archived %s) is not below its successor", version.Version, version.ArchivedAt.Format(time.RFC3339))))
+		}
+		upper = version.Version
+	}
+
+	return violations
+}
+
+// checkOrphans 抽查存储节点上的对象是否有对应元数据
+func (s *VerifierService) checkOrphans(ctx context.Context) ([]*models.InvariantViolation, int, int) {
+	if s.config.OrphanSampleSize == 0 || len(s.buckets) == 0 {
+		return nil, 0, 0
+	}
+
+	buckets := make([]string, 0, len(s.buckets))
+	for bucket := range s.buckets {
+		buckets = append(buckets, bucket)
+	}
+	sort.Strings(buckets)
+	bucket := buckets[rand.Intn(len(buckets))]
+
+	listing, err := s.storage.ListObjects(ctx, &models.ListObjectsRequest{
+		Bucket:  bucket,
+		Prefix:  s.config.Prefix,
+		MaxKeys: 1000,
+	})
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to list storage objects",
+			observability.String("bucket", bucket),
+			observability.Error(err))
+		s.recordCheck(ctx, models.InvariantNoOrphanObject, checkResultError)
+		return nil, 0, 1
+	}
+
+	objects := listing.Objects
+	rand.Shuffle(len(objects), func(i, j int) { objects[i], objects[j] = objects[j], objects[i] })
+
+	var violations []*models.InvariantViolation
+	checked, checkErrors := 0, 0
+	cutoff := time.Now().Add(-s.config.OrphanGrace())
+	for i := range objects {
+		if checked >= s.config.OrphanSampleSize || ctx.Err() != nil {
+			break
+		}
+		object := &objects[i]
+		if object.UpdatedAt.After(cutoff) {
+			continue
+		}
+		checked++
+
+		metadata, err := s.getMetadata(ctx, bucket, object.Key)
+		if err != nil {
+			checkErrors++
+			s.recordCheck(ctx, models.InvariantNoOrphanObject, checkResultError)
+			continue
+		}
+		if metadata != nil {
+			s.recordCheck(ctx, models.InvariantNoOrphanObject, checkResultPass)
+			continue
+		}
+
+		s.recordCheck(ctx, models.InvariantNoOrphanObject, checkResultViolation)
+		violations = append(violations, &models.InvariantViolation{
+			ID:         utils.NewID(),
+			Invariant:  models.InvariantNoOrphanObject,
+			Bucket:     bucket,
+			Key:        object.Key,
+			Expected:   "metadata record",
+			Actual:     "none",
+			Message:    fmt.Sprintf("stored object (%d bytes, modified %s) has no metadata", object.Size, object.UpdatedAt.Format(time.RFC3339)),
+			DetectedAt: time.Now(),
+		})
+	}
+
+	return violations, checked, checkErrors
+}
+
+// getMetadata 读取元数据，不存在时返回nil
+//
+// 通过前缀列表精确匹配key，以区分"不存在"与请求失败。
+func (s *VerifierService) getMetadata(ctx context.Context, bucket, key string) (*models.Metadata, error) {
+	page, err := s.metadata.ListMetadataPage(ctx, bucket, key, "", 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+	if len(page.Metadata) == 0 || page.Metadata[0].Key != key {
+		return nil, nil
+	}
+	return page.Metadata[0], nil
+}
+
+// report 记录一轮结果：更新统计、保存违规、发布事件
+func (s *VerifierService) report(ctx context.Context, round *models.VerificationRound) {
+	s.mu.Lock()
+	s.stats.Rounds++
+	s.stats.ObjectsChecked += int64(round.ObjectsChecked)
+	s.stats.OrphansChecked += int64(round.OrphansChecked)
+	s.stats.CheckErrors += int64(round.CheckErrors)
+	s.stats.Violations += int64(len(round.Violations))
+	for _, v := range round.Violations {
+		s.stats.ViolationsByInvariant[v.Invariant]++
+		if len(s.violations) < s.config.MaxViolations {
+			s.violations = append(s.violations, v)
+		} else {
+			s.violations[s.next] = v
+		}
+		s.next = (s.next + 1) % s.config.MaxViolations
+	}
+	s.stats.LastRound = round
+	s.mu.Unlock()
+
+	for _, v := range round.Violations {
+		s.logger.Warn(ctx, "Invariant violated",
+			observability.String("invariant", v.Invariant),
+			observability.String("bucket", v.Bucket),
+			observability.String("key", v.Key),
+			observability.String("node_id", v.NodeID),
+			observability.String("expected", v.Expected),
+			observability.String("actual", v.Actual),
+			observability.String("message", v.Message))
+
+		if s.metrics != nil {
+			s.metrics.RecordInvariantViolation(ctx, v.Invariant, v.Bucket)
+		}
+		s.publish(ctx, v)
+	}
+}
+
+// publish 发布违规事件到队列主题
+func (s *VerifierService) publish(ctx context.Context, violation *models.InvariantViolation) {
+	if s.publisher == nil {
+		return
+	}
+
+	payload, err := json.Marshal(violation)
+	if err == nil {
+		_, err = s.publisher.PublishEvent(ctx, s.config.Topic, payload)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.stats.EventsFailed++
+		s.logger.Warn(ctx, "Failed to publish violation event",
+			observability.String("topic", s.config.Topic),
+			observability.Error(err))
+		return
+	}
+	s.stats.EventsPublished++
+}
+
+// recordCheck 记录单次校验指标
+func (s *VerifierService) recordCheck(ctx context.Context, invariant, result string) {
+	if s.metrics != nil {
+		s.metrics.RecordInvariantCheck(ctx, invariant, result)
+	}
+}
+
+// newViolation 基于对象元数据创建违规记录
+func newViolation(invariant string, metadata *models.Metadata, nodeID, expected, actual, message string) *models.InvariantViolation {
+	return &models.InvariantViolation{
+		ID:         utils.NewID(),
+		Invariant:  invariant,
+		Bucket:     metadata.Bucket,
+		Key:        metadata.Key,
+		NodeID:     nodeID,
+		Expected:   expected,
+		Actual:     actual,
+		Message:    message,
+		DetectedAt: time.Now(),
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mocks3/shared/models"
 	"net/http"
 	"net/url"
 	"strconv"
 	"time"
+
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // BaseHTTPClient 基础HTTP客户端，封装通用的HTTP操作
@@ -17,6 +20,7 @@ type BaseHTTPClient struct {
 	baseURL    string
 	httpClient *http.Client
 	timeout    time.Duration
+	headers    map[string]string // 每个请求都携带的头部
 }
 
 // NewBaseHTTPClient 创建基础HTTP客户端
@@ -30,11 +34,20 @@ func NewBaseHTTPClient(baseURL string, timeout time.Duration) *BaseHTTPClient {
 	}
 }
 
+// SetHeader 设置每个请求都携带的头部（如管理接口的API key），请求级头部优先
+func (c *BaseHTTPClient) SetHeader(name, value string) {
+	if c.headers == nil {
+		c.headers = make(map[string]string)
+	}
+	c.headers[name] = value
+}
+
 // RequestOptions 请求选项
 type RequestOptions struct {
 	Method      string
 	Path        string
 	Body        any
+	RawBody     []byte // 原始请求体，设置后不再对Body做JSON编码
 	QueryParams map[string]string
 	Headers     map[string]string
 }
@@ -49,7 +62,9 @@ func (c *BaseHTTPClient) DoRequest(ctx context.Context, opts RequestOptions) (*h
 
 	// 构建请求体
 	var bodyReader io.Reader
-	if opts.Body != nil {
+	if opts.RawBody != nil {
+		bodyReader = bytes.NewReader(opts.RawBody)
+	} else if opts.Body != nil {
 		bodyBytes, err := json.Marshal(opts.Body)
 		if err != nil {
 			return nil, fmt.Errorf("marshal body: %w", err)
@@ -64,11 +79,32 @@ func (c *BaseHTTPClient) DoRequest(ctx context.Context, opts RequestOptions) (*h
 	}
 
 	// 设置默认头部
-	if opts.Body != nil {
+	if opts.Body != nil || opts.RawBody != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	// 透传请求所属租户
+	if tenant, ok := models.LookupTenant(ctx); ok {
+		req.Header.Set(models.HeaderTenantID, tenant)
+	}
+
+	// 透传原始操作者，供下游服务审计
+	if actor := models.AuditActorFromContext(ctx); actor.Name != "" {
+		req.Header.Set(models.HeaderActor, actor.Name)
+	}
+
+	// 透传请求ID，关联调用链上各服务的日志
+	if requestID := models.RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Set(models.HeaderRequestID, requestID)
+	}
+
+	// 透传baggage（租户、测试运行ID、场景ID等），未开启追踪时也传递
+	propagation.Baggage{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
 	// 设置自定义头部
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
 	for k, v := range opts.Headers {
 		req.Header.Set(k, v)
 	}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mocks3/shared/models"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+)
+
+// OutboundOperationPrefix 出站请求向mock-error查询注入时的操作名前缀，完整操作名为 outbound:<目标host>
+const OutboundOperationPrefix = "outbound:"
+
+// FaultChecker 按请求元数据检查错误注入（由MockErrorClient实现）
+type FaultChecker interface {
+	CheckInjectionWithMetadata(ctx context.Context, service, operation string, metadata map[string]string) (*models.ErrorAction, bool, error)
+}
+
+// FaultTransport 出站请求的错误注入传输层
+//
+// 每个请求发出前向mock-error查询，命中dns_failure、connection_refused、tls_handshake_error时不发出请求，
+// 返回与真实故障相同类型的错误，调用方可以用errors.As/errors.Is按真实故障处理；检查超时或失败时照常发送。
+type FaultTransport struct {
+	base         http.RoundTripper
+	checker      FaultChecker
+	serviceName  string
+	checkTimeout time.Duration
+}
+
+// NewFaultTransport 创建出站错误注入传输层，base为空时使用http.DefaultTransport
+func NewFaultTransport(base http.RoundTripper, checker FaultChecker, serviceName string, checkTimeout time.Duration) *FaultTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if checkTimeout <= 0 {
+		checkTimeout = 200 * time.Millisecond
+	}
+	return &FaultTransport{
+		base:         base,
+		checker:      checker,
+		serviceName:  serviceName,
+		checkTimeout: checkTimeout,
+	}
+}
+
+// RoundTrip 实现http.RoundTripper
+func (t *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	metadata := map[string]string{
+		models.ErrorMetadataPath: req.URL.Path,
+	}
+	for name, values := range req.Header {
+		if len(values) > 0 {
+			metadata[models.ErrorMetadataHeaderPrefix+name] = values[0]
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.checkTimeout)
+	action, inject, err := t.checker.CheckInjectionWithMetadata(ctx, t.serviceName, OutboundOperationPrefix+req.URL.Host, metadata)
+	cancel()
+	if err != nil || !inject || action == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	connErr := connectionError(action, req.URL.Hostname())
+	if connErr == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	// 延迟后再失败，模拟解析或握手超时前的等待
+	if action.Delay != nil && *action.Delay > 0 {
+		timer := time.NewTimer(*action.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, connErr
+}
+
+// connectionError 构造与net/http拨号和TLS握手失败时相同类型的错误，不是连接级动作时返回nil
+func connectionError(action *models.ErrorAction, host string) error {
+	switch action.Type {
+	case models.ErrorActionTypeDNSFailure:
+		return &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{
+			Err:        "no such host (injected)",
+			Name:       host,
+			IsNotFound: true,
+		}}
+	case models.ErrorActionTypeConnectionRefused:
+		return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", fmt.Errorf("%w (injected)", syscall.ECONNREFUSED))}
+	case models.ErrorActionTypeTLSHandshakeError:
+		// 服务端发送handshake_failure告警
+		return &net.OpError{Op: "remote error", Err: fmt.Errorf("%w (injected)", tls.AlertError(40))}
+	default:
+		return nil
+	}
+}
+
+// SetTransport 设置HTTP传输层，用于包装出站请求
+func (c *BaseHTTPClient) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
+
+// UseFaultTransport 出站请求经过错误注入传输层，模拟DNS解析失败、连接被拒绝和TLS握手错误
+func (c *BaseHTTPClient) UseFaultTransport(checker FaultChecker, serviceName string, checkTimeout time.Duration) {
+	c.SetTransport(NewFaultTransport(c.httpClient.Transport, checker, serviceName, checkTimeout))
+}
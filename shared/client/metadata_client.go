@@ -2,9 +2,11 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"mocks3/shared/models"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -20,32 +22,120 @@ func NewMetadataClient(baseURL string, timeout time.Duration) *MetadataClient {
 	}
 }
 
-// SaveMetadata 保存元数据
+// SaveMetadata 保存元数据，不符合bucket schema时返回*models.SchemaValidationError
 func (c *MetadataClient) SaveMetadata(ctx context.Context, metadata *models.Metadata) error {
-	return c.PostExpectStatus(ctx, "/api/v1/metadata", metadata, http.StatusCreated)
+	return c.writeMetadata(ctx, "POST", "/api/v1/metadata", metadata, http.StatusCreated)
 }
 
 // GetMetadata 获取元数据
 func (c *MetadataClient) GetMetadata(ctx context.Context, bucket, key string) (*models.Metadata, error) {
 	path := fmt.Sprintf("/api/v1/metadata/%s/%s", PathEscape(bucket), PathEscape(key))
-	var metadata models.Metadata
-	err := c.Get(ctx, path, nil, &metadata)
+	resp, err := c.DoRequest(ctx, RequestOptions{Method: "GET", Path: path})
 	if err != nil {
-		return nil, fmt.Errorf("metadata not found: %s/%s", bucket, key)
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
 	}
-	return &metadata, nil
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, bucket, key)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get metadata: unexpected status code: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data models.Metadata `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return &body.Data, nil
 }
 
-// UpdateMetadata 更新元数据
+// UpdateMetadata 更新元数据，不符合bucket schema时返回*models.SchemaValidationError
 func (c *MetadataClient) UpdateMetadata(ctx context.Context, metadata *models.Metadata) error {
 	path := fmt.Sprintf("/api/v1/metadata/%s/%s", PathEscape(metadata.Bucket), PathEscape(metadata.Key))
-	return c.PutExpectStatus(ctx, path, metadata, http.StatusOK)
+	return c.writeMetadata(ctx, "PUT", path, metadata, http.StatusOK)
+}
+
+// writeMetadata 发送元数据并检查状态码，400响应为schema校验失败时还原为*models.SchemaValidationError
+func (c *MetadataClient) writeMetadata(ctx context.Context, method, path string, metadata *models.Metadata, expectedStatus int) error {
+	resp, err := c.DoRequest(ctx, RequestOptions{Method: method, Path: path, Body: metadata})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		var body struct {
+			Code       string                   `json:"code"`
+			Violations []models.SchemaViolation `json:"violations"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&body) == nil && body.Code == models.ErrCodeSchemaViolation {
+			return &models.SchemaValidationError{Bucket: metadata.Bucket, Key: metadata.Key, Violations: body.Violations}
+		}
+	}
+	if resp.StatusCode != expectedStatus {
+		return fmt.Errorf("unexpected status code: %d, expected: %v", resp.StatusCode, []int{expectedStatus})
+	}
+	return nil
 }
 
-// DeleteMetadata 删除元数据
+// DeleteMetadata 删除元数据，对象仍在bucket保留期内时返回*models.RetentionViolationError
 func (c *MetadataClient) DeleteMetadata(ctx context.Context, bucket, key string) error {
 	path := fmt.Sprintf("/api/v1/metadata/%s/%s", PathEscape(bucket), PathEscape(key))
-	return c.Delete(ctx, path)
+	resp, err := c.DoRequest(ctx, RequestOptions{Method: "DELETE", Path: path})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		var body struct {
+			Code      string                          `json:"code"`
+			Retention *models.RetentionViolationError `json:"retention"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&body) == nil && body.Code == models.ErrCodeRetentionViolation && body.Retention != nil {
+			return body.Retention
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, bucket, key)
+	}
+	if !isSuccessStatus(resp.StatusCode) {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// BatchMetadata 批量创建/更新/删除元数据
+func (c *MetadataClient) BatchMetadata(ctx context.Context, req *models.MetadataBatchRequest) (*models.MetadataBatchResponse, error) {
+	var resp struct {
+		Data models.MetadataBatchResponse `json:"data"`
+	}
+	if err := c.Post(ctx, "/api/v1/metadata/batch", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// GetMetadataHistory 获取元数据历史版本（按版本号降序）
+func (c *MetadataClient) GetMetadataHistory(ctx context.Context, bucket, key string, limit, offset int) ([]*models.MetadataVersion, error) {
+	path := fmt.Sprintf("/api/v1/metadata/%s/%s/history", PathEscape(bucket), PathEscape(key))
+	queryParams := BuildQueryParams(map[string]any{
+		"limit":  limit,
+		"offset": offset,
+	})
+
+	var resp struct {
+		Data struct {
+			Versions []*models.MetadataVersion `json:"versions"`
+		} `json:"data"`
+	}
+	if err := c.Get(ctx, path, queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data.Versions, nil
 }
 
 // ListMetadata 列出元数据
@@ -62,6 +152,137 @@ func (c *MetadataClient) ListMetadata(ctx context.Context, bucket, prefix string
 	return metadataList, err
 }
 
+// ListMetadataPage 使用continuation token分页列出元数据
+func (c *MetadataClient) ListMetadataPage(ctx context.Context, bucket, prefix, continuationToken string, limit int) (*models.MetadataPage, error) {
+	queryParams := BuildQueryParams(map[string]any{
+		"bucket":             bucket,
+		"prefix":             prefix,
+		"continuation_token": continuationToken,
+		"limit":              limit,
+	})
+
+	var resp struct {
+		Data models.MetadataPage `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/metadata", queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// ListMetadataFiltered 按属性过滤（大小、创建/修改时间、内容类型、状态）分页列出元数据，过滤在服务端数据库中完成
+func (c *MetadataClient) ListMetadataFiltered(ctx context.Context, filter *models.MetadataFilter, continuationToken string, limit int) (*models.MetadataPage, error) {
+	queryParams := BuildQueryParams(map[string]any{
+		"bucket":             filter.Bucket,
+		"prefix":             filter.Prefix,
+		"status":             filter.Status,
+		"content_type":       filter.ContentType,
+		"continuation_token": continuationToken,
+		"limit":              limit,
+	})
+	if filter.SizeMin != nil {
+		queryParams["size_min"] = strconv.FormatInt(*filter.SizeMin, 10)
+	}
+	if filter.SizeMax != nil {
+		queryParams["size_max"] = strconv.FormatInt(*filter.SizeMax, 10)
+	}
+	for name, value := range map[string]*time.Time{
+		"created_from":    filter.CreatedFrom,
+		"created_to":      filter.CreatedTo,
+		"modified_after":  filter.ModifiedAfter,
+		"modified_before": filter.ModifiedBefore,
+	} {
+		if value != nil {
+			queryParams[name] = value.Format(time.RFC3339Nano)
+		}
+	}
+
+	var resp struct {
+		Data models.MetadataPage `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/metadata", queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// ListMetadataDelimited 按分隔符分层列出对象和公共前缀（伪目录），使用continuation token分页
+func (c *MetadataClient) ListMetadataDelimited(ctx context.Context, bucket, prefix, delimiter, continuationToken string, limit int) (*models.MetadataPage, error) {
+	queryParams := BuildQueryParams(map[string]any{
+		"bucket":             bucket,
+		"prefix":             prefix,
+		"delimiter":          delimiter,
+		"continuation_token": continuationToken,
+		"limit":              limit,
+	})
+
+	var resp struct {
+		Data models.MetadataPage `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/metadata", queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// ShardKeyspace 获取bucket键空间分片计划
+func (c *MetadataClient) ShardKeyspace(ctx context.Context, bucket, prefix string, shards int) (*models.MetadataShardPlan, error) {
+	queryParams := BuildQueryParams(map[string]any{
+		"bucket": bucket,
+		"prefix": prefix,
+		"shards": shards,
+	})
+
+	var resp struct {
+		Data models.MetadataShardPlan `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/metadata/shards", queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// ListMetadataRange 在分片范围内使用continuation token分页列出元数据
+func (c *MetadataClient) ListMetadataRange(ctx context.Context, bucket, prefix string, keyRange *models.MetadataKeyRange, continuationToken string, limit int) (*models.MetadataPage, error) {
+	queryParams := BuildQueryParams(map[string]any{
+		"bucket":             bucket,
+		"prefix":             prefix,
+		"start_after":        keyRange.StartAfter,
+		"end_key":            keyRange.EndKey,
+		"continuation_token": continuationToken,
+		"limit":              limit,
+	})
+
+	var resp struct {
+		Data models.MetadataPage `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/metadata", queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// QueryMetadataByTags 按标签查询表达式筛选元数据，如 "env=staging AND team!=core"
+func (c *MetadataClient) QueryMetadataByTags(ctx context.Context, query, bucket, prefix, continuationToken string, limit int) (*models.MetadataPage, error) {
+	queryParams := BuildQueryParams(map[string]any{
+		"q":                  query,
+		"bucket":             bucket,
+		"prefix":             prefix,
+		"continuation_token": continuationToken,
+		"limit":              limit,
+	})
+
+	var resp struct {
+		Data struct {
+			Page models.MetadataPage `json:"page"`
+		} `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/metadata/tags", queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data.Page, nil
+}
+
 // SearchMetadata 搜索元数据
 func (c *MetadataClient) SearchMetadata(ctx context.Context, req *models.SearchObjectsRequest) (*models.SearchObjectsResponse, error) {
 	queryParams := BuildQueryParams(map[string]any{
@@ -97,6 +318,116 @@ func (c *MetadataClient) CountObjects(ctx context.Context, bucket, prefix string
 	return countResp.Count, err
 }
 
+// FindMetadataByHash 查找内容哈希（MD5十六进制）和大小相同的对象，按创建时间升序
+func (c *MetadataClient) FindMetadataByHash(ctx context.Context, hash string, size int64, limit int) ([]*models.Metadata, error) {
+	queryParams := BuildQueryParams(map[string]any{
+		"hash":  hash,
+		"size":  strconv.FormatInt(size, 10), // 空对象的大小为0，不能省略
+		"limit": limit,
+	})
+
+	var resp struct {
+		Data []*models.Metadata `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/metadata/by-hash", queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// FindDuplicates 获取重复内容报告
+func (c *MetadataClient) FindDuplicates(ctx context.Context, bucket string, minCopies, limit int) (*models.DuplicateReport, error) {
+	queryParams := BuildQueryParams(map[string]any{
+		"bucket":     bucket,
+		"min_copies": minCopies,
+		"limit":      limit,
+	})
+
+	var resp struct {
+		Data models.DuplicateReport `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/metadata/duplicates", queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// GetBucketRetention 获取bucket的保留策略
+func (c *MetadataClient) GetBucketRetention(ctx context.Context, bucket string) (*models.BucketRetention, error) {
+	var resp struct {
+		Data models.BucketRetention `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/retention/"+PathEscape(bucket), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// SetBucketRetention 设置bucket的最短保留天数
+func (c *MetadataClient) SetBucketRetention(ctx context.Context, bucket string, minRetentionDays int) (*models.BucketRetention, error) {
+	req := map[string]int{"min_retention_days": minRetentionDays}
+	var resp struct {
+		Data models.BucketRetention `json:"data"`
+	}
+	if err := c.Put(ctx, "/api/v1/retention/"+PathEscape(bucket), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// DeleteBucketRetention 删除bucket的保留策略
+func (c *MetadataClient) DeleteBucketRetention(ctx context.Context, bucket string) error {
+	return c.Delete(ctx, "/api/v1/retention/"+PathEscape(bucket))
+}
+
+// GetBucketSchema 获取bucket注册的schema
+func (c *MetadataClient) GetBucketSchema(ctx context.Context, bucket string) (*models.BucketSchema, error) {
+	var resp struct {
+		Data models.BucketSchema `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/schemas/"+PathEscape(bucket), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// SetBucketSchema 注册bucket的schema
+func (c *MetadataClient) SetBucketSchema(ctx context.Context, bucket string, schema json.RawMessage) (*models.BucketSchema, error) {
+	var resp struct {
+		Data models.BucketSchema `json:"data"`
+	}
+	if err := c.Put(ctx, "/api/v1/schemas/"+PathEscape(bucket), schema, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// DeleteBucketSchema 删除bucket的schema
+func (c *MetadataClient) DeleteBucketSchema(ctx context.Context, bucket string) error {
+	return c.Delete(ctx, "/api/v1/schemas/"+PathEscape(bucket))
+}
+
+// ValidateMetadataSchema 按bucket的schema校验元数据（不写入），不符合时返回*models.SchemaValidationError
+func (c *MetadataClient) ValidateMetadataSchema(ctx context.Context, metadata *models.Metadata) error {
+	return c.writeMetadata(ctx, "POST", "/api/v1/metadata/validate", metadata, http.StatusOK)
+}
+
+// GetUsage 获取bucket/前缀下的对象数和字节数
+func (c *MetadataClient) GetUsage(ctx context.Context, bucket, prefix string) (*models.ObjectSummary, error) {
+	queryParams := BuildQueryParams(map[string]any{
+		"bucket": bucket,
+		"prefix": prefix,
+	})
+
+	var resp struct {
+		Data models.ObjectSummary `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/metadata/usage", queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
 // HealthCheck 健康检查
 func (c *MetadataClient) HealthCheck(ctx context.Context) error {
 	return c.BaseHTTPClient.HealthCheck(ctx)
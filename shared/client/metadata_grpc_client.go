@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mocks3/shared/models"
+	"mocks3/shared/proto/metadatapb"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	grpcmetadata "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataGRPCClient 元数据服务gRPC客户端，用于服务间低延迟调用
+type MetadataGRPCClient struct {
+	conn    *grpc.ClientConn
+	client  metadatapb.MetadataServiceClient
+	health  healthpb.HealthClient
+	timeout time.Duration
+}
+
+// NewMetadataGRPCClient 创建元数据服务gRPC客户端，target为host:port
+func NewMetadataGRPCClient(target string, timeout time.Duration) (*MetadataGRPCClient, error) {
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithUnaryInterceptor(tenantUnaryInterceptor),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata gRPC client: %w", err)
+	}
+
+	return &MetadataGRPCClient{
+		conn:    conn,
+		client:  metadatapb.NewMetadataServiceClient(conn),
+		health:  healthpb.NewHealthClient(conn),
+		timeout: timeout,
+	}, nil
+}
+
+// tenantUnaryInterceptor 将上下文中的租户、原始操作者和请求ID写入gRPC metadata
+func tenantUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if tenant, ok := models.LookupTenant(ctx); ok {
+		ctx = grpcmetadata.AppendToOutgoingContext(ctx, strings.ToLower(models.HeaderTenantID), tenant)
+	}
+	if actor := models.AuditActorFromContext(ctx); actor.Name != "" {
+		ctx = grpcmetadata.AppendToOutgoingContext(ctx, strings.ToLower(models.HeaderActor), actor.Name)
+	}
+	if requestID := models.RequestIDFromContext(ctx); requestID != "" {
+		ctx = grpcmetadata.AppendToOutgoingContext(ctx, strings.ToLower(models.HeaderRequestID), requestID)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// withTimeout 为调用设置超时，timeout<=0时不限制
+func (c *MetadataGRPCClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// SaveMetadata 保存元数据，不符合bucket schema时返回*models.SchemaValidationError
+func (c *MetadataGRPCClient) SaveMetadata(ctx context.Context, metadata *models.Metadata) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := c.client.SaveMetadata(ctx, &metadatapb.SaveMetadataRequest{Metadata: metadatapb.FromModel(metadata)}); err != nil {
+		if st, ok := status.FromError(err); ok {
+			if validationErr := metadatapb.SchemaViolationFromStatus(st); validationErr != nil {
+				return validationErr
+			}
+		}
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
+
+// GetMetadata 获取元数据
+func (c *MetadataGRPCClient) GetMetadata(ctx context.Context, bucket, key string) (*models.Metadata, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.GetMetadata(ctx, &metadatapb.GetMetadataRequest{Bucket: bucket, Key: key})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, bucket, key)
+		}
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+	return resp.GetMetadata().ToModel(), nil
+}
+
+// UpdateMetadata 更新元数据，不符合bucket schema时返回*models.SchemaValidationError
+func (c *MetadataGRPCClient) UpdateMetadata(ctx context.Context, metadata *models.Metadata) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := c.client.UpdateMetadata(ctx, &metadatapb.UpdateMetadataRequest{Metadata: metadatapb.FromModel(metadata)}); err != nil {
+		if st, ok := status.FromError(err); ok {
+			if validationErr := metadatapb.SchemaViolationFromStatus(st); validationErr != nil {
+				return validationErr
+			}
+		}
+		return fmt.Errorf("failed to update metadata: %w", err)
+	}
+	return nil
+}
+
+// DeleteMetadata 删除元数据，对象仍在bucket保留期内时返回*models.RetentionViolationError
+func (c *MetadataGRPCClient) DeleteMetadata(ctx context.Context, bucket, key string) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := c.client.DeleteMetadata(ctx, &metadatapb.DeleteMetadataRequest{Bucket: bucket, Key: key}); err != nil {
+		if st, ok := status.FromError(err); ok {
+			if violation := metadatapb.RetentionViolationFromStatus(st); violation != nil {
+				return violation
+			}
+		}
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("%w: %s/%s", models.ErrMetadataNotFound, bucket, key)
+		}
+		return fmt.Errorf("failed to delete metadata: %w", err)
+	}
+	return nil
+}
+
+// ListMetadataFiltered 按属性过滤分页列出元数据
+func (c *MetadataGRPCClient) ListMetadataFiltered(ctx context.Context, filter *models.MetadataFilter, continuationToken string, limit int) (*models.MetadataPage, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.ListMetadata(ctx, &metadatapb.ListMetadataRequest{
+		Filter:            metadatapb.FilterFromModel(filter),
+		ContinuationToken: continuationToken,
+		Limit:             int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metadata: %w", err)
+	}
+
+	page := &models.MetadataPage{
+		Metadata:              make([]*models.Metadata, 0, len(resp.GetMetadata())),
+		IsTruncated:           resp.GetIsTruncated(),
+		NextContinuationToken: resp.GetNextContinuationToken(),
+	}
+	for _, item := range resp.GetMetadata() {
+		page.Metadata = append(page.Metadata, item.ToModel())
+	}
+	return page, nil
+}
+
+// CountObjects 计算对象数量
+func (c *MetadataGRPCClient) CountObjects(ctx context.Context, bucket, prefix string) (int64, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.CountObjects(ctx, &metadatapb.CountObjectsRequest{Bucket: bucket, Prefix: prefix})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count objects: %w", err)
+	}
+	return resp.GetCount(), nil
+}
+
+// FindMetadataByHash 查找内容哈希（MD5十六进制）和大小相同的对象，按创建时间升序
+func (c *MetadataGRPCClient) FindMetadataByHash(ctx context.Context, hash string, size int64, limit int) ([]*models.Metadata, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.FindMetadataByHash(ctx, &metadatapb.FindMetadataByHashRequest{
+		Hash:  hash,
+		Size:  size,
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find metadata by hash: %w", err)
+	}
+
+	result := make([]*models.Metadata, 0, len(resp.GetMetadata()))
+	for _, item := range resp.GetMetadata() {
+		result = append(result, item.ToModel())
+	}
+	return result, nil
+}
+
+// ValidateMetadataSchema 按bucket的schema校验元数据（不写入），不符合时返回*models.SchemaValidationError
+func (c *MetadataGRPCClient) ValidateMetadataSchema(ctx context.Context, metadata *models.Metadata) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.client.ValidateMetadataSchema(ctx, &metadatapb.ValidateMetadataSchemaRequest{Metadata: metadatapb.FromModel(metadata)})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			if validationErr := metadatapb.SchemaViolationFromStatus(st); validationErr != nil {
+				return validationErr
+			}
+		}
+		return fmt.Errorf("failed to validate metadata schema: %w", err)
+	}
+	return nil
+}
+
+// HealthCheck 通过gRPC健康检查协议检查元数据服务
+func (c *MetadataGRPCClient) HealthCheck(ctx context.Context) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{Service: metadatapb.MetadataService_ServiceDesc.ServiceName})
+	if err != nil {
+		return fmt.Errorf("metadata gRPC health check failed: %w", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("metadata gRPC service not serving: %s", resp.GetStatus())
+	}
+	return nil
+}
+
+// Close 关闭连接
+func (c *MetadataGRPCClient) Close() error {
+	return c.conn.Close()
+}
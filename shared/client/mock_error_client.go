@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"time"
+)
+
+// MockErrorClient 错误注入服务客户端
+type MockErrorClient struct {
+	*BaseHTTPClient
+}
+
+// NewMockErrorClient 创建错误注入服务客户端
+func NewMockErrorClient(baseURL string, timeout time.Duration) *MockErrorClient {
+	return &MockErrorClient{
+		BaseHTTPClient: NewBaseHTTPClient(baseURL, timeout),
+	}
+}
+
+// SetNamespace 设置规则命名空间，注入检查只评估该命名空间的规则，添加的规则默认属于该命名空间；为空时为default
+func (c *MockErrorClient) SetNamespace(namespace string) {
+	if namespace != "" {
+		c.SetHeader(models.HeaderNamespace, namespace)
+	}
+}
+
+// injectionResponse 错误注入检查响应
+type injectionResponse struct {
+	ShouldInject bool                `json:"should_inject"`
+	Service      string              `json:"service"`
+	Operation    string              `json:"operation"`
+	Action       *models.ErrorAction `json:"action,omitempty"`
+}
+
+// CheckInjection 检查指定服务操作是否需要注入错误
+func (c *MockErrorClient) CheckInjection(ctx context.Context, service, operation string) (*models.ErrorAction, bool, error) {
+	return c.CheckInjectionWithMetadata(ctx, service, operation, nil)
+}
+
+// CheckInjectionWithMetadata 按请求元数据检查指定服务操作是否需要注入错误，键见models.ErrorMetadata*
+func (c *MockErrorClient) CheckInjectionWithMetadata(ctx context.Context, service, operation string, metadata map[string]string) (*models.ErrorAction, bool, error) {
+	path := fmt.Sprintf("/api/v1/inject/%s/%s", PathEscape(service), PathEscape(operation))
+
+	var body any
+	if len(metadata) > 0 {
+		body = map[string]any{"metadata": metadata}
+	}
+
+	var resp injectionResponse
+	if err := c.Post(ctx, path, body, &resp); err != nil {
+		return nil, false, err
+	}
+
+	if !resp.ShouldInject || resp.Action == nil {
+		return nil, false, nil
+	}
+	return resp.Action, true, nil
+}
+
+// AddRule 添加错误注入规则，返回规则ID
+func (c *MockErrorClient) AddRule(ctx context.Context, rule *models.ErrorRule) (string, error) {
+	var resp struct {
+		RuleID string `json:"rule_id"`
+	}
+	if err := c.Post(ctx, "/api/v1/rules", rule, &resp); err != nil {
+		return "", err
+	}
+	rule.ID = resp.RuleID
+	return resp.RuleID, nil
+}
+
+// RemoveRule 删除错误注入规则
+func (c *MockErrorClient) RemoveRule(ctx context.Context, ruleID string) error {
+	path := fmt.Sprintf("/api/v1/rules/%s", PathEscape(ruleID))
+	return c.Delete(ctx, path)
+}
+
+// HealthCheck 健康检查
+func (c *MockErrorClient) HealthCheck(ctx context.Context) error {
+	return c.BaseHTTPClient.HealthCheck(ctx)
+}
@@ -22,7 +22,73 @@ func NewQueueClient(baseURL string, timeout time.Duration) *QueueClient {
 
 // EnqueueTask 入队任务
 func (c *QueueClient) EnqueueTask(ctx context.Context, task *models.Task) error {
-	return c.PostExpectStatus(ctx, "/tasks", task, http.StatusCreated)
+	return c.PostExpectStatus(ctx, "/api/v1/tasks", task, http.StatusCreated)
+}
+
+// EnqueueTasks 批量入队任务，ScheduledAt非零的任务在该时间之后才对worker可见，ExpiresAt非空的任务过期后被丢弃，
+// Next中的任务在任务完成后入队
+//
+// 部分任务失败时不返回错误，各任务的结果见BatchEnqueueResponse.Results。
+func (c *QueueClient) EnqueueTasks(ctx context.Context, tasks []*models.Task) (*models.BatchEnqueueResponse, error) {
+	items := make([]map[string]any, len(tasks))
+	for i, task := range tasks {
+		items[i] = map[string]any{
+			"type":     task.Type,
+			"priority": task.Priority,
+			"data":     task.Data,
+		}
+		if !task.ScheduledAt.IsZero() {
+			items[i]["enqueue_at"] = task.ScheduledAt
+		}
+		if task.CallbackURL != "" {
+			items[i]["callback_url"] = task.CallbackURL
+		}
+		if task.ExpiresAt != nil {
+			items[i]["ttl"] = time.Until(*task.ExpiresAt).String()
+		}
+		if len(task.Next) > 0 {
+			items[i]["next"] = task.Next
+		}
+	}
+	req := map[string]any{"tasks": items}
+	var resp models.BatchEnqueueResponse
+	if err := c.Post(ctx, "/api/v1/tasks/batch", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ScheduleTask 入队延迟任务，任务在enqueueAt之后才对worker可见
+func (c *QueueClient) ScheduleTask(ctx context.Context, task *models.Task, enqueueAt time.Time) error {
+	req := map[string]any{
+		"type":       task.Type,
+		"priority":   task.Priority,
+		"data":       task.Data,
+		"enqueue_at": enqueueAt,
+	}
+	if task.CallbackURL != "" {
+		req["callback_url"] = task.CallbackURL
+	}
+	if task.ExpiresAt != nil {
+		req["ttl"] = time.Until(*task.ExpiresAt).String()
+	}
+	return c.PostExpectStatus(ctx, "/api/v1/tasks", req, http.StatusCreated)
+}
+
+// GetJob 获取任务执行状态，jobID为入队时返回的job_id
+func (c *QueueClient) GetJob(ctx context.Context, jobID string) (*models.JobStatus, error) {
+	path := fmt.Sprintf("/api/v1/jobs/%s", PathEscape(jobID))
+	var job models.JobStatus
+	if err := c.Get(ctx, path, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ReportJobProgress 上报正在执行的任务的进度（0-100）
+func (c *QueueClient) ReportJobProgress(ctx context.Context, jobID string, progress int) error {
+	path := fmt.Sprintf("/api/v1/jobs/%s/progress", PathEscape(jobID))
+	return c.PutExpectStatus(ctx, path, map[string]any{"progress": progress}, http.StatusOK)
 }
 
 // DequeueTask 出队任务
@@ -85,6 +151,76 @@ func (c *QueueClient) GetQueueStats(ctx context.Context, queueName string) (*mod
 	return &stats, err
 }
 
+// ReceiveTasks 领取最多maxTasks个任务，任务在visibilityTimeout内对其他消费者不可见，没有任务时最多等待wait
+//
+// 处理完成后用租约凭证调用AckTask确认，失败时调用NackTask放回；visibilityTimeout为0时使用服务端默认值。
+func (c *QueueClient) ReceiveTasks(ctx context.Context, maxTasks int, visibilityTimeout, wait time.Duration) ([]*models.TaskLease, error) {
+	req := map[string]any{
+		"max_tasks":          maxTasks,
+		"visibility_timeout": int(visibilityTimeout / time.Second),
+		"wait_time":          int(wait / time.Second),
+	}
+	var resp struct {
+		Leases []*models.TaskLease `json:"leases"`
+	}
+	if err := c.Post(ctx, "/api/v1/tasks/receive", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Leases, nil
+}
+
+// AckTask 确认领取的任务已处理完成
+func (c *QueueClient) AckTask(ctx context.Context, receiptHandle string) error {
+	path := fmt.Sprintf("/api/v1/leases/%s/ack", PathEscape(receiptHandle))
+	return c.PostExpectStatus(ctx, path, nil, http.StatusOK)
+}
+
+// AckTaskWithOutput 确认领取的任务已处理完成，output合并到任务后续任务的数据中
+func (c *QueueClient) AckTaskWithOutput(ctx context.Context, receiptHandle string, output map[string]any) error {
+	path := fmt.Sprintf("/api/v1/leases/%s/ack", PathEscape(receiptHandle))
+	return c.PostExpectStatus(ctx, path, map[string]any{"output": output}, http.StatusOK)
+}
+
+// NackTask 放回领取的任务，delay后重新可见；delay为负时按服务端的重试策略退避
+func (c *QueueClient) NackTask(ctx context.Context, receiptHandle, reason string, delay time.Duration) error {
+	path := fmt.Sprintf("/api/v1/leases/%s/nack", PathEscape(receiptHandle))
+	req := map[string]any{"reason": reason}
+	if delay >= 0 {
+		req["delay"] = delay.String()
+	}
+	return c.PostExpectStatus(ctx, path, req, http.StatusOK)
+}
+
+// ExtendVisibility 将领取的任务的不可见时间延长为从现在起visibilityTimeout
+func (c *QueueClient) ExtendVisibility(ctx context.Context, receiptHandle string, visibilityTimeout time.Duration) (*models.TaskLease, error) {
+	path := fmt.Sprintf("/api/v1/leases/%s/visibility", PathEscape(receiptHandle))
+	var lease models.TaskLease
+	if err := c.Put(ctx, path, map[string]any{"visibility_timeout": int(visibilityTimeout / time.Second)}, &lease); err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
+
+// PauseQueue 暂停队列，worker处理完已拉取的任务后不再拉取新任务，入队不受影响
+func (c *QueueClient) PauseQueue(ctx context.Context, queueName, reason string) (*models.QueueState, error) {
+	path := fmt.Sprintf("/api/v1/queues/%s/pause", PathEscape(queueName))
+	var state models.QueueState
+	if err := c.Post(ctx, path, map[string]any{"reason": reason}, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// ResumeQueue 恢复已暂停的队列
+func (c *QueueClient) ResumeQueue(ctx context.Context, queueName string) (*models.QueueState, error) {
+	path := fmt.Sprintf("/api/v1/queues/%s/resume", PathEscape(queueName))
+	var state models.QueueState
+	if err := c.Post(ctx, path, nil, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
 // RegisterWorker 注册工作节点
 func (c *QueueClient) RegisterWorker(ctx context.Context, worker *models.Worker) error {
 	return c.PostExpectStatus(ctx, "/workers", worker, http.StatusCreated)
@@ -115,6 +251,38 @@ func (c *QueueClient) UpdateTaskStatus(ctx context.Context, taskID string, statu
 	return c.PutExpectStatus(ctx, path, req, http.StatusOK)
 }
 
+// PublishEvent 发布事件到主题，payload按原样发送（可以不是合法JSON）
+func (c *QueueClient) PublishEvent(ctx context.Context, topic string, payload []byte) (*models.TopicEvent, error) {
+	path := fmt.Sprintf("/api/v1/topics/%s/events", PathEscape(topic))
+	var event models.TopicEvent
+	if err := c.DoRequestWithJSON(ctx, RequestOptions{
+		Method:  "POST",
+		Path:    path,
+		RawBody: payload,
+	}, &event); err != nil {
+		return nil, err
+	}
+	event.Payload = string(payload)
+	return &event, nil
+}
+
+// ReadEvents 读取主题中afterID之后的事件
+func (c *QueueClient) ReadEvents(ctx context.Context, topic, afterID string, count int) ([]*models.TopicEvent, error) {
+	path := fmt.Sprintf("/api/v1/topics/%s/events", PathEscape(topic))
+	queryParams := BuildQueryParams(map[string]any{
+		"after": afterID,
+		"count": count,
+	})
+
+	var resp struct {
+		Events []*models.TopicEvent `json:"events"`
+	}
+	if err := c.Get(ctx, path, queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Events, nil
+}
+
 // HealthCheck 健康检查
 func (c *QueueClient) HealthCheck(ctx context.Context) error {
 	return c.BaseHTTPClient.HealthCheck(ctx)
@@ -93,16 +93,32 @@ func (c *StorageClient) DeleteObject(ctx context.Context, bucket, key string) er
 // ListObjects 列出对象
 func (c *StorageClient) ListObjects(ctx context.Context, req *models.ListObjectsRequest) (*models.ListObjectsResponse, error) {
 	queryParams := BuildQueryParams(map[string]any{
-		"bucket":      req.Bucket,
-		"prefix":      req.Prefix,
-		"delimiter":   req.Delimiter,
-		"max_keys":    req.MaxKeys,
-		"start_after": req.StartAfter,
+		"bucket": req.Bucket,
+		"prefix": req.Prefix,
+		"limit":  req.MaxKeys,
 	})
 
-	var listResp models.ListObjectsResponse
-	err := c.Get(ctx, "/objects", queryParams, &listResp)
-	return &listResp, err
+	var resp struct {
+		Data models.ListObjectsResponse `json:"data"`
+	}
+	if err := c.Get(ctx, "/api/v1/objects", queryParams, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// GetObjectReplicas 获取对象在各存储节点上的副本状态
+func (c *StorageClient) GetObjectReplicas(ctx context.Context, bucket, key string) ([]*models.ObjectReplica, error) {
+	path := fmt.Sprintf("/api/v1/objects/%s/%s/replicas", PathEscape(bucket), PathEscape(key))
+	var resp struct {
+		Data struct {
+			Replicas []*models.ObjectReplica `json:"replicas"`
+		} `json:"data"`
+	}
+	if err := c.Get(ctx, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data.Replicas, nil
 }
 
 // HealthCheck 健康检查
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"net/http"
+	"time"
+)
+
+// ToxiproxyClient Toxiproxy管理接口客户端
+type ToxiproxyClient struct {
+	*BaseHTTPClient
+}
+
+// NewToxiproxyClient 创建Toxiproxy客户端，baseURL为管理接口地址（默认端口8474）
+func NewToxiproxyClient(baseURL string, timeout time.Duration) *ToxiproxyClient {
+	return &ToxiproxyClient{
+		BaseHTTPClient: NewBaseHTTPClient(baseURL, timeout),
+	}
+}
+
+// ListToxics 列出代理上的toxic
+func (c *ToxiproxyClient) ListToxics(ctx context.Context, proxy string) ([]*models.Toxic, error) {
+	var toxics []*models.Toxic
+	if err := c.Get(ctx, fmt.Sprintf("/proxies/%s/toxics", PathEscape(proxy)), nil, &toxics); err != nil {
+		return nil, fmt.Errorf("list toxics of proxy %s: %w", proxy, err)
+	}
+	return toxics, nil
+}
+
+// CreateToxic 在代理上创建toxic
+func (c *ToxiproxyClient) CreateToxic(ctx context.Context, proxy string, toxic *models.Toxic) error {
+	path := fmt.Sprintf("/proxies/%s/toxics", PathEscape(proxy))
+	if err := c.PostExpectStatus(ctx, path, toxic, http.StatusOK, http.StatusCreated); err != nil {
+		return fmt.Errorf("create toxic %s on proxy %s: %w", toxic.Name, proxy, err)
+	}
+	return nil
+}
+
+// DeleteToxic 删除代理上的toxic
+func (c *ToxiproxyClient) DeleteToxic(ctx context.Context, proxy, name string) error {
+	path := fmt.Sprintf("/proxies/%s/toxics/%s", PathEscape(proxy), PathEscape(name))
+	if err := c.Delete(ctx, path, http.StatusNoContent, http.StatusOK, http.StatusNotFound); err != nil {
+		return fmt.Errorf("delete toxic %s on proxy %s: %w", name, proxy, err)
+	}
+	return nil
+}
@@ -16,7 +16,7 @@ type ErrorInjectorService interface {
 	ListErrorRules(ctx context.Context) ([]*models.ErrorRule, error)
 
 	// 错误注入执行
-	ShouldInjectError(ctx context.Context, service, operation string) (*models.ErrorAction, bool)
+	ShouldInjectError(ctx context.Context, namespace, service, operation string) (*models.ErrorAction, bool) // 只评估namespace中的规则，为空时为default
 	InjectError(ctx context.Context, action *models.ErrorAction) error
 
 	// 统计信息
@@ -41,7 +41,8 @@ type ErrorInjector interface {
 
 // ErrorRuleEngine 错误规则引擎接口
 type ErrorRuleEngine interface {
-	EvaluateRules(ctx context.Context, service, operation string, metadata map[string]string) (*models.ErrorAction, bool)
+	EvaluateRules(ctx context.Context, namespace, service, operation string, metadata map[string]string) (*models.ErrorAction, bool)
+	MatchRule(ctx context.Context, namespace, service, operation string, metadata map[string]string) (*models.ErrorRule, bool)
 	AddRule(rule *models.ErrorRule) error
 	RemoveRule(ruleID string) error
 	UpdateRule(rule *models.ErrorRule) error
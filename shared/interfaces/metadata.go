@@ -2,7 +2,9 @@ package interfaces
 
 import (
 	"context"
+	"io"
 	"mocks3/shared/models"
+	"time"
 )
 
 // MetadataService 元数据服务接口
@@ -12,14 +14,58 @@ type MetadataService interface {
 	GetMetadata(ctx context.Context, bucket, key string) (*models.Metadata, error)
 	UpdateMetadata(ctx context.Context, metadata *models.Metadata) error
 	DeleteMetadata(ctx context.Context, bucket, key string) error
+	RestoreMetadata(ctx context.Context, bucket, key string) (*models.Metadata, error)
+	BatchMetadata(ctx context.Context, req *models.MetadataBatchRequest) (*models.MetadataBatchResponse, error)
+
+	// 版本历史
+	GetMetadataHistory(ctx context.Context, bucket, key string, limit, offset int) ([]*models.MetadataVersion, error)
+	RestoreMetadataVersion(ctx context.Context, bucket, key string, version int64) (*models.Metadata, error)
 
 	// 查询操作
 	ListMetadata(ctx context.Context, bucket, prefix string, limit, offset int) ([]*models.Metadata, error)
+	ListMetadataFiltered(ctx context.Context, filter *models.MetadataFilter, limit, offset int) ([]*models.Metadata, error)
+	ListMetadataPage(ctx context.Context, filter *models.MetadataFilter, continuationToken string, limit int) (*models.MetadataPage, error)
+	QueryMetadataByTags(ctx context.Context, query string, filter *models.MetadataFilter, continuationToken string, limit int) (*models.MetadataPage, error)
 	SearchMetadata(ctx context.Context, query string, limit int) ([]*models.Metadata, error)
+	SearchObjects(ctx context.Context, query *models.MetadataSearchQuery) (*models.MetadataSearchResult, error)
+	ShardKeyspace(ctx context.Context, bucket, prefix string, shards int) (*models.MetadataShardPlan, error)
+
+	// 重复内容
+	FindMetadataByHash(ctx context.Context, hash string, size int64, limit int) ([]*models.Metadata, error)
+	FindDuplicates(ctx context.Context, bucket string, minCopies, limit int) (*models.DuplicateReport, error)
+
+	// 变更审计
+	ListMetadataAudit(ctx context.Context, query *models.MetadataAuditQuery) (*models.MetadataAuditPage, error)
+
+	// bucket保留策略
+	GetBucketRetention(ctx context.Context, bucket string) (*models.BucketRetention, error)
+	SetBucketRetention(ctx context.Context, retention *models.BucketRetention) error
+	DeleteBucketRetention(ctx context.Context, bucket string) error
+	ListBucketRetention(ctx context.Context) ([]*models.BucketRetention, error)
+
+	// bucket自定义元数据schema
+	GetBucketSchema(ctx context.Context, bucket string) (*models.BucketSchema, error)
+	SetBucketSchema(ctx context.Context, schema *models.BucketSchema) error
+	DeleteBucketSchema(ctx context.Context, bucket string) error
+	ListBucketSchemas(ctx context.Context) ([]*models.BucketSchema, error)
+	ValidateMetadataSchema(ctx context.Context, metadata *models.Metadata) error
 
 	// 统计操作
 	GetStats(ctx context.Context) (*models.Stats, error)
+	RefreshStats(ctx context.Context) (*models.Stats, error)
 	CountObjects(ctx context.Context, bucket, prefix string) (int64, error)
+	GetUsage(ctx context.Context, bucket, prefix string) (*models.ObjectSummary, error)
+	ListTagCounts(ctx context.Context, bucket string, limit int) ([]*models.TagCount, error)
+	ListTenants(ctx context.Context) ([]*models.TenantStats, error)
+
+	// 导出导入
+	ExportMetadata(ctx context.Context, filter *models.MetadataFilter, format string, w io.Writer) (int, error)
+	ImportMetadata(ctx context.Context, r io.Reader, format, conflict string) (*models.MetadataImportResult, error)
+
+	// 生命周期
+	PreviewLifecycleRule(ctx context.Context, req *models.LifecyclePreviewRequest) (*models.LifecyclePreview, error)
+	ScanExpiredObjects(ctx context.Context, dryRun bool) (*models.ExpiryScanResult, error)
+	LastExpiryScan() *models.ExpiryScanResult
 
 	// 健康检查
 	HealthCheck(ctx context.Context) error
@@ -31,8 +77,43 @@ type MetadataRepository interface {
 	GetByKey(ctx context.Context, bucket, key string) (*models.Metadata, error)
 	Update(ctx context.Context, metadata *models.Metadata) error
 	Delete(ctx context.Context, bucket, key string) error
+	Restore(ctx context.Context, bucket, key string, deletedAfter time.Time) (*models.Metadata, error)
+	Import(ctx context.Context, metadata *models.Metadata, overwrite bool) (string, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
+	ExecuteBatch(ctx context.Context, ops []*models.MetadataBatchOperation, atomic bool) ([]*models.MetadataBatchItemResult, bool, error)
+	ListHistory(ctx context.Context, bucket, key string, limit, offset int) ([]*models.MetadataVersion, error)
+	GetVersion(ctx context.Context, bucket, key string, version int64) (*models.MetadataVersion, error)
+	ListAudit(ctx context.Context, query *models.MetadataAuditQuery) ([]*models.MetadataAuditEntry, error)
+	GetBucketRetention(ctx context.Context, bucket string) (*models.BucketRetention, error)
+	PutBucketRetention(ctx context.Context, retention *models.BucketRetention) error
+	DeleteBucketRetention(ctx context.Context, bucket string) (bool, error)
+	ListBucketRetention(ctx context.Context) ([]*models.BucketRetention, error)
+	GetBucketSchema(ctx context.Context, bucket string) (*models.BucketSchema, error)
+	PutBucketSchema(ctx context.Context, schema *models.BucketSchema) error
+	DeleteBucketSchema(ctx context.Context, bucket string) (bool, error)
+	ListBucketSchemas(ctx context.Context) ([]*models.BucketSchema, error)
 	List(ctx context.Context, bucket, prefix string, limit, offset int) ([]*models.Metadata, error)
+	ListFiltered(ctx context.Context, filter *models.MetadataFilter, limit, offset int) ([]*models.Metadata, error)
+	ListAfter(ctx context.Context, filter *models.MetadataFilter, cursor *models.MetadataCursor, limit int) ([]*models.Metadata, error)
+	ListDelimited(ctx context.Context, filter *models.MetadataFilter, after string, limit int) ([]*models.MetadataListEntry, error)
 	Search(ctx context.Context, query string, limit int) ([]*models.Metadata, error)
+	SampleKeys(ctx context.Context, bucket, prefix string, sampleSize int) ([]string, error)
 	Count(ctx context.Context, bucket, prefix string) (int64, error)
+	Usage(ctx context.Context, bucket, prefix string) (*models.ObjectSummary, error)
+	FindByHash(ctx context.Context, hash string, size int64, limit int) ([]*models.Metadata, error)
+	FindDuplicates(ctx context.Context, bucket string, minCopies, limit int) ([]*models.DuplicateGroup, error)
+	TagCounts(ctx context.Context, bucket string, limit int) ([]*models.TagCount, error)
+	SummarizeByAge(ctx context.Context, filter *models.MetadataFilter, cutoffs []time.Time, samplePercent float64) (*models.ObjectSummary, []*models.ObjectSummary, error)
 	GetStats(ctx context.Context) (*models.Stats, error)
+	ListTenants(ctx context.Context) ([]*models.TenantStats, error)
+	SaveStatsSnapshot(ctx context.Context, snapshots map[string]*models.Stats) error
+	LoadStatsSnapshot(ctx context.Context) (map[string]*models.Stats, error)
+}
+
+// SearchIndex 元数据搜索索引接口
+type SearchIndex interface {
+	Name() string
+	Index(ctx context.Context, metadata *models.Metadata) error
+	Remove(ctx context.Context, bucket, key string) error
+	Search(ctx context.Context, query *models.MetadataSearchQuery) (*models.MetadataSearchResult, error)
 }
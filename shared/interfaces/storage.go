@@ -9,10 +9,23 @@ import (
 type StorageService interface {
 	// 文件操作
 	WriteObject(ctx context.Context, object *models.Object) error
+	WriteObjectDedup(ctx context.Context, object *models.Object) (*models.ObjectReference, error)
 	ReadObject(ctx context.Context, bucket, key string) (*models.Object, error)
-	DeleteObject(ctx context.Context, bucket, key string) error
+	HeadObject(ctx context.Context, bucket, key string) (*models.ObjectInfo, error)
+	DeleteObject(ctx context.Context, req *models.DeleteObjectRequest) error
+	AppendObject(ctx context.Context, bucket, key string, position int64, data []byte) (*models.Object, error)
+	CopyObject(ctx context.Context, req *models.CopyObjectRequest) (*models.CopyObjectResult, error)
 	ListObjects(ctx context.Context, req *models.ListObjectsRequest) (*models.ListObjectsResponse, error)
 
+	// 分片上传
+	CreateMultipartUpload(ctx context.Context, upload *models.MultipartUpload) (*models.MultipartUpload, error)
+	UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, data []byte) (string, error)
+	CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, req *models.CompleteMultipartUploadRequest) (*models.Object, error)
+	AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error
+
+	// 副本检查
+	InspectReplicas(ctx context.Context, bucket, key string) ([]*models.ObjectReplica, error)
+
 	// 统计信息
 	GetStats(ctx context.Context) (map[string]interface{}, error)
 
@@ -0,0 +1,31 @@
+package jsonschema
+
+import "sync"
+
+// maxCachedSchemas 编译缓存的最大条数，超出时整体清空
+const maxCachedSchemas = 256
+
+// Cache 按schema文档内容缓存编译结果，文档变更后自然失效；零值可用
+type Cache struct {
+	mu       sync.Mutex
+	compiled map[string]*Schema
+}
+
+// Get 返回文档对应的已编译schema，未缓存时编译并缓存
+func (c *Cache) Get(document []byte) (*Schema, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if schema, ok := c.compiled[string(document)]; ok {
+		return schema, nil
+	}
+	schema, err := Compile(document)
+	if err != nil {
+		return nil, err
+	}
+	if c.compiled == nil || len(c.compiled) >= maxCachedSchemas {
+		c.compiled = make(map[string]*Schema)
+	}
+	c.compiled[string(document)] = schema
+	return schema, nil
+}
@@ -0,0 +1,113 @@
+package jsonschema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// JSON Schema校验，用于对象的自定义元数据和队列任务的payload，基于santhosh-tekuri/jsonschema实现。
+// 未声明$schema时按draft 2020-12处理，format关键字作为断言；$ref只能引用文档内部，不加载外部资源。
+
+// schemaURL schema文档注册到编译器时使用的地址
+const schemaURL = "mem://schema.json"
+
+// errExternalRef 拒绝加载外部schema的错误
+var errExternalRef = errors.New("external references are not allowed")
+
+// printer 违规信息使用的语言
+var printer = message.NewPrinter(language.English)
+
+// Violation 一条校验失败记录
+type Violation struct {
+	Path    string // 实例中的JSON Pointer，根为""
+	Keyword string // 未通过的关键字
+	Message string
+}
+
+// Schema 编译后的schema
+type Schema struct {
+	schema *jsonschema.Schema
+}
+
+// noLoader 不加载任何外部资源，避免schema经$ref读取本地文件或访问网络
+type noLoader struct{}
+
+// Load 实现jsonschema.URLLoader
+func (noLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("%w: %s", errExternalRef, url)
+}
+
+// Compile 解析并校验schema文档
+func Compile(data []byte) (*Schema, error) {
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	compiler.UseLoader(noLoader{})
+	if err := compiler.AddResource(schemaURL, document); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return &Schema{schema: schema}, nil
+}
+
+// Validate 校验实例，返回全部违规（通过时为空）
+//
+// 实例应为encoding/json解码得到的值（map[string]interface{}、[]interface{}、string、float64、json.Number、bool、nil）。
+func (s *Schema) Validate(instance interface{}) []Violation {
+	err := s.schema.Validate(instance)
+	if err == nil {
+		return nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []Violation{{Message: err.Error()}}
+	}
+
+	var violations []Violation
+	collectViolations(validationErr, &violations)
+	return violations
+}
+
+// collectViolations 展开嵌套的校验错误，只保留叶子节点
+func collectViolations(err *jsonschema.ValidationError, violations *[]Violation) {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			collectViolations(cause, violations)
+		}
+		return
+	}
+
+	keyword := "false"
+	if path := err.ErrorKind.KeywordPath(); len(path) > 0 {
+		keyword = path[len(path)-1]
+	}
+	*violations = append(*violations, Violation{
+		Path:    instancePointer(err.InstanceLocation),
+		Keyword: keyword,
+		Message: err.ErrorKind.LocalizedString(printer),
+	})
+}
+
+// instancePointer 实例位置转为JSON Pointer（RFC 6901），根为""
+func instancePointer(location []string) string {
+	var sb strings.Builder
+	for _, token := range location {
+		sb.WriteString("/")
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"mocks3/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// AuditActorMiddleware 返回Gin操作者解析中间件，将操作者写入请求context供审计记录使用
+//
+// 操作者名称优先取有效API key的名称，其次取上游服务透传的X-Mocks3-Actor请求头；
+// authorizer为nil时只读取请求头。需注册在鉴权和租户中间件之后。
+func AuditActorMiddleware(authorizer *Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.GetString(RBACKeyNameContextKey)
+		if name == "" && authorizer != nil {
+			if apiKey, ok := authorizer.Authenticate(c.GetHeader(authorizer.config.Header)); ok {
+				name = apiKey.Name
+			}
+		}
+		if name == "" {
+			name = strings.TrimSpace(c.GetHeader(models.HeaderActor))
+		}
+
+		tenant, _ := models.LookupTenant(c.Request.Context())
+		c.Request = c.Request.WithContext(models.WithAuditActor(c.Request.Context(), models.AuditActor{
+			Name:      name,
+			Tenant:    tenant,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
+		c.Next()
+	}
+}
+
+// AuditActorUnaryServerInterceptor 返回gRPC操作者解析拦截器，名称规则与AuditActorMiddleware一致，
+// API key和操作者从同名（小写）的gRPC metadata读取
+func AuditActorUnaryServerInterceptor(authorizer *Authorizer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		first := func(name string) string {
+			if values := md.Get(name); len(values) > 0 {
+				return values[0]
+			}
+			return ""
+		}
+
+		var actor models.AuditActor
+		if authorizer != nil {
+			if apiKey, ok := authorizer.Authenticate(first(authorizer.config.Header)); ok {
+				actor.Name = apiKey.Name
+			}
+		}
+		if actor.Name == "" {
+			actor.Name = strings.TrimSpace(first(models.HeaderActor))
+		}
+		actor.Tenant, _ = models.LookupTenant(ctx)
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			actor.IP = p.Addr.String()
+			if host, _, err := net.SplitHostPort(actor.IP); err == nil {
+				actor.IP = host
+			}
+		}
+		actor.UserAgent = first("user-agent")
+		return handler(models.WithAuditActor(ctx, actor), req)
+	}
+}
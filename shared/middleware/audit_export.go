@@ -0,0 +1,340 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditExportOperation 向mock-error查询注入时使用的操作名
+const AuditExportOperation = "audit_export"
+
+// 畸形事件类型，通过corruption动作的metadata.malform指定，未指定时随机选择
+const (
+	AuditMalformTruncated      = "truncated"       // JSON被截断
+	AuditMalformMissingFields  = "missing_fields"  // 缺少event_id/event_type/timestamp
+	AuditMalformWrongTypes     = "wrong_types"     // 字段类型错误
+	AuditMalformUnknownVersion = "unknown_version" // 未知的schema版本
+	AuditMalformNotJSON        = "not_json"        // 非JSON内容
+	AuditMalformRaw            = "raw"             // 使用动作body原样发送
+)
+
+var auditMalformModes = []string{
+	AuditMalformTruncated,
+	AuditMalformMissingFields,
+	AuditMalformWrongTypes,
+	AuditMalformUnknownVersion,
+	AuditMalformNotJSON,
+}
+
+// AuditPublisher 审计事件发布者（由QueueClient实现）
+type AuditPublisher interface {
+	PublishEvent(ctx context.Context, topic string, payload []byte) (*models.TopicEvent, error)
+}
+
+// InjectionChecker 错误注入检查器（由MockErrorClient实现）
+type InjectionChecker interface {
+	CheckInjection(ctx context.Context, service, operation string) (*models.ErrorAction, bool, error)
+}
+
+// AuditExportConfig 审计导出配置
+type AuditExportConfig struct {
+	ServiceName    string        // 事件中的service字段
+	Topic          string        // 目标主题
+	BufferSize     int           // 待发送事件缓冲区大小，满时丢弃新事件
+	PublishTimeout time.Duration // 单次发布超时
+	TenantHeaders  []string      // 按顺序读取租户标识的请求头
+	ExcludePaths   []string      // 不导出的路径前缀
+}
+
+// DefaultAuditExportConfig 默认审计导出配置
+func DefaultAuditExportConfig(serviceName string) *AuditExportConfig {
+	return &AuditExportConfig{
+		ServiceName:    serviceName,
+		Topic:          models.DefaultAuditTopic,
+		BufferSize:     1000,
+		PublishTimeout: 5 * time.Second,
+		TenantHeaders:  []string{"X-Api-Key", "X-Tenant-Id"},
+		ExcludePaths:   []string{"/health", "/metrics", "/api/v1/admin"},
+	}
+}
+
+// AuditExporter 将访问日志和审计事件异步导出到队列主题
+type AuditExporter struct {
+	config    *AuditExportConfig
+	publisher AuditPublisher
+	injector  InjectionChecker
+	logger    *observability.Logger
+	events    chan *models.AuditEvent
+
+	published atomic.Int64
+	malformed atomic.Int64
+	dropped   atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewAuditExporter 创建审计导出器
+func NewAuditExporter(config *AuditExportConfig, publisher AuditPublisher, logger *observability.Logger) (*AuditExporter, error) {
+	if config == nil || config.ServiceName == "" {
+		return nil, fmt.Errorf("audit export service name is required")
+	}
+	if config.Topic == "" {
+		return nil, fmt.Errorf("audit export topic is required")
+	}
+	if publisher == nil {
+		return nil, fmt.Errorf("audit publisher is required")
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1000
+	}
+	if config.PublishTimeout <= 0 {
+		config.PublishTimeout = 5 * time.Second
+	}
+
+	return &AuditExporter{
+		config:    config,
+		publisher: publisher,
+		logger:    logger,
+		events:    make(chan *models.AuditEvent, config.BufferSize),
+	}, nil
+}
+
+// SetInjectionChecker 设置错误注入检查器，命中corruption动作时发送畸形事件
+func (e *AuditExporter) SetInjectionChecker(checker InjectionChecker) {
+	e.injector = checker
+}
+
+// Start 启动后台发布协程，ctx取消后停止
+func (e *AuditExporter) Start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-e.events:
+				e.publish(ctx, event)
+			}
+		}
+	}()
+}
+
+// Record 记录一条事件（非阻塞），缓冲区满时丢弃并返回false
+func (e *AuditExporter) Record(event *models.AuditEvent) bool {
+	event.SchemaVersion = models.AuditEventSchemaVersion
+	if event.EventID == "" {
+		event.EventID = utils.NewID()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if event.Service == "" {
+		event.Service = e.config.ServiceName
+	}
+
+	select {
+	case e.events <- event:
+		return true
+	default:
+		e.dropped.Add(1)
+		return false
+	}
+}
+
+// Stats 获取导出统计
+func (e *AuditExporter) Stats() *models.AuditExportStats {
+	return &models.AuditExportStats{
+		Topic:     e.config.Topic,
+		Queued:    len(e.events),
+		Published: e.published.Load(),
+		Malformed: e.malformed.Load(),
+		Dropped:   e.dropped.Load(),
+		Failed:    e.failed.Load(),
+	}
+}
+
+// GinMiddleware 返回Gin审计导出中间件，每个请求完成后生成一条事件
+func (e *AuditExporter) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, prefix := range e.config.ExcludePaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		start := time.Now()
+		c.Next()
+
+		e.Record(e.buildEvent(c, start))
+	}
+}
+
+// RegisterAdminRoutes 注册审计导出管理API
+func (e *AuditExporter) RegisterAdminRoutes(group *gin.RouterGroup) {
+	group.GET("/audit-export/stats", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    e.Stats(),
+		})
+	})
+}
+
+// buildEvent 根据请求上下文生成事件
+func (e *AuditExporter) buildEvent(c *gin.Context, start time.Time) *models.AuditEvent {
+	eventType := models.AuditEventTypeAccess
+	switch c.Request.Method {
+	case http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch:
+		eventType = models.AuditEventTypeAudit
+	}
+
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+
+	var tenant string
+	for _, header := range e.config.TenantHeaders {
+		if value := c.GetHeader(header); value != "" {
+			tenant = value
+			break
+		}
+	}
+
+	status := c.Writer.Status()
+	bytesOut := int64(c.Writer.Size())
+	if bytesOut < 0 {
+		bytesOut = 0
+	}
+	bytesIn := c.Request.ContentLength
+	if bytesIn < 0 {
+		bytesIn = 0
+	}
+
+	event := &models.AuditEvent{
+		EventType:  eventType,
+		Timestamp:  start.UTC(),
+		RequestID:  requestIDFromGin(c),
+		Action:     c.Request.Method + " " + route,
+		DurationMs: time.Since(start).Milliseconds(),
+		Actor: models.AuditActor{
+			Name:      models.AuditActorFromContext(c.Request.Context()).Name,
+			Tenant:    tenant,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		},
+		Resource: models.AuditResource{
+			Bucket: c.Param("bucket"),
+			Key:    strings.TrimPrefix(c.Param("key"), "/"),
+			Path:   c.Request.URL.Path,
+		},
+		Outcome: models.AuditOutcome{
+			StatusCode: status,
+			Success:    status < http.StatusBadRequest,
+			BytesIn:    bytesIn,
+			BytesOut:   bytesOut,
+		},
+	}
+	if len(c.Errors) > 0 {
+		event.Outcome.Error = c.Errors.String()
+	}
+
+	return event
+}
+
+// publish 序列化并发布事件，必要时替换为畸形事件
+func (e *AuditExporter) publish(ctx context.Context, event *models.AuditEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		e.failed.Add(1)
+		return
+	}
+
+	if e.injector != nil {
+		action, inject, err := e.injector.CheckInjection(ctx, e.config.ServiceName, AuditExportOperation)
+		if err == nil && inject && action.Type == models.ErrorActionTypeCorruption {
+			payload = malformAuditPayload(payload, action)
+			e.malformed.Add(1)
+		}
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, e.config.PublishTimeout)
+	defer cancel()
+
+	if _, err := e.publisher.PublishEvent(publishCtx, e.config.Topic, payload); err != nil {
+		e.failed.Add(1)
+		if e.logger != nil {
+			e.logger.Warn(ctx, "Failed to publish audit event",
+				observability.String("topic", e.config.Topic),
+				observability.String("event_id", event.EventID),
+				observability.String("error", err.Error()))
+		}
+		return
+	}
+	e.published.Add(1)
+}
+
+// malformAuditPayload 按注入动作生成畸形事件
+func malformAuditPayload(payload []byte, action *models.ErrorAction) []byte {
+	mode, _ := action.Metadata["malform"].(string)
+	if mode == "" {
+		mode = auditMalformModes[rand.Intn(len(auditMalformModes))]
+	}
+
+	switch mode {
+	case AuditMalformTruncated:
+		return payload[:len(payload)/2]
+	case AuditMalformNotJSON:
+		return []byte("<corrupted audit event>")
+	case AuditMalformRaw:
+		return []byte(action.Body)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return payload
+	}
+
+	switch mode {
+	case AuditMalformMissingFields:
+		delete(fields, "event_id")
+		delete(fields, "event_type")
+		delete(fields, "timestamp")
+	case AuditMalformWrongTypes:
+		if ts, err := time.Parse(time.RFC3339Nano, fmt.Sprint(fields["timestamp"])); err == nil {
+			fields["timestamp"] = ts.Unix()
+		}
+		fields["duration_ms"] = fmt.Sprint(fields["duration_ms"])
+		if outcome, ok := fields["outcome"].(map[string]interface{}); ok {
+			outcome["status_code"] = fmt.Sprint(outcome["status_code"])
+			outcome["success"] = fmt.Sprint(outcome["success"])
+		}
+	case AuditMalformUnknownVersion:
+		fields["schema_version"] = "99.0"
+	default:
+		return payload
+	}
+
+	malformed, err := json.Marshal(fields)
+	if err != nil {
+		return payload
+	}
+	return malformed
+}
+
+// requestIDFromGin 获取请求ID，未注册请求ID中间件时读取请求头
+func requestIDFromGin(c *gin.Context) string {
+	if id := models.RequestIDFromContext(c.Request.Context()); id != "" {
+		return id
+	}
+	return c.GetHeader(models.HeaderRequestID)
+}
@@ -120,6 +120,16 @@ func (cm *ConsulManager) DiscoverServices(ctx context.Context, serviceName strin
 	return serviceInfos, nil
 }
 
+// ServiceExists 检查服务是否在Consul中注册（不论健康状态）
+func (cm *ConsulManager) ServiceExists(ctx context.Context, serviceName string) (bool, error) {
+	services, _, err := cm.client.Catalog().Service(serviceName, "", nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to query service catalog: %w", err)
+	}
+
+	return len(services) > 0, nil
+}
+
 // GetConfig 获取配置
 func (cm *ConsulManager) GetConfig(ctx context.Context, key string) (string, error) {
 	kv, _, err := cm.client.KV().Get(key, nil)
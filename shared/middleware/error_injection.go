@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"mocks3/shared/interfaces"
@@ -26,8 +27,8 @@ func NewErrorInjectionMiddleware(injectorService interfaces.ErrorInjectorService
 	}
 }
 
-// GinMiddleware 返回Gin中间件
-func (m *ErrorInjectionMiddleware) GinMiddleware(serviceName string) gin.HandlerFunc {
+// GinMiddleware 返回Gin中间件，只评估namespace中的规则
+func (m *ErrorInjectionMiddleware) GinMiddleware(namespace, serviceName string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !m.enabled {
 			c.Next()
@@ -38,7 +39,7 @@ func (m *ErrorInjectionMiddleware) GinMiddleware(serviceName string) gin.Handler
 		operation := m.extractOperation(c)
 
 		// 检查是否应该注入错误
-		action, shouldInject := m.injectorService.ShouldInjectError(c.Request.Context(), serviceName, operation)
+		action, shouldInject := m.injectorService.ShouldInjectError(c.Request.Context(), namespace, serviceName, operation)
 		if !shouldInject {
 			c.Next()
 			return
@@ -50,11 +51,12 @@ func (m *ErrorInjectionMiddleware) GinMiddleware(serviceName string) gin.Handler
 		}
 
 		c.Next()
+		finishDisconnect(c)
 	}
 }
 
-// HTTPMiddleware 返回标准HTTP中间件
-func (m *ErrorInjectionMiddleware) HTTPMiddleware(serviceName string) func(http.Handler) http.Handler {
+// HTTPMiddleware 返回标准HTTP中间件，只评估namespace中的规则
+func (m *ErrorInjectionMiddleware) HTTPMiddleware(namespace, serviceName string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if !m.enabled {
@@ -66,12 +68,37 @@ func (m *ErrorInjectionMiddleware) HTTPMiddleware(serviceName string) func(http.
 			operation := m.extractOperationFromRequest(r)
 
 			// 检查是否应该注入错误
-			action, shouldInject := m.injectorService.ShouldInjectError(r.Context(), serviceName, operation)
+			action, shouldInject := m.injectorService.ShouldInjectError(r.Context(), namespace, serviceName, operation)
 			if !shouldInject {
 				next.ServeHTTP(w, r)
 				return
 			}
 
+			// 限速需要包装响应写入器
+			if action.Type == models.ErrorActionTypeThrottle && action.BytesPerSecond > 0 {
+				next.ServeHTTP(&throttledResponseWriter{
+					ResponseWriter: w,
+					throttle:       newThrottle(r.Context(), action.BytesPerSecond),
+				}, r)
+				return
+			}
+			if action.Type == models.ErrorActionTypeSlowDrip && action.BytesPerSecond > 0 {
+				next.ServeHTTP(&slowDripResponseWriter{
+					ResponseWriter: w,
+					drip:           newSlowDrip(r.Context(), action),
+				}, r)
+				return
+			}
+			if action.Type == models.ErrorActionTypeDisconnect {
+				writer := &slowDripResponseWriter{
+					ResponseWriter: w,
+					drip:           newMidStreamDisconnect(r.Context(), action),
+				}
+				next.ServeHTTP(writer, r)
+				writer.finish()
+				return
+			}
+
 			// 注入错误
 			if m.injectHTTPError(w, r, action) {
 				return // 错误已注入，停止处理
@@ -122,6 +149,15 @@ func (m *ErrorInjectionMiddleware) injectError(c *gin.Context, action *models.Er
 		return m.injectTimeout(c, action)
 	case models.ErrorActionTypeCorruption:
 		return m.injectCorruption(c, action)
+	case models.ErrorActionTypeThrottle:
+		return m.injectThrottle(c, action)
+	case models.ErrorActionTypeSlowDrip:
+		return m.injectSlowDrip(c, action)
+	case models.ErrorActionTypeDisconnect:
+		return m.injectDisconnect(c, action)
+	case models.ErrorActionTypeCPUPressure, models.ErrorActionTypeMemoryPressure:
+		startResourcePressure(action)
+		return false // 继续处理请求
 	default:
 		return false
 	}
@@ -136,6 +172,9 @@ func (m *ErrorInjectionMiddleware) injectHTTPError(w http.ResponseWriter, r *htt
 		return m.injectDelayStandard(w, r, action)
 	case models.ErrorActionTypeTimeout:
 		return m.injectTimeoutStandard(w, r, action)
+	case models.ErrorActionTypeCPUPressure, models.ErrorActionTypeMemoryPressure:
+		startResourcePressure(action)
+		return false // 继续处理请求
 	default:
 		return false
 	}
@@ -288,23 +327,298 @@ func (w *corruptedResponseWriter) Write(data []byte) (int, error) {
 	return w.ResponseWriter.Write(corrupted)
 }
 
+// injectThrottle 限制响应带宽
+func (m *ErrorInjectionMiddleware) injectThrottle(c *gin.Context, action *models.ErrorAction) bool {
+	if action.BytesPerSecond <= 0 {
+		return false
+	}
+
+	c.Writer = &throttledGinWriter{
+		ResponseWriter: c.Writer,
+		throttle:       newThrottle(c.Request.Context(), action.BytesPerSecond),
+	}
+
+	return false // 继续处理请求
+}
+
+// throttle 按字节/秒节流写入
+type throttle struct {
+	ctx            context.Context
+	bytesPerSecond int64
+	start          time.Time
+	written        int64
+}
+
+func newThrottle(ctx context.Context, bytesPerSecond int64) *throttle {
+	return &throttle{ctx: ctx, bytesPerSecond: bytesPerSecond}
+}
+
+// write 分块写入，每块约十分之一秒的带宽，写完一块后等待到按带宽应写完的时间
+func (t *throttle) write(data []byte, write func([]byte) (int, error), flush func()) (int, error) {
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+
+	chunkSize := max(int(t.bytesPerSecond/10), 1)
+	total := 0
+	for len(data) > 0 {
+		chunk := data[:min(chunkSize, len(data))]
+		n, err := write(chunk)
+		total += n
+		t.written += int64(n)
+		if err != nil {
+			return total, err
+		}
+		data = data[n:]
+		if flush != nil {
+			flush()
+		}
+
+		due := t.start.Add(time.Duration(t.written * int64(time.Second) / t.bytesPerSecond))
+		if wait := time.Until(due); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-t.ctx.Done():
+				timer.Stop()
+				return total, t.ctx.Err()
+			}
+		}
+	}
+	return total, nil
+}
+
+// throttledGinWriter 限速的Gin响应写入器
+type throttledGinWriter struct {
+	gin.ResponseWriter
+	throttle *throttle
+}
+
+func (w *throttledGinWriter) Write(data []byte) (int, error) {
+	return w.throttle.write(data, w.ResponseWriter.Write, w.ResponseWriter.Flush)
+}
+
+func (w *throttledGinWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// throttledResponseWriter 限速的标准HTTP响应写入器
+type throttledResponseWriter struct {
+	http.ResponseWriter
+	throttle *throttle
+}
+
+func (w *throttledResponseWriter) Write(data []byte) (int, error) {
+	var flush func()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flush = flusher.Flush
+	}
+	return w.throttle.write(data, w.ResponseWriter.Write, flush)
+}
+
+// Unwrap 供http.ResponseController访问底层写入器
+func (w *throttledResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// injectSlowDrip 立即发送响应头，按带宽滴流响应体
+func (m *ErrorInjectionMiddleware) injectSlowDrip(c *gin.Context, action *models.ErrorAction) bool {
+	if action.BytesPerSecond <= 0 {
+		return false
+	}
+
+	c.Writer = &slowDripGinWriter{
+		ResponseWriter: c.Writer,
+		drip:           newSlowDrip(c.Request.Context(), action),
+	}
+
+	return false // 继续处理请求
+}
+
+// injectDisconnect 处理请求，发送部分响应后断开连接
+func (m *ErrorInjectionMiddleware) injectDisconnect(c *gin.Context, action *models.ErrorAction) bool {
+	c.Writer = &slowDripGinWriter{
+		ResponseWriter: c.Writer,
+		drip:           newMidStreamDisconnect(c.Request.Context(), action),
+	}
+
+	return false // 继续处理请求，处理结束后由finishDisconnect断开
+}
+
+// finishDisconnect 处理函数返回后，disconnect动作的响应未达到断开字节数时仍断开连接
+func finishDisconnect(c *gin.Context) {
+	if writer, ok := c.Writer.(*slowDripGinWriter); ok {
+		writer.finish()
+	}
+}
+
+// errSlowDripAborted 滴流到指定字节数后已断开连接
+var errSlowDripAborted = errors.New("slow drip aborted connection (injected)")
+
+// slowDrip 响应头不受限速影响立即发送，响应体按带宽写出，写到abortAfter字节后断开连接
+//
+// disconnect动作也使用slowDrip：不限速时throttle为nil，发送abortAfter字节（可以为0）后一定断开。
+type slowDrip struct {
+	throttle   *throttle
+	abort      bool // 是否在abortAfter字节后断开
+	disconnect bool // 响应体不足abortAfter字节时处理结束后也断开
+	abortAfter int64
+	written    int64
+	started    bool
+	aborted    bool
+}
+
+func newSlowDrip(ctx context.Context, action *models.ErrorAction) *slowDrip {
+	return &slowDrip{
+		throttle:   newThrottle(ctx, action.BytesPerSecond),
+		abort:      action.AbortAfterBytes > 0,
+		abortAfter: action.AbortAfterBytes,
+	}
+}
+
+// newMidStreamDisconnect 发送响应头和abort_after_bytes字节的响应体后断开连接，设置bytes_per_second时同时限速
+func newMidStreamDisconnect(ctx context.Context, action *models.ErrorAction) *slowDrip {
+	drip := &slowDrip{abort: true, disconnect: true, abortAfter: action.AbortAfterBytes}
+	if action.BytesPerSecond > 0 {
+		drip.throttle = newThrottle(ctx, action.BytesPerSecond)
+	}
+	return drip
+}
+
+// write 首次写入前先发送响应头，达到断开字节数时写完剩余额度后调用abort
+func (d *slowDrip) write(data []byte, write func([]byte) (int, error), flush func(), abort func()) (int, error) {
+	if d.aborted {
+		return 0, errSlowDripAborted
+	}
+	if !d.started {
+		d.started = true
+		if flush != nil {
+			flush()
+		}
+	}
+
+	truncated := false
+	if d.abort && d.written+int64(len(data)) >= d.abortAfter {
+		data = data[:d.abortAfter-d.written]
+		truncated = true
+	}
+
+	var n int
+	var err error
+	if d.throttle != nil {
+		n, err = d.throttle.write(data, write, flush)
+	} else {
+		n, err = write(data)
+		if flush != nil {
+			flush()
+		}
+	}
+	d.written += int64(n)
+	if err != nil || !truncated {
+		return n, err
+	}
+
+	d.aborted = true
+	abort()
+	return n, errSlowDripAborted
+}
+
+// finish 处理结束时响应体仍未达到断开字节数，发送已写入的数据后断开，客户端同样收到不完整的响应
+func (d *slowDrip) finish(flush func(), abort func()) {
+	if !d.disconnect || d.aborted {
+		return
+	}
+	d.aborted = true
+	if flush != nil {
+		flush()
+	}
+	abort()
+}
+
+// slowDripGinWriter 滴流的Gin响应写入器
+type slowDripGinWriter struct {
+	gin.ResponseWriter
+	drip *slowDrip
+}
+
+func (w *slowDripGinWriter) Write(data []byte) (int, error) {
+	return w.drip.write(data, w.ResponseWriter.Write, w.flush, w.abort)
+}
+
+func (w *slowDripGinWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// flush 发送响应头和已写入的数据
+func (w *slowDripGinWriter) flush() {
+	w.ResponseWriter.WriteHeaderNow()
+	w.ResponseWriter.Flush()
+}
+
+// finish 处理函数返回后调用，断开动作确保连接被断开
+func (w *slowDripGinWriter) finish() {
+	w.drip.finish(w.flush, w.abort)
+}
+
+// abort 接管并关闭连接，客户端收到不完整的响应体
+func (w *slowDripGinWriter) abort() {
+	if conn, _, err := w.ResponseWriter.Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+// slowDripResponseWriter 滴流的标准HTTP响应写入器
+type slowDripResponseWriter struct {
+	http.ResponseWriter
+	drip *slowDrip
+}
+
+func (w *slowDripResponseWriter) Write(data []byte) (int, error) {
+	flush, abort := w.controls()
+	return w.drip.write(data, w.ResponseWriter.Write, flush, abort)
+}
+
+// finish 处理函数返回后调用，断开动作确保连接被断开
+func (w *slowDripResponseWriter) finish() {
+	w.drip.finish(w.controls())
+}
+
+// controls 返回发送已写入数据和断开连接的函数
+func (w *slowDripResponseWriter) controls() (func(), func()) {
+	controller := http.NewResponseController(w.ResponseWriter)
+	flush := func() { controller.Flush() }
+	abort := func() {
+		if conn, _, err := controller.Hijack(); err == nil {
+			conn.Close()
+		}
+	}
+	return flush, abort
+}
+
+// Unwrap 供http.ResponseController访问底层写入器
+func (w *slowDripResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // DatabaseErrorInjector 数据库错误注入器
 type DatabaseErrorInjector struct {
 	injectorService interfaces.ErrorInjectorService
+	namespace       string
 	serviceName     string
 }
 
 // NewDatabaseErrorInjector 创建数据库错误注入器
-func NewDatabaseErrorInjector(injectorService interfaces.ErrorInjectorService, serviceName string) *DatabaseErrorInjector {
+func NewDatabaseErrorInjector(injectorService interfaces.ErrorInjectorService, namespace, serviceName string) *DatabaseErrorInjector {
 	return &DatabaseErrorInjector{
 		injectorService: injectorService,
+		namespace:       namespace,
 		serviceName:     serviceName,
 	}
 }
 
 // ShouldInjectError 检查是否应该注入数据库错误
 func (d *DatabaseErrorInjector) ShouldInjectError(ctx context.Context, operation string) error {
-	action, shouldInject := d.injectorService.ShouldInjectError(ctx, d.serviceName, operation)
+	action, shouldInject := d.injectorService.ShouldInjectError(ctx, d.namespace, d.serviceName, operation)
 	if !shouldInject {
 		return nil
 	}
@@ -328,20 +642,22 @@ func (d *DatabaseErrorInjector) ShouldInjectError(ctx context.Context, operation
 // StorageErrorInjector 存储错误注入器
 type StorageErrorInjector struct {
 	injectorService interfaces.ErrorInjectorService
+	namespace       string
 	serviceName     string
 }
 
 // NewStorageErrorInjector 创建存储错误注入器
-func NewStorageErrorInjector(injectorService interfaces.ErrorInjectorService, serviceName string) *StorageErrorInjector {
+func NewStorageErrorInjector(injectorService interfaces.ErrorInjectorService, namespace, serviceName string) *StorageErrorInjector {
 	return &StorageErrorInjector{
 		injectorService: injectorService,
+		namespace:       namespace,
 		serviceName:     serviceName,
 	}
 }
 
 // ShouldInjectError 检查是否应该注入存储错误
 func (s *StorageErrorInjector) ShouldInjectError(ctx context.Context, operation string) error {
-	action, shouldInject := s.injectorService.ShouldInjectError(ctx, s.serviceName, operation)
+	action, shouldInject := s.injectorService.ShouldInjectError(ctx, s.namespace, s.serviceName, operation)
 	if !shouldInject {
 		return nil
 	}
@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetadataInjectionChecker 按请求元数据检查错误注入（由MockErrorClient实现）
+type MetadataInjectionChecker interface {
+	CheckInjectionWithMetadata(ctx context.Context, service, operation string, metadata map[string]string) (*models.ErrorAction, bool, error)
+}
+
+// FaultInjectionConfig 错误注入客户端中间件配置
+type FaultInjectionConfig struct {
+	ServiceName  string        // 检查时使用的服务名，与规则的service匹配
+	CheckTimeout time.Duration // 单次检查超时，超时或失败时不注入
+	ExcludePaths []string      // 不检查的路径前缀
+}
+
+// DefaultFaultInjectionConfig 默认错误注入客户端中间件配置
+func DefaultFaultInjectionConfig(serviceName string) *FaultInjectionConfig {
+	return &FaultInjectionConfig{
+		ServiceName:  serviceName,
+		CheckTimeout: 200 * time.Millisecond,
+		ExcludePaths: []string{"/health", "/metrics", "/api/v1/admin"},
+	}
+}
+
+// FaultInjector 在数据路径上向mock-error查询每个请求是否注入错误并执行命中的动作
+//
+// 操作名为"<METHOD> <路由>"，请求头、查询参数、路径、请求大小以及trace ID、用户、租户和对象
+// 作为元数据供规则条件匹配。
+type FaultInjector struct {
+	config  *FaultInjectionConfig
+	checker MetadataInjectionChecker
+	logger  *observability.Logger
+}
+
+// NewFaultInjector 创建错误注入客户端中间件
+func NewFaultInjector(config *FaultInjectionConfig, checker MetadataInjectionChecker, logger *observability.Logger) (*FaultInjector, error) {
+	if config == nil || config.ServiceName == "" {
+		return nil, fmt.Errorf("fault injection service name is required")
+	}
+	if checker == nil {
+		return nil, fmt.Errorf("fault injection checker is required")
+	}
+	if config.CheckTimeout <= 0 {
+		config.CheckTimeout = 200 * time.Millisecond
+	}
+
+	return &FaultInjector{
+		config:  config,
+		checker: checker,
+		logger:  logger,
+	}, nil
+}
+
+// GinMiddleware 返回Gin中间件
+func (f *FaultInjector) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, prefix := range f.config.ExcludePaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		operation := c.Request.Method + " " + c.Request.URL.Path
+		if path := c.FullPath(); path != "" {
+			operation = c.Request.Method + " " + path
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), f.config.CheckTimeout)
+		action, inject, err := f.checker.CheckInjectionWithMetadata(ctx, f.config.ServiceName, operation, requestMetadata(c))
+		cancel()
+		if err != nil {
+			f.logger.Debug(c.Request.Context(), "Fault injection check failed",
+				observability.String("operation", operation),
+				observability.String("error", err.Error()))
+			c.Next()
+			return
+		}
+		if !inject || action == nil {
+			c.Next()
+			return
+		}
+
+		f.logger.Info(c.Request.Context(), "Injecting fault",
+			observability.String("operation", operation),
+			observability.String("action_type", action.Type))
+
+		if f.apply(c, action) {
+			c.Abort()
+			return
+		}
+		c.Next()
+		finishDisconnect(c)
+	}
+}
+
+// apply 执行错误动作，返回true表示已结束请求
+func (f *FaultInjector) apply(c *gin.Context, action *models.ErrorAction) bool {
+	switch action.Type {
+	case models.ErrorActionTypeHTTPError:
+		writeInjectedError(c, action, action.HTTPCode)
+		return true
+	case models.ErrorActionTypeDelay:
+		// 延迟后继续处理请求，客户端断开时直接结束
+		return !sleepContext(c.Request.Context(), action.Delay)
+	case models.ErrorActionTypeTimeout:
+		if sleepContext(c.Request.Context(), action.Delay) {
+			writeInjectedError(c, &models.ErrorAction{Message: "Request timeout (injected)"}, http.StatusRequestTimeout)
+		}
+		return true
+	case models.ErrorActionTypeNetworkError:
+		disconnect(c)
+		return true
+	case models.ErrorActionTypeDisconnect:
+		// 处理请求，发送响应头和部分响应体后断开连接
+		c.Writer = &slowDripGinWriter{
+			ResponseWriter: c.Writer,
+			drip:           newMidStreamDisconnect(c.Request.Context(), action),
+		}
+		return false
+	case models.ErrorActionTypeCorruption:
+		c.Writer = &corruptedResponseWriter{ResponseWriter: c.Writer, corruptionRate: 0.1}
+		return false
+	case models.ErrorActionTypeThrottle:
+		if action.BytesPerSecond > 0 {
+			c.Writer = &throttledGinWriter{
+				ResponseWriter: c.Writer,
+				throttle:       newThrottle(c.Request.Context(), action.BytesPerSecond),
+			}
+		}
+		return false
+	case models.ErrorActionTypeSlowDrip:
+		if action.BytesPerSecond > 0 {
+			c.Writer = &slowDripGinWriter{
+				ResponseWriter: c.Writer,
+				drip:           newSlowDrip(c.Request.Context(), action),
+			}
+		}
+		return false
+	case models.ErrorActionTypeCPUPressure, models.ErrorActionTypeMemoryPressure:
+		// 资源压力在后台持续一段时间，请求照常处理
+		if !startResourcePressure(action) {
+			f.logger.Debug(c.Request.Context(), "Resource pressure already active",
+				observability.String("action_type", action.Type))
+		}
+		return false
+	default:
+		// 数据库、存储错误由对应的注入器在业务代码中处理
+		return false
+	}
+}
+
+// requestMetadata 提取规则条件使用的请求元数据
+func requestMetadata(c *gin.Context) map[string]string {
+	metadata := map[string]string{
+		models.ErrorMetadataPath:       c.Request.URL.Path,
+		models.ErrorMetadataUserAgent:  c.Request.UserAgent(),
+		models.ErrorMetadataRemoteAddr: c.ClientIP(),
+	}
+	if c.Request.ContentLength >= 0 {
+		metadata[models.ErrorMetadataRequestSize] = strconv.FormatInt(c.Request.ContentLength, 10)
+	}
+	for name, values := range c.Request.Header {
+		if len(values) > 0 {
+			metadata[models.ErrorMetadataHeaderPrefix+name] = values[0]
+		}
+	}
+	for name, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			metadata[models.ErrorMetadataParamPrefix+name] = values[0]
+		}
+	}
+	for _, param := range c.Params {
+		metadata[models.ErrorMetadataParamPrefix+param.Key] = param.Value
+	}
+
+	// 请求属性用于把错误限定到某个trace、用户、租户、对象或测试运行
+	if traceID := traceIDFromContext(c.Request.Context()); traceID != "" {
+		metadata[models.ErrorMetadataTraceID] = traceID
+	}
+	if userID := c.GetHeader(models.HeaderUserID); userID != "" {
+		metadata[models.ErrorMetadataUserID] = userID
+	}
+	if tenant, ok := models.LookupTenant(c.Request.Context()); ok {
+		metadata[models.ErrorMetadataTenant] = tenant
+	} else if tenant := c.GetHeader(models.HeaderTenantID); tenant != "" {
+		metadata[models.ErrorMetadataTenant] = tenant
+	} else if tenant := observability.BaggageValue(c.Request.Context(), models.BaggageTenant); tenant != "" {
+		metadata[models.ErrorMetadataTenant] = tenant
+	}
+	if testRunID := observability.BaggageValue(c.Request.Context(), models.BaggageTestRunID); testRunID != "" {
+		metadata[models.ErrorMetadataTestRunID] = testRunID
+	}
+	if scenarioID := observability.BaggageValue(c.Request.Context(), models.BaggageScenarioID); scenarioID != "" {
+		metadata[models.ErrorMetadataScenarioID] = scenarioID
+	}
+	if bucket, key := c.Param("bucket"), c.Param("key"); bucket != "" && key != "" {
+		metadata[models.ErrorMetadataObjectKey] = bucket + "/" + strings.TrimPrefix(key, "/")
+	}
+	return metadata
+}
+
+// writeInjectedError 写入注入的错误响应
+func writeInjectedError(c *gin.Context, action *models.ErrorAction, statusCode int) {
+	if statusCode == 0 {
+		statusCode = http.StatusInternalServerError
+	}
+	for key, value := range action.Headers {
+		c.Header(key, value)
+	}
+
+	switch {
+	case action.Body != "":
+		c.String(statusCode, action.Body)
+	case action.Message != "":
+		c.JSON(statusCode, gin.H{"error": action.Message, "code": statusCode, "injected": true})
+	default:
+		c.JSON(statusCode, gin.H{"error": "Injected error", "code": statusCode, "injected": true})
+	}
+}
+
+// disconnect 不写响应直接关闭连接，不支持接管连接时返回502
+func disconnect(c *gin.Context) {
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		c.Header("Connection", "close")
+		c.Status(http.StatusBadGateway)
+		return
+	}
+	conn.Close()
+}
+
+// sleepContext 等待delay，ctx取消时返回false
+func sleepContext(ctx context.Context, delay *time.Duration) bool {
+	if delay == nil || *delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(*delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,651 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 事件包转储结果（指标标签）
+const (
+	incidentResultStored     = "stored"
+	incidentResultFailed     = "failed"
+	incidentResultSuppressed = "suppressed"
+)
+
+// IncidentStore 事件包存储（由StorageClient或存储服务实现）
+type IncidentStore interface {
+	WriteObject(ctx context.Context, object *models.Object) error
+	ReadObject(ctx context.Context, bucket, key string) (*models.Object, error)
+}
+
+// FlightRecorderConfig 飞行记录器配置
+type FlightRecorderConfig struct {
+	ServiceName       string
+	Bucket            string        // 事件包存储bucket，对象键为 <service>/<时间>-<id>.json
+	RequestCapacity   int           // 保留的最近请求数
+	LogCapacity       int           // 保留的最近日志数
+	SpanCapacity      int           // 保留的最近span数
+	SLOWindow         time.Duration // SLO统计窗口，窗口内的请求数同时受RequestCapacity限制
+	MinRequests       int           // 窗口内请求数少于该值时不判定SLO
+	MaxErrorRate      float64       // 5xx比例阈值，0表示不检查
+	LatencyThreshold  time.Duration // 慢请求阈值
+	MaxSlowRate       float64       // 慢请求比例阈值，0表示不检查
+	GuardrailStatuses []int         // 出现即触发护栏抓取的响应状态码
+	Cooldown          time.Duration // 两次自动抓取的最小间隔，手动抓取不受限制
+	UploadTimeout     time.Duration // 单次上传超时
+	MaxIncidents      int           // 内存中保留的事件包摘要数
+	ExcludePaths      []string      // 不记录的路径前缀
+}
+
+// DefaultFlightRecorderConfig 默认飞行记录器配置
+func DefaultFlightRecorderConfig(serviceName string) *FlightRecorderConfig {
+	return &FlightRecorderConfig{
+		ServiceName:       serviceName,
+		Bucket:            models.DefaultIncidentBucket,
+		RequestCapacity:   200,
+		LogCapacity:       500,
+		SpanCapacity:      500,
+		SLOWindow:         time.Minute,
+		MinRequests:       20,
+		MaxErrorRate:      0.1,
+		LatencyThreshold:  time.Second,
+		MaxSlowRate:       0.2,
+		GuardrailStatuses: []int{http.StatusTooManyRequests},
+		Cooldown:          5 * time.Minute,
+		UploadTimeout:     10 * time.Second,
+		MaxIncidents:      100,
+		ExcludePaths:      []string{"/health", "/metrics"},
+	}
+}
+
+// recordRing 固定容量的环形缓冲区，写满后覆盖最旧的记录
+type recordRing[T any] struct {
+	items []T
+	next  int
+	full  bool
+}
+
+func newRecordRing[T any](capacity int) *recordRing[T] {
+	return &recordRing[T]{items: make([]T, capacity)}
+}
+
+func (r *recordRing[T]) add(item T) {
+	r.items[r.next] = item
+	r.next = (r.next + 1) % len(r.items)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *recordRing[T]) len() int {
+	if r.full {
+		return len(r.items)
+	}
+	return r.next
+}
+
+// snapshot 按时间顺序（旧到新）复制记录
+func (r *recordRing[T]) snapshot() []T {
+	result := make([]T, 0, r.len())
+	if r.full {
+		result = append(result, r.items[r.next:]...)
+	}
+	return append(result, r.items[:r.next]...)
+}
+
+// ErrIncidentNotFound 事件包不存在或未保存
+var ErrIncidentNotFound = errors.New("incident not found")
+
+// FlightRecorder 飞行记录器：在内存中保留最近的请求、日志和span摘要，
+// SLO违规或护栏触发时将其转储为事件包写入存储bucket
+//
+// 记录器同时是OTel span处理器和日志钩子，通过Attach接入可观测性组件。
+type FlightRecorder struct {
+	config    *FlightRecorderConfig
+	store     IncidentStore
+	logger    *observability.Logger
+	collector *observability.MetricCollector
+
+	mu          sync.Mutex
+	requests    *recordRing[*models.RequestRecord]
+	logs        *recordRing[*models.LogRecord]
+	spans       *recordRing[*models.SpanRecord]
+	incidents   []*models.IncidentSummary // 旧到新
+	lastCapture time.Time
+	captured    int64
+	failed      int64
+	suppressed  int64
+
+	uploads sync.WaitGroup
+}
+
+var _ sdktrace.SpanProcessor = (*FlightRecorder)(nil)
+
+// NewFlightRecorder 创建飞行记录器
+func NewFlightRecorder(config *FlightRecorderConfig, store IncidentStore, logger *observability.Logger) (*FlightRecorder, error) {
+	if config == nil || config.ServiceName == "" {
+		return nil, fmt.Errorf("flight recorder service name is required")
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("flight recorder bucket is required")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("incident store is required")
+	}
+	if config.MaxErrorRate < 0 || config.MaxErrorRate > 1 || config.MaxSlowRate < 0 || config.MaxSlowRate > 1 {
+		return nil, fmt.Errorf("invalid flight recorder thresholds: rates must be between 0 and 1")
+	}
+
+	defaults := DefaultFlightRecorderConfig(config.ServiceName)
+	if config.RequestCapacity <= 0 {
+		config.RequestCapacity = defaults.RequestCapacity
+	}
+	if config.LogCapacity <= 0 {
+		config.LogCapacity = defaults.LogCapacity
+	}
+	if config.SpanCapacity <= 0 {
+		config.SpanCapacity = defaults.SpanCapacity
+	}
+	if config.SLOWindow <= 0 {
+		config.SLOWindow = defaults.SLOWindow
+	}
+	if config.UploadTimeout <= 0 {
+		config.UploadTimeout = defaults.UploadTimeout
+	}
+	if config.MaxIncidents <= 0 {
+		config.MaxIncidents = defaults.MaxIncidents
+	}
+
+	return &FlightRecorder{
+		config:   config,
+		store:    store,
+		logger:   logger,
+		requests: newRecordRing[*models.RequestRecord](config.RequestCapacity),
+		logs:     newRecordRing[*models.LogRecord](config.LogCapacity),
+		spans:    newRecordRing[*models.SpanRecord](config.SpanCapacity),
+	}, nil
+}
+
+// SetMetricCollector 设置指标收集器
+func (r *FlightRecorder) SetMetricCollector(collector *observability.MetricCollector) {
+	r.collector = collector
+}
+
+// Attach 将记录器注册为日志钩子和span处理器
+func (r *FlightRecorder) Attach(obs *observability.Observability) {
+	obs.Logger().AddHook(r.recordLog)
+	obs.RegisterSpanProcessor(r)
+}
+
+// GinMiddleware 返回Gin中间件：记录请求摘要，并在SLO违规或护栏状态码出现时触发抓取
+func (r *FlightRecorder) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		path := c.Request.URL.Path
+		for _, prefix := range r.config.ExcludePaths {
+			if strings.HasPrefix(path, prefix) {
+				return
+			}
+		}
+
+		record := &models.RequestRecord{
+			Time:         start,
+			Method:       c.Request.Method,
+			Path:         path,
+			Route:        c.FullPath(),
+			Query:        c.Request.URL.RawQuery,
+			Status:       c.Writer.Status(),
+			DurationMs:   float64(time.Since(start).Microseconds()) / 1000,
+			ClientIP:     c.ClientIP(),
+			RequestSize:  max(c.Request.ContentLength, 0),
+			ResponseSize: int64(max(c.Writer.Size(), 0)),
+			TraceID:      traceIDFromContext(c.Request.Context()),
+		}
+		if len(c.Errors) > 0 {
+			record.Errors = c.Errors.String()
+		}
+
+		r.mu.Lock()
+		r.requests.add(record)
+		trigger, reason, slo := r.evaluateLocked(record, time.Now())
+		var bundle *models.IncidentBundle
+		if trigger != "" {
+			bundle = r.captureLocked(trigger, reason, slo, false)
+		}
+		r.mu.Unlock()
+
+		if trigger != "" && bundle == nil {
+			r.recordCaptureMetric(c.Request.Context(), trigger, incidentResultSuppressed)
+		}
+		if bundle != nil {
+			r.uploads.Add(1)
+			go func() {
+				defer r.uploads.Done()
+				r.upload(context.Background(), bundle)
+			}()
+		}
+	}
+}
+
+// Trip 触发一次抓取，返回事件包摘要
+//
+// 护栏和SLO触发受冷却时间限制，冷却期内返回错误；手动触发（manual）总是抓取。
+// 上传失败时摘要状态为failed并返回错误。
+func (r *FlightRecorder) Trip(ctx context.Context, trigger, reason string) (*models.IncidentSummary, error) {
+	if trigger == "" {
+		trigger = models.IncidentTriggerManual
+	}
+
+	r.mu.Lock()
+	bundle := r.captureLocked(trigger, reason, r.sloLocked(time.Now()), trigger == models.IncidentTriggerManual)
+	r.mu.Unlock()
+
+	if bundle == nil {
+		r.recordCaptureMetric(ctx, trigger, incidentResultSuppressed)
+		return nil, fmt.Errorf("incident capture suppressed: cooldown of %s not elapsed", r.config.Cooldown)
+	}
+
+	summary := r.upload(ctx, bundle)
+	if summary.Status == models.IncidentStatusFailed {
+		return summary, fmt.Errorf("failed to store incident bundle: %s", summary.Error)
+	}
+	return summary, nil
+}
+
+// ListIncidents 列出本进程抓取的事件包摘要（新到旧），trigger为空时不过滤
+func (r *FlightRecorder) ListIncidents(trigger string) []*models.IncidentSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]*models.IncidentSummary, 0, len(r.incidents))
+	for i := len(r.incidents) - 1; i >= 0; i-- {
+		if trigger == "" || r.incidents[i].Trigger == trigger {
+			summary := *r.incidents[i]
+			result = append(result, &summary)
+		}
+	}
+	return result
+}
+
+// GetIncident 从存储bucket读取事件包
+func (r *FlightRecorder) GetIncident(ctx context.Context, id string) (*models.IncidentBundle, error) {
+	r.mu.Lock()
+	var summary *models.IncidentSummary
+	for _, incident := range r.incidents {
+		if incident.ID == id {
+			summary = incident
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if summary == nil {
+		return nil, fmt.Errorf("%w: %s", ErrIncidentNotFound, id)
+	}
+	if summary.Status != models.IncidentStatusStored {
+		return nil, fmt.Errorf("%w: %s was not stored: %s", ErrIncidentNotFound, id, summary.Error)
+	}
+
+	object, err := r.store.ReadObject(ctx, summary.Bucket, summary.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read incident bundle: %w", err)
+	}
+
+	var bundle models.IncidentBundle
+	if err := json.Unmarshal(object.Data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to decode incident bundle: %w", err)
+	}
+	return &bundle, nil
+}
+
+// Stats 获取记录器当前状态
+func (r *FlightRecorder) Stats() *models.FlightRecorderStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := &models.FlightRecorderStats{
+		Service:    r.config.ServiceName,
+		Bucket:     r.config.Bucket,
+		Requests:   r.requests.len(),
+		Logs:       r.logs.len(),
+		Spans:      r.spans.len(),
+		Captured:   r.captured,
+		Failed:     r.failed,
+		Suppressed: r.suppressed,
+		SLO:        r.sloLocked(time.Now()),
+	}
+	if !r.lastCapture.IsZero() {
+		lastCapture := r.lastCapture
+		stats.LastCaptureAt = &lastCapture
+	}
+	return stats
+}
+
+// Wait 等待进行中的事件包上传完成或ctx取消
+func (r *FlightRecorder) Wait(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		r.uploads.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// OnStart 实现sdktrace.SpanProcessor，span开始时不记录
+func (r *FlightRecorder) OnStart(parent context.Context, span sdktrace.ReadWriteSpan) {}
+
+// OnEnd 实现sdktrace.SpanProcessor，记录已结束span的摘要
+func (r *FlightRecorder) OnEnd(span sdktrace.ReadOnlySpan) {
+	record := &models.SpanRecord{
+		Name:          span.Name(),
+		Kind:          span.SpanKind().String(),
+		TraceID:       span.SpanContext().TraceID().String(),
+		SpanID:        span.SpanContext().SpanID().String(),
+		StartTime:     span.StartTime(),
+		DurationMs:    float64(span.EndTime().Sub(span.StartTime()).Microseconds()) / 1000,
+		Status:        span.Status().Code.String(),
+		StatusMessage: span.Status().Description,
+	}
+	if span.Parent().IsValid() {
+		record.ParentSpanID = span.Parent().SpanID().String()
+	}
+	if attrs := span.Attributes(); len(attrs) > 0 {
+		record.Attributes = make(map[string]string, len(attrs))
+		for _, attr := range attrs {
+			record.Attributes[string(attr.Key)] = attr.Value.Emit()
+		}
+	}
+
+	r.mu.Lock()
+	r.spans.add(record)
+	r.mu.Unlock()
+}
+
+// Shutdown 实现sdktrace.SpanProcessor
+func (r *FlightRecorder) Shutdown(ctx context.Context) error { return nil }
+
+// ForceFlush 实现sdktrace.SpanProcessor
+func (r *FlightRecorder) ForceFlush(ctx context.Context) error { return nil }
+
+// recordLog 日志钩子，记录实际输出的日志
+func (r *FlightRecorder) recordLog(ctx context.Context, level slog.Level, msg string, fields []observability.Field) {
+	record := &models.LogRecord{
+		Time:    time.Now(),
+		Level:   strings.ToLower(level.String()),
+		Message: msg,
+		TraceID: traceIDFromContext(ctx),
+	}
+	if len(fields) > 0 {
+		record.Fields = make(map[string]string, len(fields))
+		for _, field := range fields {
+			record.Fields[field.Key] = field.Attr().Value.String()
+		}
+	}
+
+	r.mu.Lock()
+	r.logs.add(record)
+	r.mu.Unlock()
+}
+
+// evaluateLocked 判断最新请求是否触发抓取，返回触发类型（空表示未触发）和原因
+func (r *FlightRecorder) evaluateLocked(record *models.RequestRecord, now time.Time) (string, string, *models.SLOSnapshot) {
+	slo := r.sloLocked(now)
+
+	for _, status := range r.config.GuardrailStatuses {
+		if record.Status == status {
+			return models.IncidentTriggerGuardrail,
+				fmt.Sprintf("%s %s returned %d", record.Method, record.Path, record.Status), slo
+		}
+	}
+
+	if slo.Requests < r.config.MinRequests || slo.Requests == 0 {
+		return "", "", slo
+	}
+	if r.config.MaxErrorRate > 0 && slo.ErrorRate > r.config.MaxErrorRate {
+		return models.IncidentTriggerSLO,
+			fmt.Sprintf("error rate %.1f%% over last %d requests exceeds %.1f%%",
+				slo.ErrorRate*100, slo.Requests, r.config.MaxErrorRate*100), slo
+	}
+	if r.config.MaxSlowRate > 0 && r.config.LatencyThreshold > 0 && slo.SlowRate > r.config.MaxSlowRate {
+		return models.IncidentTriggerSLO,
+			fmt.Sprintf("%.1f%% of last %d requests slower than %s exceeds %.1f%%",
+				slo.SlowRate*100, slo.Requests, r.config.LatencyThreshold, r.config.MaxSlowRate*100), slo
+	}
+	return "", "", slo
+}
+
+// sloLocked 统计SLO窗口内的请求
+func (r *FlightRecorder) sloLocked(now time.Time) *models.SLOSnapshot {
+	slo := &models.SLOSnapshot{
+		WindowSeconds:      r.config.SLOWindow.Seconds(),
+		LatencyThresholdMs: float64(r.config.LatencyThreshold.Milliseconds()),
+		MaxErrorRate:       r.config.MaxErrorRate,
+		MaxSlowRate:        r.config.MaxSlowRate,
+	}
+	since := now.Add(-r.config.SLOWindow)
+	thresholdMs := float64(r.config.LatencyThreshold.Microseconds()) / 1000
+
+	for _, record := range r.requests.items {
+		if record == nil || record.Time.Before(since) {
+			continue
+		}
+		slo.Requests++
+		if record.Status >= http.StatusInternalServerError {
+			slo.Errors++
+		}
+		if thresholdMs > 0 && record.DurationMs > thresholdMs {
+			slo.SlowRequests++
+		}
+	}
+	if slo.Requests > 0 {
+		slo.ErrorRate = float64(slo.Errors) / float64(slo.Requests)
+		slo.SlowRate = float64(slo.SlowRequests) / float64(slo.Requests)
+	}
+	return slo
+}
+
+// captureLocked 复制当前记录生成事件包，冷却期内（force为false时）返回nil
+func (r *FlightRecorder) captureLocked(trigger, reason string, slo *models.SLOSnapshot, force bool) *models.IncidentBundle {
+	now := time.Now()
+	if !force && !r.lastCapture.IsZero() && now.Sub(r.lastCapture) < r.config.Cooldown {
+		r.suppressed++
+		return nil
+	}
+	r.lastCapture = now
+
+	return &models.IncidentBundle{
+		ID:        utils.NewID(),
+		Service:   r.config.ServiceName,
+		Trigger:   trigger,
+		Reason:    reason,
+		CreatedAt: now.UTC(),
+		SLO:       slo,
+		Requests:  r.requests.snapshot(),
+		Logs:      r.logs.snapshot(),
+		Spans:     r.spans.snapshot(),
+	}
+}
+
+// upload 上传事件包并记录摘要
+//
+// 持有锁时不能记录日志：日志钩子会再次加锁。
+func (r *FlightRecorder) upload(ctx context.Context, bundle *models.IncidentBundle) *models.IncidentSummary {
+	summary := &models.IncidentSummary{
+		ID:        bundle.ID,
+		Service:   bundle.Service,
+		Trigger:   bundle.Trigger,
+		Reason:    bundle.Reason,
+		CreatedAt: bundle.CreatedAt,
+		Bucket:    r.config.Bucket,
+		Key:       fmt.Sprintf("%s/%s-%s.json", bundle.Service, bundle.CreatedAt.Format("20060102T150405Z"), bundle.ID),
+		Status:    models.IncidentStatusStored,
+		Requests:  len(bundle.Requests),
+		Logs:      len(bundle.Logs),
+		Spans:     len(bundle.Spans),
+		SLO:       bundle.SLO,
+	}
+
+	data, err := json.Marshal(bundle)
+	if err == nil {
+		summary.Size = int64(len(data))
+		uploadCtx, cancel := context.WithTimeout(ctx, r.config.UploadTimeout)
+		err = r.store.WriteObject(uploadCtx, &models.Object{
+			Bucket:      summary.Bucket,
+			Key:         summary.Key,
+			ContentType: "application/json",
+			Size:        summary.Size,
+			Data:        data,
+		})
+		cancel()
+	}
+	if err != nil {
+		summary.Status = models.IncidentStatusFailed
+		summary.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	r.incidents = append(r.incidents, summary)
+	if len(r.incidents) > r.config.MaxIncidents {
+		r.incidents = r.incidents[len(r.incidents)-r.config.MaxIncidents:]
+	}
+	if err != nil {
+		r.failed++
+	} else {
+		r.captured++
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		r.recordCaptureMetric(ctx, bundle.Trigger, incidentResultFailed)
+		if r.logger != nil {
+			r.logger.Error(ctx, "Failed to store incident bundle",
+				observability.String("incident_id", summary.ID),
+				observability.String("trigger", summary.Trigger),
+				observability.String("reason", summary.Reason),
+				observability.Error(err))
+		}
+	} else {
+		r.recordCaptureMetric(ctx, bundle.Trigger, incidentResultStored)
+		if r.logger != nil {
+			r.logger.Warn(ctx, "Incident bundle captured",
+				observability.String("incident_id", summary.ID),
+				observability.String("trigger", summary.Trigger),
+				observability.String("reason", summary.Reason),
+				observability.String("bucket", summary.Bucket),
+				observability.String("key", summary.Key))
+		}
+	}
+
+	result := *summary
+	return &result
+}
+
+// recordCaptureMetric 记录转储指标
+func (r *FlightRecorder) recordCaptureMetric(ctx context.Context, trigger, result string) {
+	if r.collector != nil {
+		r.collector.RecordIncidentCapture(ctx, trigger, result)
+	}
+}
+
+// traceIDFromContext 获取上下文中的trace ID，不存在时返回空
+func traceIDFromContext(ctx context.Context) string {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.HasTraceID() {
+		return ""
+	}
+	return spanCtx.TraceID().String()
+}
+
+// RegisterAdminRoutes 注册事件包查询和手动抓取API，这些请求本身不记录
+func (r *FlightRecorder) RegisterAdminRoutes(group *gin.RouterGroup) {
+	r.config.ExcludePaths = append(r.config.ExcludePaths, strings.TrimSuffix(group.BasePath(), "/")+"/incidents")
+
+	incidents := group.Group("/incidents")
+	{
+		incidents.GET("", r.handleListIncidents)
+		incidents.POST("", r.handleCaptureIncident)
+		incidents.GET("/:id", r.handleGetIncident)
+	}
+}
+
+// handleListIncidents 列出事件包摘要和记录器状态
+func (r *FlightRecorder) handleListIncidents(c *gin.Context) {
+	incidents := r.ListIncidents(c.Query("trigger"))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"incidents": incidents,
+			"count":     len(incidents),
+			"recorder":  r.Stats(),
+		},
+	})
+}
+
+// handleGetIncident 获取事件包内容
+func (r *FlightRecorder) handleGetIncident(c *gin.Context) {
+	bundle, err := r.GetIncident(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, ErrIncidentNotFound) {
+			status = http.StatusNotFound
+		}
+		utils.SetErrorResponse(c.Writer, status, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    bundle,
+	})
+}
+
+// handleCaptureIncident 手动抓取事件包
+func (r *FlightRecorder) handleCaptureIncident(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+	if req.Reason == "" {
+		req.Reason = "manual capture"
+	}
+	if name := c.GetString(RBACKeyNameContextKey); name != "" {
+		req.Reason += " (by " + name + ")"
+	}
+
+	summary, err := r.Trip(c.Request.Context(), models.IncidentTriggerManual, req.Reason)
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    summary,
+		"message": "Incident bundle captured successfully",
+	})
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxLogLevelTTL 临时日志级别最长保持时间
+const maxLogLevelTTL = 24 * time.Hour
+
+// LogLevelController 运行时修改服务的日志级别，排查问题时切换到debug不需要重启
+type LogLevelController struct {
+	logger *observability.Logger
+
+	mu        sync.Mutex
+	timer     *time.Timer // 临时级别到期后恢复
+	baseLevel string      // 临时级别到期后恢复到的级别
+	revertAt  *time.Time
+}
+
+// NewLogLevelController 创建日志级别控制器
+func NewLogLevelController(logger *observability.Logger) *LogLevelController {
+	return &LogLevelController{logger: logger}
+}
+
+// LogLevelStatus 日志级别状态
+type LogLevelStatus struct {
+	Level     string     `json:"level"`
+	RevertTo  string     `json:"revert_to,omitempty"` // 临时级别到期后恢复到的级别
+	RevertAt  *time.Time `json:"revert_at,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+}
+
+// SetLevel 设置日志级别，ttl大于0时到期后恢复到修改前的级别，避免忘记恢复导致日志量长期偏高
+func (c *LogLevelController) SetLevel(ctx context.Context, level string, ttl time.Duration) *LogLevelStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous := c.logger.Level()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	} else {
+		c.baseLevel = previous
+	}
+	c.revertAt = nil
+
+	c.logger.SetLevel(level)
+	if ttl > 0 {
+		revertAt := time.Now().Add(ttl)
+		c.revertAt = &revertAt
+		c.timer = time.AfterFunc(ttl, c.revert)
+	}
+
+	c.logger.Warn(ctx, "Log level changed",
+		observability.String("previous_level", previous),
+		observability.String("log_level", level),
+		observability.Duration("ttl", ttl))
+	return c.statusLocked()
+}
+
+// revert 临时级别到期，恢复到修改前的级别
+func (c *LogLevelController) revert() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.timer = nil
+	c.revertAt = nil
+	c.logger.SetLevel(c.baseLevel)
+	c.logger.Warn(context.Background(), "Temporary log level expired",
+		observability.String("log_level", c.baseLevel))
+}
+
+// Status 返回当前日志级别
+func (c *LogLevelController) Status() *LogLevelStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.statusLocked()
+}
+
+// statusLocked 返回当前日志级别，调用方持有锁
+func (c *LogLevelController) statusLocked() *LogLevelStatus {
+	status := &LogLevelStatus{Level: c.logger.Level()}
+	if c.timer != nil {
+		status.RevertTo = c.baseLevel
+		status.RevertAt = c.revertAt
+	}
+	return status
+}
+
+// RegisterAdminRoutes 注册日志级别查询和修改API
+func (c *LogLevelController) RegisterAdminRoutes(group *gin.RouterGroup) {
+	group.GET("/loglevel", c.handleGetLevel)
+	group.PUT("/loglevel", c.handleSetLevel)
+}
+
+// handleGetLevel 查询当前日志级别
+func (c *LogLevelController) handleGetLevel(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    c.Status(),
+	})
+}
+
+// handleSetLevel 修改日志级别
+func (c *LogLevelController) handleSetLevel(ctx *gin.Context) {
+	var req struct {
+		Level   string `json:"level"`
+		TTLSecs int    `json:"ttl_secs"` // 大于0时到期后恢复
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.SetErrorResponse(ctx.Writer, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if !observability.IsValidLogLevel(req.Level) {
+		utils.SetErrorResponse(ctx.Writer, http.StatusBadRequest, "Invalid log level: must be debug, info, warn or error")
+		return
+	}
+	ttl := time.Duration(req.TTLSecs) * time.Second
+	if ttl < 0 || ttl > maxLogLevelTTL {
+		utils.SetErrorResponse(ctx.Writer, http.StatusBadRequest, "Invalid ttl_secs: must be between 0 and 86400")
+		return
+	}
+
+	status := c.SetLevel(ctx.Request.Context(), req.Level, ttl)
+	status.UpdatedBy = ctx.GetString(RBACKeyNameContextKey)
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
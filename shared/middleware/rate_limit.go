@@ -0,0 +1,722 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"mocks3/shared/interfaces"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 限流拒绝原因
+const (
+	RateLimitReasonRate        = "rate"
+	RateLimitReasonConcurrency = "concurrency"
+)
+
+// 限流决策结果（指标标签）
+const (
+	rateLimitResultAllowed             = "allowed"
+	rateLimitResultThrottled           = "throttled"
+	rateLimitResultConcurrencyRejected = "concurrency_rejected"
+)
+
+// RateLimitConfig 限流中间件配置
+type RateLimitConfig struct {
+	DefaultPlan   *models.RatePlan // 未分配套餐的租户使用的套餐
+	TenantHeaders []string         // 按顺序读取租户标识的请求头，均为空时使用客户端IP
+	APIKeyHeader  string           // TenantHeaders中携带API key的请求头，租户标识为key名称或key的哈希，不使用key本身
+	ExcludePaths  []string         // 不限流的路径前缀
+	IdleTTL       time.Duration    // 超过该时间没有请求的租户状态被清理，0表示不清理
+	MaxTenants    int              // 最多保留的租户状态数，达到上限时清理最久没有请求的空闲租户，0表示不限制
+}
+
+// DefaultRateLimitConfig 默认限流配置
+func DefaultRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		DefaultPlan: &models.RatePlan{
+			Name:              "default",
+			RequestsPerSecond: 100,
+			Burst:             200,
+		},
+		TenantHeaders: []string{"X-Api-Key", "X-Tenant-Id"},
+		APIKeyHeader:  "X-Api-Key",
+		ExcludePaths:  []string{"/health", "/api/v1/admin"},
+		IdleTTL:       10 * time.Minute,
+		MaxTenants:    10000,
+	}
+}
+
+// tenantState 租户令牌桶及使用统计
+type tenantState struct {
+	plan       string
+	tokens     float64
+	lastRefill time.Time
+	lastSeen   time.Time // 最后一次请求的时间，用于清理空闲租户
+	usage      models.RatePlanUsage
+}
+
+// ErrRatePlanNotFound 套餐或套餐分配不存在
+var ErrRatePlanNotFound = errors.New("rate plan not found")
+
+// RateLimiter 基于命名套餐的令牌桶限流器
+type RateLimiter struct {
+	config      *RateLimitConfig
+	plans       map[string]*models.RatePlan
+	assignments map[string]string // tenant -> plan
+	tenants     map[string]*tenantState
+	lastSweep   time.Time
+	authorizer  *Authorizer
+	collector   *observability.MetricCollector
+	mu          sync.Mutex
+}
+
+// NewRateLimiter 创建限流器
+func NewRateLimiter(config *RateLimitConfig) (*RateLimiter, error) {
+	if config == nil {
+		config = DefaultRateLimitConfig()
+	}
+	if config.DefaultPlan == nil {
+		return nil, fmt.Errorf("default rate plan is required")
+	}
+
+	limiter := &RateLimiter{
+		config:      config,
+		plans:       make(map[string]*models.RatePlan),
+		assignments: make(map[string]string),
+		tenants:     make(map[string]*tenantState),
+	}
+
+	if _, err := limiter.SetPlan(config.DefaultPlan); err != nil {
+		return nil, fmt.Errorf("invalid default rate plan: %w", err)
+	}
+
+	return limiter, nil
+}
+
+// SetMetricCollector 设置指标收集器
+func (l *RateLimiter) SetMetricCollector(collector *observability.MetricCollector) {
+	l.collector = collector
+}
+
+// SetAuthorizer 设置鉴权器，有效的API key按key名称限流
+func (l *RateLimiter) SetAuthorizer(authorizer *Authorizer) {
+	l.authorizer = authorizer
+}
+
+// SetPlan 创建或更新套餐
+func (l *RateLimiter) SetPlan(plan *models.RatePlan) (*models.RatePlan, error) {
+	if err := validateRatePlan(plan); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	stored := *plan
+	if stored.Burst <= 0 {
+		stored.Burst = int(math.Ceil(stored.RequestsPerSecond))
+	}
+	stored.UpdatedAt = now
+	if existing, ok := l.plans[plan.Name]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	} else {
+		stored.CreatedAt = now
+	}
+	l.plans[plan.Name] = &stored
+
+	// 套餐变更后重置使用该套餐的令牌桶
+	for _, state := range l.tenants {
+		if state.plan == plan.Name {
+			state.tokens = float64(stored.Burst)
+			state.lastRefill = now
+		}
+	}
+
+	result := stored
+	return &result, nil
+}
+
+// GetPlan 获取套餐
+func (l *RateLimiter) GetPlan(name string) (*models.RatePlan, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	plan, ok := l.plans[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRatePlanNotFound, name)
+	}
+	result := *plan
+	return &result, nil
+}
+
+// ListPlans 列出所有套餐
+func (l *RateLimiter) ListPlans() []*models.RatePlan {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	plans := make([]*models.RatePlan, 0, len(l.plans))
+	for _, plan := range l.plans {
+		result := *plan
+		plans = append(plans, &result)
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].Name < plans[j].Name })
+	return plans
+}
+
+// DeletePlan 删除套餐（默认套餐和仍被分配的套餐不可删除）
+func (l *RateLimiter) DeletePlan(name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.plans[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrRatePlanNotFound, name)
+	}
+	if name == l.config.DefaultPlan.Name {
+		return fmt.Errorf("rate plan in use: %s is the default plan", name)
+	}
+	for tenant, plan := range l.assignments {
+		if plan == name {
+			return fmt.Errorf("rate plan in use: %s is assigned to %s", name, tenant)
+		}
+	}
+
+	delete(l.plans, name)
+	return nil
+}
+
+// AssignPlan 为租户分配套餐，API key使用resolveTenant得到的标识（apikey:<名称>）
+func (l *RateLimiter) AssignPlan(tenant, planName string) error {
+	if tenant == "" {
+		return fmt.Errorf("invalid tenant: tenant is required")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.plans[planName]; !ok {
+		return fmt.Errorf("%w: %s", ErrRatePlanNotFound, planName)
+	}
+	l.assignments[tenant] = planName
+	return nil
+}
+
+// UnassignPlan 取消租户的套餐分配（回退到默认套餐）
+func (l *RateLimiter) UnassignPlan(tenant string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.assignments[tenant]; !ok {
+		return fmt.Errorf("%w: no assignment for %s", ErrRatePlanNotFound, tenant)
+	}
+	delete(l.assignments, tenant)
+	return nil
+}
+
+// ListAssignments 列出套餐分配
+func (l *RateLimiter) ListAssignments() map[string]string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make(map[string]string, len(l.assignments))
+	for tenant, plan := range l.assignments {
+		result[tenant] = plan
+	}
+	return result
+}
+
+// GetUsage 获取租户使用情况，tenant为空时返回全部
+func (l *RateLimiter) GetUsage(tenant string) []*models.RatePlanUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	usages := make([]*models.RatePlanUsage, 0, len(l.tenants))
+	for name, state := range l.tenants {
+		if tenant != "" && name != tenant {
+			continue
+		}
+		l.refillLocked(state, l.planLocked(name), now)
+		usage := state.usage
+		usage.Remaining = int64(math.Floor(state.tokens))
+		usages = append(usages, &usage)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Tenant < usages[j].Tenant })
+	return usages
+}
+
+// Allow 检查租户是否允许请求（消耗一个令牌，不占用并发）
+func (l *RateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	decision := l.acquire(key, false)
+	l.record(ctx, decision)
+	return decision.allowed, nil
+}
+
+// SetLimit 为租户设置自定义限额：window秒内最多limit次
+func (l *RateLimiter) SetLimit(ctx context.Context, key string, limit int64, window int64) error {
+	if limit <= 0 || window <= 0 {
+		return fmt.Errorf("invalid limit: limit and window must be positive")
+	}
+
+	plan := &models.RatePlan{
+		Name:              "custom:" + key,
+		RequestsPerSecond: float64(limit) / float64(window),
+		Burst:             int(limit),
+	}
+	if _, err := l.SetPlan(plan); err != nil {
+		return err
+	}
+	return l.AssignPlan(key, plan.Name)
+}
+
+// GetLimit 获取租户当前限额
+func (l *RateLimiter) GetLimit(ctx context.Context, key string) (*models.RateLimit, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	plan := l.planLocked(key)
+	state := l.stateLocked(key, plan)
+	now := time.Now()
+	l.refillLocked(state, plan, now)
+
+	limit := &models.RateLimit{
+		Key:       key,
+		Limit:     int64(plan.Burst),
+		Remaining: int64(math.Floor(state.tokens)),
+		ResetTime: now,
+		CreatedAt: plan.CreatedAt,
+		UpdatedAt: plan.UpdatedAt,
+	}
+	if plan.RequestsPerSecond > 0 {
+		limit.Window = time.Duration(float64(plan.Burst) / plan.RequestsPerSecond * float64(time.Second))
+		limit.ResetTime = now.Add(time.Duration((float64(plan.Burst) - state.tokens) / plan.RequestsPerSecond * float64(time.Second)))
+	}
+	return limit, nil
+}
+
+// RemoveLimit 移除租户的套餐分配
+func (l *RateLimiter) RemoveLimit(ctx context.Context, key string) error {
+	return l.UnassignPlan(key)
+}
+
+// GinMiddleware 返回Gin限流中间件
+func (l *RateLimiter) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, prefix := range l.config.ExcludePaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		tenant := l.resolveTenant(c)
+		decision := l.acquire(tenant, true)
+		l.record(c.Request.Context(), decision)
+
+		c.Header("X-RateLimit-Plan", decision.plan.Name)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(decision.plan.Burst))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(decision.remaining, 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(decision.resetAt.Unix(), 10))
+
+		if !decision.allowed {
+			retryAfter := int(math.Ceil(time.Until(decision.resetAt).Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+			message := "Request rate exceeds plan limit"
+			if decision.reason == RateLimitReasonConcurrency {
+				message = "Concurrent requests exceed plan limit"
+			}
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, &models.RateLimitExceeded{
+				Error:             message,
+				Code:              "SlowDown",
+				Reason:            decision.reason,
+				Tenant:            tenant,
+				Plan:              decision.plan.Name,
+				RequestsPerSecond: decision.plan.RequestsPerSecond,
+				Burst:             decision.plan.Burst,
+				MaxConcurrent:     decision.plan.MaxConcurrent,
+				Remaining:         decision.remaining,
+				ResetAt:           decision.resetAt,
+				RetryAfterSeconds: retryAfter,
+			})
+			return
+		}
+
+		defer l.release(tenant)
+		c.Next()
+	}
+}
+
+// RegisterAdminRoutes 注册套餐管理API
+func (l *RateLimiter) RegisterAdminRoutes(group *gin.RouterGroup) {
+	rl := group.Group("/rate-limits")
+	{
+		rl.GET("/plans", l.handleListPlans)
+		rl.GET("/plans/:name", l.handleGetPlan)
+		rl.PUT("/plans/:name", l.handleSetPlan)
+		rl.DELETE("/plans/:name", l.handleDeletePlan)
+
+		rl.GET("/assignments", l.handleListAssignments)
+		rl.PUT("/assignments/:tenant", l.handleAssignPlan)
+		rl.DELETE("/assignments/:tenant", l.handleUnassignPlan)
+
+		rl.GET("/usage", l.handleGetUsage)
+	}
+}
+
+// rateLimitDecision 单次限流决策
+type rateLimitDecision struct {
+	tenant    string
+	plan      models.RatePlan
+	allowed   bool
+	reason    string
+	remaining int64
+	resetAt   time.Time
+}
+
+// acquire 消耗令牌，trackConcurrency为true时同时占用并发槽位
+func (l *RateLimiter) acquire(tenant string, trackConcurrency bool) *rateLimitDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweepLocked(now)
+	plan := l.planLocked(tenant)
+	state := l.stateLocked(tenant, plan)
+	l.refillLocked(state, plan, now)
+
+	decision := &rateLimitDecision{tenant: tenant, plan: *plan, allowed: true, resetAt: now}
+	state.lastSeen = now
+	state.usage.LastRequestAt = &now
+
+	switch {
+	case trackConcurrency && plan.MaxConcurrent > 0 && state.usage.InFlight >= plan.MaxConcurrent:
+		decision.allowed = false
+		decision.reason = RateLimitReasonConcurrency
+		decision.resetAt = now.Add(time.Second)
+		state.usage.ConcurrencyRejected++
+	case plan.RequestsPerSecond > 0 && state.tokens < 1:
+		decision.allowed = false
+		decision.reason = RateLimitReasonRate
+		decision.resetAt = now.Add(time.Duration((1 - state.tokens) / plan.RequestsPerSecond * float64(time.Second)))
+		state.usage.Throttled++
+	default:
+		if plan.RequestsPerSecond > 0 {
+			state.tokens--
+			decision.resetAt = now.Add(time.Duration((float64(plan.Burst) - state.tokens) / plan.RequestsPerSecond * float64(time.Second)))
+		}
+		if trackConcurrency {
+			state.usage.InFlight++
+		}
+		state.usage.Allowed++
+	}
+
+	decision.remaining = int64(math.Floor(state.tokens))
+	if decision.remaining < 0 {
+		decision.remaining = 0
+	}
+	return decision
+}
+
+// release 释放并发槽位
+func (l *RateLimiter) release(tenant string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if state, ok := l.tenants[tenant]; ok && state.usage.InFlight > 0 {
+		state.usage.InFlight--
+	}
+}
+
+// record 记录限流指标
+func (l *RateLimiter) record(ctx context.Context, decision *rateLimitDecision) {
+	if l.collector == nil {
+		return
+	}
+
+	result := rateLimitResultAllowed
+	switch decision.reason {
+	case RateLimitReasonRate:
+		result = rateLimitResultThrottled
+	case RateLimitReasonConcurrency:
+		result = rateLimitResultConcurrencyRejected
+	}
+	l.collector.RecordRateLimitDecision(ctx, decision.tenant, decision.plan.Name, result)
+}
+
+// planLocked 获取租户套餐（调用方需持有锁）
+func (l *RateLimiter) planLocked(tenant string) *models.RatePlan {
+	if name, ok := l.assignments[tenant]; ok {
+		if plan, ok := l.plans[name]; ok {
+			return plan
+		}
+	}
+	return l.plans[l.config.DefaultPlan.Name]
+}
+
+// stateLocked 获取或创建租户状态（调用方需持有锁）
+func (l *RateLimiter) stateLocked(tenant string, plan *models.RatePlan) *tenantState {
+	state, ok := l.tenants[tenant]
+	if !ok {
+		now := time.Now()
+		if l.config.MaxTenants > 0 && len(l.tenants) >= l.config.MaxTenants {
+			l.evictOldestLocked()
+		}
+		state = &tenantState{
+			plan:       plan.Name,
+			tokens:     float64(plan.Burst),
+			lastRefill: now,
+			lastSeen:   now,
+			usage:      models.RatePlanUsage{Tenant: tenant, Plan: plan.Name},
+		}
+		l.tenants[tenant] = state
+	}
+	return state
+}
+
+// sweepLocked 每隔IdleTTL的一半清理一次超过IdleTTL没有请求的空闲租户（调用方需持有锁）
+//
+// 空闲时间超过令牌桶填满所需的时间后，重新创建的令牌桶与原来的一致，使用统计从零开始。
+func (l *RateLimiter) sweepLocked(now time.Time) {
+	if l.config.IdleTTL <= 0 || now.Sub(l.lastSweep) < l.config.IdleTTL/2 {
+		return
+	}
+	l.lastSweep = now
+
+	for tenant, state := range l.tenants {
+		if state.usage.InFlight == 0 && now.Sub(state.lastSeen) >= l.config.IdleTTL {
+			delete(l.tenants, tenant)
+		}
+	}
+}
+
+// evictOldestLocked 清理最久没有请求的空闲租户，所有租户都有进行中的请求时不清理（调用方需持有锁）
+func (l *RateLimiter) evictOldestLocked() {
+	var oldest string
+	var oldestSeen time.Time
+	for tenant, state := range l.tenants {
+		if state.usage.InFlight > 0 {
+			continue
+		}
+		if oldest == "" || state.lastSeen.Before(oldestSeen) {
+			oldest, oldestSeen = tenant, state.lastSeen
+		}
+	}
+	if oldest != "" {
+		delete(l.tenants, oldest)
+	}
+}
+
+// refillLocked 按时间补充令牌，套餐切换时重置令牌桶（调用方需持有锁）
+func (l *RateLimiter) refillLocked(state *tenantState, plan *models.RatePlan, now time.Time) {
+	if state.plan != plan.Name {
+		state.plan = plan.Name
+		state.usage.Plan = plan.Name
+		state.tokens = float64(plan.Burst)
+		state.lastRefill = now
+		return
+	}
+
+	// 新建的令牌桶的lastRefill可能晚于调用方取得的now
+	elapsed := max(now.Sub(state.lastRefill).Seconds(), 0)
+	state.tokens = math.Min(float64(plan.Burst), state.tokens+elapsed*plan.RequestsPerSecond)
+	state.lastRefill = now
+}
+
+// resolveTenant 从请求中解析租户标识
+//
+// API key不作为租户标识：租户标识会出现在429响应、使用情况和指标中。
+func (l *RateLimiter) resolveTenant(c *gin.Context) string {
+	for _, header := range l.config.TenantHeaders {
+		value := c.GetHeader(header)
+		if value == "" {
+			continue
+		}
+		if strings.EqualFold(header, l.config.APIKeyHeader) {
+			return l.apiKeyTenant(value)
+		}
+		return value
+	}
+	return c.ClientIP()
+}
+
+// apiKeyTenant API key对应的租户标识：有效的key为apikey:<名称>，未知的key为apikey-sha256:<哈希前缀>
+func (l *RateLimiter) apiKeyTenant(key string) string {
+	if l.authorizer != nil {
+		if apiKey, ok := l.authorizer.Authenticate(key); ok {
+			return "apikey:" + apiKey.Name
+		}
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "apikey-sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// validateRatePlan 验证套餐
+func validateRatePlan(plan *models.RatePlan) error {
+	if plan == nil || plan.Name == "" {
+		return fmt.Errorf("invalid rate plan: name is required")
+	}
+	if plan.RequestsPerSecond < 0 {
+		return fmt.Errorf("invalid rate plan: requests_per_second must not be negative")
+	}
+	if plan.Burst < 0 {
+		return fmt.Errorf("invalid rate plan: burst must not be negative")
+	}
+	if plan.MaxConcurrent < 0 {
+		return fmt.Errorf("invalid rate plan: max_concurrent must not be negative")
+	}
+	return nil
+}
+
+// handleListPlans 列出套餐
+func (l *RateLimiter) handleListPlans(c *gin.Context) {
+	plans := l.ListPlans()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"plans":        plans,
+			"count":        len(plans),
+			"default_plan": l.config.DefaultPlan.Name,
+		},
+	})
+}
+
+// handleGetPlan 获取套餐
+func (l *RateLimiter) handleGetPlan(c *gin.Context) {
+	plan, err := l.GetPlan(c.Param("name"))
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    plan,
+	})
+}
+
+// handleSetPlan 创建或更新套餐
+func (l *RateLimiter) handleSetPlan(c *gin.Context) {
+	var plan models.RatePlan
+	if err := c.ShouldBindJSON(&plan); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	plan.Name = c.Param("name")
+
+	stored, err := l.SetPlan(&plan)
+	if err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stored,
+		"message": "Rate plan saved successfully",
+	})
+}
+
+// handleDeletePlan 删除套餐
+func (l *RateLimiter) handleDeletePlan(c *gin.Context) {
+	if err := l.DeletePlan(c.Param("name")); err != nil {
+		status := http.StatusConflict
+		if errors.Is(err, ErrRatePlanNotFound) {
+			status = http.StatusNotFound
+		}
+		utils.SetErrorResponse(c.Writer, status, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Rate plan deleted successfully",
+	})
+}
+
+// handleListAssignments 列出套餐分配
+func (l *RateLimiter) handleListAssignments(c *gin.Context) {
+	assignments := l.ListAssignments()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"assignments": assignments,
+			"count":       len(assignments),
+		},
+	})
+}
+
+// handleAssignPlan 为租户分配套餐
+func (l *RateLimiter) handleAssignPlan(c *gin.Context) {
+	var req struct {
+		Plan string `json:"plan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	tenant := c.Param("tenant")
+	if err := l.AssignPlan(tenant, req.Plan); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrRatePlanNotFound) {
+			status = http.StatusNotFound
+		}
+		utils.SetErrorResponse(c.Writer, status, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"tenant": tenant,
+			"plan":   req.Plan,
+		},
+		"message": "Rate plan assigned successfully",
+	})
+}
+
+// handleUnassignPlan 取消租户的套餐分配
+func (l *RateLimiter) handleUnassignPlan(c *gin.Context) {
+	if err := l.UnassignPlan(c.Param("tenant")); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Rate plan assignment removed successfully",
+	})
+}
+
+// handleGetUsage 获取套餐使用情况
+func (l *RateLimiter) handleGetUsage(c *gin.Context) {
+	usage := l.GetUsage(c.Query("tenant"))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"usage": usage,
+			"count": len(usage),
+		},
+	})
+}
+
+// 确保实现了接口
+var _ interfaces.RateLimiter = (*RateLimiter)(nil)
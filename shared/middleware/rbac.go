@@ -0,0 +1,374 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/utils"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gin上下文中保存鉴权结果的键
+const (
+	RBACRoleContextKey    = "rbac_role"
+	RBACKeyNameContextKey = "rbac_key_name"
+)
+
+// apiKeyPrefixLength 列表中展示的key前缀长度
+const apiKeyPrefixLength = 8
+
+// RBACRule 路径前缀所需的能力，读请求（GET/HEAD/OPTIONS）使用ReadCapability，其他方法使用WriteCapability
+type RBACRule struct {
+	PathPrefix      string
+	ReadCapability  string
+	WriteCapability string
+}
+
+// RBACConfig 鉴权中间件配置
+type RBACConfig struct {
+	Header string           // 读取API key的请求头
+	Rules  []*RBACRule      // 受保护的路径，按最长前缀匹配；未匹配的路径不鉴权
+	Keys   []*models.APIKey // 初始API key
+}
+
+// DefaultRBACConfig 默认鉴权配置，保护 /admin 和 /api/v1/admin 下的全部接口
+func DefaultRBACConfig() *RBACConfig {
+	return &RBACConfig{
+		Header: "X-Api-Key",
+		Rules: []*RBACRule{
+			{PathPrefix: "/admin", ReadCapability: models.CapabilityAdminRead, WriteCapability: models.CapabilityAdminWrite},
+			{PathPrefix: "/api/v1/admin", ReadCapability: models.CapabilityAdminRead, WriteCapability: models.CapabilityAdminWrite},
+		},
+	}
+}
+
+// ErrAPIKeyExists 同名或相同key的API key已存在
+var ErrAPIKeyExists = errors.New("api key already exists")
+
+// Authorizer 基于API key和角色的管理接口鉴权，同时提供API key管理
+type Authorizer struct {
+	config *RBACConfig
+	mu     sync.RWMutex
+	rules  []*RBACRule
+	keys   map[string]*models.APIKey // key -> API key
+}
+
+// NewAuthorizer 创建鉴权器
+func NewAuthorizer(config *RBACConfig) (*Authorizer, error) {
+	if config == nil {
+		config = DefaultRBACConfig()
+	}
+	if config.Header == "" {
+		return nil, fmt.Errorf("api key header is required")
+	}
+
+	a := &Authorizer{
+		config: config,
+		keys:   make(map[string]*models.APIKey),
+	}
+	for _, rule := range config.Rules {
+		a.AddRule(rule)
+	}
+	for _, key := range config.Keys {
+		if _, err := a.CreateKey(key.Name, key.Role, key.Tenant, key.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// AddRule 添加受保护的路径
+func (a *Authorizer) AddRule(rule *RBACRule) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rules = append(a.rules, rule)
+	sort.SliceStable(a.rules, func(i, j int) bool {
+		return len(a.rules[i].PathPrefix) > len(a.rules[j].PathPrefix)
+	})
+}
+
+// CreateKey 创建API key，key为空时随机生成，tenant为空时不绑定租户；返回值包含完整key
+func (a *Authorizer) CreateKey(name, role, tenant, key string) (*models.APIKey, error) {
+	if name == "" {
+		return nil, fmt.Errorf("invalid api key: name is required")
+	}
+	if !models.IsValidRole(role) {
+		return nil, fmt.Errorf("invalid api key: unknown role %q, must be one of %s", role, strings.Join(models.Roles, ", "))
+	}
+	if tenant != "" {
+		if err := models.ValidateTenantID(tenant); err != nil {
+			return nil, fmt.Errorf("invalid api key: %w", err)
+		}
+	}
+	if key == "" {
+		key = generateAPIKey()
+	}
+	if len(key) < apiKeyPrefixLength {
+		return nil, fmt.Errorf("invalid api key: key must be at least %d characters", apiKeyPrefixLength)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for existingKey, existing := range a.keys {
+		if existing.Name == name {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyExists, name)
+		}
+		if existingKey == key {
+			return nil, fmt.Errorf("%w: key is used by %s", ErrAPIKeyExists, existing.Name)
+		}
+	}
+
+	apiKey := &models.APIKey{
+		Name:      name,
+		KeyPrefix: key[:apiKeyPrefixLength],
+		Role:      role,
+		Tenant:    tenant,
+		CreatedAt: time.Now(),
+	}
+	a.keys[key] = apiKey
+
+	result := *apiKey
+	result.Key = key
+	return &result, nil
+}
+
+// DeleteKey 按名称删除API key
+func (a *Authorizer) DeleteKey(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for key, apiKey := range a.keys {
+		if apiKey.Name == name {
+			delete(a.keys, key)
+			return nil
+		}
+	}
+	return fmt.Errorf("api key not found: %s", name)
+}
+
+// ListKeys 列出API key（不含完整key）
+func (a *Authorizer) ListKeys() []*models.APIKey {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	keys := make([]*models.APIKey, 0, len(a.keys))
+	for _, apiKey := range a.keys {
+		result := *apiKey
+		keys = append(keys, &result)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys
+}
+
+// Authenticate 根据key查找API key
+func (a *Authorizer) Authenticate(key string) (*models.APIKey, bool) {
+	if key == "" {
+		return nil, false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	apiKey, ok := a.keys[key]
+	if !ok {
+		return nil, false
+	}
+	result := *apiKey
+	return &result, true
+}
+
+// RequiredCapability 获取请求所需的能力，第二个返回值为false表示路径不受保护
+func (a *Authorizer) RequiredCapability(method, path string) (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, rule := range a.rules {
+		if path != rule.PathPrefix && !strings.HasPrefix(path, strings.TrimSuffix(rule.PathPrefix, "/")+"/") {
+			continue
+		}
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return rule.ReadCapability, true
+		default:
+			return rule.WriteCapability, true
+		}
+	}
+	return "", false
+}
+
+// GinMiddleware 返回Gin鉴权中间件：缺少或无效的key返回401，角色能力不足返回403
+func (a *Authorizer) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		capability, protected := a.RequiredCapability(c.Request.Method, path)
+		if !protected {
+			c.Next()
+			return
+		}
+
+		apiKey, ok := a.Authenticate(c.GetHeader(a.config.Header))
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, &models.AccessDenied{
+				Error:              "Missing or invalid API key in " + a.config.Header + " header",
+				Code:               "Unauthorized",
+				RequiredCapability: capability,
+				Method:             c.Request.Method,
+				Path:               path,
+			})
+			return
+		}
+
+		if !models.RoleHasCapability(apiKey.Role, capability) {
+			c.AbortWithStatusJSON(http.StatusForbidden, &models.AccessDenied{
+				Error:              fmt.Sprintf("Role %s does not have capability %s", apiKey.Role, capability),
+				Code:               "AccessDenied",
+				Role:               apiKey.Role,
+				RequiredCapability: capability,
+				Method:             c.Request.Method,
+				Path:               path,
+			})
+			return
+		}
+
+		c.Set(RBACRoleContextKey, apiKey.Role)
+		c.Set(RBACKeyNameContextKey, apiKey.Name)
+		c.Next()
+	}
+}
+
+// RegisterAdminRoutes 注册角色能力查询和API key管理API
+//
+// 角色列表和当前身份只需有效的API key，API key管理需要keys:manage能力。
+func (a *Authorizer) RegisterAdminRoutes(group *gin.RouterGroup) {
+	base := strings.TrimSuffix(group.BasePath(), "/") + "/rbac"
+	a.AddRule(&RBACRule{PathPrefix: base, ReadCapability: models.CapabilityAuthenticated, WriteCapability: models.CapabilityKeysManage})
+	a.AddRule(&RBACRule{PathPrefix: base + "/keys", ReadCapability: models.CapabilityKeysManage, WriteCapability: models.CapabilityKeysManage})
+
+	rbac := group.Group("/rbac")
+	{
+		rbac.GET("/roles", a.handleListRoles)
+		rbac.GET("/whoami", a.handleWhoAmI)
+
+		rbac.GET("/keys", a.handleListKeys)
+		rbac.POST("/keys", a.handleCreateKey)
+		rbac.DELETE("/keys/:name", a.handleDeleteKey)
+	}
+}
+
+// ListRoles 列出角色及其能力
+func ListRoles() []*models.RoleInfo {
+	roles := make([]*models.RoleInfo, 0, len(models.Roles))
+	for _, role := range models.Roles {
+		roles = append(roles, &models.RoleInfo{
+			Role:         role,
+			Capabilities: append([]string(nil), models.RoleCapabilities[role]...),
+		})
+	}
+	return roles
+}
+
+// generateAPIKey 生成随机API key
+func generateAPIKey() string {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return utils.NewID()
+	}
+	return "mk_" + hex.EncodeToString(buf)
+}
+
+// handleListRoles 列出角色及其能力
+func (a *Authorizer) handleListRoles(c *gin.Context) {
+	roles := ListRoles()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"roles": roles,
+			"count": len(roles),
+		},
+	})
+}
+
+// handleWhoAmI 获取当前API key的角色和能力
+func (a *Authorizer) handleWhoAmI(c *gin.Context) {
+	role := c.GetString(RBACRoleContextKey)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"name":         c.GetString(RBACKeyNameContextKey),
+			"role":         role,
+			"capabilities": models.RoleCapabilities[role],
+		},
+	})
+}
+
+// handleListKeys 列出API key
+func (a *Authorizer) handleListKeys(c *gin.Context) {
+	keys := a.ListKeys()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"keys":  keys,
+			"count": len(keys),
+		},
+	})
+}
+
+// handleCreateKey 创建API key
+func (a *Authorizer) handleCreateKey(c *gin.Context) {
+	var req struct {
+		Name   string `json:"name" binding:"required"`
+		Role   string `json:"role" binding:"required"`
+		Tenant string `json:"tenant"`
+		Key    string `json:"key"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	apiKey, err := a.CreateKey(req.Name, req.Role, req.Tenant, req.Key)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrAPIKeyExists) {
+			status = http.StatusConflict
+		}
+		utils.SetErrorResponse(c.Writer, status, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    apiKey,
+		"message": "API key created successfully",
+	})
+}
+
+// handleDeleteKey 删除API key
+func (a *Authorizer) handleDeleteKey(c *gin.Context) {
+	name := c.Param("name")
+	if name == c.GetString(RBACKeyNameContextKey) {
+		utils.SetErrorResponse(c.Writer, http.StatusConflict, "Cannot delete the API key used by this request")
+		return
+	}
+
+	if err := a.DeleteKey(name); err != nil {
+		utils.SetErrorResponse(c.Writer, http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "API key deleted successfully",
+	})
+}
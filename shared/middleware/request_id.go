@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"mocks3/shared/models"
+	"mocks3/shared/utils"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDContextKey gin.Context中保存请求ID的键
+const RequestIDContextKey = "request_id"
+
+// RequestIDMiddleware 返回Gin请求ID中间件：沿用上游传入的X-Request-ID，没有或无效时生成新ID，
+// 写入请求context（日志和出站调用自动带上）并在响应头中返回
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := resolveRequestID(c.GetHeader(models.HeaderRequestID))
+		c.Set(RequestIDContextKey, id)
+		c.Header(models.HeaderRequestID, id)
+		c.Request = c.Request.WithContext(models.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// RequestIDUnaryServerInterceptor 返回gRPC请求ID拦截器，请求ID从同名（小写）的gRPC metadata读取
+func RequestIDUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var upstream string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(strings.ToLower(models.HeaderRequestID)); len(values) > 0 {
+				upstream = values[0]
+			}
+		}
+		return handler(models.WithRequestID(ctx, resolveRequestID(upstream)), req)
+	}
+}
+
+// resolveRequestID 上游请求ID有效时沿用，否则生成新ID
+func resolveRequestID(upstream string) string {
+	upstream = strings.TrimSpace(upstream)
+	if models.IsValidRequestID(upstream) {
+		return upstream
+	}
+	return utils.NewID()
+}
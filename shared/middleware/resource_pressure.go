@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"mocks3/shared/models"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// pressureChunkSize 内存压力按块分配，每块写入每个页面使内存实际驻留
+const pressureChunkSize = 1 << 20
+
+// 进程内同一类资源压力同时只运行一个，持续期间再次触发的动作被忽略，避免压力随请求数叠加
+var (
+	cpuPressureActive    atomic.Bool
+	memoryPressureActive atomic.Bool
+)
+
+// cpuPressureSink 保存计算结果，避免忙循环被编译器优化掉
+var cpuPressureSink atomic.Uint64
+
+// startResourcePressure 在后台按动作占用CPU或分配内存，持续时间结束后释放，返回是否启动了新的压力
+func startResourcePressure(action *models.ErrorAction) bool {
+	if action.Duration == nil || *action.Duration <= 0 {
+		return false
+	}
+	deadline := time.Now().Add(*action.Duration)
+
+	switch action.Type {
+	case models.ErrorActionTypeCPUPressure:
+		if !cpuPressureActive.CompareAndSwap(false, true) {
+			return false
+		}
+		go burnCPU(action.CPUCores, deadline)
+		return true
+	case models.ErrorActionTypeMemoryPressure:
+		if action.MemoryBytes <= 0 || !memoryPressureActive.CompareAndSwap(false, true) {
+			return false
+		}
+		go holdMemory(action.MemoryBytes, deadline)
+		return true
+	default:
+		return false
+	}
+}
+
+// burnCPU 在cores个goroutine中忙循环到deadline
+func burnCPU(cores int, deadline time.Time) {
+	defer cpuPressureActive.Store(false)
+
+	cores = min(max(cores, 1), runtime.GOMAXPROCS(0))
+	done := make(chan struct{}, cores)
+	for i := 0; i < cores; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			var x uint64 = 1
+			for time.Now().Before(deadline) {
+				for j := 0; j < 100000; j++ {
+					x = x*6364136223846793005 + 1442695040888963407
+				}
+			}
+			cpuPressureSink.Add(x)
+		}()
+	}
+	for i := 0; i < cores; i++ {
+		<-done
+	}
+}
+
+// holdMemory 分配size字节并保持到deadline，之后归还给操作系统
+func holdMemory(size int64, deadline time.Time) {
+	defer memoryPressureActive.Store(false)
+
+	chunks := make([][]byte, 0, size/pressureChunkSize+1)
+	for remaining := size; remaining > 0; remaining -= pressureChunkSize {
+		chunk := make([]byte, min(remaining, pressureChunkSize))
+		for i := 0; i < len(chunk); i += 4096 {
+			chunk[i] = 1
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	time.Sleep(time.Until(deadline))
+	runtime.KeepAlive(chunks)
+	debug.FreeOSMemory()
+}
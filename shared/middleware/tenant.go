@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"mocks3/shared/models"
+	"mocks3/shared/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TenantContextKey Gin上下文中保存请求租户的键
+const TenantContextKey = "tenant"
+
+// TenantConfig 租户解析中间件配置
+type TenantConfig struct {
+	Header        string   // 读取租户的请求头
+	DefaultTenant string   // 未指定租户时使用的租户
+	Required      bool     // 要求请求通过API key绑定或请求头显式指定租户，否则返回400
+	ExcludePaths  []string // 不要求显式租户的路径前缀（gRPC为完整方法名），这些请求使用默认租户
+}
+
+// DefaultTenantConfig 默认租户解析配置
+func DefaultTenantConfig() *TenantConfig {
+	return &TenantConfig{
+		Header:        models.HeaderTenantID,
+		DefaultTenant: models.DefaultTenant,
+		ExcludePaths:  []string{"/health", "/metrics", "/grpc.health.v1.Health/"},
+	}
+}
+
+// TenantResolver 解析请求所属租户并写入请求context
+//
+// API key绑定了租户时以绑定的租户为准，请求头指定其他租户返回403；
+// 否则使用请求头中的租户，均未指定时使用默认租户。
+type TenantResolver struct {
+	config     *TenantConfig
+	authorizer *Authorizer
+}
+
+// NewTenantResolver 创建租户解析器，authorizer为nil时只从请求头读取租户
+func NewTenantResolver(config *TenantConfig, authorizer *Authorizer) (*TenantResolver, error) {
+	if config == nil {
+		config = DefaultTenantConfig()
+	}
+	if config.Header == "" {
+		return nil, fmt.Errorf("tenant header is required")
+	}
+	if config.DefaultTenant == "" {
+		config.DefaultTenant = models.DefaultTenant
+	}
+	if err := models.ValidateTenantID(config.DefaultTenant); err != nil {
+		return nil, fmt.Errorf("invalid default tenant: %w", err)
+	}
+
+	return &TenantResolver{
+		config:     config,
+		authorizer: authorizer,
+	}, nil
+}
+
+// GinMiddleware 返回Gin租户解析中间件
+func (r *TenantResolver) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var key string
+		if r.authorizer != nil {
+			key = c.GetHeader(r.authorizer.config.Header)
+		}
+
+		tenant, code, err := r.resolve(c.GetHeader(r.config.Header), key, c.Request.URL.Path)
+		if err != nil {
+			if code == http.StatusForbidden {
+				c.AbortWithStatusJSON(code, &models.AccessDenied{
+					Error:  err.Error(),
+					Code:   "AccessDenied",
+					Method: c.Request.Method,
+					Path:   c.Request.URL.Path,
+				})
+				return
+			}
+			utils.SetErrorResponse(c.Writer, code, err.Error())
+			c.Abort()
+			return
+		}
+
+		c.Set(TenantContextKey, tenant)
+		c.Request = c.Request.WithContext(models.WithTenant(c.Request.Context(), tenant))
+		c.Header(r.config.Header, tenant)
+		c.Next()
+	}
+}
+
+// UnaryServerInterceptor 返回gRPC租户解析拦截器，租户和API key从同名（小写）的gRPC metadata读取
+func (r *TenantResolver) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		first := func(name string) string {
+			if values := md.Get(name); len(values) > 0 {
+				return values[0]
+			}
+			return ""
+		}
+
+		var key string
+		if r.authorizer != nil {
+			key = first(r.authorizer.config.Header)
+		}
+
+		tenant, code, err := r.resolve(first(r.config.Header), key, info.FullMethod)
+		if err != nil {
+			if code == http.StatusForbidden {
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return handler(models.WithTenant(ctx, tenant), req)
+	}
+}
+
+// resolve 根据请求的租户和API key确定租户，失败时返回HTTP状态码（400或403）和原因
+func (r *TenantResolver) resolve(requested, key, path string) (string, int, error) {
+	requested = strings.TrimSpace(requested)
+	if requested != "" {
+		if err := models.ValidateTenantID(requested); err != nil {
+			return "", http.StatusBadRequest, err
+		}
+	}
+
+	tenant := requested
+	if bound := r.boundTenant(key); bound != "" {
+		if requested != "" && requested != bound {
+			return "", http.StatusForbidden, fmt.Errorf("API key is bound to tenant %s, cannot access tenant %s", bound, requested)
+		}
+		tenant = bound
+	}
+
+	if tenant == "" {
+		if r.config.Required && !r.excluded(path) {
+			return "", http.StatusBadRequest, fmt.Errorf("Missing tenant in %s header", r.config.Header)
+		}
+		tenant = r.config.DefaultTenant
+	}
+	return tenant, 0, nil
+}
+
+// boundTenant API key绑定的租户，未启用鉴权、无有效key或key未绑定租户时返回空
+func (r *TenantResolver) boundTenant(key string) string {
+	if r.authorizer == nil {
+		return ""
+	}
+	apiKey, ok := r.authorizer.Authenticate(key)
+	if !ok {
+		return ""
+	}
+	return apiKey.Tenant
+}
+
+// excluded 检查路径是否不要求显式租户
+func (r *TenantResolver) excluded(path string) bool {
+	for _, prefix := range r.config.ExcludePaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// AuditEventSchemaVersion 审计事件schema版本，字段发生不兼容变更时递增主版本
+const AuditEventSchemaVersion = "1.0"
+
+// DefaultAuditTopic 审计事件默认主题
+const DefaultAuditTopic = "audit-events"
+
+// AuditEventType 审计事件类型
+const (
+	AuditEventTypeAccess = "access" // 只读访问（GET/HEAD等）
+	AuditEventTypeAudit  = "audit"  // 状态变更操作（PUT/POST/DELETE/PATCH）
+)
+
+// AuditEvent 审计/访问日志事件，导出到队列主题供下游SIEM/合规管道消费
+type AuditEvent struct {
+	SchemaVersion string            `json:"schema_version"`
+	EventID       string            `json:"event_id"`
+	EventType     string            `json:"event_type"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Service       string            `json:"service"`
+	RequestID     string            `json:"request_id,omitempty"`
+	Action        string            `json:"action"` // 方法 + 路由模板，如 "PUT /:bucket/:key"
+	Actor         AuditActor        `json:"actor"`
+	Resource      AuditResource     `json:"resource"`
+	Outcome       AuditOutcome      `json:"outcome"`
+	DurationMs    int64             `json:"duration_ms"`
+	Details       map[string]string `json:"details,omitempty"`
+}
+
+// AuditActor 发起请求的主体
+type AuditActor struct {
+	Name      string `json:"name,omitempty"` // API key名称或上游服务转发的操作者
+	Tenant    string `json:"tenant,omitempty"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// HeaderActor 服务间调用时透传原始操作者名称的请求头
+const HeaderActor = "X-Mocks3-Actor"
+
+// auditActorContextKey context中保存操作者的键
+type auditActorContextKey struct{}
+
+// WithAuditActor 返回携带操作者的context
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorContextKey{}, actor)
+}
+
+// AuditActorFromContext 获取context中的操作者，未设置时返回零值
+func AuditActorFromContext(ctx context.Context) AuditActor {
+	actor, _ := ctx.Value(auditActorContextKey{}).(AuditActor)
+	return actor
+}
+
+// AuditResource 被访问的资源
+type AuditResource struct {
+	Bucket string `json:"bucket,omitempty"`
+	Key    string `json:"key,omitempty"`
+	Path   string `json:"path"`
+}
+
+// AuditOutcome 请求结果
+type AuditOutcome struct {
+	StatusCode int    `json:"status_code"`
+	Success    bool   `json:"success"`
+	BytesIn    int64  `json:"bytes_in"`
+	BytesOut   int64  `json:"bytes_out"`
+	Error      string `json:"error,omitempty"`
+}
+
+// TopicEvent 主题中的一条事件
+// Payload按原样保存，不保证是合法JSON（用于注入畸形事件）
+type TopicEvent struct {
+	ID          string    `json:"id"`
+	Topic       string    `json:"topic"`
+	Payload     string    `json:"payload"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// AuditExportStats 审计导出统计
+type AuditExportStats struct {
+	Topic     string `json:"topic"`
+	Queued    int    `json:"queued"`
+	Published int64  `json:"published"`
+	Malformed int64  `json:"malformed"`
+	Dropped   int64  `json:"dropped"`
+	Failed    int64  `json:"failed"`
+}
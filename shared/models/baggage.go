@@ -0,0 +1,11 @@
+package models
+
+// OTel baggage键，随请求在服务间传递，日志和错误注入条件会读取
+const (
+	BaggageTenant     = "tenant"      // 请求所属租户
+	BaggageTestRunID  = "test_run_id" // 测试运行ID，用于把日志和注入限定到一次测试
+	BaggageScenarioID = "scenario_id" // 混沌场景ID
+)
+
+// HeaderBaggage W3C baggage请求头
+const HeaderBaggage = "baggage"
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// MetadataChangeSchemaVersion 元数据变更事件schema版本
+const MetadataChangeSchemaVersion = "1.0"
+
+// DefaultMetadataChangeTopic 元数据变更事件默认主题
+const DefaultMetadataChangeTopic = "metadata-changes"
+
+// 元数据变更事件类型
+const (
+	MetadataCreated = "MetadataCreated"
+	MetadataUpdated = "MetadataUpdated"
+	MetadataDeleted = "MetadataDeleted"
+)
+
+// 触发变更的操作
+const (
+	ChangeOpSave           = "save"
+	ChangeOpUpdate         = "update"
+	ChangeOpDelete         = "delete"
+	ChangeOpRestore        = "restore"         // 恢复软删除，事件类型为MetadataCreated
+	ChangeOpRestoreVersion = "restore_version" // 恢复历史版本，事件类型为MetadataUpdated
+	ChangeOpBatch          = "batch"
+)
+
+// MetadataChangeEvent 元数据变更事件（CDC），按入队顺序发布，消费者应以Version判断先后
+type MetadataChangeEvent struct {
+	SchemaVersion string    `json:"schema_version"`
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	Operation     string    `json:"operation"`
+	Timestamp     time.Time `json:"timestamp"`
+	Tenant        string    `json:"tenant"`
+	Bucket        string    `json:"bucket"`
+	Key           string    `json:"key"`
+	Version       int64     `json:"version,omitempty"`  // 变更后的版本号，删除事件为空
+	Metadata      *Metadata `json:"metadata,omitempty"` // 变更后的完整元数据，删除事件为空
+}
+
+// MetadataChangeStats 变更事件发布统计
+type MetadataChangeStats struct {
+	Topic     string `json:"topic"`
+	Queued    int    `json:"queued"`
+	Published int64  `json:"published"`
+	Dropped   int64  `json:"dropped"`
+	Failed    int64  `json:"failed"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// DeadLetterFilter 死信任务过滤条件，零值匹配全部
+type DeadLetterFilter struct {
+	Type   string     `json:"type,omitempty"`   // 任务类型
+	Before *time.Time `json:"before,omitempty"` // 只匹配在该时间之前进入死信队列的任务
+}
+
+// Matches 任务是否符合过滤条件
+func (f *DeadLetterFilter) Matches(task *Task) bool {
+	if f == nil {
+		return true
+	}
+	if f.Type != "" && task.Type != f.Type {
+		return false
+	}
+	if f.Before != nil && (task.FailedAt == nil || !task.FailedAt.Before(*f.Before)) {
+		return false
+	}
+	return true
+}
+
+// DeadLetterPage 死信任务分页结果（按进入死信队列的时间倒序）
+type DeadLetterPage struct {
+	Tasks  []*Task `json:"tasks"`
+	Total  int     `json:"total"` // 符合条件的死信任务总数
+	Offset int     `json:"offset"`
+	Limit  int     `json:"limit"`
+}
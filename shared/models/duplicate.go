@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// HeaderDuplicateOf 写入去重命中时返回已有对象（bucket/key）的响应头
+const HeaderDuplicateOf = "X-Mocks3-Duplicate-Of"
+
+// ObjectReference 对象引用
+type ObjectReference struct {
+	ID        string    `json:"id"`
+	Bucket    string    `json:"bucket"`
+	Key       string    `json:"key"`
+	ETag      string    `json:"etag,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DuplicateGroup 内容相同（哈希和大小一致）的一组对象，按创建时间升序
+type DuplicateGroup struct {
+	Hash             string             `json:"hash"`
+	Size             int64              `json:"size"`
+	Copies           int64              `json:"copies"`
+	ReclaimableBytes int64              `json:"reclaimable_bytes"` // 只保留一份时可释放的字节数
+	Objects          []*ObjectReference `json:"objects"`
+}
+
+// DuplicateReport 重复内容报告，按可释放字节数降序
+type DuplicateReport struct {
+	Groups           []*DuplicateGroup `json:"groups"`
+	ReclaimableBytes int64             `json:"reclaimable_bytes"`
+}
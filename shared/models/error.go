@@ -1,6 +1,8 @@
 package models
 
 import (
+	"math"
+	"math/rand"
 	"time"
 )
 
@@ -9,40 +11,104 @@ type ErrorRule struct {
 	ID          string            `json:"id"`
 	Name        string            `json:"name"`
 	Description string            `json:"description"`
-	Service     string            `json:"service"`    // 目标服务
-	Operation   string            `json:"operation"`  // 目标操作
-	Conditions  []ErrorCondition  `json:"conditions"` // 触发条件
-	Action      ErrorAction       `json:"action"`     // 错误动作
+	Namespace   string            `json:"namespace,omitempty"` // 规则所属命名空间，为空表示default
+	Service     string            `json:"service"`             // 目标服务
+	Operation   string            `json:"operation"`           // 目标操作
+	Conditions  []ErrorCondition  `json:"conditions"`          // 触发条件，都满足时触发（条件树的根）
+	Action      ErrorAction       `json:"action"`              // 错误动作
 	Enabled     bool              `json:"enabled"`
 	Priority    int               `json:"priority"`           // 规则优先级
 	MaxTriggers int               `json:"max_triggers"`       // 最大触发次数，0表示无限制
 	Triggered   int               `json:"triggered"`          // 已触发次数
 	Schedule    *ErrorSchedule    `json:"schedule,omitempty"` // 调度配置
+	Ramp        *ErrorRamp        `json:"ramp,omitempty"`     // 触发概率逐步上升
+	Burst       *ErrorBurst       `json:"burst,omitempty"`    // 触发后连续失败
+	Webhooks    []*RuleWebhook    `json:"webhooks,omitempty"` // 规则触发时的通知地址
+	DryRun      bool              `json:"dry_run,omitempty"`  // 只记录匹配，不执行动作
+	Seed        *int64            `json:"seed,omitempty"`     // 随机种子，设置后概率由请求哈希确定，可复现
+	Executor    string            `json:"executor,omitempty"` // 动作执行方式，为空时由目标服务的中间件执行
 	Metadata    map[string]string `json:"metadata,omitempty"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
 	CreatedBy   string            `json:"created_by"`
+
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"` // 最近一次触发时间
 }
 
-// ErrorCondition 错误触发条件
+// 规则动作的执行方式
+const (
+	RuleExecutorInline    = "inline"    // 目标服务的中间件和客户端按请求执行（默认）
+	RuleExecutorToxiproxy = "toxiproxy" // 在Toxiproxy中为目标服务的代理创建toxic，TCP层生效，不检查请求
+)
+
+// ErrorCondition 错误触发条件，and/or/not条件组合Conditions中的子条件，构成条件树
 type ErrorCondition struct {
-	Type     string      `json:"type"`     // 条件类型：probability, header, param, time, etc.
-	Operator string      `json:"operator"` // 操作符：eq, ne, gt, lt, contains, etc.
-	Field    string      `json:"field"`    // 字段名
-	Value    interface{} `json:"value"`    // 期望值
+	Type       string           `json:"type"`                 // 条件类型：probability, header, path, request_size, time_of_day, every_nth, and, or, not, etc.
+	Operator   string           `json:"operator"`             // 操作符：eq, ne, gt, lt, contains, etc.
+	Field      string           `json:"field"`                // 字段名
+	Value      interface{}      `json:"value"`                // 期望值
+	Conditions []ErrorCondition `json:"conditions,omitempty"` // and/or/not的子条件，not只有一个子条件
 }
 
 // ErrorConditionType 条件类型
 const (
-	ErrorConditionTypeProbability = "probability" // 概率触发
-	ErrorConditionTypeHeader      = "header"      // HTTP 头
-	ErrorConditionTypeParam       = "param"       // 请求参数
-	ErrorConditionTypeTime        = "time"        // 时间条件
-	ErrorConditionTypeUserAgent   = "user_agent"  // User-Agent
-	ErrorConditionTypeIP          = "ip"          // IP 地址
-	ErrorConditionTypeCount       = "count"       // 请求计数
+	ErrorConditionTypeProbability = "probability"  // 概率触发
+	ErrorConditionTypeHeader      = "header"       // HTTP 头
+	ErrorConditionTypeParam       = "param"        // 请求参数
+	ErrorConditionTypeTime        = "time"         // 时间条件
+	ErrorConditionTypeUserAgent   = "user_agent"   // User-Agent
+	ErrorConditionTypeIP          = "ip"           // IP 地址
+	ErrorConditionTypeCount       = "count"        // 请求计数
+	ErrorConditionTypePath        = "path"         // 请求路径
+	ErrorConditionTypeOperation   = "operation"    // 操作名
+	ErrorConditionTypeRequestSize = "request_size" // 请求大小（字节）
+	ErrorConditionTypeTimeOfDay   = "time_of_day"  // 每天的时间窗口，值为"09:00-18:00"，字段可指定时区
+	ErrorConditionTypeEveryNth    = "every_nth"    // 每N个请求触发一次
+	ErrorConditionTypeTraceID     = "trace_id"     // 请求的trace ID
+	ErrorConditionTypeUserID      = "user_id"      // 请求的用户ID
+	ErrorConditionTypeTenant      = "tenant"       // 请求所属租户
+	ErrorConditionTypeObjectKey   = "object_key"   // 操作的对象，值为"<bucket>/<key>"
+	ErrorConditionTypeTestRunID   = "test_run_id"  // baggage中的测试运行ID
+	ErrorConditionTypeScenarioID  = "scenario_id"  // baggage中的混沌场景ID
+	ErrorConditionTypeAnd         = "and"          // 子条件都满足
+	ErrorConditionTypeOr          = "or"           // 任一子条件满足
+	ErrorConditionTypeNot         = "not"          // 子条件不满足
 )
 
+// 错误注入检查的请求元数据键
+const (
+	ErrorMetadataHeaderPrefix = "header_"      // 请求头，键为header_<名称>
+	ErrorMetadataParamPrefix  = "param_"       // 请求参数，键为param_<名称>
+	ErrorMetadataPath         = "path"         // 请求路径
+	ErrorMetadataRequestSize  = "request_size" // 请求大小（字节）
+	ErrorMetadataUserAgent    = "user_agent"
+	ErrorMetadataRemoteAddr   = "remote_addr"
+	ErrorMetadataRequestCount = "request_count"
+	ErrorMetadataTraceID      = "trace_id"    // 未提供时从traceparent请求头解析
+	ErrorMetadataUserID       = "user_id"     // 未提供时取X-User-Id请求头
+	ErrorMetadataTenant       = "tenant"      // 未提供时取X-Tenant-Id请求头，再取baggage
+	ErrorMetadataObjectKey    = "object_key"  // "<bucket>/<key>"
+	ErrorMetadataTestRunID    = "test_run_id" // 未提供时从baggage请求头解析
+	ErrorMetadataScenarioID   = "scenario_id" // 未提供时从baggage请求头解析
+)
+
+// HeaderUserID 标识请求用户，用于把错误注入限定到某个用户的请求
+const HeaderUserID = "X-User-Id"
+
+// 规则命名空间：多个团队共用一个mock-error实例时，各自的规则只对本命名空间的注入检查生效
+const (
+	DefaultRuleNamespace = "default"
+	HeaderNamespace      = "X-Mocks3-Namespace" // 注入检查和规则管理请求所属的命名空间
+)
+
+// RuleNamespace 返回命名空间，为空时为default
+func RuleNamespace(namespace string) string {
+	if namespace == "" {
+		return DefaultRuleNamespace
+	}
+	return namespace
+}
+
 // ErrorAction 错误动作
 type ErrorAction struct {
 	Type     string                 `json:"type"`                // 动作类型
@@ -52,20 +118,78 @@ type ErrorAction struct {
 	Headers  map[string]string      `json:"headers,omitempty"`   // 响应头
 	Body     string                 `json:"body,omitempty"`      // 响应体
 	Metadata map[string]interface{} `json:"metadata,omitempty"`  // 额外数据
+
+	BytesPerSecond  int64                `json:"bytes_per_second,omitempty"`  // 限速和滴流动作的响应带宽（字节/秒）
+	AbortAfterBytes int64                `json:"abort_after_bytes,omitempty"` // 滴流和断开动作发送该字节数的响应体后断开连接，滴流为0时发送完整响应
+	Latency         *LatencyDistribution `json:"latency,omitempty"`           // 延迟分布，设置后每次触发按分布采样Delay
+
+	Duration    *time.Duration `json:"duration,omitempty"`     // 资源压力动作的持续时间
+	CPUCores    int            `json:"cpu_cores,omitempty"`    // CPU压力动作占满的核数，默认1，不超过进程可用的核数
+	MemoryBytes int64          `json:"memory_bytes,omitempty"` // 内存压力动作分配的字节数
 }
 
 // ErrorActionType 错误动作类型
 const (
-	ErrorActionTypeHTTPError     = "http_error"     // HTTP 错误响应
-	ErrorActionTypeNetworkError  = "network_error"  // 网络错误
-	ErrorActionTypeTimeout       = "timeout"        // 超时
-	ErrorActionTypeDelay         = "delay"          // 延迟
-	ErrorActionTypeCorruption    = "corruption"     // 数据损坏
-	ErrorActionTypeDisconnect    = "disconnect"     // 连接断开
-	ErrorActionTypeDatabaseError = "database_error" // 数据库错误
-	ErrorActionTypeStorageError  = "storage_error"  // 存储错误
+	ErrorActionTypeHTTPError         = "http_error"          // HTTP 错误响应
+	ErrorActionTypeNetworkError      = "network_error"       // 网络错误
+	ErrorActionTypeTimeout           = "timeout"             // 超时
+	ErrorActionTypeDelay             = "delay"               // 延迟
+	ErrorActionTypeCorruption        = "corruption"          // 数据损坏
+	ErrorActionTypeDisconnect        = "disconnect"          // 发送响应头和部分响应体后断开连接
+	ErrorActionTypeDatabaseError     = "database_error"      // 数据库错误
+	ErrorActionTypeStorageError      = "storage_error"       // 存储错误
+	ErrorActionTypeThrottle          = "throttle"            // 限制响应带宽
+	ErrorActionTypeSlowDrip          = "slow_drip"           // 立即返回响应头，响应体滴流发送，可中途断开
+	ErrorActionTypeCPUPressure       = "cpu_pressure"        // 在目标进程中占用CPU，请求照常处理
+	ErrorActionTypeMemoryPressure    = "memory_pressure"     // 在目标进程中分配内存，请求照常处理
+	ErrorActionTypeDNSFailure        = "dns_failure"         // 出站请求DNS解析失败
+	ErrorActionTypeConnectionRefused = "connection_refused"  // 出站请求连接被拒绝
+	ErrorActionTypeTLSHandshakeError = "tls_handshake_error" // 出站请求TLS握手失败
 )
 
+// LatencyDistributionType 延迟分布类型
+const (
+	LatencyDistributionNormal      = "normal"      // 正态分布：mean_ms、stddev_ms
+	LatencyDistributionExponential = "exponential" // 指数分布：mean_ms
+	LatencyDistributionPareto      = "pareto"      // 帕累托分布：scale_ms为最小值，shape越小尾部越长
+)
+
+// LatencyDistribution 延迟分布
+type LatencyDistribution struct {
+	Type     string  `json:"type"`
+	MeanMs   float64 `json:"mean_ms,omitempty"`
+	StddevMs float64 `json:"stddev_ms,omitempty"`
+	ScaleMs  float64 `json:"scale_ms,omitempty"`
+	Shape    float64 `json:"shape,omitempty"`
+	MinMs    float64 `json:"min_ms,omitempty"` // 采样结果的下限
+	MaxMs    float64 `json:"max_ms,omitempty"` // 采样结果的上限，0表示不限制
+}
+
+// Sample 按分布采样一个延迟，结果不小于0并限制在[min_ms, max_ms]内；r为nil时使用全局随机源
+func (d *LatencyDistribution) Sample(r *rand.Rand) time.Duration {
+	float64Fn, normFn, expFn := rand.Float64, rand.NormFloat64, rand.ExpFloat64
+	if r != nil {
+		float64Fn, normFn, expFn = r.Float64, r.NormFloat64, r.ExpFloat64
+	}
+
+	var ms float64
+	switch d.Type {
+	case LatencyDistributionNormal:
+		ms = d.MeanMs + normFn()*d.StddevMs
+	case LatencyDistributionExponential:
+		ms = expFn() * d.MeanMs
+	case LatencyDistributionPareto:
+		// 逆变换采样，1-U落在(0, 1]
+		ms = d.ScaleMs / math.Pow(1-float64Fn(), 1/d.Shape)
+	}
+
+	ms = math.Max(ms, math.Max(d.MinMs, 0))
+	if d.MaxMs > 0 {
+		ms = math.Min(ms, d.MaxMs)
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
 // ErrorSchedule 错误调度配置
 type ErrorSchedule struct {
 	StartTime *time.Time `json:"start_time,omitempty"` // 开始时间
@@ -73,6 +197,96 @@ type ErrorSchedule struct {
 	Days      []string   `json:"days,omitempty"`       // 生效日期 (monday, tuesday, etc.)
 	Hours     []int      `json:"hours,omitempty"`      // 生效小时 (0-23)
 	Timezone  string     `json:"timezone,omitempty"`   // 时区
+
+	Cron            string `json:"cron,omitempty"`              // 生效分钟的cron表达式（分 时 日 月 周），如"* 9-17 * * 1-5"
+	MaxDurationMins int    `json:"max_duration_mins,omitempty"` // 从开始时间（未设置时为规则创建时间）起最多生效的分钟数
+}
+
+// ExpiresAt 调度的结束时间：结束时间和最长生效时间中较早的一个，都未设置时为nil
+func (s *ErrorSchedule) ExpiresAt(createdAt time.Time) *time.Time {
+	end := s.EndTime
+	if s.MaxDurationMins > 0 {
+		start := createdAt
+		if s.StartTime != nil {
+			start = *s.StartTime
+		}
+		maxEnd := start.Add(time.Duration(s.MaxDurationMins) * time.Minute)
+		if end == nil || maxEnd.Before(*end) {
+			end = &maxEnd
+		}
+	}
+	return end
+}
+
+// ErrorRamp 触发概率从起始值线性上升到目标值，条件满足后再按当前概率触发
+type ErrorRamp struct {
+	StartProbability  float64    `json:"start_probability"`
+	TargetProbability float64    `json:"target_probability"`
+	DurationSecs      int        `json:"duration_secs"`        // 从起始值上升到目标值的秒数
+	StartTime         *time.Time `json:"start_time,omitempty"` // 开始上升的时间，未设置时为规则创建时间
+}
+
+// ProbabilityAt 指定时间的触发概率，开始前为起始概率，结束后为目标概率
+func (r *ErrorRamp) ProbabilityAt(createdAt, now time.Time) float64 {
+	start := createdAt
+	if r.StartTime != nil {
+		start = *r.StartTime
+	}
+
+	elapsed := now.Sub(start)
+	duration := time.Duration(r.DurationSecs) * time.Second
+	switch {
+	case elapsed <= 0:
+		return r.StartProbability
+	case duration <= 0 || elapsed >= duration:
+		return r.TargetProbability
+	default:
+		progress := float64(elapsed) / float64(duration)
+		return r.StartProbability + (r.TargetProbability-r.StartProbability)*progress
+	}
+}
+
+// ErrorBurst 规则触发后，同一服务和操作接下来的请求不再评估条件直接失败，共连续失败Count次后恢复
+type ErrorBurst struct {
+	Count      int `json:"count"`                 // 连续失败次数，包括第一次触发
+	WindowSecs int `json:"window_secs,omitempty"` // 第一次触发后超过该秒数未用完的失败次数作废，0表示不限制
+}
+
+// KillSwitchStatus 全局熔断开关状态，开启后不注入任何错误
+type KillSwitchStatus struct {
+	Engaged   bool       `json:"engaged"`
+	Reason    string     `json:"reason,omitempty"`
+	EngagedBy string     `json:"engaged_by,omitempty"`
+	EngagedAt *time.Time `json:"engaged_at,omitempty"`
+}
+
+// RuleWebhook 规则触发时POST RuleTriggerEvent的地址
+//
+// MinTriggersPerMinute为0时每次触发都通知；大于0时只在最近一分钟的触发次数达到该值时发送rule.rate_exceeded。
+type RuleWebhook struct {
+	URL                  string `json:"url"`
+	MinTriggersPerMinute int    `json:"min_triggers_per_minute,omitempty"`
+	CooldownSecs         int    `json:"cooldown_secs,omitempty"` // 两次通知的最小间隔，触发率通知未设置时为60秒
+}
+
+// 规则触发通知事件
+const (
+	RuleEventTriggered    = "rule.triggered"
+	RuleEventRateExceeded = "rule.rate_exceeded"
+)
+
+// RuleTriggerEvent 规则触发时POST到webhook的通知，text为可读摘要，可直接发送到Slack兼容的webhook
+type RuleTriggerEvent struct {
+	Event             string      `json:"event"`
+	Text              string      `json:"text"`
+	RuleID            string      `json:"rule_id"`
+	RuleName          string      `json:"rule_name"`
+	Service           string      `json:"service"`
+	Operation         string      `json:"operation"`
+	Action            ErrorAction `json:"action"`
+	Triggered         int         `json:"triggered"`                     // 规则累计触发次数
+	TriggersPerMinute int         `json:"triggers_per_minute,omitempty"` // 最近一分钟的触发次数（触发率通知）
+	Timestamp         time.Time   `json:"timestamp"`
 }
 
 // ErrorStats 错误统计
@@ -82,6 +296,7 @@ type ErrorStats struct {
 	TotalTriggers    int64                   `json:"total_triggers"`
 	TriggersLastHour int64                   `json:"triggers_last_hour"`
 	TriggersToday    int64                   `json:"triggers_today"`
+	DryRunMatches    int64                   `json:"dry_run_matches"` // 试运行命中但未执行动作的次数
 	RuleStats        map[string]*RuleStat    `json:"rule_stats"`
 	ServiceStats     map[string]*ServiceStat `json:"service_stats"`
 	ErrorTypeStats   map[string]int64        `json:"error_type_stats"`
@@ -95,6 +310,7 @@ type RuleStat struct {
 	RuleName      string           `json:"rule_name"`
 	TotalTriggers int64            `json:"total_triggers"`
 	LastTriggered time.Time        `json:"last_triggered"`
+	DryRunMatches int64            `json:"dry_run_matches"`
 	ErrorCounts   map[string]int64 `json:"error_counts"` // error_type -> count
 }
 
@@ -117,18 +333,108 @@ type OpStat struct {
 
 // ErrorEvent 错误事件（用于记录和分析）
 type ErrorEvent struct {
-	ID         string                 `json:"id"`
-	RuleID     string                 `json:"rule_id"`
-	RuleName   string                 `json:"rule_name"`
-	Service    string                 `json:"service"`
-	Operation  string                 `json:"operation"`
-	Action     ErrorAction            `json:"action"`
-	RequestID  string                 `json:"request_id,omitempty"`
-	UserAgent  string                 `json:"user_agent,omitempty"`
-	RemoteAddr string                 `json:"remote_addr,omitempty"`
-	Headers    map[string]string      `json:"headers,omitempty"`
-	Params     map[string]interface{} `json:"params,omitempty"`
-	Timestamp  time.Time              `json:"timestamp"`
-	Success    bool                   `json:"success"` // 是否成功注入错误
-	Error      string                 `json:"error,omitempty"`
+	ID          string                 `json:"id"`
+	RuleID      string                 `json:"rule_id"`
+	RuleName    string                 `json:"rule_name"`
+	Namespace   string                 `json:"namespace,omitempty"`
+	Service     string                 `json:"service"`
+	Operation   string                 `json:"operation"`
+	Action      ErrorAction            `json:"action"`
+	RequestID   string                 `json:"request_id,omitempty"`
+	UserAgent   string                 `json:"user_agent,omitempty"`
+	RemoteAddr  string                 `json:"remote_addr,omitempty"`
+	Headers     map[string]string      `json:"headers,omitempty"`
+	Params      map[string]interface{} `json:"params,omitempty"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Success     bool                   `json:"success"`                // 是否成功注入错误
+	DryRun      bool                   `json:"dry_run,omitempty"`      // 试运行命中，未执行动作
+	RequestHash string                 `json:"request_hash,omitempty"` // 请求哈希，固定随机种子时决定概率判定
+	Error       string                 `json:"error,omitempty"`
+}
+
+// SeededDecision 固定随机种子时请求哈希的概率判定结果，其他条件视为满足
+type SeededDecision struct {
+	RequestHash string             `json:"request_hash"`
+	Triggers    bool               `json:"triggers"`
+	Draws       map[string]float64 `json:"draws,omitempty"` // 随机数，键为概率条件在条件树中的位置，概率爬升为ramp
+}
+
+// ErrorRuleSetVersion 规则集导出格式版本
+const ErrorRuleSetVersion = 1
+
+// 规则集导入模式
+const (
+	RuleImportModeMerge   = "merge"   // 按ID更新已有规则，添加新规则
+	RuleImportModeReplace = "replace" // 删除规则集中没有的规则
+)
+
+// ErrorRuleSet 导出或导入的规则集
+type ErrorRuleSet struct {
+	Version int          `json:"version"`
+	Rules   []*ErrorRule `json:"rules"`
+}
+
+// RuleImportResult 规则集导入结果
+type RuleImportResult struct {
+	Mode    string   `json:"mode"`
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Deleted int      `json:"deleted"`
+	RuleIDs []string `json:"rule_ids"` // 规则集中各规则的ID，按导入顺序
+}
+
+// RuleTemplate 参数化的规则模板
+type RuleTemplate struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	ActionType  string                   `json:"action_type"`
+	Parameters  []*RuleTemplateParameter `json:"parameters"`
+}
+
+// RuleTemplateParameter 规则模板的数值参数
+type RuleTemplateParameter struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Default     float64 `json:"default"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+}
+
+// RuleFromTemplateRequest 从模板创建规则的请求
+type RuleFromTemplateRequest struct {
+	Name       string             `json:"name"` // 为空时使用模板名称和目标服务
+	Namespace  string             `json:"namespace"`
+	Service    string             `json:"service"`
+	Operation  string             `json:"operation"`
+	Priority   int                `json:"priority"`
+	Enabled    *bool              `json:"enabled"`              // 默认启用
+	Params     map[string]float64 `json:"params"`               // 未指定的参数使用默认值
+	Conditions []ErrorCondition   `json:"conditions,omitempty"` // 追加到模板条件之后
+}
+
+// StaleRuleReason 过期规则原因
+const (
+	StaleRuleReasonNeverMatched   = "never_matched"   // 长时间未命中
+	StaleRuleReasonExpired        = "expired"         // 调度已结束
+	StaleRuleReasonServiceMissing = "service_missing" // 目标服务已不存在
+)
+
+// StaleRule 过期规则
+type StaleRule struct {
+	RuleID          string     `json:"rule_id"`
+	RuleName        string     `json:"rule_name"`
+	Service         string     `json:"service"`
+	Reason          string     `json:"reason"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
+	IdleFor         string     `json:"idle_for"`
+	Disabled        bool       `json:"disabled"` // 是否已被自动禁用
+}
+
+// StaleRuleReport 过期规则报告
+type StaleRuleReport struct {
+	IdleThreshold string       `json:"idle_threshold"`
+	TotalRules    int          `json:"total_rules"`
+	StaleRules    []*StaleRule `json:"stale_rules"`
+	DisabledCount int          `json:"disabled_count"`
+	GeneratedAt   time.Time    `json:"generated_at"`
 }
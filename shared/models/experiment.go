@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// 混沌实验状态
+const (
+	ExperimentStatusRunning    = "running"
+	ExperimentStatusCompleted  = "completed"   // 持续时间结束且稳态探针始终满足
+	ExperimentStatusRolledBack = "rolled_back" // 稳态探针失败超过容忍次数，已自动禁用规则
+	ExperimentStatusAborted    = "aborted"     // 手动停止
+	ExperimentStatusFailed     = "failed"      // 注入前稳态不满足或规则创建失败，未开始注入
+)
+
+// 稳态探针类型
+const (
+	ProbeTypeHTTP   = "http"   // 请求URL，检查状态码和延迟
+	ProbeTypeMetric = "metric" // 请求返回JSON的URL，检查指定字段的数值
+)
+
+// ExperimentMetadataKey 实验创建的规则在Metadata中记录实验ID
+const ExperimentMetadataKey = "experiment_id"
+
+// Experiment 混沌实验：注入一组规则（场景）的同时持续检查稳态探针，稳态被破坏时自动回滚
+type Experiment struct {
+	ID                string              `json:"id"`
+	Name              string              `json:"name"`
+	Description       string              `json:"description"`
+	Rules             []*ErrorRule        `json:"rules"`                         // 实验场景，开始时创建，结束时禁用
+	Probes            []*SteadyStateProbe `json:"probes"`                        // 稳态探针
+	DurationSecs      int                 `json:"duration_secs"`                 // 持续时间，0表示直到手动停止
+	ProbeIntervalSecs int                 `json:"probe_interval_secs,omitempty"` // 探针检查间隔，默认10秒
+	Status            string              `json:"status"`
+	Reason            string              `json:"reason,omitempty"` // 回滚、失败或停止的原因
+	RuleIDs           []string            `json:"rule_ids,omitempty"`
+	ProbeResults      []*ProbeResult      `json:"probe_results,omitempty"` // 各探针最近一次的检查结果
+	CreatedBy         string              `json:"created_by,omitempty"`
+	StartedAt         time.Time           `json:"started_at"`
+	EndedAt           *time.Time          `json:"ended_at,omitempty"`
+}
+
+// SteadyStateProbe 稳态探针，连续失败超过Tolerance次视为稳态被破坏
+type SteadyStateProbe struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method,omitempty"`     // http探针使用，默认GET
+	Headers   map[string]string `json:"headers,omitempty"`    // 如访问启用鉴权的服务时的X-API-Key
+	TimeoutMs int               `json:"timeout_ms,omitempty"` // 默认5000
+	Tolerance int               `json:"tolerance,omitempty"`  // 允许的连续失败次数
+
+	// http探针
+	ExpectedStatus []int `json:"expected_status,omitempty"` // 为空时要求2xx
+	MaxLatencyMs   int   `json:"max_latency_ms,omitempty"`
+
+	// metric探针
+	Field string   `json:"field,omitempty"` // JSON字段路径，以.分隔，如service_stats.storage-service.error_rate
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+}
+
+// ProbeResult 一次稳态探针检查的结果
+type ProbeResult struct {
+	Probe               string    `json:"probe"`
+	Success             bool      `json:"success"`
+	Value               *float64  `json:"value,omitempty"` // metric探针读取的数值
+	LatencyMs           int64     `json:"latency_ms"`
+	Error               string    `json:"error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	CheckedAt           time.Time `json:"checked_at"`
+}
@@ -0,0 +1,116 @@
+package models
+
+import "time"
+
+// 事件包触发类型
+const (
+	IncidentTriggerSLO       = "slo_violation" // 最近请求的错误率或慢请求比例超过阈值
+	IncidentTriggerGuardrail = "guardrail"     // 护栏触发（如限流拒绝）
+	IncidentTriggerManual    = "manual"        // 通过管理接口手动抓取
+)
+
+// 事件包存储状态
+const (
+	IncidentStatusStored = "stored"
+	IncidentStatusFailed = "failed"
+)
+
+// DefaultIncidentBucket 事件包默认存储bucket
+const DefaultIncidentBucket = "mocks3-incidents"
+
+// RequestRecord 飞行记录器中的请求摘要
+type RequestRecord struct {
+	Time         time.Time `json:"time"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Route        string    `json:"route,omitempty"`
+	Query        string    `json:"query,omitempty"`
+	Status       int       `json:"status"`
+	DurationMs   float64   `json:"duration_ms"`
+	ClientIP     string    `json:"client_ip,omitempty"`
+	RequestSize  int64     `json:"request_size"`
+	ResponseSize int64     `json:"response_size"`
+	TraceID      string    `json:"trace_id,omitempty"`
+	Errors       string    `json:"errors,omitempty"`
+}
+
+// LogRecord 飞行记录器中的日志
+type LogRecord struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	TraceID string            `json:"trace_id,omitempty"`
+}
+
+// SpanRecord 飞行记录器中的span摘要
+type SpanRecord struct {
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind"`
+	TraceID       string            `json:"trace_id"`
+	SpanID        string            `json:"span_id"`
+	ParentSpanID  string            `json:"parent_span_id,omitempty"`
+	StartTime     time.Time         `json:"start_time"`
+	DurationMs    float64           `json:"duration_ms"`
+	Status        string            `json:"status"`
+	StatusMessage string            `json:"status_message,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+}
+
+// SLOSnapshot 抓取时最近请求窗口的SLO统计
+type SLOSnapshot struct {
+	WindowSeconds      float64 `json:"window_seconds"`
+	Requests           int     `json:"requests"`
+	Errors             int     `json:"errors"` // 5xx响应数
+	ErrorRate          float64 `json:"error_rate"`
+	SlowRequests       int     `json:"slow_requests"`
+	SlowRate           float64 `json:"slow_rate"`
+	LatencyThresholdMs float64 `json:"latency_threshold_ms"`
+	MaxErrorRate       float64 `json:"max_error_rate"`
+	MaxSlowRate        float64 `json:"max_slow_rate"`
+}
+
+// IncidentSummary 事件包摘要
+type IncidentSummary struct {
+	ID        string       `json:"id"`
+	Service   string       `json:"service"`
+	Trigger   string       `json:"trigger"`
+	Reason    string       `json:"reason"`
+	CreatedAt time.Time    `json:"created_at"`
+	Bucket    string       `json:"bucket"`
+	Key       string       `json:"key"`
+	Size      int64        `json:"size"`
+	Status    string       `json:"status"` // stored, failed
+	Error     string       `json:"error,omitempty"`
+	Requests  int          `json:"requests"`
+	Logs      int          `json:"logs"`
+	Spans     int          `json:"spans"`
+	SLO       *SLOSnapshot `json:"slo,omitempty"`
+}
+
+// IncidentBundle 异常发生时转储的最近请求、日志和span
+type IncidentBundle struct {
+	ID        string           `json:"id"`
+	Service   string           `json:"service"`
+	Trigger   string           `json:"trigger"`
+	Reason    string           `json:"reason"`
+	CreatedAt time.Time        `json:"created_at"`
+	SLO       *SLOSnapshot     `json:"slo,omitempty"`
+	Requests  []*RequestRecord `json:"requests"`
+	Logs      []*LogRecord     `json:"logs"`
+	Spans     []*SpanRecord    `json:"spans"`
+}
+
+// FlightRecorderStats 飞行记录器当前状态
+type FlightRecorderStats struct {
+	Service       string       `json:"service"`
+	Bucket        string       `json:"bucket"`
+	Requests      int          `json:"requests"`
+	Logs          int          `json:"logs"`
+	Spans         int          `json:"spans"`
+	Captured      int64        `json:"captured"`
+	Failed        int64        `json:"failed"`
+	Suppressed    int64        `json:"suppressed"` // 冷却期内未转储的触发
+	LastCaptureAt *time.Time   `json:"last_capture_at,omitempty"`
+	SLO           *SLOSnapshot `json:"slo"`
+}
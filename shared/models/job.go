@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// JobState 对外暴露的任务执行状态
+type JobState string
+
+const (
+	JobStateQueued   JobState = "queued"   // 等待worker处理（含尚未到期的延迟任务）
+	JobStateRunning  JobState = "running"  // worker正在处理
+	JobStateRetrying JobState = "retrying" // 执行失败，等待下一次重试
+	JobStateDone     JobState = "done"
+	JobStateFailed   JobState = "failed"  // 重试次数用尽，已移入死信队列
+	JobStateExpired  JobState = "expired" // 过期前未开始执行，已丢弃
+)
+
+// JobStatus 任务执行状态和进度
+type JobStatus struct {
+	ID          string     `json:"id"`
+	Type        string     `json:"type"`
+	State       JobState   `json:"state"`
+	Attempts    int        `json:"attempts"`           // 已开始的执行次数
+	Progress    *int       `json:"progress,omitempty"` // worker上报的当前执行进度（0-100）
+	Error       string     `json:"error,omitempty"`    // 最近一次失败的原因
+	WorkerID    string     `json:"worker_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ScheduledAt time.Time  `json:"scheduled_at"` // 可见时间，重试中的任务为下一次执行时间
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	FailedAt    *time.Time `json:"failed_at,omitempty"`
+	CallbackURL string     `json:"callback_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+
+	Output     map[string]interface{} `json:"output,omitempty"`      // 完成时的输出，合并到后续任务的数据中
+	Next       []string               `json:"next,omitempty"`        // 完成后入队的后续任务ID
+	ParentID   string                 `json:"parent_id,omitempty"`   // 完成后入队本任务的任务ID
+	WorkflowID string                 `json:"workflow_id,omitempty"` // 任务链第一个任务的ID
+}
+
+// NewJobStatus 由任务快照生成执行状态
+func NewJobStatus(task *Task) *JobStatus {
+	status := &JobStatus{
+		ID:          task.ID,
+		Type:        task.Type,
+		State:       task.JobState(),
+		Attempts:    task.RetryCount,
+		Progress:    task.Progress,
+		Error:       task.Error,
+		WorkerID:    task.WorkerID,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+		ScheduledAt: task.ScheduledAt,
+		StartedAt:   task.StartedAt,
+		CompletedAt: task.CompletedAt,
+		FailedAt:    task.FailedAt,
+		CallbackURL: task.CallbackURL,
+		ExpiresAt:   task.ExpiresAt,
+
+		Output:     task.Output,
+		Next:       task.NextIDs(),
+		ParentID:   task.ParentID,
+		WorkflowID: task.WorkflowID,
+	}
+	// RetryCount只统计失败的执行
+	if status.State == JobStateRunning || status.State == JobStateDone {
+		status.Attempts++
+	}
+	return status
+}
+
+// JobState 任务状态对应的执行状态
+func (t *Task) JobState() JobState {
+	switch t.Status {
+	case TaskStatusRunning:
+		return JobStateRunning
+	case TaskStatusRetrying:
+		return JobStateRetrying
+	case TaskStatusCompleted:
+		return JobStateDone
+	case TaskStatusFailed:
+		return JobStateFailed
+	case TaskStatusExpired:
+		return JobStateExpired
+	case TaskStatusPending, TaskStatusScheduled:
+		return JobStateQueued
+	default:
+		return JobState(t.Status)
+	}
+}
+
+// 任务结束通知的事件类型
+const (
+	JobEventDone    = "job.done"
+	JobEventFailed  = "job.failed"  // 重试次数用尽，已移入死信队列
+	JobEventExpired = "job.expired" // 过期前未开始执行，已丢弃
+)
+
+// JobEvent 任务完成、最终失败或过期时POST到任务回调地址的通知
+type JobEvent struct {
+	Event     string     `json:"event"`
+	Job       *JobStatus `json:"job"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// BatchEnqueueResult 批量入队中单个任务的结果
+type BatchEnqueueResult struct {
+	Index       int        `json:"index"` // 任务在请求中的位置
+	JobID       string     `json:"job_id,omitempty"`
+	Status      TaskStatus `json:"status,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	DuplicateOf string     `json:"duplicate_of,omitempty"` // 去重窗口内payload相同的已入队任务，任务未重复入队，JobID为该任务
+	Error       string     `json:"error,omitempty"`        // 入队失败的原因
+}
+
+// BatchEnqueueResponse 批量入队结果，Results与请求中的任务一一对应
+type BatchEnqueueResponse struct {
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []BatchEnqueueResult `json:"results"`
+}
+
+// DuplicateTaskError 入队任务的payload与去重窗口内已入队的任务相同，任务未入队
+type DuplicateTaskError struct {
+	TaskID      string
+	DuplicateOf string // 窗口内首次入队的任务ID
+}
+
+// Error 实现error接口
+func (e *DuplicateTaskError) Error() string {
+	return fmt.Sprintf("task %s is a duplicate of %s", e.TaskID, e.DuplicateOf)
+}
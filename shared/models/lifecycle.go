@@ -0,0 +1,157 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 生命周期影响预览模式
+const (
+	LifecyclePreviewExact  = "exact"  // 全表精确统计
+	LifecyclePreviewSample = "sample" // 按页采样（TABLESAMPLE）估算
+)
+
+// LifecycleRule 生命周期规则：对象最后修改时间超过指定天数后转换存储类别或过期删除
+type LifecycleRule struct {
+	ID             string            `json:"id,omitempty"`
+	Bucket         string            `json:"bucket"`
+	Prefix         string            `json:"prefix,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"` // 需全部匹配
+	ExpirationDays int               `json:"expiration_days,omitempty"`
+	TransitionDays int               `json:"transition_days,omitempty"`
+	StorageClass   string            `json:"storage_class,omitempty"` // 转换目标存储类别
+}
+
+// Validate 验证规则
+func (r *LifecycleRule) Validate() error {
+	if r.Bucket == "" {
+		return fmt.Errorf("%w: bucket is required", ErrInvalidArgument)
+	}
+	if r.ExpirationDays < 0 || r.TransitionDays < 0 {
+		return fmt.Errorf("%w: days must not be negative", ErrInvalidArgument)
+	}
+	if r.ExpirationDays == 0 && r.TransitionDays == 0 {
+		return fmt.Errorf("%w: expiration_days or transition_days is required", ErrInvalidArgument)
+	}
+	if r.TransitionDays > 0 && r.StorageClass == "" {
+		return fmt.Errorf("%w: storage_class is required for transition", ErrInvalidArgument)
+	}
+	if r.TransitionDays > 0 && r.ExpirationDays > 0 && r.TransitionDays >= r.ExpirationDays {
+		return fmt.Errorf("%w: transition_days must be less than expiration_days", ErrInvalidArgument)
+	}
+	return nil
+}
+
+// Filter 规则匹配的对象范围（不含时间条件）
+func (r *LifecycleRule) Filter() *MetadataFilter {
+	return &MetadataFilter{
+		Bucket: r.Bucket,
+		Prefix: r.Prefix,
+		Tags:   r.Tags,
+	}
+}
+
+// LifecyclePreviewRequest 生命周期规则影响预览请求
+type LifecyclePreviewRequest struct {
+	Rule          LifecycleRule `json:"rule"`
+	Mode          string        `json:"mode,omitempty"`           // exact（默认）或sample
+	SamplePercent float64       `json:"sample_percent,omitempty"` // sample模式下采样的数据页比例，默认10
+	Examples      int           `json:"examples,omitempty"`       // 返回的将过期对象示例数，默认10
+}
+
+// ObjectSummary 对象数量和总字节数
+type ObjectSummary struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// LifecyclePreview 生命周期规则影响预览结果，不会修改任何对象
+type LifecyclePreview struct {
+	Rule             LifecycleRule `json:"rule"`
+	Mode             string        `json:"mode"`
+	Estimated        bool          `json:"estimated"` // 采样估算值
+	SamplePercent    float64       `json:"sample_percent,omitempty"`
+	Matched          ObjectSummary `json:"matched"`    // 规则范围内的全部对象
+	Expire           ObjectSummary `json:"expire"`     // 将被过期删除的对象
+	Transition       ObjectSummary `json:"transition"` // 将转换存储类别（且不会同时过期）的对象
+	ExpireBefore     *time.Time    `json:"expire_before,omitempty"`
+	TransitionBefore *time.Time    `json:"transition_before,omitempty"`
+	Examples         []string      `json:"examples"` // 最早受影响的对象键（有过期时为将过期的对象）
+	EvaluatedAt      time.Time     `json:"evaluated_at"`
+}
+
+// 对象级过期标签，由过期扫描器处理
+const (
+	TagExpiresAt       = "expires-at"        // 绝对过期时间（RFC3339）
+	TagExpireAfterDays = "expire-after-days" // 最后修改时间之后的过期天数
+)
+
+// 过期扫描中对象的处理结果
+const (
+	ExpiryActionEnqueued     = "enqueued"      // 已发出删除任务
+	ExpiryActionWouldEnqueue = "would_enqueue" // dry-run：将发出删除任务
+	ExpiryActionHeld         = "held"          // 受保留期或法律保留保护，跳过
+	ExpiryActionInvalid      = "invalid"       // 过期标签无效，跳过
+	ExpiryActionFailed       = "failed"        // 删除任务入队失败
+)
+
+// ObjectExpiry 根据对象过期标签计算过期时间，未设置时返回nil；两个标签都设置时取较早者
+func ObjectExpiry(metadata *Metadata) (*time.Time, error) {
+	var expiry *time.Time
+
+	if value, ok := metadata.Tags[TagExpiresAt]; ok {
+		at, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s tag %q, expected RFC3339", TagExpiresAt, value)
+		}
+		expiry = &at
+	}
+
+	if value, ok := metadata.Tags[TagExpireAfterDays]; ok {
+		days, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("invalid %s tag %q, expected non-negative integer", TagExpireAfterDays, value)
+		}
+		at := metadata.UpdatedAt.AddDate(0, 0, days)
+		if expiry == nil || at.Before(*expiry) {
+			expiry = &at
+		}
+	}
+
+	return expiry, nil
+}
+
+// ExpiredObject 过期扫描中已到期（或标签无效）的对象
+type ExpiredObject struct {
+	Bucket    string     `json:"bucket"`
+	Key       string     `json:"key"`
+	Version   int64      `json:"version"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Action    string     `json:"action"`
+	Reason    string     `json:"reason,omitempty"` // 跳过或失败原因
+	TaskID    string     `json:"task_id,omitempty"`
+}
+
+// ErrExpiryScannerDisabled 未启用对象过期扫描
+var ErrExpiryScannerDisabled = errors.New("expiry scanner is not enabled")
+
+// ExpiryScanResult 一次过期扫描的结果
+type ExpiryScanResult struct {
+	DryRun          bool             `json:"dry_run"`
+	StartedAt       time.Time        `json:"started_at"`
+	FinishedAt      time.Time        `json:"finished_at"`
+	DurationMs      int64            `json:"duration_ms"`
+	Scanned         int              `json:"scanned"`          // 带过期标签的对象
+	Expired         int              `json:"expired"`          // 已到期的对象
+	Enqueued        int              `json:"enqueued"`         // 本次发出（dry-run时为将发出）的删除任务
+	AlreadyEnqueued int              `json:"already_enqueued"` // 之前的扫描已为同一版本发出任务
+	Held            int              `json:"held"`
+	Invalid         int              `json:"invalid"`
+	Failed          int              `json:"failed"`
+	Truncated       bool             `json:"truncated"` // 达到单次任务数上限，剩余对象留给下一次扫描
+	Objects         []*ExpiredObject `json:"objects"`   // 受影响对象示例
+	Error           string           `json:"error,omitempty"`
+}
@@ -1,9 +1,20 @@
 package models
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
+// ErrMetadataNotFound 元数据不存在
+var ErrMetadataNotFound = errors.New("metadata not found")
+
+// ErrMetadataExists 同名元数据已存在
+var ErrMetadataExists = errors.New("metadata already exists")
+
 // Metadata 元数据模型
 type Metadata struct {
 	ID           string            `json:"id" db:"id"`
@@ -24,6 +35,13 @@ type Metadata struct {
 	DeletedAt    *time.Time        `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
+// MetadataVersion 元数据历史版本（更新前的快照）
+type MetadataVersion struct {
+	Metadata
+	HistoryID  int64     `json:"history_id" db:"history_id"`
+	ArchivedAt time.Time `json:"archived_at" db:"archived_at"`
+}
+
 // MetadataFilter 元数据过滤器
 type MetadataFilter struct {
 	Bucket      string            `json:"bucket,omitempty"`
@@ -35,6 +53,47 @@ type MetadataFilter struct {
 	SizeMax     *int64            `json:"size_max,omitempty"`
 	CreatedFrom *time.Time        `json:"created_from,omitempty"`
 	CreatedTo   *time.Time        `json:"created_to,omitempty"`
+
+	ModifiedAfter  *time.Time `json:"modified_after,omitempty"`  // updated_at > ModifiedAfter
+	ModifiedBefore *time.Time `json:"modified_before,omitempty"` // updated_at < ModifiedBefore
+
+	StartAfter string `json:"start_after,omitempty"` // key > StartAfter
+	EndKey     string `json:"end_key,omitempty"`     // key <= EndKey
+
+	TagQuery *TagExpr `json:"tag_query,omitempty"` // 标签查询表达式，如 env=staging AND team!=core
+
+	// Delimiter 非空时按分隔符分层列出：Prefix之后包含分隔符的key合并为公共前缀（伪目录）
+	Delimiter string `json:"delimiter,omitempty"`
+}
+
+// Validate 校验过滤条件中的范围
+func (f *MetadataFilter) Validate() error {
+	if (f.SizeMin != nil && *f.SizeMin < 0) || (f.SizeMax != nil && *f.SizeMax < 0) {
+		return fmt.Errorf("%w: size_min and size_max must not be negative", ErrInvalidArgument)
+	}
+	if f.SizeMin != nil && f.SizeMax != nil && *f.SizeMin > *f.SizeMax {
+		return fmt.Errorf("%w: size_min must not exceed size_max", ErrInvalidArgument)
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return fmt.Errorf("%w: created_from must not be after created_to", ErrInvalidArgument)
+	}
+	if f.ModifiedAfter != nil && f.ModifiedBefore != nil && !f.ModifiedAfter.Before(*f.ModifiedBefore) {
+		return fmt.Errorf("%w: modified_after must be before modified_before", ErrInvalidArgument)
+	}
+	if f.ContentType != "" && strings.Contains(strings.TrimSuffix(f.ContentType, "*"), "*") {
+		return fmt.Errorf("%w: content type filter only supports a trailing wildcard, e.g. image/*", ErrInvalidArgument)
+	}
+	return nil
+}
+
+// ContentTypePrefix 内容类型过滤为前缀通配（如image/*）时返回前缀
+func (f *MetadataFilter) ContentTypePrefix() (string, bool) {
+	return strings.CutSuffix(f.ContentType, "*")
+}
+
+// HasModifiedRange 是否按修改时间窗口过滤
+func (f *MetadataFilter) HasModifiedRange() bool {
+	return f.ModifiedAfter != nil || f.ModifiedBefore != nil
 }
 
 // Stats 统计信息
@@ -48,6 +107,28 @@ type Stats struct {
 	StatusCounts map[string]int64  `json:"status_counts"`
 	DailyUploads []DailyUploadStat `json:"daily_uploads"`
 	LastUpdated  time.Time         `json:"last_updated"`
+
+	// 按bucket汇总（含对象已全部删除、仍有软删除记录的bucket）
+	Buckets map[string]*BucketRollup `json:"buckets,omitempty"`
+
+	// 新鲜度：后台汇总时LastUpdated为汇总时间
+	Source        string     `json:"source"`                    // rollup（后台汇总）或 live（实时计算）
+	AgeSeconds    float64    `json:"age_seconds"`               // 距LastUpdated的秒数
+	NextRefreshAt *time.Time `json:"next_refresh_at,omitempty"` // 下一次计划汇总时间
+	Stale         bool       `json:"stale"`                     // 超过两个汇总周期未刷新
+}
+
+// 统计来源
+const (
+	StatsSourceRollup = "rollup"
+	StatsSourceLive   = "live"
+)
+
+// BucketRollup 单个bucket的统计汇总
+type BucketRollup struct {
+	Objects      int64      `json:"objects"`
+	Bytes        int64      `json:"bytes"`
+	LastActivity *time.Time `json:"last_activity,omitempty"` // 最近一次写入或删除时间
 }
 
 // DailyUploadStat 每日上传统计
@@ -57,6 +138,13 @@ type DailyUploadStat struct {
 	Size  int64  `json:"size"`
 }
 
+// TagCount 标签键值对及使用该标签的对象数
+type TagCount struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
 // MetadataBackup 元数据备份
 type MetadataBackup struct {
 	ID        string    `json:"id"`
@@ -79,3 +167,150 @@ type MetadataSyncEvent struct {
 	SourceNode   string            `json:"source_node"`
 	ReplicatedTo []string          `json:"replicated_to"`
 }
+
+// MetadataSearchQuery 元数据全文搜索查询
+type MetadataSearchQuery struct {
+	Query       string            `json:"query"`
+	Bucket      string            `json:"bucket,omitempty"`
+	ContentType string            `json:"content_type,omitempty"` // 前缀匹配，如 image/
+	Tags        map[string]string `json:"tags,omitempty"`         // 全部匹配
+	SizeMin     *int64            `json:"size_min,omitempty"`
+	SizeMax     *int64            `json:"size_max,omitempty"`
+	Limit       int               `json:"limit"`
+	Offset      int               `json:"offset"`
+}
+
+// MetadataSearchHit 搜索命中结果
+type MetadataSearchHit struct {
+	Metadata *Metadata `json:"metadata"`
+	Score    float64   `json:"score"`
+}
+
+// MetadataSearchResult 搜索结果
+type MetadataSearchResult struct {
+	Query   string               `json:"query"`
+	Backend string               `json:"backend"`
+	Hits    []*MetadataSearchHit `json:"hits"`
+	Total   int64                `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+}
+
+// MetadataCursor 列表游标（keyset分页位置）
+type MetadataCursor struct {
+	Bucket    string     `json:"b"`
+	Key       string     `json:"k"`
+	UpdatedAt *time.Time `json:"u,omitempty"` // 按修改时间排序时使用
+}
+
+// Encode 编码为continuation token
+func (c *MetadataCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeMetadataCursor 解析continuation token
+func DecodeMetadataCursor(token string) (*MetadataCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: continuation token: %v", ErrInvalidArgument, err)
+	}
+
+	var cursor MetadataCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: continuation token: %v", ErrInvalidArgument, err)
+	}
+
+	return &cursor, nil
+}
+
+// MetadataPage 游标分页结果
+type MetadataPage struct {
+	Metadata              []*Metadata `json:"metadata"`
+	CommonPrefixes        []string    `json:"common_prefixes,omitempty"` // 按分隔符列出时的伪目录
+	Count                 int         `json:"count"`                     // 本页条目数（对象与公共前缀之和）
+	Limit                 int         `json:"limit"`
+	IsTruncated           bool        `json:"is_truncated"`
+	ContinuationToken     string      `json:"continuation_token,omitempty"`
+	NextContinuationToken string      `json:"next_continuation_token,omitempty"`
+}
+
+// MetadataListEntry 分层列表中的一项：对象或公共前缀
+type MetadataListEntry struct {
+	Prefix   string    // 公共前缀（以分隔符结尾），为空时表示对象
+	Metadata *Metadata // 对象元数据
+}
+
+// Name 条目的排序键（公共前缀或对象key）
+func (e *MetadataListEntry) Name() string {
+	if e.Prefix != "" {
+		return e.Prefix
+	}
+	return e.Metadata.Key
+}
+
+// MetadataKeyRange 键空间分片范围 (StartAfter, EndKey]，空值表示无边界
+type MetadataKeyRange struct {
+	Index          int    `json:"index"`
+	StartAfter     string `json:"start_after,omitempty"`
+	EndKey         string `json:"end_key,omitempty"`
+	EstimatedCount int64  `json:"estimated_count"`
+}
+
+// MetadataShardPlan 列表分片计划
+type MetadataShardPlan struct {
+	Bucket       string              `json:"bucket"`
+	Prefix       string              `json:"prefix,omitempty"`
+	Shards       int                 `json:"shards"`
+	SampleSize   int                 `json:"sample_size"`
+	TotalObjects int64               `json:"total_objects"`
+	Ranges       []*MetadataKeyRange `json:"ranges"`
+}
+
+// 批量操作类型
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// 批量操作单项状态
+const (
+	BatchItemSucceeded = "succeeded"
+	BatchItemFailed    = "failed"
+	BatchItemAborted   = "aborted" // 原子模式下因其他项失败而回滚
+)
+
+// MetadataBatchOperation 批量操作项
+type MetadataBatchOperation struct {
+	Op       string    `json:"op"`               // create, update, delete
+	Bucket   string    `json:"bucket,omitempty"` // delete时使用
+	Key      string    `json:"key,omitempty"`    // delete时使用
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+// MetadataBatchRequest 批量操作请求
+type MetadataBatchRequest struct {
+	Operations []*MetadataBatchOperation `json:"operations"`
+	Atomic     bool                      `json:"atomic"` // 为true时任一项失败则整体回滚
+}
+
+// MetadataBatchItemResult 批量操作单项结果
+type MetadataBatchItemResult struct {
+	Index    int       `json:"index"`
+	Op       string    `json:"op"`
+	Bucket   string    `json:"bucket"`
+	Key      string    `json:"key"`
+	Status   string    `json:"status"`
+	Error    string    `json:"error,omitempty"`
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+// MetadataBatchResponse 批量操作结果
+type MetadataBatchResponse struct {
+	Results   []*MetadataBatchItemResult `json:"results"`
+	Succeeded int                        `json:"succeeded"`
+	Failed    int                        `json:"failed"`
+	Atomic    bool                       `json:"atomic"`
+	Committed bool                       `json:"committed"`
+}
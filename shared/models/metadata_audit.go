@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// 元数据审计操作
+const (
+	MetadataAuditCreate  = "create"
+	MetadataAuditUpdate  = "update"
+	MetadataAuditDelete  = "delete"
+	MetadataAuditRestore = "restore"
+	MetadataAuditImport  = "import"
+)
+
+// MetadataAuditEntry 一次元数据变更的审计记录，OldValue/NewValue为变更前后的完整元数据
+type MetadataAuditEntry struct {
+	ID         int64      `json:"id"`
+	Tenant     string     `json:"tenant"`
+	Bucket     string     `json:"bucket"`
+	Key        string     `json:"key"`
+	MetadataID string     `json:"metadata_id"`
+	Action     string     `json:"action"`
+	Actor      AuditActor `json:"actor"`
+	OldValue   *Metadata  `json:"old_value,omitempty"` // 创建和恢复时为空
+	NewValue   *Metadata  `json:"new_value,omitempty"` // 删除时为空
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// MetadataAuditQuery 审计记录查询条件，按ID升序分页
+type MetadataAuditQuery struct {
+	Bucket string     `json:"bucket,omitempty"`
+	Key    string     `json:"key,omitempty"`
+	Action string     `json:"action,omitempty"`
+	Since  *time.Time `json:"since,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+	After  int64      `json:"after,omitempty"` // 上一页最后一条记录的ID
+	Limit  int        `json:"limit,omitempty"`
+}
+
+// MetadataAuditPage 一页审计记录
+type MetadataAuditPage struct {
+	Entries     []*MetadataAuditEntry `json:"entries"`
+	IsTruncated bool                  `json:"is_truncated"`
+	NextAfter   int64                 `json:"next_after,omitempty"`
+}
@@ -0,0 +1,46 @@
+package models
+
+// 元数据导出/导入格式
+const (
+	MetadataFormatJSONL = "jsonl" // 每行一个Metadata JSON对象
+	MetadataFormatCSV   = "csv"   // 首行为列名，storage_nodes/headers/tags为JSON
+)
+
+// 导入时与已有记录（同bucket/key的未删除记录或同ID记录）冲突的处理方式
+const (
+	ImportConflictSkip      = "skip"      // 保留已有记录
+	ImportConflictOverwrite = "overwrite" // 删除已有记录后写入
+	ImportConflictFail      = "fail"      // 遇到冲突或无效记录时停止导入
+)
+
+// 单条记录的导入结果
+const (
+	ImportOutcomeCreated     = "created"
+	ImportOutcomeOverwritten = "overwritten"
+	ImportOutcomeSkipped     = "skipped"
+)
+
+// MetadataCSVColumns CSV导出的列
+var MetadataCSVColumns = []string{
+	"id", "bucket", "key", "size", "content_type", "md5_hash", "etag",
+	"storage_nodes", "headers", "tags", "status", "version", "created_at", "updated_at",
+}
+
+// MetadataImportError 导入失败的记录
+type MetadataImportError struct {
+	Line   int    `json:"line"` // 记录所在行号（CSV含列名行）
+	Bucket string `json:"bucket,omitempty"`
+	Key    string `json:"key,omitempty"`
+	Error  string `json:"error"`
+}
+
+// MetadataImportResult 导入结果
+type MetadataImportResult struct {
+	Total       int                    `json:"total"`       // 读取的记录数
+	Imported    int                    `json:"imported"`    // 写入的记录数（含覆盖）
+	Overwritten int                    `json:"overwritten"` // 覆盖已有记录的数量
+	Skipped     int                    `json:"skipped"`     // 因冲突跳过的数量
+	Failed      int                    `json:"failed"`      // 无效或写入失败的数量
+	Aborted     bool                   `json:"aborted"`     // fail模式下是否提前停止
+	Errors      []*MetadataImportError `json:"errors,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// MigrationStatus 单个数据库迁移的状态
+type MigrationStatus struct {
+	Version    int64      `json:"version"`
+	Name       string     `json:"name"`
+	Checksum   string     `json:"checksum"`
+	Applied    bool       `json:"applied"`
+	AppliedAt  *time.Time `json:"applied_at,omitempty"`
+	DurationMs int64      `json:"duration_ms,omitempty"`
+	Modified   bool       `json:"modified,omitempty"` // 应用后迁移文件被修改
+}
+
+// MigrationReport 数据库迁移状态汇总
+type MigrationReport struct {
+	CurrentVersion int64              `json:"current_version"`
+	LatestVersion  int64              `json:"latest_version"`
+	Pending        int                `json:"pending"`
+	Migrations     []*MigrationStatus `json:"migrations"`
+}
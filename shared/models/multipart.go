@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxPartNumber 分片编号上限（与S3一致）
+const MaxPartNumber = 10000
+
+// 分片上传相关的HTTP头
+const (
+	HeaderMultipartPartsCount = "X-Amz-Mp-Parts-Count"
+	// HeaderMultipartPartSizes 内部头：分片上传对象各分片大小（逗号分隔），GET/HEAD不返回
+	HeaderMultipartPartSizes = "X-Mocks3-Mp-Part-Sizes"
+)
+
+// 分片上传错误码
+const (
+	ErrCodeNoSuchUpload      = "NoSuchUpload"
+	ErrCodeInvalidPart       = "InvalidPart"
+	ErrCodeInvalidPartOrder  = "InvalidPartOrder"
+	ErrCodeInvalidPartNumber = "InvalidPartNumber"
+)
+
+// internalHeaderPrefix 仅供服务内部使用的对象头前缀
+const internalHeaderPrefix = "X-Mocks3-"
+
+// IsInternalHeader 是否为不对外返回的内部对象头
+func IsInternalHeader(name string) bool {
+	return strings.HasPrefix(name, internalHeaderPrefix)
+}
+
+// MultipartUpload 进行中的分片上传
+type MultipartUpload struct {
+	UploadID    string            `json:"upload_id"`
+	Bucket      string            `json:"bucket"`
+	Key         string            `json:"key"`
+	ContentType string            `json:"content_type,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Initiated   time.Time         `json:"initiated"`
+}
+
+// CompletedPart 完成分片上传时提交的分片
+type CompletedPart struct {
+	PartNumber int    `json:"part_number" xml:"PartNumber"`
+	ETag       string `json:"etag" xml:"ETag"`
+}
+
+// CompleteMultipartUploadRequest 完成分片上传请求（S3 CompleteMultipartUpload XML）
+type CompleteMultipartUploadRequest struct {
+	Parts []CompletedPart `json:"parts" xml:"Part"`
+}
+
+// PartRange 分片在对象中的字节范围
+type PartRange struct {
+	PartNumber int   `json:"part_number"`
+	PartsCount int   `json:"parts_count"` // 非分片上传对象为0
+	Offset     int64 `json:"offset"`
+	Length     int64 `json:"length"`
+}
+
+// FormatPartSizes 编码分片大小列表
+func FormatPartSizes(sizes []int64) string {
+	values := make([]string, len(sizes))
+	for i, size := range sizes {
+		values[i] = strconv.FormatInt(size, 10)
+	}
+	return strings.Join(values, ",")
+}
+
+// ObjectPartRange 计算对象中指定分片的字节范围
+//
+// 非分片上传的对象视为只有一个分片；分片编号超出范围时返回InvalidPartNumber错误。
+func ObjectPartRange(headers map[string]string, size int64, partNumber int) (*PartRange, error) {
+	if partNumber < 1 || partNumber > MaxPartNumber {
+		return nil, &APIError{Code: ErrCodeInvalidArgument, Message: "part number must be an integer between 1 and 10000"}
+	}
+
+	encoded := headers[HeaderMultipartPartSizes]
+	if encoded == "" {
+		if partNumber != 1 {
+			return nil, &APIError{Code: ErrCodeInvalidPartNumber, Message: "the requested partnumber is not satisfiable"}
+		}
+		return &PartRange{PartNumber: 1, Length: size}, nil
+	}
+
+	values := strings.Split(encoded, ",")
+	if partNumber > len(values) {
+		return nil, &APIError{Code: ErrCodeInvalidPartNumber, Message: "the requested partnumber is not satisfiable"}
+	}
+
+	var offset int64
+	for i, value := range values {
+		partSize, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || partSize < 0 {
+			return nil, fmt.Errorf("invalid part sizes header: %q", encoded)
+		}
+		if i+1 == partNumber {
+			if offset+partSize > size {
+				return nil, fmt.Errorf("part %d exceeds object size %d", partNumber, size)
+			}
+			return &PartRange{PartNumber: partNumber, PartsCount: len(values), Offset: offset, Length: partSize}, nil
+		}
+		offset += partSize
+	}
+
+	return nil, &APIError{Code: ErrCodeInvalidPartNumber, Message: "the requested partnumber is not satisfiable"}
+}
@@ -1,9 +1,18 @@
 package models
 
 import (
+	"encoding/xml"
+	"errors"
+	"sort"
 	"time"
 )
 
+// ErrAppendPositionMismatch 追加位置与对象当前大小不一致
+var ErrAppendPositionMismatch = errors.New("position mismatch")
+
+// ErrObjectNotFound 存储节点上没有该对象
+var ErrObjectNotFound = errors.New("object not found")
+
 // Object 对象模型
 type Object struct {
 	ID           string            `json:"id" db:"id"`
@@ -36,6 +45,28 @@ type ObjectInfo struct {
 	UpdatedAt   time.Time         `json:"updated_at"`
 }
 
+// Tagging 对象标签（S3 GetObjectTagging XML）
+type Tagging struct {
+	XMLName xml.Name `json:"-" xml:"Tagging"`
+	TagSet  []Tag    `json:"tag_set" xml:"TagSet>Tag"`
+}
+
+// Tag 单个标签
+type Tag struct {
+	Key   string `json:"key" xml:"Key"`
+	Value string `json:"value" xml:"Value"`
+}
+
+// NewTagging 按标签名排序构建标签集
+func NewTagging(tags map[string]string) *Tagging {
+	tagging := &Tagging{TagSet: make([]Tag, 0, len(tags))}
+	for key, value := range tags {
+		tagging.TagSet = append(tagging.TagSet, Tag{Key: key, Value: value})
+	}
+	sort.Slice(tagging.TagSet, func(i, j int) bool { return tagging.TagSet[i].Key < tagging.TagSet[j].Key })
+	return tagging
+}
+
 // UploadRequest 上传请求
 type UploadRequest struct {
 	Key         string            `json:"key" binding:"required"`
@@ -48,15 +79,16 @@ type UploadRequest struct {
 
 // UploadResponse 上传响应
 type UploadResponse struct {
-	Success   bool   `json:"success"`
-	ObjectID  string `json:"object_id,omitempty"`
-	Key       string `json:"key,omitempty"`
-	Bucket    string `json:"bucket,omitempty"`
-	Size      int64  `json:"size,omitempty"`
-	MD5Hash   string `json:"md5_hash,omitempty"`
-	ETag      string `json:"etag,omitempty"`
-	Message   string `json:"message,omitempty"`
-	Timestamp string `json:"timestamp,omitempty"`
+	Success     bool             `json:"success"`
+	ObjectID    string           `json:"object_id,omitempty"`
+	Key         string           `json:"key,omitempty"`
+	Bucket      string           `json:"bucket,omitempty"`
+	Size        int64            `json:"size,omitempty"`
+	MD5Hash     string           `json:"md5_hash,omitempty"`
+	ETag        string           `json:"etag,omitempty"`
+	DuplicateOf *ObjectReference `json:"duplicate_of,omitempty"` // 写入去重命中时的已有对象
+	Message     string           `json:"message,omitempty"`
+	Timestamp   string           `json:"timestamp,omitempty"`
 }
 
 // ListObjectsRequest 列表请求
@@ -97,3 +129,53 @@ type SearchObjectsResponse struct {
 	Limit   int          `json:"limit"`
 	Offset  int          `json:"offset"`
 }
+
+// 复制指令（x-amz-metadata-directive / x-amz-tagging-directive）
+const (
+	CopyDirectiveCopy    = "COPY"    // 使用源对象的值
+	CopyDirectiveReplace = "REPLACE" // 使用请求中的值
+	CopyDirectiveMerge   = "MERGE"   // 以源对象为基础，请求中的值覆盖同名项
+)
+
+// CopyObjectRequest 复制对象请求
+type CopyObjectRequest struct {
+	SourceBucket string `json:"source_bucket"`
+	SourceKey    string `json:"source_key"`
+	DestBucket   string `json:"dest_bucket"`
+	DestKey      string `json:"dest_key"`
+
+	// 复制条件（x-amz-copy-source-if-*）
+	IfMatch           string     `json:"if_match,omitempty"`
+	IfNoneMatch       string     `json:"if_none_match,omitempty"`
+	IfModifiedSince   *time.Time `json:"if_modified_since,omitempty"`
+	IfUnmodifiedSince *time.Time `json:"if_unmodified_since,omitempty"`
+
+	MetadataDirective string            `json:"metadata_directive,omitempty"`
+	TaggingDirective  string            `json:"tagging_directive,omitempty"`
+	ContentType       string            `json:"content_type,omitempty"`
+	Headers           map[string]string `json:"headers,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+}
+
+// CopyObjectResult 复制对象结果
+type CopyObjectResult struct {
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// DeleteObjectRequest 删除对象请求
+type DeleteObjectRequest struct {
+	Bucket                    string `json:"bucket"`
+	Key                       string `json:"key"`
+	BypassGovernanceRetention bool   `json:"bypass_governance_retention,omitempty"` // x-amz-bypass-governance-retention
+}
+
+// ObjectReplica 对象在单个存储节点上的副本状态
+type ObjectReplica struct {
+	NodeID  string `json:"node_id"`
+	Healthy bool   `json:"healthy"`
+	Exists  bool   `json:"exists"`
+	Size    int64  `json:"size"`
+	MD5Hash string `json:"md5_hash,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
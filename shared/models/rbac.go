@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// 管理角色，按列出顺序能力递增，高级角色拥有低级角色的全部能力
+const (
+	RoleViewer     = "viewer"      // 只读查看管理接口和错误注入规则
+	RoleOperator   = "operator"    // 运维变更（限流套餐、统计刷新、复制等）
+	RoleChaosAdmin = "chaos-admin" // 管理错误注入规则
+	RoleSuperAdmin = "super-admin" // 管理API key
+)
+
+// 管理能力
+const (
+	CapabilityAdminRead     = "admin:read"  // 读取管理接口
+	CapabilityAdminWrite    = "admin:write" // 修改管理接口
+	CapabilityRulesRead     = "rules:read"  // 查看错误注入规则、统计和事件
+	CapabilityRulesWrite    = "rules:write" // 创建/修改/删除错误注入规则，重置注入统计
+	CapabilityKeysManage    = "keys:manage" // 创建/删除API key
+	CapabilityAuthenticated = ""            // 任意有效API key
+)
+
+// Roles 按能力递增排列的角色
+var Roles = []string{RoleViewer, RoleOperator, RoleChaosAdmin, RoleSuperAdmin}
+
+// RoleCapabilities 各角色拥有的能力
+var RoleCapabilities = map[string][]string{
+	RoleViewer:     {CapabilityAdminRead, CapabilityRulesRead},
+	RoleOperator:   {CapabilityAdminRead, CapabilityRulesRead, CapabilityAdminWrite},
+	RoleChaosAdmin: {CapabilityAdminRead, CapabilityRulesRead, CapabilityAdminWrite, CapabilityRulesWrite},
+	RoleSuperAdmin: {CapabilityAdminRead, CapabilityRulesRead, CapabilityAdminWrite, CapabilityRulesWrite, CapabilityKeysManage},
+}
+
+// RoleHasCapability 检查角色是否拥有能力
+func RoleHasCapability(role, capability string) bool {
+	capabilities, ok := RoleCapabilities[role]
+	if !ok {
+		return false
+	}
+	if capability == CapabilityAuthenticated {
+		return true
+	}
+	for _, c := range capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidRole 检查角色是否存在
+func IsValidRole(role string) bool {
+	_, ok := RoleCapabilities[role]
+	return ok
+}
+
+// APIKey 管理API key
+type APIKey struct {
+	Name      string    `json:"name"`
+	Key       string    `json:"key,omitempty"` // 仅在创建时返回完整key
+	KeyPrefix string    `json:"key_prefix"`    // 用于识别key的前缀
+	Role      string    `json:"role"`
+	Tenant    string    `json:"tenant,omitempty"` // 绑定的租户，设置后该key的请求只能访问此租户
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RoleInfo 角色及其能力
+type RoleInfo struct {
+	Role         string   `json:"role"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// AccessDenied 鉴权失败（401/403）响应体
+type AccessDenied struct {
+	Error              string `json:"error"`
+	Code               string `json:"code"` // Unauthorized, AccessDenied
+	Role               string `json:"role,omitempty"`
+	RequiredCapability string `json:"required_capability,omitempty"`
+	Method             string `json:"method"`
+	Path               string `json:"path"`
+}
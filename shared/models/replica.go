@@ -0,0 +1,36 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// ReplicaStatus 只读副本状态
+type ReplicaStatus struct {
+	Name          string    `json:"name"`
+	Available     bool      `json:"available"` // 可达且延迟未超过阈值，参与读请求路由
+	LagSeconds    float64   `json:"lag_seconds"`
+	Error         string    `json:"error,omitempty"`
+	LastCheckedAt time.Time `json:"last_checked_at"`
+}
+
+// ReplicaReport 读副本路由状态汇总
+type ReplicaReport struct {
+	MaxLagSeconds int              `json:"max_lag_seconds"` // 0表示不检查延迟
+	Available     int              `json:"available"`
+	Replicas      []*ReplicaStatus `json:"replicas"`
+}
+
+// primaryReadContextKey context中标记读主库的键
+type primaryReadContextKey struct{}
+
+// WithPrimaryRead 返回要求读请求走主库的context，用于写入前的读取（读己之写）
+func WithPrimaryRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadContextKey{}, true)
+}
+
+// IsPrimaryRead 判断context是否要求读主库
+func IsPrimaryRead(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadContextKey{}).(bool)
+	return primary
+}
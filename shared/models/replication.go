@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// 复制状态头（与S3一致，副本对象为REPLICA）
+const (
+	HeaderReplicationStatus  = "X-Amz-Replication-Status"
+	ReplicationStatusReplica = "REPLICA"
+)
+
+// 复制失败原因，注入的失败使用mock-error动作类型（如network_error、timeout）
+const (
+	ReplicationFailureQueueFull        = "queue_full"        // 复制队列已满，对象未进入复制
+	ReplicationFailureSourceRead       = "source_read"       // 读取源对象失败
+	ReplicationFailureDestinationWrite = "destination_write" // 写入目标bucket失败
+)
+
+// ReplicationDestinationStatus 单个复制目标的状态
+type ReplicationDestinationStatus struct {
+	Destination      string           `json:"destination"`
+	SourceBuckets    []string         `json:"source_buckets"`
+	Pending          int64            `json:"pending"`          // 等待复制（含等待重试）的对象数
+	Replicated       int64            `json:"replicated"`       // 已完成复制的对象数
+	Failed           int64            `json:"failed"`           // 重试耗尽后放弃的对象数
+	Failures         map[string]int64 `json:"failures"`         // 失败原因 -> 失败次数（每次尝试计一次）
+	LagSeconds       float64          `json:"lag_seconds"`      // 当前延迟：有待复制对象时为最早待复制对象的等待时长，否则为最近一次复制延迟
+	LastLagSeconds   float64          `json:"last_lag_seconds"` // 最近一次完成复制的延迟
+	MaxLagSeconds    float64          `json:"max_lag_seconds"`  // 完成复制的最大延迟
+	OldestPendingAt  *time.Time       `json:"oldest_pending_at,omitempty"`
+	LastReplicatedAt *time.Time       `json:"last_replicated_at,omitempty"`
+}
+
+// ReplicationReport 复制状态报告
+type ReplicationReport struct {
+	Destinations []*ReplicationDestinationStatus `json:"destinations"`
+	GeneratedAt  time.Time                       `json:"generated_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"context"
+	"regexp"
+)
+
+// HeaderRequestID 请求ID请求头，入口服务生成，服务间调用时透传，未开启追踪时也能关联同一请求的日志
+const HeaderRequestID = "X-Request-ID"
+
+// requestIDPattern 请求ID：可见ASCII字符，不超过128个字符
+var requestIDPattern = regexp.MustCompile(`^[\x21-\x7e]{1,128}$`)
+
+// IsValidRequestID 检查上游传入的请求ID是否可用
+func IsValidRequestID(id string) bool {
+	return requestIDPattern.MatchString(id)
+}
+
+// requestIDContextKey context中保存请求ID的键
+type requestIDContextKey struct{}
+
+// WithRequestID 返回携带请求ID的context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext 获取context中的请求ID，未设置时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
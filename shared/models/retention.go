@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCodeRetentionViolation 对象仍在bucket最短保留期内，拒绝删除
+const ErrCodeRetentionViolation = "RetentionViolation"
+
+// ErrBucketRetentionNotFound bucket没有保留策略
+var ErrBucketRetentionNotFound = errors.New("bucket retention not found")
+
+// HoldReasonBucketRetention bucket保留策略阻止删除
+const HoldReasonBucketRetention = "bucket_retention"
+
+// MaxRetentionDays bucket最短保留天数上限
+const MaxRetentionDays = 36500
+
+// BucketRetention bucket级保留策略：对象创建后至少保留MinRetentionDays天，期间拒绝删除
+type BucketRetention struct {
+	Bucket           string    `json:"bucket"`
+	MinRetentionDays int       `json:"min_retention_days"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// RetainUntil 创建于createdAt的对象在该策略下的最早可删除时间
+func (r *BucketRetention) RetainUntil(createdAt time.Time) time.Time {
+	return createdAt.AddDate(0, 0, r.MinRetentionDays)
+}
+
+// RetentionViolationError 删除的对象仍在bucket保留期内
+type RetentionViolationError struct {
+	Bucket           string    `json:"bucket"`
+	Key              string    `json:"key"`
+	MinRetentionDays int       `json:"min_retention_days"`
+	CreatedAt        time.Time `json:"created_at"`
+	RetainUntil      time.Time `json:"retain_until"`
+}
+
+// Error 实现error接口
+func (e *RetentionViolationError) Error() string {
+	return fmt.Sprintf("%s: object %s/%s is retained by bucket policy (%d days) until %s",
+		ErrCodeRetentionViolation, e.Bucket, e.Key, e.MinRetentionDays, e.RetainUntil.UTC().Format(time.RFC3339))
+}
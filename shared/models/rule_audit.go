@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// 错误规则审计操作
+const (
+	RuleAuditCreate  = "create"
+	RuleAuditUpdate  = "update"
+	RuleAuditDelete  = "delete"
+	RuleAuditEnable  = "enable"
+	RuleAuditDisable = "disable"
+)
+
+// RuleAuditEntry 一次错误规则变更的审计记录，Before/After为变更前后的完整规则
+type RuleAuditEntry struct {
+	ID        int64              `json:"id"`
+	RuleID    string             `json:"rule_id"`
+	RuleName  string             `json:"rule_name"`
+	Action    string             `json:"action"`
+	Actor     AuditActor         `json:"actor"`
+	Before    *ErrorRule         `json:"before,omitempty"` // 创建时为空
+	After     *ErrorRule         `json:"after,omitempty"`  // 删除时为空
+	Changes   []*RuleFieldChange `json:"changes,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// RuleFieldChange 规则顶层字段的变更，不包括触发次数和时间戳等运行时字段
+type RuleFieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// RuleAuditQuery 规则审计记录查询条件，按ID升序分页
+type RuleAuditQuery struct {
+	RuleID string     `json:"rule_id,omitempty"`
+	Action string     `json:"action,omitempty"`
+	Since  *time.Time `json:"since,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+	After  int64      `json:"after,omitempty"` // 上一页最后一条记录的ID
+	Limit  int        `json:"limit,omitempty"`
+}
+
+// RuleAuditPage 一页规则审计记录
+type RuleAuditPage struct {
+	Entries     []*RuleAuditEntry `json:"entries"`
+	IsTruncated bool              `json:"is_truncated"`
+	NextAfter   int64             `json:"next_after,omitempty"`
+}
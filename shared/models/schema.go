@@ -0,0 +1,77 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrCodeSchemaViolation 自定义元数据不符合bucket注册的schema
+const ErrCodeSchemaViolation = "MetadataSchemaViolation"
+
+// ErrBucketSchemaNotFound bucket没有注册schema
+var ErrBucketSchemaNotFound = errors.New("bucket schema not found")
+
+// MaxBucketSchemaSize bucket schema文档的最大字节数
+const MaxBucketSchemaSize = 32 * 1024
+
+// BucketSchema bucket注册的JSON Schema，用于校验对象的自定义元数据
+//
+// 校验的文档形如 {"headers": {...}, "tags": {...}}，未设置的部分为空对象。
+type BucketSchema struct {
+	Bucket    string          `json:"bucket"`
+	Schema    json.RawMessage `json:"schema"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// SchemaDocument 构造用于schema校验的自定义元数据文档
+func SchemaDocument(metadata *Metadata) map[string]interface{} {
+	toObject := func(values map[string]string) map[string]interface{} {
+		object := make(map[string]interface{}, len(values))
+		for k, v := range values {
+			object[k] = v
+		}
+		return object
+	}
+	return map[string]interface{}{
+		"headers": toObject(metadata.Headers),
+		"tags":    toObject(metadata.Tags),
+	}
+}
+
+// SchemaViolation 一条schema校验失败记录
+type SchemaViolation struct {
+	Path    string `json:"path"`    // 文档中的JSON Pointer，如 /tags/env
+	Keyword string `json:"keyword"` // 未通过的schema关键字
+	Message string `json:"message"`
+}
+
+// SchemaValidationError 写入的自定义元数据不符合bucket schema
+type SchemaValidationError struct {
+	Bucket     string            `json:"bucket"`
+	Key        string            `json:"key"`
+	Violations []SchemaViolation `json:"violations"`
+}
+
+// maxViolationsInMessage 错误信息中最多列出的违规数，完整列表见Violations
+const maxViolationsInMessage = 5
+
+// Error 实现error接口
+func (e *SchemaValidationError) Error() string {
+	parts := make([]string, 0, maxViolationsInMessage+1)
+	for i, violation := range e.Violations {
+		if i == maxViolationsInMessage {
+			parts = append(parts, fmt.Sprintf("and %d more", len(e.Violations)-i))
+			break
+		}
+		path := violation.Path
+		if path == "" {
+			path = "/"
+		}
+		parts = append(parts, path+": "+violation.Message)
+	}
+	return fmt.Sprintf("%s: invalid metadata for %s/%s: %s",
+		ErrCodeSchemaViolation, e.Bucket, e.Key, strings.Join(parts, "; "))
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -50,6 +52,43 @@ type RateLimit struct {
 	UpdatedAt time.Time     `json:"updated_at"`
 }
 
+// RatePlan 限流套餐
+type RatePlan struct {
+	Name              string    `json:"name"`
+	RequestsPerSecond float64   `json:"requests_per_second"` // 0表示不限速
+	Burst             int       `json:"burst"`               // 令牌桶容量，默认为每秒请求数
+	MaxConcurrent     int       `json:"max_concurrent"`      // 0表示不限制并发
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// RatePlanUsage 租户的套餐使用情况
+type RatePlanUsage struct {
+	Tenant              string     `json:"tenant"`
+	Plan                string     `json:"plan"`
+	Allowed             int64      `json:"allowed"`
+	Throttled           int64      `json:"throttled"`
+	ConcurrencyRejected int64      `json:"concurrency_rejected"`
+	InFlight            int        `json:"in_flight"`
+	Remaining           int64      `json:"remaining"`
+	LastRequestAt       *time.Time `json:"last_request_at,omitempty"`
+}
+
+// RateLimitExceeded 限流拒绝（429）响应体
+type RateLimitExceeded struct {
+	Error             string    `json:"error"`
+	Code              string    `json:"code"`
+	Reason            string    `json:"reason"` // rate, concurrency
+	Tenant            string    `json:"tenant"`
+	Plan              string    `json:"plan"`
+	RequestsPerSecond float64   `json:"requests_per_second"`
+	Burst             int       `json:"burst"`
+	MaxConcurrent     int       `json:"max_concurrent,omitempty"`
+	Remaining         int64     `json:"remaining"`
+	ResetAt           time.Time `json:"reset_at"`
+	RetryAfterSeconds int       `json:"retry_after_seconds"`
+}
+
 // AuthToken 认证令牌
 type AuthToken struct {
 	Token     string            `json:"token"`
@@ -78,6 +117,27 @@ type APIError struct {
 	Details string `json:"details,omitempty"`
 }
 
+// Error 实现error接口
+func (e *APIError) Error() string {
+	if e.Details != "" {
+		return fmt.Sprintf("%s: %s (%s)", e.Code, e.Message, e.Details)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// S3兼容错误码
+const (
+	ErrCodeNoSuchKey          = "NoSuchKey"
+	ErrCodePreconditionFailed = "PreconditionFailed"
+	ErrCodeInvalidArgument    = "InvalidArgument"
+	ErrCodeInvalidRequest     = "InvalidRequest"
+	ErrCodeAccessDenied       = "AccessDenied"
+	ErrCodeSlowDown           = "SlowDown"
+)
+
+// ErrInvalidArgument 请求参数无效，handler据此返回400
+var ErrInvalidArgument = errors.New("invalid argument")
+
 // HealthCheckResponse 健康检查响应
 type HealthCheckResponse struct {
 	Status    HealthStatus           `json:"status"`
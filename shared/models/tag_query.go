@@ -0,0 +1,365 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// 标签查询表达式限制
+const (
+	maxTagQueryLength = 4096
+	maxTagQueryDepth  = 32
+)
+
+// 标签表达式操作符
+const (
+	TagExprAnd    = "and"
+	TagExprOr     = "or"
+	TagExprNot    = "not"
+	TagExprEq     = "eq"     // key=value
+	TagExprNe     = "ne"     // key!=value（标签不存在或值不同）
+	TagExprExists = "exists" // key（标签存在）
+)
+
+// TagExpr 标签查询表达式树
+//
+// 语法（关键字不区分大小写，AND优先级高于OR）：
+//
+//	expr   := or
+//	or     := and { OR and }
+//	and    := unary { AND unary }
+//	unary  := NOT unary | "(" expr ")" | term
+//	term   := key "=" value | key "!=" value | key
+//
+// key/value为不含空白和 ()=!" 的单词，或双引号字符串（支持\"和\\转义）。
+// 例如：env=staging AND team!=core、(env=prod OR env=staging) AND NOT legacy
+type TagExpr struct {
+	Op       string     `json:"op"`
+	Key      string     `json:"key,omitempty"`
+	Value    string     `json:"value,omitempty"`
+	Children []*TagExpr `json:"children,omitempty"`
+}
+
+// Matches 判断标签是否满足表达式
+func (e *TagExpr) Matches(tags map[string]string) bool {
+	switch e.Op {
+	case TagExprAnd:
+		for _, child := range e.Children {
+			if !child.Matches(tags) {
+				return false
+			}
+		}
+		return true
+	case TagExprOr:
+		for _, child := range e.Children {
+			if child.Matches(tags) {
+				return true
+			}
+		}
+		return false
+	case TagExprNot:
+		return !e.Children[0].Matches(tags)
+	case TagExprEq:
+		value, ok := tags[e.Key]
+		return ok && value == e.Value
+	case TagExprNe:
+		value, ok := tags[e.Key]
+		return !ok || value != e.Value
+	case TagExprExists:
+		_, ok := tags[e.Key]
+		return ok
+	}
+	return false
+}
+
+// String 规范化表达式文本
+func (e *TagExpr) String() string {
+	switch e.Op {
+	case TagExprAnd, TagExprOr:
+		parts := make([]string, len(e.Children))
+		for i, child := range e.Children {
+			parts[i] = child.String()
+			if child.Op == TagExprOr || (child.Op == TagExprAnd && e.Op == TagExprOr) {
+				parts[i] = "(" + parts[i] + ")"
+			}
+		}
+		return strings.Join(parts, " "+strings.ToUpper(e.Op)+" ")
+	case TagExprNot:
+		child := e.Children[0].String()
+		if e.Children[0].Op == TagExprAnd || e.Children[0].Op == TagExprOr {
+			child = "(" + child + ")"
+		}
+		return "NOT " + child
+	case TagExprEq:
+		return quoteTagToken(e.Key) + "=" + quoteTagToken(e.Value)
+	case TagExprNe:
+		return quoteTagToken(e.Key) + "!=" + quoteTagToken(e.Value)
+	case TagExprExists:
+		return quoteTagToken(e.Key)
+	}
+	return ""
+}
+
+// ParseTagQuery 解析标签查询表达式
+func ParseTagQuery(query string) (*TagExpr, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("%w: tag query expression is required", ErrInvalidArgument)
+	}
+	if len(query) > maxTagQueryLength {
+		return nil, fmt.Errorf("%w: tag query exceeds %d bytes", ErrInvalidArgument, maxTagQueryLength)
+	}
+
+	tokens, err := tokenizeTagQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tag query: %v", ErrInvalidArgument, err)
+	}
+
+	p := &tagQueryParser{tokens: tokens}
+	expr, err := p.parseOr(0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tag query: %v", ErrInvalidArgument, err)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: tag query: unexpected %q at position %d", ErrInvalidArgument, p.tokens[p.pos].text, p.tokens[p.pos].offset)
+	}
+	return expr, nil
+}
+
+// 词法单元类型
+const (
+	tagTokWord = iota
+	tagTokString
+	tagTokEq
+	tagTokNe
+	tagTokLParen
+	tagTokRParen
+)
+
+// tagToken 词法单元
+type tagToken struct {
+	kind   int
+	text   string
+	offset int
+}
+
+// isKeyword 是否为指定关键字（仅非引号单词）
+func (t tagToken) isKeyword(keyword string) bool {
+	return t.kind == tagTokWord && strings.EqualFold(t.text, keyword)
+}
+
+// tokenizeTagQuery 词法分析
+func tokenizeTagQuery(query string) ([]tagToken, error) {
+	var tokens []tagToken
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, tagToken{kind: tagTokLParen, text: "(", offset: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, tagToken{kind: tagTokRParen, text: ")", offset: i})
+			i++
+		case r == '=':
+			tokens = append(tokens, tagToken{kind: tagTokEq, text: "=", offset: i})
+			i++
+		case r == '!':
+			if i+1 >= len(runes) || runes[i+1] != '=' {
+				return nil, fmt.Errorf("expected '=' after '!' at position %d", i)
+			}
+			tokens = append(tokens, tagToken{kind: tagTokNe, text: "!=", offset: i})
+			i += 2
+		case r == '"':
+			start := i
+			var b strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+					b.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			tokens = append(tokens, tagToken{kind: tagTokString, text: b.String(), offset: start})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()=!"`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, tagToken{kind: tagTokWord, text: string(runes[start:i]), offset: start})
+		}
+	}
+
+	return tokens, nil
+}
+
+// tagQueryParser 递归下降解析器
+type tagQueryParser struct {
+	tokens []tagToken
+	pos    int
+}
+
+// peek 查看当前词法单元
+func (p *tagQueryParser) peek() (tagToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return tagToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// parseOr or := and { OR and }
+func (p *tagQueryParser) parseOr(depth int) (*TagExpr, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	children := []*TagExpr{left}
+	for {
+		tok, ok := p.peek()
+		if !ok || !tok.isKeyword("OR") {
+			break
+		}
+		p.pos++
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &TagExpr{Op: TagExprOr, Children: children}, nil
+}
+
+// parseAnd and := unary { AND unary }
+func (p *tagQueryParser) parseAnd(depth int) (*TagExpr, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	children := []*TagExpr{left}
+	for {
+		tok, ok := p.peek()
+		if !ok || !tok.isKeyword("AND") {
+			break
+		}
+		p.pos++
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &TagExpr{Op: TagExprAnd, Children: children}, nil
+}
+
+// parseUnary unary := NOT unary | "(" expr ")" | term
+func (p *tagQueryParser) parseUnary(depth int) (*TagExpr, error) {
+	if depth > maxTagQueryDepth {
+		return nil, fmt.Errorf("expression nested deeper than %d levels", maxTagQueryDepth)
+	}
+
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch {
+	case tok.isKeyword("NOT"):
+		p.pos++
+		child, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &TagExpr{Op: TagExprNot, Children: []*TagExpr{child}}, nil
+	case tok.kind == tagTokLParen:
+		p.pos++
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := p.peek()
+		if !ok || closing.kind != tagTokRParen {
+			return nil, fmt.Errorf("missing ')' for '(' at position %d", tok.offset)
+		}
+		p.pos++
+		return expr, nil
+	}
+
+	return p.parseTerm()
+}
+
+// parseTerm term := key "=" value | key "!=" value | key
+func (p *tagQueryParser) parseTerm() (*TagExpr, error) {
+	keyTok, _ := p.peek()
+	if keyTok.kind != tagTokWord && keyTok.kind != tagTokString {
+		return nil, fmt.Errorf("expected tag key at position %d, got %q", keyTok.offset, keyTok.text)
+	}
+	if keyTok.kind == tagTokWord && (keyTok.isKeyword("AND") || keyTok.isKeyword("OR")) {
+		return nil, fmt.Errorf("expected tag key at position %d, got keyword %s", keyTok.offset, keyTok.text)
+	}
+	if keyTok.text == "" {
+		return nil, fmt.Errorf("empty tag key at position %d", keyTok.offset)
+	}
+	p.pos++
+
+	opTok, ok := p.peek()
+	if !ok || (opTok.kind != tagTokEq && opTok.kind != tagTokNe) {
+		return &TagExpr{Op: TagExprExists, Key: keyTok.text}, nil
+	}
+	p.pos++
+
+	valueTok, ok := p.peek()
+	if !ok || (valueTok.kind != tagTokWord && valueTok.kind != tagTokString) {
+		return nil, fmt.Errorf("expected value after %q for key %q", opTok.text, keyTok.text)
+	}
+	p.pos++
+
+	op := TagExprEq
+	if opTok.kind == tagTokNe {
+		op = TagExprNe
+	}
+	return &TagExpr{Op: op, Key: keyTok.text, Value: valueTok.text}, nil
+}
+
+// quoteTagToken 必要时为key/value加引号
+func quoteTagToken(s string) string {
+	needsQuote := s == ""
+	for _, r := range s {
+		if unicode.IsSpace(r) || strings.ContainsRune(`()=!"\`, r) {
+			needsQuote = true
+			break
+		}
+	}
+	switch strings.ToUpper(s) {
+	case "AND", "OR", "NOT":
+		needsQuote = true
+	}
+	if !needsQuote {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
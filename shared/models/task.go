@@ -1,28 +1,41 @@
 package models
 
 import (
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 )
 
+// ErrTaskNotFound 任务不存在
+var ErrTaskNotFound = errors.New("task not found")
+
 // Task 任务模型
 type Task struct {
 	ID          string                 `json:"id"`
 	Type        string                 `json:"type"`         // task type
 	Queue       string                 `json:"queue"`        // queue name
+	Tenant      string                 `json:"tenant"`       // owning tenant
 	ObjectKey   string                 `json:"object_key"`   // related object key
 	Data        map[string]interface{} `json:"data"`         // task payload
 	Priority    int                    `json:"priority"`     // task priority (higher number = higher priority)
 	MaxRetries  int                    `json:"max_retries"`  // maximum retry attempts
-	RetryCount  int                    `json:"retry_count"`  // current retry count
+	RetryCount  int                    `json:"retry_count"`  // failed attempts so far
 	Status      TaskStatus             `json:"status"`       // task status
-	ScheduledAt time.Time              `json:"scheduled_at"` // when to execute
+	ScheduledAt time.Time              `json:"scheduled_at"` // when to execute (next attempt for retrying tasks)
 	StartedAt   *time.Time             `json:"started_at,omitempty"`
 	CompletedAt *time.Time             `json:"completed_at,omitempty"`
 	FailedAt    *time.Time             `json:"failed_at,omitempty"`
-	Error       string                 `json:"error,omitempty"`
+	Error       string                 `json:"error,omitempty"` // error of the last failed attempt
 	WorkerID    string                 `json:"worker_id,omitempty"`
-	StreamID    string                 `json:"stream_id,omitempty"` // Redis stream message ID
+	Progress    *int                   `json:"progress,omitempty"`     // progress of the current attempt (0-100) reported by the worker
+	StreamID    string                 `json:"stream_id,omitempty"`    // Redis stream message ID
+	CallbackURL string                 `json:"callback_url,omitempty"` // receives a JobEvent when the job is done, failed or expired
+	ExpiresAt   *time.Time             `json:"expires_at,omitempty"`   // dropped instead of processed if not started by then
+	Output      map[string]interface{} `json:"output,omitempty"`       // result of the completed attempt, merged into the data of next tasks
+	Next        []*Task                `json:"next,omitempty"`         // enqueued when this task completes
+	ParentID    string                 `json:"parent_id,omitempty"`    // task whose completion enqueued this one
+	WorkflowID  string                 `json:"workflow_id,omitempty"`  // ID of the first task of the chain
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
 }
@@ -34,22 +47,49 @@ func (t *Task) GenerateID() {
 	}
 }
 
+// TenantOrDefault 任务所属租户，启用多租户前入队的任务属于默认租户
+func (t *Task) TenantOrDefault() string {
+	if t.Tenant == "" {
+		return DefaultTenant
+	}
+	return t.Tenant
+}
+
+// Expired 任务是否已过期，没有设置过期时间的任务不会过期
+func (t *Task) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && now.After(*t.ExpiresAt)
+}
+
 // generateTaskID 生成随机任务ID
 func generateTaskID() string {
-	// 简单的ID生成实现
-	return fmt.Sprintf("task_%d", time.Now().UnixNano())
+	// 纳秒时间戳，同一纳秒内（如批量入队）生成的ID顺延，保证进程内不重复
+	for {
+		last := lastTaskID.Load()
+		id := time.Now().UnixNano()
+		if id <= last {
+			id = last + 1
+		}
+		if lastTaskID.CompareAndSwap(last, id) {
+			return fmt.Sprintf("task_%d", id)
+		}
+	}
 }
 
+// lastTaskID 最近一次生成的任务ID
+var lastTaskID atomic.Int64
+
 // TaskStatus 任务状态
 type TaskStatus string
 
 const (
 	TaskStatusPending   TaskStatus = "pending"
+	TaskStatusScheduled TaskStatus = "scheduled" // 延迟任务，到达scheduled_at前对worker不可见
 	TaskStatusRunning   TaskStatus = "running"
 	TaskStatusCompleted TaskStatus = "completed"
 	TaskStatusFailed    TaskStatus = "failed"
 	TaskStatusRetrying  TaskStatus = "retrying"
 	TaskStatusCancelled TaskStatus = "cancelled"
+	TaskStatusExpired   TaskStatus = "expired" // 到达expires_at时仍未开始执行，已丢弃
 )
 
 // TaskType 任务类型
@@ -61,6 +101,9 @@ const (
 	TaskTypeBackupMetadata    = "backup_metadata"
 	TaskTypeSyncMetadata      = "sync_metadata"
 	TaskTypeHealthCheck       = "health_check"
+
+	// TaskTypeFileDeletion 队列服务worker处理的文件删除任务，数据包含bucket和key
+	TaskTypeFileDeletion = "file_deletion"
 )
 
 // QueueConfig 队列配置
@@ -86,6 +129,21 @@ type QueueStats struct {
 	ThroughputPerSecond float64   `json:"throughput_per_second"`
 }
 
+// QueueState 队列的运行状态，暂停期间worker不再拉取新任务，入队不受影响
+type QueueState struct {
+	Name     string     `json:"name"`
+	Paused   bool       `json:"paused"`
+	Reason   string     `json:"reason,omitempty"`
+	PausedAt *time.Time `json:"paused_at,omitempty"`
+}
+
+// TaskLease 外部消费者领取任务的租约，VisibleAt前任务对其他消费者不可见，确认或放回任务时使用ReceiptHandle
+type TaskLease struct {
+	ReceiptHandle string    `json:"receipt_handle"`
+	Task          *Task     `json:"task"`
+	VisibleAt     time.Time `json:"visible_at"` // 未确认时任务在该时间后重新入队
+}
+
 // Worker 工作节点模型
 type Worker struct {
 	ID          string            `json:"id"`
@@ -127,3 +185,22 @@ type TaskResult struct {
 	WorkerID  string                 `json:"worker_id"`
 	Timestamp time.Time              `json:"timestamp"`
 }
+
+// TaskRateLimit 任务类型的处理速率限制（令牌桶），worker处理该类型的任务前取令牌
+type TaskRateLimit struct {
+	Type          string    `json:"type"`
+	RatePerSecond float64   `json:"rate_per_second"`
+	Burst         int       `json:"burst"` // 令牌桶容量，为0时取ceil(rate_per_second)
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Validate 验证速率限制
+func (l *TaskRateLimit) Validate() error {
+	if l.RatePerSecond <= 0 {
+		return fmt.Errorf("%w: rate_per_second must be positive, got %v", ErrInvalidArgument, l.RatePerSecond)
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("%w: burst must not be negative, got %d", ErrInvalidArgument, l.Burst)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxTaskSchemaSize 任务类型schema文档的最大字节数
+const MaxTaskSchemaSize = 32 * 1024
+
+// ErrInvalidPayload 任务payload无法解析或不符合要求，重试也不会成功，worker直接将任务移入死信队列
+var ErrInvalidPayload = errors.New("invalid payload")
+
+// TaskSchema 任务类型注册的JSON Schema，入队时用于校验任务的data
+type TaskSchema struct {
+	Type      string          `json:"type"`
+	Schema    json.RawMessage `json:"schema"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// TaskPayloadError 入队任务的data不符合任务类型注册的schema
+type TaskPayloadError struct {
+	Type       string            `json:"type"`
+	Violations []SchemaViolation `json:"violations"`
+}
+
+// Error 实现error接口
+func (e *TaskPayloadError) Error() string {
+	parts := make([]string, 0, maxViolationsInMessage+1)
+	for i, violation := range e.Violations {
+		if i == maxViolationsInMessage {
+			parts = append(parts, fmt.Sprintf("and %d more", len(e.Violations)-i))
+			break
+		}
+		path := violation.Path
+		if path == "" {
+			path = "/"
+		}
+		parts = append(parts, path+": "+violation.Message)
+	}
+	return fmt.Sprintf("invalid payload for task type %s: %s", e.Type, strings.Join(parts, "; "))
+}
+
+// Unwrap 使errors.Is(err, ErrInvalidPayload)成立
+func (e *TaskPayloadError) Unwrap() error {
+	return ErrInvalidPayload
+}
+
+// DecodeData 将任务data解码到v（通常为payload结构体），失败时返回包装ErrInvalidPayload的错误
+func (t *Task) DecodeData(v any) error {
+	data, err := json.Marshal(t.Data)
+	if err != nil {
+		return fmt.Errorf("%w: task %s: %v", ErrInvalidPayload, t.ID, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: task %s: %v", ErrInvalidPayload, t.ID, err)
+	}
+	return nil
+}
+
+// FileDeletionPayload 文件删除任务的data
+type FileDeletionPayload struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+}
+
+// Validate 验证文件删除任务的data
+func (p *FileDeletionPayload) Validate() error {
+	if p.Bucket == "" || p.Key == "" {
+		return fmt.Errorf("%w: bucket and key are required", ErrInvalidPayload)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// 租户
+const (
+	HeaderTenantID = "X-Tenant-Id" // 请求所属租户，服务间调用时透传
+	DefaultTenant  = "default"     // 未指定租户的请求和启用多租户前的数据
+)
+
+// tenantIDPattern 租户ID：小写字母、数字、-和_，不超过64个字符
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidateTenantID 校验租户ID
+func ValidateTenantID(tenant string) error {
+	if !tenantIDPattern.MatchString(tenant) {
+		return fmt.Errorf("invalid tenant id %q: must be 1-64 lowercase letters, digits, '-' or '_'", tenant)
+	}
+	return nil
+}
+
+// tenantContextKey context中保存租户的键
+type tenantContextKey struct{}
+
+// WithTenant 返回携带租户的context
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// LookupTenant 获取context中显式设置的租户
+func LookupTenant(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// TenantFromContext 获取context中的租户，未设置时返回DefaultTenant
+func TenantFromContext(ctx context.Context) string {
+	if tenant, ok := LookupTenant(ctx); ok {
+		return tenant
+	}
+	return DefaultTenant
+}
+
+// TenantStats 租户用量汇总
+type TenantStats struct {
+	Tenant       string     `json:"tenant"`
+	Objects      int64      `json:"objects"`
+	Bytes        int64      `json:"bytes"`
+	Buckets      int64      `json:"buckets"`
+	LastActivity *time.Time `json:"last_activity,omitempty"`
+}
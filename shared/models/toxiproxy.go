@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// ToxicNamePrefix mock-error在Toxiproxy中创建的toxic名称前缀，同步时只管理带该前缀的toxic
+const ToxicNamePrefix = "mocks3-"
+
+// Toxic 流向
+const (
+	ToxicStreamDownstream = "downstream" // 代理返回给客户端的数据
+	ToxicStreamUpstream   = "upstream"   // 客户端发给目标服务的数据
+)
+
+// Toxic 类型
+const (
+	ToxicTypeLatency   = "latency"    // latency、jitter（毫秒）
+	ToxicTypeBandwidth = "bandwidth"  // rate（KB/s）
+	ToxicTypeTimeout   = "timeout"    // timeout（毫秒），0表示不返回数据直到连接关闭
+	ToxicTypeResetPeer = "reset_peer" // timeout（毫秒）后以RST关闭连接
+	ToxicTypeLimitData = "limit_data" // 发送bytes字节后关闭连接
+)
+
+// Toxic Toxiproxy中的toxic
+type Toxic struct {
+	Name       string           `json:"name"`
+	Type       string           `json:"type"`
+	Stream     string           `json:"stream"`
+	Toxicity   float64          `json:"toxicity"` // 受影响连接的比例
+	Attributes map[string]int64 `json:"attributes"`
+}
+
+// ToxiproxyToxic 规则在Toxiproxy中对应的toxic
+type ToxiproxyToxic struct {
+	RuleID string `json:"rule_id"`
+	Proxy  string `json:"proxy"`
+	Toxic  *Toxic `json:"toxic"`
+}
+
+// ToxiproxyStatus Toxiproxy同步状态
+type ToxiproxyStatus struct {
+	Enabled    bool              `json:"enabled"`
+	Toxics     []*ToxiproxyToxic `json:"toxics"` // 上次同步后生效的toxic
+	LastSyncAt *time.Time        `json:"last_sync_at,omitempty"`
+	LastError  string            `json:"last_error,omitempty"`
+}
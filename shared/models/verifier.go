@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// DefaultViolationTopic 一致性违规事件默认主题
+const DefaultViolationTopic = "invariant-violations"
+
+// 一致性不变量
+const (
+	InvariantSizeMatch        = "size_match"        // 元数据大小等于各副本文件大小
+	InvariantReplicaPresence  = "replica_presence"  // 元数据存在时至少一个健康节点持有副本，且各健康节点均持有
+	InvariantReplicaChecksum  = "replica_checksum"  // 各副本MD5一致且与元数据一致
+	InvariantVersionMonotonic = "version_monotonic" // 历史版本号严格递增且小于当前版本
+	InvariantNoOrphanObject   = "no_orphan_object"  // 存储节点上的对象均有对应元数据
+)
+
+// InvariantViolation 一致性违规记录
+type InvariantViolation struct {
+	ID         string    `json:"id"`
+	Invariant  string    `json:"invariant"`
+	Bucket     string    `json:"bucket"`
+	Key        string    `json:"key"`
+	NodeID     string    `json:"node_id,omitempty"`
+	Expected   string    `json:"expected,omitempty"`
+	Actual     string    `json:"actual,omitempty"`
+	Message    string    `json:"message"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// VerificationRound 一轮校验结果
+type VerificationRound struct {
+	StartedAt      time.Time             `json:"started_at"`
+	Duration       time.Duration         `json:"duration"`
+	ObjectsChecked int                   `json:"objects_checked"`
+	OrphansChecked int                   `json:"orphans_checked"`
+	CheckErrors    int                   `json:"check_errors"`
+	Violations     []*InvariantViolation `json:"violations"`
+	Wrapped        bool                  `json:"wrapped"` // 本轮扫描到键空间末尾，下轮从头开始
+}
+
+// VerifierStats 校验服务累计统计
+type VerifierStats struct {
+	Rounds                int64              `json:"rounds"`
+	ObjectsChecked        int64              `json:"objects_checked"`
+	OrphansChecked        int64              `json:"orphans_checked"`
+	CheckErrors           int64              `json:"check_errors"`
+	Violations            int64              `json:"violations"`
+	ViolationsByInvariant map[string]int64   `json:"violations_by_invariant"`
+	EventsPublished       int64              `json:"events_published"`
+	EventsFailed          int64              `json:"events_failed"`
+	LastRound             *VerificationRound `json:"last_round,omitempty"`
+}
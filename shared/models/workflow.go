@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+)
+
+// MaxWorkflowTasks 一个任务及其所有后续任务的总数上限
+const MaxWorkflowTasks = 32
+
+// GenerateIDs 为任务及其所有后续任务生成ID，使入队时就能得到后续任务的ID
+func (t *Task) GenerateIDs() {
+	t.GenerateID()
+	for _, next := range t.Next {
+		next.GenerateIDs()
+	}
+}
+
+// ValidateNext 验证后续任务：每个任务都要指定类型，连同任务本身不超过MaxWorkflowTasks个
+func (t *Task) ValidateNext() error {
+	count := 0
+	var walk func(task *Task) error
+	walk = func(task *Task) error {
+		count++
+		if count > MaxWorkflowTasks {
+			return fmt.Errorf("invalid next: workflow must not have more than %d tasks", MaxWorkflowTasks)
+		}
+		for _, next := range task.Next {
+			if next == nil || next.Type == "" {
+				return fmt.Errorf("invalid next: task type is required")
+			}
+			if err := walk(next); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(t)
+}
+
+// NextIDs 后续任务的ID
+func (t *Task) NextIDs() []string {
+	if len(t.Next) == 0 {
+		return nil
+	}
+	ids := make([]string, len(t.Next))
+	for i, next := range t.Next {
+		ids[i] = next.ID
+	}
+	return ids
+}
+
+// FollowUps 任务完成后要入队的后续任务
+//
+// 后续任务属于同一租户和工作流，任务输出中后续任务数据未指定的字段合并到其数据中。
+func (t *Task) FollowUps() []*Task {
+	workflowID := t.WorkflowID
+	if workflowID == "" {
+		workflowID = t.ID
+	}
+
+	tasks := make([]*Task, 0, len(t.Next))
+	for _, next := range t.Next {
+		task := *next
+		task.Tenant = t.Tenant
+		task.ParentID = t.ID
+		task.WorkflowID = workflowID
+		task.Data = maps.Clone(next.Data)
+		for key, value := range t.Output {
+			if _, ok := task.Data[key]; !ok {
+				if task.Data == nil {
+					task.Data = make(map[string]interface{}, len(t.Output))
+				}
+				task.Data[key] = value
+			}
+		}
+		task.GenerateID()
+		tasks = append(tasks, &task)
+	}
+	return tasks
+}
@@ -0,0 +1,375 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mocks3/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 审计日志目标类型
+const (
+	AuditSinkFile = "file" // 追加写入JSON Lines文件
+	AuditSinkHTTP = "http" // 批量POST到HTTP端点（application/x-ndjson）
+)
+
+// 审计日志分类
+const (
+	AuditCategoryRuleChange = "rule_change" // 注入规则变更
+	AuditCategoryDelete     = "delete"      // 数据删除
+	AuditCategoryAdmin      = "admin"       // 管理接口的修改操作
+)
+
+// 审计结果
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// HTTP目标的批量发送参数
+const (
+	auditHTTPBatchSize     = 100
+	auditHTTPFlushInterval = time.Second
+)
+
+// AuditLogConfig 审计日志配置，Sink为空时不记录
+type AuditLogConfig struct {
+	Sink       string
+	Path       string        // file目标的文件路径
+	URL        string        // http目标的地址
+	BufferSize int           // http目标待发送缓冲区大小，满时丢弃新记录
+	Timeout    time.Duration // http目标单次发送超时
+}
+
+// AuditLogConfigFromEnv 根据环境变量读取审计日志配置
+//
+// AUDIT_LOG_SINK 为 file 或 http；AUDIT_LOG_PATH 为文件路径（默认audit.log）；
+// AUDIT_LOG_URL 为HTTP地址；AUDIT_LOG_BUFFER_SIZE 为HTTP目标缓冲区大小（默认1000）。
+func AuditLogConfigFromEnv() AuditLogConfig {
+	cfg := AuditLogConfig{
+		Sink:       strings.ToLower(os.Getenv("AUDIT_LOG_SINK")),
+		Path:       os.Getenv("AUDIT_LOG_PATH"),
+		URL:        os.Getenv("AUDIT_LOG_URL"),
+		BufferSize: 1000,
+		Timeout:    5 * time.Second,
+	}
+	if cfg.Path == "" {
+		cfg.Path = "audit.log"
+	}
+	if size, err := strconv.Atoi(os.Getenv("AUDIT_LOG_BUFFER_SIZE")); err == nil && size > 0 {
+		cfg.BufferSize = size
+	}
+	return cfg
+}
+
+// AuditLogEntry 一条审计日志，与应用日志分开写入，供安全审计和操作追溯
+type AuditLogEntry struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Service   string         `json:"service"`
+	Category  string         `json:"category"`
+	Action    string         `json:"action"`
+	Resource  string         `json:"resource,omitempty"`
+	Outcome   string         `json:"outcome"`
+	Error     string         `json:"error,omitempty"`
+	Actor     string         `json:"actor,omitempty"`
+	Tenant    string         `json:"tenant,omitempty"`
+	IP        string         `json:"ip,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	TraceID   string         `json:"trace_id,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// auditSink 审计日志写入目标，每次写入一行JSON
+type auditSink interface {
+	write(line []byte) error
+	close(ctx context.Context) error
+}
+
+// AuditLogger 审计日志器，未配置目标时Record不做任何事，调用方不需要判断是否开启
+type AuditLogger struct {
+	service string
+	sink    auditSink
+	logger  *Logger
+}
+
+// newAuditLogger 按配置创建审计日志器
+func newAuditLogger(service string, cfg AuditLogConfig, logger *Logger) (*AuditLogger, error) {
+	a := &AuditLogger{service: service, logger: logger}
+	switch cfg.Sink {
+	case "":
+		return a, nil
+	case AuditSinkFile:
+		sink, err := newFileAuditSink(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		a.sink = sink
+	case AuditSinkHTTP:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("audit log url is required for http sink")
+		}
+		a.sink = newHTTPAuditSink(cfg, logger)
+	default:
+		return nil, fmt.Errorf("unknown audit log sink: %s", cfg.Sink)
+	}
+	return a, nil
+}
+
+// Enabled 是否配置了审计日志目标
+func (a *AuditLogger) Enabled() bool {
+	return a != nil && a.sink != nil
+}
+
+// Record 记录审计日志，补全时间、服务名、trace ID，未指定操作者时取context中的操作者；写入失败只记录应用日志
+func (a *AuditLogger) Record(ctx context.Context, entry *AuditLogEntry) {
+	if !a.Enabled() {
+		return
+	}
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	entry.Service = a.service
+	if entry.Outcome == "" {
+		entry.Outcome = AuditOutcomeSuccess
+		if entry.Error != "" {
+			entry.Outcome = AuditOutcomeFailure
+		}
+	}
+	if entry.Actor == "" {
+		actor := models.AuditActorFromContext(ctx)
+		entry.Actor = actor.Name
+		if entry.Tenant == "" {
+			entry.Tenant = actor.Tenant
+		}
+		if entry.IP == "" {
+			entry.IP = actor.IP
+		}
+	}
+	if entry.RequestID == "" {
+		entry.RequestID = models.RequestIDFromContext(ctx)
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		entry.TraceID = spanCtx.TraceID().String()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		a.logger.Warn(ctx, "Failed to encode audit log entry", Error(err))
+		return
+	}
+	if err := a.sink.write(line); err != nil {
+		a.logger.Warn(ctx, "Failed to write audit log entry",
+			String("category", entry.Category),
+			String("action", entry.Action),
+			Error(err))
+	}
+}
+
+// GinAdminMiddleware 返回Gin中间件，把prefix下的修改请求（非GET/HEAD/OPTIONS）记录为admin审计日志
+func (a *AuditLogger) GinAdminMiddleware(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if !a.Enabled() || !strings.HasPrefix(c.Request.URL.Path, prefix) {
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		status := c.Writer.Status()
+		entry := &AuditLogEntry{
+			Category: AuditCategoryAdmin,
+			Action:   c.Request.Method + " " + route,
+			Resource: c.Request.URL.Path,
+			Outcome:  AuditOutcomeSuccess,
+			Details:  map[string]any{"status_code": status},
+		}
+		if status >= http.StatusBadRequest {
+			entry.Outcome = AuditOutcomeFailure
+		}
+		if models.AuditActorFromContext(c.Request.Context()).IP == "" {
+			entry.IP = c.ClientIP()
+		}
+		a.Record(c.Request.Context(), entry)
+	}
+}
+
+// Close 关闭审计日志目标，HTTP目标会先发送缓冲区中的记录
+func (a *AuditLogger) Close(ctx context.Context) error {
+	if !a.Enabled() {
+		return nil
+	}
+	return a.sink.close(ctx)
+}
+
+// fileAuditSink 追加写入JSON Lines文件
+type fileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newFileAuditSink(path string) (*fileAuditSink, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	return &fileAuditSink{file: file}, nil
+}
+
+func (s *fileAuditSink) write(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.file.Write(append(line, '\n'))
+	return err
+}
+
+func (s *fileAuditSink) close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// httpAuditSink 后台批量POST到HTTP端点，缓冲区满时丢弃新记录，避免阻塞请求
+type httpAuditSink struct {
+	url     string
+	client  *http.Client
+	logger  *Logger
+	pending chan []byte
+	done    chan struct{}
+	dropped atomic.Int64
+
+	mu     sync.RWMutex // 保护closed，关闭pending后不能再写入
+	closed bool
+}
+
+func newHTTPAuditSink(cfg AuditLogConfig, logger *Logger) *httpAuditSink {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	s := &httpAuditSink{
+		url:     cfg.URL,
+		client:  &http.Client{Timeout: timeout},
+		logger:  logger,
+		pending: make(chan []byte, bufferSize),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *httpAuditSink) write(line []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return fmt.Errorf("audit log sink closed")
+	}
+	select {
+	case s.pending <- line:
+		return nil
+	default:
+		dropped := s.dropped.Add(1)
+		return fmt.Errorf("audit log buffer full, %d entries dropped", dropped)
+	}
+}
+
+// run 攒批发送，达到批量大小或刷新间隔时发送一次
+func (s *httpAuditSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(auditHTTPFlushInterval)
+	defer ticker.Stop()
+
+	var batch bytes.Buffer
+	count := 0
+	flush := func() {
+		if count == 0 {
+			return
+		}
+		if err := s.send(batch.Bytes()); err != nil {
+			s.logger.Warn(context.Background(), "Failed to send audit log batch",
+				Int("entries", count),
+				Error(err))
+		}
+		batch.Reset()
+		count = 0
+	}
+
+	for {
+		select {
+		case line, ok := <-s.pending:
+			if !ok {
+				flush()
+				return
+			}
+			batch.Write(line)
+			batch.WriteByte('\n')
+			count++
+			if count >= auditHTTPBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (s *httpAuditSink) send(body []byte) error {
+	resp, err := s.client.Post(s.url, "application/x-ndjson", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("audit log endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *httpAuditSink) close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.pending)
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,105 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+
+	"mocks3/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// surfacedBaggageKeys 自动写入日志的baggage键
+var surfacedBaggageKeys = []string{
+	models.BaggageTenant,
+	models.BaggageTestRunID,
+	models.BaggageScenarioID,
+}
+
+// WithBaggage 返回设置了baggage成员的context，之后的出站调用会透传给下游服务
+func WithBaggage(ctx context.Context, key, value string) (context.Context, error) {
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid baggage member %s: %w", key, err)
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to set baggage %s: %w", key, err)
+	}
+	return baggage.ContextWithBaggage(ctx, bag), nil
+}
+
+// WithTestRun 设置测试运行ID和场景ID，值为空时不设置
+func WithTestRun(ctx context.Context, testRunID, scenarioID string) (context.Context, error) {
+	var err error
+	if testRunID != "" {
+		if ctx, err = WithBaggage(ctx, models.BaggageTestRunID, testRunID); err != nil {
+			return ctx, err
+		}
+	}
+	if scenarioID != "" {
+		if ctx, err = WithBaggage(ctx, models.BaggageScenarioID, scenarioID); err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+// BaggageValue 获取context中的baggage值，未设置时返回空字符串
+func BaggageValue(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// SurfacedBaggage 返回context中租户、测试运行ID和场景ID等baggage值
+func SurfacedBaggage(ctx context.Context) map[string]string {
+	bag := baggage.FromContext(ctx)
+	if bag.Len() == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(surfacedBaggageKeys))
+	for _, key := range surfacedBaggageKeys {
+		if value := bag.Member(key).Value(); value != "" {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// appendBaggageFields 把baggage值追加为日志字段，已有同名字段时不覆盖
+func appendBaggageFields(ctx context.Context, fields []Field) []Field {
+	bag := baggage.FromContext(ctx)
+	if bag.Len() == 0 {
+		return fields
+	}
+	for _, key := range surfacedBaggageKeys {
+		value := bag.Member(key).Value()
+		if value == "" || hasField(fields, key) {
+			continue
+		}
+		fields = append(fields[:len(fields):len(fields)], String(key, value))
+	}
+	return fields
+}
+
+// hasField 字段列表中是否已有该键
+func hasField(fields []Field, key string) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// GinBaggageMiddleware 返回Gin中间件，从baggage请求头读取baggage写入请求context，未开启追踪时也生效
+func (o *Observability) GinBaggageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(models.HeaderBaggage) != "" {
+			ctx := propagation.Baggage{}.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,134 @@
+package observability
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDebugAddress 调试服务默认只监听本机
+const defaultDebugAddress = "127.0.0.1:6060"
+
+// DebugConfig 调试服务配置，提供pprof、expvar和goroutine转储，用于在线排查内存和goroutine泄漏
+type DebugConfig struct {
+	Enabled bool
+	Address string
+	Token   string // 非空时请求需要携带 Authorization: Bearer <token>；监听非本机地址时必须配置
+}
+
+// DebugConfigFromEnv 根据环境变量读取调试服务配置
+//
+// DEBUG_SERVER_ENABLED=true 开启；DEBUG_SERVER_ADDR 为监听地址（默认127.0.0.1:6060）；
+// DEBUG_SERVER_TOKEN 为访问令牌。
+func DebugConfigFromEnv() DebugConfig {
+	cfg := DebugConfig{
+		Enabled: strings.EqualFold(os.Getenv("DEBUG_SERVER_ENABLED"), "true"),
+		Address: os.Getenv("DEBUG_SERVER_ADDR"),
+		Token:   os.Getenv("DEBUG_SERVER_TOKEN"),
+	}
+	if cfg.Address == "" {
+		cfg.Address = defaultDebugAddress
+	}
+	return cfg
+}
+
+// validate 检查调试服务配置，未配置令牌时只允许监听本机地址
+func (c DebugConfig) validate() error {
+	host, _, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		return fmt.Errorf("invalid debug server address %q: %w", c.Address, err)
+	}
+	if c.Token != "" {
+		return nil
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("debug server on non-loopback address %q requires a token", c.Address)
+}
+
+var publishDebugVarsOnce sync.Once
+
+// publishDebugVars 发布/debug/vars中的运行时变量，expvar同名变量只能发布一次
+func publishDebugVars() {
+	publishDebugVarsOnce.Do(func() {
+		expvar.Publish("goroutines", expvar.Func(func() any {
+			return runtime.NumGoroutine()
+		}))
+	})
+}
+
+// startDebugServer 启动调试服务，监听失败时返回错误
+func startDebugServer(cfg DebugConfig, logger *Logger) (*http.Server, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	publishDebugVars()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/goroutines", handleGoroutineDump)
+
+	listener, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", cfg.Address, err)
+	}
+
+	server := &http.Server{
+		Handler:           debugAuth(cfg.Token, mux),
+		ReadHeaderTimeout: 5 * time.Second,
+		// 不设置WriteTimeout：CPU profile和trace按seconds参数持续采集
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(context.Background(), "Debug server stopped", Error(err))
+		}
+	}()
+
+	logger.Info(context.Background(), "Debug server started",
+		String("address", listener.Addr().String()),
+		Bool("token_required", cfg.Token != ""))
+	return server, nil
+}
+
+// debugAuth 校验访问令牌，令牌为空时不校验
+func debugAuth(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="debug"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleGoroutineDump 输出所有goroutine的完整调用栈
+func handleGoroutineDump(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "goroutines: %d\n\n", runtime.NumGoroutine())
+	runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
@@ -0,0 +1,74 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+// newOTLPLogHook 创建把日志通过OTel Logs SDK导出的钩子，SDK从ctx中取trace ID和span ID，日志与追踪在后端关联
+func newOTLPLogHook(logger otellog.Logger) LogHook {
+	return func(ctx context.Context, level slog.Level, msg string, fields []Field) {
+		now := time.Now()
+
+		var record otellog.Record
+		record.SetTimestamp(now)
+		record.SetObservedTimestamp(now)
+		record.SetSeverity(otelSeverity(level))
+		record.SetSeverityText(level.String())
+		record.SetBody(otellog.StringValue(msg))
+
+		attrs := make([]otellog.KeyValue, 0, len(fields))
+		for _, field := range fields {
+			attrs = append(attrs, otellog.KeyValue{Key: field.Key, Value: otelLogValue(field.Attr().Value)})
+		}
+		record.AddAttributes(attrs...)
+
+		logger.Emit(ctx, record)
+	}
+}
+
+// otelSeverity 转换slog级别为OTel日志级别
+func otelSeverity(level slog.Level) otellog.Severity {
+	switch {
+	case level >= slog.LevelError:
+		return otellog.SeverityError
+	case level >= slog.LevelWarn:
+		return otellog.SeverityWarn
+	case level >= slog.LevelInfo:
+		return otellog.SeverityInfo
+	default:
+		return otellog.SeverityDebug
+	}
+}
+
+// otelLogValue 转换字段值，与标准输出的JSON日志保持相同的类型
+func otelLogValue(value slog.Value) otellog.Value {
+	switch value.Kind() {
+	case slog.KindString:
+		return otellog.StringValue(value.String())
+	case slog.KindBool:
+		return otellog.BoolValue(value.Bool())
+	case slog.KindInt64:
+		return otellog.Int64Value(value.Int64())
+	case slog.KindUint64:
+		return otellog.Int64Value(int64(value.Uint64()))
+	case slog.KindFloat64:
+		return otellog.Float64Value(value.Float64())
+	case slog.KindDuration:
+		return otellog.Int64Value(int64(value.Duration()))
+	case slog.KindTime:
+		return otellog.StringValue(value.Time().Format(time.RFC3339Nano))
+	case slog.KindGroup:
+		group := value.Group()
+		kvs := make([]otellog.KeyValue, 0, len(group))
+		for _, attr := range group {
+			kvs = append(kvs, otellog.KeyValue{Key: attr.Key, Value: otelLogValue(attr.Value)})
+		}
+		return otellog.MapValue(kvs...)
+	default:
+		return otellog.StringValue(value.String())
+	}
+}
@@ -0,0 +1,90 @@
+package observability
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// LogFileConfig 日志文件输出配置，在标准输出之外写入按大小和时间轮转的文件，用于没有日志采集的裸机部署
+type LogFileConfig struct {
+	Path       string // 为空时只输出到标准输出
+	MaxSizeMB  int    // 单个文件达到该大小后轮转
+	MaxAgeDays int    // 轮转后的文件保留天数，为0时不按时间清理
+	MaxBackups int    // 最多保留的轮转文件数，为0时不按数量清理
+	Compress   bool   // 轮转后的文件是否gzip压缩
+}
+
+// LogFileConfigFromEnv 根据环境变量读取日志文件配置
+//
+// LOG_FILE_PATH 为日志文件路径（未设置时不写文件）；LOG_FILE_MAX_SIZE_MB 默认100；
+// LOG_FILE_MAX_AGE_DAYS 默认7；LOG_FILE_MAX_BACKUPS 默认10；LOG_FILE_COMPRESS=true 时压缩轮转文件。
+func LogFileConfigFromEnv() LogFileConfig {
+	return LogFileConfig{
+		Path:       os.Getenv("LOG_FILE_PATH"),
+		MaxSizeMB:  envInt("LOG_FILE_MAX_SIZE_MB", 100),
+		MaxAgeDays: envInt("LOG_FILE_MAX_AGE_DAYS", 7),
+		MaxBackups: envInt("LOG_FILE_MAX_BACKUPS", 10),
+		Compress:   strings.EqualFold(os.Getenv("LOG_FILE_COMPRESS"), "true"),
+	}
+}
+
+// envInt 读取非负整数环境变量，未设置或无效时返回默认值
+func envInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value >= 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// logOutput 日志输出目标，可在运行中增加文件输出
+type logOutput struct {
+	writer atomic.Pointer[io.Writer]
+}
+
+func newLogOutput(w io.Writer) *logOutput {
+	out := &logOutput{}
+	out.writer.Store(&w)
+	return out
+}
+
+func (o *logOutput) Write(p []byte) (int, error) {
+	return (*o.writer.Load()).Write(p)
+}
+
+// set 替换输出目标
+func (o *logOutput) set(w io.Writer) {
+	o.writer.Store(&w)
+}
+
+// EnableFileOutput 在标准输出之外把日志写入轮转文件，返回的Closer在退出时关闭文件
+func (l *Logger) EnableFileOutput(cfg LogFileConfig) (io.Closer, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("log file path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file := &lumberjack.Logger{
+		Filename:   cfg.Path,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxAge:     cfg.MaxAgeDays,
+		MaxBackups: cfg.MaxBackups,
+		Compress:   cfg.Compress,
+		LocalTime:  true,
+	}
+	// 打开文件，路径不可写时在启动阶段报错
+	if _, err := file.Write(nil); err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	l.output.set(io.MultiWriter(os.Stdout, file))
+	return file, nil
+}
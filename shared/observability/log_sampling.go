@@ -0,0 +1,115 @@
+package observability
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LogSamplingConfig 日志采样配置：同一级别、同一消息每秒最多输出PerSecond条，超出的丢弃，
+// 下一条输出的日志带上sampled_dropped字段记录丢弃数。只对MaxLevel及以下级别生效，默认不采样warn和error
+type LogSamplingConfig struct {
+	PerSecond int    // 为0时不采样
+	MaxLevel  string // 采样的最高级别，默认info
+}
+
+// LogSamplingConfigFromEnv 根据环境变量读取日志采样配置
+//
+// LOG_SAMPLING_PER_SECOND 为每条消息每秒最多输出的条数（默认0，不采样）；
+// LOG_SAMPLING_MAX_LEVEL 为采样的最高级别（默认info）。
+func LogSamplingConfigFromEnv() LogSamplingConfig {
+	cfg := LogSamplingConfig{MaxLevel: os.Getenv("LOG_SAMPLING_MAX_LEVEL")}
+	if perSecond, err := strconv.Atoi(os.Getenv("LOG_SAMPLING_PER_SECOND")); err == nil && perSecond > 0 {
+		cfg.PerSecond = perSecond
+	}
+	if cfg.MaxLevel == "" {
+		cfg.MaxLevel = "info"
+	}
+	return cfg
+}
+
+// logSampler 按级别和消息计数的采样器
+type logSampler struct {
+	perSecond int64
+	maxLevel  slog.Level
+	counters  sync.Map // logSampleKey -> *logSampleCounter
+}
+
+// logSampleKey 采样键
+type logSampleKey struct {
+	level slog.Level
+	msg   string
+}
+
+// logSampleCounter 一个采样键在当前秒内的计数
+type logSampleCounter struct {
+	mu      sync.Mutex
+	second  int64
+	count   int64
+	dropped int64 // 上一条输出之后丢弃的条数
+}
+
+func newLogSampler(cfg LogSamplingConfig) *logSampler {
+	return &logSampler{
+		perSecond: int64(cfg.PerSecond),
+		maxLevel:  toSlogLevel(parseLogLevel(cfg.MaxLevel)),
+	}
+}
+
+// allow 判断日志是否输出，输出时返回之前丢弃的条数
+func (s *logSampler) allow(level slog.Level, msg string, now time.Time) (bool, int64) {
+	if level > s.maxLevel {
+		return true, 0
+	}
+
+	key := logSampleKey{level: level, msg: msg}
+	value, ok := s.counters.Load(key)
+	if !ok {
+		value, _ = s.counters.LoadOrStore(key, &logSampleCounter{})
+	}
+	counter := value.(*logSampleCounter)
+
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+
+	second := now.Unix()
+	if counter.second != second {
+		counter.second = second
+		counter.count = 0
+	}
+	if counter.count >= s.perSecond {
+		counter.dropped++
+		return false, 0
+	}
+	counter.count++
+	dropped := counter.dropped
+	counter.dropped = 0
+	return true, dropped
+}
+
+// SetSampling 设置日志采样，PerSecond为0时关闭，运行时立即生效
+func (l *Logger) SetSampling(cfg LogSamplingConfig) {
+	if cfg.PerSecond <= 0 {
+		l.sampler.Store(nil)
+		return
+	}
+	l.sampler.Store(newLogSampler(cfg))
+}
+
+// sample 按采样配置判断日志是否输出，需要输出时返回追加了丢弃数的字段
+func (l *Logger) sample(level slog.Level, msg string, fields []Field) ([]Field, bool) {
+	sampler := l.sampler.Load()
+	if sampler == nil {
+		return fields, true
+	}
+	ok, dropped := sampler.allow(level, msg, time.Now())
+	if !ok {
+		return nil, false
+	}
+	if dropped > 0 {
+		fields = append(fields[:len(fields):len(fields)], Int64("sampled_dropped", dropped))
+	}
+	return fields, true
+}
@@ -5,8 +5,13 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"mocks3/shared/models"
+
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -20,6 +25,32 @@ const (
 	LevelError
 )
 
+// logLevelNames 日志级别名称
+var logLevelNames = map[LogLevel]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// String 返回日志级别名称
+func (l LogLevel) String() string {
+	if name, ok := logLevelNames[l]; ok {
+		return name
+	}
+	return "info"
+}
+
+// IsValidLogLevel 检查日志级别名称是否有效
+func IsValidLogLevel(level string) bool {
+	for _, name := range logLevelNames {
+		if name == level {
+			return true
+		}
+	}
+	return false
+}
+
 // Field 日志字段
 type Field struct {
 	Key   string
@@ -48,12 +79,29 @@ func Float64(key string, value float64) Field {
 
 // Error 创建错误字段
 func Error(err error) Field {
-	return Field{Key: "error", Value: err.Error()}
+	return Field{Key: "error", Value: err}
+}
+
+// ErrorStack 创建带调用栈的错误字段，输出为包含message、type和stack的分组
+func ErrorStack(err error) Field {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(2, pcs[:])
+	return Field{Key: "error", Value: stackError{err: err, stack: pcs[:n]}}
 }
 
-// Duration 创建持续时间字段
+// Duration 创建持续时间字段，JSON输出为纳秒数
 func Duration(key string, duration time.Duration) Field {
-	return Field{Key: key, Value: duration.String()}
+	return Field{Key: key, Value: duration}
+}
+
+// Time 创建时间字段
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Group 创建分组字段，JSON输出为嵌套对象
+func Group(key string, fields ...Field) Field {
+	return Field{Key: key, Value: fields}
 }
 
 func Bool(key string, value bool) Field {
@@ -64,31 +112,85 @@ func Any(key string, value interface{}) Field {
 	return Field{Key: key, Value: value}
 }
 
+// Attr 转换为slog属性，基本类型保留原生类型
+func (f Field) Attr() slog.Attr {
+	return slog.Attr{Key: f.Key, Value: slogValue(f.Value)}
+}
+
+// maxStackDepth 错误字段记录的最大调用栈深度
+const maxStackDepth = 32
+
+// stackError 带调用栈的错误
+type stackError struct {
+	err   error
+	stack []uintptr
+}
+
+// slogValue 转换字段值为slog值，slog原生支持的类型直接使用，分组和错误单独处理，其他值格式化为字符串
+func slogValue(value any) slog.Value {
+	switch v := value.(type) {
+	case []Field:
+		attrs := make([]slog.Attr, 0, len(v))
+		for _, field := range v {
+			attrs = append(attrs, field.Attr())
+		}
+		return slog.GroupValue(attrs...)
+	case stackError:
+		message := "<nil>"
+		if v.err != nil {
+			message = v.err.Error()
+		}
+		return slog.GroupValue(
+			slog.String("message", message),
+			slog.String("type", fmt.Sprintf("%T", v.err)),
+			slog.String("stack", formatStack(v.stack)),
+		)
+	case error:
+		return slog.StringValue(v.Error())
+	case nil:
+		return slog.StringValue("<nil>")
+	}
+
+	if v := slog.AnyValue(value); v.Kind() != slog.KindAny {
+		return v
+	}
+	return slog.StringValue(fmt.Sprintf("%v", value))
+}
+
+// formatStack 格式化调用栈，每帧一行：函数名 文件:行号
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s %s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// LogHook 日志钩子，每条实际输出的日志都会调用
+type LogHook func(ctx context.Context, level slog.Level, msg string, fields []Field)
+
 // Logger 优化后的日志器 - 兼容现有接口
 type Logger struct {
 	logger      *slog.Logger
 	serviceName string
-	level       LogLevel
+	level       atomic.Int32   // LogLevel，运行时可修改
+	slogLevel   *slog.LevelVar // 与level同步，使输出处理器按新级别过滤
 	baseAttrs   []slog.Attr
+	hooks       atomic.Pointer[[]LogHook]
+	sampler     atomic.Pointer[logSampler] // 为nil时不采样
+	output      *logOutput                 // 标准输出，开启文件输出后同时写入文件
 }
 
 // NewLogger 创建新的日志器
 func NewLogger(serviceName string, level string) *Logger {
 	logLevel := parseLogLevel(level)
-
-	var slogLevel slog.Level
-	switch logLevel {
-	case LevelDebug:
-		slogLevel = slog.LevelDebug
-	case LevelInfo:
-		slogLevel = slog.LevelInfo
-	case LevelWarn:
-		slogLevel = slog.LevelWarn
-	case LevelError:
-		slogLevel = slog.LevelError
-	default:
-		slogLevel = slog.LevelInfo
-	}
+	slogLevel := new(slog.LevelVar)
+	slogLevel.Set(toSlogLevel(logLevel))
 
 	opts := &slog.HandlerOptions{
 		Level: slogLevel,
@@ -101,7 +203,8 @@ func NewLogger(serviceName string, level string) *Logger {
 		},
 	}
 
-	handler := slog.NewJSONHandler(os.Stdout, opts)
+	output := newLogOutput(os.Stdout)
+	handler := slog.NewJSONHandler(output, opts)
 	logger := slog.New(handler)
 
 	// 预创建基础属性
@@ -109,22 +212,52 @@ func NewLogger(serviceName string, level string) *Logger {
 		slog.String("service", serviceName),
 	}
 
-	return &Logger{
+	l := &Logger{
 		logger:      logger,
 		serviceName: serviceName,
-		level:       logLevel,
+		slogLevel:   slogLevel,
 		baseAttrs:   baseAttrs,
+		output:      output,
 	}
+	l.level.Store(int32(logLevel))
+	return l
 }
 
-// SetLevel 设置日志级别
+// SetLevel 设置日志级别，运行时立即生效
 func (l *Logger) SetLevel(level string) {
-	l.level = parseLogLevel(level)
+	logLevel := parseLogLevel(level)
+	l.level.Store(int32(logLevel))
+	l.slogLevel.Set(toSlogLevel(logLevel))
+}
+
+// Level 返回当前日志级别名称
+func (l *Logger) Level() string {
+	return l.currentLevel().String()
+}
+
+// currentLevel 返回当前日志级别
+func (l *Logger) currentLevel() LogLevel {
+	return LogLevel(l.level.Load())
+}
+
+// AddHook 添加日志钩子，可在记录日志的同时调用
+func (l *Logger) AddHook(hook LogHook) {
+	for {
+		current := l.hooks.Load()
+		var hooks []LogHook
+		if current != nil {
+			hooks = append(hooks, *current...)
+		}
+		hooks = append(hooks, hook)
+		if l.hooks.CompareAndSwap(current, &hooks) {
+			return
+		}
+	}
 }
 
 // Debug 调试日志
 func (l *Logger) Debug(ctx context.Context, msg string, fields ...Field) {
-	if l.level > LevelDebug {
+	if l.currentLevel() > LevelDebug {
 		return
 	}
 	l.emit(ctx, slog.LevelDebug, msg, fields...)
@@ -132,7 +265,7 @@ func (l *Logger) Debug(ctx context.Context, msg string, fields ...Field) {
 
 // Info 信息日志
 func (l *Logger) Info(ctx context.Context, msg string, fields ...Field) {
-	if l.level > LevelInfo {
+	if l.currentLevel() > LevelInfo {
 		return
 	}
 	l.emit(ctx, slog.LevelInfo, msg, fields...)
@@ -140,7 +273,7 @@ func (l *Logger) Info(ctx context.Context, msg string, fields ...Field) {
 
 // Warn 警告日志
 func (l *Logger) Warn(ctx context.Context, msg string, fields ...Field) {
-	if l.level > LevelWarn {
+	if l.currentLevel() > LevelWarn {
 		return
 	}
 	l.emit(ctx, slog.LevelWarn, msg, fields...)
@@ -148,7 +281,7 @@ func (l *Logger) Warn(ctx context.Context, msg string, fields ...Field) {
 
 // Error 错误日志
 func (l *Logger) Error(ctx context.Context, msg string, fields ...Field) {
-	if l.level > LevelError {
+	if l.currentLevel() > LevelError {
 		return
 	}
 	l.emit(ctx, slog.LevelError, msg, fields...)
@@ -156,7 +289,7 @@ func (l *Logger) Error(ctx context.Context, msg string, fields ...Field) {
 
 // ErrorWithErr 记录错误，包含错误对象
 func (l *Logger) ErrorWithErr(ctx context.Context, err error, msg string, fields ...Field) {
-	if err == nil || l.level > LevelError {
+	if err == nil || l.currentLevel() > LevelError {
 		return
 	}
 
@@ -167,13 +300,24 @@ func (l *Logger) ErrorWithErr(ctx context.Context, err error, msg string, fields
 
 // emit 发送日志
 func (l *Logger) emit(ctx context.Context, level slog.Level, msg string, fields ...Field) {
+	fields, ok := l.sample(level, msg, fields)
+	if !ok {
+		return
+	}
+
+	// 请求ID在未开启追踪时也能关联同一请求的日志
+	if requestID := models.RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields[:len(fields):len(fields)], String("request_id", requestID))
+	}
+	fields = appendBaggageFields(ctx, fields)
+
 	// 复用基础属性，避免重复分配
 	attrs := make([]slog.Attr, 0, len(l.baseAttrs)+len(fields)+3)
 	attrs = append(attrs, l.baseAttrs...)
 
 	// 处理额外字段
 	for _, field := range fields {
-		attrs = append(attrs, slog.String(field.Key, fmt.Sprintf("%v", field.Value)))
+		attrs = append(attrs, field.Attr())
 	}
 
 	// 添加追踪信息（如果存在）
@@ -187,6 +331,12 @@ func (l *Logger) emit(ctx context.Context, level slog.Level, msg string, fields
 
 	// 创建并发送日志记录
 	l.logger.LogAttrs(ctx, level, msg, attrs...)
+
+	if hooks := l.hooks.Load(); hooks != nil {
+		for _, hook := range *hooks {
+			hook(ctx, level, msg, fields)
+		}
+	}
 }
 
 // 兼容性方法 - 支持现有的字符串参数接口
@@ -221,6 +371,20 @@ func (l *Logger) argsToFields(args ...interface{}) []Field {
 	return fields
 }
 
+// toSlogLevel 转换为slog级别
+func toSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
 // parseLogLevel 解析日志级别字符串
 func parseLogLevel(level string) LogLevel {
 	switch level {
@@ -31,6 +31,46 @@ type MetricCollector struct {
 	activeConnections metric.Int64UpDownCounter
 	queueSize        metric.Int64ObservableGauge
 	errorCount       metric.Int64Counter
+
+	// 限流指标
+	rateLimitDecisions metric.Int64Counter
+
+	// 一致性校验指标
+	invariantChecks     metric.Int64Counter
+	invariantViolations metric.Int64Counter
+
+	// 缓存指标
+	cacheRequests metric.Int64Counter
+
+	// 对象保留指标
+	blockedDeletes metric.Int64Counter
+
+	// bucket复制指标
+	replicationLag      metric.Float64Histogram
+	replicationPending  metric.Int64UpDownCounter
+	replicationFailures metric.Int64Counter
+
+	// 飞行记录器指标
+	incidentCaptures metric.Int64Counter
+
+	// 队列worker池指标
+	queueWorkers      metric.Int64UpDownCounter
+	workerScaleEvents metric.Int64Counter
+	taskDuration      metric.Float64Histogram
+	workerTasks       metric.Int64Counter
+	dedupHits         metric.Int64Counter
+	expiredTasks      metric.Int64Counter
+	stuckJobs         metric.Int64Counter
+
+	// 队列深度指标，由ObserveQueueDepth注册的采样函数在采集时填充
+	queueOldestAge metric.Float64ObservableGauge
+
+	// 错误注入指标
+	errorInjections metric.Int64Counter
+	injectedDelay   metric.Float64Histogram
+
+	// SLO指标，由TrackSLOs声明
+	slos *sloMetrics
 }
 
 // NewMetricCollector 创建指标收集器
@@ -116,7 +156,7 @@ func NewMetricCollector(meter metric.Meter, logger *Logger) (*MetricCollector, e
 
 	if collector.queueSize, err = meter.Int64ObservableGauge(
 		"queue_size",
-		metric.WithDescription("Current queue size"),
+		metric.WithDescription("Current number of tasks in the queue by state"),
 	); err != nil {
 		return nil, fmt.Errorf("failed to create queue_size gauge: %w", err)
 	}
@@ -128,6 +168,143 @@ func NewMetricCollector(meter metric.Meter, logger *Logger) (*MetricCollector, e
 		return nil, fmt.Errorf("failed to create errors_total counter: %w", err)
 	}
 
+	if collector.rateLimitDecisions, err = meter.Int64Counter(
+		"rate_limit_requests_total",
+		metric.WithDescription("Total number of rate limit decisions by tenant, plan and result"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create rate_limit_requests_total counter: %w", err)
+	}
+
+	if collector.invariantChecks, err = meter.Int64Counter(
+		"invariant_checks_total",
+		metric.WithDescription("Total number of invariant checks by invariant and result"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create invariant_checks_total counter: %w", err)
+	}
+
+	if collector.invariantViolations, err = meter.Int64Counter(
+		"invariant_violations_total",
+		metric.WithDescription("Total number of invariant violations by invariant and bucket"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create invariant_violations_total counter: %w", err)
+	}
+
+	if collector.cacheRequests, err = meter.Int64Counter(
+		"cache_requests_total",
+		metric.WithDescription("Total number of cache lookups by cache and result"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create cache_requests_total counter: %w", err)
+	}
+
+	if collector.blockedDeletes, err = meter.Int64Counter(
+		"object_deletes_blocked_total",
+		metric.WithDescription("Total number of deletes blocked by object retention or legal hold by bucket and reason"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create object_deletes_blocked_total counter: %w", err)
+	}
+
+	if collector.replicationLag, err = meter.Float64Histogram(
+		"replication_lag_seconds",
+		metric.WithDescription("Time from source write to replica write by destination"),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create replication_lag_seconds histogram: %w", err)
+	}
+
+	if collector.replicationPending, err = meter.Int64UpDownCounter(
+		"replication_pending_objects",
+		metric.WithDescription("Number of objects waiting to be replicated by destination"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create replication_pending_objects counter: %w", err)
+	}
+
+	if collector.replicationFailures, err = meter.Int64Counter(
+		"replication_failures_total",
+		metric.WithDescription("Total number of failed replication attempts by destination and reason"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create replication_failures_total counter: %w", err)
+	}
+
+	if collector.incidentCaptures, err = meter.Int64Counter(
+		"incident_captures_total",
+		metric.WithDescription("Total number of flight recorder incident captures by trigger and result"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create incident_captures_total counter: %w", err)
+	}
+
+	if collector.queueWorkers, err = meter.Int64UpDownCounter(
+		"queue_workers",
+		metric.WithDescription("Number of workers in the autoscaled queue worker pool"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create queue_workers counter: %w", err)
+	}
+
+	if collector.workerScaleEvents, err = meter.Int64Counter(
+		"queue_worker_scale_events_total",
+		metric.WithDescription("Total number of worker pool scale events by direction and reason"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create queue_worker_scale_events_total counter: %w", err)
+	}
+
+	if collector.taskDuration, err = meter.Float64Histogram(
+		"queue_task_duration_seconds",
+		metric.WithDescription("Task processing duration by task type and result"),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create queue_task_duration_seconds histogram: %w", err)
+	}
+
+	if collector.workerTasks, err = meter.Int64Counter(
+		"queue_worker_tasks_total",
+		metric.WithDescription("Total number of tasks processed by worker, task type and result"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create queue_worker_tasks_total counter: %w", err)
+	}
+
+	if collector.dedupHits, err = meter.Int64Counter(
+		"queue_dedup_hits_total",
+		metric.WithDescription("Total number of tasks dropped as duplicates within the dedup window by task type"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create queue_dedup_hits_total counter: %w", err)
+	}
+
+	if collector.expiredTasks, err = meter.Int64Counter(
+		"queue_tasks_expired_total",
+		metric.WithDescription("Total number of tasks dropped because their TTL expired before processing by task type"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create queue_tasks_expired_total counter: %w", err)
+	}
+
+	if collector.stuckJobs, err = meter.Int64Counter(
+		"queue_stuck_jobs_total",
+		metric.WithDescription("Total number of tasks requeued because their worker stopped heartbeating by task type"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create queue_stuck_jobs_total counter: %w", err)
+	}
+
+	if collector.queueOldestAge, err = meter.Float64ObservableGauge(
+		"queue_oldest_message_age_seconds",
+		metric.WithDescription("Age of the oldest task not yet acknowledged"),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create queue_oldest_message_age_seconds gauge: %w", err)
+	}
+
+	if collector.errorInjections, err = meter.Int64Counter(
+		"error_injections_total",
+		metric.WithDescription("Total number of error rule matches by namespace, rule, service, action type and whether the rule ran in dry-run mode"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create error_injections_total counter: %w", err)
+	}
+
+	if collector.injectedDelay, err = meter.Float64Histogram(
+		"error_injection_delay_seconds",
+		metric.WithDescription("Delay injected by error rules by service and action type"),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create error_injection_delay_seconds histogram: %w", err)
+	}
+
 	return collector, nil
 }
 
@@ -148,6 +325,8 @@ func (c *MetricCollector) RecordHTTPRequest(ctx context.Context, method, path st
 	if responseSize > 0 {
 		c.httpResponseSize.Record(ctx, responseSize, labels)
 	}
+
+	c.recordSLOs(path, statusCode, duration)
 }
 
 // RecordError 记录错误
@@ -157,6 +336,178 @@ func (c *MetricCollector) RecordError(ctx context.Context, errorType string) {
 	))
 }
 
+// RecordRateLimitDecision 记录限流决策（allowed, throttled, concurrency_rejected）
+func (c *MetricCollector) RecordRateLimitDecision(ctx context.Context, tenant, plan, result string) {
+	c.rateLimitDecisions.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("tenant", tenant),
+		attribute.String("plan", plan),
+		attribute.String("result", result),
+	))
+}
+
+// RecordInvariantCheck 记录一致性校验结果（pass, violation, error）
+func (c *MetricCollector) RecordInvariantCheck(ctx context.Context, invariant, result string) {
+	c.invariantChecks.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("invariant", invariant),
+		attribute.String("result", result),
+	))
+}
+
+// RecordInvariantViolation 记录一致性违规
+func (c *MetricCollector) RecordInvariantViolation(ctx context.Context, invariant, bucket string) {
+	c.invariantViolations.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("invariant", invariant),
+		attribute.String("bucket", bucket),
+	))
+}
+
+// RecordCacheRequest 记录缓存查询结果（hit, miss, error）
+func (c *MetricCollector) RecordCacheRequest(ctx context.Context, cache, result string) {
+	c.cacheRequests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("cache", cache),
+		attribute.String("result", result),
+	))
+}
+
+// RecordBlockedDelete 记录被保留设置阻止的删除（legal_hold, governance_retention, compliance_retention, bucket_retention）
+func (c *MetricCollector) RecordBlockedDelete(ctx context.Context, bucket, reason string) {
+	c.blockedDeletes.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("bucket", bucket),
+		attribute.String("reason", reason),
+	))
+}
+
+// RecordReplicationLag 记录一次完成复制的延迟
+func (c *MetricCollector) RecordReplicationLag(ctx context.Context, destination string, lag time.Duration) {
+	c.replicationLag.Record(ctx, lag.Seconds(), metric.WithAttributes(
+		attribute.String("destination", destination),
+	))
+}
+
+// AddReplicationPending 调整待复制对象数
+func (c *MetricCollector) AddReplicationPending(ctx context.Context, destination string, delta int64) {
+	c.replicationPending.Add(ctx, delta, metric.WithAttributes(
+		attribute.String("destination", destination),
+	))
+}
+
+// RecordReplicationFailure 记录复制失败（source_read, destination_write, queue_full或注入的动作类型）
+func (c *MetricCollector) RecordReplicationFailure(ctx context.Context, destination, reason string) {
+	c.replicationFailures.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("destination", destination),
+		attribute.String("reason", reason),
+	))
+}
+
+// RecordIncidentCapture 记录飞行记录器事件包转储（stored, failed, suppressed）
+func (c *MetricCollector) RecordIncidentCapture(ctx context.Context, trigger, result string) {
+	c.incidentCaptures.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("trigger", trigger),
+		attribute.String("result", result),
+	))
+}
+
+// RecordWorkerScale 记录worker池伸缩（direction为up或down，reason为startup、backlog、latency、idle或manual），delta为worker数变化
+func (c *MetricCollector) RecordWorkerScale(ctx context.Context, direction, reason string, delta int64) {
+	c.workerScaleEvents.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("direction", direction),
+		attribute.String("reason", reason),
+	))
+	c.queueWorkers.Add(ctx, delta)
+}
+
+// RecordTaskDuration 记录一次任务处理耗时（success, failure）
+func (c *MetricCollector) RecordTaskDuration(ctx context.Context, taskType, result string, duration time.Duration) {
+	c.taskDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(
+		attribute.String("task_type", taskType),
+		attribute.String("result", result),
+	))
+}
+
+// RecordWorkerTask 记录worker处理完成的一个任务（success, failure），用于统计每个worker的吞吐
+func (c *MetricCollector) RecordWorkerTask(ctx context.Context, workerID, taskType, result string) {
+	c.workerTasks.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("worker_id", workerID),
+		attribute.String("task_type", taskType),
+		attribute.String("result", result),
+	))
+}
+
+// RecordDedupHit 记录一个因payload与去重窗口内的任务相同而未入队的任务
+func (c *MetricCollector) RecordDedupHit(ctx context.Context, taskType string) {
+	c.dedupHits.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("task_type", taskType),
+	))
+}
+
+// RecordTaskExpired 记录一个过期前未开始执行而被丢弃的任务
+func (c *MetricCollector) RecordTaskExpired(ctx context.Context, taskType string) {
+	c.expiredTasks.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("task_type", taskType),
+	))
+}
+
+// RecordStuckJob 记录一个因worker停止心跳而重新入队的任务
+func (c *MetricCollector) RecordStuckJob(ctx context.Context, taskType string) {
+	c.stuckJobs.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("task_type", taskType),
+	))
+}
+
+// RecordErrorInjection 记录一次错误规则命中，dryRun为true表示只记录未执行动作
+func (c *MetricCollector) RecordErrorInjection(ctx context.Context, namespace, ruleID, service, actionType string, dryRun bool) {
+	c.errorInjections.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("namespace", namespace),
+		attribute.String("rule_id", ruleID),
+		attribute.String("service", service),
+		attribute.String("action_type", actionType),
+		attribute.Bool("dry_run", dryRun),
+	))
+}
+
+// RecordInjectedDelay 记录一次注入的延迟（delay、timeout或带延迟的HTTP错误等动作）
+func (c *MetricCollector) RecordInjectedDelay(ctx context.Context, service, actionType string, delay time.Duration) {
+	c.injectedDelay.Record(ctx, delay.Seconds(), metric.WithAttributes(
+		attribute.String("service", service),
+		attribute.String("action_type", actionType),
+	))
+}
+
+// QueueDepthSample 一个队列的深度采样
+type QueueDepthSample struct {
+	Queue     string
+	States    map[string]int64 // 按状态的任务数
+	OldestAge time.Duration    // 最早未确认任务的等待时间
+}
+
+// ObserveQueueDepth 注册队列深度采样函数，每次采集指标时调用，填充queue_size和queue_oldest_message_age_seconds
+func (c *MetricCollector) ObserveQueueDepth(sample func(ctx context.Context) ([]QueueDepthSample, error)) error {
+	_, err := c.meter.RegisterCallback(
+		func(ctx context.Context, observer metric.Observer) error {
+			samples, err := sample(ctx)
+			if err != nil {
+				c.logger.Warn(ctx, "Failed to sample queue depth", Error(err))
+				return nil
+			}
+
+			for _, s := range samples {
+				queue := attribute.String("queue", s.Queue)
+				for state, count := range s.States {
+					observer.ObserveInt64(c.queueSize, count, metric.WithAttributes(queue, attribute.String("state", state)))
+				}
+				observer.ObserveFloat64(c.queueOldestAge, s.OldestAge.Seconds(), metric.WithAttributes(queue))
+			}
+			return nil
+		},
+		c.queueSize,
+		c.queueOldestAge,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register queue depth callback: %w", err)
+	}
+	return nil
+}
+
 // IncrementActiveConnections 增加活跃连接数
 func (c *MetricCollector) IncrementActiveConnections(ctx context.Context) {
 	c.activeConnections.Add(ctx, 1)
@@ -3,10 +3,14 @@ package observability
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 
 	"mocks3/shared/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/grafana/pyroscope-go"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 // Config 简化的可观测性配置
@@ -16,6 +20,12 @@ type Config struct {
 	Environment    string
 	OTLPEndpoint   string
 	LogLevel       string
+	Debug          DebugConfig       // 调试服务（pprof等），默认关闭
+	Profiling      ProfilingConfig   // 持续性能分析，默认关闭
+	SLOs           []SLO             // 服务声明的SLO，按HTTP请求计算SLI和错误预算
+	AuditLog       AuditLogConfig    // 审计日志目标，为空时不记录
+	LogSampling    LogSamplingConfig // 按消息限制日志输出频率，默认不采样
+	LogFile        LogFileConfig     // 日志文件输出，Path为空时只输出到标准输出
 }
 
 // Observability 统一的可观测性实例
@@ -24,6 +34,10 @@ type Observability struct {
 	logger     *Logger
 	collector  *MetricCollector
 	middleware *HTTPMiddleware
+	debug      *http.Server
+	profiler   *pyroscope.Profiler
+	auditLog   *AuditLogger
+	logFile    io.Closer
 }
 
 // New 创建可观测性实例
@@ -45,12 +59,29 @@ func New(ctx context.Context, config *Config) (*Observability, error) {
 		return nil, fmt.Errorf("failed to create providers: %w", err)
 	}
 
+	providers.Logger.SetSampling(config.LogSampling)
+
+	var logFile io.Closer
+	if config.LogFile.Path != "" {
+		if logFile, err = providers.Logger.EnableFileOutput(config.LogFile); err != nil {
+			providers.Shutdown(ctx)
+			return nil, fmt.Errorf("failed to enable log file output: %w", err)
+		}
+	}
+
 	// 创建指标收集器
 	collector, err := NewMetricCollector(providers.Meter, providers.Logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create metric collector: %w", err)
 	}
 
+	if len(config.SLOs) > 0 {
+		if err := collector.TrackSLOs(config.SLOs); err != nil {
+			providers.Shutdown(ctx)
+			return nil, fmt.Errorf("failed to track slos: %w", err)
+		}
+	}
+
 	// 创建HTTP中间件
 	httpMiddleware := NewHTTPMiddleware(collector, providers.Logger)
 
@@ -59,6 +90,32 @@ func New(ctx context.Context, config *Config) (*Observability, error) {
 		logger:     providers.Logger,
 		collector:  collector,
 		middleware: httpMiddleware,
+		logFile:    logFile,
+	}
+
+	// 创建审计日志器
+	obs.auditLog, err = newAuditLogger(config.ServiceName, config.AuditLog, providers.Logger)
+	if err != nil {
+		providers.Shutdown(ctx)
+		return nil, fmt.Errorf("failed to create audit logger: %w", err)
+	}
+
+	// 启动调试服务
+	if config.Debug.Enabled {
+		obs.debug, err = startDebugServer(config.Debug, providers.Logger)
+		if err != nil {
+			providers.Shutdown(ctx)
+			return nil, fmt.Errorf("failed to start debug server: %w", err)
+		}
+	}
+
+	// 启动持续性能分析
+	if config.Profiling.Enabled {
+		obs.profiler, err = startProfiler(config, providers.Logger)
+		if err != nil {
+			obs.Shutdown(ctx)
+			return nil, fmt.Errorf("failed to start profiler: %w", err)
+		}
 	}
 
 	// 启动系统指标收集
@@ -72,6 +129,11 @@ func (o *Observability) Logger() *Logger {
 	return o.logger
 }
 
+// AuditLogger 获取审计日志器
+func (o *Observability) AuditLogger() *AuditLogger {
+	return o.auditLog
+}
+
 // Tracer 获取追踪器
 func (o *Observability) Tracer() interface{} {
 	return o.providers.Tracer
@@ -82,12 +144,40 @@ func (o *Observability) Meter() interface{} {
 	return o.providers.Meter
 }
 
+// Collector 获取指标收集器
+func (o *Observability) Collector() *MetricCollector {
+	return o.collector
+}
+
+// RegisterSpanProcessor 注册额外的span处理器（如飞行记录器），与OTLP导出并行接收已结束的span
+func (o *Observability) RegisterSpanProcessor(processor sdktrace.SpanProcessor) {
+	o.providers.traceProvider.RegisterSpanProcessor(processor)
+}
+
 // GinMiddleware 获取Gin中间件
 func (o *Observability) GinMiddleware() gin.HandlerFunc {
 	return o.middleware.GinMetricsMiddleware()
 }
 
+// GinTracingMiddleware 获取Gin追踪中间件，为每个请求创建服务端span
+func (o *Observability) GinTracingMiddleware() gin.HandlerFunc {
+	return o.middleware.GinTracingMiddleware()
+}
+
 // Shutdown 关闭可观测性组件
 func (o *Observability) Shutdown(ctx context.Context) error {
-	return o.providers.Shutdown(ctx)
+	if o.profiler != nil {
+		o.profiler.Stop()
+	}
+	if o.debug != nil {
+		o.debug.Shutdown(ctx)
+	}
+	if err := o.auditLog.Close(ctx); err != nil {
+		o.logger.Warn(ctx, "Failed to close audit log", Error(err))
+	}
+	err := o.providers.Shutdown(ctx)
+	if o.logFile != nil {
+		o.logFile.Close()
+	}
+	return err
 }
\ No newline at end of file
@@ -0,0 +1,101 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/pyroscope-go"
+)
+
+// defaultProfilingServer 默认Pyroscope服务地址
+const defaultProfilingServer = "http://pyroscope:4040"
+
+// ProfilingConfig 持续性能分析配置，按固定间隔把CPU、内存和goroutine profile推送到Pyroscope
+type ProfilingConfig struct {
+	Enabled           bool
+	ServerAddress     string
+	BasicAuthUser     string
+	BasicAuthPassword string
+	UploadRate        time.Duration     // 推送间隔，为0时使用客户端默认值（15秒）
+	Tags              map[string]string // 附加标签，服务名和版本总会带上
+}
+
+// ProfilingConfigFromEnv 根据环境变量读取持续性能分析配置
+//
+// PROFILING_ENABLED=true 开启；PROFILING_SERVER_ADDRESS 为Pyroscope地址（默认http://pyroscope:4040）；
+// PROFILING_BASIC_AUTH_USER/PROFILING_BASIC_AUTH_PASSWORD 为认证信息；PROFILING_UPLOAD_SECS 为推送间隔。
+func ProfilingConfigFromEnv() ProfilingConfig {
+	cfg := ProfilingConfig{
+		Enabled:           strings.EqualFold(os.Getenv("PROFILING_ENABLED"), "true"),
+		ServerAddress:     os.Getenv("PROFILING_SERVER_ADDRESS"),
+		BasicAuthUser:     os.Getenv("PROFILING_BASIC_AUTH_USER"),
+		BasicAuthPassword: os.Getenv("PROFILING_BASIC_AUTH_PASSWORD"),
+	}
+	if cfg.ServerAddress == "" {
+		cfg.ServerAddress = defaultProfilingServer
+	}
+	if secs, err := strconv.Atoi(os.Getenv("PROFILING_UPLOAD_SECS")); err == nil && secs > 0 {
+		cfg.UploadRate = time.Duration(secs) * time.Second
+	}
+	return cfg
+}
+
+// startProfiler 启动持续性能分析，profile的应用名为服务名，并按版本和环境打标签
+func startProfiler(config *Config, logger *Logger) (*pyroscope.Profiler, error) {
+	cfg := config.Profiling
+	tags := make(map[string]string, len(cfg.Tags)+2)
+	for key, value := range cfg.Tags {
+		tags[key] = value
+	}
+	tags["version"] = config.ServiceVersion
+	if config.Environment != "" {
+		tags["environment"] = config.Environment
+	}
+
+	profiler, err := pyroscope.Start(pyroscope.Config{
+		ApplicationName:   config.ServiceName,
+		ServerAddress:     cfg.ServerAddress,
+		BasicAuthUser:     cfg.BasicAuthUser,
+		BasicAuthPassword: cfg.BasicAuthPassword,
+		UploadRate:        cfg.UploadRate,
+		Tags:              tags,
+		Logger:            profilerLogger{logger: logger},
+		ProfileTypes: []pyroscope.ProfileType{
+			pyroscope.ProfileCPU,
+			pyroscope.ProfileAllocObjects,
+			pyroscope.ProfileAllocSpace,
+			pyroscope.ProfileInuseObjects,
+			pyroscope.ProfileInuseSpace,
+			pyroscope.ProfileGoroutines,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(context.Background(), "Continuous profiling started",
+		String("server", cfg.ServerAddress),
+		String("version", config.ServiceVersion))
+	return profiler, nil
+}
+
+// profilerLogger 把Pyroscope客户端日志写入服务日志
+type profilerLogger struct {
+	logger *Logger
+}
+
+func (l profilerLogger) Infof(format string, args ...any) {
+	l.logger.Debug(context.Background(), fmt.Sprintf(format, args...), String("component", "profiler"))
+}
+
+func (l profilerLogger) Debugf(format string, args ...any) {
+	l.logger.Debug(context.Background(), fmt.Sprintf(format, args...), String("component", "profiler"))
+}
+
+func (l profilerLogger) Errorf(format string, args ...any) {
+	l.logger.Warn(context.Background(), fmt.Sprintf(format, args...), String("component", "profiler"))
+}
@@ -6,8 +6,10 @@ import (
 	"mocks3/shared/utils"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -15,6 +17,7 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 	otrace "go.opentelemetry.io/otel/trace"
 
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 )
 
@@ -24,6 +27,7 @@ type Providers struct {
 	resource       *resource.Resource
 	metricProvider *sdkmetric.MeterProvider
 	traceProvider  *trace.TracerProvider
+	logProvider    *sdklog.LoggerProvider
 
 	// 公共接口
 	Logger *Logger
@@ -53,8 +57,13 @@ func NewProviders(config *utils.Config) (*Providers, error) {
 		return nil, fmt.Errorf("failed to init trace provider: %w", err)
 	}
 
+	if err := providers.initLogProvider(); err != nil {
+		return nil, fmt.Errorf("failed to init log provider: %w", err)
+	}
+
 	// 创建公共接口
 	providers.Logger = NewLogger(config.ServiceName, config.LogLevel)
+	providers.Logger.AddHook(newOTLPLogHook(providers.logProvider.Logger(config.ServiceName)))
 	providers.Meter = providers.metricProvider.Meter(config.ServiceName)
 	providers.Tracer = providers.traceProvider.Tracer(config.ServiceName)
 
@@ -119,6 +128,25 @@ func (p *Providers) initTraceProvider() error {
 	return nil
 }
 
+// initLogProvider 初始化日志提供者，日志在输出到标准输出的同时导出到OTLP端点
+func (p *Providers) initLogProvider() error {
+	exporter, err := otlploghttp.New(context.Background(),
+		otlploghttp.WithEndpoint(p.config.OTLPEndpoint),
+		otlploghttp.WithInsecure(),
+	)
+	if err != nil {
+		return err
+	}
+
+	p.logProvider = sdklog.NewLoggerProvider(
+		sdklog.WithResource(p.resource),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+	)
+
+	global.SetLoggerProvider(p.logProvider)
+	return nil
+}
+
 // Shutdown 关闭所有提供者
 func (p *Providers) Shutdown(ctx context.Context) error {
 	var errs []error
@@ -131,6 +159,10 @@ func (p *Providers) Shutdown(ctx context.Context) error {
 		errs = append(errs, fmt.Errorf("trace provider shutdown: %w", err))
 	}
 
+	if err := p.logProvider.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("log provider shutdown: %w", err))
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("shutdown errors: %v", errs)
 	}
@@ -0,0 +1,315 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// SLO类型
+const (
+	SLOKindAvailability = "availability" // 5xx响应计为坏请求
+	SLOKindLatency      = "latency"      // 慢于阈值的请求计为坏请求
+)
+
+// defaultSLOWindow 默认SLO统计窗口
+const defaultSLOWindow = 24 * time.Hour
+
+// sloBucketSize SLI按分钟分桶统计
+const sloBucketSize = time.Minute
+
+// sloBurnWindows 计算燃烧率的窗口，短窗口发现突发消耗，长窗口发现持续消耗
+var sloBurnWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+}
+
+// SLO 服务声明的目标，SLI由HTTP请求指标计算
+type SLO struct {
+	Name             string
+	Kind             string        // availability 或 latency
+	Objective        float64       // 好请求比例目标，如0.999
+	LatencyThreshold time.Duration // latency类型的阈值
+	Window           time.Duration // 统计窗口，为0时使用24小时
+	Routes           []string      // 只统计这些路由前缀的请求，为空时统计所有请求
+}
+
+// AvailabilitySLO 创建可用性SLO
+func AvailabilitySLO(name string, objective float64) SLO {
+	return SLO{Name: name, Kind: SLOKindAvailability, Objective: objective}
+}
+
+// LatencySLO 创建延迟SLO：至少objective比例的请求在threshold内完成
+func LatencySLO(name string, objective float64, threshold time.Duration) SLO {
+	return SLO{Name: name, Kind: SLOKindLatency, Objective: objective, LatencyThreshold: threshold}
+}
+
+// validate 检查SLO定义
+func (s SLO) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("slo name is required")
+	}
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return fmt.Errorf("slo %s: objective must be between 0 and 1: %v", s.Name, s.Objective)
+	}
+	switch s.Kind {
+	case SLOKindAvailability:
+	case SLOKindLatency:
+		if s.LatencyThreshold <= 0 {
+			return fmt.Errorf("slo %s: latency threshold is required", s.Name)
+		}
+	default:
+		return fmt.Errorf("slo %s: unknown kind: %s", s.Name, s.Kind)
+	}
+	if s.Window < 0 || (s.Window > 0 && s.Window < sloBucketSize) {
+		return fmt.Errorf("slo %s: window must be at least %s", s.Name, sloBucketSize)
+	}
+	return nil
+}
+
+// matches 请求路由是否计入该SLO
+func (s SLO) matches(path string) bool {
+	if len(s.Routes) == 0 {
+		return true
+	}
+	for _, route := range s.Routes {
+		if strings.HasPrefix(path, route) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBad 请求是否消耗错误预算
+func (s SLO) isBad(statusCode int, duration time.Duration) bool {
+	if s.Kind == SLOKindLatency {
+		return duration > s.LatencyThreshold
+	}
+	return statusCode >= 500
+}
+
+// SLOStatus SLO当前状态
+type SLOStatus struct {
+	Name            string             `json:"name"`
+	Kind            string             `json:"kind"`
+	Objective       float64            `json:"objective"`
+	Window          string             `json:"window"`
+	Requests        int64              `json:"requests"`
+	BadRequests     int64              `json:"bad_requests"`
+	SLI             float64            `json:"sli"`              // 窗口内好请求比例，无请求时为1
+	BudgetRemaining float64            `json:"budget_remaining"` // 剩余错误预算比例，耗尽后为负
+	BurnRates       map[string]float64 `json:"burn_rates"`       // 各窗口的燃烧率，1表示恰好在窗口结束时耗尽预算
+}
+
+// sloBucket 一分钟内的请求计数
+type sloBucket struct {
+	minute int64
+	total  int64
+	bad    int64
+}
+
+// sloTracker 按分钟环形缓冲区统计一个SLO的请求
+type sloTracker struct {
+	slo     SLO
+	mu      sync.Mutex
+	buckets []sloBucket
+}
+
+func newSLOTracker(slo SLO) *sloTracker {
+	if slo.Window == 0 {
+		slo.Window = defaultSLOWindow
+	}
+	return &sloTracker{
+		slo:     slo,
+		buckets: make([]sloBucket, int(slo.Window/sloBucketSize)),
+	}
+}
+
+// record 记录一个请求
+func (t *sloTracker) record(now time.Time, bad bool) {
+	minute := now.Unix() / int64(sloBucketSize/time.Second)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	bucket := &t.buckets[minute%int64(len(t.buckets))]
+	if bucket.minute != minute {
+		*bucket = sloBucket{minute: minute}
+	}
+	bucket.total++
+	if bad {
+		bucket.bad++
+	}
+}
+
+// countLocked 统计最近window内的请求数和坏请求数，调用方持有锁
+func (t *sloTracker) countLocked(now time.Time, window time.Duration) (total, bad int64) {
+	minute := now.Unix() / int64(sloBucketSize/time.Second)
+	n := min(int64(window/sloBucketSize), int64(len(t.buckets)))
+	for i := int64(0); i < n; i++ {
+		bucket := t.buckets[(minute-i)%int64(len(t.buckets))]
+		if bucket.minute == minute-i {
+			total += bucket.total
+			bad += bucket.bad
+		}
+	}
+	return total, bad
+}
+
+// status 计算SLI、剩余预算和燃烧率
+func (t *sloTracker) status(now time.Time) SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	budget := 1 - t.slo.Objective
+	total, bad := t.countLocked(now, t.slo.Window)
+	status := SLOStatus{
+		Name:            t.slo.Name,
+		Kind:            t.slo.Kind,
+		Objective:       t.slo.Objective,
+		Window:          t.slo.Window.String(),
+		Requests:        total,
+		BadRequests:     bad,
+		SLI:             1,
+		BudgetRemaining: 1,
+		BurnRates:       make(map[string]float64, len(sloBurnWindows)),
+	}
+	if total > 0 {
+		errorRate := float64(bad) / float64(total)
+		status.SLI = 1 - errorRate
+		status.BudgetRemaining = 1 - errorRate/budget
+	}
+
+	for _, window := range sloBurnWindows {
+		if window.duration > t.slo.Window {
+			continue
+		}
+		total, bad := t.countLocked(now, window.duration)
+		rate := 0.0
+		if total > 0 {
+			rate = float64(bad) / float64(total) / budget
+		}
+		status.BurnRates[window.name] = rate
+	}
+	return status
+}
+
+// sloMetrics SLO指标
+type sloMetrics struct {
+	trackers        []*sloTracker
+	sli             metric.Float64ObservableGauge
+	budgetRemaining metric.Float64ObservableGauge
+	burnRate        metric.Float64ObservableGauge
+	objective       metric.Float64ObservableGauge
+}
+
+// TrackSLOs 声明服务的SLO，之后每个HTTP请求按SLO分类计数，采集指标时计算slo_sli、slo_error_budget_remaining和slo_burn_rate
+func (c *MetricCollector) TrackSLOs(slos []SLO) error {
+	names := make(map[string]bool, len(slos))
+	m := &sloMetrics{}
+	for _, slo := range slos {
+		if err := slo.validate(); err != nil {
+			return err
+		}
+		if names[slo.Name] {
+			return fmt.Errorf("duplicate slo name: %s", slo.Name)
+		}
+		names[slo.Name] = true
+		m.trackers = append(m.trackers, newSLOTracker(slo))
+	}
+
+	var err error
+	if m.sli, err = c.meter.Float64ObservableGauge(
+		"slo_sli",
+		metric.WithDescription("Ratio of good requests over the SLO window"),
+	); err != nil {
+		return fmt.Errorf("failed to create slo_sli gauge: %w", err)
+	}
+	if m.budgetRemaining, err = c.meter.Float64ObservableGauge(
+		"slo_error_budget_remaining",
+		metric.WithDescription("Fraction of the error budget left over the SLO window, negative once exhausted"),
+	); err != nil {
+		return fmt.Errorf("failed to create slo_error_budget_remaining gauge: %w", err)
+	}
+	if m.burnRate, err = c.meter.Float64ObservableGauge(
+		"slo_burn_rate",
+		metric.WithDescription("Error budget burn rate by window, 1 means the budget runs out exactly at the end of the SLO window"),
+	); err != nil {
+		return fmt.Errorf("failed to create slo_burn_rate gauge: %w", err)
+	}
+	if m.objective, err = c.meter.Float64ObservableGauge(
+		"slo_objective",
+		metric.WithDescription("Declared SLO objective"),
+	); err != nil {
+		return fmt.Errorf("failed to create slo_objective gauge: %w", err)
+	}
+
+	_, err = c.meter.RegisterCallback(
+		func(ctx context.Context, observer metric.Observer) error {
+			now := time.Now()
+			for _, tracker := range m.trackers {
+				status := tracker.status(now)
+				attrs := metric.WithAttributes(
+					attribute.String("slo", status.Name),
+					attribute.String("kind", status.Kind),
+				)
+				observer.ObserveFloat64(m.sli, status.SLI, attrs)
+				observer.ObserveFloat64(m.budgetRemaining, status.BudgetRemaining, attrs)
+				observer.ObserveFloat64(m.objective, status.Objective, attrs)
+				for window, rate := range status.BurnRates {
+					observer.ObserveFloat64(m.burnRate, rate, metric.WithAttributes(
+						attribute.String("slo", status.Name),
+						attribute.String("kind", status.Kind),
+						attribute.String("window", window),
+					))
+				}
+			}
+			return nil
+		},
+		m.sli,
+		m.budgetRemaining,
+		m.burnRate,
+		m.objective,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register slo callback: %w", err)
+	}
+
+	c.slos = m
+	return nil
+}
+
+// recordSLOs 按请求结果更新各SLO的计数
+func (c *MetricCollector) recordSLOs(path string, statusCode int, duration time.Duration) {
+	if c.slos == nil {
+		return
+	}
+	now := time.Now()
+	for _, tracker := range c.slos.trackers {
+		if tracker.slo.matches(path) {
+			tracker.record(now, tracker.slo.isBad(statusCode, duration))
+		}
+	}
+}
+
+// SLOStatus 返回各SLO的当前状态，未声明SLO时返回空
+func (c *MetricCollector) SLOStatus() []SLOStatus {
+	if c.slos == nil {
+		return nil
+	}
+	now := time.Now()
+	statuses := make([]SLOStatus, 0, len(c.slos.trackers))
+	for _, tracker := range c.slos.trackers {
+		statuses = append(statuses, tracker.status(now))
+	}
+	return statuses
+}
@@ -0,0 +1,137 @@
+package metadatapb
+
+import (
+	"mocks3/shared/models"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromModel 将元数据模型转换为protobuf消息
+func FromModel(metadata *models.Metadata) *Metadata {
+	if metadata == nil {
+		return nil
+	}
+	return &Metadata{
+		Id:           metadata.ID,
+		Key:          metadata.Key,
+		Bucket:       metadata.Bucket,
+		Size:         metadata.Size,
+		ContentType:  metadata.ContentType,
+		Md5Hash:      metadata.MD5Hash,
+		Etag:         metadata.ETag,
+		StorageNodes: metadata.StorageNodes,
+		Headers:      metadata.Headers,
+		Tags:         metadata.Tags,
+		Status:       metadata.Status,
+		Version:      metadata.Version,
+		CreatedAt:    fromTime(metadata.CreatedAt),
+		UpdatedAt:    fromTime(metadata.UpdatedAt),
+		DeletedAt:    fromTimePtr(metadata.DeletedAt),
+	}
+}
+
+// ToModel 将protobuf消息转换为元数据模型
+func (m *Metadata) ToModel() *models.Metadata {
+	if m == nil {
+		return nil
+	}
+	metadata := &models.Metadata{
+		ID:           m.GetId(),
+		Key:          m.GetKey(),
+		Bucket:       m.GetBucket(),
+		Size:         m.GetSize(),
+		ContentType:  m.GetContentType(),
+		MD5Hash:      m.GetMd5Hash(),
+		ETag:         m.GetEtag(),
+		StorageNodes: m.GetStorageNodes(),
+		Headers:      m.GetHeaders(),
+		Tags:         m.GetTags(),
+		Status:       m.GetStatus(),
+		Version:      m.GetVersion(),
+		DeletedAt:    toTimePtr(m.GetDeletedAt()),
+	}
+	if m.GetCreatedAt() != nil {
+		metadata.CreatedAt = m.GetCreatedAt().AsTime()
+	}
+	if m.GetUpdatedAt() != nil {
+		metadata.UpdatedAt = m.GetUpdatedAt().AsTime()
+	}
+	return metadata
+}
+
+// FromModels 批量转换元数据模型
+func FromModels(list []*models.Metadata) []*Metadata {
+	result := make([]*Metadata, 0, len(list))
+	for _, metadata := range list {
+		result = append(result, FromModel(metadata))
+	}
+	return result
+}
+
+// FilterFromModel 将列表过滤条件转换为protobuf消息
+func FilterFromModel(filter *models.MetadataFilter) *MetadataFilter {
+	if filter == nil {
+		return nil
+	}
+	return &MetadataFilter{
+		Bucket:         filter.Bucket,
+		Prefix:         filter.Prefix,
+		Status:         filter.Status,
+		ContentType:    filter.ContentType,
+		SizeMin:        filter.SizeMin,
+		SizeMax:        filter.SizeMax,
+		CreatedFrom:    fromTimePtr(filter.CreatedFrom),
+		CreatedTo:      fromTimePtr(filter.CreatedTo),
+		ModifiedAfter:  fromTimePtr(filter.ModifiedAfter),
+		ModifiedBefore: fromTimePtr(filter.ModifiedBefore),
+		StartAfter:     filter.StartAfter,
+		EndKey:         filter.EndKey,
+	}
+}
+
+// ToModel 将列表过滤条件转换为模型，nil返回空过滤条件
+func (f *MetadataFilter) ToModel() *models.MetadataFilter {
+	if f == nil {
+		return &models.MetadataFilter{}
+	}
+	return &models.MetadataFilter{
+		Bucket:         f.GetBucket(),
+		Prefix:         f.GetPrefix(),
+		Status:         f.GetStatus(),
+		ContentType:    f.GetContentType(),
+		SizeMin:        f.SizeMin,
+		SizeMax:        f.SizeMax,
+		CreatedFrom:    toTimePtr(f.GetCreatedFrom()),
+		CreatedTo:      toTimePtr(f.GetCreatedTo()),
+		ModifiedAfter:  toTimePtr(f.GetModifiedAfter()),
+		ModifiedBefore: toTimePtr(f.GetModifiedBefore()),
+		StartAfter:     f.GetStartAfter(),
+		EndKey:         f.GetEndKey(),
+	}
+}
+
+// fromTime 零值时间转换为nil
+func fromTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// fromTimePtr 转换可选时间
+func fromTimePtr(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// toTimePtr 转换可选时间
+func toTimePtr(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
@@ -0,0 +1,120 @@
+package metadatapb
+
+import (
+	"strconv"
+	"time"
+
+	"mocks3/shared/models"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain gRPC错误详情中的错误域
+const errorDomain = "metadata.mocks3"
+
+// RetentionViolationStatus 将bucket保留期违规转换为FailedPrecondition状态，详情中携带保留信息
+func RetentionViolationStatus(violation *models.RetentionViolationError) *status.Status {
+	st := status.New(codes.FailedPrecondition, violation.Error())
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: models.ErrCodeRetentionViolation,
+		Domain: errorDomain,
+		Metadata: map[string]string{
+			"bucket":             violation.Bucket,
+			"key":                violation.Key,
+			"min_retention_days": strconv.Itoa(violation.MinRetentionDays),
+			"created_at":         violation.CreatedAt.UTC().Format(time.RFC3339Nano),
+			"retain_until":       violation.RetainUntil.UTC().Format(time.RFC3339Nano),
+		},
+	})
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// RetentionViolationFromStatus 从gRPC状态还原bucket保留期违规，不是该错误时返回nil
+func RetentionViolationFromStatus(st *status.Status) *models.RetentionViolationError {
+	if st.Code() != codes.FailedPrecondition {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetReason() != models.ErrCodeRetentionViolation || info.GetDomain() != errorDomain {
+			continue
+		}
+		metadata := info.GetMetadata()
+		violation := &models.RetentionViolationError{
+			Bucket: metadata["bucket"],
+			Key:    metadata["key"],
+		}
+		violation.MinRetentionDays, _ = strconv.Atoi(metadata["min_retention_days"])
+		violation.CreatedAt, _ = time.Parse(time.RFC3339Nano, metadata["created_at"])
+		violation.RetainUntil, _ = time.Parse(time.RFC3339Nano, metadata["retain_until"])
+		return violation
+	}
+	return nil
+}
+
+// SchemaViolationStatus 将bucket schema校验失败转换为InvalidArgument状态，详情中逐项携带违规
+func SchemaViolationStatus(validationErr *models.SchemaValidationError) *status.Status {
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range validationErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Path,
+			Description: violation.Message,
+			Reason:      violation.Keyword,
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, validationErr.Error())
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: models.ErrCodeSchemaViolation,
+		Domain: errorDomain,
+		Metadata: map[string]string{
+			"bucket": validationErr.Bucket,
+			"key":    validationErr.Key,
+		},
+	}, badRequest)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// SchemaViolationFromStatus 从gRPC状态还原bucket schema校验失败，不是该错误时返回nil
+func SchemaViolationFromStatus(st *status.Status) *models.SchemaValidationError {
+	if st.Code() != codes.InvalidArgument {
+		return nil
+	}
+
+	var validationErr *models.SchemaValidationError
+	var fieldViolations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetReason() == models.ErrCodeSchemaViolation && d.GetDomain() == errorDomain {
+				validationErr = &models.SchemaValidationError{
+					Bucket: d.GetMetadata()["bucket"],
+					Key:    d.GetMetadata()["key"],
+				}
+			}
+		case *errdetails.BadRequest:
+			fieldViolations = d.GetFieldViolations()
+		}
+	}
+	if validationErr == nil {
+		return nil
+	}
+
+	validationErr.Violations = make([]models.SchemaViolation, 0, len(fieldViolations))
+	for _, violation := range fieldViolations {
+		validationErr.Violations = append(validationErr.Violations, models.SchemaViolation{
+			Path:    violation.GetField(),
+			Keyword: violation.GetReason(),
+			Message: violation.GetDescription(),
+		})
+	}
+	return validationErr
+}
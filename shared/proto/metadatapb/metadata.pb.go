@@ -0,0 +1,1278 @@
+// 元数据服务gRPC接口：与HTTP API共用同一业务实现，供存储服务等内部服务低延迟调用
+//
+// 修改后执行 make proto 重新生成 shared/proto/metadatapb
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: metadata.proto
+
+package metadatapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Metadata 对象元数据
+type Metadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Bucket        string                 `protobuf:"bytes,3,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	ContentType   string                 `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Md5Hash       string                 `protobuf:"bytes,6,opt,name=md5_hash,json=md5Hash,proto3" json:"md5_hash,omitempty"`
+	Etag          string                 `protobuf:"bytes,7,opt,name=etag,proto3" json:"etag,omitempty"`
+	StorageNodes  []string               `protobuf:"bytes,8,rep,name=storage_nodes,json=storageNodes,proto3" json:"storage_nodes,omitempty"`
+	Headers       map[string]string      `protobuf:"bytes,9,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Tags          map[string]string      `protobuf:"bytes,10,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Status        string                 `protobuf:"bytes,11,opt,name=status,proto3" json:"status,omitempty"`
+	Version       int64                  `protobuf:"varint,12,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt     *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Metadata) Reset() {
+	*x = Metadata{}
+	mi := &file_metadata_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metadata) ProtoMessage() {}
+
+func (x *Metadata) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metadata.ProtoReflect.Descriptor instead.
+func (*Metadata) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Metadata) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Metadata) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Metadata) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *Metadata) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Metadata) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Metadata) GetMd5Hash() string {
+	if x != nil {
+		return x.Md5Hash
+	}
+	return ""
+}
+
+func (x *Metadata) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *Metadata) GetStorageNodes() []string {
+	if x != nil {
+		return x.StorageNodes
+	}
+	return nil
+}
+
+func (x *Metadata) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Metadata) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Metadata) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Metadata) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Metadata) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Metadata) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Metadata) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+// MetadataFilter 列表过滤条件，未设置的字段不过滤
+type MetadataFilter struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Bucket         string                 `protobuf:"bytes,1,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Prefix         string                 `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Status         string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	ContentType    string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // 精确匹配或尾部通配（如 image/*）
+	SizeMin        *int64                 `protobuf:"varint,5,opt,name=size_min,json=sizeMin,proto3,oneof" json:"size_min,omitempty"`
+	SizeMax        *int64                 `protobuf:"varint,6,opt,name=size_max,json=sizeMax,proto3,oneof" json:"size_max,omitempty"`
+	CreatedFrom    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`
+	CreatedTo      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`
+	ModifiedAfter  *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=modified_after,json=modifiedAfter,proto3" json:"modified_after,omitempty"`
+	ModifiedBefore *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=modified_before,json=modifiedBefore,proto3" json:"modified_before,omitempty"`
+	StartAfter     string                 `protobuf:"bytes,11,opt,name=start_after,json=startAfter,proto3" json:"start_after,omitempty"`
+	EndKey         string                 `protobuf:"bytes,12,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *MetadataFilter) Reset() {
+	*x = MetadataFilter{}
+	mi := &file_metadata_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetadataFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataFilter) ProtoMessage() {}
+
+func (x *MetadataFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataFilter.ProtoReflect.Descriptor instead.
+func (*MetadataFilter) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MetadataFilter) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *MetadataFilter) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *MetadataFilter) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *MetadataFilter) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *MetadataFilter) GetSizeMin() int64 {
+	if x != nil && x.SizeMin != nil {
+		return *x.SizeMin
+	}
+	return 0
+}
+
+func (x *MetadataFilter) GetSizeMax() int64 {
+	if x != nil && x.SizeMax != nil {
+		return *x.SizeMax
+	}
+	return 0
+}
+
+func (x *MetadataFilter) GetCreatedFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return nil
+}
+
+func (x *MetadataFilter) GetCreatedTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return nil
+}
+
+func (x *MetadataFilter) GetModifiedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ModifiedAfter
+	}
+	return nil
+}
+
+func (x *MetadataFilter) GetModifiedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ModifiedBefore
+	}
+	return nil
+}
+
+func (x *MetadataFilter) GetStartAfter() string {
+	if x != nil {
+		return x.StartAfter
+	}
+	return ""
+}
+
+func (x *MetadataFilter) GetEndKey() string {
+	if x != nil {
+		return x.EndKey
+	}
+	return ""
+}
+
+type SaveMetadataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metadata      *Metadata              `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveMetadataRequest) Reset() {
+	*x = SaveMetadataRequest{}
+	mi := &file_metadata_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveMetadataRequest) ProtoMessage() {}
+
+func (x *SaveMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveMetadataRequest.ProtoReflect.Descriptor instead.
+func (*SaveMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SaveMetadataRequest) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type SaveMetadataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metadata      *Metadata              `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveMetadataResponse) Reset() {
+	*x = SaveMetadataResponse{}
+	mi := &file_metadata_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveMetadataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveMetadataResponse) ProtoMessage() {}
+
+func (x *SaveMetadataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveMetadataResponse.ProtoReflect.Descriptor instead.
+func (*SaveMetadataResponse) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SaveMetadataResponse) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type GetMetadataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bucket        string                 `protobuf:"bytes,1,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetadataRequest) Reset() {
+	*x = GetMetadataRequest{}
+	mi := &file_metadata_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetadataRequest) ProtoMessage() {}
+
+func (x *GetMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetadataRequest.ProtoReflect.Descriptor instead.
+func (*GetMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetMetadataRequest) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *GetMetadataRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type GetMetadataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metadata      *Metadata              `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetadataResponse) Reset() {
+	*x = GetMetadataResponse{}
+	mi := &file_metadata_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetadataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetadataResponse) ProtoMessage() {}
+
+func (x *GetMetadataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetadataResponse.ProtoReflect.Descriptor instead.
+func (*GetMetadataResponse) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetMetadataResponse) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type UpdateMetadataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metadata      *Metadata              `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMetadataRequest) Reset() {
+	*x = UpdateMetadataRequest{}
+	mi := &file_metadata_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMetadataRequest) ProtoMessage() {}
+
+func (x *UpdateMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMetadataRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateMetadataRequest) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type UpdateMetadataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metadata      *Metadata              `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMetadataResponse) Reset() {
+	*x = UpdateMetadataResponse{}
+	mi := &file_metadata_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMetadataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMetadataResponse) ProtoMessage() {}
+
+func (x *UpdateMetadataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMetadataResponse.ProtoReflect.Descriptor instead.
+func (*UpdateMetadataResponse) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateMetadataResponse) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type DeleteMetadataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bucket        string                 `protobuf:"bytes,1,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMetadataRequest) Reset() {
+	*x = DeleteMetadataRequest{}
+	mi := &file_metadata_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMetadataRequest) ProtoMessage() {}
+
+func (x *DeleteMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMetadataRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteMetadataRequest) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *DeleteMetadataRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type DeleteMetadataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMetadataResponse) Reset() {
+	*x = DeleteMetadataResponse{}
+	mi := &file_metadata_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMetadataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMetadataResponse) ProtoMessage() {}
+
+func (x *DeleteMetadataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMetadataResponse.ProtoReflect.Descriptor instead.
+func (*DeleteMetadataResponse) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{9}
+}
+
+type ListMetadataRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Filter            *MetadataFilter        `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	ContinuationToken string                 `protobuf:"bytes,2,opt,name=continuation_token,json=continuationToken,proto3" json:"continuation_token,omitempty"`
+	Limit             int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ListMetadataRequest) Reset() {
+	*x = ListMetadataRequest{}
+	mi := &file_metadata_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMetadataRequest) ProtoMessage() {}
+
+func (x *ListMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMetadataRequest.ProtoReflect.Descriptor instead.
+func (*ListMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListMetadataRequest) GetFilter() *MetadataFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListMetadataRequest) GetContinuationToken() string {
+	if x != nil {
+		return x.ContinuationToken
+	}
+	return ""
+}
+
+func (x *ListMetadataRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListMetadataResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Metadata              []*Metadata            `protobuf:"bytes,1,rep,name=metadata,proto3" json:"metadata,omitempty"`
+	IsTruncated           bool                   `protobuf:"varint,2,opt,name=is_truncated,json=isTruncated,proto3" json:"is_truncated,omitempty"`
+	NextContinuationToken string                 `protobuf:"bytes,3,opt,name=next_continuation_token,json=nextContinuationToken,proto3" json:"next_continuation_token,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ListMetadataResponse) Reset() {
+	*x = ListMetadataResponse{}
+	mi := &file_metadata_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMetadataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMetadataResponse) ProtoMessage() {}
+
+func (x *ListMetadataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMetadataResponse.ProtoReflect.Descriptor instead.
+func (*ListMetadataResponse) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListMetadataResponse) GetMetadata() []*Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ListMetadataResponse) GetIsTruncated() bool {
+	if x != nil {
+		return x.IsTruncated
+	}
+	return false
+}
+
+func (x *ListMetadataResponse) GetNextContinuationToken() string {
+	if x != nil {
+		return x.NextContinuationToken
+	}
+	return ""
+}
+
+type CountObjectsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bucket        string                 `protobuf:"bytes,1,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Prefix        string                 `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountObjectsRequest) Reset() {
+	*x = CountObjectsRequest{}
+	mi := &file_metadata_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountObjectsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountObjectsRequest) ProtoMessage() {}
+
+func (x *CountObjectsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountObjectsRequest.ProtoReflect.Descriptor instead.
+func (*CountObjectsRequest) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CountObjectsRequest) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *CountObjectsRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type CountObjectsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountObjectsResponse) Reset() {
+	*x = CountObjectsResponse{}
+	mi := &file_metadata_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountObjectsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountObjectsResponse) ProtoMessage() {}
+
+func (x *CountObjectsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountObjectsResponse.ProtoReflect.Descriptor instead.
+func (*CountObjectsResponse) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CountObjectsResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type FindMetadataByHashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindMetadataByHashRequest) Reset() {
+	*x = FindMetadataByHashRequest{}
+	mi := &file_metadata_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindMetadataByHashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindMetadataByHashRequest) ProtoMessage() {}
+
+func (x *FindMetadataByHashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindMetadataByHashRequest.ProtoReflect.Descriptor instead.
+func (*FindMetadataByHashRequest) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *FindMetadataByHashRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *FindMetadataByHashRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FindMetadataByHashRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type FindMetadataByHashResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metadata      []*Metadata            `protobuf:"bytes,1,rep,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindMetadataByHashResponse) Reset() {
+	*x = FindMetadataByHashResponse{}
+	mi := &file_metadata_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindMetadataByHashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindMetadataByHashResponse) ProtoMessage() {}
+
+func (x *FindMetadataByHashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindMetadataByHashResponse.ProtoReflect.Descriptor instead.
+func (*FindMetadataByHashResponse) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *FindMetadataByHashResponse) GetMetadata() []*Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ValidateMetadataSchemaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metadata      *Metadata              `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateMetadataSchemaRequest) Reset() {
+	*x = ValidateMetadataSchemaRequest{}
+	mi := &file_metadata_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateMetadataSchemaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateMetadataSchemaRequest) ProtoMessage() {}
+
+func (x *ValidateMetadataSchemaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateMetadataSchemaRequest.ProtoReflect.Descriptor instead.
+func (*ValidateMetadataSchemaRequest) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ValidateMetadataSchemaRequest) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ValidateMetadataSchemaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateMetadataSchemaResponse) Reset() {
+	*x = ValidateMetadataSchemaResponse{}
+	mi := &file_metadata_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateMetadataSchemaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateMetadataSchemaResponse) ProtoMessage() {}
+
+func (x *ValidateMetadataSchemaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metadata_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateMetadataSchemaResponse.ProtoReflect.Descriptor instead.
+func (*ValidateMetadataSchemaResponse) Descriptor() ([]byte, []int) {
+	return file_metadata_proto_rawDescGZIP(), []int{17}
+}
+
+var File_metadata_proto protoreflect.FileDescriptor
+
+const file_metadata_proto_rawDesc = "" +
+	"\n" +
+	"\x0emetadata.proto\x12\x12mocks3.metadata.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa8\x05\n" +
+	"\bMetadata\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x16\n" +
+	"\x06bucket\x18\x03 \x01(\tR\x06bucket\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x12\x19\n" +
+	"\bmd5_hash\x18\x06 \x01(\tR\amd5Hash\x12\x12\n" +
+	"\x04etag\x18\a \x01(\tR\x04etag\x12#\n" +
+	"\rstorage_nodes\x18\b \x03(\tR\fstorageNodes\x12C\n" +
+	"\aheaders\x18\t \x03(\v2).mocks3.metadata.v1.Metadata.HeadersEntryR\aheaders\x12:\n" +
+	"\x04tags\x18\n" +
+	" \x03(\v2&.mocks3.metadata.v1.Metadata.TagsEntryR\x04tags\x12\x16\n" +
+	"\x06status\x18\v \x01(\tR\x06status\x12\x18\n" +
+	"\aversion\x18\f \x01(\x03R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x129\n" +
+	"\n" +
+	"deleted_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x91\x04\n" +
+	"\x0eMetadataFilter\x12\x16\n" +
+	"\x06bucket\x18\x01 \x01(\tR\x06bucket\x12\x16\n" +
+	"\x06prefix\x18\x02 \x01(\tR\x06prefix\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12\x1e\n" +
+	"\bsize_min\x18\x05 \x01(\x03H\x00R\asizeMin\x88\x01\x01\x12\x1e\n" +
+	"\bsize_max\x18\x06 \x01(\x03H\x01R\asizeMax\x88\x01\x01\x12=\n" +
+	"\fcreated_from\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedFrom\x129\n" +
+	"\n" +
+	"created_to\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedTo\x12A\n" +
+	"\x0emodified_after\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\rmodifiedAfter\x12C\n" +
+	"\x0fmodified_before\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x0emodifiedBefore\x12\x1f\n" +
+	"\vstart_after\x18\v \x01(\tR\n" +
+	"startAfter\x12\x17\n" +
+	"\aend_key\x18\f \x01(\tR\x06endKeyB\v\n" +
+	"\t_size_minB\v\n" +
+	"\t_size_max\"O\n" +
+	"\x13SaveMetadataRequest\x128\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x1c.mocks3.metadata.v1.MetadataR\bmetadata\"P\n" +
+	"\x14SaveMetadataResponse\x128\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x1c.mocks3.metadata.v1.MetadataR\bmetadata\">\n" +
+	"\x12GetMetadataRequest\x12\x16\n" +
+	"\x06bucket\x18\x01 \x01(\tR\x06bucket\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"O\n" +
+	"\x13GetMetadataResponse\x128\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x1c.mocks3.metadata.v1.MetadataR\bmetadata\"Q\n" +
+	"\x15UpdateMetadataRequest\x128\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x1c.mocks3.metadata.v1.MetadataR\bmetadata\"R\n" +
+	"\x16UpdateMetadataResponse\x128\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x1c.mocks3.metadata.v1.MetadataR\bmetadata\"A\n" +
+	"\x15DeleteMetadataRequest\x12\x16\n" +
+	"\x06bucket\x18\x01 \x01(\tR\x06bucket\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"\x18\n" +
+	"\x16DeleteMetadataResponse\"\x96\x01\n" +
+	"\x13ListMetadataRequest\x12:\n" +
+	"\x06filter\x18\x01 \x01(\v2\".mocks3.metadata.v1.MetadataFilterR\x06filter\x12-\n" +
+	"\x12continuation_token\x18\x02 \x01(\tR\x11continuationToken\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\xab\x01\n" +
+	"\x14ListMetadataResponse\x128\n" +
+	"\bmetadata\x18\x01 \x03(\v2\x1c.mocks3.metadata.v1.MetadataR\bmetadata\x12!\n" +
+	"\fis_truncated\x18\x02 \x01(\bR\visTruncated\x126\n" +
+	"\x17next_continuation_token\x18\x03 \x01(\tR\x15nextContinuationToken\"E\n" +
+	"\x13CountObjectsRequest\x12\x16\n" +
+	"\x06bucket\x18\x01 \x01(\tR\x06bucket\x12\x16\n" +
+	"\x06prefix\x18\x02 \x01(\tR\x06prefix\",\n" +
+	"\x14CountObjectsResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"Y\n" +
+	"\x19FindMetadataByHashRequest\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"V\n" +
+	"\x1aFindMetadataByHashResponse\x128\n" +
+	"\bmetadata\x18\x01 \x03(\v2\x1c.mocks3.metadata.v1.MetadataR\bmetadata\"Y\n" +
+	"\x1dValidateMetadataSchemaRequest\x128\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x1c.mocks3.metadata.v1.MetadataR\bmetadata\" \n" +
+	"\x1eValidateMetadataSchemaResponse2\xe2\x06\n" +
+	"\x0fMetadataService\x12a\n" +
+	"\fSaveMetadata\x12'.mocks3.metadata.v1.SaveMetadataRequest\x1a(.mocks3.metadata.v1.SaveMetadataResponse\x12^\n" +
+	"\vGetMetadata\x12&.mocks3.metadata.v1.GetMetadataRequest\x1a'.mocks3.metadata.v1.GetMetadataResponse\x12g\n" +
+	"\x0eUpdateMetadata\x12).mocks3.metadata.v1.UpdateMetadataRequest\x1a*.mocks3.metadata.v1.UpdateMetadataResponse\x12g\n" +
+	"\x0eDeleteMetadata\x12).mocks3.metadata.v1.DeleteMetadataRequest\x1a*.mocks3.metadata.v1.DeleteMetadataResponse\x12a\n" +
+	"\fListMetadata\x12'.mocks3.metadata.v1.ListMetadataRequest\x1a(.mocks3.metadata.v1.ListMetadataResponse\x12a\n" +
+	"\fCountObjects\x12'.mocks3.metadata.v1.CountObjectsRequest\x1a(.mocks3.metadata.v1.CountObjectsResponse\x12s\n" +
+	"\x12FindMetadataByHash\x12-.mocks3.metadata.v1.FindMetadataByHashRequest\x1a..mocks3.metadata.v1.FindMetadataByHashResponse\x12\x7f\n" +
+	"\x16ValidateMetadataSchema\x121.mocks3.metadata.v1.ValidateMetadataSchemaRequest\x1a2.mocks3.metadata.v1.ValidateMetadataSchemaResponseB+Z)mocks3/shared/proto/metadatapb;metadatapbb\x06proto3"
+
+var (
+	file_metadata_proto_rawDescOnce sync.Once
+	file_metadata_proto_rawDescData []byte
+)
+
+func file_metadata_proto_rawDescGZIP() []byte {
+	file_metadata_proto_rawDescOnce.Do(func() {
+		file_metadata_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_metadata_proto_rawDesc), len(file_metadata_proto_rawDesc)))
+	})
+	return file_metadata_proto_rawDescData
+}
+
+var file_metadata_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_metadata_proto_goTypes = []any{
+	(*Metadata)(nil),                       // 0: mocks3.metadata.v1.Metadata
+	(*MetadataFilter)(nil),                 // 1: mocks3.metadata.v1.MetadataFilter
+	(*SaveMetadataRequest)(nil),            // 2: mocks3.metadata.v1.SaveMetadataRequest
+	(*SaveMetadataResponse)(nil),           // 3: mocks3.metadata.v1.SaveMetadataResponse
+	(*GetMetadataRequest)(nil),             // 4: mocks3.metadata.v1.GetMetadataRequest
+	(*GetMetadataResponse)(nil),            // 5: mocks3.metadata.v1.GetMetadataResponse
+	(*UpdateMetadataRequest)(nil),          // 6: mocks3.metadata.v1.UpdateMetadataRequest
+	(*UpdateMetadataResponse)(nil),         // 7: mocks3.metadata.v1.UpdateMetadataResponse
+	(*DeleteMetadataRequest)(nil),          // 8: mocks3.metadata.v1.DeleteMetadataRequest
+	(*DeleteMetadataResponse)(nil),         // 9: mocks3.metadata.v1.DeleteMetadataResponse
+	(*ListMetadataRequest)(nil),            // 10: mocks3.metadata.v1.ListMetadataRequest
+	(*ListMetadataResponse)(nil),           // 11: mocks3.metadata.v1.ListMetadataResponse
+	(*CountObjectsRequest)(nil),            // 12: mocks3.metadata.v1.CountObjectsRequest
+	(*CountObjectsResponse)(nil),           // 13: mocks3.metadata.v1.CountObjectsResponse
+	(*FindMetadataByHashRequest)(nil),      // 14: mocks3.metadata.v1.FindMetadataByHashRequest
+	(*FindMetadataByHashResponse)(nil),     // 15: mocks3.metadata.v1.FindMetadataByHashResponse
+	(*ValidateMetadataSchemaRequest)(nil),  // 16: mocks3.metadata.v1.ValidateMetadataSchemaRequest
+	(*ValidateMetadataSchemaResponse)(nil), // 17: mocks3.metadata.v1.ValidateMetadataSchemaResponse
+	nil,                                    // 18: mocks3.metadata.v1.Metadata.HeadersEntry
+	nil,                                    // 19: mocks3.metadata.v1.Metadata.TagsEntry
+	(*timestamppb.Timestamp)(nil),          // 20: google.protobuf.Timestamp
+}
+var file_metadata_proto_depIdxs = []int32{
+	18, // 0: mocks3.metadata.v1.Metadata.headers:type_name -> mocks3.metadata.v1.Metadata.HeadersEntry
+	19, // 1: mocks3.metadata.v1.Metadata.tags:type_name -> mocks3.metadata.v1.Metadata.TagsEntry
+	20, // 2: mocks3.metadata.v1.Metadata.created_at:type_name -> google.protobuf.Timestamp
+	20, // 3: mocks3.metadata.v1.Metadata.updated_at:type_name -> google.protobuf.Timestamp
+	20, // 4: mocks3.metadata.v1.Metadata.deleted_at:type_name -> google.protobuf.Timestamp
+	20, // 5: mocks3.metadata.v1.MetadataFilter.created_from:type_name -> google.protobuf.Timestamp
+	20, // 6: mocks3.metadata.v1.MetadataFilter.created_to:type_name -> google.protobuf.Timestamp
+	20, // 7: mocks3.metadata.v1.MetadataFilter.modified_after:type_name -> google.protobuf.Timestamp
+	20, // 8: mocks3.metadata.v1.MetadataFilter.modified_before:type_name -> google.protobuf.Timestamp
+	0,  // 9: mocks3.metadata.v1.SaveMetadataRequest.metadata:type_name -> mocks3.metadata.v1.Metadata
+	0,  // 10: mocks3.metadata.v1.SaveMetadataResponse.metadata:type_name -> mocks3.metadata.v1.Metadata
+	0,  // 11: mocks3.metadata.v1.GetMetadataResponse.metadata:type_name -> mocks3.metadata.v1.Metadata
+	0,  // 12: mocks3.metadata.v1.UpdateMetadataRequest.metadata:type_name -> mocks3.metadata.v1.Metadata
+	0,  // 13: mocks3.metadata.v1.UpdateMetadataResponse.metadata:type_name -> mocks3.metadata.v1.Metadata
+	1,  // 14: mocks3.metadata.v1.ListMetadataRequest.filter:type_name -> mocks3.metadata.v1.MetadataFilter
+	0,  // 15: mocks3.metadata.v1.ListMetadataResponse.metadata:type_name -> mocks3.metadata.v1.Metadata
+	0,  // 16: mocks3.metadata.v1.FindMetadataByHashResponse.metadata:type_name -> mocks3.metadata.v1.Metadata
+	0,  // 17: mocks3.metadata.v1.ValidateMetadataSchemaRequest.metadata:type_name -> mocks3.metadata.v1.Metadata
+	2,  // 18: mocks3.metadata.v1.MetadataService.SaveMetadata:input_type -> mocks3.metadata.v1.SaveMetadataRequest
+	4,  // 19: mocks3.metadata.v1.MetadataService.GetMetadata:input_type -> mocks3.metadata.v1.GetMetadataRequest
+	6,  // 20: mocks3.metadata.v1.MetadataService.UpdateMetadata:input_type -> mocks3.metadata.v1.UpdateMetadataRequest
+	8,  // 21: mocks3.metadata.v1.MetadataService.DeleteMetadata:input_type -> mocks3.metadata.v1.DeleteMetadataRequest
+	10, // 22: mocks3.metadata.v1.MetadataService.ListMetadata:input_type -> mocks3.metadata.v1.ListMetadataRequest
+	12, // 23: mocks3.metadata.v1.MetadataService.CountObjects:input_type -> mocks3.metadata.v1.CountObjectsRequest
+	14, // 24: mocks3.metadata.v1.MetadataService.FindMetadataByHash:input_type -> mocks3.metadata.v1.FindMetadataByHashRequest
+	16, // 25: mocks3.metadata.v1.MetadataService.ValidateMetadataSchema:input_type -> mocks3.metadata.v1.ValidateMetadataSchemaRequest
+	3,  // 26: mocks3.metadata.v1.MetadataService.SaveMetadata:output_type -> mocks3.metadata.v1.SaveMetadataResponse
+	5,  // 27: mocks3.metadata.v1.MetadataService.GetMetadata:output_type -> mocks3.metadata.v1.GetMetadataResponse
+	7,  // 28: mocks3.metadata.v1.MetadataService.UpdateMetadata:output_type -> mocks3.metadata.v1.UpdateMetadataResponse
+	9,  // 29: mocks3.metadata.v1.MetadataService.DeleteMetadata:output_type -> mocks3.metadata.v1.DeleteMetadataResponse
+	11, // 30: mocks3.metadata.v1.MetadataService.ListMetadata:output_type -> mocks3.metadata.v1.ListMetadataResponse
+	13, // 31: mocks3.metadata.v1.MetadataService.CountObjects:output_type -> mocks3.metadata.v1.CountObjectsResponse
+	15, // 32: mocks3.metadata.v1.MetadataService.FindMetadataByHash:output_type -> mocks3.metadata.v1.FindMetadataByHashResponse
+	17, // 33: mocks3.metadata.v1.MetadataService.ValidateMetadataSchema:output_type -> mocks3.metadata.v1.ValidateMetadataSchemaResponse
+	26, // [26:34] is the sub-list for method output_type
+	18, // [18:26] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_metadata_proto_init() }
+func file_metadata_proto_init() {
+	if File_metadata_proto != nil {
+		return
+	}
+	file_metadata_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_metadata_proto_rawDesc), len(file_metadata_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   20,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_metadata_proto_goTypes,
+		DependencyIndexes: file_metadata_proto_depIdxs,
+		MessageInfos:      file_metadata_proto_msgTypes,
+	}.Build()
+	File_metadata_proto = out.File
+	file_metadata_proto_goTypes = nil
+	file_metadata_proto_depIdxs = nil
+}
@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ID生成策略
+const (
+	IDStrategyUUIDv4    = "uuidv4"    // 随机UUID
+	IDStrategyUUIDv7    = "uuidv7"    // 按时间排序的UUID（默认）
+	IDStrategyULID      = "ulid"      // 按时间排序的26位ULID
+	IDStrategySnowflake = "snowflake" // 64位雪花ID（十进制字符串），需要节点ID
+)
+
+// 雪花ID位布局：41位毫秒时间戳 | 10位节点ID | 12位序列号
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNodeID    = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+// snowflakeEpoch 雪花ID时间戳起点（2024-01-01T00:00:00Z）
+var snowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+// IDGenerator ID生成器
+type IDGenerator interface {
+	NewID() string
+}
+
+// defaultIDGenerator 全局ID生成器
+var defaultIDGenerator atomic.Value
+
+func init() {
+	defaultIDGenerator.Store(idGeneratorHolder{&uuidV7Generator{}})
+}
+
+// idGeneratorHolder 保证atomic.Value中存储的具体类型一致
+type idGeneratorHolder struct {
+	IDGenerator
+}
+
+// NewID 使用全局ID生成器生成ID
+func NewID() string {
+	return defaultIDGenerator.Load().(idGeneratorHolder).NewID()
+}
+
+// SetIDGenerator 设置全局ID生成器
+func SetIDGenerator(generator IDGenerator) {
+	if generator == nil {
+		return
+	}
+	defaultIDGenerator.Store(idGeneratorHolder{generator})
+}
+
+// NewIDGenerator 根据策略创建ID生成器，nodeID仅用于snowflake
+func NewIDGenerator(strategy string, nodeID int64) (IDGenerator, error) {
+	switch strings.ToLower(strategy) {
+	case "", IDStrategyUUIDv7:
+		return &uuidV7Generator{}, nil
+	case IDStrategyUUIDv4:
+		return &uuidV4Generator{}, nil
+	case IDStrategyULID:
+		return &ulidGenerator{}, nil
+	case IDStrategySnowflake:
+		if nodeID < 0 || nodeID > snowflakeMaxNodeID {
+			return nil, fmt.Errorf("snowflake node id must be between 0 and %d", snowflakeMaxNodeID)
+		}
+		return &snowflakeGenerator{nodeID: nodeID}, nil
+	default:
+		return nil, fmt.Errorf("unknown id strategy: %s", strategy)
+	}
+}
+
+// ConfigureIDGeneratorFromEnv 根据环境变量设置全局ID生成器
+//
+// ID_STRATEGY 选择策略（默认uuidv7）；ID_NODE_ID 为snowflake节点ID，
+// 未设置时由主机名哈希得到，同一部署中的多个实例应显式配置不同的值。
+func ConfigureIDGeneratorFromEnv() (string, error) {
+	strategy := strings.ToLower(os.Getenv("ID_STRATEGY"))
+	if strategy == "" {
+		strategy = IDStrategyUUIDv7
+	}
+
+	nodeID := hostnameNodeID()
+	if value := os.Getenv("ID_NODE_ID"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid ID_NODE_ID: %w", err)
+		}
+		nodeID = parsed
+	}
+
+	generator, err := NewIDGenerator(strategy, nodeID)
+	if err != nil {
+		return "", err
+	}
+	SetIDGenerator(generator)
+
+	return strategy, nil
+}
+
+// hostnameNodeID 由主机名计算默认节点ID
+func hostnameNodeID() int64 {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(hostname))
+	return int64(h.Sum32() % (snowflakeMaxNodeID + 1))
+}
+
+// uuidV4Generator 随机UUID
+type uuidV4Generator struct{}
+
+func (g *uuidV4Generator) NewID() string {
+	return uuid.New().String()
+}
+
+// uuidV7Generator 按时间排序的UUID
+type uuidV7Generator struct{}
+
+func (g *uuidV7Generator) NewID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.New().String()
+	}
+	return id.String()
+}
+
+// crockfordAlphabet ULID使用的Crockford Base32字母表
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidGenerator 单调递增的ULID（48位毫秒时间戳 + 80位随机数）
+//
+// 同一毫秒内在上一个随机部分上加1，保证进程内严格递增。
+type ulidGenerator struct {
+	mu       sync.Mutex
+	lastTime uint64
+	lastHigh uint16 // 随机部分高16位
+	lastLow  uint64 // 随机部分低64位
+}
+
+func (g *ulidGenerator) NewID() string {
+	g.mu.Lock()
+	now := uint64(time.Now().UnixMilli())
+	if now > g.lastTime {
+		var entropy [10]byte
+		if _, err := rand.Read(entropy[:]); err != nil {
+			g.mu.Unlock()
+			return uuid.New().String()
+		}
+		g.lastTime = now
+		g.lastHigh = binary.BigEndian.Uint16(entropy[:2])
+		g.lastLow = binary.BigEndian.Uint64(entropy[2:])
+	} else {
+		// 同一毫秒或时钟回拨：沿用上次时间戳并递增随机部分
+		g.lastLow++
+		if g.lastLow == 0 {
+			g.lastHigh++
+		}
+	}
+	timestamp, high, low := g.lastTime, g.lastHigh, g.lastLow
+	g.mu.Unlock()
+
+	var data [16]byte
+	data[0] = byte(timestamp >> 40)
+	data[1] = byte(timestamp >> 32)
+	data[2] = byte(timestamp >> 24)
+	data[3] = byte(timestamp >> 16)
+	data[4] = byte(timestamp >> 8)
+	data[5] = byte(timestamp)
+	binary.BigEndian.PutUint16(data[6:8], high)
+	binary.BigEndian.PutUint64(data[8:], low)
+
+	return encodeULID(data)
+}
+
+// encodeULID 将128位数据编码为26位Crockford Base32
+func encodeULID(data [16]byte) string {
+	hi := binary.BigEndian.Uint64(data[:8])
+	lo := binary.BigEndian.Uint64(data[8:])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// snowflakeGenerator 雪花ID生成器
+type snowflakeGenerator struct {
+	mu       sync.Mutex
+	nodeID   int64
+	lastTime int64
+	sequence int64
+}
+
+func (g *snowflakeGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now().UnixMilli() - snowflakeEpoch
+	if now < g.lastTime {
+		// 时钟回拨时沿用上次时间戳，避免生成重复ID
+		now = g.lastTime
+	}
+
+	if now == g.lastTime {
+		g.sequence = (g.sequence + 1) & snowflakeMaxSequence
+		if g.sequence == 0 {
+			// 本毫秒序列号用尽，借用下一毫秒
+			now++
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastTime = now
+
+	id := now<<(snowflakeNodeBits+snowflakeSequenceBits) | g.nodeID<<snowflakeSequenceBits | g.sequence
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,127 @@
+package scenario
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxReportedViolations 断言失败时最多列出的违规项
+const maxReportedViolations = 10
+
+// assert 执行单个断言
+func (r *Runner) assert(ctx context.Context, spec AssertionSpec, stats *WorkloadStats) *StepResult {
+	start := time.Now()
+	step := &StepResult{Phase: PhaseAssert, Name: spec.Name, Status: StatusPassed}
+	defer func() {
+		step.Duration = time.Since(start)
+	}()
+
+	var ok bool
+	switch spec.Type {
+	case AssertErrorRate, AssertAvailability, AssertLatency, AssertThroughput:
+		if stats == nil {
+			step.Status = StatusSkipped
+			step.Message = "no workload executed"
+			return step
+		}
+		ok, step.Message = assertWorkload(spec, stats)
+	case AssertSeedIntact:
+		ok, step.Message = r.assertSeedIntact(ctx)
+	case AssertMetadataConsistent:
+		ok, step.Message = r.assertMetadataConsistent(ctx)
+	}
+
+	if !ok {
+		step.Status = StatusFailed
+	}
+	return step
+}
+
+// assertWorkload 校验工作负载SLO
+func assertWorkload(spec AssertionSpec, stats *WorkloadStats) (bool, string) {
+	op := stats.Operation(spec.Operation)
+	scope := spec.Operation
+	if scope == "" {
+		scope = "all operations"
+	}
+
+	switch spec.Type {
+	case AssertErrorRate:
+		rate := op.ErrorRate()
+		return rate <= spec.Max, fmt.Sprintf("%s error rate %.4f (max %.4f)", scope, rate, spec.Max)
+	case AssertAvailability:
+		availability := 1 - op.ErrorRate()
+		if op.Total == 0 {
+			availability = 0
+		}
+		return availability >= spec.Min, fmt.Sprintf("%s availability %.4f (min %.4f)", scope, availability, spec.Min)
+	case AssertLatency:
+		latency := op.Percentile(spec.Percentile)
+		return op.Total > 0 && latency <= spec.MaxLatency,
+			fmt.Sprintf("%s p%g latency %s (max %s)", scope, spec.Percentile, latency.Round(time.Microsecond), spec.MaxLatency)
+	case AssertThroughput:
+		throughput := 0.0
+		if stats.Duration > 0 {
+			throughput = float64(op.Total) / stats.Duration.Seconds()
+		}
+		return throughput >= spec.Min, fmt.Sprintf("%s throughput %.2f ops/s (min %.2f)", scope, throughput, spec.Min)
+	}
+	return false, fmt.Sprintf("unsupported workload assertion: %s", spec.Type)
+}
+
+// assertSeedIntact 校验种子对象可读且内容未变
+func (r *Runner) assertSeedIntact(ctx context.Context) (bool, string) {
+	var violations []string
+	for _, obj := range r.seeded {
+		status, data, err := r.objects.do(ctx, http.MethodGet, r.scenario.Seed.Bucket, obj.Key, nil, "")
+		if err != nil || status != http.StatusOK {
+			violations = append(violations, fmt.Sprintf("%s: %s", obj.Key, describeFailure(status, err)))
+			continue
+		}
+		sum := md5.Sum(data)
+		if hex.EncodeToString(sum[:]) != obj.MD5 {
+			violations = append(violations, fmt.Sprintf("%s: content changed", obj.Key))
+		}
+	}
+	return len(violations) == 0, summarizeViolations(len(r.seeded), violations)
+}
+
+// assertMetadataConsistent 校验种子对象元数据与内容一致
+func (r *Runner) assertMetadataConsistent(ctx context.Context) (bool, string) {
+	var violations []string
+	for _, obj := range r.seeded {
+		metadata, err := r.metadata.GetMetadata(ctx, r.scenario.Seed.Bucket, obj.Key)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("%s: %v", obj.Key, err))
+			continue
+		}
+		if metadata.Size != obj.Size {
+			violations = append(violations, fmt.Sprintf("%s: metadata size %d, content size %d", obj.Key, metadata.Size, obj.Size))
+		}
+		if metadata.MD5Hash != "" && metadata.MD5Hash != obj.MD5 {
+			violations = append(violations, fmt.Sprintf("%s: metadata md5 %s, content md5 %s", obj.Key, metadata.MD5Hash, obj.MD5))
+		}
+	}
+	return len(violations) == 0, summarizeViolations(len(r.seeded), violations)
+}
+
+// summarizeViolations 生成违规摘要
+func summarizeViolations(checked int, violations []string) string {
+	if len(violations) == 0 {
+		return fmt.Sprintf("%d objects checked", checked)
+	}
+	return fmt.Sprintf("%d of %d objects violated: %s", len(violations), checked, joinLimited(violations))
+}
+
+// joinLimited 拼接前maxReportedViolations项
+func joinLimited(items []string) string {
+	if len(items) > maxReportedViolations {
+		return strings.Join(items[:maxReportedViolations], "; ") + fmt.Sprintf("; ... (%d more)", len(items)-maxReportedViolations)
+	}
+	return strings.Join(items, "; ")
+}
@@ -0,0 +1,105 @@
+package scenario
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// junitTestSuites JUnit XML根节点
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite 一个场景对应一个测试套件
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase 一个步骤对应一个测试用例
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitMessage 失败/跳过信息
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit 将场景结果写为JUnit XML
+func WriteJUnit(w io.Writer, results []*Result) error {
+	report := junitTestSuites{}
+	var total time.Duration
+
+	for _, result := range results {
+		passed, failed, skipped := result.Counts()
+		suite := junitTestSuite{
+			Name:      result.Scenario,
+			Tests:     passed + failed + skipped,
+			Failures:  failed,
+			Skipped:   skipped,
+			Time:      junitSeconds(result.Duration),
+			Timestamp: result.StartedAt.UTC().Format(time.RFC3339),
+		}
+
+		for _, step := range result.Steps {
+			tc := junitTestCase{
+				Name:      step.Name,
+				Classname: fmt.Sprintf("%s.%s", result.Scenario, step.Phase),
+				Time:      junitSeconds(step.Duration),
+				SystemOut: step.Output,
+			}
+			switch step.Status {
+			case StatusFailed:
+				tc.Failure = &junitMessage{Message: step.Message}
+			case StatusSkipped:
+				tc.Skipped = &junitMessage{Message: step.Message}
+			default:
+				if tc.SystemOut == "" {
+					tc.SystemOut = step.Message
+				}
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		total += result.Duration
+		report.Suites = append(report.Suites, suite)
+	}
+	report.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write junit report: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write junit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSeconds 格式化为秒
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
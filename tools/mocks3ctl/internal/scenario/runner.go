@@ -0,0 +1,426 @@
+package scenario
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"mocks3/shared/client"
+	"mocks3/shared/models"
+	"mocks3/shared/observability"
+	"mocks3/shared/utils"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 步骤状态
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// 步骤阶段
+const (
+	PhaseSeed     = "seed"
+	PhaseWorkload = "workload"
+	PhaseFault    = "fault"
+	PhaseAssert   = "assert"
+	PhaseTeardown = "teardown"
+)
+
+// StepResult 单个步骤的执行结果
+type StepResult struct {
+	Phase    string        `json:"phase"`
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Message  string        `json:"message,omitempty"`
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Result 场景执行结果
+type Result struct {
+	Scenario  string         `json:"scenario"`
+	TestRunID string         `json:"test_run_id"`
+	StartedAt time.Time      `json:"started_at"`
+	Duration  time.Duration  `json:"duration"`
+	Steps     []*StepResult  `json:"steps"`
+	Workload  *WorkloadStats `json:"workload,omitempty"`
+	mu        sync.Mutex
+}
+
+// Failed 是否有失败步骤
+func (r *Result) Failed() bool {
+	for _, step := range r.Steps {
+		if step.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// Counts 统计各状态步骤数
+func (r *Result) Counts() (passed, failed, skipped int) {
+	for _, step := range r.Steps {
+		switch step.Status {
+		case StatusPassed:
+			passed++
+		case StatusFailed:
+			failed++
+		case StatusSkipped:
+			skipped++
+		}
+	}
+	return
+}
+
+// addStep 添加步骤结果（并发安全）
+func (r *Result) addStep(step *StepResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Steps = append(r.Steps, step)
+}
+
+// seededObject 已写入的种子对象
+type seededObject struct {
+	Key  string
+	MD5  string
+	Size int64
+}
+
+// Runner 场景执行器
+type Runner struct {
+	scenario  *Scenario
+	objects   *objectClient
+	metadata  *client.MetadataClient
+	mockError *client.MockErrorClient
+	seeded    []seededObject
+	pool      *keyPool
+	rules     map[string]string // fault name -> rule ID
+	rulesMu   sync.Mutex
+	testRunID string // 随baggage透传给各服务，日志和注入规则可据此区分本次运行的请求
+}
+
+// NewRunner 创建场景执行器
+func NewRunner(s *Scenario) *Runner {
+	timeout := s.Endpoints.Timeout
+	mockError := client.NewMockErrorClient(s.Endpoints.MockError, timeout)
+	if s.Endpoints.APIKey != "" {
+		mockError.SetHeader("X-Api-Key", s.Endpoints.APIKey)
+	}
+	mockError.SetNamespace(s.Endpoints.Namespace)
+
+	return &Runner{
+		scenario:  s,
+		objects:   newObjectClient(s.Endpoints.Storage, timeout),
+		metadata:  client.NewMetadataClient(s.Endpoints.Metadata, timeout),
+		mockError: mockError,
+		pool:      &keyPool{owned: make(map[string]bool)},
+		rules:     make(map[string]string),
+		testRunID: utils.NewID(),
+	}
+}
+
+// Run 执行场景，清理步骤总会执行
+func (r *Runner) Run(ctx context.Context) *Result {
+	result := &Result{Scenario: r.scenario.Name, TestRunID: r.testRunID, StartedAt: time.Now()}
+	ctx = r.withTestRun(ctx)
+	defer func() {
+		result.Duration = time.Since(result.StartedAt)
+	}()
+	defer r.teardown(result)
+
+	if !r.seed(ctx, result) {
+		r.skipRemaining(result, "seed failed")
+		return result
+	}
+
+	if r.scenario.Workload.Duration > 0 {
+		result.Workload = r.runWorkloadWithFaults(ctx, result)
+	} else {
+		r.applyFaultsWithoutWorkload(ctx, result)
+	}
+
+	for _, assertion := range r.scenario.Assertions {
+		result.addStep(r.assert(ctx, assertion, result.Workload))
+	}
+
+	return result
+}
+
+// withTestRun 在context的baggage中设置测试运行ID和场景ID，设置失败时不影响执行
+func (r *Runner) withTestRun(ctx context.Context) context.Context {
+	if tagged, err := observability.WithTestRun(ctx, r.testRunID, r.scenario.Name); err == nil {
+		return tagged
+	}
+	return ctx
+}
+
+// seed 写入种子数据
+func (r *Runner) seed(ctx context.Context, result *Result) bool {
+	start := time.Now()
+	step := &StepResult{Phase: PhaseSeed, Name: "seed", Status: StatusPassed}
+	defer func() {
+		step.Duration = time.Since(start)
+		result.addStep(step)
+	}()
+
+	var failures []string
+	for _, obj := range r.scenario.Seed.Objects {
+		count := obj.Count
+		if count <= 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			key := obj.Key
+			if obj.Count > 1 {
+				key = fmt.Sprintf("%s-%d", obj.Key, i)
+			}
+
+			data := []byte(obj.Content)
+			if obj.Content == "" {
+				size := obj.Size
+				if size <= 0 {
+					size = 1024
+				}
+				data = randomBytes(size)
+			}
+
+			status, _, err := r.objects.do(ctx, http.MethodPut, r.scenario.Seed.Bucket, key, data, obj.ContentType)
+			if err != nil || status >= 300 {
+				failures = append(failures, fmt.Sprintf("%s: %s", key, describeFailure(status, err)))
+				continue
+			}
+
+			sum := md5.Sum(data)
+			r.seeded = append(r.seeded, seededObject{Key: key, MD5: hex.EncodeToString(sum[:]), Size: int64(len(data))})
+			if r.scenario.Workload.Bucket == r.scenario.Seed.Bucket {
+				r.pool.readable = append(r.pool.readable, key)
+			}
+		}
+	}
+
+	step.Output = fmt.Sprintf("seeded %d objects into %s", len(r.seeded), r.scenario.Seed.Bucket)
+	if len(failures) > 0 {
+		step.Status = StatusFailed
+		step.Message = fmt.Sprintf("%d seed objects failed: %s", len(failures), joinLimited(failures))
+		return false
+	}
+	return true
+}
+
+// runWorkloadWithFaults 执行工作负载并按时间注入/移除故障
+func (r *Runner) runWorkloadWithFaults(ctx context.Context, result *Result) *WorkloadStats {
+	workloadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, fault := range r.scenario.Faults {
+		wg.Add(1)
+		go func(fault FaultSpec) {
+			defer wg.Done()
+			if !sleepContext(workloadCtx, fault.At) {
+				result.addStep(&StepResult{Phase: PhaseFault, Name: fault.Name + "/apply", Status: StatusSkipped, Message: "workload finished before fault start"})
+				return
+			}
+			if !r.applyFault(ctx, fault, result) {
+				return
+			}
+			if fault.Duration > 0 {
+				sleepContext(workloadCtx, fault.Duration)
+			} else {
+				<-workloadCtx.Done()
+			}
+			r.removeFault(ctx, fault.Name, result)
+		}(fault)
+	}
+
+	start := time.Now()
+	stats := runWorkload(ctx, r.scenario.Workload, r.objects, r.pool)
+	cancel()
+	wg.Wait()
+
+	total := stats.Operation("")
+	result.addStep(&StepResult{
+		Phase:    PhaseWorkload,
+		Name:     "workload",
+		Status:   StatusPassed,
+		Message:  fmt.Sprintf("%d operations, %d errors", total.Total, total.Errors),
+		Output:   stats.Summary(),
+		Duration: time.Since(start),
+	})
+	return stats
+}
+
+// applyFaultsWithoutWorkload 没有工作负载时立即注入故障，断言结束后由清理步骤移除
+func (r *Runner) applyFaultsWithoutWorkload(ctx context.Context, result *Result) {
+	for _, fault := range r.scenario.Faults {
+		r.applyFault(ctx, fault, result)
+	}
+}
+
+// applyFault 在mock-error中创建故障规则
+func (r *Runner) applyFault(ctx context.Context, fault FaultSpec, result *Result) bool {
+	start := time.Now()
+	rule := &models.ErrorRule{
+		Name:        fmt.Sprintf("scenario:%s:%s", r.scenario.Name, fault.Name),
+		Description: fmt.Sprintf("Injected by scenario %s", r.scenario.Name),
+		Service:     fault.Service,
+		Operation:   fault.Operation,
+		Enabled:     true,
+		Action: models.ErrorAction{
+			Type:     fault.Action.Type,
+			HTTPCode: fault.Action.HTTPCode,
+			Message:  fault.Action.Message,
+			Body:     fault.Action.Body,
+		},
+		Metadata:  map[string]string{"scenario": r.scenario.Name, "fault": fault.Name, models.BaggageTestRunID: r.testRunID},
+		CreatedBy: "mocks3ctl",
+	}
+	if fault.Action.Delay > 0 {
+		delay := fault.Action.Delay
+		rule.Action.Delay = &delay
+	}
+	if len(fault.Action.Metadata) > 0 {
+		rule.Action.Metadata = make(map[string]interface{}, len(fault.Action.Metadata))
+		for k, v := range fault.Action.Metadata {
+			rule.Action.Metadata[k] = v
+		}
+	}
+	if fault.Probability > 0 && fault.Probability < 1 {
+		rule.Conditions = []models.ErrorCondition{{
+			Type:     models.ErrorConditionTypeProbability,
+			Operator: "lt",
+			Value:    fault.Probability,
+		}}
+	}
+
+	step := &StepResult{Phase: PhaseFault, Name: fault.Name + "/apply", Status: StatusPassed}
+	ruleID, err := r.mockError.AddRule(ctx, rule)
+	step.Duration = time.Since(start)
+	if err != nil {
+		step.Status = StatusFailed
+		step.Message = fmt.Sprintf("failed to create mock-error rule: %v", err)
+		result.addStep(step)
+		return false
+	}
+
+	r.rulesMu.Lock()
+	r.rules[fault.Name] = ruleID
+	r.rulesMu.Unlock()
+
+	step.Output = fmt.Sprintf("rule %s on %s/%s (%s)", ruleID, fault.Service, fault.Operation, fault.Action.Type)
+	result.addStep(step)
+	return true
+}
+
+// removeFault 删除故障规则
+func (r *Runner) removeFault(ctx context.Context, name string, result *Result) {
+	r.rulesMu.Lock()
+	ruleID, ok := r.rules[name]
+	delete(r.rules, name)
+	r.rulesMu.Unlock()
+	if !ok {
+		return
+	}
+
+	start := time.Now()
+	step := &StepResult{Phase: PhaseFault, Name: name + "/remove", Status: StatusPassed}
+	if err := r.mockError.RemoveRule(ctx, ruleID); err != nil {
+		step.Status = StatusFailed
+		step.Message = fmt.Sprintf("failed to remove mock-error rule %s: %v", ruleID, err)
+	}
+	step.Duration = time.Since(start)
+	result.addStep(step)
+}
+
+// teardown 移除残留故障并清理数据
+func (r *Runner) teardown(result *Result) {
+	ctx := r.withTestRun(context.Background())
+
+	r.rulesMu.Lock()
+	names := make([]string, 0, len(r.rules))
+	for name := range r.rules {
+		names = append(names, name)
+	}
+	r.rulesMu.Unlock()
+	sort.Strings(names)
+	for _, name := range names {
+		r.removeFault(ctx, name, result)
+	}
+
+	start := time.Now()
+	step := &StepResult{Phase: PhaseTeardown, Name: "teardown", Status: StatusPassed}
+	defer func() {
+		step.Duration = time.Since(start)
+		result.addStep(step)
+	}()
+
+	if r.scenario.Teardown.KeepData {
+		step.Output = "keep_data set, objects retained"
+		return
+	}
+
+	var failures []string
+	deleted := 0
+	deleteObject := func(bucket, key string) {
+		status, _, err := r.objects.do(ctx, http.MethodDelete, bucket, key, nil, "")
+		if err != nil || (status >= 300 && status != http.StatusNotFound) {
+			failures = append(failures, fmt.Sprintf("%s/%s: %s", bucket, key, describeFailure(status, err)))
+			return
+		}
+		deleted++
+	}
+
+	for _, obj := range r.seeded {
+		deleteObject(r.scenario.Seed.Bucket, obj.Key)
+	}
+	for _, key := range r.pool.ownedKeys() {
+		deleteObject(r.scenario.Workload.Bucket, key)
+	}
+
+	step.Output = fmt.Sprintf("deleted %d objects", deleted)
+	if len(failures) > 0 {
+		step.Status = StatusFailed
+		step.Message = fmt.Sprintf("%d objects could not be deleted: %s", len(failures), joinLimited(failures))
+	}
+}
+
+// skipRemaining 将未执行的步骤标记为跳过
+func (r *Runner) skipRemaining(result *Result, reason string) {
+	if r.scenario.Workload.Duration > 0 {
+		result.addStep(&StepResult{Phase: PhaseWorkload, Name: "workload", Status: StatusSkipped, Message: reason})
+	}
+	for _, fault := range r.scenario.Faults {
+		result.addStep(&StepResult{Phase: PhaseFault, Name: fault.Name + "/apply", Status: StatusSkipped, Message: reason})
+	}
+	for _, assertion := range r.scenario.Assertions {
+		result.addStep(&StepResult{Phase: PhaseAssert, Name: assertion.Name, Status: StatusSkipped, Message: reason})
+	}
+}
+
+// sleepContext 等待指定时间，ctx结束时返回false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// describeFailure 描述请求失败原因
+func describeFailure(status int, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("status %d", status)
+}
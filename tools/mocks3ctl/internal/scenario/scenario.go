@@ -0,0 +1,252 @@
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 工作负载操作类型
+const (
+	OpGet    = "get"
+	OpPut    = "put"
+	OpHead   = "head"
+	OpDelete = "delete"
+)
+
+// 断言类型
+const (
+	AssertErrorRate          = "error_rate"          // 错误率不超过max
+	AssertAvailability       = "availability"        // 成功率不低于min
+	AssertLatency            = "latency"             // 指定百分位延迟不超过max_latency
+	AssertThroughput         = "throughput"          // 吞吐量（ops/s）不低于min
+	AssertSeedIntact         = "seed_intact"         // 种子对象可读且内容未变
+	AssertMetadataConsistent = "metadata_consistent" // 种子对象元数据大小/MD5与内容一致
+)
+
+// Scenario 端到端混沌场景
+// 执行顺序：seed → workload（期间按时间注入faults）→ assertions → teardown
+type Scenario struct {
+	Name        string          `yaml:"name"`
+	Description string          `yaml:"description"`
+	Endpoints   Endpoints       `yaml:"endpoints"`
+	Seed        SeedSpec        `yaml:"seed"`
+	Workload    WorkloadSpec    `yaml:"workload"`
+	Faults      []FaultSpec     `yaml:"faults"`
+	Assertions  []AssertionSpec `yaml:"assertions"`
+	Teardown    TeardownSpec    `yaml:"teardown"`
+}
+
+// Endpoints 被测服务地址
+type Endpoints struct {
+	Storage   string        `yaml:"storage"`
+	Metadata  string        `yaml:"metadata"`
+	MockError string        `yaml:"mock_error"`
+	APIKey    string        `yaml:"api_key"`   // mock-error启用鉴权时用于管理规则，为空时读取MOCKS3_API_KEY环境变量
+	Namespace string        `yaml:"namespace"` // 故障规则所属的mock-error命名空间，需与被测服务配置的命名空间一致
+	Timeout   time.Duration `yaml:"timeout"`
+}
+
+// SeedSpec 种子数据
+type SeedSpec struct {
+	Bucket  string       `yaml:"bucket"`
+	Objects []SeedObject `yaml:"objects"`
+}
+
+// SeedObject 种子对象，count>1时生成 key-0..key-(count-1)
+type SeedObject struct {
+	Key         string `yaml:"key"`
+	Content     string `yaml:"content"` // 为空时生成size字节随机内容
+	Size        int    `yaml:"size"`
+	Count       int    `yaml:"count"`
+	ContentType string `yaml:"content_type"`
+}
+
+// WorkloadSpec 工作负载
+type WorkloadSpec struct {
+	Duration    time.Duration  `yaml:"duration"`
+	Concurrency int            `yaml:"concurrency"`
+	Rate        float64        `yaml:"rate"` // 总请求速率（ops/s），0表示不限速
+	Mix         map[string]int `yaml:"mix"`  // 操作权重
+	Bucket      string         `yaml:"bucket"`
+	KeyPrefix   string         `yaml:"key_prefix"`
+	ObjectSize  int            `yaml:"object_size"`
+}
+
+// FaultSpec 故障注入（通过mock-error规则实现）
+type FaultSpec struct {
+	Name        string        `yaml:"name"`
+	Service     string        `yaml:"service"`
+	Operation   string        `yaml:"operation"`
+	At          time.Duration `yaml:"at"`       // 相对工作负载开始的时间
+	Duration    time.Duration `yaml:"duration"` // 持续时间，0表示持续到工作负载结束
+	Probability float64       `yaml:"probability"`
+	Action      FaultAction   `yaml:"action"`
+}
+
+// FaultAction 故障动作
+type FaultAction struct {
+	Type     string            `yaml:"type"`
+	HTTPCode int               `yaml:"http_code"`
+	Message  string            `yaml:"message"`
+	Delay    time.Duration     `yaml:"delay"`
+	Body     string            `yaml:"body"`
+	Metadata map[string]string `yaml:"metadata"`
+}
+
+// AssertionSpec 断言
+type AssertionSpec struct {
+	Name       string        `yaml:"name"`
+	Type       string        `yaml:"type"`
+	Operation  string        `yaml:"operation"`  // 为空时统计全部操作
+	Percentile float64       `yaml:"percentile"` // latency断言使用，默认99
+	Max        float64       `yaml:"max"`
+	Min        float64       `yaml:"min"`
+	MaxLatency time.Duration `yaml:"max_latency"`
+}
+
+// TeardownSpec 清理配置
+type TeardownSpec struct {
+	KeepData bool `yaml:"keep_data"` // 保留种子数据和工作负载写入的对象
+}
+
+// Load 从YAML文件加载场景
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+
+	var s Scenario
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file: %w", err)
+	}
+
+	s.applyDefaults()
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+
+	return &s, nil
+}
+
+// applyDefaults 填充默认值
+func (s *Scenario) applyDefaults() {
+	if s.Endpoints.Storage == "" {
+		s.Endpoints.Storage = "http://localhost:8082"
+	}
+	if s.Endpoints.Metadata == "" {
+		s.Endpoints.Metadata = "http://localhost:8081"
+	}
+	if s.Endpoints.MockError == "" {
+		s.Endpoints.MockError = "http://localhost:8085"
+	}
+	if s.Endpoints.APIKey == "" {
+		s.Endpoints.APIKey = os.Getenv("MOCKS3_API_KEY")
+	}
+	if s.Endpoints.Timeout <= 0 {
+		s.Endpoints.Timeout = 10 * time.Second
+	}
+
+	if s.Workload.Bucket == "" {
+		s.Workload.Bucket = s.Seed.Bucket
+	}
+	if s.Workload.KeyPrefix == "" {
+		s.Workload.KeyPrefix = "workload-"
+	}
+	if s.Workload.Concurrency <= 0 {
+		s.Workload.Concurrency = 1
+	}
+	if s.Workload.ObjectSize <= 0 {
+		s.Workload.ObjectSize = 1024
+	}
+	if len(s.Workload.Mix) == 0 {
+		s.Workload.Mix = map[string]int{OpGet: 70, OpPut: 20, OpHead: 10}
+	}
+
+	for i := range s.Assertions {
+		if s.Assertions[i].Type == AssertLatency && s.Assertions[i].Percentile <= 0 {
+			s.Assertions[i].Percentile = 99
+		}
+		if s.Assertions[i].Name == "" {
+			s.Assertions[i].Name = s.Assertions[i].Type
+		}
+	}
+}
+
+// Validate 验证场景
+func (s *Scenario) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if len(s.Seed.Objects) > 0 && s.Seed.Bucket == "" {
+		return fmt.Errorf("seed bucket is required")
+	}
+	for _, obj := range s.Seed.Objects {
+		if obj.Key == "" {
+			return fmt.Errorf("seed object key is required")
+		}
+		if obj.Size < 0 || obj.Count < 0 {
+			return fmt.Errorf("seed object %s: size and count must not be negative", obj.Key)
+		}
+	}
+
+	if s.Workload.Duration < 0 {
+		return fmt.Errorf("workload duration must not be negative")
+	}
+	if s.Workload.Duration > 0 {
+		if s.Workload.Bucket == "" {
+			return fmt.Errorf("workload bucket is required")
+		}
+		total := 0
+		for op, weight := range s.Workload.Mix {
+			switch op {
+			case OpGet, OpPut, OpHead, OpDelete:
+			default:
+				return fmt.Errorf("unknown workload operation: %s", op)
+			}
+			if weight < 0 {
+				return fmt.Errorf("workload weight for %s must not be negative", op)
+			}
+			total += weight
+		}
+		if total == 0 {
+			return fmt.Errorf("workload mix must have at least one positive weight")
+		}
+	}
+
+	for _, fault := range s.Faults {
+		if fault.Name == "" {
+			return fmt.Errorf("fault name is required")
+		}
+		if fault.Service == "" || fault.Action.Type == "" {
+			return fmt.Errorf("fault %s: service and action type are required", fault.Name)
+		}
+		if fault.At < 0 || fault.Duration < 0 {
+			return fmt.Errorf("fault %s: at and duration must not be negative", fault.Name)
+		}
+		if fault.Probability < 0 || fault.Probability > 1 {
+			return fmt.Errorf("fault %s: probability must be between 0 and 1", fault.Name)
+		}
+	}
+
+	for _, assertion := range s.Assertions {
+		switch assertion.Type {
+		case AssertErrorRate, AssertAvailability, AssertThroughput, AssertSeedIntact, AssertMetadataConsistent:
+		case AssertLatency:
+			if assertion.MaxLatency <= 0 {
+				return fmt.Errorf("assertion %s: max_latency is required", assertion.Name)
+			}
+			if assertion.Percentile > 100 {
+				return fmt.Errorf("assertion %s: percentile must not exceed 100", assertion.Name)
+			}
+		default:
+			return fmt.Errorf("unknown assertion type: %s", assertion.Type)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,398 @@
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	mrand "math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// objectClient 通过S3兼容接口访问存储服务
+type objectClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// newObjectClient 创建对象客户端
+func newObjectClient(baseURL string, timeout time.Duration) *objectClient {
+	return &objectClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// do 执行请求，返回状态码和响应体
+func (c *objectClient) do(ctx context.Context, method, bucket, key string, body []byte, contentType string) (int, []byte, error) {
+	requestURL := fmt.Sprintf("%s/%s/%s", c.baseURL, url.PathEscape(bucket), url.PathEscape(key))
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("User-Agent", "mocks3ctl-scenario")
+	// 透传测试运行ID和场景ID
+	propagation.Baggage{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	return resp.StatusCode, data, nil
+}
+
+// OpStats 单个操作的统计
+type OpStats struct {
+	Total     int64           `json:"total"`
+	Errors    int64           `json:"errors"`
+	Latencies []time.Duration `json:"-"`
+}
+
+// ErrorRate 错误率
+func (s *OpStats) ErrorRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Total)
+}
+
+// Percentile 延迟百分位
+func (s *OpStats) Percentile(p float64) time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(s.Latencies))
+	copy(sorted, s.Latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	index := int(float64(len(sorted))*p/100+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// merge 合并统计
+func (s *OpStats) merge(other *OpStats) {
+	s.Total += other.Total
+	s.Errors += other.Errors
+	s.Latencies = append(s.Latencies, other.Latencies...)
+}
+
+// WorkloadStats 工作负载统计
+type WorkloadStats struct {
+	Duration time.Duration       `json:"duration"`
+	Ops      map[string]*OpStats `json:"ops"`
+	Errors   map[string]int64    `json:"errors"` // 错误原因 -> 次数
+	mu       sync.Mutex
+}
+
+// newWorkloadStats 创建工作负载统计
+func newWorkloadStats() *WorkloadStats {
+	return &WorkloadStats{
+		Ops:    make(map[string]*OpStats),
+		Errors: make(map[string]int64),
+	}
+}
+
+// record 记录一次操作
+func (w *WorkloadStats) record(op string, latency time.Duration, errReason string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := w.Ops[op]
+	if stats == nil {
+		stats = &OpStats{}
+		w.Ops[op] = stats
+	}
+	stats.Total++
+	stats.Latencies = append(stats.Latencies, latency)
+	if errReason != "" {
+		stats.Errors++
+		w.Errors[op+": "+errReason]++
+	}
+}
+
+// Operation 获取指定操作的统计，op为空时返回全部操作的汇总
+func (w *WorkloadStats) Operation(op string) *OpStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if op != "" {
+		if stats := w.Ops[op]; stats != nil {
+			return stats
+		}
+		return &OpStats{}
+	}
+
+	total := &OpStats{}
+	for _, stats := range w.Ops {
+		total.merge(stats)
+	}
+	return total
+}
+
+// Summary 统计摘要
+func (w *WorkloadStats) Summary() string {
+	w.mu.Lock()
+	ops := make([]string, 0, len(w.Ops))
+	for op := range w.Ops {
+		ops = append(ops, op)
+	}
+	w.mu.Unlock()
+	sort.Strings(ops)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "duration=%s\n", w.Duration.Round(time.Millisecond))
+	for _, op := range ops {
+		stats := w.Operation(op)
+		fmt.Fprintf(&b, "%s: total=%d errors=%d error_rate=%.4f p50=%s p99=%s\n",
+			op, stats.Total, stats.Errors, stats.ErrorRate(),
+			stats.Percentile(50).Round(time.Microsecond), stats.Percentile(99).Round(time.Microsecond))
+	}
+
+	w.mu.Lock()
+	reasons := make([]string, 0, len(w.Errors))
+	for reason := range w.Errors {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		fmt.Fprintf(&b, "error %s x%d\n", reason, w.Errors[reason])
+	}
+	w.mu.Unlock()
+
+	return b.String()
+}
+
+// keyPool 工作负载可访问的对象键
+type keyPool struct {
+	readable []string        // 可读取的键（种子+工作负载写入）
+	owned    map[string]bool // 工作负载写入的键，只有这些键会被删除
+	mu       sync.Mutex
+}
+
+// pick 随机选择一个可读键
+func (p *keyPool) pick(rng *mrand.Rand) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.readable) == 0 {
+		return "", false
+	}
+	return p.readable[rng.Intn(len(p.readable))], true
+}
+
+// pickOwned 随机选择并移除一个工作负载写入的键
+func (p *keyPool) pickOwned(rng *mrand.Rand) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, key := range p.readable {
+		if p.owned[key] && rng.Intn(2) == 0 {
+			p.readable = append(p.readable[:i], p.readable[i+1:]...)
+			delete(p.owned, key)
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// add 添加工作负载写入的键
+func (p *keyPool) add(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.owned[key] {
+		p.owned[key] = true
+		p.readable = append(p.readable, key)
+	}
+}
+
+// ownedKeys 获取工作负载写入的全部键
+func (p *keyPool) ownedKeys() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keys := make([]string, 0, len(p.owned))
+	for key := range p.owned {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// runWorkload 按配置执行工作负载直到duration结束
+func runWorkload(ctx context.Context, spec WorkloadSpec, client *objectClient, pool *keyPool) *WorkloadStats {
+	stats := newWorkloadStats()
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, spec.Duration)
+	defer cancel()
+
+	// 限速：令牌由单独协程按速率发放
+	var tokens chan struct{}
+	if spec.Rate > 0 {
+		tokens = make(chan struct{}, spec.Concurrency)
+		go func() {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / spec.Rate))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					select {
+					case tokens <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}()
+	}
+
+	ops, weights := workloadMix(spec.Mix)
+	var seq int64
+	var seqMu sync.Mutex
+	nextKey := func() string {
+		seqMu.Lock()
+		defer seqMu.Unlock()
+		seq++
+		return fmt.Sprintf("%s%d-%d", spec.KeyPrefix, start.UnixNano(), seq)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < spec.Concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := mrand.New(mrand.NewSource(start.UnixNano() + int64(worker)))
+			for {
+				if tokens != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-tokens:
+					}
+				} else if ctx.Err() != nil {
+					return
+				}
+
+				op := pickOperation(rng, ops, weights)
+				latency, reason, ok := executeOperation(ctx, op, spec, client, pool, rng, nextKey)
+				if !ok || ctx.Err() != nil {
+					// 没有可操作的键，或请求因负载结束被取消，不计入统计
+					continue
+				}
+				stats.record(op, latency, reason)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats.Duration = time.Since(start)
+	return stats
+}
+
+// executeOperation 执行一次操作，返回延迟、错误原因以及是否实际执行
+func executeOperation(ctx context.Context, op string, spec WorkloadSpec, client *objectClient, pool *keyPool, rng *mrand.Rand, nextKey func() string) (time.Duration, string, bool) {
+	var key string
+	var body []byte
+	method := http.MethodGet
+
+	switch op {
+	case OpPut:
+		method = http.MethodPut
+		key = nextKey()
+		body = randomBytes(spec.ObjectSize)
+	case OpGet, OpHead:
+		if op == OpHead {
+			method = http.MethodHead
+		}
+		var ok bool
+		if key, ok = pool.pick(rng); !ok {
+			return 0, "", false
+		}
+	case OpDelete:
+		method = http.MethodDelete
+		var ok bool
+		if key, ok = pool.pickOwned(rng); !ok {
+			return 0, "", false
+		}
+	}
+
+	start := time.Now()
+	status, _, err := client.do(ctx, method, spec.Bucket, key, body, "application/octet-stream")
+	latency := time.Since(start)
+
+	reason := ""
+	switch {
+	case err != nil:
+		reason = "transport error"
+	case status >= 300:
+		reason = fmt.Sprintf("status %d", status)
+	case op == OpPut:
+		pool.add(key)
+	}
+	return latency, reason, true
+}
+
+// workloadMix 将权重表转换为有序列表
+func workloadMix(mix map[string]int) ([]string, []int) {
+	ops := make([]string, 0, len(mix))
+	for op, weight := range mix {
+		if weight > 0 {
+			ops = append(ops, op)
+		}
+	}
+	sort.Strings(ops)
+
+	weights := make([]int, len(ops))
+	for i, op := range ops {
+		weights[i] = mix[op]
+	}
+	return ops, weights
+}
+
+// pickOperation 按权重选择操作
+func pickOperation(rng *mrand.Rand, ops []string, weights []int) string {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	n := rng.Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return ops[i]
+		}
+		n -= w
+	}
+	return ops[len(ops)-1]
+}
+
+// randomBytes 生成随机内容
+func randomBytes(size int) []byte {
+	data := make([]byte, size)
+	_, _ = rand.Read(data)
+	return data
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"mocks3/tools/mocks3ctl/internal/scenario"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const usage = `mocks3ctl - MockS3 命令行工具
+
+用法:
+  mocks3ctl scenario run [-junit report.xml] [-timeout 10m] <scenario.yaml>...
+  mocks3ctl scenario validate <scenario.yaml>...
+  mocks3ctl metadata export [-url http://localhost:8081] [-tenant t] [-bucket b] [-prefix p] [-format jsonl|csv] [-o file]
+  mocks3ctl metadata import [-url http://localhost:8081] [-tenant t] [-format jsonl|csv] [-conflict skip|overwrite|fail] <file>
+`
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] + " " + os.Args[2] {
+	case "scenario run":
+		os.Exit(runScenarios(os.Args[3:]))
+	case "scenario validate":
+		os.Exit(validateScenarios(os.Args[3:]))
+	case "metadata export":
+		os.Exit(exportMetadata(os.Args[3:]))
+	case "metadata import":
+		os.Exit(importMetadata(os.Args[3:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// runScenarios 依次执行场景，有失败时返回1
+func runScenarios(args []string) int {
+	fs := flag.NewFlagSet("scenario run", flag.ExitOnError)
+	junitPath := fs.String("junit", "", "JUnit XML报告输出路径")
+	timeout := fs.Duration("timeout", 30*time.Minute, "全部场景的超时时间")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	scenarios := make([]*scenario.Scenario, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		s, err := scenario.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		scenarios = append(scenarios, s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	failed := false
+	results := make([]*scenario.Result, 0, len(scenarios))
+	for _, s := range scenarios {
+		fmt.Printf("=== RUN   %s\n", s.Name)
+		result := scenario.NewRunner(s).Run(ctx)
+		results = append(results, result)
+		printResult(result)
+		if result.Failed() {
+			failed = true
+		}
+	}
+
+	if *junitPath != "" {
+		file, err := os.Create(*junitPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create junit report: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		if err := scenario.WriteJUnit(file, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// validateScenarios 仅校验场景文件
+func validateScenarios(paths []string) int {
+	if len(paths) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	code := 0
+	for _, path := range paths {
+		if _, err := scenario.Load(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			code = 1
+			continue
+		}
+		fmt.Printf("ok   %s\n", path)
+	}
+	return code
+}
+
+// printResult 输出场景结果
+func printResult(result *scenario.Result) {
+	for _, step := range result.Steps {
+		line := fmt.Sprintf("    --- %-7s %s/%s (%.3fs)", strings.ToUpper(step.Status), step.Phase, step.Name, step.Duration.Seconds())
+		if step.Message != "" {
+			line += ": " + step.Message
+		}
+		fmt.Println(line)
+	}
+	if result.Workload != nil {
+		fmt.Print(indent(result.Workload.Summary(), "        "))
+	}
+
+	passed, failed, skipped := result.Counts()
+	status := "PASS"
+	if result.Failed() {
+		status = "FAIL"
+	}
+	fmt.Printf("--- %s: %s (%.3fs) passed=%d failed=%d skipped=%d test_run_id=%s\n",
+		status, result.Scenario, result.Duration.Seconds(), passed, failed, skipped, result.TestRunID)
+}
+
+// indent 为每行添加缩进
+func indent(text, prefix string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	return prefix + strings.Join(lines, "\n"+prefix) + "\n"
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"mocks3/shared/models"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// exportMetadata 从元数据服务导出元数据到文件或标准输出
+func exportMetadata(args []string) int {
+	fs := flag.NewFlagSet("metadata export", flag.ExitOnError)
+	baseURL := fs.String("url", "http://localhost:8081", "元数据服务地址")
+	tenant := fs.String("tenant", "", "导出该租户的元数据（X-Tenant-Id），默认为请求的默认租户")
+	bucket := fs.String("bucket", "", "只导出该bucket")
+	prefix := fs.String("prefix", "", "只导出该前缀")
+	format := fs.String("format", models.MetadataFormatJSONL, "导出格式（jsonl或csv）")
+	output := fs.String("o", "", "输出文件，默认为标准输出")
+	fs.Parse(args)
+
+	query := url.Values{}
+	query.Set("format", *format)
+	if *bucket != "" {
+		query.Set("bucket", *bucket)
+	}
+	if *prefix != "" {
+		query.Set("prefix", *prefix)
+	}
+
+	req, err := newTenantRequest(http.MethodGet, strings.TrimRight(*baseURL, "/")+"/api/v1/metadata/export?"+query.Encode(), *tenant, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to export metadata: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		fmt.Fprintf(os.Stderr, "Error: export failed with status %d: %s\n", resp.StatusCode, strings.TrimSpace(string(body)))
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create output file: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	written, err := io.Copy(w, resp.Body)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write export: %v\n", err)
+		return 1
+	}
+	if *output != "" {
+		fmt.Printf("exported %d bytes to %s\n", written, *output)
+	}
+	return 0
+}
+
+// importMetadata 将导出文件导入元数据服务，有记录失败时返回1
+func importMetadata(args []string) int {
+	fs := flag.NewFlagSet("metadata import", flag.ExitOnError)
+	baseURL := fs.String("url", "http://localhost:8081", "元数据服务地址")
+	tenant := fs.String("tenant", "", "导入到该租户（X-Tenant-Id），默认为请求的默认租户")
+	format := fs.String("format", "", "文件格式（jsonl或csv），默认按扩展名判断")
+	conflict := fs.String("conflict", models.ImportConflictSkip, "与已有记录冲突时的处理方式（skip、overwrite或fail）")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	path := fs.Arg(0)
+
+	if *format == "" {
+		*format = models.MetadataFormatJSONL
+		if strings.HasSuffix(strings.ToLower(path), ".csv") {
+			*format = models.MetadataFormatCSV
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to open import file: %v\n", err)
+		return 2
+	}
+	defer file.Close()
+
+	query := url.Values{}
+	query.Set("format", *format)
+	query.Set("conflict", *conflict)
+
+	contentType := "application/x-ndjson"
+	if *format == models.MetadataFormatCSV {
+		contentType = "text/csv"
+	}
+
+	req, err := newTenantRequest(http.MethodPost, strings.TrimRight(*baseURL, "/")+"/api/v1/metadata/import?"+query.Encode(), *tenant, file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to import metadata: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "Error: import failed with status %d: %s\n", resp.StatusCode, strings.TrimSpace(string(body)))
+		return 1
+	}
+
+	var response struct {
+		Success bool                         `json:"success"`
+		Data    *models.MetadataImportResult `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || response.Data == nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid import response: %s\n", strings.TrimSpace(string(body)))
+		return 1
+	}
+
+	result := response.Data
+	for _, importErr := range result.Errors {
+		fmt.Fprintf(os.Stderr, "line %d: %s/%s: %s\n", importErr.Line, importErr.Bucket, importErr.Key, importErr.Error)
+	}
+	fmt.Printf("total=%d imported=%d overwritten=%d skipped=%d failed=%d\n",
+		result.Total, result.Imported, result.Overwritten, result.Skipped, result.Failed)
+	if result.Aborted {
+		fmt.Println("import aborted on first failure")
+	}
+
+	if !response.Success {
+		return 1
+	}
+	return 0
+}
+
+// newTenantRequest 创建请求，tenant非空时通过X-Tenant-Id头指定租户
+func newTenantRequest(method, rawURL, tenant string, body io.Reader) (*http.Request, error) {
+	if tenant != "" {
+		if err := models.ValidateTenantID(tenant); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(method, rawURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if tenant != "" {
+		req.Header.Set(models.HeaderTenantID, tenant)
+	}
+	return req, nil
+}